@@ -4,10 +4,24 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"copy-image/internal/config"
 	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	"copy-image/internal/hooks"
+	"copy-image/internal/i18n"
+	"copy-image/internal/lock"
+	"copy-image/internal/logging"
+	"copy-image/internal/queue"
+	"copy-image/internal/recent"
+	"copy-image/internal/schedule"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -17,9 +31,52 @@ import (
 // The context is used for Wails runtime calls like dialogs and events.
 type App struct {
 	ctx    context.Context
-	config *config.Config
 	copier *copier.Copier
 
+	// mu guards config, loadErr and tr, which the config watcher replaces
+	// while the bindings read them. The config is never changed in place:
+	// read it with currentConfig and change it with setConfig or
+	// updateConfig.
+	mu     sync.RWMutex
+	config *config.Config
+	tr     *i18n.Catalog
+
+	// configPath is the file the config was loaded from and is saved to,
+	// found by config.Locate; "" means config.DefaultPath.
+	configPath string
+
+	// loadErr is why the config file could not be fully loaded at startup,
+	// kept so the frontend can show it instead of silently using defaults.
+	loadErr error
+
+	// scheduler runs groups that have a schedule while the app is open.
+	scheduler *schedule.Scheduler
+
+	// watchCancel stops watch mode; nil when it is not running.
+	watchCancel context.CancelFunc
+
+	// dropped is the ad-hoc batch dropped onto the window, copied by the
+	// next StartCopy instead of the scanned source; nil after ScanFiles.
+	dropped *droppedFiles
+
+	// folders keeps the recent and pinned folders offered by the pickers.
+	folders *recent.Store
+
+	// logs keeps and streams what copies log, for the log panel.
+	logs *logging.Feed
+
+	// tray is the system tray icon; quitting is set by its Quit, which
+	// closes the window even when closing hides it to the tray.
+	tray     *tray
+	quitting atomic.Bool
+
+	// jobs runs the copy jobs lined up with EnqueueJob.
+	jobs *queue.Queue
+
+	// lastVerify is the report of the last VerifyDestination, kept for
+	// ExportVerifyReport.
+	lastVerify *copier.VerifyReport
+
 	// cancelFunc allows us to cancel ongoing copy operations.
 	// This is essential for providing a responsive UI where users can stop
 	// long-running tasks without waiting for completion.
@@ -38,20 +95,108 @@ func NewApp() *App {
 // This is the first lifecycle hook where we have access to Wails runtime.
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
-	a.config = config.DefaultConfig()
+	cfg := config.DefaultConfig()
 
 	// Attempt to load config from file on startup.
-	// We silently ignore errors here because the app should still work
-	// with default config if no config file exists.
-	if loadedCfg, err := config.LoadFromFile("config.yaml"); err == nil {
-		a.config = loadedCfg
+	// The app should still work with default config if no config file
+	// exists; other errors are kept for GetConfigProblems.
+	a.configPath = config.Locate("")
+	loadedCfg, err := config.LoadFromFile(a.configPath)
+	if loadedCfg != nil {
+		// Unknown keys still return the recognized settings.
+		cfg = loadedCfg
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	a.setConfig(cfg, err)
+	a.restoreWindow(ctx)
+	if a.configPath != "" {
+		a.watchConfig()
+	}
+	a.folders = recent.NewStore(a.dataPath(recent.FileName))
+	a.startLogs()
+	a.startScheduler()
+	a.startQueue()
+	go a.startCardWatch()
+	go a.watchUpdates()
+	a.startTray()
+	runtime.OnFileDrop(ctx, func(x, y int, paths []string) {
+		a.handleDrop(paths)
+	})
+}
+
+// shutdown is called when the app is closing, after the window is gone.
+func (a *App) shutdown(ctx context.Context) {
+	a.stopTray()
+}
+
+// currentConfig returns the config in use. It is replaced rather than
+// modified, so it stays the same for as long as the caller uses it.
+func (a *App) currentConfig() *config.Config {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.config
+}
+
+// catalog returns the messages in the configured language.
+func (a *App) catalog() *i18n.Catalog {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.tr
+}
+
+// setConfig makes cfg the config in use, with loadErr as the problems of
+// its file.
+func (a *App) setConfig(cfg *config.Config, loadErr error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.replaceConfig(cfg)
+	a.loadErr = loadErr
+}
+
+// updateConfig applies change to a copy of the config in use, which it
+// replaces unless change fails, and returns the copy. Changes are applied
+// one at a time, so none is lost to another made meanwhile; change must
+// not call the accessors of the config.
+func (a *App) updateConfig(change func(next *config.Config) error) (*config.Config, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	next := cloneConfig(a.config)
+	if err := change(next); err != nil {
+		return nil, err
+	}
+	a.replaceConfig(next)
+	return next, nil
+}
+
+// replaceConfig makes cfg the config in use; a.mu must be held.
+func (a *App) replaceConfig(cfg *config.Config) {
+	if a.tr == nil || a.config == nil || cfg.Language != a.config.Language {
+		a.tr = i18n.New(i18n.Resolve(cfg.Language))
+	}
+	a.config = cfg
+}
+
+// cloneConfig returns a copy of c whose groups and profiles can be
+// changed without affecting c.
+func cloneConfig(c *config.Config) *config.Config {
+	next := *c
+	if c.Groups != nil {
+		next.Groups = make([]config.CopyGroup, len(c.Groups))
+	}
+	for i, g := range c.Groups {
+		g.Destinations = append([]config.Destination(nil), g.Destinations...)
+		next.Groups[i] = g
 	}
+	next.Profiles = maps.Clone(c.Profiles)
+	return &next
 }
 
 // GetConfig returns the current configuration.
 // The frontend uses this to populate the settings UI on load.
 func (a *App) GetConfig() *config.Config {
-	return a.config
+	return a.currentConfig()
 }
 
 // UpdateConfig updates the application configuration.
@@ -61,21 +206,132 @@ func (a *App) UpdateConfig(cfg *config.Config) error {
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
-	a.config = cfg
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.replaceConfig(cfg)
 	return nil
 }
 
-// SaveConfig persists the current configuration to a YAML file.
+// GetConfigProblems returns the problems found in config.yaml at startup,
+// such as misspelled keys, so the frontend can list them with their hints.
+func (a *App) GetConfigProblems() []config.Problem {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return config.Problems(a.loadErr)
+}
+
+// ValidateConfig checks cfg without applying it and returns every problem,
+// so the settings form can flag each field before the user saves.
+func (a *App) ValidateConfig(cfg *config.Config) []config.Problem {
+	check := *cfg
+	return config.Problems(check.Validate())
+}
+
+// GetTranslations returns the UI messages for the configured language
+// (or the OS language), keyed like "app.scan_first". The frontend calls it
+// on load and again after the language setting changes.
+func (a *App) GetTranslations() map[string]string {
+	return a.catalog().Messages()
+}
+
+// ListProfiles returns the names of the saved profiles, sorted.
+func (a *App) ListProfiles() []string {
+	return a.currentConfig().ProfileNames()
+}
+
+// SelectProfile applies the named profile to the current configuration and
+// returns the updated config so the UI can refresh its fields. Like
+// UpdateConfig, the change is kept in memory until SaveConfig is called.
+func (a *App) SelectProfile(name string) (*config.Config, error) {
+	cfg, err := a.updateConfig(func(next *config.Config) error {
+		return next.ApplyProfile(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.copier = nil // the scanned file list belongs to the previous source
+	return cfg, nil
+}
+
+// SaveProfile stores the current source/destination settings under name.
+func (a *App) SaveProfile(name string) error {
+	_, err := a.updateConfig(func(next *config.Config) error {
+		return next.SaveProfile(name)
+	})
+	return err
+}
+
+// DeleteProfile removes the named profile.
+func (a *App) DeleteProfile(name string) error {
+	_, err := a.updateConfig(func(next *config.Config) error {
+		if !next.DeleteProfile(name) {
+			return fmt.Errorf("profile %q not found", name)
+		}
+		return nil
+	})
+	return err
+}
+
+// SaveConfig persists the current configuration to the file it was loaded
+// from, or to the user config directory when there was none.
 // This ensures user preferences survive app restarts.
 func (a *App) SaveConfig() error {
-	return a.config.SaveToFile("config.yaml")
+	cfg := a.currentConfig()
+	if a.configPath == "" {
+		path, err := cfg.SaveToDefault()
+		if err != nil {
+			return err
+		}
+		a.configPath = path
+		a.watchConfig()
+	} else if err := cfg.SaveToFile(a.configPath); err != nil {
+		return err
+	}
+	// The file now holds exactly the current settings, so problems found
+	// at startup (e.g. unknown keys) no longer apply.
+	a.mu.Lock()
+	a.loadErr = nil
+	a.mu.Unlock()
+	a.refreshSchedule()
+	a.refreshTray()
+	return nil
+}
+
+// ExportConfig writes the full configuration (groups, filters and profiles)
+// to path so it can be shared with other machines. The format follows the
+// file extension (.yaml, .json or .toml).
+func (a *App) ExportConfig(path string) error {
+	if path == "" {
+		return fmt.Errorf("export path is required")
+	}
+	return a.currentConfig().SaveToFile(path)
+}
+
+// ImportConfig merges a settings bundle written by ExportConfig into the
+// current configuration (see config.Merge) and returns the result. A bundle
+// with unknown keys, or one that would leave the config invalid, is
+// rejected and the current config is left untouched. Call SaveConfig to
+// keep the imported settings.
+func (a *App) ImportConfig(path string) (*config.Config, error) {
+	bundle, err := config.LoadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.updateConfig(func(next *config.Config) error {
+		next.Merge(bundle)
+		if err := next.Validate(); err != nil {
+			return fmt.Errorf("invalid settings bundle: %w", err)
+		}
+		return nil
+	})
 }
 
 // SelectSourceFolder opens a native directory picker dialog for source folder.
 // Using native dialogs provides a familiar experience and respects OS accessibility settings.
 func (a *App) SelectSourceFolder() (string, error) {
 	folder, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select Source Folder",
+		Title: a.catalog().T("app.select_source"),
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to open directory dialog: %w", err)
@@ -86,7 +342,7 @@ func (a *App) SelectSourceFolder() (string, error) {
 // SelectDestFolder opens a native directory picker dialog for destination folder.
 func (a *App) SelectDestFolder() (string, error) {
 	folder, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select Destination Folder",
+		Title: a.catalog().T("app.select_destination"),
 	})
 	if err != nil {
 		return "", fmt.Errorf("failed to open directory dialog: %w", err)
@@ -98,12 +354,22 @@ func (a *App) SelectDestFolder() (string, error) {
 // This is separated from the copy operation so the UI can show a preview
 // of how many files will be copied before the user commits.
 func (a *App) ScanFiles() ([]string, error) {
-	if a.config.Source == "" {
+	cfg := a.currentConfig()
+	if cfg.Source == "" {
 		return nil, fmt.Errorf("source path is not configured")
 	}
 
-	a.copier = copier.New(a.config)
-	files, err := a.copier.GetFiles()
+	a.dropped = nil
+	a.copier = copier.New(cfg)
+
+	// CancelCopy also stops a scan of a huge source.
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelFunc = cancel
+	defer func() {
+		cancel()
+		a.cancelFunc = nil
+	}()
+	files, err := a.copier.GetFilesContext(a.scanning(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan files: %w", err)
 	}
@@ -120,38 +386,137 @@ type ProgressEvent struct {
 	Percent  float64 `json:"percent"`
 	FileName string  `json:"fileName"`
 	Status   string  `json:"status"` // "copying", "success", "failed", "skipped"
+	// Action is what a dry-run found the copy would do: "copy",
+	// "overwrite", "skip" or "conflict".
+	Action string `json:"action,omitempty"`
+	// Error is why the file failed, and ErrorCode its kind, e.g.
+	// "locked" or "no-space", for the frontend to branch on.
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+func newProgressEvent(f copier.FileFinished) ProgressEvent {
+	ev := ProgressEvent{
+		Current:  f.Current,
+		Total:    f.Total,
+		Percent:  f.Percent(),
+		FileName: f.Result.FileName,
+		Status:   f.Status,
+		Action:   f.Result.Action,
+	}
+	if err := f.Result.Error; err != nil {
+		ev.Error, ev.ErrorCode = err.Error(), copier.ErrorCode(err)
+	}
+	return ev
+}
+
+// emitProgress returns an event handler emitting the copy:progress event
+// for every file finished.
+func (a *App) emitProgress(ev copier.Event) {
+	if f, ok := ev.(copier.FileFinished); ok {
+		runtime.EventsEmit(a.ctx, "copy:progress", newProgressEvent(f))
+	}
+}
+
+// ScanProgress is sent with the scan:progress event while a source is
+// scanned, so the frontend can show a slow share is still being read.
+type ScanProgress struct {
+	Dir     string `json:"dir"`
+	Entries int    `json:"entries"` // entries read so far
+	Files   int    `json:"files"`   // files to copy among them
+	Done    bool   `json:"done"`
+}
+
+// scanning returns ctx making the scans under it emit scan:progress.
+func (a *App) scanning(ctx context.Context) context.Context {
+	return copier.WithScanProgress(ctx, func(p copier.ScanProgress) {
+		runtime.EventsEmit(a.ctx, "scan:progress", ScanProgress{Dir: p.Dir, Entries: p.Entries, Files: p.Files, Done: p.Done})
+	})
 }
 
 // CopyResult represents the final result of a copy operation.
 // This provides a summary for the UI to display completion statistics.
 type CopyResult struct {
-	Success     bool     `json:"success"`
-	Message     string   `json:"message"`
-	TotalFiles  int      `json:"totalFiles"`
-	Successful  int      `json:"successful"`
-	Failed      int      `json:"failed"`
-	Skipped     int      `json:"skipped"`
-	FailedFiles []string `json:"failedFiles"`
-	Duration    float64  `json:"duration"` // in seconds
+	Success    bool   `json:"success"`
+	Message    string `json:"message"`
+	TotalFiles int    `json:"totalFiles"`
+	Successful int    `json:"successful"`
+	Failed     int    `json:"failed"`
+	Skipped    int    `json:"skipped"`
+	// FailedFiles are the files that failed, with why, for retrying them
+	// or exporting the list.
+	FailedFiles []copier.FailedFile `json:"failedFiles"`
+	Corrupt     int                 `json:"corrupt"`  // failed files that are corrupt images
+	Duration    float64             `json:"duration"` // in seconds
+	// TotalBytes were written, OverwrittenBytes replaced and SkippedBytes
+	// left alone; BytesPerSecond is the speed of the copy.
+	TotalBytes       int64   `json:"totalBytes"`
+	OverwrittenBytes int64   `json:"overwrittenBytes"`
+	SkippedBytes     int64   `json:"skippedBytes"`
+	BytesPerSecond   float64 `json:"bytesPerSecond"`
+}
+
+// PrepareCopy works out what StartCopy(overwrite) would do without copying:
+// the files to copy, overwrite and skip, their size and the free space at
+// the destination, so the frontend can confirm with real numbers.
+func (a *App) PrepareCopy(overwrite bool) (copier.Plan, error) {
+	if a.copier == nil {
+		return copier.Plan{}, errors.New(a.catalog().T("app.scan_first"))
+	}
+
+	cfg := *a.currentConfig()
+	cfg.Overwrite = overwrite
+	var c *copier.Copier
+	var files []string
+	var err error
+	if a.dropped != nil {
+		c = copier.New(a.dropped.config(&cfg))
+		files = a.dropped.files
+	} else {
+		c = copier.New(&cfg)
+		files, err = c.GetFilesContext(a.scanning(a.ctx))
+	}
+	if err != nil {
+		return copier.Plan{}, errors.New(a.catalog().T("app.scan_failed", err))
+	}
+	if err := c.Err(); err != nil {
+		return copier.Plan{}, errors.New(a.catalog().T("app.destination_unavailable", err))
+	}
+	// The manifest is only read, to skip unchanged files.
+	_ = a.useManifest(c)
+	return c.Plan(files), nil
 }
 
 // StartCopy begins the file copy operation.
 // It creates a cancellable context so users can stop the operation mid-way.
 // Progress updates are emitted as events to keep the UI responsive.
 func (a *App) StartCopy(overwrite bool) CopyResult {
+	return a.startCopy(overwrite, history.KindCopy)
+}
+
+// startCopy is StartCopy with the run recorded in history as kind.
+func (a *App) startCopy(overwrite bool, kind string) CopyResult {
 	if a.copier == nil {
 		return CopyResult{
 			Success: false,
-			Message: "Please scan files first",
+			Message: a.catalog().T("app.scan_first"),
 		}
 	}
 
 	// Update the overwrite setting based on user choice
-	a.config.Overwrite = overwrite
+	current, _ := a.updateConfig(func(next *config.Config) error {
+		next.Overwrite = overwrite
+		return nil
+	})
 
 	// Re-initialize copier with the latest config
 	// This ensures we use the current settings (especially if DryRun was toggled)
-	a.copier = copier.New(a.config)
+	cfg := current
+	if a.dropped != nil {
+		cfg = a.dropped.config(current)
+	}
+	a.copier = copier.New(cfg)
+	a.logTo(a.copier)
 
 	// Create a cancellable context for this copy operation.
 	// This allows users to stop long-running copies without closing the app.
@@ -161,40 +526,88 @@ func (a *App) StartCopy(overwrite bool) CopyResult {
 		a.cancelFunc = nil
 	}()
 
-	// Get files to copy
-	files, err := a.copier.GetFiles()
+	// Get files to copy: the dropped batch, or the files in the source.
+	var files []string
+	var err error
+	if a.dropped != nil {
+		files = a.dropped.files
+	} else {
+		files, err = a.copier.GetFilesContext(a.scanning(ctx))
+	}
 	if err != nil {
 		return CopyResult{
 			Success: false,
-			Message: fmt.Sprintf("Failed to get files: %v", err),
+			Message: a.catalog().T("app.scan_failed", err),
 		}
 	}
 
 	if len(files) == 0 {
 		return CopyResult{
 			Success: true,
-			Message: "No files found to copy",
+			Message: a.catalog().T("app.no_files"),
 		}
 	}
 
+	if err := a.copier.Err(); err != nil {
+		return CopyResult{
+			Success: false,
+			Message: a.catalog().T("app.destination_unavailable", err),
+		}
+	}
+
+	// pre_copy runs before the lock so it can mount the destination.
+	hk := hooks.New(current)
+	batch := hooks.Batch{Kind: kind, Copier: a.copier, Files: len(files)}
+	if err := hk.Before(ctx, batch); err != nil {
+		a.warnHook(hk.Aborted(ctx, batch, err))
+		return CopyResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	// Refuse to interleave writes with another session (CLI, scheduled task
+	// or a second machine) copying into the same destination.
+	if !current.DryRun {
+		lease, err := lock.Acquire(ctx, current.LockDir(), lock.Options{})
+		if err != nil {
+			return CopyResult{
+				Success: false,
+				Message: a.catalog().T("app.destination_busy", err),
+			}
+		}
+		defer func() { _ = lease.Release() }()
+	}
+
+	a.useFolder(recent.Source, cfg.Source)
+	a.useFolder(recent.Destination, current.Destination)
+
 	// Emit initial progress
 	runtime.EventsEmit(a.ctx, "copy:start", map[string]any{
 		"total": len(files),
 	})
 
 	// Create a new copier with event emitting capability
-	summary := a.copier.CopyFilesParallelWithEvents(ctx, files, func(current int, total int, fileName string, status string) {
-		// Emit progress event to frontend
-		runtime.EventsEmit(a.ctx, "copy:progress", ProgressEvent{
-			Current:  current,
-			Total:    total,
-			Percent:  float64(current) / float64(total) * 100,
-			FileName: fileName,
-			Status:   status,
-		})
-	})
+	a.notifyStart(kind, "", a.copier, len(files))
+	start := time.Now()
+	save := a.useManifest(a.copier)
+	events, wait := copier.Dispatch(a.emitProgress)
+	summary := a.copier.CopyFilesParallelWithEvents(ctx, files, events)
+	wait()
+
+	save()
+	a.warnHook(hk.After(ctx, batch, summary, ctx.Err() != nil))
+	a.recordRun(kind, "", a.copier, start, summary, ctx.Err() != nil)
+	result := a.copyResult(summary)
+
+	// Emit completion event
+	runtime.EventsEmit(a.ctx, "copy:complete", result)
+
+	return result
+}
 
-	// Build result
+// copyResult converts a copy summary into the result shown by the frontend.
+func (a *App) copyResult(summary copier.CopySummary) CopyResult {
 	result := CopyResult{
 		Success:     summary.Failed == 0,
 		TotalFiles:  summary.TotalFiles,
@@ -202,25 +615,30 @@ func (a *App) StartCopy(overwrite bool) CopyResult {
 		Failed:      summary.Failed,
 		Skipped:     summary.Skipped,
 		FailedFiles: summary.FailedFiles,
+		Corrupt:     summary.Corrupt,
 		Duration:    summary.Duration.Seconds(),
+
+		TotalBytes:       summary.TotalBytes,
+		OverwrittenBytes: summary.OverwrittenBytes,
+		SkippedBytes:     summary.SkippedBytes,
+		BytesPerSecond:   summary.Throughput(),
+	}
+	if result.FailedFiles == nil {
+		result.FailedFiles = []copier.FailedFile{}
 	}
 
 	if summary.Failed > 0 {
-		result.Message = fmt.Sprintf("Completed with %d errors", summary.Failed)
+		result.Message = a.catalog().T("app.copy_errors", summary.Failed)
 	} else {
-		result.Message = fmt.Sprintf("Successfully copied %d files", summary.Successful)
+		result.Message = a.catalog().T("app.copy_done", summary.Successful)
 	}
-
-	// Emit completion event
-	runtime.EventsEmit(a.ctx, "copy:complete", result)
-
 	return result
 }
 
 // CancelCopy stops an ongoing copy operation.
 // This is called when the user clicks the cancel button.
-// The cancellation is graceful - in-progress file copies may complete,
-// but no new files will start copying.
+// Files being copied stop within a moment and their partial copies are
+// removed; no new files start copying.
 func (a *App) CancelCopy() {
 	if a.cancelFunc != nil {
 		a.cancelFunc()
@@ -0,0 +1,89 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"copy-image/internal/history"
+	"copy-image/internal/removable"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// CardInserted is sent with the card:inserted event when an inserted drive
+// matches a card profile. Unless AutoStart is set, the import waits for
+// the frontend to call ImportCard.
+type CardInserted struct {
+	Drive     removable.Drive `json:"drive"`
+	Profile   string          `json:"profile"`
+	GroupID   string          `json:"groupId"`
+	AutoStart bool            `json:"autoStart"`
+}
+
+// CardImport is sent with the card:done event after a card was imported.
+type CardImport struct {
+	Path    string     `json:"path"`
+	GroupID string     `json:"groupId"`
+	Result  CopyResult `json:"result"`
+}
+
+// startCardWatch watches for inserted cards for as long as the app is
+// open. Without it the app works as before, so a failure is only logged.
+func (a *App) startCardWatch() {
+	if err := removable.Watch(a.ctx, a.cardInserted); err != nil {
+		runtime.LogWarningf(a.ctx, "cards: %v", err)
+	}
+}
+
+// cardInserted looks for the card profile of a new drive.
+func (a *App) cardInserted(d removable.Drive) {
+	p := a.currentConfig().MatchCard(d.Label, d.HasDCIM())
+	if p == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, "card:inserted", CardInserted{
+		Drive:     d,
+		Profile:   p.Name,
+		GroupID:   p.Group,
+		AutoStart: p.AutoStart,
+	})
+	// Cards inserted one after another are imported in turn.
+	if p.AutoStart {
+		if _, err := a.EnqueueJob(JobSpec{Kind: JobCard, Path: d.Path, Profile: p.Name}); err != nil {
+			runtime.LogWarningf(a.ctx, "cards: %v", err)
+		}
+	}
+}
+
+// ImportCard runs the group of the named card profile with the card
+// mounted at path as its source, emitting card:done.
+func (a *App) ImportCard(path, profile string) CopyResult {
+	return a.importCard(a.ctx, path, profile)
+}
+
+// importCard is ImportCard stopped by cancelling ctx.
+func (a *App) importCard(ctx context.Context, path, profile string) CopyResult {
+	groupID, result := a.runCard(ctx, path, profile)
+	runtime.EventsEmit(a.ctx, "card:done", CardImport{Path: path, GroupID: groupID, Result: result})
+	return result
+}
+
+func (a *App) runCard(ctx context.Context, path, profile string) (string, CopyResult) {
+	cfg := a.currentConfig()
+	p := cfg.FindCard(profile)
+	if p == nil {
+		return "", CopyResult{Message: fmt.Sprintf("card profile %q not found", profile)}
+	}
+	group, err := cfg.CardGroup(*p, path)
+	if err != nil {
+		return p.Group, CopyResult{Message: err.Error()}
+	}
+
+	summary, err := a.runGroup(ctx, history.KindCard, cfg, group)
+	if err != nil {
+		return group.ID, CopyResult{Message: err.Error()}
+	}
+	return group.ID, a.copyResult(summary.Total())
+}
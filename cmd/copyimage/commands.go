@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"copy-image/internal/config"
+	"copy-image/internal/logging"
+)
+
+// command is a CLI subcommand such as `copyimage scan`.
+type command struct {
+	name        string
+	summary     string
+	subcommands []string // verbs such as `groups run`, used by completion
+	hidden      bool     // internal commands left out of help
+	run         func(ctx context.Context, args []string) int
+}
+
+// commands lists every subcommand in the order shown by `copyimage help`.
+// It is a function rather than a variable because help itself refers to it.
+func commands() []command {
+	return []command{
+		{name: "copy", summary: "Copy files from source to destination (default)", run: runCopyCommand},
+		{name: "scan", summary: "List the files that would be copied", run: runScanCommand},
+		{name: "verify", summary: "Check that every source file exists in the destination", run: runVerifyCommand},
+		{name: "offload", summary: "Copy, verify every file by hash, then optionally seal the source (-seal readonly | marker)", run: runOffloadCommand},
+		{name: "groups", summary: "List or run copy groups (groups list | groups run <id>... | -all)", subcommands: []string{"list", "run"}, run: runGroupsCommand},
+		{name: "watch", summary: "Copy new files as they appear (watch [<id>... | -all])", run: runWatchCommand},
+		{name: "schedule", summary: "Show or run scheduled groups (schedule list | schedule run)", subcommands: []string{"list", "run"}, run: runScheduleCommand},
+		{name: "serve", summary: "Serve a REST API to run groups and follow their progress remotely (serve -listen :8080)", run: runServeCommand},
+		{name: "service", summary: "Run the scheduler as a background service (service install | uninstall | start | stop | status)", subcommands: []string{"install", "uninstall", "start", "stop", "status", "run"}, run: runServiceCommand},
+		{name: "history", summary: "List past runs, or show one (history [<id>])", run: runHistoryCommand},
+		{name: "undo", summary: "Remove the files a past run created (undo <run-id>)", run: runUndoCommand},
+		{name: "version", summary: "Show the version, commit and build date (version [-json])", run: func(_ context.Context, args []string) int {
+			return runVersion(args, os.Stdout)
+		}},
+		{name: "self-update", summary: "Update the CLI to the newest release (self-update [-check] [-channel beta])", run: runSelfUpdateCommand},
+		{name: "rollback", summary: "Put back the version the last update replaced; run again to undo", run: runRollbackCommand},
+		{name: "config", summary: "Show or change settings (config show | get <key> | set <key> <value>)", subcommands: []string{"show", "get", "set"}, run: runConfigCommand},
+		{name: "rename", summary: "Apply a rename template to an existing folder", run: func(_ context.Context, args []string) int {
+			return runRename(args, os.Stdout)
+		}},
+		{name: "completion", summary: "Print a shell completion script (bash | zsh | powershell)", run: runCompletionCommand},
+		{name: "help", summary: "Show this help", run: func(_ context.Context, args []string) int {
+			if code, ok := parseFlags(flag.NewFlagSet("help", flag.ContinueOnError), args); !ok {
+				return code
+			}
+			printUsage()
+			return exitOK
+		}},
+		{name: "__complete", hidden: true, run: runCompleteCommand},
+	}
+}
+
+// run dispatches to a subcommand and returns the process exit code.
+// Without a subcommand (or when the first argument is a flag) it runs
+// `copy`, so existing scripts using plain flags keep working.
+func run(ctx context.Context, args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runCopyCommand(ctx, args)
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			return cmd.run(ctx, args[1:])
+		}
+	}
+
+	fmt.Printf("❌ Unknown command %q\n\n", args[0])
+	printUsage()
+	return exitConfig
+}
+
+// printUsage prints the list of subcommands.
+func printUsage() {
+	fmt.Println("Usage: copyimage <command> [flags]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, cmd := range commands() {
+		if cmd.hidden {
+			continue
+		}
+		fmt.Printf("  %-12s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Println()
+	fmt.Println("Run 'copyimage <command> -h' for the flags of a command.")
+	fmt.Println("Add -no-update-check to any command to skip looking for a newer release.")
+}
+
+// commonFlags are the flags shared by the commands that read the config.
+type commonFlags struct {
+	configFile *string
+	profile    *string
+	sources    *stringList
+	dest       *string
+	ext        *string
+	output     *string
+	verbosity  *int
+}
+
+// addCommonFlags registers the shared flags on fs.
+func addCommonFlags(fs *flag.FlagSet) *commonFlags {
+	return &commonFlags{
+		configFile: fs.String("config", config.FileName, "Path to config file (default: search the current, user config and program folders)"),
+		profile:    fs.String("profile", "", "Use a named profile from the config file"),
+		sources:    addSourceFlag(fs),
+		dest:       fs.String("dest", "", "Destination directory path"),
+		ext:        fs.String("ext", "", "Comma-separated list of extensions to include (e.g., .jpg,.png)"),
+		output:     fs.String("output", "plain", "Output format: plain, json or ndjson"),
+		verbosity:  addVerbosityFlags(fs),
+	}
+}
+
+// load selects the output mode and loads the configuration with the
+// flag overrides applied. It prints the error itself and returns false
+// when the command should stop with exitConfig.
+func (f *commonFlags) load() (*config.Config, bool) {
+	mode, err := parseOutputMode(*f.output)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, false
+	}
+	ui = newReporter(mode, os.Stdout)
+	ui.setVerbosity(*f.verbosity, os.Stderr)
+
+	cfg, err := loadProfileConfig(*f.configFile, *f.profile, *f.dest, false, 10, false, *f.ext)
+	if err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return nil, false
+	}
+	f.sources.apply(cfg)
+	return cfg, true
+}
+
+// addVerbosityFlags registers -q/-quiet, -v and -vv on fs and returns the
+// selected logging verbosity. -v may be repeated (-v -v equals -vv).
+func addVerbosityFlags(fs *flag.FlagSet) *int {
+	level := new(int)
+	quiet := func(string) error {
+		*level = logging.Quiet
+		return nil
+	}
+	fs.BoolFunc("q", "Quiet: only print errors and the final summary", quiet)
+	fs.BoolFunc("quiet", "Same as -q", quiet)
+	fs.BoolFunc("v", "Verbose: print a line per file", func(string) error {
+		*level = max(*level, logging.Normal) + 1
+		return nil
+	})
+	fs.BoolFunc("vv", "Very verbose: also print retry details", func(string) error {
+		*level = logging.Debug
+		return nil
+	})
+	return level
+}
+
+// stringList is a flag that may be repeated, e.g. -source a -source b.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// addSourceFlag registers the repeatable -source flag on fs.
+func addSourceFlag(fs *flag.FlagSet) *stringList {
+	sources := &stringList{}
+	fs.Var(sources, "source", "Source `directory` or glob pattern (e.g. D:\\Camera\\2024-*); repeat to merge several")
+	return sources
+}
+
+// apply replaces the configured sources when -source was given, so the
+// extra `sources:` of config.yaml do not sneak into an explicit run.
+func (s *stringList) apply(cfg *config.Config) {
+	if len(*s) == 0 {
+		return
+	}
+	cfg.Source = (*s)[0]
+	cfg.Sources = append([]string(nil), (*s)[1:]...)
+}
+
+// parseFlags parses args with fs and reports the exit code to use when
+// parsing fails (help is not an error).
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if flagSetProbe != nil {
+		flagSetProbe(fs)
+		return exitOK, false
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitConfig, false
+	}
+	return exitOK, true
+}
+
+// parseInterspersed parses flags that may appear before or after
+// positional arguments (e.g. `groups run nightly -dry-run`), which the
+// standard flag package does not support on its own.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, int, bool) {
+	var positional []string
+	for {
+		if code, ok := parseFlags(fs, args); !ok {
+			return nil, code, false
+		}
+		if fs.NArg() == 0 {
+			return positional, exitOK, true
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/history"
+	"copy-image/internal/manifest"
+)
+
+// writeGroupConfig creates a source with two images and a config file
+// defining one group that copies them to dst.
+func writeGroupConfig(t *testing.T) (cfgPath, src, dst string) {
+	t.Helper()
+	dir := t.TempDir()
+	src = filepath.Join(dir, "src")
+	dst = filepath.Join(dir, "dst")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.AddGroup(config.CopyGroup{
+		ID:           "nightly",
+		Name:         "Nightly backup",
+		Source:       src,
+		Enabled:      true,
+		Destinations: []config.Destination{{ID: "d1", Path: dst, Enabled: true}},
+	})
+	cfgPath = filepath.Join(dir, "config.yaml")
+	if err := cfg.SaveToFile(cfgPath); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	return cfgPath, src, dst
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	if code := run(context.Background(), []string{"frobnicate"}); code != exitConfig {
+		t.Errorf("Expected exit code %d, got %d", exitConfig, code)
+	}
+}
+
+func TestRunHelp(t *testing.T) {
+	if code := run(context.Background(), []string{"help"}); code != exitOK {
+		t.Errorf("Expected exit code %d, got %d", exitOK, code)
+	}
+}
+
+func TestScanCommand(t *testing.T) {
+	_, src, _ := writeGroupConfig(t)
+
+	code := run(context.Background(), []string{"scan", "-config", "", "-source", src, "-output", "json"})
+	if code != exitOK {
+		t.Errorf("Expected exit code %d, got %d", exitOK, code)
+	}
+
+	code = run(context.Background(), []string{"scan", "-config", "", "-source", "/non/existent"})
+	if code != exitConfig {
+		t.Errorf("Expected exit code %d for missing source, got %d", exitConfig, code)
+	}
+}
+
+func TestVerifyCommand(t *testing.T) {
+	_, src, dst := writeGroupConfig(t)
+	args := []string{"verify", "-config", "", "-source", src, "-dest", dst, "-output", "ndjson"}
+
+	// Nothing copied yet: every file is missing.
+	if code := run(context.Background(), args); code != exitPartial {
+		t.Errorf("Expected exit code %d before copy, got %d", exitPartial, code)
+	}
+
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		t.Fatalf("Failed to create destination: %v", err)
+	}
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		if err := os.WriteFile(filepath.Join(dst, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create destination file: %v", err)
+		}
+	}
+	if code := run(context.Background(), args); code != exitOK {
+		t.Errorf("Expected exit code %d after copy, got %d", exitOK, code)
+	}
+
+	// A size difference is reported as a mismatch.
+	if err := os.WriteFile(filepath.Join(dst, "a.jpg"), []byte("truncated content"), 0644); err != nil {
+		t.Fatalf("Failed to modify destination file: %v", err)
+	}
+	if code := run(context.Background(), args); code != exitPartial {
+		t.Errorf("Expected exit code %d for mismatch, got %d", exitPartial, code)
+	}
+
+	// Same size, different content: only -hash notices.
+	if err := os.WriteFile(filepath.Join(dst, "a.jpg"), []byte("x.jpg"), 0644); err != nil {
+		t.Fatalf("Failed to modify destination file: %v", err)
+	}
+	if code := run(context.Background(), args); code != exitOK {
+		t.Errorf("Expected exit code %d comparing sizes, got %d", exitOK, code)
+	}
+	report := filepath.Join(t.TempDir(), "diff.csv")
+	if code := run(context.Background(), append(args, "-hash", "-report", report)); code != exitPartial {
+		t.Errorf("Expected exit code %d comparing hashes, got %d", exitPartial, code)
+	}
+	if data, err := os.ReadFile(report); err != nil || !strings.Contains(string(data), "mismatched,a.jpg") {
+		t.Errorf("Expected the report to list a.jpg, got %q, %v", data, err)
+	}
+}
+
+func TestGroupsCommands(t *testing.T) {
+	cfgPath, _, dst := writeGroupConfig(t)
+
+	if code := run(context.Background(), []string{"groups", "list", "-config", cfgPath}); code != exitOK {
+		t.Errorf("groups list: expected exit code %d, got %d", exitOK, code)
+	}
+
+	// Flags after the positional group ID must still be honored.
+	code := run(context.Background(), []string{"groups", "run", "nightly", "-config", cfgPath, "-output", "ndjson", "-dry-run"})
+	if code != exitOK {
+		t.Errorf("groups run -dry-run: expected exit code %d, got %d", exitOK, code)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "a.jpg")); !os.IsNotExist(err) {
+		t.Error("Dry-run must not copy files")
+	}
+
+	code = run(context.Background(), []string{"groups", "run", "-config", cfgPath, "-output", "ndjson", "nightly"})
+	if code != exitOK {
+		t.Errorf("groups run: expected exit code %d, got %d", exitOK, code)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "a.jpg")); err != nil {
+		t.Errorf("Expected group run to copy files: %v", err)
+	}
+
+	if code := run(context.Background(), []string{"groups", "run", "missing", "-config", cfgPath, "-output", "ndjson"}); code != exitConfig {
+		t.Errorf("Expected exit code %d for unknown group, got %d", exitConfig, code)
+	}
+	if code := run(context.Background(), []string{"groups"}); code != exitConfig {
+		t.Errorf("Expected exit code %d without groups subcommand, got %d", exitConfig, code)
+	}
+	if code := run(context.Background(), []string{"groups", "run", "-config", cfgPath}); code != exitConfig {
+		t.Errorf("Expected exit code %d without group IDs or -all, got %d", exitConfig, code)
+	}
+	if code := run(context.Background(), []string{"groups", "run", "-all", "-config", cfgPath, "-output", "ndjson"}); code != exitOK {
+		t.Errorf("groups run -all: expected exit code %d, got %d", exitOK, code)
+	}
+}
+
+func TestConfigCommands(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	// set creates the file from defaults when it does not exist yet.
+	if code := run(context.Background(), []string{"config", "set", "workers", "4", "-config", cfgPath}); code != exitOK {
+		t.Fatalf("config set: expected exit code %d, got %d", exitOK, code)
+	}
+	cfg, err := config.LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load saved config: %v", err)
+	}
+	if cfg.Workers != 4 {
+		t.Errorf("Expected workers=4, got %d", cfg.Workers)
+	}
+
+	if code := run(context.Background(), []string{"config", "get", "workers", "-config", cfgPath}); code != exitOK {
+		t.Errorf("config get: expected exit code %d, got %d", exitOK, code)
+	}
+	if code := run(context.Background(), []string{"config", "show", "-config", cfgPath}); code != exitOK {
+		t.Errorf("config show: expected exit code %d, got %d", exitOK, code)
+	}
+	if code := run(context.Background(), []string{"config", "set", "worker", "4", "-config", cfgPath}); code != exitConfig {
+		t.Errorf("Expected exit code %d for unknown key, got %d", exitConfig, code)
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	dry := fs.Bool("dry-run", false, "")
+	name := fs.String("name", "", "")
+
+	positional, _, ok := parseInterspersed(fs, []string{"-name", "x", "one", "-dry-run", "two"})
+	if !ok {
+		t.Fatal("Expected parsing to succeed")
+	}
+	if len(positional) != 2 || positional[0] != "one" || positional[1] != "two" {
+		t.Errorf("Unexpected positional args: %v", positional)
+	}
+	if !*dry || *name != "x" {
+		t.Errorf("Expected flags to be parsed, got dry=%v name=%q", *dry, *name)
+	}
+}
+
+func TestRepeatedSourceFlag(t *testing.T) {
+	_, src, dst := writeGroupConfig(t)
+	other := t.TempDir()
+	if err := os.WriteFile(filepath.Join(other, "c.jpg"), []byte("c"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	code := run(context.Background(), []string{"-yes", "-output", "ndjson", "-config", "", "-source", src, "-source", other, "-dest", dst})
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	for _, name := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err != nil {
+			t.Errorf("Expected %s to be copied: %v", name, err)
+		}
+	}
+}
+
+func TestProfileFlag(t *testing.T) {
+	_, src, dst := writeGroupConfig(t)
+	cfg := config.DefaultConfig()
+	cfg.Source = src
+	cfg.Destination = dst
+	if err := cfg.SaveProfile("card-to-nas"); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+	cfg.Source, cfg.Destination = "", ""
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := cfg.SaveToFile(cfgPath); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	code := run(context.Background(), []string{"-yes", "-output", "ndjson", "-config", cfgPath, "-profile", "card-to-nas"})
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "a.jpg")); err != nil {
+		t.Errorf("Expected the profile's destination to receive files: %v", err)
+	}
+
+	// Flags still override the profile.
+	other := t.TempDir()
+	code = run(context.Background(), []string{"scan", "-output", "ndjson", "-config", cfgPath, "-profile", "card-to-nas", "-source", other})
+	if code != exitOK {
+		t.Errorf("Expected exit code %d, got %d", exitOK, code)
+	}
+
+	code = run(context.Background(), []string{"-yes", "-output", "ndjson", "-config", cfgPath, "-profile", "missing"})
+	if code != exitConfig {
+		t.Errorf("Expected exit code %d for unknown profile, got %d", exitConfig, code)
+	}
+}
+
+func TestWatchCommand(t *testing.T) {
+	cfgPath, src, dst := writeGroupConfig(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	codes := make(chan int, 1)
+	go func() {
+		codes <- run(ctx, []string{"watch", "nightly", "-config", cfgPath, "-output", "ndjson", "-debounce", "20ms", "-settle", "50ms"})
+	}()
+
+	// Existing files are left alone; only new ones are copied.
+	time.Sleep(200 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(src, "c.jpg"), []byte("c"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(dst, "c.jpg")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the new file to be copied")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	if code := <-codes; code != exitOK {
+		t.Errorf("Expected exit code %d, got %d", exitOK, code)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "a.jpg")); !os.IsNotExist(err) {
+		t.Error("Expected existing files not to be copied")
+	}
+
+	if code := run(context.Background(), []string{"watch", "missing", "-config", cfgPath, "-output", "ndjson"}); code != exitConfig {
+		t.Errorf("Expected exit code %d for unknown group, got %d", exitConfig, code)
+	}
+}
+
+func TestScheduleCommands(t *testing.T) {
+	cfgPath, _, dst := writeGroupConfig(t)
+	cfg, err := config.LoadFromFile(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.Groups[0].Schedule = "@every 1s"
+	if err := cfg.SaveToFile(cfgPath); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	if code := run(context.Background(), []string{"schedule", "list", "-config", cfgPath, "-output", "json"}); code != exitOK {
+		t.Errorf("schedule list: expected exit code %d, got %d", exitOK, code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	codes := make(chan int, 1)
+	go func() {
+		codes <- run(ctx, []string{"schedule", "run", "-config", cfgPath, "-output", "ndjson"})
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(dst, "a.jpg")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the scheduled group to run")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	cancel()
+	if code := <-codes; code != exitOK {
+		t.Errorf("schedule run: expected exit code %d, got %d", exitOK, code)
+	}
+
+	if code := run(context.Background(), []string{"schedule"}); code != exitConfig {
+		t.Errorf("Expected exit code %d without schedule subcommand, got %d", exitConfig, code)
+	}
+}
+
+func TestHistoryCommand(t *testing.T) {
+	cfgPath, _, _ := writeGroupConfig(t)
+
+	if code := run(context.Background(), []string{"history", "-config", cfgPath, "-output", "json"}); code != exitOK {
+		t.Errorf("Expected exit code %d for an empty history, got %d", exitOK, code)
+	}
+	if code := run(context.Background(), []string{"groups", "run", "-all", "-config", cfgPath, "-output", "ndjson"}); code != exitOK {
+		t.Fatalf("groups run: expected exit code %d, got %d", exitOK, code)
+	}
+
+	runs, err := history.Open(filepath.Join(filepath.Dir(cfgPath), history.FileName)).List(0)
+	if err != nil {
+		t.Fatalf("Failed to read history: %v", err)
+	}
+	if len(runs) == 0 || runs[0].Kind != history.KindGroup || runs[0].GroupID == "" || runs[0].Successful == 0 {
+		t.Fatalf("Expected the group run to be recorded, got %+v", runs)
+	}
+
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"history"}, exitOK},
+		{[]string{"history", "-limit", "1"}, exitOK},
+		{[]string{"history", strconv.FormatUint(runs[0].ID, 10)}, exitOK},
+		{[]string{"history", "999"}, exitConfig},
+		{[]string{"history", "latest"}, exitConfig},
+	}
+	for _, tt := range tests {
+		args := append(tt.args, "-config", cfgPath, "-output", "json")
+		if code := run(context.Background(), args); code != tt.want {
+			t.Errorf("%v: expected exit code %d, got %d", tt.args, tt.want, code)
+		}
+	}
+}
+
+func TestCopyIncremental(t *testing.T) {
+	cfgPath, src, dst := writeGroupConfig(t)
+	args := []string{"-yes", "-output", "ndjson", "-config", cfgPath, "-source", src, "-dest", dst, "-incremental"}
+
+	if code := run(context.Background(), args); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	entries, err := os.ReadDir(filepath.Join(filepath.Dir(cfgPath), manifest.DirName))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected one manifest next to the config file, got %v, %v", entries, err)
+	}
+
+	// Files moved out of the destination are not copied again.
+	if err := os.Remove(filepath.Join(dst, "a.jpg")); err != nil {
+		t.Fatal(err)
+	}
+	if code := run(context.Background(), args); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "a.jpg")); !os.IsNotExist(err) {
+		t.Error("Expected an unchanged file not to be copied again")
+	}
+}
+
+func TestUndoCommand(t *testing.T) {
+	cfgPath, src, dst := writeGroupConfig(t)
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dst, "b.jpg"), []byte("already there"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := run(context.Background(), []string{"-yes", "-output", "ndjson", "-config", cfgPath, "-source", src, "-dest", dst}); code != exitOK {
+		t.Fatalf("copy: expected exit code %d, got %d", exitOK, code)
+	}
+	runs, err := history.Open(filepath.Join(filepath.Dir(cfgPath), history.FileName)).List(1)
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("Expected the copy to be recorded, got %v, %v", runs, err)
+	}
+	id := strconv.FormatUint(runs[0].ID, 10)
+
+	if code := run(context.Background(), []string{"undo", id, "-config", cfgPath, "-output", "json"}); code != exitOK {
+		t.Fatalf("undo: expected exit code %d, got %d", exitOK, code)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "a.jpg")); !os.IsNotExist(err) {
+		t.Error("Expected the copied file to be removed")
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "b.jpg")); err != nil || string(data) != "already there" {
+		t.Errorf("Expected the preexisting file to be kept, got %q, %v", data, err)
+	}
+
+	for _, args := range [][]string{{"undo", id}, {"undo", "999"}, {"undo"}} {
+		if code := run(context.Background(), append(args, "-config", cfgPath, "-output", "json")); code != exitConfig {
+			t.Errorf("%v: expected exit code %d, got %d", args, exitConfig, code)
+		}
+	}
+}
+
+func TestRollbackCommand(t *testing.T) {
+	// The test binary was never updated, so there is nothing to go back to.
+	for _, args := range [][]string{{"rollback"}, {"rollback", "v1.0.0"}} {
+		if code := run(context.Background(), args); code != exitConfig {
+			t.Errorf("%v: expected exit code %d, got %d", args, exitConfig, code)
+		}
+	}
+}
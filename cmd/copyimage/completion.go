@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"copy-image/internal/config"
+)
+
+// completionShells are the shells `copyimage completion` can generate for.
+var completionShells = []string{"bash", "zsh", "powershell"}
+
+// runCompletionCommand implements `copyimage completion <shell>`, which
+// prints a script that wires tab completion to `copyimage __complete`.
+// Candidates are computed by the binary itself, so new flags, groups and
+// config keys complete without regenerating the script.
+func runCompletionCommand(_ context.Context, args []string) int {
+	fs := flag.NewFlagSet("completion", flag.ContinueOnError)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fmt.Printf("Usage: copyimage completion %s\n", strings.Join(completionShells, "|"))
+		return exitConfig
+	}
+
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	if err := writeCompletionScript(os.Stdout, fs.Arg(0), name); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitConfig
+	}
+	return exitOK
+}
+
+// writeCompletionScript writes the completion script for shell. Every
+// script passes the words before the cursor followed by the word being
+// completed prefixed with ":", because some shells (Windows PowerShell)
+// drop empty arguments when calling native programs.
+func writeCompletionScript(w io.Writer, shell, name string) error {
+	fn := "_" + strings.NewReplacer("-", "_", ".", "_").Replace(name)
+
+	var script string
+	switch shell {
+	case "bash":
+		script = `# bash completion for {name}
+# Add to ~/.bashrc:  source <({name} completion bash)
+{fn}() {
+    local IFS=$'\n'
+    COMPREPLY=($({name} __complete "${COMP_WORDS[@]:1:COMP_CWORD-1}" ":${COMP_WORDS[COMP_CWORD]}" 2>/dev/null))
+}
+complete -o default -F {fn} {name}
+`
+	case "zsh":
+		script = `#compdef {name}
+# Add to ~/.zshrc:  source <({name} completion zsh)
+{fn}() {
+    local -a candidates
+    candidates=("${(@f)$({name} __complete "${(@)words[2,CURRENT-1]}" ":${words[CURRENT]}" 2>/dev/null)}")
+    if [[ -n ${candidates[1]} ]]; then
+        compadd -a candidates
+    else
+        _files
+    fi
+}
+compdef {fn} {name}
+`
+	case "powershell":
+		script = `# PowerShell completion for {name}
+# Add to $PROFILE:  {name} completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName '{name}', '{name}.exe' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $start = $cursorPosition - $wordToComplete.Length
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 |
+        Where-Object { $_.Extent.EndOffset -lt $start } |
+        ForEach-Object { $_.ToString() })
+    & '{name}' __complete @words ":$wordToComplete" 2>$null | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`
+	default:
+		return fmt.Errorf("unsupported shell %q (expected %s)", shell, strings.Join(completionShells, ", "))
+	}
+
+	script = strings.NewReplacer("{name}", name, "{fn}", fn).Replace(script)
+	_, err := io.WriteString(w, script)
+	return err
+}
+
+// runCompleteCommand implements the hidden `copyimage __complete` command
+// called by the completion scripts. It prints one candidate per line and
+// prints nothing when the shell should fall back to file names.
+func runCompleteCommand(_ context.Context, args []string) int {
+	if len(args) == 0 {
+		return exitOK
+	}
+	words := args[:len(args)-1]
+	current := strings.TrimPrefix(args[len(args)-1], ":")
+
+	for _, c := range completeWords(words, current) {
+		fmt.Println(c)
+	}
+	return exitOK
+}
+
+// completeWords returns the candidates for current given the words typed
+// before it (without the program name).
+func completeWords(words []string, current string) []string {
+	var cmd *command
+	path := words
+	if len(words) > 0 && !strings.HasPrefix(words[0], "-") {
+		cmd = findCommand(words[0])
+		if cmd == nil {
+			return nil
+		}
+		path = words[1:]
+	}
+	if cmd == nil {
+		// No subcommand yet: plain flags run `copy`.
+		if !strings.HasPrefix(current, "-") && len(words) == 0 {
+			var names []string
+			for _, c := range commands() {
+				if !c.hidden {
+					names = append(names, c.name)
+				}
+			}
+			return filterPrefix(names, current)
+		}
+		cmd = findCommand("copy")
+	}
+
+	// Nested commands such as `groups run` select their verb first.
+	var sub []string
+	if len(cmd.subcommands) > 0 {
+		if len(path) == 0 {
+			return filterPrefix(cmd.subcommands, current)
+		}
+		sub = path[:1]
+		path = path[1:]
+	}
+
+	fs := commandFlags(cmd, sub)
+
+	// Complete the value of a flag given as a separate word.
+	if len(path) > 0 && fs != nil {
+		if name, ok := flagName(path[len(path)-1]); ok && !strings.Contains(name, "=") {
+			if f := fs.Lookup(name); f != nil && !isBoolFlag(f) {
+				return completeFlagValue(name, words, current)
+			}
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		if fs == nil {
+			return nil
+		}
+		dashes := "-"
+		if strings.HasPrefix(current, "--") {
+			dashes = "--"
+		}
+		var names []string
+		fs.VisitAll(func(f *flag.Flag) {
+			names = append(names, dashes+f.Name)
+		})
+		return filterPrefix(names, current)
+	}
+
+	return completeArgument(cmd.name, sub, words, current)
+}
+
+// completeArgument returns candidates for positional arguments that come
+// from the configuration or a fixed list.
+func completeArgument(cmd string, sub, words []string, current string) []string {
+	verb := ""
+	if len(sub) > 0 {
+		verb = sub[0]
+	}
+
+	switch {
+	case cmd == "completion":
+		return filterPrefix(completionShells, current)
+	case cmd == "groups" && verb == "run":
+		cfg := loadCompletionConfig(words)
+		if cfg == nil {
+			return nil
+		}
+		var ids []string
+		for _, g := range cfg.Groups {
+			ids = append(ids, g.ID)
+		}
+		return filterPrefix(ids, current)
+	case cmd == "config" && (verb == "get" || verb == "set"):
+		// Only the first argument is a key; set's value is free-form.
+		if positionalCount(words[2:]) > 0 {
+			return nil
+		}
+		return filterPrefix(config.Keys(), current)
+	}
+	return nil
+}
+
+// completeFlagValue returns candidates for the value of flag name. Flags
+// taking paths return nothing so the shell completes file names.
+func completeFlagValue(name string, words []string, current string) []string {
+	switch name {
+	case "output":
+		return filterPrefix([]string{"plain", "json", "ndjson"}, current)
+	case "profile":
+		if cfg := loadCompletionConfig(words); cfg != nil {
+			return filterPrefix(cfg.ProfileNames(), current)
+		}
+	}
+	return nil
+}
+
+// flagSetProbe, when set, receives the flag set of the command being run
+// instead of letting it parse its arguments. Completion uses it to list
+// the flags each command really defines.
+var flagSetProbe func(fs *flag.FlagSet)
+
+// commandFlags returns the flag set defined by cmd (and its verb), or nil.
+func commandFlags(cmd *command, sub []string) *flag.FlagSet {
+	var fs *flag.FlagSet
+	flagSetProbe = func(f *flag.FlagSet) { fs = f }
+	defer func() { flagSetProbe = nil }()
+
+	// Probed commands return from parseFlags before doing any work.
+	_ = cmd.run(context.Background(), sub)
+	return fs
+}
+
+// loadCompletionConfig loads the config named by -config in words (or the
+// default one). Completion must never fail loudly, so errors yield nil.
+func loadCompletionConfig(words []string) *config.Config {
+	configFile := config.FileName
+	for i, w := range words {
+		name, ok := flagName(w)
+		if !ok {
+			continue
+		}
+		if v, found := strings.CutPrefix(name, "config="); found {
+			configFile = v
+		} else if name == "config" && i+1 < len(words) {
+			configFile = words[i+1]
+		}
+	}
+
+	path := resolveConfigPath(configFile)
+	if path == "" {
+		return nil
+	}
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		return nil
+	}
+	return cfg
+}
+
+// findCommand returns the subcommand called name, or nil.
+func findCommand(name string) *command {
+	for _, c := range commands() {
+		if c.name == name {
+			return &c
+		}
+	}
+	return nil
+}
+
+// flagName strips the leading dashes from a flag word.
+func flagName(word string) (string, bool) {
+	if !strings.HasPrefix(word, "-") || word == "-" || word == "--" {
+		return "", false
+	}
+	return strings.TrimLeft(word, "-"), true
+}
+
+// isBoolFlag reports whether f can be given without a value.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// positionalCount counts the words that are neither flags nor flag values.
+// It assumes every flag other than the known booleans takes a value.
+func positionalCount(words []string) int {
+	n := 0
+	for i := 0; i < len(words); i++ {
+		name, ok := flagName(words[i])
+		if !ok {
+			n++
+			continue
+		}
+		if !strings.Contains(name, "=") {
+			i++ // skip the flag's value
+		}
+	}
+	return n
+}
+
+// filterPrefix returns the sorted candidates starting with prefix.
+func filterPrefix(candidates []string, prefix string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompleteWords(t *testing.T) {
+	cfgPath, _, _ := writeGroupConfig(t)
+
+	tests := []struct {
+		name     string
+		words    []string
+		current  string
+		expected []string
+	}{
+		{"commands", nil, "co", []string{"completion", "config", "copy"}},
+		{"hidden commands", nil, "__", nil},
+		{"default copy flags", nil, "-ov", []string{"-overwrite"}},
+		{"double dash", []string{"copy"}, "--dry", []string{"--dry-run"}},
+		{"verbs", []string{"groups"}, "", []string{"list", "run"}},
+		{"verb flags", []string{"groups", "run"}, "-lock", []string{"-lock-wait"}},
+		{"group ids", []string{"groups", "run", "-config", cfgPath}, "n", []string{"nightly"}},
+		{"config keys", []string{"config", "get"}, "work", []string{"workers"}},
+		{"config value is free-form", []string{"config", "set", "workers"}, "", nil},
+		{"output values", []string{"scan", "-output"}, "nd", []string{"ndjson"}},
+		{"path values fall back to files", []string{"copy", "-source"}, "", nil},
+		{"bool flag is not a value", []string{"copy", "-dry-run"}, "-ver", []string{"-version"}},
+		{"shells", []string{"completion"}, "p", []string{"powershell"}},
+		{"service install takes schedule run flags", []string{"service", "install"}, "-wat", []string{"-watch"}},
+		{"service verbs only have help", []string{"service", "stop"}, "-", nil},
+		{"unknown command", []string{"frobnicate"}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := completeWords(tt.words, tt.current)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("completeWords(%v, %q) = %v, expected %v", tt.words, tt.current, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteCompletionScript(t *testing.T) {
+	for _, shell := range completionShells {
+		t.Run(shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeCompletionScript(&buf, shell, "copyimage-cli"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			script := buf.String()
+			if !strings.Contains(script, "copyimage-cli") || !strings.Contains(script, "__complete") {
+				t.Errorf("Script does not call __complete:\n%s", script)
+			}
+			if strings.Contains(script, "{name}") || strings.Contains(script, "{fn}") {
+				t.Errorf("Script has unexpanded placeholders:\n%s", script)
+			}
+		})
+	}
+
+	if err := writeCompletionScript(&bytes.Buffer{}, "fish", "copyimage"); err == nil {
+		t.Error("Expected error for unsupported shell")
+	}
+}
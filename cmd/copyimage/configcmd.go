@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"copy-image/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runConfigCommand implements `copyimage config show|get|set`.
+func runConfigCommand(_ context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Println("Usage: copyimage config show | get <key> | set <key> <value> [-config file]")
+		return exitConfig
+	}
+
+	fs := flag.NewFlagSet("config "+args[0], flag.ContinueOnError)
+	configFile := fs.String("config", config.FileName, "Path to config file (default: search the current, user config and program folders)")
+	output := fs.String("output", "plain", "Output format for show: plain (YAML), json or ndjson")
+
+	positional, code, ok := parseInterspersed(fs, args[1:])
+	if !ok {
+		return code
+	}
+
+	switch args[0] {
+	case "show":
+		return runConfigShow(*configFile, *output)
+	case "get":
+		if len(positional) != 1 {
+			fmt.Println("Usage: copyimage config get <key>")
+			return exitConfig
+		}
+		return runConfigGet(*configFile, positional[0])
+	case "set":
+		if len(positional) != 2 {
+			fmt.Println("Usage: copyimage config set <key> <value>")
+			return exitConfig
+		}
+		return runConfigSet(*configFile, positional[0], positional[1])
+	default:
+		fmt.Printf("❌ Unknown config command %q (expected show, get or set)\n", args[0])
+		return exitConfig
+	}
+}
+
+// readConfig loads the config file if it exists, or returns the defaults
+// together with the path that a save should write to. Without an explicit
+// -config that path is "", meaning config.DefaultPath.
+func readConfig(configFile string) (*config.Config, string, error) {
+	path := resolveConfigPath(configFile)
+	if path == "" {
+		if configFile == config.FileName {
+			return config.DefaultConfig(), "", nil
+		}
+		return config.DefaultConfig(), configFile, nil
+	}
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		return nil, path, err
+	}
+	return cfg, path, nil
+}
+
+// runConfigShow prints the effective configuration.
+func runConfigShow(configFile, output string) int {
+	mode, err := parseOutputMode(output)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitConfig
+	}
+
+	cfg, _, err := readConfig(configFile)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitConfig
+	}
+
+	if mode != outputPlain {
+		newReporter(mode, os.Stdout).Result(cfg)
+		return exitOK
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitConfig
+	}
+	fmt.Print(string(data))
+	return exitOK
+}
+
+// runConfigGet prints a single setting.
+func runConfigGet(configFile, key string) int {
+	cfg, _, err := readConfig(configFile)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitConfig
+	}
+
+	value, err := cfg.Get(key)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitConfig
+	}
+	fmt.Println(value)
+	return exitOK
+}
+
+// runConfigSet changes a single setting and saves the config file.
+func runConfigSet(configFile, key, value string) int {
+	cfg, path, err := readConfig(configFile)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitConfig
+	}
+
+	if err := cfg.Set(key, value); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitConfig
+	}
+	if path == "" {
+		path, err = cfg.SaveToDefault()
+	} else {
+		err = cfg.SaveToFile(path)
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitPartial
+	}
+
+	fmt.Printf("✅ %s = %s (%s)\n", key, value, path)
+	return exitOK
+}
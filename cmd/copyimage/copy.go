@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	"copy-image/internal/hooks"
+	"copy-image/internal/lock"
+	"copy-image/internal/notify"
+	"copy-image/internal/version"
+)
+
+// runCopyCommand implements `copyimage copy`, the default command when no
+// subcommand is given. It returns the process exit code.
+func runCopyCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("copy", flag.ContinueOnError)
+
+	// Define CLI flags
+	sources := addSourceFlag(fs)
+	destPath := fs.String("dest", "", "Destination directory path")
+	overwrite := fs.Bool("overwrite", false, "Overwrite existing files")
+	workers := fs.Int("workers", 10, "Number of concurrent workers")
+	configFile := fs.String("config", config.FileName, "Path to config file (default: search the current, user config and program folders)")
+	profile := fs.String("profile", "", "Use a named profile from the config file")
+	dryRun := fs.Bool("dry-run", false, "Show what would be copied without copying")
+	incremental := fs.Bool("incremental", false, "Only copy files that are new or changed since they were last copied to the destination")
+	stableChecks := fs.Int("stable-checks", 0, "Wait until each source file's size has not changed for this many checks a second apart (0 = off)")
+	offlineWait := fs.Duration("offline-wait", 0, "How long to pause if the destination goes offline before failing the files left (0 = config or 5m, negative = don't wait)")
+	extensions := fs.String("ext", "", "Comma-separated list of extensions to include (e.g., .jpg,.png)")
+	showVersion := fs.Bool("version", false, "Show version")
+	interactive := fs.Bool("interactive", true, "Run in interactive mode")
+	output := fs.String("output", "plain", "Output format: plain, json or ndjson")
+	yes := fs.Bool("yes", false, "Never prompt: skip the menu and the final Enter (same as -non-interactive)")
+	nonInteractive := fs.Bool("non-interactive", false, "Never read from stdin; suitable for scheduled tasks")
+	lockWait := fs.Duration("lock-wait", 0, "How long to wait if another session is writing to the destination (0 = refuse)")
+	filesFrom := fs.String("files-from", "", "Copy exactly the files listed in this file (one per line, - for stdin) instead of scanning the source")
+	null := fs.Bool("null", false, "Entries in -files-from are separated by NUL characters (find -print0)")
+	urlsFrom := fs.String("urls-from", "", "Download the http(s) URLs listed in this file (one per line) instead of scanning the source")
+	checksums := fs.String("checksums", "", "Write a checksum manifest (sha256 or md5, sha256sum format) into the destination after each batch")
+	order := fs.String("order", "", "Order files are copied in: name, size-asc, size-desc, mtime or random (default: scan order)")
+	mhl := fs.Bool("mhl", false, "Write a Media Hash List (MHL v1, MD5) into the destination for each batch")
+	verbosity := addVerbosityFlags(fs)
+	resultLogPath := fs.String("result-log", "", "Append one NDJSON record per file (path, status, bytes, duration, error, hash) to this file")
+	otlp := addTracingFlag(fs)
+
+	// Plain `copyimage -h` lands here, so also list the other commands.
+	fs.Usage = func() {
+		printUsage()
+		fmt.Println("\nFlags for copy:")
+		fs.PrintDefaults()
+	}
+
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	// Show version
+	if *showVersion {
+		fmt.Printf("copy-image version %s\n", version.Version)
+		return exitOK
+	}
+
+	mode, err := parseOutputMode(*output)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitConfig
+	}
+	ui = newReporter(mode, os.Stdout)
+	ui.setVerbosity(*verbosity, os.Stderr)
+
+	// Headless runs never block on stdin. Machine-readable output implies
+	// headless because there is nobody to answer prompts.
+	// A list piped on stdin leaves nothing to read answers from either.
+	headless := *yes || *nonInteractive || !ui.isPlain() || *filesFrom == "-"
+	if headless {
+		*interactive = false
+	}
+
+	// Print banner
+	if ui.isChatty() {
+		printBanner()
+	}
+
+	// Load configuration
+	cfg, err := loadProfileConfig(*configFile, *profile, *destPath, *overwrite, *workers, *dryRun, *extensions)
+	if err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		waitForKey(headless)
+		return exitConfig
+	}
+	sources.apply(cfg)
+	cfg.Incremental = cfg.Incremental || *incremental
+	if *stableChecks > 0 {
+		cfg.StableChecks = *stableChecks
+	}
+	switch {
+	case *offlineWait < 0:
+		cfg.OfflineWait = -1
+	case *offlineWait > 0:
+		cfg.OfflineWait = max(int(offlineWait.Seconds()), 1)
+	}
+	if *urlsFrom != "" {
+		cfg.URLsFrom = *urlsFrom
+	}
+	if *checksums != "" {
+		cfg.Checksums = *checksums
+	}
+	if *order != "" {
+		cfg.Order = *order
+	}
+	cfg.MHL = cfg.MHL || *mhl
+	if *filesFrom != "" && cfg.Source == "" {
+		// Relative entries in the list are resolved against the source,
+		// which then defaults to the current directory.
+		cfg.Source = "."
+	}
+
+	// Validate configuration
+	if err := cfg.Validate(); err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		waitForKey(headless)
+		return exitConfig
+	}
+
+	// Interactive mode - show menu and get user choice
+	if *interactive {
+		choice := showMenu()
+		if choice == 0 {
+			fmt.Println(tr.T("cli.exited"))
+			return exitOK
+		}
+		cfg.Overwrite = (choice == 1)
+	}
+
+	// Print configuration
+	if ui.isChatty() {
+		printConfig(cfg)
+	}
+
+	// Create copier
+	c := copier.New(cfg)
+
+	results, err := openResultLog(*resultLogPath, cfg.DryRun)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		waitForKey(headless)
+		return exitConfig
+	}
+	defer func() { _ = results.Close() }()
+	results.attach(c)
+
+	flush, err := startTracing(ctx, *otlp)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		waitForKey(headless)
+		return exitConfig
+	}
+	defer flush()
+
+	// Get files
+	var files []string
+	if *filesFrom != "" {
+		files, err = readFileListFrom(*filesFrom, cfg.Source, *null)
+	} else {
+		ui.Println(tr.T("cli.scanning"))
+		files, err = c.GetFilesContext(copier.WithScanProgress(ctx, ui.ScanProgress))
+	}
+	if err != nil && ctx.Err() != nil {
+		ui.Println(tr.T("cli.cancelled"))
+		return exitCancelled
+	}
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		waitForKey(headless)
+		return exitConfig
+	}
+
+	if len(files) == 0 {
+		ui.Println(tr.T("cli.no_files"))
+		if !ui.isPlain() {
+			ui.Summary(copier.CopySummary{}, cfg.DryRun)
+		}
+		waitForKey(headless)
+		return exitOK
+	}
+
+	ui.Printf("%s\n\n", tr.T("cli.found_files", len(files)))
+
+	// Copy files
+	if cfg.DryRun {
+		ui.Println(tr.T("cli.dry_run_mode"))
+	} else {
+		ui.Println(tr.T("cli.starting"))
+	}
+
+	// Only trap Ctrl+C while copying so it still quits the menu and prompts.
+	copyCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	release := func() {}
+	// pre_copy runs before the lock so it can mount the destination.
+	hk := hooks.New(cfg)
+	batch := hooks.Batch{Kind: history.KindCopy, Copier: c, Files: len(files)}
+	if err = c.Err(); err == nil {
+		if err = hk.Before(copyCtx, batch); err != nil {
+			warnHook(hk.Aborted(ctx, batch, err))
+		} else {
+			release, err = lockDestination(copyCtx, cfg.LockDir(), cfg.DryRun, *lockWait)
+		}
+	}
+	if err != nil {
+		stop()
+		ui.Error(tr.T("cli.error"), err)
+		waitForKey(headless)
+		switch {
+		case errors.Is(err, lock.ErrLocked):
+			return exitLocked
+		case errors.Is(err, context.Canceled):
+			return exitCancelled
+		default:
+			// The destination cannot be written at all, so every file fails.
+			return exitPartial
+		}
+	}
+	notes := notify.New(cfg)
+	warnNotify(notes.Started(copyCtx, history.KindCopy, "", c, len(files)))
+	start := time.Now()
+	save := useManifest(*configFile, c)
+	summary := runCopy(copyCtx, c, files)
+	save()
+	release()
+	cancelled := copyCtx.Err() != nil
+	warnNotify(notes.Finished(copyCtx, history.KindCopy, "", c, summary, cancelled))
+	warnHook(hk.After(copyCtx, batch, summary, cancelled))
+	stop()
+	openHistory(*configFile).record(history.KindCopy, "", c, start, summary, cancelled)
+
+	ui.Summary(summary, cfg.DryRun)
+
+	if cancelled {
+		ui.Println(tr.T("cli.cancelled"))
+		return exitCancelled
+	}
+
+	// Wait for user input before exit
+	waitForKey(headless)
+
+	if summary.Failed > 0 {
+		return exitPartial
+	}
+	return exitOK
+}
+
+// runCopy copies files using the terminal progress bar in plain mode, or the
+// event-based copier otherwise so progress can be reported as records (JSON
+// modes) or log lines (-v).
+func runCopy(ctx context.Context, c *copier.Copier, files []string) copier.CopySummary {
+	c.SetLogger(ui.log)
+	if ui.showProgressBar() {
+		return c.CopyFilesParallel(ctx, files)
+	}
+
+	ui.Start(len(files))
+	events, wait := copier.Dispatch(ui.Progress)
+	defer wait()
+	return c.CopyFilesParallelWithEvents(ctx, files, events)
+}
+
+// lockDestination takes the session lock on dir so two instances (or two
+// machines on the same share) never interleave writes. Dry-runs write
+// nothing and skip the lock. The returned func releases the lock.
+func lockDestination(ctx context.Context, dir string, dryRun bool, wait time.Duration) (func(), error) {
+	if dryRun {
+		return func() {}, nil
+	}
+
+	lease, err := lock.Acquire(ctx, dir, lock.Options{Wait: wait})
+	if err != nil {
+		return nil, err
+	}
+	return func() { _ = lease.Release() }, nil
+}
+
+// lockGroups returns ctx making the groups run under it hold their run
+// lock, kept next to the config file, so a group is never run by two
+// sessions at once. A busy group is waited for up to wait.
+func lockGroups(ctx context.Context, configFile string, wait time.Duration) context.Context {
+	return copier.WithGroupLocks(ctx, dataPath(configFile, "locks"), wait)
+}
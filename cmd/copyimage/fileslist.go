@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readFileListFrom reads the -files-from list at path ("-" for stdin).
+func readFileListFrom(path, baseDir string, null bool) ([]string, error) {
+	if path == "-" {
+		return readFileList(os.Stdin, baseDir, null)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file list: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return readFileList(f, baseDir, null)
+}
+
+// readFileList parses a list of files to copy, one per line or separated
+// by NUL when null is set (for names containing newlines, as produced by
+// `find -print0`). Blank lines and, in line mode, lines starting with '#'
+// are ignored. Relative entries are joined with baseDir and duplicates are
+// dropped so two workers never write the same destination file.
+func readFileList(r io.Reader, baseDir string, null bool) ([]string, error) {
+	sep := byte('\n')
+	if null {
+		sep = 0
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexByte(data, sep); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+
+	var files []string
+	seen := make(map[string]bool)
+	for scanner.Scan() {
+		entry := scanner.Text()
+		if !null {
+			// Lists written on Windows end lines with CRLF.
+			entry = strings.TrimSuffix(entry, "\r")
+			if strings.HasPrefix(entry, "#") {
+				continue
+			}
+		}
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(entry) {
+			entry = filepath.Join(baseDir, entry)
+		}
+		entry = filepath.Clean(entry)
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		files = append(files, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file list: %w", err)
+	}
+	return files, nil
+}
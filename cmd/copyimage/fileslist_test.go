@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadFileList(t *testing.T) {
+	base := filepath.Join("data", "in")
+	abs, _ := filepath.Abs(filepath.Join("other", "c.jpg"))
+
+	tests := []struct {
+		name     string
+		input    string
+		null     bool
+		expected []string
+	}{
+		{
+			name:     "lines with comments and blanks",
+			input:    "# picked by the DAM\na.jpg\r\n\n  \nsub/b.jpg\n",
+			expected: []string{filepath.Join(base, "a.jpg"), filepath.Join(base, "sub", "b.jpg")},
+		},
+		{
+			name:     "absolute paths are kept",
+			input:    abs + "\n",
+			expected: []string{abs},
+		},
+		{
+			name:     "duplicates are dropped",
+			input:    "a.jpg\n./a.jpg\na.jpg",
+			expected: []string{filepath.Join(base, "a.jpg")},
+		},
+		{
+			name:     "nul separated names may contain newlines",
+			input:    "a\nb.jpg\x00#c.jpg\x00",
+			null:     true,
+			expected: []string{filepath.Join(base, "a\nb.jpg"), filepath.Join(base, "#c.jpg")},
+		},
+		{
+			name:     "empty",
+			input:    "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readFileList(strings.NewReader(tt.input), base, tt.null)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCopyFilesFrom(t *testing.T) {
+	_, src, dst := writeGroupConfig(t)
+	list := filepath.Join(t.TempDir(), "list.txt")
+	if err := os.WriteFile(list, []byte("b.jpg\n"), 0644); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+
+	code := run(context.Background(), []string{"-yes", "-output", "ndjson", "-config", "", "-source", src, "-dest", dst, "-files-from", list})
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "b.jpg")); err != nil {
+		t.Errorf("Expected listed file to be copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "a.jpg")); !os.IsNotExist(err) {
+		t.Error("Files not in the list must not be copied")
+	}
+}
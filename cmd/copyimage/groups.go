@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	"copy-image/internal/hooks"
+	"copy-image/internal/lock"
+	"copy-image/internal/notify"
+)
+
+// groupInfo is the machine-readable description of a copy group.
+type groupInfo struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Source       string   `json:"source"`
+	Enabled      bool     `json:"enabled"`
+	Destinations int      `json:"destinations"`
+	Priority     int      `json:"priority,omitempty"`
+	DependsOn    []string `json:"dependsOn,omitempty"`
+}
+
+// runGroupsCommand implements `copyimage groups list` and
+// `copyimage groups run <id>...`.
+func runGroupsCommand(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Println("Usage: copyimage groups list | groups run <id>... | groups run -all [flags]")
+		return exitConfig
+	}
+
+	switch args[0] {
+	case "list":
+		return runGroupsList(args[1:])
+	case "run":
+		return runGroupsRun(ctx, args[1:])
+	default:
+		fmt.Printf("❌ Unknown groups command %q (expected list or run)\n", args[0])
+		return exitConfig
+	}
+}
+
+// runGroupsList prints the configured copy groups.
+func runGroupsList(args []string) int {
+	fs := flag.NewFlagSet("groups list", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	cfg, ok := common.load()
+	if !ok {
+		return exitConfig
+	}
+
+	// List groups in the order `groups run -all` runs them.
+	ordered, err := config.OrderGroups(cfg.Groups)
+	if err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+
+	groups := make([]groupInfo, 0, len(ordered))
+	for _, g := range ordered {
+		groups = append(groups, groupInfo{
+			ID:           g.ID,
+			Name:         g.Name,
+			Source:       g.Source,
+			Enabled:      g.Enabled,
+			Destinations: len(g.Destinations),
+			Priority:     g.Priority,
+			DependsOn:    g.DependsOn,
+		})
+	}
+	ui.Result(groups)
+
+	if len(groups) == 0 {
+		ui.Println(tr.T("groups.none"))
+	}
+	for _, g := range groups {
+		state := "✓"
+		if !g.Enabled {
+			state = "⊘"
+		}
+		after := ""
+		if len(g.DependsOn) > 0 {
+			after = " after " + strings.Join(g.DependsOn, ", ")
+		}
+		ui.Outputf("  %s %-20s %s (%d destination(s))%s\n", state, g.ID, g.Name, g.Destinations, after)
+	}
+	return exitOK
+}
+
+// runGroupsRun copies the named groups (or, with -all, every enabled group)
+// to all of their enabled destinations, in dependency and priority order.
+func runGroupsRun(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("groups run", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	all := fs.Bool("all", false, "Run every enabled group")
+	dryRun := fs.Bool("dry-run", false, "Show what would be copied without copying")
+	lockWait := fs.Duration("lock-wait", 0, "How long to wait if another session is running a group or writing to a destination (0 = refuse)")
+	resultLogPath := fs.String("result-log", "", "Append one NDJSON record per file (path, status, bytes, duration, error, hash) to this file")
+	reportPath := fs.String("report", "", "Write the summary of every group and destination to this JSON file")
+	otlp := addTracingFlag(fs)
+
+	positional, code, ok := parseInterspersed(fs, args)
+	if !ok {
+		return code
+	}
+	if *all && len(positional) > 0 || !*all && len(positional) == 0 {
+		fmt.Println("Usage: copyimage groups run <id>... | groups run -all [flags]")
+		return exitConfig
+	}
+
+	cfg, ok := common.load()
+	if !ok {
+		return exitConfig
+	}
+	if *dryRun {
+		cfg.DryRun = true
+	}
+
+	groups := cfg.GetEnabledGroups()
+	if !*all {
+		groups = nil
+		for _, id := range positional {
+			group := cfg.FindGroup(id)
+			if group == nil {
+				ui.Error(tr.T("cli.error"), fmt.Errorf("group %q not found", id))
+				return exitConfig
+			}
+			groups = append(groups, *group)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+
+	results, err := openResultLog(*resultLogPath, cfg.DryRun)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	defer func() { _ = results.Close() }()
+
+	flush, err := startTracing(ctx, *otlp)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	defer flush()
+
+	copyCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	reload := watchConfig(copyCtx, *common.configFile)
+
+	var groupID string
+	runner := lockedRunner(*common.configFile, cfg.DryRun, *lockWait, results, nil)
+	runner = hooks.New(cfg).Wrap(history.KindGroup, &groupID, runner, warnHook)
+	runner = notify.New(cfg).Wrap(history.KindGroup, &groupID, runner, warnNotify)
+	runner = openHistory(*common.configFile).wrap(history.KindGroup, &groupID, runner)
+	batch, err := copier.RunGroups(copier.WithScanProgress(lockGroups(copyCtx, *common.configFile, *lockWait), ui.ScanProgress), cfg, groups, runner, func(group *config.CopyGroup) {
+		groupID = group.ID
+		reload.applyExcludes(cfg, group)
+		ui.Println(tr.T("groups.running", group.ID, group.Name))
+	})
+	if err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+
+	scanFailed := false
+	for _, summary := range batch.Groups {
+		if summary.Err != nil {
+			ui.Error(tr.T("cli.error"), summary.Err)
+			// A group still running elsewhere is no config problem.
+			scanFailed = scanFailed || !errors.Is(summary.Err, copier.ErrDependencyFailed) && !errors.Is(summary.Err, lock.ErrGroupRunning)
+		}
+		for _, d := range summary.Destinations {
+			ui.Printf("\n📂 %s\n", d.Path)
+			if ui.isPlain() {
+				d.Summary.PrintSummary()
+			}
+		}
+	}
+	if !ui.isPlain() {
+		ui.BatchSummary(batch, cfg.DryRun)
+	}
+	if *reportPath != "" {
+		if err := writeReport(*reportPath, batch); err != nil {
+			ui.Error(tr.T("cli.error"), err)
+		}
+	}
+	// The batch is over; a webhook may still want to hear about it.
+	if err := notify.New(cfg).Batch(context.WithoutCancel(copyCtx), history.KindGroup, batch, copyCtx.Err() != nil); err != nil {
+		warnNotify(err)
+	}
+
+	switch {
+	case copyCtx.Err() != nil:
+		return exitCancelled
+	case scanFailed:
+		// The source folder of a group is missing or unreadable.
+		return exitConfig
+	case batch.Failed():
+		return exitPartial
+	}
+	return exitOK
+}
+
+// writeReport writes the summary of a groups run to path as indented
+// JSON, for tools that check on unattended runs.
+func writeReport(path string, batch copier.BatchSummary) error {
+	data, err := json.MarshalIndent(batch, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// lockedRunner returns a DestinationRunner that takes the destination's
+// session lock, logs results and copies with the CLI's progress output,
+// skipping files already copied in incremental mode (manifests are found
+// through configFile). A busy destination fails as a whole; the other
+// destinations still run. Copies are reported to m unless it is nil.
+func lockedRunner(configFile string, dryRun bool, lockWait time.Duration, results *resultLog, m copier.Metrics) copier.DestinationRunner {
+	return lockedCopy(configFile, dryRun, lockWait, results, m, runCopy)
+}
+
+// lockedCopy is lockedRunner with copyFiles doing the copy instead of
+// runCopy, for callers reporting progress their own way.
+func lockedCopy(configFile string, dryRun bool, lockWait time.Duration, results *resultLog, m copier.Metrics, copyFiles copier.DestinationRunner) copier.DestinationRunner {
+	return func(ctx context.Context, c *copier.Copier, files []string) copier.CopySummary {
+		release := func() {}
+		err := c.Err()
+		if err == nil {
+			release, err = lockDestination(ctx, c.Config().LockDir(), dryRun, lockWait)
+		}
+		if err != nil {
+			return copier.CopySummary{
+				TotalFiles:  len(files),
+				Failed:      len(files),
+				FailedFiles: copier.AllFailed(files, err),
+			}
+		}
+		defer release()
+		results.attach(c)
+		if m != nil {
+			c.SetMetrics(m)
+		}
+		save := useManifest(configFile, c)
+		defer save()
+		return copyFiles(ctx, c, files)
+	}
+}
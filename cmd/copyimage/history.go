@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"strconv"
+	"time"
+
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+)
+
+// runHistory records every batch in the history database next to the
+// config file. Failing to record is logged but never fails the copy.
+type runHistory struct {
+	db *history.DB
+}
+
+// openHistory returns the history of the given -config value.
+func openHistory(configFile string) *runHistory {
+	return &runHistory{db: history.Open(dataPath(configFile, history.FileName))}
+}
+
+// record stores one batch copied by c.
+func (h *runHistory) record(kind, groupID string, c *copier.Copier, start time.Time, summary copier.CopySummary, cancelled bool) {
+	run := history.NewRun(kind, groupID, c, start, summary)
+	run.Cancelled = cancelled
+	if err := h.db.Add(run); err != nil {
+		ui.log.Warn("run not recorded in history", "error", err)
+	}
+}
+
+// wrap returns run with each batch recorded. groupID is read when a batch
+// starts, so a single runner can serve groups that run one after another;
+// it is nil outside groups.
+func (h *runHistory) wrap(kind string, groupID *string, run copier.DestinationRunner) copier.DestinationRunner {
+	return func(ctx context.Context, c *copier.Copier, files []string) copier.CopySummary {
+		id := ""
+		if groupID != nil {
+			id = *groupID
+		}
+		start := time.Now()
+		summary := run(ctx, c, files)
+		h.record(kind, id, c, start, summary, ctx.Err() != nil)
+		return summary
+	}
+}
+
+// runHistoryCommand implements `copyimage history [<id>]`: the latest runs,
+// or the details of one run.
+func runHistoryCommand(_ context.Context, args []string) int {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	limit := fs.Int("limit", 20, "Number of runs to list (0 lists all)")
+	positional, code, ok := parseInterspersed(fs, args)
+	if !ok {
+		return code
+	}
+	if len(positional) > 1 {
+		fs.Usage()
+		return exitConfig
+	}
+
+	if _, ok := common.load(); !ok {
+		return exitConfig
+	}
+	h := openHistory(*common.configFile)
+
+	if len(positional) == 1 {
+		id, err := strconv.ParseUint(positional[0], 10, 64)
+		if err != nil {
+			ui.Error(tr.T("cli.error"), errors.New("run ID must be a number"))
+			return exitConfig
+		}
+		run, err := h.db.Get(id)
+		if err != nil {
+			ui.Error(tr.T("cli.error"), err)
+			return exitConfig
+		}
+		ui.Result(run)
+		printRun(*run)
+		for _, f := range run.Failures {
+			ui.Outputf("    ✗ %s\n", f)
+		}
+		return exitOK
+	}
+
+	runs, err := h.db.List(*limit)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	if runs == nil {
+		runs = []history.Run{}
+	}
+	ui.Result(runs)
+	if len(runs) == 0 {
+		ui.Println(tr.T("history.none"))
+	}
+	for _, run := range runs {
+		printRun(run)
+	}
+	return exitOK
+}
+
+// printRun prints the one-line summary of a run in plain mode.
+func printRun(run history.Run) {
+	mark := "✓"
+	switch {
+	case run.UndoneAt != nil:
+		mark = "↩"
+	case run.Cancelled:
+		mark = "⏹"
+	case run.Failed > 0:
+		mark = "✗"
+	}
+	what := run.Kind
+	if run.GroupID != "" {
+		what += " " + run.GroupID
+	}
+	if run.DryRun {
+		what += " (dry run)"
+	}
+	ui.Outputf("%5d %s %s %-20s → %s: %d copied, %d skipped, %d failed in %.1fs\n",
+		run.ID, mark, run.Start.Format(time.DateTime), what, run.Destination,
+		run.Successful, run.Skipped, run.Failed, run.Duration)
+}
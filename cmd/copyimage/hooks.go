@@ -0,0 +1,9 @@
+package main
+
+// warnHook logs a post_copy or on_failure command that failed. Only a
+// failing pre_copy fails the batch.
+func warnHook(err error) {
+	if err != nil {
+		ui.log.Warn("hook failed", "error", err)
+	}
+}
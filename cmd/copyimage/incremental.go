@@ -0,0 +1,27 @@
+package main
+
+import (
+	"copy-image/internal/copier"
+	"copy-image/internal/manifest"
+)
+
+// useManifest attaches the incremental manifest of c's destination when
+// incremental mode is on, and returns the func saving it after the batch.
+// The manifests are kept next to the config file. A manifest that cannot
+// be read only costs a full copy, so it is logged and the batch goes on.
+func useManifest(configFile string, c *copier.Copier) (save func()) {
+	if !c.Config().Incremental {
+		return func() {}
+	}
+	m, err := manifest.Open(dataPath(configFile, manifest.DirName), c.Destination())
+	if err != nil {
+		ui.log.Warn("copying every file", "error", err)
+		return func() {}
+	}
+	c.SetManifest(m)
+	return func() {
+		if err := m.Save(); err != nil {
+			ui.log.Warn("incremental manifest not saved", "error", err)
+		}
+	}
+}
@@ -2,129 +2,94 @@ package main
 
 import (
 	"bufio"
-	"flag"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"copy-image/internal/config"
-	"copy-image/internal/copier"
+	"copy-image/internal/i18n"
+	"copy-image/internal/version"
 )
 
-var (
-	version = "1.0.0"
+// Exit codes let scripts and schedulers tell outcomes apart without
+// parsing output.
+const (
+	exitOK        = 0   // every file was copied or skipped
+	exitPartial   = 1   // the batch finished but some files failed
+	exitConfig    = 2   // invalid flags, configuration or source
+	exitLocked    = 3   // another session is writing to the destination
+	exitCancelled = 130 // interrupted by Ctrl+C (128 + SIGINT)
 )
 
 func main() {
-	// Define CLI flags
-	sourcePath := flag.String("source", "", "Source directory path")
-	destPath := flag.String("dest", "", "Destination directory path")
-	overwrite := flag.Bool("overwrite", false, "Overwrite existing files")
-	workers := flag.Int("workers", 10, "Number of concurrent workers")
-	configFile := flag.String("config", "config.yaml", "Path to config file")
-	dryRun := flag.Bool("dry-run", false, "Show what would be copied without copying")
-	extensions := flag.String("ext", "", "Comma-separated list of extensions to include (e.g., .jpg,.png)")
-	showVersion := flag.Bool("version", false, "Show version")
-	interactive := flag.Bool("interactive", true, "Run in interactive mode")
-
-	flag.Parse()
-
-	// Show version
-	if *showVersion {
-		fmt.Printf("copy-image version %s\n", version)
-		os.Exit(0)
-	}
-
-	// Print banner
-	printBanner()
-
-	// Load configuration
-	cfg := loadConfig(*configFile, *sourcePath, *destPath, *overwrite, *workers, *dryRun, *extensions)
-
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		fmt.Printf("❌ Configuration error: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Interactive mode - show menu and get user choice
-	if *interactive {
-		choice := showMenu()
-		if choice == 0 {
-			fmt.Println("\n👋 Đã thoát chương trình.")
-			os.Exit(0)
-		}
-		cfg.Overwrite = (choice == 1)
-	}
-
-	// Print configuration
-	printConfig(cfg)
-
-	// Create copier
-	c := copier.New(cfg)
+	args, notice := startUpdateCheck(os.Args[1:], os.Stderr)
+	code := run(context.Background(), args)
+	notice()
+	os.Exit(code)
+}
 
-	// Get files
-	fmt.Println("\n🔍 Đang quét thư mục nguồn...")
-	files, err := c.GetFiles()
+// loadConfig reads the config file and applies the CLI flag overrides.
+// An invalid config file or environment override is reported but does not
+// stop the load.
+func loadConfig(configFile, source, dest string, overwrite bool, workers int, dryRun bool, extensions string) *config.Config {
+	cfg, err := readConfigFile(configFile)
 	if err != nil {
-		fmt.Printf("❌ Lỗi: %v\n", err)
-		waitForKey()
-		os.Exit(1)
+		ui.Error(tr.T("cli.config_error"), err)
 	}
+	applyFlagOverrides(cfg, source, dest, overwrite, workers, dryRun, extensions)
+	return cfg
+}
 
-	if len(files) == 0 {
-		fmt.Println("⚠️  Không tìm thấy file nào trong thư mục nguồn.")
-		waitForKey()
-		os.Exit(0)
+// loadProfileConfig is like loadConfig but applies the named profile (if
+// any) between the config file and the flags, so flags still win.
+func loadProfileConfig(configFile, profile, dest string, overwrite bool, workers int, dryRun bool, extensions string) (*config.Config, error) {
+	cfg, err := readConfigFile(configFile)
+	if err != nil {
+		return nil, err
 	}
-
-	fmt.Printf("📁 Tìm thấy %d file(s)\n\n", len(files))
-
-	// Copy files
-	if cfg.DryRun {
-		fmt.Println("🔄 [DRY-RUN MODE] - Không thực hiện copy thật")
-	} else {
-		fmt.Println("🚀 Bắt đầu copy files...")
+	if profile != "" {
+		if err := cfg.ApplyProfile(profile); err != nil {
+			return nil, fmt.Errorf("%w (available: %s)", err, strings.Join(cfg.ProfileNames(), ", "))
+		}
+		ui.Println(tr.T("cli.profile", profile))
 	}
-
-	summary := c.CopyFilesParallel(files)
-	summary.PrintSummary()
-
-	// Wait for user input before exit
-	waitForKey()
+	applyFlagOverrides(cfg, "", dest, overwrite, workers, dryRun, extensions)
+	return cfg, nil
 }
 
-func loadConfig(configFile, source, dest string, overwrite bool, workers int, dryRun bool, extensions string) *config.Config {
+// readConfigFile loads the config file, falling back to the defaults when
+// it does not exist or is invalid (and returning the error), applies COPYIMAGE_* overrides, expands ${VAR} and
+// %VAR% in paths and switches the CLI to the configured language.
+func readConfigFile(configFile string) (*config.Config, error) {
 	cfg := config.DefaultConfig()
+	tr = i18n.New(i18n.Resolve(cfg.Language))
 
-	// Try to load from config file
-	if configFile != "" {
-		// Check current directory first
-		if _, err := os.Stat(configFile); err == nil {
-			loadedCfg, err := config.LoadFromFile(configFile)
-			if err == nil {
-				cfg = loadedCfg
-				fmt.Printf("✅ Loaded config from: %s\n", configFile)
-			}
-		} else {
-			// Try to find config in executable directory
-			exePath, err := os.Executable()
-			if err == nil {
-				exeDir := filepath.Dir(exePath)
-				altConfigPath := filepath.Join(exeDir, configFile)
-				if _, err := os.Stat(altConfigPath); err == nil {
-					loadedCfg, err := config.LoadFromFile(altConfigPath)
-					if err == nil {
-						cfg = loadedCfg
-						fmt.Printf("✅ Loaded config from: %s\n", altConfigPath)
-					}
-				}
-			}
+	// Try to load from config file. A file that exists but is invalid is
+	// an error: silently using the defaults would copy to the wrong place.
+	if path := resolveConfigPath(configFile); path != "" {
+		loadedCfg, err := config.LoadFromFile(path)
+		if err != nil {
+			return cfg, err
 		}
+		cfg = loadedCfg
+		tr = i18n.New(i18n.Resolve(cfg.Language))
+		ui.Println(tr.T("cli.loaded_config", path))
 	}
 
-	// Override with CLI flags if provided
+	// Environment overrides apply even without a config file, so CI jobs
+	// can be configured from variables alone.
+	err := cfg.ApplyEnv()
+	cfg.ExpandEnv()
+	tr = i18n.New(i18n.Resolve(cfg.Language))
+	return cfg, err
+}
+
+// applyFlagOverrides overrides the config with the CLI flags that differ
+// from their defaults.
+func applyFlagOverrides(cfg *config.Config, source, dest string, overwrite bool, workers int, dryRun bool, extensions string) {
 	if source != "" {
 		cfg.Source = source
 	}
@@ -143,8 +108,34 @@ func loadConfig(configFile, source, dest string, overwrite bool, workers int, dr
 	if extensions != "" {
 		cfg.Extensions = parseExtensions(extensions)
 	}
+}
 
-	return cfg
+// resolveConfigPath returns the config file to load, or "" when there is
+// none. An empty -config disables the config file; the default value runs
+// the discovery chain (see config.Locate) so the CLI and the GUI find the
+// same file.
+func resolveConfigPath(configFile string) string {
+	switch configFile {
+	case "":
+		return ""
+	case config.FileName:
+		return config.Locate("")
+	default:
+		return config.Locate(configFile)
+	}
+}
+
+// dataPath returns where the state file name (scheduled runs, history)
+// is kept: next to the config file, so the CLI sees what a service running
+// under another account with the same config did, or in the user config
+// folder without a config file.
+func dataPath(configFile, name string) string {
+	if path := resolveConfigPath(configFile); path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			return filepath.Join(filepath.Dir(abs), name)
+		}
+	}
+	return config.DataPath(name)
 }
 
 func parseExtensions(ext string) []string {
@@ -166,6 +157,8 @@ func parseExtensions(ext string) []string {
 }
 
 func printBanner() {
+	// The camera takes two columns but counts as one rune.
+	title := boxPad("          📷 Bulk Image Copy Tool - "+version.Version, 58)
 	fmt.Print(`
 ╔═══════════════════════════════════════════════════════════╗
 ║                                                           ║
@@ -176,23 +169,21 @@ func printBanner() {
 ║  ╚██████╗╚██████╔╝██║        ██║       ██║██║ ╚═╝ ██║     ║
 ║   ╚═════╝ ╚═════╝ ╚═╝        ╚═╝       ╚═╝╚═╝     ╚═╝     ║
 ║                                                           ║
-║          📷 Bulk Image Copy Tool - v1.0.0                 ║
+║` + title + `║
 ╚═══════════════════════════════════════════════════════════╝
 `)
 }
 
 func showMenu() int {
-	fmt.Println("┌─────────────────────────────────────┐")
-	fmt.Println("│         LỰA CHỌN THAO TÁC           │")
-	fmt.Println("├─────────────────────────────────────┤")
-	fmt.Println("│  0: Không copy (thoát)              │")
-	fmt.Println("│  1: Copy và ghi đè files cũ         │")
-	fmt.Println("│  2: Copy và bỏ qua files đã tồn tại │")
-	fmt.Println("└─────────────────────────────────────┘")
+	printBox(tr.T("cli.menu.title"), []string{
+		tr.T("cli.menu.quit"),
+		tr.T("cli.menu.overwrite"),
+		tr.T("cli.menu.skip"),
+	})
 
 	reader := bufio.NewReader(os.Stdin)
 	for {
-		fmt.Print("\n👉 Nhập lựa chọn (0/1/2): ")
+		fmt.Print(tr.T("cli.menu.prompt"))
 		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(input)
 
@@ -204,27 +195,73 @@ func showMenu() int {
 		case "2":
 			return 2
 		default:
-			fmt.Println("❌ Lựa chọn không hợp lệ. Vui lòng nhập 0, 1 hoặc 2.")
+			fmt.Println(tr.T("cli.menu.invalid"))
 		}
 	}
 }
 
+// printBox prints a framed menu with a centered title. The frame grows to
+// fit the longest line because translations differ in length.
+func printBox(title string, lines []string) {
+	width := boxWidth
+	for _, l := range append([]string{title}, lines...) {
+		width = max(width, utf8.RuneCountInString(l)+3)
+	}
+
+	fmt.Println("┌" + strings.Repeat("─", width) + "┐")
+	fmt.Println("│" + boxCenter(title, width) + "│")
+	fmt.Println("├" + strings.Repeat("─", width) + "┤")
+	for _, l := range lines {
+		fmt.Println("│" + boxPad("  "+l, width) + "│")
+	}
+	fmt.Println("└" + strings.Repeat("─", width) + "┘")
+}
+
+// boxWidth is the inner width of the CLI's framed boxes.
+const boxWidth = 37
+
+// boxPad pads s with spaces to width runes.
+func boxPad(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-utf8.RuneCountInString(s), 0))
+}
+
+// boxCenter centers s within width runes.
+func boxCenter(s string, width int) string {
+	left := max((width-utf8.RuneCountInString(s))/2, 0)
+	return boxPad(strings.Repeat(" ", left)+s, width)
+}
+
 func printConfig(cfg *config.Config) {
-	fmt.Println("\n┌─────────────────────────────────────┐")
-	fmt.Println("│          CẤU HÌNH HIỆN TẠI          │")
-	fmt.Println("├─────────────────────────────────────┤")
-	fmt.Printf("│ Source:    %s\n", cfg.Source)
-	fmt.Printf("│ Dest:      %s\n", cfg.Destination)
-	fmt.Printf("│ Workers:   %d\n", cfg.Workers)
-	fmt.Printf("│ Overwrite: %v\n", cfg.Overwrite)
-	fmt.Printf("│ Dry-run:   %v\n", cfg.DryRun)
+	row := func(key string, value any) {
+		fmt.Printf("│ %-10s %v\n", tr.T(key)+":", value)
+	}
+
+	fmt.Println("\n┌" + strings.Repeat("─", boxWidth) + "┐")
+	fmt.Println("│" + boxCenter(tr.T("cli.config.title"), boxWidth) + "│")
+	fmt.Println("├" + strings.Repeat("─", boxWidth) + "┤")
+	sources := cfg.SourcePatterns()
+	if cfg.URLsFrom != "" {
+		sources = []string{cfg.URLsFrom}
+	}
+	for _, source := range sources {
+		row("cli.config.source", source)
+	}
+	row("cli.config.destination", cfg.Destination)
+	row("cli.config.workers", cfg.Workers)
+	row("cli.config.overwrite", cfg.Overwrite)
+	row("cli.config.dry_run", cfg.DryRun)
 	if cfg.HasExtensionFilter() {
-		fmt.Printf("│ Extensions: %v\n", cfg.Extensions)
+		row("cli.config.extensions", cfg.Extensions)
 	}
-	fmt.Println("└─────────────────────────────────────┘")
+	fmt.Println("└" + strings.Repeat("─", boxWidth) + "┘")
 }
 
-func waitForKey() {
-	fmt.Print("\n⏎  Nhấn Enter để thoát...")
+// waitForKey keeps the console window open so users who double-click the exe
+// can read the results. Headless runs never wait on stdin.
+func waitForKey(headless bool) {
+	if headless {
+		return
+	}
+	fmt.Print(tr.T("cli.press_enter"))
 	_, _ = bufio.NewReader(os.Stdin).ReadBytes('\n')
 }
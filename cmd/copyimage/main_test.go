@@ -1,13 +1,33 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"copy-image/internal/config"
+	"copy-image/internal/lock"
+	"copy-image/internal/version"
 )
 
+// TestMain keeps the history and state that commands write without a
+// config file out of the real user config folder.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "copyimage-test")
+	if err != nil {
+		panic(err)
+	}
+	_ = os.Setenv("XDG_CONFIG_HOME", dir)
+	_ = os.Setenv("AppData", dir)
+	code := m.Run()
+	_ = os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 func TestParseExtensions(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -80,11 +100,21 @@ func TestPrintBanner(t *testing.T) {
 }
 
 func TestVersion(t *testing.T) {
-	if version == "" {
-		t.Error("Expected version to be set")
+	var out bytes.Buffer
+	if code := runVersion([]string{"--json"}, &out); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	var info version.Info
+	if err := json.Unmarshal(out.Bytes(), &info); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", out.String(), err)
 	}
-	if version != "1.0.0" {
-		t.Errorf("Expected version='1.0.0', got %s", version)
+	if info.Version != version.Version || info.GoVersion == "" || info.Platform == "" {
+		t.Errorf("Expected the build info, got %+v", info)
+	}
+
+	out.Reset()
+	if code := runVersion(nil, &out); code != exitOK || !strings.Contains(out.String(), "copy-image version "+version.Version) {
+		t.Errorf("Expected the version printed, got %d, %q", code, out.String())
 	}
 }
 
@@ -181,6 +211,36 @@ func TestLoadConfigNonExistentFile(t *testing.T) {
 	}
 }
 
+func TestLoadConfigEnvironment(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := "source: ${PHOTO_INBOX}/card\ndestination: /file/dest\n"
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("PHOTO_INBOX", "/srv/inbox")
+	t.Setenv("COPYIMAGE_DESTINATION", "/ci/dest")
+	t.Setenv("COPYIMAGE_WORKERS", "4")
+
+	cfg := loadConfig(configPath, "", "", false, 10, false, "")
+
+	if cfg.Source != "/srv/inbox/card" {
+		t.Errorf("Expected expanded Source='/srv/inbox/card', got %s", cfg.Source)
+	}
+	if cfg.Destination != "/ci/dest" {
+		t.Errorf("Expected COPYIMAGE_DESTINATION to override, got %s", cfg.Destination)
+	}
+	if cfg.Workers != 4 {
+		t.Errorf("Expected Workers=4 from COPYIMAGE_WORKERS, got %d", cfg.Workers)
+	}
+
+	// Flags still win over the environment.
+	cfg = loadConfig(configPath, "", "/cli/dest", false, 10, false, "")
+	if cfg.Destination != "/cli/dest" {
+		t.Errorf("Expected CLI Destination='/cli/dest', got %s", cfg.Destination)
+	}
+}
+
 func TestPrintConfig(t *testing.T) {
 	cfg := &config.Config{
 		Source:      "/test/source",
@@ -398,3 +458,89 @@ extensions:
 		t.Errorf("Expected extension '.bmp', got '%s'", cfg.Extensions[0])
 	}
 }
+
+func TestRunExitCodes(t *testing.T) {
+	newDirs := func(t *testing.T) (string, string) {
+		t.Helper()
+		src := t.TempDir()
+		if err := os.WriteFile(filepath.Join(src, "a.jpg"), []byte("a"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		return src, t.TempDir()
+	}
+
+	t.Run("success", func(t *testing.T) {
+		src, dst := newDirs(t)
+		code := run(context.Background(), []string{"-yes", "-output", "ndjson", "-config", "", "-source", src, "-dest", dst})
+		if code != exitOK {
+			t.Errorf("Expected exit code %d, got %d", exitOK, code)
+		}
+	})
+
+	t.Run("config error", func(t *testing.T) {
+		src, _ := newDirs(t)
+		code := run(context.Background(), []string{"-non-interactive", "-output", "ndjson", "-config", "", "-source", src})
+		if code != exitConfig {
+			t.Errorf("Expected exit code %d, got %d", exitConfig, code)
+		}
+	})
+
+	t.Run("invalid config file", func(t *testing.T) {
+		src, dst := newDirs(t)
+		cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(cfgPath, []byte("worker: 4\n"), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		code := run(context.Background(), []string{"-yes", "-output", "ndjson", "-config", cfgPath, "-source", src, "-dest", dst})
+		if code != exitConfig {
+			t.Errorf("Expected exit code %d, got %d", exitConfig, code)
+		}
+	})
+
+	t.Run("bad flag", func(t *testing.T) {
+		code := run(context.Background(), []string{"-no-such-flag"})
+		if code != exitConfig {
+			t.Errorf("Expected exit code %d, got %d", exitConfig, code)
+		}
+	})
+
+	t.Run("partial failure", func(t *testing.T) {
+		src, dst := newDirs(t)
+		// A regular file where the destination directory should be makes every copy fail.
+		blocked := filepath.Join(dst, "blocked")
+		if err := os.WriteFile(blocked, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create blocker: %v", err)
+		}
+		code := run(context.Background(), []string{"-yes", "-output", "ndjson", "-config", "", "-source", src, "-dest", blocked})
+		if code != exitPartial {
+			t.Errorf("Expected exit code %d, got %d", exitPartial, code)
+		}
+	})
+
+	t.Run("destination locked", func(t *testing.T) {
+		src, dst := newDirs(t)
+		lease, err := lock.Acquire(context.Background(), dst, lock.Options{})
+		if err != nil {
+			t.Fatalf("Failed to lock destination: %v", err)
+		}
+		defer func() { _ = lease.Release() }()
+
+		code := run(context.Background(), []string{"-yes", "-output", "ndjson", "-config", "", "-source", src, "-dest", dst})
+		if code != exitLocked {
+			t.Errorf("Expected exit code %d, got %d", exitLocked, code)
+		}
+		if _, err := os.Stat(filepath.Join(dst, "a.jpg")); !os.IsNotExist(err) {
+			t.Error("A locked destination must not receive files")
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		src, dst := newDirs(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		code := run(ctx, []string{"-yes", "-output", "ndjson", "-config", "", "-source", src, "-dest", dst})
+		if code != exitCancelled {
+			t.Errorf("Expected exit code %d, got %d", exitCancelled, code)
+		}
+	})
+}
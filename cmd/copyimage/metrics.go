@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"copy-image/internal/copier"
+	"copy-image/internal/metrics"
+)
+
+// addMetricsFlag registers -metrics on the flag sets of long-running
+// commands.
+func addMetricsFlag(fs *flag.FlagSet) *string {
+	return fs.String("metrics", "", "Serve Prometheus metrics at /metrics on this address (e.g. :9464) while running")
+}
+
+// startMetrics serves metrics on addr until ctx is cancelled and returns
+// the collector to attach to copiers, or nil when addr is empty.
+func startMetrics(ctx context.Context, addr string) (copier.Metrics, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	m := metrics.New()
+	if err := m.Start(ctx, addr); err != nil {
+		return nil, err
+	}
+	ui.log.Info("serving metrics", "address", addr, "path", metrics.Path)
+	return m, nil
+}
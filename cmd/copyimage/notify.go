@@ -0,0 +1,9 @@
+package main
+
+// warnNotify logs a webhook or email that could not be sent. Notifications
+// never fail a copy.
+func warnNotify(err error) {
+	if err != nil {
+		ui.log.Warn("notification not sent", "error", err)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"time"
+
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	"copy-image/internal/hooks"
+	"copy-image/internal/lock"
+	"copy-image/internal/notify"
+)
+
+// offloadResult is the machine-readable output of `copyimage offload`,
+// written after the copy summary.
+type offloadResult struct {
+	Type   string               `json:"type"`
+	Verify *copier.VerifyReport `json:"verify"`
+	Seal   string               `json:"seal"`
+	Sealed bool                 `json:"sealed"`
+}
+
+// runOffloadCommand implements `copyimage offload`: copy, then compare
+// every copied file with its source by SHA-256, and only when all of them
+// match seal the source with -seal. It is meant for camera cards, where a
+// card must not be formatted until its copies are known to be good.
+func runOffloadCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("offload", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	seal := fs.String("seal", copier.SealNone, "After a clean verify: none, readonly (make source files read-only) or marker (write a .verified file into the source)")
+	lockWait := fs.Duration("lock-wait", 0, "How long to wait if another session is writing to the destination (0 = refuse)")
+	otlp := addTracingFlag(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	cfg, ok := common.load()
+	if !ok {
+		return exitConfig
+	}
+	if err := cfg.Validate(); err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+	if err := copier.CheckSeal(*seal); err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+	if cfg.DryRun {
+		ui.Error(tr.T("cli.config_error"), errors.New("an offload cannot be a dry run"))
+		return exitConfig
+	}
+
+	flush, err := startTracing(ctx, *otlp)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	defer flush()
+
+	c := copier.New(cfg)
+	ui.Println(tr.T("cli.scanning"))
+	files, err := c.GetFilesContext(copier.WithScanProgress(ctx, ui.ScanProgress))
+	if err != nil && ctx.Err() != nil {
+		ui.Println(tr.T("cli.cancelled"))
+		return exitCancelled
+	}
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	ui.Printf("%s\n\n", tr.T("cli.found_files", len(files)))
+
+	copyCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	release := func() {}
+	hk := hooks.New(cfg)
+	batch := hooks.Batch{Kind: history.KindOffload, Copier: c, Files: len(files)}
+	if err = c.Err(); err == nil {
+		if err = hk.Before(copyCtx, batch); err != nil {
+			warnHook(hk.Aborted(ctx, batch, err))
+		} else {
+			release, err = lockDestination(copyCtx, cfg.LockDir(), false, *lockWait)
+		}
+	}
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		switch {
+		case errors.Is(err, lock.ErrLocked):
+			return exitLocked
+		case errors.Is(err, context.Canceled):
+			return exitCancelled
+		default:
+			return exitPartial
+		}
+	}
+	defer release()
+
+	notes := notify.New(cfg)
+	warnNotify(notes.Started(copyCtx, history.KindOffload, "", c, len(files)))
+	start := time.Now()
+	summary := runCopy(copyCtx, c, files)
+	warnNotify(notes.Finished(copyCtx, history.KindOffload, "", c, summary, copyCtx.Err() != nil))
+	warnHook(hk.After(copyCtx, batch, summary, copyCtx.Err() != nil))
+	openHistory(*common.configFile).record(history.KindOffload, "", c, start, summary, copyCtx.Err() != nil)
+	ui.Summary(summary, false)
+	if copyCtx.Err() != nil {
+		ui.Println(tr.T("cli.cancelled"))
+		return exitCancelled
+	}
+
+	// Files that failed to copy show up as missing or mismatched, so the
+	// report alone decides whether the source may be sealed.
+	ui.Println(tr.T("offload.verifying"))
+	report, err := c.VerifyFiles(copyCtx, files, true)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		if copyCtx.Err() != nil {
+			return exitCancelled
+		}
+		return exitPartial
+	}
+	for _, name := range report.Missing {
+		ui.Outputf("%s\n", tr.T("verify.missing", name))
+	}
+	for _, name := range report.Mismatched {
+		ui.Outputf("%s\n", tr.T("verify.mismatched", name))
+	}
+	ui.Outputf("%s\n", tr.T("verify.verified", report.OK, report.Checked))
+
+	result := offloadResult{Type: "offload", Verify: report, Seal: *seal}
+	if !report.Clean() || summary.Failed > 0 {
+		ui.Result(result)
+		ui.Println(tr.T("offload.not_sealed"))
+		return exitPartial
+	}
+	if err := c.Seal(*seal, files); err != nil {
+		ui.Result(result)
+		ui.Error(tr.T("cli.error"), err)
+		return exitPartial
+	}
+	result.Sealed = *seal != copier.SealNone
+	ui.Result(result)
+	if result.Sealed {
+		ui.Println(tr.T("offload.sealed", *seal))
+	}
+	return exitOK
+}
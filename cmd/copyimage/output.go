@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/i18n"
+	"copy-image/internal/logging"
+)
+
+// outputMode controls how the CLI reports progress and results.
+// Plain mode is the human-friendly terminal UI; the JSON modes are meant
+// for scripts and CI pipelines that need to parse the output.
+type outputMode string
+
+const (
+	outputPlain  outputMode = "plain"
+	outputJSON   outputMode = "json"
+	outputNDJSON outputMode = "ndjson"
+)
+
+// parseOutputMode converts the -output flag value into an outputMode.
+func parseOutputMode(s string) (outputMode, error) {
+	switch outputMode(s) {
+	case outputPlain, outputJSON, outputNDJSON:
+		return outputMode(s), nil
+	default:
+		return "", fmt.Errorf("invalid output mode %q (expected plain, json or ndjson)", s)
+	}
+}
+
+// fileEvent describes the outcome of a single file in machine-readable output.
+type fileEvent struct {
+	Type     string  `json:"type"`
+	Current  int     `json:"current"`
+	Total    int     `json:"total"`
+	Percent  float64 `json:"percent"`
+	FileName string  `json:"fileName"`
+	Status   string  `json:"status"`
+	// Action is what a dry-run found the copy would do, e.g. "overwrite".
+	Action string `json:"action,omitempty"`
+	// ErrorCode is the kind of error a file failed with, e.g. "locked".
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+// summaryReport is the JSON representation of a copier.CopySummary.
+// Duration is reported in seconds because that is what scripts usually expect.
+type summaryReport struct {
+	Type        string      `json:"type"`
+	TotalFiles  int         `json:"totalFiles"`
+	Successful  int         `json:"successful"`
+	Failed      int         `json:"failed"`
+	Skipped     int         `json:"skipped"`
+	FailedFiles []string    `json:"failedFiles"`
+	Corrupt     int         `json:"corrupt"`
+	Duration    float64     `json:"duration"`
+	DryRun      bool        `json:"dryRun"`
+	Files       []fileEvent `json:"files,omitempty"`
+
+	// Bytes written, replaced at the destination and skipped, and the
+	// bytes written per second.
+	TotalBytes       int64   `json:"totalBytes"`
+	OverwrittenBytes int64   `json:"overwrittenBytes"`
+	SkippedBytes     int64   `json:"skippedBytes"`
+	BytesPerSecond   float64 `json:"bytesPerSecond"`
+
+	// Groups breaks the summary of a groups run down by group and
+	// destination.
+	Groups []copier.GroupSummary `json:"groups,omitempty"`
+}
+
+// reporter writes CLI output in the selected mode.
+// In plain mode it behaves like fmt.Printf; in JSON modes the decorative
+// messages are suppressed and only structured records are written.
+// Verbosity decides how chatty plain mode is and what the logger shows.
+type reporter struct {
+	mode      outputMode
+	w         io.Writer
+	verbosity int
+	log       *slog.Logger
+
+	mu    sync.Mutex
+	files []fileEvent
+}
+
+// ui is the reporter used by the CLI. It defaults to plain output so helper
+// functions behave the same when called from tests.
+var ui = newReporter(outputPlain, os.Stdout)
+
+// tr translates CLI messages. It follows the OS language until loadConfig
+// switches it to the `language` set in config.yaml.
+var tr = i18n.New(i18n.Resolve(""))
+
+// newReporter creates a reporter that writes to w in the given mode.
+func newReporter(mode outputMode, w io.Writer) *reporter {
+	return &reporter{mode: mode, w: w, log: logging.Discard()}
+}
+
+// setVerbosity applies the -q/-v flags. Logs go to logW (stderr) so they
+// never mix with the JSON records written to stdout.
+func (r *reporter) setVerbosity(v int, logW io.Writer) {
+	r.verbosity = v
+	r.log = logging.New(logW, v, !r.isPlain())
+}
+
+// isPlain reports whether the human-friendly terminal UI is enabled.
+func (r *reporter) isPlain() bool {
+	return r.mode == outputPlain
+}
+
+// isChatty reports whether decorative plain-mode output (banner, config
+// box, status lines) should be printed; -q turns it off.
+func (r *reporter) isChatty() bool {
+	return r.isPlain() && r.verbosity > logging.Quiet
+}
+
+// showProgressBar reports whether the terminal progress bar is used. With
+// -q there is no bar, and with -v the per-file log lines replace it.
+func (r *reporter) showProgressBar() bool {
+	return r.isPlain() && r.verbosity == logging.Normal
+}
+
+// Printf prints a decorative message in plain mode and does nothing otherwise.
+func (r *reporter) Printf(format string, args ...any) {
+	if r.isChatty() {
+		_, _ = fmt.Fprintf(r.w, format, args...)
+	}
+}
+
+// Outputf prints a command's own result in plain mode, even with -q.
+func (r *reporter) Outputf(format string, args ...any) {
+	if r.isPlain() {
+		_, _ = fmt.Fprintf(r.w, format, args...)
+	}
+}
+
+// Println prints a decorative line in plain mode and does nothing otherwise.
+func (r *reporter) Println(args ...any) {
+	if r.isChatty() {
+		_, _ = fmt.Fprintln(r.w, args...)
+	}
+}
+
+// Start records the beginning of a copy batch.
+func (r *reporter) Start(total int) {
+	if r.mode == outputNDJSON {
+		r.writeRecord(map[string]any{"type": "start", "total": total})
+	}
+}
+
+// ScanProgress shows how far the scan of the source got: a line updated
+// in place in plain mode, a scan record per report in NDJSON mode.
+func (r *reporter) ScanProgress(p copier.ScanProgress) {
+	switch {
+	case r.mode == outputNDJSON:
+		r.writeRecord(map[string]any{"type": "scan", "entries": p.Entries, "files": p.Files, "done": p.Done})
+	case r.showProgressBar():
+		end := ""
+		if p.Done {
+			end = "\n"
+		}
+		r.mu.Lock()
+		_, _ = fmt.Fprintf(r.w, "\r"+tr.T("cli.scan_progress")+end, p.Entries, p.Files)
+		r.mu.Unlock()
+	}
+}
+
+// Progress records the outcome of each file from the copier's events, and
+// in NDJSON mode the destination going offline and coming back. Plain
+// output gets those from the copier's warnings.
+func (r *reporter) Progress(event copier.Event) {
+	var f copier.FileFinished
+	switch ev := event.(type) {
+	case copier.FileFinished:
+		f = ev
+	case copier.DestinationOffline:
+		if r.mode == outputNDJSON {
+			r.writeRecord(map[string]any{"type": "offline", "destination": ev.Path, "message": ev.Err.Error(), "wait": ev.Wait.Seconds()})
+		}
+		return
+	case copier.DestinationOnline:
+		if r.mode == outputNDJSON {
+			r.writeRecord(map[string]any{"type": "online", "destination": ev.Path, "downtime": ev.Downtime.Seconds()})
+		}
+		return
+	default:
+		return
+	}
+	ev := fileEvent{
+		Type:      "progress",
+		Current:   f.Current,
+		Total:     f.Total,
+		FileName:  f.Result.FileName,
+		Status:    f.Status,
+		Action:    f.Result.Action,
+		ErrorCode: copier.ErrorCode(f.Result.Error),
+	}
+	if f.Total > 0 {
+		ev.Percent = f.Percent()
+	}
+
+	switch r.mode {
+	case outputNDJSON:
+		r.writeRecord(ev)
+	case outputJSON:
+		r.mu.Lock()
+		r.files = append(r.files, ev)
+		r.mu.Unlock()
+	}
+}
+
+// Summary writes the final result of a copy batch.
+func (r *reporter) Summary(summary copier.CopySummary, dryRun bool) {
+	if r.isPlain() {
+		summary.PrintSummary()
+		return
+	}
+	r.writeSummary(r.summaryReport(summary, dryRun))
+}
+
+// BatchSummary writes the final result of a groups run: the total, and
+// in JSON modes also each group and destination.
+func (r *reporter) BatchSummary(batch copier.BatchSummary, dryRun bool) {
+	total := batch.Total()
+	if r.isPlain() {
+		total.PrintSummary()
+		return
+	}
+	report := r.summaryReport(total, dryRun)
+	report.Groups = batch.Groups
+	if report.Groups == nil {
+		report.Groups = []copier.GroupSummary{}
+	}
+	r.writeSummary(report)
+}
+
+func (r *reporter) summaryReport(summary copier.CopySummary, dryRun bool) summaryReport {
+	return summaryReport{
+		Type:        "summary",
+		TotalFiles:  summary.TotalFiles,
+		Successful:  summary.Successful,
+		Failed:      summary.Failed,
+		Skipped:     summary.Skipped,
+		FailedFiles: summary.FailureLines(),
+		Corrupt:     summary.Corrupt,
+		Duration:    summary.Duration.Seconds(),
+		DryRun:      dryRun,
+
+		TotalBytes:       summary.TotalBytes,
+		OverwrittenBytes: summary.OverwrittenBytes,
+		SkippedBytes:     summary.SkippedBytes,
+		BytesPerSecond:   summary.Throughput(),
+	}
+}
+
+// writeSummary writes report, with the files reported so far in JSON
+// mode.
+func (r *reporter) writeSummary(report summaryReport) {
+	if r.mode == outputJSON {
+		r.mu.Lock()
+		report.Files = r.files
+		r.mu.Unlock()
+		r.writeIndented(report)
+		return
+	}
+	r.writeRecord(report)
+}
+
+// Error reports a fatal error. Plain mode prints the label with the usual
+// emoji prefix; JSON modes emit an error record instead.
+func (r *reporter) Error(label string, err error) {
+	if r.isPlain() {
+		_, _ = fmt.Fprintf(r.w, "❌ %s: %v\n", label, err)
+		return
+	}
+	record := map[string]any{"type": "error", "message": err.Error()}
+	var verr *config.ValidationError
+	if errors.As(err, &verr) {
+		record["problems"] = verr.Problems
+	}
+	r.writeRecord(record)
+}
+
+// Result writes a command-specific result document. JSON mode pretty-prints
+// it; NDJSON mode writes it as a single line. Plain mode ignores it because
+// commands print their own human-friendly output.
+func (r *reporter) Result(v any) {
+	switch r.mode {
+	case outputJSON:
+		r.writeIndented(v)
+	case outputNDJSON:
+		r.writeRecord(v)
+	}
+}
+
+// writeRecord writes v as a single JSON line.
+func (r *reporter) writeRecord(v any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_ = json.NewEncoder(r.w).Encode(v)
+}
+
+// writeIndented writes v as a human-readable JSON document.
+func (r *reporter) writeIndented(v any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/logging"
+)
+
+func TestParseOutputMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    outputMode
+		wantErr bool
+	}{
+		{input: "plain", want: outputPlain},
+		{input: "json", want: outputJSON},
+		{input: "ndjson", want: outputNDJSON},
+		{input: "xml", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseOutputMode(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReporterPlainSuppressesNothing(t *testing.T) {
+	var buf bytes.Buffer
+	r := newReporter(outputPlain, &buf)
+
+	r.Printf("hello %s\n", "world")
+	r.Println("line")
+
+	if !strings.Contains(buf.String(), "hello world") || !strings.Contains(buf.String(), "line") {
+		t.Errorf("Expected plain messages in output, got %q", buf.String())
+	}
+}
+
+func TestReporterNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	r := newReporter(outputNDJSON, &buf)
+
+	r.Printf("decorative %d\n", 1)
+	r.Start(2)
+	r.Progress(copier.FileStarted{Path: "in/a.jpg", Total: 2})
+	r.Progress(copier.FileFinished{Result: copier.CopyResult{FileName: "a.jpg"}, Status: copier.StatusSuccess, Current: 1, Total: 2})
+	r.Progress(copier.DestinationOffline{Path: "/nas", Err: errors.New("network error"), Wait: time.Minute})
+	r.Progress(copier.DestinationOnline{Path: "/nas", Downtime: time.Second})
+	r.Progress(copier.FileFinished{Result: copier.CopyResult{FileName: "b.jpg"}, Status: copier.StatusFailed, Current: 2, Total: 2})
+	r.Summary(copier.CopySummary{
+		TotalFiles:  2,
+		Successful:  1,
+		Failed:      1,
+		Duration:    2 * time.Second,
+		FailedFiles: []copier.FailedFile{{Path: "/cards/b.jpg", Err: errors.New("boom")}},
+	}, false)
+
+	var types []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var rec map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("Line is not valid JSON: %q (%v)", scanner.Text(), err)
+		}
+		types = append(types, rec["type"].(string))
+
+		if rec["type"] == "summary" && rec["duration"].(float64) != 2 {
+			t.Errorf("Expected duration=2 seconds, got %v", rec["duration"])
+		}
+	}
+
+	want := []string{"start", "progress", "offline", "online", "progress", "summary"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected record types %v, got %v", want, types)
+	}
+}
+
+func TestReporterJSONSingleDocument(t *testing.T) {
+	var buf bytes.Buffer
+	r := newReporter(outputJSON, &buf)
+
+	r.Start(1)
+	r.Progress(copier.FileFinished{Result: copier.CopyResult{FileName: "a.jpg"}, Status: copier.StatusSuccess, Current: 1, Total: 1})
+	r.Summary(copier.CopySummary{TotalFiles: 1, Successful: 1}, true)
+
+	var report summaryReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Output is not a single JSON document: %v\n%s", err, buf.String())
+	}
+	if report.TotalFiles != 1 || report.Successful != 1 {
+		t.Errorf("Unexpected summary: %+v", report)
+	}
+	if !report.DryRun {
+		t.Error("Expected dryRun=true")
+	}
+	if len(report.Files) != 1 || report.Files[0].FileName != "a.jpg" {
+		t.Errorf("Expected per-file results in document, got %+v", report.Files)
+	}
+	if report.FailedFiles == nil {
+		t.Error("Expected failedFiles to be an empty array, not null")
+	}
+}
+
+func TestReporterBatchSummary(t *testing.T) {
+	var buf bytes.Buffer
+	r := newReporter(outputNDJSON, &buf)
+
+	r.BatchSummary(copier.BatchSummary{Groups: []copier.GroupSummary{
+		{GroupID: "cards", Destinations: []copier.DestinationSummary{
+			{DestinationID: "nas", Path: "/nas", Summary: copier.CopySummary{TotalFiles: 2, Successful: 2}},
+			{DestinationID: "usb", Path: "/usb", Summary: copier.CopySummary{TotalFiles: 2, Successful: 1, Failed: 1}},
+		}},
+	}}, false)
+
+	var report struct {
+		summaryReport
+		Groups []struct {
+			GroupID string `json:"groupId"`
+			Total   struct {
+				TotalFiles int `json:"totalFiles"`
+			} `json:"total"`
+			Destinations []json.RawMessage `json:"destinations"`
+		} `json:"groups"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Expected a JSON summary record: %v\n%s", err, buf.String())
+	}
+	if report.Type != "summary" || report.TotalFiles != 4 || report.Failed != 1 {
+		t.Errorf("Expected the total of the batch, got %s", buf.String())
+	}
+	if len(report.Groups) != 1 || report.Groups[0].GroupID != "cards" || report.Groups[0].Total.TotalFiles != 4 || len(report.Groups[0].Destinations) != 2 {
+		t.Errorf("Expected the group with its destinations, got %s", buf.String())
+	}
+}
+
+func TestReporterError(t *testing.T) {
+	var buf bytes.Buffer
+	r := newReporter(outputNDJSON, &buf)
+
+	r.Error("Configuration error", errors.New("source path is required"))
+
+	var rec map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("Expected JSON error record: %v", err)
+	}
+	if rec["type"] != "error" || rec["message"] != "source path is required" {
+		t.Errorf("Unexpected error record: %v", rec)
+	}
+
+	buf.Reset()
+	newReporter(outputPlain, &buf).Error("Lỗi", errors.New("boom"))
+	if !strings.Contains(buf.String(), "Lỗi: boom") {
+		t.Errorf("Expected plain error message, got %q", buf.String())
+	}
+
+	// Validation errors carry their problems so tools can point at fields.
+	buf.Reset()
+	r.w = &buf
+	r.Error("Configuration error", (&config.Config{}).Validate())
+	var verr struct {
+		Problems []config.Problem `json:"problems"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &verr); err != nil {
+		t.Fatalf("Expected JSON error record: %v", err)
+	}
+	if len(verr.Problems) != 2 || verr.Problems[0].Field != "source" {
+		t.Errorf("Expected source and destination problems, got %+v", verr.Problems)
+	}
+}
+
+func TestReporterVerbosity(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity int
+		chatty    bool
+		bar       bool
+		logLines  int
+	}{
+		{"quiet", logging.Quiet, false, false, 0},
+		{"normal", logging.Normal, true, true, 0},
+		{"verbose", logging.Verbose, true, false, 1},
+		{"debug", logging.Debug, true, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, logs bytes.Buffer
+			r := newReporter(outputPlain, &out)
+			r.setVerbosity(tt.verbosity, &logs)
+
+			r.Println("decoration")
+			r.Outputf("result\n")
+			r.log.Info("copied")
+			r.log.Debug("retrying")
+
+			if got := strings.Contains(out.String(), "decoration"); got != tt.chatty {
+				t.Errorf("Expected decoration printed=%v, got output %q", tt.chatty, out.String())
+			}
+			if !strings.Contains(out.String(), "result") {
+				t.Error("Command results must always be printed")
+			}
+			if r.showProgressBar() != tt.bar {
+				t.Errorf("Expected showProgressBar()=%v", tt.bar)
+			}
+			if n := strings.Count(logs.String(), "\n"); n != tt.logLines {
+				t.Errorf("Expected %d log lines, got %d: %q", tt.logLines, n, logs.String())
+			}
+		})
+	}
+}
+
+func TestVerbosityFlags(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{nil, logging.Normal},
+		{[]string{"-q"}, logging.Quiet},
+		{[]string{"--quiet"}, logging.Quiet},
+		{[]string{"-v"}, logging.Verbose},
+		{[]string{"-v", "-v"}, logging.Debug},
+		{[]string{"-vv"}, logging.Debug},
+	}
+
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		level := addVerbosityFlags(fs)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("Parse(%v) failed: %v", tt.args, err)
+		}
+		if *level != tt.expected {
+			t.Errorf("Parse(%v): expected verbosity %d, got %d", tt.args, tt.expected, *level)
+		}
+	}
+}
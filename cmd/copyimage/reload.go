@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"copy-image/internal/config"
+)
+
+// configReload collects changes to the config file made while a long run
+// is in progress, so they can be applied between batches rather than in
+// the middle of one.
+type configReload struct {
+	mu     sync.Mutex
+	latest *config.Config
+}
+
+// watchConfig starts watching the config file named by the -config flag
+// value. Without a config file, or when it cannot be watched, the returned
+// reload never reports a change.
+func watchConfig(ctx context.Context, configFile string) *configReload {
+	r := &configReload{}
+	path := resolveConfigPath(configFile)
+	if path == "" {
+		return r
+	}
+
+	err := config.Watch(ctx, path, func(cfg *config.Config, err error) {
+		if err == nil {
+			// Environment overrides keep winning over the file.
+			err = cfg.ApplyEnv()
+		}
+		if err == nil {
+			cfg.ExpandEnv()
+			err = cfg.Validate()
+		}
+		if err != nil {
+			// Keep running with the settings that were valid.
+			ui.log.Warn("ignoring config change", "path", path, "error", err)
+			return
+		}
+
+		r.mu.Lock()
+		r.latest = cfg
+		r.mu.Unlock()
+	})
+	if err != nil {
+		ui.log.Warn("config changes will not be picked up", "error", err)
+	}
+	return r
+}
+
+// take returns the config saved since the last call, or nil.
+func (r *configReload) take() *config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cfg := r.latest
+	r.latest = nil
+	return cfg
+}
+
+// applyExcludes updates cfg and group with the excludes of a reloaded
+// config. Only the excludes are picked up: they are safe to change between
+// groups, while other settings (sources, destinations) define the run.
+func (r *configReload) applyExcludes(cfg *config.Config, group *config.CopyGroup) {
+	newer := r.take()
+	if newer == nil {
+		return
+	}
+	cfg.Exclude = newer.Exclude
+	if g := newer.FindGroup(group.ID); g != nil {
+		group.Exclude = g.Exclude
+		for i, dest := range group.Destinations {
+			for _, d := range g.Destinations {
+				if d.ID == dest.ID {
+					group.Destinations[i].Exclude = d.Exclude
+				}
+			}
+		}
+	}
+	ui.Println(tr.T("cli.reloaded_config"))
+}
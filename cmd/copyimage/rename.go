@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"copy-image/internal/config"
+	"copy-image/internal/rename"
+)
+
+// runRename implements `copyimage rename`, which applies a rename template
+// to files that are already in a directory (e.g. an existing archive).
+// It returns the process exit code.
+func runRename(args []string, w io.Writer) int {
+	fs := flag.NewFlagSet("rename", flag.ContinueOnError)
+	fs.SetOutput(w)
+	template := fs.String("template", "", "Rename template, e.g. {date:YYYY-MM-DD}_{seq:4}")
+	path := fs.String("path", "", "Directory containing the files to rename")
+	dryRun := fs.Bool("dry-run", false, "Preview the renames without applying them")
+	extensions := fs.String("ext", "", "Comma-separated list of extensions to include (e.g., .jpg,.png)")
+	undo := fs.String("undo", "", "Revert a previous rename using its undo log")
+
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if *undo != "" {
+		ops, err := rename.Undo(*undo)
+		if err != nil {
+			_, _ = fmt.Fprintf(w, "❌ %s: %v\n", tr.T("cli.error"), err)
+			return exitPartial
+		}
+		_, _ = fmt.Fprintf(w, "↩️  Restored %d file(s)\n", len(ops))
+		return exitOK
+	}
+
+	if *template == "" || *path == "" {
+		_, _ = fmt.Fprintln(w, "❌ Both -template and -path are required")
+		fs.Usage()
+		return exitConfig
+	}
+
+	tmpl, err := rename.Parse(*template)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "❌ Invalid template: %v\n", err)
+		return exitConfig
+	}
+
+	filterCfg := &config.Config{Extensions: parseExtensions(*extensions)}
+	filter := func(name string) bool {
+		return filterCfg.IsExtensionAllowed(strings.ToLower(filepath.Ext(name)))
+	}
+
+	ops, err := rename.Plan(*path, tmpl, filter)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "❌ %s: %v\n", tr.T("cli.error"), err)
+		return exitPartial
+	}
+
+	if len(ops) == 0 {
+		_, _ = fmt.Fprintln(w, "✅ Nothing to rename - all names already match the template")
+		return exitOK
+	}
+
+	for _, op := range ops {
+		_, _ = fmt.Fprintf(w, "  %s → %s\n", op.From, op.To)
+	}
+
+	if *dryRun {
+		_, _ = fmt.Fprintf(w, "\n🔄 [DRY-RUN MODE] %d file(s) would be renamed\n", len(ops))
+		return exitOK
+	}
+
+	logPath, err := rename.Apply(*path, tmpl, ops)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "❌ %s: %v\n", tr.T("cli.error"), err)
+		if logPath != "" {
+			_, _ = fmt.Fprintf(w, "   Undo log: %s\n", logPath)
+		}
+		return exitPartial
+	}
+
+	_, _ = fmt.Fprintf(w, "\n✅ Renamed %d file(s)\n", len(ops))
+	_, _ = fmt.Fprintf(w, "   Undo with: copyimage rename -undo %q\n", logPath)
+	return exitOK
+}
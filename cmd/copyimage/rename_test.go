@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRunRenameDryRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.jpg"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	code := runRename([]string{"-template", "img_{seq:3}", "-path", dir, "-dry-run"}, &buf)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, buf.String())
+	}
+	if !strings.Contains(buf.String(), "a.jpg → img_001.jpg") {
+		t.Errorf("Expected preview line, got %q", buf.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.jpg")); err != nil {
+		t.Error("Dry-run must not rename files")
+	}
+}
+
+func TestRunRenameApplyAndUndo(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.jpg"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if code := runRename([]string{"-template", "img_{seq}", "-path", dir}, &buf); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, buf.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "img_1.jpg")); err != nil {
+		t.Fatalf("Expected renamed file: %v", err)
+	}
+
+	m := regexp.MustCompile(`-undo "(.+)"`).FindStringSubmatch(buf.String())
+	if m == nil {
+		t.Fatalf("Expected undo hint in output, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if code := runRename([]string{"-undo", m[1]}, &buf); code != 0 {
+		t.Fatalf("Undo failed with code %d: %s", code, buf.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.jpg")); err != nil {
+		t.Error("Expected original name to be restored")
+	}
+}
+
+func TestRunRenameMissingArgs(t *testing.T) {
+	var buf bytes.Buffer
+	if code := runRename([]string{"-path", t.TempDir()}, &buf); code == 0 {
+		t.Error("Expected non-zero exit code without -template")
+	}
+	if code := runRename([]string{"-template", "{bogus}", "-path", t.TempDir()}, &buf); code == 0 {
+		t.Error("Expected non-zero exit code for invalid template")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"copy-image/internal/copier"
+)
+
+// resultRecord is one line of the per-file result log.
+type resultRecord struct {
+	Time        time.Time `json:"time"`
+	Source      string    `json:"source"`
+	Destination string    `json:"destination"`
+	// OriginalName is set when the filename policy renamed the copy.
+	OriginalName string `json:"originalName,omitempty"`
+	Status       string `json:"status"`
+	Bytes        int64  `json:"bytes"`
+	DurationMs   int64  `json:"durationMs"`
+	Error        string `json:"error,omitempty"`
+	ErrorCode    string `json:"errorCode,omitempty"`
+	SHA256       string `json:"sha256,omitempty"`
+	DryRun       bool   `json:"dryRun,omitempty"`
+	// Action is what the dry-run found the copy would do.
+	Action string `json:"action,omitempty"`
+}
+
+// resultLog appends one NDJSON record per copied file to a file as the run
+// progresses, so log shippers (Filebeat, Promtail, ...) can tail it.
+type resultLog struct {
+	mu     sync.Mutex
+	f      *os.File
+	enc    *json.Encoder
+	dryRun bool
+}
+
+// openResultLog opens path for appending, creating it if needed.
+// It returns nil when path is empty so callers can skip logging.
+func openResultLog(path string, dryRun bool) (*resultLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open result log: %w", err)
+	}
+	return &resultLog{f: f, enc: json.NewEncoder(f), dryRun: dryRun}, nil
+}
+
+// attach makes c write its results to the log. It is a no-op on a nil log.
+func (l *resultLog) attach(c *copier.Copier) {
+	if l != nil {
+		c.OnResult(l.record)
+	}
+}
+
+// record writes a single result. Each record is written with one Write
+// call on an O_APPEND file so lines stay whole even if a reader tails it.
+func (l *resultLog) record(r copier.CopyResult) {
+	rec := resultRecord{
+		Time:         time.Now(),
+		Source:       r.SourcePath,
+		Destination:  r.DestPath,
+		OriginalName: r.OriginalName,
+		Status:       "success",
+		Bytes:        r.Bytes,
+		DurationMs:   r.Duration.Milliseconds(),
+		SHA256:       r.Hash,
+		DryRun:       l.dryRun,
+		Action:       r.Action,
+	}
+	switch {
+	case r.Skipped:
+		rec.Status = "skipped"
+	case !r.Success:
+		rec.Status = "failed"
+	}
+	if r.Error != nil {
+		rec.Error, rec.ErrorCode = r.Error.Error(), copier.ErrorCode(r.Error)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_ = l.enc.Encode(rec)
+}
+
+// Close closes the log file. It is a no-op on a nil log.
+func (l *resultLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.f.Close()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResultLog(t *testing.T) {
+	_, src, dst := writeGroupConfig(t)
+	logPath := filepath.Join(t.TempDir(), "results.ndjson")
+	args := []string{"-yes", "-output", "ndjson", "-config", "", "-source", src, "-dest", dst, "-result-log", logPath}
+
+	if code := run(context.Background(), args); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	// The log is appended to, so a second run adds skipped records.
+	if code := run(context.Background(), args); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatalf("Failed to open result log: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	counts := map[string]int{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec resultRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("Invalid record %q: %v", scanner.Text(), err)
+		}
+		counts[rec.Status]++
+		if rec.Status == "success" && (rec.SHA256 == "" || rec.Bytes == 0 || rec.Destination == "") {
+			t.Errorf("Incomplete success record: %+v", rec)
+		}
+	}
+	if counts["success"] != 2 || counts["skipped"] != 2 {
+		t.Errorf("Expected 2 success and 2 skipped records, got %v", counts)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"copy-image/internal/updater"
+)
+
+// runRollbackCommand implements `copyimage rollback`: it puts back the
+// version the last update replaced, for when a new release breaks a
+// workflow and there is no time to wait for a fix. Running it again undoes
+// the rollback.
+func runRollbackCommand(_ context.Context, args []string) int {
+	fs := flag.NewFlagSet("rollback", flag.ContinueOnError)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 0 {
+		fmt.Println("Usage: copyimage rollback")
+		return exitConfig
+	}
+
+	u, err := updater.New()
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	if err := u.Rollback(); err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		if errors.Is(err, updater.ErrNoPrevious) {
+			return exitConfig
+		}
+		return exitPartial
+	}
+	ui.Outputf("%s\n", tr.T("rollback.done", u.Path(), u.Path()+".old"))
+	return exitOK
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+
+	"copy-image/internal/copier"
+)
+
+// scanResult is the machine-readable output of `copyimage scan`.
+type scanResult struct {
+	Type   string   `json:"type"`
+	Source string   `json:"source"`
+	Total  int      `json:"total"`
+	Files  []string `json:"files"`
+}
+
+// runScanCommand implements `copyimage scan`, which lists the files that a
+// copy would pick up without touching the destination.
+func runScanCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	cfg, ok := common.load()
+	if !ok {
+		return exitConfig
+	}
+
+	files, err := copier.New(cfg).GetFilesContext(ctx)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+
+	if files == nil {
+		files = []string{}
+	}
+	ui.Result(scanResult{Type: "scan", Source: cfg.Source, Total: len(files), Files: files})
+
+	for _, f := range files {
+		ui.Printf("  %s\n", filepath.Base(f))
+	}
+	ui.Outputf("%s\n", tr.T("cli.found_files", len(files)))
+	return exitOK
+}
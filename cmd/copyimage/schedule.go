@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	"copy-image/internal/hooks"
+	"copy-image/internal/notify"
+	"copy-image/internal/schedule"
+)
+
+// runScheduleCommand implements `copyimage schedule list|run`.
+func runScheduleCommand(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Println("Usage: copyimage schedule list | schedule run [flags]")
+		return exitConfig
+	}
+
+	switch args[0] {
+	case "list":
+		return runScheduleList(args[1:])
+	case "run":
+		return runScheduleRun(ctx, args[1:])
+	default:
+		fmt.Printf("❌ Unknown schedule command %q (expected list or run)\n", args[0])
+		return exitConfig
+	}
+}
+
+// runScheduleList prints the scheduled groups with their next and last run.
+func runScheduleList(args []string) int {
+	fs := flag.NewFlagSet("schedule list", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	cfg, ok := common.load()
+	if !ok {
+		return exitConfig
+	}
+	s := schedule.New(nil, dataPath(*common.configFile, schedule.StateFile))
+	if err := s.SetJobs(schedule.Jobs(cfg)); err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+
+	status := s.Status()
+	ui.Result(status)
+	if len(status) == 0 {
+		ui.Println(tr.T("schedule.none"))
+	}
+	for _, st := range status {
+		last := "never run"
+		if r := st.LastRun; r != nil {
+			last = "last " + r.Start.Format(time.DateTime) + " ✓"
+			if !r.OK {
+				last = "last " + r.Start.Format(time.DateTime) + " ✗ " + r.Error
+			}
+		}
+		ui.Outputf("  %-20s %-14s next %s, %s\n", st.ID, st.Schedule, st.NextRun.Format(time.DateTime), last)
+	}
+	return exitOK
+}
+
+// runScheduleRun runs scheduled groups in the foreground until Ctrl+C.
+// With -watch it also copies new files as they appear in every enabled
+// group, which makes it the engine behind `copyimage service`.
+// Changes to the config file are picked up without a restart.
+func runScheduleRun(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("schedule run", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	watchGroups := fs.Bool("watch", false, "Also copy new files as they appear in the source of every enabled group")
+	lockWait := fs.Duration("lock-wait", time.Minute, "How long a scheduled run waits if another session is running its group or writing to a destination")
+	resultLogPath := fs.String("result-log", "", "Append one NDJSON record per file (path, status, bytes, duration, error, hash) to this file")
+	otlp := addTracingFlag(fs)
+	metricsAddr := addMetricsFlag(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	cfg, ok := common.load()
+	if !ok {
+		return exitConfig
+	}
+	if err := cfg.Validate(); err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+
+	results, err := openResultLog(*resultLogPath, cfg.DryRun)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	defer func() { _ = results.Close() }()
+
+	flush, err := startTracing(ctx, *otlp)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	defer flush()
+
+	runCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	m, err := startMetrics(runCtx, *metricsAddr)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+
+	// The config may be replaced by a reload while a group runs.
+	var mu sync.Mutex
+	current := func() *config.Config {
+		mu.Lock()
+		defer mu.Unlock()
+		return cfg
+	}
+
+	// Scheduled runs and watched batches take turns so their output does
+	// not interleave.
+	var busy sync.Mutex
+	runner := lockedRunner(*common.configFile, cfg.DryRun, *lockWait, results, m)
+	hist := openHistory(*common.configFile)
+
+	s := schedule.New(func(ctx context.Context, id string) error {
+		busy.Lock()
+		defer busy.Unlock()
+		cfg := current()
+		group := cfg.FindGroup(id)
+		if group == nil {
+			return fmt.Errorf("group %q not found", id)
+		}
+		ui.Println(tr.T("groups.running", group.ID, group.Name))
+		hooked := hooks.New(cfg).Wrap(history.KindSchedule, &group.ID, runner, warnHook)
+		notified := notify.New(cfg).Wrap(history.KindSchedule, &group.ID, hooked, warnNotify)
+		summary, err := copier.RunGroup(lockGroups(ctx, *common.configFile, *lockWait), cfg, *group, hist.wrap(history.KindSchedule, &group.ID, notified))
+		if err != nil {
+			ui.Error(tr.T("cli.error"), err)
+			return err
+		}
+		total := summary.Total()
+		ui.Summary(total, cfg.DryRun)
+		if total.Failed > 0 {
+			return fmt.Errorf("%d file(s) failed", total.Failed)
+		}
+		return nil
+	}, dataPath(*common.configFile, schedule.StateFile))
+	if err := s.SetJobs(schedule.Jobs(cfg)); err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+
+	if path := resolveConfigPath(*common.configFile); path != "" {
+		err := config.Watch(runCtx, path, func(next *config.Config, err error) {
+			if err == nil {
+				err = next.ApplyEnv()
+			}
+			if err == nil {
+				next.ExpandEnv()
+				err = next.Validate()
+			}
+			if err == nil {
+				err = s.SetJobs(schedule.Jobs(next))
+			}
+			if err != nil {
+				ui.log.Warn("ignoring config change", "path", path, "error", err)
+				return
+			}
+			mu.Lock()
+			cfg = next
+			mu.Unlock()
+			ui.Println(tr.T("schedule.reloaded"))
+		})
+		if err != nil {
+			ui.log.Warn("config changes will not be picked up", "error", err)
+		}
+	}
+
+	if *watchGroups {
+		notes, hk := notify.New(cfg), hooks.New(cfg)
+		// Watching uses the groups as loaded; restart to watch new ones.
+		for _, group := range cfg.GetEnabledGroups() {
+			ui.Println(tr.T("watch.watching", group.Source))
+			hooked := hk.Wrap(history.KindWatch, &group.ID, runner, warnHook)
+			groupRunner := hist.wrap(history.KindWatch, &group.ID, notes.Wrap(history.KindWatch, &group.ID, hooked, warnNotify))
+			go func() {
+				err := copier.WatchGroup(runCtx, cfg, group, copier.DefaultWatchOptions(), func(ctx context.Context, c *copier.Copier, files []string) copier.CopySummary {
+					busy.Lock()
+					defer busy.Unlock()
+					ui.Println(tr.T("watch.new_files", len(files)))
+					summary := groupRunner(ctx, c, files)
+					ui.Summary(summary, cfg.DryRun)
+					return summary
+				}, nil)
+				if err != nil {
+					// The scheduler keeps running for the other groups.
+					ui.Error(tr.T("cli.error"), err)
+				}
+			}()
+		}
+	}
+
+	for _, st := range s.Status() {
+		ui.Println(tr.T("schedule.next", st.ID, st.NextRun.Format(time.DateTime)))
+	}
+	ui.Println(tr.T("schedule.waiting"))
+	s.Start(runCtx)
+	return exitOK
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+
+	"copy-image/internal/config"
+	"copy-image/internal/updater"
+	"copy-image/internal/version"
+)
+
+// newUpdater returns the updater of the running CLI; tests replace it so
+// as not to overwrite the test binary.
+var newUpdater = updater.New
+
+// selfUpdateResult is the result document of `copyimage self-update`.
+type selfUpdateResult struct {
+	Current   string `json:"current"`
+	Latest    string `json:"latest"`
+	Available bool   `json:"available"`
+	Updated   bool   `json:"updated"`
+}
+
+// runSelfUpdateCommand implements `copyimage self-update`: it replaces the
+// CLI with the newest release for this OS and architecture, checked
+// against the release's checksums. The desktop app updates itself.
+func runSelfUpdateCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("self-update", flag.ContinueOnError)
+	configFile := fs.String("config", config.FileName, "Path to config file, for the update feed, channel and proxy (default: search the current, user config and program folders)")
+	channel := fs.String("channel", "", "Update channel: stable or beta (default: the config's app.update_channel)")
+	checkOnly := fs.Bool("check", false, "Only report whether an update is available")
+	output := fs.String("output", "plain", "Output format: plain, json or ndjson")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 0 {
+		fmt.Println("Usage: copyimage self-update [-check] [-channel stable|beta]")
+		return exitConfig
+	}
+	mode, err := parseOutputMode(*output)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitConfig
+	}
+	ui = newReporter(mode, os.Stdout)
+
+	cfg, _, err := readConfig(*configFile)
+	if err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+	if *channel == "" {
+		*channel = cfg.App.Channel()
+	}
+	if *channel != updater.ChannelStable && *channel != updater.ChannelBeta {
+		ui.Error(tr.T("cli.error"), fmt.Errorf("unknown update channel %q (expected stable or beta)", *channel))
+		return exitConfig
+	}
+	client, err := updater.NewClient(cfg.HTTP.ProxyURL(), cfg.HTTP.CAPath())
+	if err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+
+	release, err := updater.Latest(ctx, client, updater.NewFeed(cfg.UpdateFeed), *channel)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), fmt.Errorf("check for updates: %w", err))
+		return exitPartial
+	}
+	result := selfUpdateResult{Current: version.Version, Latest: release.TagName, Available: updater.Newer(release.TagName, version.Version)}
+	if !result.Available || *checkOnly {
+		if result.Available {
+			ui.Outputf("%s\n", tr.T("selfupdate.available", release.TagName, version.Version))
+		} else {
+			ui.Outputf("%s\n", tr.T("selfupdate.current", version.Version))
+		}
+		ui.Result(result)
+		return exitOK
+	}
+
+	if err := installRelease(ctx, client, release); err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitPartial
+	}
+	result.Updated = true
+	ui.Outputf("%s\n", tr.T("selfupdate.done", release.TagName, version.Version))
+	ui.Result(result)
+	return exitOK
+}
+
+// installRelease downloads the CLI of release for this platform, verifies
+// it and puts it in place of the running one, which keeps working until
+// it exits.
+func installRelease(ctx context.Context, client *http.Client, release *updater.Release) error {
+	name := updater.CLIAssetName(runtime.GOOS, runtime.GOARCH)
+	asset, ok := release.Asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no %s", release.TagName, name)
+	}
+	u, err := newUpdater()
+	if err != nil {
+		return err
+	}
+	// Kept under a name of its own in the cache folder, so that a broken
+	// download resumes at the next attempt.
+	partPath, err := updater.PartialPath(asset.BrowserDownloadURL)
+	if err != nil {
+		return err
+	}
+
+	ui.Printf("%s\n", tr.T("selfupdate.downloading", name))
+	if err := updater.Download(ctx, client, asset.BrowserDownloadURL, partPath, nil); err != nil {
+		return err
+	}
+	if err := updater.Verify(ctx, client, asset.BrowserDownloadURL, partPath); err != nil {
+		// Not to be resumed: it is the wrong file.
+		_ = os.Remove(partPath)
+		return fmt.Errorf("update refused: %w", err)
+	}
+	err = u.Install(partPath)
+	_ = os.Remove(partPath)
+	return err
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"copy-image/internal/updater"
+)
+
+// fakeUpdater installs into a file of the test instead of the test binary.
+type fakeUpdater struct {
+	path string
+}
+
+func (f *fakeUpdater) Install(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, data, 0755)
+}
+
+func (f *fakeUpdater) Restart() error  { return nil }
+func (f *fakeUpdater) Rollback() error { return nil }
+func (f *fakeUpdater) Path() string    { return f.path }
+
+// serveFeed serves a JSON update feed with one release, v9.0.0, holding
+// the CLI for this platform with content, and checksums listing sum.
+func serveFeed(t *testing.T, content, sum string) string {
+	name := updater.CLIAssetName(runtime.GOOS, runtime.GOARCH)
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("/feed.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"tag_name": "v9.0.0", "assets": [{"name": "` + name + `", "browser_download_url": "` + srv.URL + `/v9.0.0/` + name + `"}]}]`))
+	})
+	mux.HandleFunc("/v9.0.0/"+name, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(content))
+	})
+	mux.HandleFunc("/v9.0.0/"+updater.ChecksumsFile, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(sum + "  " + name + "\n"))
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(cfgPath, []byte("update_feed:\n  url: "+srv.URL+"/feed.json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return cfgPath
+}
+
+func TestSelfUpdateCommand(t *testing.T) {
+	// Partial downloads go to the user's cache folder.
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("LocalAppData", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	exe := filepath.Join(t.TempDir(), "copyimage")
+	if err := os.WriteFile(exe, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	newUpdater = func() (updater.Updater, error) { return &fakeUpdater{path: exe}, nil }
+	defer func() { newUpdater = updater.New }()
+
+	sum := sha256.Sum256([]byte("new"))
+	cfgPath := serveFeed(t, "new", hex.EncodeToString(sum[:]))
+
+	if code := run(context.Background(), []string{"self-update", "-check", "-config", cfgPath}); code != exitOK {
+		t.Fatalf("self-update -check: expected exit code %d, got %d", exitOK, code)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Fatalf("Expected -check to leave the CLI alone, got %q", data)
+	}
+	if code := run(context.Background(), []string{"self-update", "-config", cfgPath}); code != exitOK {
+		t.Fatalf("self-update: expected exit code %d, got %d", exitOK, code)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new" {
+		t.Errorf("Expected the new release installed, got %q", data)
+	}
+
+	// A download that does not match its checksum is refused.
+	if err := os.WriteFile(exe, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath = serveFeed(t, "tampered", hex.EncodeToString(sum[:]))
+	if code := run(context.Background(), []string{"self-update", "-config", cfgPath}); code != exitPartial {
+		t.Errorf("self-update of a tampered release: expected exit code %d, got %d", exitPartial, code)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Errorf("Expected the tampered release refused, got %q", data)
+	}
+
+	if code := run(context.Background(), []string{"self-update", "-channel", "nightly", "-config", cfgPath}); code != exitConfig {
+		t.Errorf("self-update -channel nightly: expected exit code %d, got %d", exitConfig, code)
+	}
+}
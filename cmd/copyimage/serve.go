@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"sync"
+
+	"copy-image/internal/api"
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	"copy-image/internal/hooks"
+	"copy-image/internal/notify"
+)
+
+// tokenEnv holds the API token when -token is not given, which keeps it
+// out of the process list and the service definition.
+const tokenEnv = "COPYIMAGE_API_TOKEN"
+
+// runServeCommand implements `copyimage serve`: a REST API, and with
+// -grpc-listen a gRPC one, to list groups, start and cancel group runs,
+// stream their progress and read the history, so imports can be started
+// from another system.
+func runServeCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	listen := fs.String("listen", ":8080", "Address to serve the REST API on")
+	grpcListen := fs.String("grpc-listen", "", "Address to also serve the gRPC API on, e.g. :9090")
+	token := fs.String("token", "", "Token clients must send as \"Authorization: Bearer <token>\" (default $"+tokenEnv+")")
+	lockWait := fs.Duration("lock-wait", 0, "How long to wait if another session is running a group or writing to a destination (0 = refuse)")
+	resultLogPath := fs.String("result-log", "", "Append one NDJSON record per file (path, status, bytes, duration, error, hash) to this file")
+	otlp := addTracingFlag(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	if *token == "" {
+		*token = os.Getenv(tokenEnv)
+	}
+	if *token == "" {
+		ui.Error(tr.T("cli.config_error"), errors.New("an API token is required: set -token or "+tokenEnv))
+		return exitConfig
+	}
+	cfg, ok := common.load()
+	if !ok {
+		return exitConfig
+	}
+	if err := cfg.Validate(); err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+
+	results, err := openResultLog(*resultLogPath, cfg.DryRun)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	defer func() { _ = results.Close() }()
+
+	flush, err := startTracing(ctx, *otlp)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	defer flush()
+
+	serveCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	// Each run picks up the config as it is when the run starts.
+	reload := watchConfig(serveCtx, *common.configFile)
+	var mu sync.Mutex
+	current := func() *config.Config {
+		mu.Lock()
+		defer mu.Unlock()
+		if next := reload.take(); next != nil {
+			cfg = next
+		}
+		return cfg
+	}
+
+	hist := openHistory(*common.configFile)
+	server := api.New(api.Options{
+		Token:   *token,
+		Config:  current,
+		History: hist.db,
+		Run: func(ctx context.Context, cfg *config.Config, group config.CopyGroup, progress func(api.Progress)) (copier.GroupSummary, error) {
+			ui.Println(tr.T("groups.running", group.ID, group.Name))
+			runner := lockedCopy(*common.configFile, cfg.DryRun, *lockWait, results, nil, func(ctx context.Context, c *copier.Copier, files []string) copier.CopySummary {
+				c.SetLogger(ui.log)
+				events, wait := copier.Dispatch(func(ev copier.Event) {
+					if f, ok := ev.(copier.FileFinished); ok {
+						progress(api.Progress{Destination: c.Destination(), Current: f.Current, Total: f.Total, File: f.Result.FileName, Status: f.Status})
+					}
+				})
+				defer wait()
+				return c.CopyFilesParallelWithEvents(ctx, files, events)
+			})
+			runner = hooks.New(cfg).Wrap(history.KindAPI, &group.ID, runner, warnHook)
+			runner = notify.New(cfg).Wrap(history.KindAPI, &group.ID, runner, warnNotify)
+			summary, err := copier.RunGroup(lockGroups(ctx, *common.configFile, *lockWait), cfg, group, hist.wrap(history.KindAPI, &group.ID, runner))
+			if err == nil {
+				ui.Summary(summary.Total(), cfg.DryRun)
+			}
+			return summary, err
+		},
+	})
+
+	if *grpcListen != "" {
+		if err := server.StartGRPC(serveCtx, *grpcListen); err != nil {
+			ui.Error(tr.T("cli.error"), err)
+			return exitConfig
+		}
+		ui.Println(tr.T("serve.listening_grpc", *grpcListen))
+	}
+	ui.Println(tr.T("serve.listening", *listen))
+	if err := server.ListenAndServe(serveCtx, *listen); err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	return exitOK
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// serviceName identifies the installed service (Windows service name and
+// systemd unit name).
+const serviceName = "copyimage"
+
+// runServiceCommand implements `copyimage service`, which runs the
+// scheduler (and, with -watch, watch mode) in the background without a
+// logged-in user: a Windows service, or a systemd unit on Linux.
+//
+// `service install [flags]` stores the flags of `schedule run` in the
+// service definition; `service run` is what the service manager executes.
+func runServiceCommand(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		fmt.Println("Usage: copyimage service install [schedule run flags] | uninstall | start | stop | status")
+		return exitConfig
+	}
+
+	verb := args[0]
+	var err error
+	switch verb {
+	case "install":
+		runArgs, code, ok := serviceRunArgs(args[1:])
+		if !ok {
+			return code
+		}
+		err = installService(runArgs)
+	case "uninstall", "start", "stop", "status":
+		fs := flag.NewFlagSet("service "+verb, flag.ContinueOnError)
+		if code, ok := parseFlags(fs, args[1:]); !ok {
+			return code
+		}
+		switch verb {
+		case "uninstall":
+			err = uninstallService()
+		case "start":
+			err = startService()
+		case "stop":
+			err = stopService()
+		case "status":
+			var state string
+			if state, err = serviceStatus(); err == nil {
+				fmt.Printf("%s: %s\n", serviceName, state)
+				return exitOK
+			}
+		}
+	case "run":
+		return runService(ctx, args[1:])
+	default:
+		fmt.Printf("❌ Unknown service command %q (expected install, uninstall, start, stop or status)\n", verb)
+		return exitConfig
+	}
+	if err != nil {
+		fmt.Printf("❌ service %s: %v\n", verb, err)
+		return exitPartial
+	}
+	fmt.Printf("✅ service %s: done\n", verb)
+	return exitOK
+}
+
+// serviceRunArgs parses the flags given to `service install`, which are
+// those of `schedule run`, and returns them with the config file made
+// absolute: services start in a different folder (and, on Windows, as a
+// different user) so discovery would not find the user's config.
+func serviceRunArgs(args []string) ([]string, int, bool) {
+	// Borrow schedule run's flag set without running it.
+	probe := flagSetProbe
+	var fs *flag.FlagSet
+	flagSetProbe = func(f *flag.FlagSet) { fs = f }
+	_ = runScheduleRun(context.Background(), nil)
+	flagSetProbe = probe
+
+	positional, code, ok := parseInterspersed(fs, args)
+	if !ok {
+		return nil, code, false
+	}
+	if len(positional) > 0 {
+		fmt.Printf("❌ Unexpected arguments: %s\n", strings.Join(positional, " "))
+		return nil, exitConfig, false
+	}
+
+	path := resolveConfigPath(fs.Lookup("config").Value.String())
+	if path == "" {
+		fmt.Println("❌ No config file found; create one first or pass -config")
+		return nil, exitConfig, false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, exitConfig, false
+	}
+
+	runArgs := []string{"-config", abs}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			runArgs = append(runArgs, "-"+f.Name+"="+f.Value.String())
+		}
+	})
+	return runArgs, exitOK, true
+}
+
+// runServiceEngine runs the background engine until ctx is cancelled.
+func runServiceEngine(ctx context.Context, args []string) int {
+	// Services have no console to prompt on or draw a progress bar in.
+	if !hasFlag(args, "output") {
+		args = append([]string{"-output", "ndjson"}, args...)
+	}
+	return runScheduleRun(ctx, args)
+}
+
+// hasFlag reports whether args set the named flag.
+func hasFlag(args []string, name string) bool {
+	for _, a := range args {
+		if n, ok := flagName(a); ok && (n == name || strings.HasPrefix(n, name+"=")) {
+			return true
+		}
+	}
+	return false
+}
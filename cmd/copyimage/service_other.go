@@ -0,0 +1,178 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// installService writes a systemd unit that runs `copyimage service run`
+// with args and enables it. As root it is a system unit; otherwise a user
+// unit, which runs without a login once lingering is enabled
+// (loginctl enable-linger).
+func installService(args []string) error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("not supported on %s; run `copyimage service run` from your init system", runtime.GOOS)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	path, user, err := unitPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists; uninstall it first", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(systemdUnit(exe, args, user)), 0644); err != nil {
+		return err
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", serviceName)
+}
+
+// uninstallService stops, disables and removes the unit.
+func uninstallService() error {
+	path, _, err := unitPath()
+	if err != nil {
+		return err
+	}
+	_ = systemctl("disable", "--now", serviceName)
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return systemctl("daemon-reload")
+}
+
+// startService starts the unit.
+func startService() error { return systemctl("start", serviceName) }
+
+// stopService stops the unit; systemd waits for runService to return.
+func stopService() error { return systemctl("stop", serviceName) }
+
+// serviceStatus returns the state reported by systemd, e.g. "active".
+func serviceStatus() (string, error) {
+	out, err := exec.Command("systemctl", append(systemctlScope(), "is-active", serviceName)...).Output()
+	state := strings.TrimSpace(string(out))
+	if state != "" {
+		// is-active exits non-zero for every state but "active".
+		return state, nil
+	}
+	return "", err
+}
+
+// unitPath returns where the unit file goes and whether it is a user unit.
+func unitPath() (string, bool, error) {
+	if os.Geteuid() == 0 {
+		return filepath.Join("/etc/systemd/system", serviceName+".service"), false, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false, err
+	}
+	return filepath.Join(dir, "systemd", "user", serviceName+".service"), true, nil
+}
+
+// systemdUnit returns the unit file for running exe as a notify service.
+func systemdUnit(exe string, args []string, user bool) string {
+	cmd := []string{systemdQuote(exe), "service", "run"}
+	for _, a := range args {
+		cmd = append(cmd, systemdQuote(a))
+	}
+	target := "multi-user.target"
+	if user {
+		target = "default.target"
+	}
+	return fmt.Sprintf(`[Unit]
+Description=Copy Image scheduled and watched groups
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=notify
+ExecStart=%s
+Restart=on-failure
+RestartSec=30
+
+[Install]
+WantedBy=%s
+`, strings.Join(cmd, " "), target)
+}
+
+// systemdQuote quotes s for an ExecStart line.
+func systemdQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$%;") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `$$`, `%`, `%%`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// systemctl runs systemctl for the unit's scope (system or user).
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", append(systemctlScope(), args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// systemctlScope selects the user manager unless running as root.
+func systemctlScope() []string {
+	if os.Geteuid() == 0 {
+		return nil
+	}
+	return []string{"--user"}
+}
+
+// runService runs the engine until SIGTERM (systemd stop) or Ctrl+C,
+// telling systemd when it is ready and when it is stopping.
+func runService(ctx context.Context, args []string) int {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	_ = sdNotify("READY=1")
+	go func() {
+		<-ctx.Done()
+		_ = sdNotify("STOPPING=1")
+	}()
+	return runServiceEngine(ctx, args)
+}
+
+// sdNotify sends state to systemd's notification socket. Outside systemd
+// (no NOTIFY_SOCKET) it does nothing.
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	if strings.HasPrefix(socket, "@") {
+		// Abstract namespace socket.
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("sd_notify: %w", err)
+	}
+	return nil
+}
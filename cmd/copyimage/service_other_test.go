@@ -0,0 +1,69 @@
+//go:build !windows
+
+package main
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSystemdUnit(t *testing.T) {
+	unit := systemdUnit("/opt/copy image/copyimage", []string{"-config", "/srv/config.yaml", "-watch=true"}, true)
+
+	for _, want := range []string{
+		`ExecStart="/opt/copy image/copyimage" service run -config /srv/config.yaml -watch=true`,
+		"Type=notify",
+		"WantedBy=default.target",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("Expected unit to contain %q:\n%s", want, unit)
+		}
+	}
+	if unit := systemdUnit("/usr/bin/copyimage", nil, false); !strings.Contains(unit, "WantedBy=multi-user.target") {
+		t.Errorf("Expected a system unit to be wanted by multi-user.target:\n%s", unit)
+	}
+}
+
+func TestSystemdQuote(t *testing.T) {
+	tests := map[string]string{
+		"/usr/bin/copyimage": "/usr/bin/copyimage",
+		"with space":         `"with space"`,
+		`$HOME\x`:            `"$$HOME\\x"`,
+		"100%":               `"100%%"`,
+		"":                   `""`,
+	}
+	for in, want := range tests {
+		if got := systemdQuote(in); got != want {
+			t.Errorf("systemdQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSdNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if err := sdNotify("READY=1"); err != nil {
+		t.Errorf("Expected no error outside systemd, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets unavailable: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	if err := sdNotify("READY=1"); err != nil {
+		t.Fatalf("sdNotify failed: %v", err)
+	}
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("Failed to read notification: %v", err)
+	}
+	if got := string(buf[:n]); got != "READY=1" {
+		t.Errorf("Expected READY=1, got %q", got)
+	}
+}
@@ -0,0 +1,192 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// installService registers the service to start automatically at boot and
+// run `copyimage service run` with args. It needs an elevated prompt.
+func installService(args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("cannot connect to the service manager (run as administrator): %w", err)
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		_ = s.Close()
+		return fmt.Errorf("service %s is already installed; uninstall it first", serviceName)
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: "Copy Image",
+		Description: "Runs scheduled and watched Copy Image groups in the background.",
+		StartType:   mgr.StartAutomatic,
+	}, append([]string{"service", "run"}, args...)...)
+	if err != nil {
+		return err
+	}
+	return s.Close()
+}
+
+// uninstallService stops and removes the service.
+func uninstallService() error {
+	m, s, err := openService()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+	defer func() { _ = s.Close() }()
+
+	if status, err := s.Query(); err == nil && status.State != svc.Stopped {
+		_ = stopAndWait(s)
+	}
+	return s.Delete()
+}
+
+// startService asks the service manager to start the service.
+func startService() error {
+	m, s, err := openService()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+	defer func() { _ = s.Close() }()
+	return s.Start()
+}
+
+// stopService stops the service and waits until it has stopped, so a copy
+// in progress can finish its current file.
+func stopService() error {
+	m, s, err := openService()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Disconnect() }()
+	defer func() { _ = s.Close() }()
+	return stopAndWait(s)
+}
+
+// serviceStatus returns the state reported by the service manager.
+func serviceStatus() (string, error) {
+	m, s, err := openService()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = m.Disconnect() }()
+	defer func() { _ = s.Close() }()
+
+	status, err := s.Query()
+	if err != nil {
+		return "", err
+	}
+	switch status.State {
+	case svc.Stopped:
+		return "stopped", nil
+	case svc.StartPending:
+		return "starting", nil
+	case svc.StopPending:
+		return "stopping", nil
+	case svc.Running:
+		return "running", nil
+	case svc.PausePending, svc.Paused, svc.ContinuePending:
+		return "paused", nil
+	}
+	return fmt.Sprintf("state %d", status.State), nil
+}
+
+// openService connects to the service manager and opens the service.
+func openService() (*mgr.Mgr, *mgr.Service, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot connect to the service manager (run as administrator): %w", err)
+	}
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		_ = m.Disconnect()
+		return nil, nil, fmt.Errorf("service %s is not installed: %w", serviceName, err)
+	}
+	return m, s, nil
+}
+
+// stopAndWait sends Stop and waits up to a minute for the service to stop.
+func stopAndWait(s *mgr.Service) error {
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(time.Minute)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return errors.New("timed out waiting for the service to stop")
+		}
+		time.Sleep(300 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runService runs the engine. Started by the service manager it reports
+// its state there and stops on Stop or Shutdown; started from a console it
+// runs in the foreground until Ctrl+C.
+func runService(ctx context.Context, args []string) int {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return runServiceEngine(ctx, args)
+	}
+
+	h := &serviceHandler{run: func(ctx context.Context) int { return runServiceEngine(ctx, args) }}
+	if err := svc.Run(serviceName, h); err != nil {
+		return exitPartial
+	}
+	return h.code
+}
+
+// serviceHandler adapts the engine to the service control manager.
+type serviceHandler struct {
+	run  func(ctx context.Context) int
+	code int
+}
+
+// Execute implements svc.Handler.
+func (h *serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan int, 1)
+	go func() { done <- h.run(ctx) }()
+
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case h.code = <-done:
+			changes <- svc.Status{State: svc.StopPending}
+			// A non-zero code (e.g. an invalid config) shows up as the
+			// service-specific exit code in the service manager.
+			return h.code != exitOK, uint32(h.code)
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+			}
+		}
+	}
+}
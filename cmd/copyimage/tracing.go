@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"copy-image/internal/tracing"
+	"copy-image/internal/version"
+)
+
+// addTracingFlag registers -otlp on the flag sets of commands that copy.
+func addTracingFlag(fs *flag.FlagSet) *string {
+	return fs.String("otlp", "", "Send traces of each batch and file to this OpenTelemetry collector (e.g. http://localhost:4318; OTEL_EXPORTER_OTLP_ENDPOINT also works)")
+}
+
+// startTracing sets up tracing to endpoint and returns the function that
+// sends the remaining spans, to be deferred. Nothing is set up when no
+// endpoint is given or configured through the environment.
+func startTracing(ctx context.Context, endpoint string) (func(), error) {
+	shutdown, err := tracing.Setup(ctx, endpoint, version.Version)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		// Do not hang on exit when the collector is unreachable.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			ui.log.Warn("traces not sent", "error", err)
+		}
+	}, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"copy-image/internal/lock"
+	"copy-image/internal/storage"
+)
+
+// runUndoCommand implements `copyimage undo <run-id>`: it removes the files
+// a recorded run created, e.g. after copying a card to the wrong folder.
+func runUndoCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("undo", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	lockWait := fs.Duration("lock-wait", 0, "How long to wait if another session is writing to the destination (0 = refuse)")
+	positional, code, ok := parseInterspersed(fs, args)
+	if !ok {
+		return code
+	}
+	if len(positional) != 1 {
+		fmt.Println("Usage: copyimage undo <run-id> (see copyimage history)")
+		return exitConfig
+	}
+
+	cfg, ok := common.load()
+	if !ok {
+		return exitConfig
+	}
+	id, err := strconv.ParseUint(positional[0], 10, 64)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), errors.New("run ID must be a number"))
+		return exitConfig
+	}
+
+	h := openHistory(*common.configFile)
+	run, err := h.db.Get(id)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	target := run.Target(cfg)
+	release := func() {}
+	// A destination deleted since holds nothing left to undo, and locking
+	// it would create it again.
+	if _, err := os.Stat(target.LockDir()); err == nil {
+		release, err = lockDestination(ctx, target.LockDir(), false, *lockWait)
+		if err != nil {
+			ui.Error(tr.T("cli.error"), err)
+			if errors.Is(err, lock.ErrLocked) {
+				return exitLocked
+			}
+			return exitPartial
+		}
+	}
+	defer release()
+
+	dst, err := storage.ForDestination(target)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitPartial
+	}
+	result, err := h.db.Undo(id, dst, cfg.UseTrash)
+	if result == nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	ui.Result(result)
+	ui.Outputf("%s\n", tr.T("undo.done", len(result.Removed), run.Destination))
+	for _, path := range result.Kept {
+		ui.Outputf("%s\n", tr.T("undo.kept", path))
+	}
+	for _, f := range result.Failed {
+		ui.Outputf("  ✗ %s\n", f)
+	}
+	if err != nil {
+		// The files are gone but the run could not be marked undone.
+		ui.Error(tr.T("cli.error"), err)
+		return exitPartial
+	}
+	if len(result.Failed) > 0 {
+		return exitPartial
+	}
+	return exitOK
+}
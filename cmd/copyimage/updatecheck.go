@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/updater"
+	"copy-image/internal/version"
+)
+
+// Update notice: the CLI looks for a newer release while its command runs
+// and mentions it in one line at the end. The feed is asked at most once a
+// day, in the background, and the command never waits on it for longer
+// than updateCheckTimeout all told, so an offline or blocked network costs
+// nothing noticeable.
+const (
+	updateCheckTimeout = 2 * time.Second
+	updateCheckEvery   = 24 * time.Hour
+)
+
+// noUpdateCheckFlag turns the notice off for one run. It applies to every
+// command, so it is taken out of the arguments before they are parsed.
+const noUpdateCheckFlag = "no-update-check"
+
+// quietCommands never print the notice: self-update reports updates
+// itself, and completion output is read by the shell.
+var quietCommands = []string{"self-update", "completion", "__complete"}
+
+// startUpdateCheck starts looking for a newer release unless args, the
+// config or the command rule it out. It returns args without
+// -no-update-check, and a function to call once the command is done,
+// which prints the notice to w if there is one.
+func startUpdateCheck(args []string, w io.Writer) ([]string, func()) {
+	args, disabled := stripFlag(args, noUpdateCheckFlag)
+	if disabled || len(args) > 0 && slices.Contains(quietCommands, args[0]) {
+		return args, func() {}
+	}
+	configFile := flagValue(args, "config", config.FileName)
+	cfg, _, err := readConfig(configFile)
+	if err != nil {
+		return args, func() {}
+	}
+	// COPYIMAGE_NO_UPDATE_CHECK=true turns it off for CI jobs.
+	_ = cfg.ApplyEnv()
+	if cfg.NoUpdateCheck {
+		return args, func() {}
+	}
+	client, err := updater.NewClient(cfg.HTTP.ProxyURL(), cfg.HTTP.CAPath())
+	if err != nil {
+		return args, func() {}
+	}
+	checker := &updater.Checker{
+		Client:    client,
+		Feed:      updater.NewFeed(cfg.UpdateFeed),
+		Channel:   cfg.App.Channel(),
+		CachePath: dataPath(configFile, updater.CacheFile),
+	}
+
+	found := make(chan *updater.Release, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	if release, at := checker.Cached(); release != nil && time.Since(at) < updateCheckEvery {
+		found <- release
+	} else {
+		go func() {
+			release, _ := checker.Check(ctx)
+			found <- release
+		}()
+	}
+	return args, func() {
+		defer cancel()
+		var release *updater.Release
+		select {
+		case release = <-found:
+		case <-ctx.Done():
+		}
+		if release != nil && updater.Newer(release.TagName, version.Version) {
+			_, _ = fmt.Fprintln(w, tr.T("selfupdate.notice", release.TagName, version.Version))
+		}
+	}
+}
+
+// stripFlag returns args without the boolean flag name, given as -name or
+// --name, and whether it was there.
+func stripFlag(args []string, name string) ([]string, bool) {
+	var rest []string
+	found := false
+	for _, arg := range args {
+		if arg == "-"+name || arg == "--"+name {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
+
+// flagValue returns the value of the flag name in args, given as -name
+// value, -name=value or with two dashes, or def if it is not there.
+func flagValue(args []string, name, def string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		key, value, hasValue := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-"), "=")
+		if !strings.HasPrefix(arg, "-") || key != name {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return def
+}
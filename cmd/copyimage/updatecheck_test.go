@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStartUpdateCheck(t *testing.T) {
+	cfgPath := serveFeed(t, "new", "")
+	tests := []struct {
+		name   string
+		args   []string
+		notice bool
+	}{
+		{"notice", []string{"scan", "-config", cfgPath}, true},
+		{"cached", []string{"scan", "--config=" + cfgPath}, true},
+		{"flag", []string{"scan", "-config", cfgPath, "--no-update-check"}, false},
+		{"quiet command", []string{"self-update", "-config", cfgPath}, false},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		args, notice := startUpdateCheck(tt.args, &out)
+		notice()
+		if slices.Contains(args, "--no-update-check") {
+			t.Errorf("%s: expected the flag taken out, got %v", tt.name, args)
+		}
+		if got := strings.Contains(out.String(), "v9.0.0"); got != tt.notice {
+			t.Errorf("%s: notice %q, want one: %v", tt.name, out.String(), tt.notice)
+		}
+	}
+
+	if err := os.WriteFile(cfgPath, []byte("no_update_check: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	_, notice := startUpdateCheck([]string{"scan", "-config", cfgPath}, &out)
+	notice()
+	if out.Len() != 0 {
+		t.Errorf("Expected no notice with no_update_check, got %q", out.String())
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"scan", "-config", "a.yaml"}, "a.yaml"},
+		{[]string{"scan", "--config=b.yaml", "-v"}, "b.yaml"},
+		{[]string{"scan", "-configs", "c.yaml"}, "default"},
+		{[]string{"scan", "--", "-config", "d.yaml"}, "default"},
+		{[]string{"scan", "-config"}, "default"},
+	}
+	for _, tt := range tests {
+		if got := flagValue(tt.args, "config", "default"); got != tt.want {
+			t.Errorf("flagValue(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
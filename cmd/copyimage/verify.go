@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"copy-image/internal/copier"
+)
+
+// verifyResult is the machine-readable output of `copyimage verify`.
+type verifyResult struct {
+	Type string `json:"type"`
+	*copier.VerifyReport
+}
+
+// runVerifyCommand implements `copyimage verify`, which checks that every
+// source file exists in the destination with the same size (and with -hash
+// the same content), and lists files found only in the destination.
+// Nothing is copied.
+func runVerifyCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	hash := fs.Bool("hash", false, "Also compare file contents (SHA-256), not just sizes")
+	reportPath := fs.String("report", "", "Write the differences to this file: CSV for .csv, JSON otherwise")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	cfg, ok := common.load()
+	if !ok {
+		return exitConfig
+	}
+	if err := cfg.Validate(); err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+
+	report, err := copier.New(cfg).Verify(ctx, *hash)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+
+	ui.Result(verifyResult{Type: "verify", VerifyReport: report})
+	for _, name := range report.Missing {
+		ui.Outputf("%s\n", tr.T("verify.missing", name))
+	}
+	for _, name := range report.Mismatched {
+		ui.Outputf("%s\n", tr.T("verify.mismatched", name))
+	}
+	for _, name := range report.Extra {
+		ui.Outputf("%s\n", tr.T("verify.extra", name))
+	}
+	ui.Outputf("%s\n", tr.T("verify.verified", report.OK, report.Checked))
+
+	if *reportPath != "" {
+		if err := report.Export(*reportPath); err != nil {
+			ui.Error(tr.T("cli.error"), err)
+			return exitConfig
+		}
+	}
+
+	if !report.Clean() {
+		return exitPartial
+	}
+	return exitOK
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"copy-image/internal/version"
+)
+
+// runVersion implements `copyimage version`: the version, commit and build
+// date, as text or with -json for scripts and bug reports.
+func runVersion(args []string, w io.Writer) int {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the build info as JSON")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	info := version.Get()
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(info)
+		return exitOK
+	}
+	_, _ = fmt.Fprintf(w, "copy-image version %s\n", info.Version)
+	if info.Commit != "" {
+		_, _ = fmt.Fprintf(w, "  commit: %s\n", info.Commit)
+	}
+	if info.Date != "" {
+		_, _ = fmt.Fprintf(w, "  built:  %s\n", info.Date)
+	}
+	_, _ = fmt.Fprintf(w, "  go:     %s (%s)\n", info.GoVersion, info.Platform)
+	return exitOK
+}
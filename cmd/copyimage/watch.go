@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	"copy-image/internal/hooks"
+	"copy-image/internal/notify"
+)
+
+// runWatchCommand implements `copyimage watch`, which keeps running and
+// copies new files as they appear in the source, e.g. a camera tethering
+// folder. Without group IDs it watches the configured source and
+// destination; with IDs (or -all) it watches each group's source and
+// copies to all of its enabled destinations. It stops on Ctrl+C.
+func runWatchCommand(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
+	common := addCommonFlags(fs)
+	all := fs.Bool("all", false, "Watch every enabled group")
+	dryRun := fs.Bool("dry-run", false, "Show what would be copied without copying")
+	lockWait := fs.Duration("lock-wait", 0, "How long to wait if another session is writing to a destination (0 = refuse)")
+	resultLogPath := fs.String("result-log", "", "Append one NDJSON record per file (path, status, bytes, duration, error, hash) to this file")
+	otlp := addTracingFlag(fs)
+	metricsAddr := addMetricsFlag(fs)
+	opts := copier.DefaultWatchOptions()
+	fs.DurationVar(&opts.Debounce, "debounce", opts.Debounce, "Wait this long after the last change to a file before checking it")
+	fs.DurationVar(&opts.Settle, "settle", opts.Settle, "Copy a file once its size has not changed for this long")
+
+	positional, code, ok := parseInterspersed(fs, args)
+	if !ok {
+		return code
+	}
+	if *all && len(positional) > 0 {
+		fmt.Println("Usage: copyimage watch [<group id>... | -all] [flags]")
+		return exitConfig
+	}
+
+	cfg, ok := common.load()
+	if !ok {
+		return exitConfig
+	}
+	if *dryRun {
+		cfg.DryRun = true
+	}
+
+	var groups []config.CopyGroup
+	switch {
+	case *all:
+		groups = cfg.GetEnabledGroups()
+		if len(groups) == 0 {
+			ui.Println(tr.T("groups.none"))
+			return exitConfig
+		}
+	default:
+		for _, id := range positional {
+			group := cfg.FindGroup(id)
+			if group == nil {
+				ui.Error(tr.T("cli.error"), fmt.Errorf("group %q not found", id))
+				return exitConfig
+			}
+			groups = append(groups, *group)
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		ui.Error(tr.T("cli.config_error"), err)
+		return exitConfig
+	}
+
+	results, err := openResultLog(*resultLogPath, cfg.DryRun)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	defer func() { _ = results.Close() }()
+
+	flush, err := startTracing(ctx, *otlp)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+	defer flush()
+
+	watchCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	m, err := startMetrics(watchCtx, *metricsAddr)
+	if err != nil {
+		ui.Error(tr.T("cli.error"), err)
+		return exitConfig
+	}
+
+	// Batches from different groups are copied one at a time so their
+	// progress output does not interleave.
+	var mu sync.Mutex
+	failed := false
+	runner := lockedRunner(*common.configFile, cfg.DryRun, *lockWait, results, m)
+	hist := openHistory(*common.configFile)
+	notes, hk := notify.New(cfg), hooks.New(cfg)
+	report := func(summary copier.CopySummary) {
+		failed = failed || summary.Failed > 0
+		ui.Summary(summary, cfg.DryRun)
+	}
+
+	if len(groups) == 0 {
+		c := copier.New(cfg)
+		ui.Println(tr.T("watch.watching", cfg.Source))
+		err = c.Watch(watchCtx, opts, func(files []string) {
+			ui.Println(tr.T("watch.new_files", len(files)))
+			hooked := hk.Wrap(history.KindWatch, nil, runner, warnHook)
+			report(hist.wrap(history.KindWatch, nil, notes.Wrap(history.KindWatch, nil, hooked, warnNotify))(watchCtx, c, files))
+		})
+		if err != nil {
+			ui.Error(tr.T("cli.error"), err)
+			return exitConfig
+		}
+		return watchExitCode(failed)
+	}
+
+	errs := make(chan error, len(groups))
+	for _, group := range groups {
+		ui.Println(tr.T("watch.watching", group.Source))
+		hooked := hk.Wrap(history.KindWatch, &group.ID, runner, warnHook)
+		groupRunner := hist.wrap(history.KindWatch, &group.ID, notes.Wrap(history.KindWatch, &group.ID, hooked, warnNotify))
+		go func() {
+			errs <- copier.WatchGroup(watchCtx, cfg, group, opts, func(ctx context.Context, c *copier.Copier, files []string) copier.CopySummary {
+				mu.Lock()
+				defer mu.Unlock()
+				ui.Println(tr.T("watch.new_files", len(files)))
+				summary := groupRunner(ctx, c, files)
+				report(summary)
+				return summary
+			}, nil)
+		}()
+	}
+
+	code = exitOK
+	for range groups {
+		if err := <-errs; err != nil {
+			// One group's source is missing; stop watching the others too
+			// so the problem is not missed.
+			ui.Error(tr.T("cli.error"), err)
+			stop()
+			code = exitConfig
+		}
+	}
+	if code != exitOK {
+		return code
+	}
+	return watchExitCode(failed)
+}
+
+// watchExitCode is the exit code once watching was stopped: Ctrl+C is the
+// normal way to end it, so only failed copies make it non-zero.
+func watchExitCode(failed bool) int {
+	if failed {
+		return exitPartial
+	}
+	return exitOK
+}
@@ -0,0 +1,45 @@
+//go:build windows
+
+package main
+
+import (
+	"reflect"
+
+	"copy-image/internal/config"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// watchConfig reloads the config when its file is edited outside the app
+// (by hand, with `copyimage config set` or by another instance) and emits
+// config:changed so the frontend can refresh the settings screen. The
+// app's own saves produce the same settings and emit nothing.
+func (a *App) watchConfig() {
+	err := config.Watch(a.ctx, a.configPath, func(cfg *config.Config, err error) {
+		a.mu.Lock()
+		if cfg == nil {
+			// The file cannot be parsed: keep the current settings but
+			// let the frontend show why.
+			a.loadErr = err
+			current := a.config
+			a.mu.Unlock()
+			runtime.EventsEmit(a.ctx, "config:changed", current)
+			return
+		}
+		if err == nil && a.loadErr == nil && reflect.DeepEqual(cfg, a.config) {
+			a.mu.Unlock()
+			return
+		}
+
+		// Unknown keys still return the recognized settings.
+		a.replaceConfig(cfg)
+		a.loadErr = err
+		a.mu.Unlock()
+		a.refreshSchedule()
+		a.refreshTray()
+		runtime.EventsEmit(a.ctx, "config:changed", cfg)
+	})
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "config changes will not be picked up: %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package main
+
+import "copy-image/internal/storage"
+
+// ListDevices returns the phones and cameras connected over USB as media
+// devices, which have no drive letter. Their Sources can be used as the
+// source folder to import photos straight from the device.
+func (a *App) ListDevices() ([]storage.MTPDevice, error) {
+	return storage.MTPDevices()
+}
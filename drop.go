@@ -0,0 +1,109 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// DropSummary is sent with the drop:scanned event after files or folders
+// were dropped onto the window. A single folder becomes the source, like
+// picking it with SelectSourceFolder. Anything else is an ad-hoc batch:
+// the next StartCopy copies the dropped files and the files found in the
+// dropped folders, until ScanFiles is called or something else is dropped.
+type DropSummary struct {
+	// Source is the dropped folder when it became the source.
+	Source string `json:"source,omitempty"`
+	// Files is how many files the next copy takes, Bytes their total size.
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+	// Rejected are the dropped paths that are neither files nor folders,
+	// or could not be read.
+	Rejected []string `json:"rejected"`
+	// Error is why the dropped folders could not be scanned.
+	Error string `json:"error,omitempty"`
+}
+
+// droppedFiles is an ad-hoc batch dropped onto the window.
+type droppedFiles struct {
+	folders []string
+	files   []string
+}
+
+// config returns cfg reading from the dropped folders instead of its own
+// sources, so recursive copies keep the subfolders of each dropped folder.
+func (d *droppedFiles) config(cfg *config.Config) *config.Config {
+	next := *cfg
+	next.Source = ""
+	next.Sources = d.folders
+	next.URLsFrom = ""
+	next.SourceS3 = nil
+	next.SourceSFTP = nil
+	return &next
+}
+
+// handleDrop is called by Wails with the paths dropped onto the window.
+func (a *App) handleDrop(paths []string) {
+	runtime.EventsEmit(a.ctx, "drop:scanned", a.drop(paths))
+}
+
+func (a *App) drop(paths []string) DropSummary {
+	summary := DropSummary{Rejected: []string{}}
+	var folders, files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			summary.Rejected = append(summary.Rejected, path)
+		case info.IsDir():
+			folders = append(folders, path)
+		case info.Mode().IsRegular():
+			files = append(files, path)
+		default:
+			summary.Rejected = append(summary.Rejected, path)
+		}
+	}
+
+	var scanned []string
+	switch {
+	case len(folders) == 1 && len(files) == 0:
+		_, _ = a.updateConfig(func(next *config.Config) error {
+			next.Source = folders[0]
+			next.Sources = nil
+			next.URLsFrom = ""
+			return nil
+		})
+		summary.Source = folders[0]
+		var err error
+		if scanned, err = a.ScanFiles(); err != nil {
+			summary.Error = err.Error()
+			return summary
+		}
+	case len(folders) > 0 || len(files) > 0:
+		d := &droppedFiles{folders: folders}
+		a.copier = copier.New(d.config(a.currentConfig()))
+		if len(folders) > 0 {
+			var err error
+			if d.files, err = a.copier.GetFilesContext(a.scanning(a.ctx)); err != nil {
+				summary.Error = a.catalog().T("app.scan_failed", err)
+				return summary
+			}
+		}
+		d.files = append(d.files, files...)
+		a.dropped = d
+		scanned = d.files
+	}
+
+	summary.Files = len(scanned)
+	for _, path := range scanned {
+		if info, err := os.Stat(path); err == nil {
+			summary.Bytes += info.Size()
+		}
+	}
+	return summary
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+
+	"copy-image/internal/config"
+	"copy-image/internal/shell"
+)
+
+// OpenInExplorer shows path in Explorer, for the completion screen's
+// "Open destination" and the "Reveal" button of failed files: a folder is
+// opened, a file is selected in its folder, and for a file that is missing
+// its folder is opened.
+func (a *App) OpenInExplorer(path string) error {
+	if path == "" {
+		return fmt.Errorf("path is required")
+	}
+	if config.IsRemote(path) || config.IsMTP(path) || config.IsHTTP(path) {
+		return fmt.Errorf("%s is not on this computer", path)
+	}
+	return shell.Reveal(path)
+}
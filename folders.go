@@ -0,0 +1,39 @@
+//go:build windows
+
+package main
+
+import (
+	"copy-image/internal/recent"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// GetRecentFolders returns the recently used sources and destinations,
+// most recent first, and the pinned folders, for the folder pickers.
+func (a *App) GetRecentFolders() (recent.Folders, error) {
+	return a.folders.Get()
+}
+
+// RecordFolder records path as the most recent folder of kind ("source"
+// or "destination"). Copies record their folders themselves.
+func (a *App) RecordFolder(kind, path string) error {
+	return a.folders.Use(kind, path)
+}
+
+// PinFolder adds path to the favorites.
+func (a *App) PinFolder(path string) error {
+	return a.folders.Pin(path)
+}
+
+// UnpinFolder removes path from the favorites.
+func (a *App) UnpinFolder(path string) error {
+	return a.folders.Unpin(path)
+}
+
+// useFolder records a folder a copy used. Failing to do so does not stop
+// the copy, so it is only logged.
+func (a *App) useFolder(kind, path string) {
+	if err := a.folders.Use(kind, path); err != nil {
+		runtime.LogWarningf(a.ctx, "recent folders: %v", err)
+	}
+}
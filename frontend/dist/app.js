@@ -23,9 +23,19 @@ document.addEventListener('DOMContentLoaded', function () {
         window.runtime.EventsOn('copy:complete', handleCompleteEvent);
         window.runtime.EventsOn('copy:cancelled', handleCancelledEvent);
 
+        // The config file was edited outside the app
+        window.runtime.EventsOn('config:changed', function () {
+            if (!isCopying) {
+                loadConfig();
+            }
+        });
+
         // Update progress events
-        window.runtime.EventsOn('update:progress', function (message) {
-            showToast(message, 'info');
+        window.runtime.EventsOn('update:progress', handleUpdateProgress);
+        // Releases found by the background check while the app runs
+        window.runtime.EventsOn('update:available', function (info) {
+            showUpdate(info);
+            showToast(`Update ${info.latestVersion} available`, 'info');
         });
     }
 
@@ -73,23 +83,85 @@ async function loadConfig() {
  */
 async function checkForUpdates() {
     try {
-        updateInfo = await window.go.main.App.CheckForUpdate();
-
-        if (updateInfo && updateInfo.available) {
-            const updateBtn = document.getElementById('updateBtn');
-            updateBtn.classList.add('visible');
-            updateBtn.title = `Update to ${updateInfo.latestVersion} available! Click to install.`;
-            console.log('Update available:', updateInfo.latestVersion);
-        } else {
-            const updateBtn = document.getElementById('updateBtn');
-            updateBtn.classList.remove('visible');
-        }
+        showUpdate(await window.go.main.App.CheckForUpdate());
     } catch (err) {
         // Network errors are expected when offline - fail silently
         console.error('Failed to check for updates:', err);
     }
 }
 
+/**
+ * Show the update button if info offers an update, hide it otherwise.
+ */
+function showUpdate(info) {
+    updateInfo = info;
+    const updateBtn = document.getElementById('updateBtn');
+    if (updateInfo && updateInfo.available) {
+        updateBtn.classList.add('visible');
+        updateBtn.title = `Update to ${updateInfo.latestVersion} available! Click to install, right-click to skip this version.`;
+        updateBtn.oncontextmenu = skipUpdate;
+        console.log('Update available:', updateInfo.latestVersion);
+    } else {
+        updateBtn.classList.remove('visible');
+    }
+}
+
+/**
+ * Stop offering the available version; the next release is offered again.
+ */
+async function skipUpdate(event) {
+    event.preventDefault();
+    if (!updateInfo || !confirm(`Skip ${updateInfo.latestVersion}? You will be offered the next release instead.`)) {
+        return;
+    }
+    try {
+        await window.go.main.App.SkipVersion(updateInfo.latestVersion);
+        document.getElementById('updateBtn').classList.remove('visible');
+        showToast(`${updateInfo.latestVersion} skipped`, 'info');
+    } catch (err) {
+        showToast('Failed to skip the update: ' + err, 'error');
+    }
+}
+
+/**
+ * Show how far the update got: a toast when a stage starts, and the
+ * download's percent and speed on the update button.
+ */
+let updateStage = '';
+function handleUpdateProgress(progress) {
+    if (progress.stage !== updateStage) {
+        updateStage = progress.stage;
+        showToast(progress.message, 'info');
+    }
+    const updateBtn = document.getElementById('updateBtn');
+    if (progress.stage === 'download' && progress.total > 0) {
+        const speed = (progress.bytesPerSecond / 1e6).toFixed(1);
+        updateBtn.title = `Downloading ${updateInfo.latestVersion}: ${progress.percent.toFixed(0)}% (${speed} MB/s)`;
+    } else {
+        updateBtn.title = progress.message;
+    }
+}
+
+/**
+ * The question asking to update to info's version, with its release date
+ * and notes, shortened to fit a dialog.
+ */
+function whatsNew(info) {
+    let text = `Update to ${info.latestVersion}`;
+    if (info.publishedAt) {
+        text += ` (released ${new Date(info.publishedAt).toLocaleDateString()})`;
+    }
+    text += '?';
+    let notes = (info.releaseNotes || '').trim();
+    if (notes) {
+        if (notes.length > 1500) {
+            notes = notes.slice(0, 1500) + '…\n(see the full notes on the release page)';
+        }
+        text += "\n\nWhat's new:\n" + notes;
+    }
+    return text;
+}
+
 /**
  * Download and install the available update.
  * This will restart the application after installing.
@@ -100,6 +172,10 @@ async function performUpdate() {
         return;
     }
 
+    if (!confirm(whatsNew(updateInfo))) {
+        return;
+    }
+
     const updateBtn = document.getElementById('updateBtn');
     updateBtn.disabled = true;
 
@@ -110,6 +186,7 @@ async function performUpdate() {
         showToast('Update installed! Restarting...', 'success');
     } catch (err) {
         showToast('Update failed: ' + err, 'error');
+        updateStage = '';
         updateBtn.disabled = false;
     }
 }
@@ -226,6 +303,41 @@ async function scanFiles() {
     }
 }
 
+/**
+ * Compare the destination with the source without copying, and offer to
+ * export the differences when there are any.
+ */
+async function verifyDestination() {
+    await updateConfigFromForm();
+
+    const verifyBtn = document.getElementById('verifyBtn');
+    verifyBtn.disabled = true;
+    verifyBtn.textContent = 'Verifying...';
+
+    try {
+        const report = await window.go.main.App.VerifyDestination(true);
+        const differences = report.missing.length + report.extra.length + report.mismatched.length;
+        if (differences === 0) {
+            showToast(`All ${report.checked} file(s) verified`, 'success');
+            return;
+        }
+
+        showToast(`${report.ok}/${report.checked} verified: ${report.missing.length} missing, ` +
+            `${report.mismatched.length} mismatched, ${report.extra.length} extra`, 'info');
+        if (confirm('The destination differs from the source. Export the report?')) {
+            const path = await window.go.main.App.ExportVerifyReport();
+            if (path) {
+                showToast('Report saved to ' + path, 'success');
+            }
+        }
+    } catch (err) {
+        showToast('Verify failed: ' + err, 'error');
+    } finally {
+        verifyBtn.disabled = false;
+        verifyBtn.textContent = 'Verify Destination';
+    }
+}
+
 /**
  * Start the copy operation.
  * @param {boolean} overwrite - Whether to overwrite existing files
@@ -266,6 +378,45 @@ async function startCopy(overwrite) {
     }
 }
 
+/**
+ * Copy, check every copied file against the source by hash, and seal the
+ * source when all of them match, for camera cards that must not be
+ * formatted before their copies are known to be good.
+ */
+async function startOffload() {
+    await updateConfigFromForm();
+
+    if (!document.getElementById('destPath').value) {
+        showToast('Please select a destination folder', 'error');
+        return;
+    }
+    const seal = confirm('After a clean verify, write a .verified marker into the source?') ? 'marker' : 'none';
+
+    const offloadBtn = document.getElementById('offloadBtn');
+    offloadBtn.disabled = true;
+    isCopying = true;
+    disableCopyButtons();
+    showProgressCard();
+    hideResultsCard();
+    resetProgress();
+
+    try {
+        const result = await window.go.main.App.StartOffload(seal);
+        if (isCopying) {
+            handleCompleteEvent(result.copy);
+        }
+        const clean = result.verify && result.verify.missing.length === 0 && result.verify.mismatched.length === 0;
+        showToast(result.message, clean ? 'success' : 'error');
+    } catch (err) {
+        showToast('Offload failed: ' + err, 'error');
+        hideProgressCard();
+        enableCopyButtons();
+        isCopying = false;
+    } finally {
+        offloadBtn.disabled = false;
+    }
+}
+
 /**
  * Cancel an ongoing copy operation.
  * Remaining files will not be copied.
@@ -358,6 +509,8 @@ function showResultsCard(result) {
 
     document.getElementById('resultSuccess').textContent = result.successful;
     document.getElementById('resultFailed').textContent = result.failed;
+    document.getElementById('resultFailedDesc').textContent =
+        result.corrupt > 0 ? `Errors (${result.corrupt} corrupt)` : 'Errors';
     document.getElementById('resultSkipped').textContent = result.skipped;
     document.getElementById('resultDuration').textContent = result.duration.toFixed(2) + 's';
 }
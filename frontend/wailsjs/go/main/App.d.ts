@@ -2,25 +2,127 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 import {config} from '../models';
+import {copier} from '../models';
+import {history} from '../models';
+import {logging} from '../models';
+import {queue} from '../models';
+import {recent} from '../models';
+import {schedule} from '../models';
+import {storage} from '../models';
+import {version} from '../models';
+
+export function AddGroup(arg1:config.CopyGroup):Promise<config.CopyGroup>;
 
 export function CancelCopy():Promise<void>;
 
+export function CancelJob(arg1:string):Promise<void>;
+
 export function CheckForUpdate():Promise<main.UpdateInfo>;
 
+export function ClearFinishedJobs():Promise<void>;
+
+export function DeleteGroup(arg1:string):Promise<void>;
+
+export function DeleteProfile(arg1:string):Promise<void>;
+
+export function DuplicateGroup(arg1:string):Promise<config.CopyGroup>;
+
+export function EnqueueJob(arg1:main.JobSpec):Promise<queue.Job>;
+
+export function ExportConfig(arg1:string):Promise<void>;
+
+export function ExportVerifyReport():Promise<string>;
+
+export function GetAppSettings():Promise<main.Settings>;
+
+export function GetBuildInfo():Promise<version.Info>;
+
 export function GetConfig():Promise<config.Config>;
 
+export function GetConfigProblems():Promise<Array<config.Problem>>;
+
 export function GetCurrentVersion():Promise<string>;
 
+export function GetGroups():Promise<Array<config.CopyGroup>>;
+
+export function GetHistory():Promise<Array<history.Run>>;
+
+export function GetRecentFolders():Promise<recent.Folders>;
+
+export function GetRecentLogs(arg1:number):Promise<Array<logging.Entry>>;
+
+export function GetRunDetails(arg1:number):Promise<history.Run>;
+
+export function GetSchedule():Promise<Array<schedule.Status>>;
+
+export function GetThumbnails(arg1:Array<string>, arg2:number):Promise<{[key: string]: string}>;
+
+export function GetTranslations():Promise<{[key: string]: string}>;
+
+export function ImportCard(arg1:string, arg2:string):Promise<main.CopyResult>;
+
+export function ImportConfig(arg1:string):Promise<config.Config>;
+
+export function IsWatching():Promise<boolean>;
+
+export function ListDevices():Promise<Array<storage.MTPDevice>>;
+
+export function ListJobs():Promise<Array<queue.Job>>;
+
+export function ListProfiles():Promise<Array<string>>;
+
+export function OpenInExplorer(arg1:string):Promise<void>;
+
 export function PerformUpdate(arg1:string):Promise<boolean>;
 
+export function PinFolder(arg1:string):Promise<void>;
+
+export function PrepareCopy(arg1:boolean):Promise<copier.Plan>;
+
+export function RecordFolder(arg1:string, arg2:string):Promise<void>;
+
+export function ReorderJobs(arg1:Array<string>):Promise<void>;
+
+export function RetryFailed(arg1:number):Promise<main.CopyResult>;
+
+export function RollbackUpdate():Promise<boolean>;
+
+export function RunGroup(arg1:string):Promise<main.CopyResult>;
+
 export function SaveConfig():Promise<void>;
 
+export function SaveProfile(arg1:string):Promise<void>;
+
 export function ScanFiles():Promise<Array<string>>;
 
 export function SelectDestFolder():Promise<string>;
 
+export function SelectProfile(arg1:string):Promise<config.Config>;
+
 export function SelectSourceFolder():Promise<string>;
 
+export function SetAppSettings(arg1:main.Settings):Promise<void>;
+
+export function SkipVersion(arg1:string):Promise<void>;
+
 export function StartCopy(arg1:boolean):Promise<main.CopyResult>;
 
+export function StartOffload(arg1:string):Promise<main.OffloadResult>;
+
+export function StartWatch():Promise<void>;
+
+export function StopWatch():Promise<void>;
+
+export function ToggleGroup(arg1:string, arg2:boolean):Promise<void>;
+
+export function UndoRun(arg1:number):Promise<history.UndoResult>;
+
+export function UnpinFolder(arg1:string):Promise<void>;
+
 export function UpdateConfig(arg1:config.Config):Promise<void>;
+
+export function UpdateGroup(arg1:config.CopyGroup):Promise<void>;
+
+export function ValidateConfig(arg1:config.Config):Promise<Array<config.Problem>>;
+
+export function VerifyDestination(arg1:boolean):Promise<copier.VerifyReport>;
@@ -2,30 +2,170 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddGroup(arg1) {
+  return window['go']['main']['App']['AddGroup'](arg1);
+}
+
 export function CancelCopy() {
   return window['go']['main']['App']['CancelCopy']();
 }
 
+export function CancelJob(arg1) {
+  return window['go']['main']['App']['CancelJob'](arg1);
+}
+
 export function CheckForUpdate() {
   return window['go']['main']['App']['CheckForUpdate']();
 }
 
+export function ClearFinishedJobs() {
+  return window['go']['main']['App']['ClearFinishedJobs']();
+}
+
+export function DeleteGroup(arg1) {
+  return window['go']['main']['App']['DeleteGroup'](arg1);
+}
+
+export function DeleteProfile(arg1) {
+  return window['go']['main']['App']['DeleteProfile'](arg1);
+}
+
+export function DuplicateGroup(arg1) {
+  return window['go']['main']['App']['DuplicateGroup'](arg1);
+}
+
+export function EnqueueJob(arg1) {
+  return window['go']['main']['App']['EnqueueJob'](arg1);
+}
+
+export function ExportConfig(arg1) {
+  return window['go']['main']['App']['ExportConfig'](arg1);
+}
+
+export function ExportVerifyReport() {
+  return window['go']['main']['App']['ExportVerifyReport']();
+}
+
+export function GetAppSettings() {
+  return window['go']['main']['App']['GetAppSettings']();
+}
+
+export function GetBuildInfo() {
+  return window['go']['main']['App']['GetBuildInfo']();
+}
+
 export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
 
+export function GetConfigProblems() {
+  return window['go']['main']['App']['GetConfigProblems']();
+}
+
 export function GetCurrentVersion() {
   return window['go']['main']['App']['GetCurrentVersion']();
 }
 
+export function GetGroups() {
+  return window['go']['main']['App']['GetGroups']();
+}
+
+export function GetHistory() {
+  return window['go']['main']['App']['GetHistory']();
+}
+
+export function GetRecentFolders() {
+  return window['go']['main']['App']['GetRecentFolders']();
+}
+
+export function GetRecentLogs(arg1) {
+  return window['go']['main']['App']['GetRecentLogs'](arg1);
+}
+
+export function GetRunDetails(arg1) {
+  return window['go']['main']['App']['GetRunDetails'](arg1);
+}
+
+export function GetSchedule() {
+  return window['go']['main']['App']['GetSchedule']();
+}
+
+export function GetThumbnails(arg1, arg2) {
+  return window['go']['main']['App']['GetThumbnails'](arg1, arg2);
+}
+
+export function GetTranslations() {
+  return window['go']['main']['App']['GetTranslations']();
+}
+
+export function ImportCard(arg1, arg2) {
+  return window['go']['main']['App']['ImportCard'](arg1, arg2);
+}
+
+export function ImportConfig(arg1) {
+  return window['go']['main']['App']['ImportConfig'](arg1);
+}
+
+export function IsWatching() {
+  return window['go']['main']['App']['IsWatching']();
+}
+
+export function ListDevices() {
+  return window['go']['main']['App']['ListDevices']();
+}
+
+export function ListJobs() {
+  return window['go']['main']['App']['ListJobs']();
+}
+
+export function ListProfiles() {
+  return window['go']['main']['App']['ListProfiles']();
+}
+
+export function OpenInExplorer(arg1) {
+  return window['go']['main']['App']['OpenInExplorer'](arg1);
+}
+
 export function PerformUpdate(arg1) {
   return window['go']['main']['App']['PerformUpdate'](arg1);
 }
 
+export function PinFolder(arg1) {
+  return window['go']['main']['App']['PinFolder'](arg1);
+}
+
+export function PrepareCopy(arg1) {
+  return window['go']['main']['App']['PrepareCopy'](arg1);
+}
+
+export function RecordFolder(arg1, arg2) {
+  return window['go']['main']['App']['RecordFolder'](arg1, arg2);
+}
+
+export function ReorderJobs(arg1) {
+  return window['go']['main']['App']['ReorderJobs'](arg1);
+}
+
+export function RetryFailed(arg1) {
+  return window['go']['main']['App']['RetryFailed'](arg1);
+}
+
+export function RollbackUpdate() {
+  return window['go']['main']['App']['RollbackUpdate']();
+}
+
+export function RunGroup(arg1) {
+  return window['go']['main']['App']['RunGroup'](arg1);
+}
+
 export function SaveConfig() {
   return window['go']['main']['App']['SaveConfig']();
 }
 
+export function SaveProfile(arg1) {
+  return window['go']['main']['App']['SaveProfile'](arg1);
+}
+
 export function ScanFiles() {
   return window['go']['main']['App']['ScanFiles']();
 }
@@ -34,14 +174,62 @@ export function SelectDestFolder() {
   return window['go']['main']['App']['SelectDestFolder']();
 }
 
+export function SelectProfile(arg1) {
+  return window['go']['main']['App']['SelectProfile'](arg1);
+}
+
 export function SelectSourceFolder() {
   return window['go']['main']['App']['SelectSourceFolder']();
 }
 
+export function SetAppSettings(arg1) {
+  return window['go']['main']['App']['SetAppSettings'](arg1);
+}
+
+export function SkipVersion(arg1) {
+  return window['go']['main']['App']['SkipVersion'](arg1);
+}
+
 export function StartCopy(arg1) {
   return window['go']['main']['App']['StartCopy'](arg1);
 }
 
+export function StartOffload(arg1) {
+  return window['go']['main']['App']['StartOffload'](arg1);
+}
+
+export function StartWatch() {
+  return window['go']['main']['App']['StartWatch']();
+}
+
+export function StopWatch() {
+  return window['go']['main']['App']['StopWatch']();
+}
+
+export function ToggleGroup(arg1, arg2) {
+  return window['go']['main']['App']['ToggleGroup'](arg1, arg2);
+}
+
+export function UndoRun(arg1) {
+  return window['go']['main']['App']['UndoRun'](arg1);
+}
+
+export function UnpinFolder(arg1) {
+  return window['go']['main']['App']['UnpinFolder'](arg1);
+}
+
 export function UpdateConfig(arg1) {
   return window['go']['main']['App']['UpdateConfig'](arg1);
 }
+
+export function UpdateGroup(arg1) {
+  return window['go']['main']['App']['UpdateGroup'](arg1);
+}
+
+export function ValidateConfig(arg1) {
+  return window['go']['main']['App']['ValidateConfig'](arg1);
+}
+
+export function VerifyDestination(arg1) {
+  return window['go']['main']['App']['VerifyDestination'](arg1);
+}
@@ -1,10 +1,194 @@
 export namespace config {
 	
+	export class FTPOptions {
+	    username?: string;
+	    password?: string;
+	    tls?: string;
+	    insecureSkipVerify?: boolean;
+	    disableEPSV?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new FTPOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.tls = source["tls"];
+	        this.insecureSkipVerify = source["insecureSkipVerify"];
+	        this.disableEPSV = source["disableEPSV"];
+	    }
+	}
+	export class S3Options {
+	    endpoint?: string;
+	    region?: string;
+	    profile?: string;
+	    storageClass?: string;
+	    partSizeMB?: number;
+	    insecure?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new S3Options(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.endpoint = source["endpoint"];
+	        this.region = source["region"];
+	        this.profile = source["profile"];
+	        this.storageClass = source["storageClass"];
+	        this.partSizeMB = source["partSizeMB"];
+	        this.insecure = source["insecure"];
+	    }
+	}
+	export class SFTPOptions {
+	    username: string;
+	    password?: string;
+	    keyFile?: string;
+	    knownHosts?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SFTPOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.keyFile = source["keyFile"];
+	        this.knownHosts = source["knownHosts"];
+	    }
+	}
+	export class SMBOptions {
+	    username: string;
+	    password?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SMBOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.username = source["username"];
+	        this.password = source["password"];
+	    }
+	}
+	export class ZipOptions {
+	    password?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ZipOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.password = source["password"];
+	    }
+	}
+	export class Resize {
+	    maxSize: number;
+	    quality?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Resize(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxSize = source["maxSize"];
+	        this.quality = source["quality"];
+	    }
+	}
+	export class Watermark {
+	    image?: string;
+	    text?: string;
+	    position?: string;
+	    opacity?: number;
+	    size?: number;
+	    quality?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Watermark(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.image = source["image"];
+	        this.text = source["text"];
+	        this.position = source["position"];
+	        this.opacity = source["opacity"];
+	        this.size = source["size"];
+	        this.quality = source["quality"];
+	    }
+	}
+	export class Transform {
+	    resize?: Resize;
+	    stripMetadata?: boolean;
+	    watermark?: Watermark;
+	
+	    static createFrom(source: any = {}) {
+	        return new Transform(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.resize = this.convertValues(source["resize"], Resize);
+	        this.stripMetadata = source["stripMetadata"];
+	        this.watermark = this.convertValues(source["watermark"], Watermark);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TranscodeRule {
+	    from: string[];
+	    to: string;
+	    quality?: number;
+	    command?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TranscodeRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.quality = source["quality"];
+	        this.command = source["command"];
+	    }
+	}
 	export class Destination {
 	    id: string;
 	    path: string;
 	    overwrite: boolean;
 	    enabled: boolean;
+	    extensions?: string[];
+	    exclude?: string[];
+	    s3?: S3Options;
+	    smb?: SMBOptions;
+	    ftp?: FTPOptions;
+	    type?: string;
+	    zip?: ZipOptions;
+	    transcode?: TranscodeRule[];
+	    transform?: Transform;
+	    compress?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new Destination(source);
@@ -16,7 +200,35 @@ export namespace config {
 	        this.path = source["path"];
 	        this.overwrite = source["overwrite"];
 	        this.enabled = source["enabled"];
+	        this.extensions = source["extensions"];
+	        this.exclude = source["exclude"];
+	        this.s3 = this.convertValues(source["s3"], S3Options);
+	        this.smb = this.convertValues(source["smb"], SMBOptions);
+	        this.ftp = this.convertValues(source["ftp"], FTPOptions);
+	        this.type = source["type"];
+	        this.zip = this.convertValues(source["zip"], ZipOptions);
+	        this.transcode = this.convertValues(source["transcode"], TranscodeRule);
+	        this.transform = this.convertValues(source["transform"], Transform);
+	        this.compress = source["compress"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class CopyGroup {
 	    id: string;
@@ -24,6 +236,17 @@ export namespace config {
 	    source: string;
 	    destinations: Destination[];
 	    enabled: boolean;
+	    workers?: number;
+	    maxRetries?: number;
+	    extensions?: string[];
+	    exclude?: string[];
+	    recursive?: boolean;
+	    incremental?: boolean;
+	    priority?: number;
+	    dependsOn?: string[];
+	    schedule?: string;
+	    s3?: S3Options;
+	    sftp?: SFTPOptions;
 	
 	    static createFrom(source: any = {}) {
 	        return new CopyGroup(source);
@@ -36,6 +259,253 @@ export namespace config {
 	        this.source = source["source"];
 	        this.destinations = this.convertValues(source["destinations"], Destination);
 	        this.enabled = source["enabled"];
+	        this.workers = source["workers"];
+	        this.maxRetries = source["maxRetries"];
+	        this.extensions = source["extensions"];
+	        this.exclude = source["exclude"];
+	        this.recursive = source["recursive"];
+	        this.incremental = source["incremental"];
+	        this.priority = source["priority"];
+	        this.dependsOn = source["dependsOn"];
+	        this.schedule = source["schedule"];
+	        this.s3 = this.convertValues(source["s3"], S3Options);
+	        this.sftp = this.convertValues(source["sftp"], SFTPOptions);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Problem {
+	    field: string;
+	    message: string;
+	    hint?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Problem(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.message = source["message"];
+	        this.hint = source["hint"];
+	    }
+	}
+	export class Profile {
+	    source: string;
+	    sources?: string[];
+	    destination: string;
+	    overwrite: boolean;
+	    extensions?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Profile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.source = source["source"];
+	        this.sources = source["sources"];
+	        this.destination = source["destination"];
+	        this.overwrite = source["overwrite"];
+	        this.extensions = source["extensions"];
+	    }
+	}
+	export class CardProfile {
+	    name: string;
+	    label?: string;
+	    dcim?: boolean;
+	    folder?: string;
+	    group: string;
+	    autoStart?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CardProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.label = source["label"];
+	        this.dcim = source["dcim"];
+	        this.folder = source["folder"];
+	        this.group = source["group"];
+	        this.autoStart = source["autoStart"];
+	    }
+	}
+	export class Email {
+	    host: string;
+	    port?: number;
+	    username?: string;
+	    password?: string;
+	    from?: string;
+	    to: string[];
+	    events?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Email(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.port = source["port"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.events = source["events"];
+	    }
+	}
+	export class Hooks {
+	    preCopy?: string;
+	    postCopy?: string;
+	    onFailure?: string;
+	    timeout?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Hooks(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.preCopy = source["preCopy"];
+	        this.postCopy = source["postCopy"];
+	        this.onFailure = source["onFailure"];
+	        this.timeout = source["timeout"];
+	    }
+	}
+	export class Processor {
+	    name: string;
+	    extensions?: string[];
+	    command?: string;
+	    stage?: string;
+	    timeout?: number;
+	    options?: {[key: string]: string};
+	
+	    static createFrom(source: any = {}) {
+	        return new Processor(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.extensions = source["extensions"];
+	        this.command = source["command"];
+	        this.stage = source["stage"];
+	        this.timeout = source["timeout"];
+	        this.options = source["options"];
+	    }
+	}
+	export class Webhook {
+	    url: string;
+	    events?: string[];
+	    headers?: {[key: string]: string};
+	    template?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Webhook(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.events = source["events"];
+	        this.headers = source["headers"];
+	        this.template = source["template"];
+	    }
+	}
+	export class WindowState {
+	    x: number;
+	    y: number;
+	    width: number;
+	    height: number;
+	    maximized?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new WindowState(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.x = source["x"];
+	        this.y = source["y"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.maximized = source["maximized"];
+	    }
+	}
+	export class UpdateFeed {
+	    github?: string;
+	    repo?: string;
+	    url?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UpdateFeed(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.github = source["github"];
+	        this.repo = source["repo"];
+	        this.url = source["url"];
+	    }
+	}
+	export class HTTPOptions {
+	    proxy?: string;
+	    caFile?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HTTPOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.proxy = source["proxy"];
+	        this.caFile = source["caFile"];
+	    }
+	}
+	export class AppSettings {
+	    theme?: string;
+	    startMinimized?: boolean;
+	    closeToTray?: boolean;
+	    window?: WindowState;
+	    checkUpdates?: boolean;
+	    updateInterval?: string;
+	    updateChannel?: string;
+	    skipVersion?: string;
+	    confirmOverwrite?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new AppSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.theme = source["theme"];
+	        this.startMinimized = source["startMinimized"];
+	        this.closeToTray = source["closeToTray"];
+	        this.window = this.convertValues(source["window"], WindowState);
+	        this.checkUpdates = source["checkUpdates"];
+	        this.updateInterval = source["updateInterval"];
+	        this.updateChannel = source["updateChannel"];
+	        this.skipVersion = source["skipVersion"];
+	        this.confirmOverwrite = source["confirmOverwrite"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -59,12 +529,48 @@ export namespace config {
 	export class Config {
 	    source: string;
 	    destination: string;
+	    s3?: S3Options;
+	    smb?: SMBOptions;
+	    ftp?: FTPOptions;
+	    destinationType?: string;
+	    zip?: ZipOptions;
+	    sourceS3?: S3Options;
+	    sourceSFTP?: SFTPOptions;
+	    sources?: string[];
+	    urlsFrom?: string;
+	    profiles?: {[key: string]: Profile};
+	    activeProfile?: string;
 	    groups: CopyGroup[];
+	    cards?: CardProfile[];
 	    workers: number;
 	    overwrite: boolean;
 	    extensions: string[];
 	    maxRetries: number;
 	    dryRun: boolean;
+	    order?: string;
+	    recursive: boolean;
+	    incremental?: boolean;
+	    stableChecks?: number;
+	    offlineWait?: number;
+	    exclude?: string[];
+	    useTrash?: boolean;
+	    filenamePolicy?: string;
+	    checksums?: string;
+	    checksumsIn?: string;
+	    mhl?: boolean;
+	    webhooks?: Webhook[];
+	    email?: Email;
+	    hooks?: Hooks;
+	    validateImages?: string;
+	    transcode?: TranscodeRule[];
+	    transform?: Transform;
+	    compress?: string;
+	    processors?: Processor[];
+	    http?: HTTPOptions;
+	    noUpdateCheck?: boolean;
+	    updateFeed?: UpdateFeed;
+	    language: string;
+	    app?: AppSettings;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -74,12 +580,48 @@ export namespace config {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.source = source["source"];
 	        this.destination = source["destination"];
+	        this.s3 = this.convertValues(source["s3"], S3Options);
+	        this.smb = this.convertValues(source["smb"], SMBOptions);
+	        this.ftp = this.convertValues(source["ftp"], FTPOptions);
+	        this.destinationType = source["destinationType"];
+	        this.zip = this.convertValues(source["zip"], ZipOptions);
+	        this.sourceS3 = this.convertValues(source["sourceS3"], S3Options);
+	        this.sourceSFTP = this.convertValues(source["sourceSFTP"], SFTPOptions);
+	        this.sources = source["sources"];
+	        this.urlsFrom = source["urlsFrom"];
+	        this.profiles = this.convertValues(source["profiles"], Profile, true);
+	        this.activeProfile = source["activeProfile"];
 	        this.groups = this.convertValues(source["groups"], CopyGroup);
+	        this.cards = this.convertValues(source["cards"], CardProfile);
 	        this.workers = source["workers"];
 	        this.overwrite = source["overwrite"];
 	        this.extensions = source["extensions"];
 	        this.maxRetries = source["maxRetries"];
 	        this.dryRun = source["dryRun"];
+	        this.order = source["order"];
+	        this.recursive = source["recursive"];
+	        this.incremental = source["incremental"];
+	        this.stableChecks = source["stableChecks"];
+	        this.offlineWait = source["offlineWait"];
+	        this.exclude = source["exclude"];
+	        this.useTrash = source["useTrash"];
+	        this.filenamePolicy = source["filenamePolicy"];
+	        this.checksums = source["checksums"];
+	        this.checksumsIn = source["checksumsIn"];
+	        this.mhl = source["mhl"];
+	        this.webhooks = this.convertValues(source["webhooks"], Webhook);
+	        this.email = this.convertValues(source["email"], Email);
+	        this.hooks = this.convertValues(source["hooks"], Hooks);
+	        this.validateImages = source["validateImages"];
+	        this.transcode = this.convertValues(source["transcode"], TranscodeRule);
+	        this.transform = this.convertValues(source["transform"], Transform);
+	        this.compress = source["compress"];
+	        this.processors = this.convertValues(source["processors"], Processor);
+	        this.http = this.convertValues(source["http"], HTTPOptions);
+	        this.noUpdateCheck = source["noUpdateCheck"];
+	        this.updateFeed = this.convertValues(source["updateFeed"], UpdateFeed);
+	        this.language = source["language"];
+	        this.app = this.convertValues(source["app"], AppSettings);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -104,40 +646,392 @@ export namespace config {
 
 }
 
-export namespace main {
+export namespace copier {
 	
-	export class CopyResult {
-	    success: boolean;
-	    message: string;
-	    totalFiles: number;
-	    successful: number;
-	    failed: number;
-	    skipped: number;
-	    failedFiles: string[];
-	    duration: number;
+	export class CreatedFile {
+	    path: string;
+	    size: number;
+	    // Go type: time
+	    modTime: any;
 	
 	    static createFrom(source: any = {}) {
-	        return new CopyResult(source);
+	        return new CreatedFile(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.success = source["success"];
-	        this.message = source["message"];
-	        this.totalFiles = source["totalFiles"];
-	        this.successful = source["successful"];
-	        this.failed = source["failed"];
-	        this.skipped = source["skipped"];
-	        this.failedFiles = source["failedFiles"];
-	        this.duration = source["duration"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.modTime = this.convertValues(source["modTime"], null);
 	    }
-	}
-	export class UpdateInfo {
-	    available: boolean;
-	    currentVersion: string;
-	    latestVersion: string;
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class FailedFile {
+	    path: string;
+	    dest?: string;
+	    error: string;
+	    errorCode?: string;
+	    attempts: number;
+	    bytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new FailedFile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.dest = source["dest"];
+	        this.error = source["error"];
+	        this.errorCode = source["errorCode"];
+	        this.attempts = source["attempts"];
+	        this.bytes = source["bytes"];
+	    }
+	}
+	export class Plan {
+	    copy: string[];
+	    overwrite: string[];
+	    skip: string[];
+	    bytes: number;
+	    freeBytes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Plan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.copy = source["copy"];
+	        this.overwrite = source["overwrite"];
+	        this.skip = source["skip"];
+	        this.bytes = source["bytes"];
+	        this.freeBytes = source["freeBytes"];
+	    }
+	}
+	export class VerifyReport {
+	    destination: string;
+	    hashed: boolean;
+	    checked: number;
+	    ok: number;
+	    missing: string[];
+	    extra: string[];
+	    mismatched: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new VerifyReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.destination = source["destination"];
+	        this.hashed = source["hashed"];
+	        this.checked = source["checked"];
+	        this.ok = source["ok"];
+	        this.missing = source["missing"];
+	        this.extra = source["extra"];
+	        this.mismatched = source["mismatched"];
+	    }
+	}
+
+}
+
+export namespace history {
+	
+	export class Run {
+	    id: number;
+	    kind: string;
+	    groupId?: string;
+	    sources: string[];
+	    destination: string;
+	    // Go type: time
+	    start: any;
+	    duration: number;
+	    dryRun: boolean;
+	    cancelled?: boolean;
+	    totalFiles: number;
+	    successful: number;
+	    failed: number;
+	    skipped: number;
+	    failures?: string[];
+	    failedPaths?: string[];
+	    created?: copier.CreatedFile[];
+	    // Go type: time
+	    undoneAt?: any;
+	    config?: config.Config;
+	
+	    static createFrom(source: any = {}) {
+	        return new Run(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.kind = source["kind"];
+	        this.groupId = source["groupId"];
+	        this.sources = source["sources"];
+	        this.destination = source["destination"];
+	        this.start = this.convertValues(source["start"], null);
+	        this.duration = source["duration"];
+	        this.dryRun = source["dryRun"];
+	        this.cancelled = source["cancelled"];
+	        this.totalFiles = source["totalFiles"];
+	        this.successful = source["successful"];
+	        this.failed = source["failed"];
+	        this.skipped = source["skipped"];
+	        this.failures = source["failures"];
+	        this.failedPaths = source["failedPaths"];
+	        this.created = this.convertValues(source["created"], copier.CreatedFile);
+	        this.undoneAt = this.convertValues(source["undoneAt"], null);
+	        this.config = this.convertValues(source["config"], config.Config);
+	    }
+
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UndoResult {
+	    removed: string[];
+	    missing: string[];
+	    kept: string[];
+	    failed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new UndoResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.removed = source["removed"];
+	        this.missing = source["missing"];
+	        this.kept = source["kept"];
+	        this.failed = source["failed"];
+	    }
+	}
+
+}
+
+export namespace logging {
+	
+	export class Entry {
+	    // Go type: time
+	    time: any;
+	    level: string;
+	    message: string;
+	    file?: string;
+	    attrs?: {[key: string]: string};
+	
+	    static createFrom(source: any = {}) {
+	        return new Entry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.time = this.convertValues(source["time"], null);
+	        this.level = source["level"];
+	        this.message = source["message"];
+	        this.file = source["file"];
+	        this.attrs = source["attrs"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+export namespace main {
+	
+	export class CopyResult {
+	    success: boolean;
+	    message: string;
+	    totalFiles: number;
+	    successful: number;
+	    failed: number;
+	    skipped: number;
+	    failedFiles: copier.FailedFile[];
+	    corrupt: number;
+	    duration: number;
+	    totalBytes: number;
+	    overwrittenBytes: number;
+	    skippedBytes: number;
+	    bytesPerSecond: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CopyResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.totalFiles = source["totalFiles"];
+	        this.successful = source["successful"];
+	        this.failed = source["failed"];
+	        this.skipped = source["skipped"];
+	        this.failedFiles = this.convertValues(source["failedFiles"], copier.FailedFile);
+	        this.corrupt = source["corrupt"];
+	        this.duration = source["duration"];
+	        this.totalBytes = source["totalBytes"];
+	        this.overwrittenBytes = source["overwrittenBytes"];
+	        this.skippedBytes = source["skippedBytes"];
+	        this.bytesPerSecond = source["bytesPerSecond"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class JobSpec {
+	    kind: string;
+	    groupId?: string;
+	    path?: string;
+	    profile?: string;
+	    source?: string;
+	    destination?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobSpec(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.groupId = source["groupId"];
+	        this.path = source["path"];
+	        this.profile = source["profile"];
+	        this.source = source["source"];
+	        this.destination = source["destination"];
+	    }
+	}
+	export class OffloadResult {
+	    copy: CopyResult;
+	    verify: copier.VerifyReport;
+	    sealed: boolean;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OffloadResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.copy = this.convertValues(source["copy"], CopyResult);
+	        this.verify = this.convertValues(source["verify"], copier.VerifyReport);
+	        this.sealed = source["sealed"];
+	        this.message = source["message"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Settings {
+	    theme: string;
+	    language: string;
+	    startMinimized: boolean;
+	    closeToTray: boolean;
+	    checkUpdates: boolean;
+	    updateChannel: string;
+	    confirmOverwrite: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.theme = source["theme"];
+	        this.language = source["language"];
+	        this.startMinimized = source["startMinimized"];
+	        this.closeToTray = source["closeToTray"];
+	        this.checkUpdates = source["checkUpdates"];
+	        this.updateChannel = source["updateChannel"];
+	        this.confirmOverwrite = source["confirmOverwrite"];
+	    }
+	}
+	export class UpdateInfo {
+	    available: boolean;
+	    currentVersion: string;
+	    latestVersion: string;
 	    downloadUrl: string;
 	    releaseUrl: string;
+	    releaseNotes: string;
+	    publishedAt: string;
+	    channel: string;
+	    skipped: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new UpdateInfo(source);
@@ -150,6 +1044,206 @@ export namespace main {
 	        this.latestVersion = source["latestVersion"];
 	        this.downloadUrl = source["downloadUrl"];
 	        this.releaseUrl = source["releaseUrl"];
+	        this.releaseNotes = source["releaseNotes"];
+	        this.publishedAt = source["publishedAt"];
+	        this.channel = source["channel"];
+	        this.skipped = source["skipped"];
+	    }
+	}
+
+}
+
+export namespace queue {
+	
+	export class Job {
+	    id: string;
+	    name: string;
+	    status: string;
+	    // Go type: time
+	    added: any;
+	    // Go type: time
+	    started?: any;
+	    // Go type: time
+	    finished?: any;
+	    result?: any;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Job(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.status = source["status"];
+	        this.added = this.convertValues(source["added"], null);
+	        this.started = this.convertValues(source["started"], null);
+	        this.finished = this.convertValues(source["finished"], null);
+	        this.result = source["result"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+export namespace recent {
+	
+	export class Folders {
+	    sources: string[];
+	    destinations: string[];
+	    favorites: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Folders(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sources = source["sources"];
+	        this.destinations = source["destinations"];
+	        this.favorites = source["favorites"];
+	    }
+	}
+
+}
+export namespace schedule {
+	
+	export class Run {
+	    // Go type: time
+	    start: any;
+	    duration: number;
+	    ok: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Run(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = this.convertValues(source["start"], null);
+	        this.duration = source["duration"];
+	        this.ok = source["ok"];
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Status {
+	    id: string;
+	    schedule: string;
+	    // Go type: time
+	    nextRun: any;
+	    lastRun?: Run;
+	
+	    static createFrom(source: any = {}) {
+	        return new Status(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.schedule = source["schedule"];
+	        this.nextRun = this.convertValues(source["nextRun"], null);
+	        this.lastRun = this.convertValues(source["lastRun"], Run);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace storage {
+	
+	export class MTPDevice {
+	    name: string;
+	    storages: string[];
+	    sources: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new MTPDevice(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.storages = source["storages"];
+	        this.sources = source["sources"];
+	    }
+	}
+
+}
+
+export namespace version {
+	
+	export class Info {
+	    version: string;
+	    commit?: string;
+	    date?: string;
+	    goVersion: string;
+	    platform: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Info(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.version = source["version"];
+	        this.commit = source["commit"];
+	        this.date = source["date"];
+	        this.goVersion = source["goVersion"];
+	        this.platform = source["platform"];
 	    }
 	}
 
@@ -0,0 +1,233 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// The group manager edits the groups of the current config through these
+// bindings, which check the result like UpdateConfig does. Changes are
+// kept in memory until the frontend calls SaveConfig.
+
+// GetGroups returns the configured copy groups in config order.
+func (a *App) GetGroups() []config.CopyGroup {
+	return a.currentConfig().Groups
+}
+
+// AddGroup adds a copy group and returns it with generated IDs filled in.
+// The change is only kept if the resulting config is valid; call
+// SaveConfig to persist it.
+func (a *App) AddGroup(group config.CopyGroup) (config.CopyGroup, error) {
+	var added config.CopyGroup
+	_, err := a.updateConfig(func(next *config.Config) error {
+		var err error
+		if added, err = next.AddGroup(group); err != nil {
+			return err
+		}
+		if err := next.Validate(); err != nil {
+			return fmt.Errorf("invalid group: %w", err)
+		}
+		return nil
+	})
+	return added, err
+}
+
+// UpdateGroup replaces the group with the same ID, e.g. after editing it
+// in the group editor. Like AddGroup, an invalid result is rejected and
+// the current config is left untouched.
+func (a *App) UpdateGroup(group config.CopyGroup) error {
+	_, err := a.updateConfig(func(next *config.Config) error {
+		if err := next.UpdateGroup(group); err != nil {
+			return err
+		}
+		if err := next.Validate(); err != nil {
+			return fmt.Errorf("invalid group: %w", err)
+		}
+		return nil
+	})
+	return err
+}
+
+// DeleteGroup removes the group with the given ID. It is refused while
+// another group depends on it or a card profile imports with it.
+func (a *App) DeleteGroup(id string) error {
+	_, err := a.updateConfig(func(next *config.Config) error {
+		if !next.RemoveGroup(id) {
+			return fmt.Errorf("group %q not found", id)
+		}
+		if err := next.Validate(); err != nil {
+			return fmt.Errorf("cannot delete group: %w", err)
+		}
+		return nil
+	})
+	return err
+}
+
+// DuplicateGroup adds a disabled copy of the group with the given ID right
+// after it and returns it, ready to be edited (see
+// config.Config.DuplicateGroup).
+func (a *App) DuplicateGroup(id string) (config.CopyGroup, error) {
+	var dup config.CopyGroup
+	_, err := a.updateConfig(func(next *config.Config) error {
+		var err error
+		if dup, err = next.DuplicateGroup(id); err != nil {
+			return err
+		}
+		if err := next.Validate(); err != nil {
+			return fmt.Errorf("invalid group: %w", err)
+		}
+		return nil
+	})
+	return dup, err
+}
+
+// ToggleGroup enables or disables the group with the given ID, so it is
+// included in or left out of "run all", watch mode and its schedule.
+func (a *App) ToggleGroup(id string, enabled bool) error {
+	_, err := a.updateConfig(func(next *config.Config) error {
+		group := next.FindGroup(id)
+		if group == nil {
+			return fmt.Errorf("group %q not found", id)
+		}
+		group.Enabled = enabled
+		if err := next.Validate(); err != nil {
+			return fmt.Errorf("invalid group: %w", err)
+		}
+		return nil
+	})
+	return err
+}
+
+// RunGroup copies the group with the given ID to all of its enabled
+// destinations, even when the group itself is disabled, like
+// `copyimage groups run <id>`. CancelCopy stops it.
+func (a *App) RunGroup(id string) CopyResult {
+	// Validate fills in defaults, so it checks a copy.
+	cfg := cloneConfig(a.currentConfig())
+	group := cfg.FindGroup(id)
+	if group == nil {
+		return CopyResult{Message: fmt.Sprintf("group %q not found", id)}
+	}
+	if err := cfg.Validate(); err != nil {
+		return CopyResult{Message: err.Error()}
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelFunc = cancel
+	defer func() {
+		cancel()
+		a.cancelFunc = nil
+	}()
+
+	summary, err := a.runGroup(ctx, history.KindGroup, cfg, *group)
+	if err != nil {
+		return CopyResult{Message: err.Error()}
+	}
+	return a.copyResult(summary.Total())
+}
+
+// GroupStart is sent with the copy:group:start event before a group runs,
+// listing the destinations it copies to, so the frontend can lay out one
+// progress bar per destination.
+type GroupStart struct {
+	GroupID      string             `json:"groupId"`
+	Name         string             `json:"name"`
+	Kind         string             `json:"kind"` // history.Kind* that started the run
+	Destinations []GroupDestination `json:"destinations"`
+}
+
+// GroupDestination is an enabled destination of a running group.
+type GroupDestination struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
+
+// DestinationProgress is sent with the copy:destination:progress event for
+// every file copied to one destination of a running group.
+type DestinationProgress struct {
+	GroupID       string `json:"groupId"`
+	DestinationID string `json:"destinationId"`
+	ProgressEvent
+}
+
+// GroupComplete is sent with the copy:group:complete event once a group
+// ran, with the result of each destination. A group whose source could not
+// be scanned has no destinations and its error in Result.Message.
+type GroupComplete struct {
+	GroupID      string              `json:"groupId"`
+	Result       CopyResult          `json:"result"`
+	Destinations []DestinationResult `json:"destinations"`
+}
+
+// DestinationResult is the result of one destination of a group.
+type DestinationResult struct {
+	ID     string     `json:"id"`
+	Path   string     `json:"path"`
+	Result CopyResult `json:"result"`
+}
+
+// runGroup runs group with hooks, notifications and history recorded as
+// kind, emitting copy:group:start, copy:destination:progress and
+// copy:group:complete.
+func (a *App) runGroup(ctx context.Context, kind string, cfg *config.Config, group config.CopyGroup) (copier.GroupSummary, error) {
+	start := GroupStart{GroupID: group.ID, Name: group.Name, Kind: kind, Destinations: []GroupDestination{}}
+	for _, d := range group.Destinations {
+		if d.Enabled {
+			start.Destinations = append(start.Destinations, GroupDestination{ID: d.ID, Path: d.Path})
+		}
+	}
+	runtime.EventsEmit(a.ctx, "copy:group:start", start)
+
+	// A group started by hand while its schedule runs it, or the other
+	// way round, is refused rather than copied twice at once.
+	locked := copier.WithGroupLocks(a.scanning(ctx), a.dataPath("locks"), 0)
+	summary, err := copier.RunGroup(locked, cfg, group, func(ctx context.Context, c *copier.Copier, files []string) copier.CopySummary {
+		destID := destinationID(group, c)
+		a.notifyStart(kind, group.ID, c, len(files))
+		begin := time.Now()
+		summary := a.runHooked(ctx, kind, group.ID, c, len(files), func() copier.CopySummary {
+			return a.copyLocked(ctx, c, files, cfg.DryRun, func(ev copier.Event) {
+				if f, ok := ev.(copier.FileFinished); ok {
+					runtime.EventsEmit(a.ctx, "copy:destination:progress", DestinationProgress{
+						GroupID:       group.ID,
+						DestinationID: destID,
+						ProgressEvent: newProgressEvent(f),
+					})
+				}
+			})
+		})
+		a.recordRun(kind, group.ID, c, begin, summary, ctx.Err() != nil)
+		return summary
+	})
+
+	complete := GroupComplete{GroupID: group.ID, Destinations: []DestinationResult{}}
+	if err != nil {
+		complete.Result = CopyResult{Message: err.Error()}
+	} else {
+		complete.Result = a.copyResult(summary.Total())
+		for _, d := range summary.Destinations {
+			complete.Destinations = append(complete.Destinations, DestinationResult{ID: d.DestinationID, Path: d.Path, Result: a.copyResult(d.Summary)})
+		}
+	}
+	runtime.EventsEmit(a.ctx, "copy:group:complete", complete)
+	return summary, err
+}
+
+// destinationID returns the ID of the destination of group c copies to.
+func destinationID(group config.CopyGroup, c *copier.Copier) string {
+	for _, d := range group.Destinations {
+		if d.Enabled && d.Path == c.Config().Destination {
+			return d.ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	"copy-image/internal/lock"
+	"copy-image/internal/storage"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// historyLimit is how many runs GetHistory returns; older ones stay in
+// the database and can be listed with `copyimage history -limit 0`.
+const historyLimit = 200
+
+// history returns the run history, kept next to the config file like the
+// CLI's so both list the same runs.
+func (a *App) history() *history.DB {
+	return history.Open(a.dataPath(history.FileName))
+}
+
+// recordRun stores a finished batch in the history and tells the webhooks.
+// Failing to record is only logged: the copy itself went through.
+func (a *App) recordRun(kind, groupID string, c *copier.Copier, start time.Time, summary copier.CopySummary, cancelled bool) {
+	run := history.NewRun(kind, groupID, c, start, summary)
+	run.Cancelled = cancelled
+	if err := a.history().Add(run); err != nil {
+		runtime.LogWarningf(a.ctx, "history: %v", err)
+	}
+	a.notifyFinish(kind, groupID, c, summary, cancelled)
+}
+
+// GetHistory returns the latest runs, newest first, without their config
+// snapshot and failures (see GetRunDetails).
+func (a *App) GetHistory() ([]history.Run, error) {
+	return a.history().List(historyLimit)
+}
+
+// GetRunDetails returns a run with its config snapshot and failed files.
+func (a *App) GetRunDetails(id uint64) (*history.Run, error) {
+	return a.history().Get(id)
+}
+
+// UndoRun removes the files a run created, keeping any file changed since
+// and everything that was in the destination before. It takes the
+// destination's session lock so it never races a copy.
+func (a *App) UndoRun(id uint64) (*history.UndoResult, error) {
+	db := a.history()
+	run, err := db.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	current := a.currentConfig()
+	target := run.Target(current)
+	// A destination deleted since holds nothing left to undo, and locking
+	// it would create it again.
+	if _, err := os.Stat(target.LockDir()); err == nil {
+		lease, err := lock.Acquire(a.ctx, target.LockDir(), lock.Options{})
+		if err != nil {
+			return nil, errors.New(a.catalog().T("app.destination_busy", err))
+		}
+		defer func() { _ = lease.Release() }()
+	}
+	dst, err := storage.ForDestination(target)
+	if err != nil {
+		return nil, err
+	}
+	return db.Undo(id, dst, current.UseTrash)
+}
+
+// RetryFailed copies again only the files that failed in a run (the latest
+// one when id is 0), to the same destination with the settings it ran
+// with. It emits the events of StartCopy and is recorded as a new run.
+func (a *App) RetryFailed(id uint64) CopyResult {
+	db := a.history()
+	var run *history.Run
+	var err error
+	if id == 0 {
+		run, err = db.Last()
+	} else {
+		run, err = db.Get(id)
+	}
+	if err != nil {
+		return CopyResult{Message: err.Error()}
+	}
+	if run.Config == nil {
+		return CopyResult{Message: fmt.Sprintf("run %d has no settings to retry with", run.ID)}
+	}
+	files := run.FailedPaths
+	if len(files) == 0 {
+		return CopyResult{Success: true, Message: a.catalog().T("app.no_files")}
+	}
+
+	cfg := *run.Config
+	// History keeps no passwords: those of the destination and source
+	// come from the settings in use now.
+	cfg.RestoreSecrets(a.currentConfig(), run.GroupID)
+	// The files were being written when they failed, so what the
+	// destination holds of them is a partial copy to replace.
+	cfg.Overwrite = true
+	cfg.DryRun = false
+	c := copier.New(&cfg)
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.cancelFunc = cancel
+	defer func() {
+		cancel()
+		a.cancelFunc = nil
+	}()
+
+	runtime.EventsEmit(a.ctx, "copy:start", map[string]any{
+		"total": len(files),
+	})
+	a.notifyStart(history.KindRetry, run.GroupID, c, len(files))
+	start := time.Now()
+	summary := a.runHooked(ctx, history.KindRetry, run.GroupID, c, len(files), func() copier.CopySummary {
+		return a.copyLocked(ctx, c, files, false, a.emitProgress)
+	})
+	a.recordRun(history.KindRetry, run.GroupID, c, start, summary, ctx.Err() != nil)
+	result := a.copyResult(summary)
+	runtime.EventsEmit(a.ctx, "copy:complete", result)
+	return result
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+
+	"copy-image/internal/copier"
+	"copy-image/internal/hooks"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// runHooked copies a batch with copyFiles between the configured hook
+// commands. A failing pre_copy fails the batch; other failures are only
+// logged.
+func (a *App) runHooked(ctx context.Context, kind, groupID string, c *copier.Copier, files int, copyFiles func() copier.CopySummary) copier.CopySummary {
+	summary, err := hooks.New(a.currentConfig()).Run(ctx, hooks.Batch{Kind: kind, GroupID: groupID, Copier: c, Files: files}, copyFiles)
+	a.warnHook(err)
+	return summary
+}
+
+func (a *App) warnHook(err error) {
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "hooks: %v", err)
+	}
+}
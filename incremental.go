@@ -0,0 +1,31 @@
+//go:build windows
+
+package main
+
+import (
+	"copy-image/internal/copier"
+	"copy-image/internal/manifest"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// useManifest attaches the incremental manifest of c's destination when
+// incremental mode is on, and returns the func saving it after the batch.
+// The manifests are shared with the CLI, next to the config file. One that
+// cannot be read only costs a full copy, so it is just logged.
+func (a *App) useManifest(c *copier.Copier) (save func()) {
+	if !c.Config().Incremental {
+		return func() {}
+	}
+	m, err := manifest.Open(a.dataPath(manifest.DirName), c.Destination())
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "incremental: %v", err)
+		return func() {}
+	}
+	c.SetManifest(m)
+	return func() {
+		if err := m.Save(); err != nil {
+			runtime.LogWarningf(a.ctx, "incremental: %v", err)
+		}
+	}
+}
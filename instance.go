@@ -0,0 +1,59 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+)
+
+// instanceID identifies the app for Wails' single-instance lock, so a
+// second launch hands its arguments to the running app instead of opening
+// another window that would save config.yaml over the first one's.
+const instanceID = "com.hoangtran1411.copy-image"
+
+// secondInstance is called by Wails when the app is launched again while
+// it runs, e.g. with "Open with" on a folder: the window comes to the front
+// and the paths it was given are opened like a drop.
+func (a *App) secondInstance(data options.SecondInstanceData) {
+	a.showWindow()
+	if paths := launchPaths(data.Args, data.WorkingDirectory); len(paths) > 0 {
+		a.handleDrop(paths)
+	}
+}
+
+// openArgs makes domReady open the launch paths once, not again when the
+// frontend reloads.
+var openArgs sync.Once
+
+// domReady opens the paths the app was launched with, once the frontend
+// listens for drop:scanned.
+func (a *App) domReady(ctx context.Context) {
+	openArgs.Do(func() {
+		dir, _ := os.Getwd()
+		if paths := launchPaths(os.Args[1:], dir); len(paths) > 0 {
+			a.handleDrop(paths)
+		}
+	})
+}
+
+// launchPaths returns the files and folders among args, relative ones
+// resolved against dir. Flags are left out.
+func launchPaths(args []string, dir string) []string {
+	var paths []string
+	for _, arg := range args {
+		if arg == "" || strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if !filepath.IsAbs(arg) && dir != "" {
+			arg = filepath.Join(dir, arg)
+		}
+		paths = append(paths, arg)
+	}
+	return paths
+}
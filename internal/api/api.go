@@ -0,0 +1,535 @@
+// Package api is the REST and gRPC API of `copyimage serve`. It lets
+// another system, such as a DAM, list the copy groups, start and cancel
+// group runs, follow their progress and read the run history, without a
+// remote desktop session on the machine doing the copying.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+)
+
+// Prefix is the path every endpoint is served under.
+const Prefix = "/api/v1"
+
+// maxFinished is how many finished runs are kept for GET /runs; older
+// ones are still in the history.
+const maxFinished = 50
+
+// Errors returned by the Server methods, wrapped with the group or run
+// they are about.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrRunning  = errors.New("already running")
+)
+
+// Run states.
+const (
+	StatusRunning   = "running"
+	StatusCompleted = "completed" // every file was copied or skipped
+	StatusFailed    = "failed"    // files failed, or the group could not run
+	StatusCancelled = "cancelled"
+)
+
+// Progress is the latest file done in a run.
+type Progress struct {
+	Destination string `json:"destination"`
+	Current     int    `json:"current"`
+	Total       int    `json:"total"`
+	File        string `json:"file"`
+	Status      string `json:"status"` // success, skipped or failed
+}
+
+// RunFunc copies group with cfg to its destinations, calling progress as
+// each file is done. The caller takes the destination locks and records
+// the history, as the CLI does for `groups run`.
+type RunFunc func(ctx context.Context, cfg *config.Config, group config.CopyGroup, progress func(Progress)) (copier.GroupSummary, error)
+
+// Options configure a Server.
+type Options struct {
+	// Token must be sent as "Authorization: Bearer <token>".
+	Token string
+	// Config returns the current config; it is called for every request
+	// so edits to the config file are picked up.
+	Config func() *config.Config
+	// Run copies a group.
+	Run RunFunc
+	// History is the run history database; nil disables /history.
+	History *history.DB
+}
+
+// Server keeps track of the runs started through the API and serves them
+// over HTTP; the gRPC service (NewGRPCServer) shares the same runs. Runs
+// are cancelled when the context given to ListenAndServe is.
+type Server struct {
+	opts Options
+	ctx  context.Context
+
+	mu     sync.Mutex
+	runs   []*run // oldest first
+	nextID int
+}
+
+// New returns a Server for opts. Runs started before ListenAndServe are
+// only cancelled explicitly.
+func New(opts Options) *Server {
+	return &Server{opts: opts, ctx: context.Background()}
+}
+
+// SetContext makes the runs started from now on stop when ctx is
+// cancelled, for callers serving the API themselves.
+func (s *Server) SetContext(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+}
+
+// Handler returns the HTTP handler serving the API under Prefix.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+Prefix+"/health", s.health)
+	mux.Handle("GET "+Prefix+"/groups", s.auth(s.listGroups))
+	mux.Handle("POST "+Prefix+"/groups/{id}/run", s.auth(s.startRun))
+	mux.Handle("GET "+Prefix+"/runs", s.auth(s.listRuns))
+	mux.Handle("GET "+Prefix+"/runs/{id}", s.auth(s.getRun))
+	mux.Handle("DELETE "+Prefix+"/runs/{id}", s.auth(s.cancelRun))
+	mux.Handle("GET "+Prefix+"/runs/{id}/events", s.streamAuth(s.runEvents))
+	mux.Handle("GET "+Prefix+"/history", s.auth(s.listHistory))
+	mux.Handle("GET "+Prefix+"/history/{id}", s.auth(s.getHistory))
+	return mux
+}
+
+// ListenAndServe serves the API on addr (e.g. ":8080") until ctx is
+// cancelled, then stops the runs in progress and returns nil.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to serve the API: %w", err)
+	}
+	s.SetContext(ctx)
+	srv := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	s.Wait()
+	return nil
+}
+
+// auth rejects requests without the token in an Authorization header.
+func (s *Server) auth(next http.HandlerFunc) http.Handler {
+	return s.requireToken(next, false)
+}
+
+// streamAuth is auth for the event stream, which also takes the token as
+// ?access_token=: browsers cannot set headers on an EventSource. No other
+// endpoint does, as URLs end up in the logs of proxies and servers.
+func (s *Server) streamAuth(next http.HandlerFunc) http.Handler {
+	return s.requireToken(next, true)
+}
+
+func (s *Server) requireToken(next http.HandlerFunc, inQuery bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch {
+		case ok:
+		case inQuery:
+			token = r.URL.Query().Get("access_token")
+		default:
+			token = ""
+		}
+		if s.opts.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="copy-image"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		next(w, r)
+	})
+}
+
+func (s *Server) health(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Group is a copy group as listed by GET /groups.
+type Group struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Source       string        `json:"source"`
+	Enabled      bool          `json:"enabled"`
+	Schedule     string        `json:"schedule,omitempty"`
+	Destinations []Destination `json:"destinations"`
+	Running      bool          `json:"running"`
+}
+
+// Destination is one destination of a Group.
+type Destination struct {
+	ID      string `json:"id"`
+	Path    string `json:"path"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (s *Server) listGroups(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.Groups())
+}
+
+// Groups returns the copy groups of the current config.
+func (s *Server) Groups() []Group {
+	cfg := s.opts.Config()
+	groups := make([]Group, 0, len(cfg.Groups))
+	for _, g := range cfg.Groups {
+		group := Group{ID: g.ID, Name: g.Name, Source: g.Source, Enabled: g.Enabled, Schedule: g.Schedule, Running: s.running(g.ID) != nil}
+		for _, d := range g.Destinations {
+			group.Destinations = append(group.Destinations, Destination{ID: d.ID, Path: d.Path, Enabled: d.Enabled})
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// runRequest is the optional body of POST /groups/{id}/run.
+type runRequest struct {
+	DryRun bool `json:"dryRun"`
+}
+
+func (s *Server) startRun(w http.ResponseWriter, r *http.Request) {
+	var req runRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+	}
+
+	info, err := s.StartRun(r.PathValue("id"), req.DryRun)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/runs/%d", Prefix, info.ID))
+	writeJSON(w, http.StatusAccepted, info)
+}
+
+// StartRun copies the group in the background, as a dry run if asked, and
+// returns the run. A group runs at most once at a time; its destinations
+// are also protected by their session locks.
+func (s *Server) StartRun(groupID string, dryRun bool) (Run, error) {
+	cfg := *s.opts.Config()
+	group := cfg.FindGroup(groupID)
+	if group == nil {
+		return Run{}, fmt.Errorf("group %q %w", groupID, ErrNotFound)
+	}
+	if dryRun {
+		cfg.DryRun = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ru := range s.runs {
+		if info, _ := ru.snapshot(); info.GroupID == group.ID && info.Status == StatusRunning {
+			return Run{}, fmt.Errorf("group %q is %w as run %d", group.ID, ErrRunning, info.ID)
+		}
+	}
+
+	s.nextID++
+	ctx, cancel := context.WithCancel(s.ctx)
+	ru := &run{
+		info:    Run{ID: s.nextID, GroupID: group.ID, Status: StatusRunning, DryRun: cfg.DryRun, Started: time.Now()},
+		cancel:  cancel,
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	s.runs = append(s.runs, ru)
+	s.prune()
+
+	go func() {
+		defer close(ru.done)
+		defer cancel()
+		summary, err := s.opts.Run(ctx, &cfg, *group, func(p Progress) {
+			ru.update(func(info *Run) { info.Progress = &p })
+		})
+		ru.finish(ctx, summary, err)
+	}()
+	info, _ := ru.snapshot()
+	return info, nil
+}
+
+// prune forgets the oldest finished runs beyond maxFinished. s.mu is held.
+func (s *Server) prune() {
+	finished := 0
+	for i := len(s.runs) - 1; i >= 0; i-- {
+		if info, _ := s.runs[i].snapshot(); info.Status == StatusRunning {
+			continue
+		}
+		if finished++; finished > maxFinished {
+			s.runs = append(s.runs[:i], s.runs[i+1:]...)
+		}
+	}
+}
+
+// running returns the run of groupID in progress, or nil.
+func (s *Server) running(groupID string) *run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ru := range s.runs {
+		if info, _ := ru.snapshot(); info.GroupID == groupID && info.Status == StatusRunning {
+			return ru
+		}
+	}
+	return nil
+}
+
+// find returns the run with id.
+func (s *Server) find(id int) (*run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ru := range s.runs {
+		if ru.info.ID == id {
+			return ru, nil
+		}
+	}
+	return nil, fmt.Errorf("run %d %w", id, ErrNotFound)
+}
+
+// runID returns the run ID in the request path, or writes a 404.
+func runID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("run %q %w", r.PathValue("id"), ErrNotFound))
+		return 0, false
+	}
+	return id, true
+}
+
+// Wait blocks until every run has ended.
+func (s *Server) Wait() {
+	s.mu.Lock()
+	runs := append([]*run(nil), s.runs...)
+	s.mu.Unlock()
+	for _, ru := range runs {
+		<-ru.done
+	}
+}
+
+func (s *Server) listRuns(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.Runs())
+}
+
+// Runs returns the runs started since the server started, newest first.
+func (s *Server) Runs() []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := make([]Run, 0, len(s.runs))
+	for i := len(s.runs) - 1; i >= 0; i-- {
+		info, _ := s.runs[i].snapshot()
+		runs = append(runs, info)
+	}
+	return runs
+}
+
+func (s *Server) getRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := runID(w, r)
+	if !ok {
+		return
+	}
+	info, err := s.GetRun(id)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// GetRun returns the run with id as it is now.
+func (s *Server) GetRun(id int) (Run, error) {
+	ru, err := s.find(id)
+	if err != nil {
+		return Run{}, err
+	}
+	info, _ := ru.snapshot()
+	return info, nil
+}
+
+func (s *Server) cancelRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := runID(w, r)
+	if !ok {
+		return
+	}
+	info, err := s.CancelRun(r.Context(), id)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// CancelRun stops a run: files being copied are finished, no new ones
+// are started. It returns once the run has ended, or ctx is done.
+func (s *Server) CancelRun(ctx context.Context, id int) (Run, error) {
+	ru, err := s.find(id)
+	if err != nil {
+		return Run{}, err
+	}
+	ru.cancel()
+	select {
+	case <-ru.done:
+	case <-ctx.Done():
+		return Run{}, ctx.Err()
+	}
+	info, _ := ru.snapshot()
+	return info, nil
+}
+
+// WatchRun calls send with the run now and each time it changes, the last
+// time once it has ended. Changes are not queued, so a slow receiver sees
+// the latest state rather than every file. It returns when the run has
+// ended, ctx is done or send fails.
+func (s *Server) WatchRun(ctx context.Context, id int, send func(Run) error) error {
+	ru, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	for {
+		info, changed := ru.snapshot()
+		if err := send(info); err != nil {
+			return err
+		}
+		if info.Status != StatusRunning {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// runEvents streams the run as server-sent events: a "progress" event
+// with the run each time it changes, then a "done" event when it ends.
+func (s *Server) runEvents(w http.ResponseWriter, r *http.Request) {
+	id, ok := runID(w, r)
+	if !ok {
+		return
+	}
+	if _, err := s.find(id); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	_ = s.WatchRun(r.Context(), id, func(info Run) error {
+		event := "progress"
+		if info.Status != StatusRunning {
+			event = "done"
+		}
+		data, err := json.Marshal(info)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+}
+
+func (s *Server) listHistory(w http.ResponseWriter, r *http.Request) {
+	if s.opts.History == nil {
+		writeError(w, http.StatusNotFound, errors.New("no run history"))
+		return
+	}
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q", v))
+			return
+		}
+		limit = n
+	}
+	runs, err := s.opts.History.List(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if runs == nil {
+		runs = []history.Run{}
+	}
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func (s *Server) getHistory(w http.ResponseWriter, r *http.Request) {
+	if s.opts.History == nil {
+		writeError(w, http.StatusNotFound, errors.New("no run history"))
+		return
+	}
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("run %q %w", r.PathValue("id"), ErrNotFound))
+		return
+	}
+	run, err := s.opts.History.Get(id)
+	switch {
+	case errors.Is(err, history.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		// Runs recorded before history kept its snapshots redacted may
+		// still hold passwords.
+		if run.Config != nil {
+			run.Config = run.Config.Redacted()
+		}
+		writeJSON(w, http.StatusOK, run)
+	}
+}
+
+// statusOf returns the HTTP status for an error of the Server methods.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrRunning):
+		return http.StatusConflict
+	case errors.Is(err, context.Canceled):
+		return http.StatusRequestTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
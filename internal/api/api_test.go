@@ -0,0 +1,276 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+)
+
+const testToken = "t0ken"
+
+func testConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Groups = []config.CopyGroup{{
+		ID: "cards", Name: "Camera cards", Source: "/media/card", Enabled: true,
+		Destinations: []config.Destination{{ID: "nas", Path: "/nas/photos", Enabled: true}},
+	}}
+	return cfg
+}
+
+// newTestServer returns a server whose runs call run.
+func newTestServer(t *testing.T, run RunFunc) (*httptest.Server, *history.DB) {
+	db := history.Open(filepath.Join(t.TempDir(), history.FileName))
+	s := New(Options{Token: testToken, Config: testConfig, Run: run, History: db})
+	srv := httptest.NewServer(s.Handler())
+	t.Cleanup(func() {
+		srv.Close()
+		s.Wait()
+	})
+	return srv, db
+}
+
+func request(t *testing.T, srv *httptest.Server, method, path string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+Prefix+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: invalid JSON: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAuth(t *testing.T) {
+	srv, _ := newTestServer(t, nil)
+	for _, header := range []string{"", "Bearer wrong", testToken} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+Prefix+"/groups", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: expected 401, got %d", header, resp.StatusCode)
+		}
+	}
+
+	// The health check needs no token.
+	resp, err := srv.Client().Get(srv.URL + Prefix + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the health check to answer 200, got %d", resp.StatusCode)
+	}
+
+	// A query token is accepted for EventSource clients, on the event
+	// stream only.
+	for path, want := range map[string]int{"/groups": http.StatusUnauthorized, "/runs/99/events": http.StatusNotFound} {
+		resp, err = srv.Client().Get(srv.URL + Prefix + path + "?access_token=" + testToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s with a query token: expected %d, got %d", path, want, resp.StatusCode)
+		}
+	}
+}
+
+func TestListGroups(t *testing.T) {
+	srv, _ := newTestServer(t, nil)
+	var groups []Group
+	if code := request(t, srv, http.MethodGet, "/groups", &groups); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if len(groups) != 1 || groups[0].ID != "cards" || len(groups[0].Destinations) != 1 || groups[0].Destinations[0].Path != "/nas/photos" {
+		t.Errorf("Unexpected groups: %+v", groups)
+	}
+}
+
+func TestRunLifecycle(t *testing.T) {
+	proceed := make(chan struct{})
+	srv, _ := newTestServer(t, func(ctx context.Context, cfg *config.Config, group config.CopyGroup, progress func(Progress)) (copier.GroupSummary, error) {
+		progress(Progress{Destination: "/nas/photos", Current: 1, Total: 2, File: "a.jpg", Status: "success"})
+		<-proceed
+		progress(Progress{Destination: "/nas/photos", Current: 2, Total: 2, File: "b.jpg", Status: "failed"})
+		return copier.GroupSummary{GroupID: group.ID, Destinations: []copier.DestinationSummary{{
+			DestinationID: "nas", Path: "/nas/photos",
+			Summary: copier.CopySummary{TotalFiles: 2, Successful: 1, Failed: 1, FailedFiles: []copier.FailedFile{{Path: "/cards/b.jpg", Err: errors.New("disk full")}}},
+		}}}, nil
+	})
+
+	if code := request(t, srv, http.MethodPost, "/groups/missing/run", nil); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown group, got %d", code)
+	}
+	var started Run
+	if code := request(t, srv, http.MethodPost, "/groups/cards/run", &started); code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d", code)
+	}
+	if started.Status != StatusRunning || started.GroupID != "cards" {
+		t.Errorf("Unexpected run: %+v", started)
+	}
+	if code := request(t, srv, http.MethodPost, "/groups/cards/run", nil); code != http.StatusConflict {
+		t.Errorf("Expected 409 for a group already running, got %d", code)
+	}
+
+	// Follow the run as server-sent events.
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s/runs/%d/events", srv.URL, Prefix, started.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected an event stream, got %q", ct)
+	}
+	events := readEvents(t, resp.Body, proceed)
+	last := events[len(events)-1]
+	if last.name != "done" {
+		t.Fatalf("Expected the stream to end with done, got %+v", events)
+	}
+	if last.run.Status != StatusFailed || len(last.run.Destinations) != 1 || last.run.Destinations[0].Failed != 1 || last.run.Finished == nil {
+		t.Errorf("Unexpected final run: %+v", last.run)
+	}
+
+	var runs []Run
+	request(t, srv, http.MethodGet, "/runs", &runs)
+	if len(runs) != 1 || runs[0].Status != StatusFailed || runs[0].Progress == nil || runs[0].Progress.File != "b.jpg" {
+		t.Errorf("Unexpected runs: %+v", runs)
+	}
+}
+
+type sseEvent struct {
+	name string
+	run  Run
+}
+
+// readEvents reads events until done, letting the run proceed once its
+// first progress event was seen.
+func readEvents(t *testing.T, r io.Reader, proceed chan struct{}) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var name string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			var e sseEvent
+			e.name = name
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e.run); err != nil {
+				t.Fatalf("Invalid event data %q: %v", line, err)
+			}
+			events = append(events, e)
+			if e.run.Progress != nil && e.run.Progress.Current == 1 && proceed != nil {
+				close(proceed)
+				proceed = nil
+			}
+			if name == "done" {
+				return events
+			}
+		}
+	}
+	t.Fatalf("Stream ended without done: %+v", events)
+	return nil
+}
+
+func TestCancelRun(t *testing.T) {
+	srv, _ := newTestServer(t, func(ctx context.Context, cfg *config.Config, group config.CopyGroup, progress func(Progress)) (copier.GroupSummary, error) {
+		if !cfg.DryRun {
+			t.Error("Expected the run to be a dry run")
+		}
+		<-ctx.Done()
+		return copier.GroupSummary{GroupID: group.ID}, nil
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+Prefix+"/groups/cards/run", strings.NewReader(`{"dryRun": true}`))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var started Run
+	_ = json.NewDecoder(resp.Body).Decode(&started)
+	_ = resp.Body.Close()
+	if !started.DryRun {
+		t.Errorf("Expected a dry run, got %+v", started)
+	}
+
+	var cancelled Run
+	if code := request(t, srv, http.MethodDelete, fmt.Sprintf("/runs/%d", started.ID), &cancelled); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if cancelled.Status != StatusCancelled {
+		t.Errorf("Expected the run to be cancelled, got %+v", cancelled)
+	}
+	if code := request(t, srv, http.MethodGet, "/runs/99", nil); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown run, got %d", code)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	srv, db := newTestServer(t, nil)
+	var runs []history.Run
+	if code := request(t, srv, http.MethodGet, "/history", &runs); code != http.StatusOK || len(runs) != 0 {
+		t.Fatalf("Expected an empty history, got %d %+v", code, runs)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = "/media/card"
+	cfg.Destination = "/nas/photos"
+	if err := db.Add(history.NewRun(history.KindAPI, "cards", copier.New(cfg), time.Now(), copier.CopySummary{TotalFiles: 3, Successful: 3})); err != nil {
+		t.Fatal(err)
+	}
+	if code := request(t, srv, http.MethodGet, "/history?limit=5", &runs); code != http.StatusOK || len(runs) != 1 {
+		t.Fatalf("Expected one run, got %d %+v", code, runs)
+	}
+	var run history.Run
+	if code := request(t, srv, http.MethodGet, fmt.Sprintf("/history/%d", runs[0].ID), &run); code != http.StatusOK || run.Kind != history.KindAPI {
+		t.Errorf("Unexpected run: %d %+v", code, run)
+	}
+	// A run recorded with its secrets is answered without them.
+	cfg.SMB = &config.SMBOptions{Username: "copy", Password: "hunter2"}
+	if err := db.Add(&history.Run{Kind: history.KindAPI, Destination: cfg.Destination, Config: cfg}); err != nil {
+		t.Fatal(err)
+	}
+	run = history.Run{}
+	if code := request(t, srv, http.MethodGet, "/history/2", &run); code != http.StatusOK || run.Config == nil || run.Config.SMB.Password != "" {
+		t.Errorf("Expected the run's config without its password, got %d %+v", code, run.Config)
+	}
+	if code := request(t, srv, http.MethodGet, "/history/12345", nil); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown run, got %d", code)
+	}
+	if code := request(t, srv, http.MethodGet, "/history?limit=x", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid limit, got %d", code)
+	}
+}
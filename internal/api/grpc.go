@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"copy-image/internal/history"
+	pb "copy-image/proto/copyimage/v1"
+)
+
+// NewGRPCServer returns a gRPC server with the CopyService backed by s
+// registered, checking the token of every call.
+func (s *Server) NewGRPCServer() *grpc.Server {
+	g := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := s.checkToken(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.checkToken(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	pb.RegisterCopyServiceServer(g, &copyService{s: s})
+	return g
+}
+
+// StartGRPC listens on addr (e.g. ":9090") and serves the CopyService in
+// the background until ctx is cancelled. Runs it starts are cancelled with
+// ctx. A port already in use is reported right away.
+func (s *Server) StartGRPC(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to serve gRPC: %w", err)
+	}
+	s.SetContext(ctx)
+	g := s.NewGRPCServer()
+	go func() { _ = g.Serve(ln) }()
+	go func() {
+		<-ctx.Done()
+		g.GracefulStop()
+	}()
+	return nil
+}
+
+func (s *Server) checkToken(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && s.opts.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid token")
+}
+
+// copyService implements pb.CopyServiceServer with the Server methods.
+type copyService struct {
+	pb.UnimplementedCopyServiceServer
+	s *Server
+}
+
+func (c *copyService) ListGroups(context.Context, *pb.ListGroupsRequest) (*pb.ListGroupsResponse, error) {
+	resp := &pb.ListGroupsResponse{}
+	for _, g := range c.s.Groups() {
+		group := &pb.Group{Id: g.ID, Name: g.Name, Source: g.Source, Enabled: g.Enabled, Schedule: g.Schedule, Running: g.Running}
+		for _, d := range g.Destinations {
+			group.Destinations = append(group.Destinations, &pb.Destination{Id: d.ID, Path: d.Path, Enabled: d.Enabled})
+		}
+		resp.Groups = append(resp.Groups, group)
+	}
+	return resp, nil
+}
+
+func (c *copyService) StartCopy(_ context.Context, req *pb.StartCopyRequest) (*pb.Run, error) {
+	run, err := c.s.StartRun(req.GetGroupId(), req.GetDryRun())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return runProto(run), nil
+}
+
+func (c *copyService) CancelCopy(ctx context.Context, req *pb.CancelCopyRequest) (*pb.Run, error) {
+	run, err := c.s.CancelRun(ctx, int(req.GetRunId()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return runProto(run), nil
+}
+
+func (c *copyService) GetRun(_ context.Context, req *pb.GetRunRequest) (*pb.Run, error) {
+	run, err := c.s.GetRun(int(req.GetRunId()))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return runProto(run), nil
+}
+
+func (c *copyService) StreamProgress(req *pb.StreamProgressRequest, stream grpc.ServerStreamingServer[pb.Run]) error {
+	err := c.s.WatchRun(stream.Context(), int(req.GetRunId()), func(run Run) error {
+		return stream.Send(runProto(run))
+	})
+	if err != nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+func (c *copyService) GetHistory(_ context.Context, req *pb.GetHistoryRequest) (*pb.GetHistoryResponse, error) {
+	if c.s.opts.History == nil {
+		return nil, status.Error(codes.NotFound, "no run history")
+	}
+	limit := int(req.GetLimit())
+	if limit <= 0 {
+		limit = 20
+	}
+	runs, err := c.s.opts.History.List(limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &pb.GetHistoryResponse{}
+	for _, r := range runs {
+		resp.Runs = append(resp.Runs, historyProto(r))
+	}
+	return resp, nil
+}
+
+// grpcError returns the gRPC status for an error of the Server methods.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrRunning):
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	return status.FromContextError(err).Err()
+}
+
+var runStatuses = map[string]pb.RunStatus{
+	StatusRunning:   pb.RunStatus_RUN_STATUS_RUNNING,
+	StatusCompleted: pb.RunStatus_RUN_STATUS_COMPLETED,
+	StatusFailed:    pb.RunStatus_RUN_STATUS_FAILED,
+	StatusCancelled: pb.RunStatus_RUN_STATUS_CANCELLED,
+}
+
+func runProto(r Run) *pb.Run {
+	run := &pb.Run{
+		Id:      int64(r.ID),
+		GroupId: r.GroupID,
+		Status:  runStatuses[r.Status],
+		DryRun:  r.DryRun,
+		Started: timestamppb.New(r.Started),
+		Error:   r.Error,
+	}
+	if r.Finished != nil {
+		run.Finished = timestamppb.New(*r.Finished)
+	}
+	if p := r.Progress; p != nil {
+		run.Progress = &pb.Progress{Destination: p.Destination, Current: int32(p.Current), Total: int32(p.Total), File: p.File, Status: p.Status}
+	}
+	for _, d := range r.Destinations {
+		run.Destinations = append(run.Destinations, &pb.DestinationResult{
+			Id:          d.ID,
+			Path:        d.Path,
+			TotalFiles:  int32(d.TotalFiles),
+			Successful:  int32(d.Successful),
+			Failed:      int32(d.Failed),
+			Skipped:     int32(d.Skipped),
+			FailedFiles: d.FailedFiles,
+			Duration:    durationpb.New(seconds(d.Duration)),
+		})
+	}
+	return run
+}
+
+func historyProto(r history.Run) *pb.HistoryRun {
+	return &pb.HistoryRun{
+		Id:          r.ID,
+		Kind:        r.Kind,
+		GroupId:     r.GroupID,
+		Sources:     r.Sources,
+		Destination: r.Destination,
+		Start:       timestamppb.New(r.Start),
+		Duration:    durationpb.New(seconds(r.Duration)),
+		DryRun:      r.DryRun,
+		Cancelled:   r.Cancelled,
+		TotalFiles:  int32(r.TotalFiles),
+		Successful:  int32(r.Successful),
+		Failed:      int32(r.Failed),
+		Skipped:     int32(r.Skipped),
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
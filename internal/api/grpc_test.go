@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	pb "copy-image/proto/copyimage/v1"
+)
+
+// newGRPCClient serves s over an in-memory connection and returns a
+// client for it.
+func newGRPCClient(t *testing.T, s *Server) pb.CopyServiceClient {
+	ln := bufconn.Listen(1 << 20)
+	g := s.NewGRPCServer()
+	go func() { _ = g.Serve(ln) }()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		g.Stop()
+		s.Wait()
+	})
+	return pb.NewCopyServiceClient(conn)
+}
+
+func authorized() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+testToken)
+}
+
+func TestGRPCAuth(t *testing.T) {
+	client := newGRPCClient(t, New(Options{Token: testToken, Config: testConfig}))
+	_, err := client.ListGroups(context.Background(), &pb.ListGroupsRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without a token, got %v", err)
+	}
+	stream, err := client.StreamProgress(context.Background(), &pb.StreamProgressRequest{RunId: 1})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for a stream without a token, got %v", err)
+	}
+
+	resp, err := client.ListGroups(authorized(), &pb.ListGroupsRequest{})
+	if err != nil {
+		t.Fatalf("ListGroups failed: %v", err)
+	}
+	if len(resp.Groups) != 1 || resp.Groups[0].Id != "cards" || resp.Groups[0].Destinations[0].Path != "/nas/photos" {
+		t.Errorf("Unexpected groups: %v", resp.Groups)
+	}
+}
+
+func TestGRPCRun(t *testing.T) {
+	proceed := make(chan struct{})
+	s := New(Options{Token: testToken, Config: testConfig, Run: func(ctx context.Context, cfg *config.Config, group config.CopyGroup, progress func(Progress)) (copier.GroupSummary, error) {
+		progress(Progress{Destination: "/nas/photos", Current: 1, Total: 1, File: "a.jpg", Status: "success"})
+		<-proceed
+		return copier.GroupSummary{GroupID: group.ID, Destinations: []copier.DestinationSummary{{
+			DestinationID: "nas", Path: "/nas/photos",
+			Summary: copier.CopySummary{TotalFiles: 1, Successful: 1, Duration: 2 * time.Second},
+		}}}, nil
+	}})
+	client := newGRPCClient(t, s)
+
+	if _, err := client.StartCopy(authorized(), &pb.StartCopyRequest{GroupId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown group, got %v", err)
+	}
+	run, err := client.StartCopy(authorized(), &pb.StartCopyRequest{GroupId: "cards"})
+	if err != nil {
+		t.Fatalf("StartCopy failed: %v", err)
+	}
+	if run.Status != pb.RunStatus_RUN_STATUS_RUNNING {
+		t.Errorf("Expected a running run, got %v", run)
+	}
+	if _, err := client.StartCopy(authorized(), &pb.StartCopyRequest{GroupId: "cards"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Expected AlreadyExists while the group runs, got %v", err)
+	}
+
+	stream, err := client.StreamProgress(authorized(), &pb.StreamProgressRequest{RunId: run.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last *pb.Run
+	release := proceed
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if r.Progress != nil && release != nil {
+			close(release)
+			release = nil
+		}
+		last = r
+	}
+	if last == nil || last.Status != pb.RunStatus_RUN_STATUS_COMPLETED || last.Finished == nil {
+		t.Fatalf("Expected the stream to end with the completed run, got %v", last)
+	}
+	if d := last.Destinations; len(d) != 1 || d[0].Successful != 1 || d[0].Duration.AsDuration() != 2*time.Second {
+		t.Errorf("Unexpected results: %v", d)
+	}
+
+	if _, err := client.GetRun(authorized(), &pb.GetRunRequest{RunId: 42}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown run, got %v", err)
+	}
+}
+
+func TestGRPCCancelAndHistory(t *testing.T) {
+	db := history.Open(filepath.Join(t.TempDir(), history.FileName))
+	s := New(Options{Token: testToken, Config: testConfig, History: db, Run: func(ctx context.Context, cfg *config.Config, group config.CopyGroup, progress func(Progress)) (copier.GroupSummary, error) {
+		<-ctx.Done()
+		return copier.GroupSummary{GroupID: group.ID}, nil
+	}})
+	client := newGRPCClient(t, s)
+
+	run, err := client.StartCopy(authorized(), &pb.StartCopyRequest{GroupId: "cards", DryRun: true})
+	if err != nil {
+		t.Fatalf("StartCopy failed: %v", err)
+	}
+	cancelled, err := client.CancelCopy(authorized(), &pb.CancelCopyRequest{RunId: run.Id})
+	if err != nil {
+		t.Fatalf("CancelCopy failed: %v", err)
+	}
+	if cancelled.Status != pb.RunStatus_RUN_STATUS_CANCELLED || !cancelled.DryRun {
+		t.Errorf("Expected a cancelled dry run, got %v", cancelled)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = "/media/card"
+	cfg.Destination = "/nas/photos"
+	if err := db.Add(history.NewRun(history.KindAPI, "cards", copier.New(cfg), time.Now(), copier.CopySummary{TotalFiles: 2, Successful: 1, Failed: 1, FailedFiles: []copier.FailedFile{{Path: "/cards/b.jpg", Err: errors.New("disk full")}}})); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.GetHistory(authorized(), &pb.GetHistoryRequest{})
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(resp.Runs) != 1 || resp.Runs[0].Kind != history.KindAPI || resp.Runs[0].Failed != 1 {
+		t.Errorf("Unexpected history: %v", resp.Runs)
+	}
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"copy-image/internal/copier"
+)
+
+// Run is a group run started through the API, as returned by the /runs
+// endpoints and streamed by /runs/{id}/events.
+type Run struct {
+	ID       int        `json:"id"`
+	GroupID  string     `json:"groupId"`
+	Status   string     `json:"status"`
+	DryRun   bool       `json:"dryRun"`
+	Started  time.Time  `json:"started"`
+	Finished *time.Time `json:"finished,omitempty"`
+	// Progress is the latest file done; nil until the first one.
+	Progress *Progress `json:"progress,omitempty"`
+	// Destinations are the results once the run has ended.
+	Destinations []DestinationResult `json:"destinations,omitempty"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// DestinationResult is the outcome of a run at one destination, shaped
+// like the CLI's JSON summary.
+type DestinationResult struct {
+	ID          string   `json:"id"`
+	Path        string   `json:"path"`
+	TotalFiles  int      `json:"totalFiles"`
+	Successful  int      `json:"successful"`
+	Failed      int      `json:"failed"`
+	Skipped     int      `json:"skipped"`
+	FailedFiles []string `json:"failedFiles"`
+	Duration    float64  `json:"duration"` // in seconds
+}
+
+// run tracks a Run in progress. Watchers wait on changed, which is closed
+// and replaced on every update.
+type run struct {
+	mu      sync.Mutex
+	info    Run
+	changed chan struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{} // closed once the run has ended
+}
+
+// snapshot returns the run as it is now and a channel closed on its next
+// change.
+func (r *run) snapshot() (Run, <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.info, r.changed
+}
+
+func (r *run) update(fn func(info *Run)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fn(&r.info)
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// finish records how the run ended.
+func (r *run) finish(ctx context.Context, summary copier.GroupSummary, err error) {
+	r.update(func(info *Run) {
+		now := time.Now()
+		info.Finished = &now
+		for _, d := range summary.Destinations {
+			info.Destinations = append(info.Destinations, DestinationResult{
+				ID:          d.DestinationID,
+				Path:        d.Path,
+				TotalFiles:  d.Summary.TotalFiles,
+				Successful:  d.Summary.Successful,
+				Failed:      d.Summary.Failed,
+				Skipped:     d.Summary.Skipped,
+				FailedFiles: d.Summary.FailureLines(),
+				Duration:    d.Summary.Duration.Seconds(),
+			})
+		}
+		if err == nil {
+			err = summary.Err
+		}
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			info.Status = StatusCancelled
+		case err != nil:
+			info.Status = StatusFailed
+			info.Error = err.Error()
+		case summary.Failed():
+			info.Status = StatusFailed
+		default:
+			info.Status = StatusCompleted
+		}
+	})
+}
@@ -0,0 +1,91 @@
+// Package compress writes copies compressed with gzip or zstd
+// (Config.Compress) and reads them back, so that backups of uncompressed
+// files such as TIFFs take a fraction of the space.
+package compress
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+)
+
+// New returns the processor compressing files with algo and adding its
+// extension to their names.
+func New(algo string) processor.FileProcessor {
+	return compressor{algo}
+}
+
+type compressor struct {
+	algo string
+}
+
+func (compressor) Name() string { return "compress" }
+
+func (c compressor) Rename(dest string) string {
+	return dest + config.CompressedExtension(c.algo)
+}
+
+// Transform compresses r as it is read. Closing the returned reader stops
+// the compression.
+func (c compressor) Transform(ctx context.Context, f processor.File, r io.Reader) (io.Reader, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		w, err := NewWriter(c.algo, pw)
+		if err == nil {
+			_, err = io.Copy(w, r)
+			if cerr := w.Close(); err == nil {
+				err = cerr
+			}
+		}
+		_ = pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// NewWriter returns a writer compressing to w with algo. Closing it
+// flushes the compressed data but does not close w.
+func NewWriter(algo string, w io.Writer) (io.WriteCloser, error) {
+	if algo == config.CompressGzip {
+		return gzip.NewWriter(w), nil
+	}
+	// One goroutine per file: the copy workers already run in parallel,
+	// and the output is the same on every run, so interrupted uploads
+	// can resume.
+	return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+}
+
+// NewReader returns the decompressed content of r, which was compressed
+// with algo. Closing it closes r.
+func NewReader(algo string, r io.ReadCloser) (io.ReadCloser, error) {
+	if algo == config.CompressGzip {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			_ = r.Close()
+			return nil, err
+		}
+		return readCloser{zr, func() { _ = zr.Close() }, r}, nil
+	}
+	zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+	return readCloser{zr, zr.Close, r}, nil
+}
+
+// readCloser releases a decompressor, then closes the file it reads.
+type readCloser struct {
+	io.Reader
+	release func()
+	file    io.Closer
+}
+
+func (r readCloser) Close() error {
+	r.release()
+	return r.file.Close()
+}
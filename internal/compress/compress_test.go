@@ -0,0 +1,60 @@
+package compress
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+)
+
+func TestRoundTrip(t *testing.T) {
+	content := strings.Repeat("uncompressed tiff strip ", 1000)
+	for _, tt := range []struct {
+		algo string
+		ext  string
+	}{
+		{config.CompressGzip, ".gz"},
+		{config.CompressZstd, ".zst"},
+	} {
+		t.Run(tt.algo, func(t *testing.T) {
+			p := New(tt.algo)
+			if got := p.(processor.Renamer).Rename("a.tif"); got != "a.tif"+tt.ext {
+				t.Errorf("Expected a.tif%s, got %s", tt.ext, got)
+			}
+			r, err := p.(processor.Transformer).Transform(context.Background(), processor.File{}, strings.NewReader(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			compressed, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(compressed) >= len(content) {
+				t.Errorf("Expected compressed data, got %d bytes", len(compressed))
+			}
+
+			dr, err := NewReader(tt.algo, io.NopCloser(bytes.NewReader(compressed)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = dr.Close() }()
+			got, err := io.ReadAll(dr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != content {
+				t.Errorf("Expected the content back, got %d bytes", len(got))
+			}
+		})
+	}
+}
+
+func TestNewReaderRejectsPlainFiles(t *testing.T) {
+	if _, err := NewReader(config.CompressGzip, io.NopCloser(strings.NewReader("plain"))); err == nil {
+		t.Error("Expected an error for a file that is not gzip")
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Themes of the desktop app.
+const (
+	ThemeDark   = "dark"
+	ThemeLight  = "light"
+	ThemeSystem = "system" // follows the Windows setting
+)
+
+// Update channels of the desktop app.
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta" // prereleases as well
+)
+
+// How often the desktop app looks for updates.
+const (
+	UpdateDaily  = "daily"
+	UpdateWeekly = "weekly"
+)
+
+// AppSettings are preferences of the desktop app, which the CLI ignores.
+// The language is Config.Language, shared by both.
+type AppSettings struct {
+	// Theme is "dark" (the default), "light" or "system".
+	Theme string `yaml:"theme,omitempty" json:"theme,omitempty" toml:"theme,omitempty"`
+	// StartMinimized opens the window minimized, e.g. when the app starts
+	// with Windows only to run schedules and card imports.
+	StartMinimized bool `yaml:"start_minimized,omitempty" json:"startMinimized,omitempty" toml:"start_minimized,omitempty"`
+	// CloseToTray hides the window to the system tray when it is closed,
+	// so watch mode and schedules keep running; Quit in the tray menu ends
+	// the app.
+	CloseToTray bool `yaml:"close_to_tray,omitempty" json:"closeToTray,omitempty" toml:"close_to_tray,omitempty"`
+	// Window is where the window was when it was last closed, restored at
+	// the next launch.
+	Window *WindowState `yaml:"window,omitempty" json:"window,omitempty" toml:"window,omitempty"`
+	// CheckUpdates looks for a new release at launch; unset means yes.
+	CheckUpdates *bool `yaml:"check_updates,omitempty" json:"checkUpdates,omitempty" toml:"check_updates,omitempty"`
+	// UpdateInterval is how often the app looks for a new release while it
+	// runs, "daily" (the default) or "weekly".
+	UpdateInterval string `yaml:"update_interval,omitempty" json:"updateInterval,omitempty" toml:"update_interval,omitempty"`
+	// UpdateChannel is "stable" (the default), offering releases only, or
+	// "beta", offering prereleases as well.
+	UpdateChannel string `yaml:"update_channel,omitempty" json:"updateChannel,omitempty" toml:"update_channel,omitempty"`
+	// SkipVersion is a release the user chose not to be offered; a later
+	// one is offered again.
+	SkipVersion string `yaml:"skip_version,omitempty" json:"skipVersion,omitempty" toml:"skip_version,omitempty"`
+	// ConfirmOverwrite asks before a copy that overwrites existing files;
+	// unset means yes.
+	ConfirmOverwrite *bool `yaml:"confirm_overwrite,omitempty" json:"confirmOverwrite,omitempty" toml:"confirm_overwrite,omitempty"`
+}
+
+// WindowState is the size and position of the window, in pixels. X and Y
+// are relative to the screen the window is on.
+type WindowState struct {
+	X         int  `yaml:"x" json:"x" toml:"x"`
+	Y         int  `yaml:"y" json:"y" toml:"y"`
+	Width     int  `yaml:"width" json:"width" toml:"width"`
+	Height    int  `yaml:"height" json:"height" toml:"height"`
+	Maximized bool `yaml:"maximized,omitempty" json:"maximized,omitempty" toml:"maximized,omitempty"`
+}
+
+// ThemeName returns the theme, dark by default. s may be nil.
+func (s *AppSettings) ThemeName() string {
+	if s == nil || s.Theme == "" {
+		return ThemeDark
+	}
+	return s.Theme
+}
+
+// StartsMinimized reports whether the window opens minimized. s may be nil.
+func (s *AppSettings) StartsMinimized() bool {
+	return s != nil && s.StartMinimized
+}
+
+// ClosesToTray reports whether closing the window hides it to the tray. s
+// may be nil.
+func (s *AppSettings) ClosesToTray() bool {
+	return s != nil && s.CloseToTray
+}
+
+// ChecksUpdates reports whether the app looks for updates at launch. s
+// may be nil.
+func (s *AppSettings) ChecksUpdates() bool {
+	return s == nil || s.CheckUpdates == nil || *s.CheckUpdates
+}
+
+// UpdateEvery returns how often to look for updates, daily by default. s
+// may be nil.
+func (s *AppSettings) UpdateEvery() time.Duration {
+	if s != nil && s.UpdateInterval == UpdateWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Channel returns the update channel, stable by default. s may be nil.
+func (s *AppSettings) Channel() string {
+	if s == nil || s.UpdateChannel == "" {
+		return ChannelStable
+	}
+	return s.UpdateChannel
+}
+
+// SkippedVersion returns the release the user chose not to be offered, if
+// any. s may be nil.
+func (s *AppSettings) SkippedVersion() string {
+	if s == nil {
+		return ""
+	}
+	return s.SkipVersion
+}
+
+// ConfirmsOverwrite reports whether the app asks before overwriting. s may
+// be nil.
+func (s *AppSettings) ConfirmsOverwrite() bool {
+	return s == nil || s.ConfirmOverwrite == nil || *s.ConfirmOverwrite
+}
+
+// checkApp returns the problems of the app settings s, which may be nil.
+func checkApp(s *AppSettings) []Problem {
+	var problems []Problem
+	switch s.ThemeName() {
+	case ThemeDark, ThemeLight, ThemeSystem:
+	default:
+		problems = append(problems, Problem{Field: "app.theme", Message: fmt.Sprintf("unknown theme %q", s.Theme), Hint: `use "dark", "light" or "system"`})
+	}
+	if s != nil {
+		switch s.UpdateInterval {
+		case "", UpdateDaily, UpdateWeekly:
+		default:
+			problems = append(problems, Problem{Field: "app.update_interval", Message: fmt.Sprintf("unknown update interval %q", s.UpdateInterval), Hint: `use "daily" or "weekly"`})
+		}
+	}
+	switch s.Channel() {
+	case ChannelStable, ChannelBeta:
+	default:
+		problems = append(problems, Problem{Field: "app.update_channel", Message: fmt.Sprintf("unknown update channel %q", s.UpdateChannel), Hint: `use "stable" or "beta"`})
+	}
+	return problems
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CardProfile recognizes a memory card or removable drive when it is
+// inserted and names the group that imports it. The group's destinations
+// and settings are used, with the card as its source.
+type CardProfile struct {
+	Name string `yaml:"name" json:"name" toml:"name"`
+	// Label matches the volume label, as a case-insensitive glob such as
+	// "EOS_DIGITAL" or "NIKON*"; empty matches any label.
+	Label string `yaml:"label,omitempty" json:"label,omitempty" toml:"label,omitempty"`
+	// DCIM requires a DCIM folder, which every camera creates, so cards
+	// match whatever they are named without matching other USB drives.
+	DCIM bool `yaml:"dcim,omitempty" json:"dcim,omitempty" toml:"dcim,omitempty"`
+	// Folder is the folder on the card to copy; empty copies DCIM when it
+	// is required and the whole card otherwise.
+	Folder string `yaml:"folder,omitempty" json:"folder,omitempty" toml:"folder,omitempty"`
+	// Group is the ID of the group to run.
+	Group string `yaml:"group" json:"group" toml:"group"`
+	// AutoStart imports as soon as the card is inserted instead of asking.
+	AutoStart bool `yaml:"auto_start,omitempty" json:"autoStart,omitempty" toml:"auto_start,omitempty"`
+}
+
+// Matches reports whether a drive with the volume label, holding a DCIM
+// folder or not, is this kind of card.
+func (p CardProfile) Matches(label string, hasDCIM bool) bool {
+	if p.DCIM && !hasDCIM {
+		return false
+	}
+	if p.Label == "" {
+		return true
+	}
+	ok, _ := path.Match(strings.ToLower(p.Label), strings.ToLower(label))
+	return ok
+}
+
+// Source returns the folder to copy from a card mounted at root.
+func (p CardProfile) Source(root string) string {
+	folder := p.Folder
+	if folder == "" && p.DCIM {
+		folder = "DCIM"
+	}
+	return filepath.Join(root, filepath.FromSlash(folder))
+}
+
+// MatchCard returns the first card profile matching a drive, or nil.
+func (c *Config) MatchCard(label string, hasDCIM bool) *CardProfile {
+	for i := range c.Cards {
+		if c.Cards[i].Matches(label, hasDCIM) {
+			return &c.Cards[i]
+		}
+	}
+	return nil
+}
+
+// FindCard returns the card profile with the given name, or nil.
+func (c *Config) FindCard(name string) *CardProfile {
+	for i := range c.Cards {
+		if c.Cards[i].Name == name {
+			return &c.Cards[i]
+		}
+	}
+	return nil
+}
+
+// CardGroup returns the group a card profile runs, with the card mounted
+// at root as its source.
+func (c *Config) CardGroup(p CardProfile, root string) (CopyGroup, error) {
+	group := c.FindGroup(p.Group)
+	if group == nil {
+		return CopyGroup{}, fmt.Errorf("group %q not found", p.Group)
+	}
+	g := *group
+	g.Source = p.Source(root)
+	return g, nil
+}
+
+// validateCards checks that each profile can tell cards apart and names
+// an existing group.
+func (c *Config) validateCards() []Problem {
+	names := make([]string, 0, len(c.Groups))
+	for _, g := range c.Groups {
+		names = append(names, g.ID)
+	}
+
+	var problems []Problem
+	for i, p := range c.Cards {
+		field := fmt.Sprintf("cards[%d]", i)
+		if p.Label == "" && !p.DCIM {
+			problems = append(problems, Problem{Field: field, Message: "matches every drive", Hint: "set label, or dcim: true to match camera cards"})
+		}
+		if _, err := path.Match(p.Label, ""); err != nil {
+			problems = append(problems, Problem{Field: field + ".label", Message: fmt.Sprintf("invalid pattern %q: %v", p.Label, err)})
+		}
+		if p.Folder != "" && !filepath.IsLocal(filepath.FromSlash(p.Folder)) {
+			problems = append(problems, Problem{Field: field + ".folder", Message: fmt.Sprintf("%q is not a folder on the card", p.Folder), Hint: `use a relative path such as "DCIM" or "PRIVATE/M4ROOT"`})
+		}
+		if c.FindGroup(p.Group) == nil {
+			prob := unknownKey(field+".group", p.Group, names)
+			prob.Message = fmt.Sprintf("%q is not a group ID", p.Group)
+			if !strings.HasPrefix(prob.Hint, "did you mean") {
+				prob.Hint = "valid group IDs: " + strings.Join(names, ", ")
+			}
+			problems = append(problems, prob)
+		}
+	}
+	return problems
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCardProfileMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile CardProfile
+		label   string
+		dcim    bool
+		want    bool
+	}{
+		{"label glob", CardProfile{Label: "nikon*"}, "NIKON D850", false, true},
+		{"other label", CardProfile{Label: "EOS_DIGITAL"}, "BACKUP", true, false},
+		{"any camera card", CardProfile{DCIM: true}, "Untitled", true, true},
+		{"no DCIM", CardProfile{DCIM: true}, "Untitled", false, false},
+		{"label and DCIM", CardProfile{Label: "EOS_DIGITAL", DCIM: true}, "EOS_DIGITAL", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.Matches(tt.label, tt.dcim); got != tt.want {
+				t.Errorf("Matches(%q, %v) = %v, want %v", tt.label, tt.dcim, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCardGroup(t *testing.T) {
+	cfg := &Config{
+		Groups: []CopyGroup{{ID: "canon", Source: "E:/DCIM", Destinations: []Destination{{Path: "/photos"}}}},
+		Cards: []CardProfile{
+			{Name: "Sony", Label: "SONY*", Folder: "PRIVATE/M4ROOT", Group: "canon"},
+			{Name: "Any camera", DCIM: true, Group: "canon"},
+		},
+	}
+	p := cfg.MatchCard("Untitled", true)
+	if p == nil || p.Name != "Any camera" {
+		t.Fatalf("MatchCard() = %+v, want the DCIM profile", p)
+	}
+	g, err := cfg.CardGroup(*p, "/media/card")
+	if err != nil || g.Source != filepath.Join("/media/card", "DCIM") || len(g.Destinations) != 1 {
+		t.Errorf("CardGroup() = %+v, %v", g, err)
+	}
+	if got := cfg.Cards[0].Source("/media/card"); got != filepath.Join("/media/card", "PRIVATE", "M4ROOT") {
+		t.Errorf("Source() = %q", got)
+	}
+	if cfg.Groups[0].Source != "E:/DCIM" {
+		t.Error("CardGroup should not change the configured group")
+	}
+	if p := cfg.FindCard("Sony"); p == nil || p.Folder != "PRIVATE/M4ROOT" {
+		t.Errorf("FindCard(Sony) = %+v", p)
+	}
+	if cfg.FindCard("Nikon") != nil {
+		t.Error("FindCard should return nil for an unknown profile")
+	}
+}
+
+func TestValidateCards(t *testing.T) {
+	cfg := &Config{
+		Source:      "/in",
+		Destination: "/out",
+		Groups:      []CopyGroup{{ID: "canon", Source: "E:/DCIM", Destinations: []Destination{{Path: "/photos"}}}},
+		Cards: []CardProfile{
+			{Name: "ok", DCIM: true, Group: "canon"},
+			{Name: "everything", Group: "canon"},
+			{Name: "typo", Label: "EOS[", Folder: "../up", Group: "cannon"},
+		},
+	}
+	problems := Problems(cfg.Validate())
+	fields := make([]string, len(problems))
+	for i, p := range problems {
+		fields[i] = p.Field
+	}
+	want := []string{"cards[1]", "cards[2].label", "cards[2].folder", "cards[2].group"}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected problems for %v, got %v", want, problems)
+	}
+}
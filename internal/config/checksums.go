@@ -0,0 +1,55 @@
+package config
+
+import "fmt"
+
+// Checksum manifest algorithms (Config.Checksums).
+const (
+	ChecksumSHA256 = "sha256"
+	ChecksumMD5    = "md5"
+)
+
+// Where checksum manifests are written (Config.ChecksumsIn).
+const (
+	ChecksumsInDestination = "destination"
+	ChecksumsInSource      = "source"
+	ChecksumsInBoth        = "both"
+)
+
+// ChecksumFile returns the name of the manifest written for an algorithm,
+// e.g. checksums.sha256.
+func ChecksumFile(algo string) string {
+	return "checksums." + algo
+}
+
+// IsChecksumFile reports whether name is a manifest the copier writes, so
+// scans and verifies leave it out.
+func IsChecksumFile(name string) bool {
+	return name == ChecksumFile(ChecksumSHA256) || name == ChecksumFile(ChecksumMD5)
+}
+
+// ChecksumTargets reports whether manifests go to the destination and to
+// the source.
+func (c *Config) ChecksumTargets() (destination, source bool) {
+	switch c.ChecksumsIn {
+	case ChecksumsInSource:
+		return false, true
+	case ChecksumsInBoth:
+		return true, true
+	}
+	return true, false
+}
+
+func (c *Config) validateChecksums() []Problem {
+	var problems []Problem
+	switch c.Checksums {
+	case "", ChecksumSHA256, ChecksumMD5:
+	default:
+		problems = append(problems, Problem{Field: "checksums", Message: fmt.Sprintf("unknown algorithm %q", c.Checksums), Hint: `use "sha256" or "md5"`})
+	}
+	switch c.ChecksumsIn {
+	case "", ChecksumsInDestination, ChecksumsInSource, ChecksumsInBoth:
+	default:
+		problems = append(problems, Problem{Field: "checksums_in", Message: fmt.Sprintf("unknown place %q", c.ChecksumsIn), Hint: `use "destination", "source" or "both"`})
+	}
+	return problems
+}
@@ -0,0 +1,28 @@
+package config
+
+import "fmt"
+
+// Compression algorithms for files at rest (Config.Compress).
+const (
+	CompressGzip = "gzip"
+	CompressZstd = "zstd"
+)
+
+// CompressedExtension returns the extension appended to the files
+// compressed with algo, e.g. .zst.
+func CompressedExtension(algo string) string {
+	if algo == CompressGzip {
+		return ".gz"
+	}
+	return ".zst"
+}
+
+// checkCompress checks the algorithm at field, e.g. compress or
+// groups[0].destinations[1].compress.
+func checkCompress(field, algo string) []Problem {
+	switch algo {
+	case "", CompressGzip, CompressZstd:
+		return nil
+	}
+	return []Problem{{Field: field, Message: fmt.Sprintf("unknown algorithm %q", algo), Hint: `use "zstd", or "gzip" for tools without zstd`}}
+}
@@ -1,31 +1,103 @@
 package config
 
 import (
+	"crypto/rand"
 	"fmt"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
-	"gopkg.in/yaml.v3"
+	"copy-image/internal/sanitize"
 )
 
 // Destination represents a single destination with its own settings.
 // Each destination can have independent overwrite settings, allowing
 // fine-grained control over how files are copied to different locations.
 type Destination struct {
-	ID        string `yaml:"id" json:"id"`
-	Path      string `yaml:"path" json:"path"`
-	Overwrite bool   `yaml:"overwrite" json:"overwrite"`
-	Enabled   bool   `yaml:"enabled" json:"enabled"`
+	ID        string `yaml:"id" json:"id" toml:"id"`
+	Path      string `yaml:"path" json:"path" toml:"path"`
+	Overwrite bool   `yaml:"overwrite" json:"overwrite" toml:"overwrite"`
+	Enabled   bool   `yaml:"enabled" json:"enabled" toml:"enabled"`
+
+	// Optional filters replacing the group's for this destination only,
+	// e.g. RAW files to the archive NAS but only JPEGs to a shared drive.
+	Extensions []string `yaml:"extensions,omitempty" json:"extensions,omitempty" toml:"extensions,omitempty"`
+	Exclude    []string `yaml:"exclude,omitempty" json:"exclude,omitempty" toml:"exclude,omitempty"`
+
+	// S3 configures the connection when Path is an s3://bucket/prefix URL.
+	S3 *S3Options `yaml:"s3,omitempty" json:"s3,omitempty" toml:"s3,omitempty"`
+	// SMB connects a \\server\share Path with these credentials (Windows).
+	SMB *SMBOptions `yaml:"smb,omitempty" json:"smb,omitempty" toml:"smb,omitempty"`
+	// FTP configures the connection when Path is an ftp:// URL.
+	FTP *FTPOptions `yaml:"ftp,omitempty" json:"ftp,omitempty" toml:"ftp,omitempty"`
+	// Type is "zip" to write the files into the archive at Path instead
+	// of a folder; Zip may set its password.
+	Type string      `yaml:"type,omitempty" json:"type,omitempty" toml:"type,omitempty"`
+	Zip  *ZipOptions `yaml:"zip,omitempty" json:"zip,omitempty" toml:"zip,omitempty"`
+
+	// Transcode replaces the global transcode rules for this destination,
+	// e.g. JPEGs for a client gallery while the archive gets originals.
+	Transcode []TranscodeRule `yaml:"transcode,omitempty" json:"transcode,omitempty" toml:"transcode,omitempty"`
+	// Transform changes the images written to this destination, such as
+	// downscaled previews.
+	Transform *Transform `yaml:"transform,omitempty" json:"transform,omitempty" toml:"transform,omitempty"`
+	// Compress replaces the global compression for this destination.
+	Compress string `yaml:"compress,omitempty" json:"compress,omitempty" toml:"compress,omitempty"`
+}
+
+// HasFilters reports whether the destination overrides the file filters.
+func (d Destination) HasFilters() bool {
+	return len(d.Extensions) > 0 || len(d.Exclude) > 0
 }
 
 // CopyGroup represents a copy configuration with one source and multiple destinations.
 // This enables the common use case of backing up/distributing files to multiple locations.
 type CopyGroup struct {
-	ID           string        `yaml:"id" json:"id"`
-	Name         string        `yaml:"name" json:"name"`
-	Source       string        `yaml:"source" json:"source"`
-	Destinations []Destination `yaml:"destinations" json:"destinations"`
-	Enabled      bool          `yaml:"enabled" json:"enabled"`
+	ID           string        `yaml:"id" json:"id" toml:"id"`
+	Name         string        `yaml:"name" json:"name" toml:"name"`
+	Source       string        `yaml:"source" json:"source" toml:"source"`
+	Destinations []Destination `yaml:"destinations" json:"destinations" toml:"destinations"`
+	Enabled      bool          `yaml:"enabled" json:"enabled" toml:"enabled"`
+
+	// Optional overrides of the global settings for this group only, e.g.
+	// 2 workers for large videos going to a NAS but 30 for thumbnails going
+	// to an SSD. Unset (zero or nil) values use the global setting.
+	Workers     int      `yaml:"workers,omitempty" json:"workers,omitempty" toml:"workers,omitempty"`
+	MaxRetries  *int     `yaml:"max_retries,omitempty" json:"maxRetries,omitempty" toml:"max_retries,omitempty"`
+	Extensions  []string `yaml:"extensions,omitempty" json:"extensions,omitempty" toml:"extensions,omitempty"`
+	Exclude     []string `yaml:"exclude,omitempty" json:"exclude,omitempty" toml:"exclude,omitempty"`
+	Recursive   *bool    `yaml:"recursive,omitempty" json:"recursive,omitempty" toml:"recursive,omitempty"`
+	Incremental *bool    `yaml:"incremental,omitempty" json:"incremental,omitempty" toml:"incremental,omitempty"`
+
+	// Scheduling: groups run after the groups listed in DependsOn (by ID),
+	// and otherwise higher Priority first. See OrderGroups.
+	Priority  int      `yaml:"priority,omitempty" json:"priority,omitempty" toml:"priority,omitempty"`
+	DependsOn []string `yaml:"depends_on,omitempty" json:"dependsOn,omitempty" toml:"depends_on,omitempty"`
+
+	// Schedule runs the group automatically while the desktop app or
+	// `copyimage schedule run` is running. It is a cron expression
+	// (minute hour day month weekday, e.g. "0 2 * * *") or a shortcut
+	// such as "@daily" or "@every 6h".
+	Schedule string `yaml:"schedule,omitempty" json:"schedule,omitempty" toml:"schedule,omitempty"`
+
+	// S3 and SFTP configure the connection when Source is an s3:// or
+	// sftp:// URL.
+	S3   *S3Options   `yaml:"s3,omitempty" json:"s3,omitempty" toml:"s3,omitempty"`
+	SFTP *SFTPOptions `yaml:"sftp,omitempty" json:"sftp,omitempty" toml:"sftp,omitempty"`
+}
+
+// Profile is a named preset of the single source/destination settings,
+// e.g. "SD card → NAS". Applying a profile replaces those settings as a
+// whole so switching setups never leaves a stale source behind.
+type Profile struct {
+	Source      string   `yaml:"source" json:"source" toml:"source"`
+	Sources     []string `yaml:"sources,omitempty" json:"sources,omitempty" toml:"sources,omitempty"`
+	Destination string   `yaml:"destination" json:"destination" toml:"destination"`
+	Overwrite   bool     `yaml:"overwrite" json:"overwrite" toml:"overwrite"`
+	Extensions  []string `yaml:"extensions,omitempty" json:"extensions,omitempty" toml:"extensions,omitempty"`
 }
 
 // Config represents the application configuration.
@@ -33,18 +105,131 @@ type CopyGroup struct {
 // JSON tags are added for Wails frontend binding.
 type Config struct {
 	// Legacy single source/destination (for backward compatibility with CLI mode)
-	Source      string `yaml:"source" json:"source"`
-	Destination string `yaml:"destination" json:"destination"`
+	Source      string `yaml:"source" json:"source" toml:"source"`
+	Destination string `yaml:"destination" json:"destination" toml:"destination"`
+	// S3 configures the connection when Destination is an s3:// URL.
+	S3 *S3Options `yaml:"s3,omitempty" json:"s3,omitempty" toml:"s3,omitempty"`
+	// SMB connects a \\server\share Destination with these credentials.
+	SMB *SMBOptions `yaml:"smb,omitempty" json:"smb,omitempty" toml:"smb,omitempty"`
+	// FTP configures the connection when Destination is an ftp:// URL.
+	FTP *FTPOptions `yaml:"ftp,omitempty" json:"ftp,omitempty" toml:"ftp,omitempty"`
+	// DestinationType is "zip" when Destination is a zip archive to write
+	// the files into; Zip may set its password.
+	DestinationType string      `yaml:"destination_type,omitempty" json:"destinationType,omitempty" toml:"destination_type,omitempty"`
+	Zip             *ZipOptions `yaml:"zip,omitempty" json:"zip,omitempty" toml:"zip,omitempty"`
+	// SourceS3 and SourceSFTP configure the connection when the sources
+	// are s3:// or sftp:// URLs.
+	SourceS3   *S3Options   `yaml:"source_s3,omitempty" json:"sourceS3,omitempty" toml:"source_s3,omitempty"`
+	SourceSFTP *SFTPOptions `yaml:"source_sftp,omitempty" json:"sourceSFTP,omitempty" toml:"source_sftp,omitempty"`
+
+	// Sources are extra source folders or glob patterns (e.g. D:\Camera\2024-*)
+	// merged with Source, so one run can pull from several card dumps.
+	Sources []string `yaml:"sources,omitempty" json:"sources,omitempty" toml:"sources,omitempty"`
+
+	// URLsFrom is a text file of http(s) image URLs, one per line, to
+	// download instead of scanning the sources. Only used without groups.
+	URLsFrom string `yaml:"urls_from,omitempty" json:"urlsFrom,omitempty" toml:"urls_from,omitempty"`
+
+	// Named presets for Source/Destination, selected with -profile or in the app
+	Profiles      map[string]Profile `yaml:"profiles,omitempty" json:"profiles,omitempty" toml:"profiles,omitempty"`
+	ActiveProfile string             `yaml:"active_profile,omitempty" json:"activeProfile,omitempty" toml:"active_profile,omitempty"`
 
 	// Copy Groups - allows one source to copy to multiple destinations
-	Groups []CopyGroup `yaml:"groups,omitempty" json:"groups"`
+	Groups []CopyGroup `yaml:"groups,omitempty" json:"groups" toml:"groups,omitempty"`
+
+	// Cards start a group when a matching memory card or removable drive
+	// is inserted while the desktop app is open.
+	Cards []CardProfile `yaml:"cards,omitempty" json:"cards,omitempty" toml:"cards,omitempty"`
 
 	// Global settings applied to all copy operations
-	Workers    int      `yaml:"workers" json:"workers"`
-	Overwrite  bool     `yaml:"overwrite" json:"overwrite"`
-	Extensions []string `yaml:"extensions" json:"extensions"`
-	MaxRetries int      `yaml:"max_retries" json:"maxRetries"`
-	DryRun     bool     `yaml:"dry_run" json:"dryRun"`
+	Workers    int      `yaml:"workers" json:"workers" toml:"workers"`
+	Overwrite  bool     `yaml:"overwrite" json:"overwrite" toml:"overwrite"`
+	Extensions []string `yaml:"extensions" json:"extensions" toml:"extensions"`
+	MaxRetries int      `yaml:"max_retries" json:"maxRetries" toml:"max_retries"`
+	DryRun     bool     `yaml:"dry_run" json:"dryRun" toml:"dry_run"`
+	// Order is the order files are handed to the workers: "name",
+	// "size-asc", "size-desc" (largest first), "mtime" (oldest first) or
+	// "random". Empty keeps the order of the scan.
+	Order string `yaml:"order,omitempty" json:"order,omitempty" toml:"order,omitempty"`
+
+	// Recursive includes files in subfolders of the source, recreating the
+	// folder structure under the destination.
+	Recursive bool `yaml:"recursive" json:"recursive" toml:"recursive"`
+	// Incremental copies only files that are new or changed since they
+	// were last copied to the destination, according to a manifest kept
+	// next to the config file rather than by looking at the destination.
+	Incremental bool `yaml:"incremental,omitempty" json:"incremental,omitempty" toml:"incremental,omitempty"`
+	// StableChecks makes each copy wait until its source file has stopped
+	// changing (same size over this many checks a second apart) so files a
+	// camera is still writing are not copied truncated. 0 disables it.
+	StableChecks int `yaml:"stable_checks,omitempty" json:"stableChecks,omitempty" toml:"stable_checks,omitempty"`
+	// OfflineWait is how many seconds a batch pauses when its destination
+	// goes offline, such as a share behind a VPN that dropped, waiting for
+	// it to come back before failing the files left; 0 means 300, and a
+	// negative number fails them right away.
+	OfflineWait int `yaml:"offline_wait,omitempty" json:"offlineWait,omitempty" toml:"offline_wait,omitempty"`
+	// Exclude skips files and folders whose name or path relative to the
+	// source matches one of these glob patterns, e.g. "*.tmp" or ".thumbnails".
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty" toml:"exclude,omitempty"`
+	// UseTrash sends the files the app deletes (e.g. by undo) to the
+	// recycle bin instead of deleting them for good.
+	UseTrash bool `yaml:"use_trash,omitempty" json:"useTrash,omitempty" toml:"use_trash,omitempty"`
+	// FilenamePolicy adapts destination names: "nfc" normalizes Unicode
+	// (Macs write decomposed names) and "windows" also replaces characters
+	// NTFS and SMB shares reject. Empty or "none" keeps names as they are.
+	FilenamePolicy string `yaml:"filename_policy,omitempty" json:"filenamePolicy,omitempty" toml:"filename_policy,omitempty"`
+	// Checksums writes a manifest of the copied files after each batch,
+	// "sha256" or "md5", in the format of sha256sum and md5sum so archival
+	// tools can verify the delivery on their own. Empty writes none.
+	Checksums string `yaml:"checksums,omitempty" json:"checksums,omitempty" toml:"checksums,omitempty"`
+	// ChecksumsIn is where the manifest goes: "destination" (the default),
+	// "source", to keep it with the card, or "both".
+	ChecksumsIn string `yaml:"checksums_in,omitempty" json:"checksumsIn,omitempty" toml:"checksums_in,omitempty"`
+	// MHL writes a Media Hash List (MHL v1, MD5) into the destination for
+	// each batch, as offload tools do on set.
+	MHL bool `yaml:"mhl,omitempty" json:"mhl,omitempty" toml:"mhl,omitempty"`
+
+	// Webhooks are notified when batches start, complete or fail.
+	Webhooks []Webhook `yaml:"webhooks,omitempty" json:"webhooks,omitempty" toml:"webhooks,omitempty"`
+	// Email mails the summary and failed files of each batch.
+	Email *Email `yaml:"email,omitempty" json:"email,omitempty" toml:"email,omitempty"`
+	// Hooks run commands before and after each batch.
+	Hooks *Hooks `yaml:"hooks,omitempty" json:"hooks,omitempty" toml:"hooks,omitempty"`
+	// ValidateImages rejects corrupt JPEG, PNG, GIF, WebP, BMP and TIFF
+	// files before they are copied: "header" decodes their headers and
+	// looks for truncation, "full" decodes them. Empty copies files as
+	// they are.
+	ValidateImages string `yaml:"validate_images,omitempty" json:"validateImages,omitempty" toml:"validate_images,omitempty"`
+	// Transcode converts files to another format while they are copied.
+	Transcode []TranscodeRule `yaml:"transcode,omitempty" json:"transcode,omitempty" toml:"transcode,omitempty"`
+	// Transform changes the images written; a group's destination may
+	// replace it.
+	Transform *Transform `yaml:"transform,omitempty" json:"transform,omitempty" toml:"transform,omitempty"`
+	// Compress writes every file compressed, "zstd" (adding .zst to its
+	// name) or "gzip" (adding .gz), to save space on backup targets of
+	// TIFFs and other uncompressed files. Verify decompresses them to
+	// compare them with their sources. Empty copies files as they are.
+	Compress string `yaml:"compress,omitempty" json:"compress,omitempty" toml:"compress,omitempty"`
+	// Processors inspect, transform or check every file copied.
+	Processors []Processor `yaml:"processors,omitempty" json:"processors,omitempty" toml:"processors,omitempty"`
+	// HTTP sets the proxy and certificate authorities for update checks
+	// and downloads.
+	HTTP *HTTPOptions `yaml:"http,omitempty" json:"http,omitempty" toml:"http,omitempty"`
+	// NoUpdateCheck stops the CLI from looking for a newer release when it
+	// runs, like its -no-update-check flag.
+	NoUpdateCheck bool `yaml:"no_update_check,omitempty" json:"noUpdateCheck,omitempty" toml:"no_update_check,omitempty"`
+	// UpdateFeed replaces the public GitHub repository as the source of
+	// updates.
+	UpdateFeed *UpdateFeed `yaml:"update_feed,omitempty" json:"updateFeed,omitempty" toml:"update_feed,omitempty"`
+
+	// Language of CLI and GUI messages ("en", "vi"); empty follows the OS.
+	Language string `yaml:"language" json:"language" toml:"language"`
+	// App holds the desktop app's preferences.
+	App *AppSettings `yaml:"app,omitempty" json:"app,omitempty" toml:"app,omitempty"`
+
+	// format is the file format the config was loaded from, so saving
+	// writes it back the same way.
+	format Format
 }
 
 // DefaultConfig returns a config with sensible default values.
@@ -62,26 +247,49 @@ func DefaultConfig() *Config {
 	}
 }
 
-// LoadFromFile loads configuration from a YAML file.
-// Returns an error if the file cannot be read or parsed.
+// LoadFromFile loads configuration from a YAML, JSON or TOML file.
+// The format follows the extension (.yaml/.yml, .json, .toml) and is
+// sniffed from the content otherwise, since deployment tooling often
+// generates JSON. Returns an error if the file cannot be read or parsed.
+// Unknown keys are reported as a *ValidationError; the config holding the
+// recognized settings is returned with it so the app can still show them.
 func LoadFromFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	format := FormatFromPath(path)
+	if format == "" {
+		format = sniffFormat(data)
+	}
+
 	config := DefaultConfig()
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if err := config.decode(data, format); err != nil {
+		return nil, fmt.Errorf("failed to parse %s config file: %w", format, err)
+	}
+	config.format = format
+	config.EnsureIDs()
+
+	// Decoders silently ignore unknown keys, so a typo like "worker: 10"
+	// would otherwise fall back to the default without any warning.
+	if problems := unknownKeys(data, format); len(problems) > 0 {
+		return config, fmt.Errorf("invalid config file %s: %w", path, &ValidationError{Problems: problems})
 	}
 
 	return config, nil
 }
 
-// SaveToFile persists the configuration to a YAML file.
+// SaveToFile persists the configuration to a file.
 // This allows user preferences to survive application restarts.
+// The format follows the extension, falling back to the format the config
+// was loaded in (YAML for a new config) so a load/save round-trips.
 func (c *Config) SaveToFile(path string) error {
-	data, err := yaml.Marshal(c)
+	format := FormatFromPath(path)
+	if format == "" {
+		format = c.format
+	}
+	data, err := c.encode(format)
 	if err != nil {
 		return fmt.Errorf("failed to serialize config: %w", err)
 	}
@@ -97,17 +305,96 @@ func (c *Config) SaveToFile(path string) error {
 
 // Validate checks if the configuration is valid for copy operations.
 // It also normalizes values to ensure they're within acceptable ranges.
+// Every problem found is reported at once in a *ValidationError so users
+// can fix their config in one pass.
 func (c *Config) Validate() error {
+	var problems []Problem
+
 	// In legacy mode, source and destination are required
 	if len(c.Groups) == 0 {
-		if c.Source == "" {
-			return fmt.Errorf("source path is required")
+		if len(c.SourcePatterns()) == 0 && c.URLsFrom == "" {
+			problems = append(problems, Problem{Field: "source", Message: "is required", Hint: "set source or sources in the config, or pass -source"})
 		}
 		if c.Destination == "" {
-			return fmt.Errorf("destination path is required")
+			problems = append(problems, Problem{Field: "destination", Message: "is required", Hint: "set destination in the config, or pass -dest"})
+		}
+		problems = append(problems, checkS3("destination", c.Destination, c.S3)...)
+		problems = append(problems, checkSMB("destination", c.Destination, c.SMB)...)
+		problems = append(problems, checkFTP("destination", c.Destination, c.FTP)...)
+		problems = append(problems, checkZip("destination", "destination_type", c.Destination, c.DestinationType, c.Zip)...)
+		problems = append(problems, checkSources("source", c.SourcePatterns(), c.SourceS3, c.SourceSFTP)...)
+		if scheme := sourceOnly(c.Destination); scheme != "" {
+			problems = append(problems, Problem{Field: "destination", Message: scheme + " is only supported as a source", Hint: "use a local folder, share, s3:// or ftp:// destination"})
+		}
+		for _, source := range c.SourcePatterns() {
+			if c.Destination != "" && filepath.Clean(source) == filepath.Clean(c.Destination) {
+				problems = append(problems, Problem{Field: "destination", Message: "is the same folder as the source", Hint: "choose a different destination folder"})
+			}
+		}
+	}
+
+	for i, ext := range c.Extensions {
+		if p, ok := checkExtension(fmt.Sprintf("extensions[%d]", i), ext); !ok {
+			problems = append(problems, p)
 		}
 	}
 
+	for i, pattern := range c.Exclude {
+		if p, ok := checkPattern(fmt.Sprintf("exclude[%d]", i), pattern); !ok {
+			problems = append(problems, p)
+		}
+	}
+
+	if _, err := sanitize.Parse(c.FilenamePolicy); err != nil {
+		problems = append(problems, Problem{Field: "filename_policy", Message: err.Error(), Hint: `use "nfc", or "windows" for NTFS and SMB destinations`})
+	}
+	problems = append(problems, c.validateChecksums()...)
+
+	problems = append(problems, c.validateIDs()...)
+
+	for i, g := range c.Groups {
+		if !g.Enabled {
+			continue
+		}
+		problems = append(problems, g.validateOverrides(fmt.Sprintf("groups[%d]", i))...)
+		if g.Workers > 50 {
+			c.Groups[i].Workers = 50
+		}
+		if strings.TrimSpace(g.Source) == "" {
+			problems = append(problems, Problem{Field: fmt.Sprintf("groups[%d].source", i), Message: "is required", Hint: fmt.Sprintf("set the folder group %q copies from, or disable it", g.Name)})
+		}
+		problems = append(problems, checkSources(fmt.Sprintf("groups[%d].source", i), []string{g.Source}, g.S3, g.SFTP)...)
+		for j, d := range g.Destinations {
+			if d.Enabled && strings.TrimSpace(d.Path) == "" {
+				problems = append(problems, Problem{Field: fmt.Sprintf("groups[%d].destinations[%d].path", i, j), Message: "is required", Hint: "set the destination folder, or disable the destination"})
+			}
+			if d.Enabled {
+				field := fmt.Sprintf("groups[%d].destinations[%d].path", i, j)
+				problems = append(problems, checkS3(field, d.Path, d.S3)...)
+				problems = append(problems, checkSMB(field, d.Path, d.SMB)...)
+				problems = append(problems, checkFTP(field, d.Path, d.FTP)...)
+				problems = append(problems, checkZip(field, fmt.Sprintf("groups[%d].destinations[%d].type", i, j), d.Path, d.Type, d.Zip)...)
+				if scheme := sourceOnly(d.Path); scheme != "" {
+					problems = append(problems, Problem{Field: field, Message: scheme + " is only supported as a source", Hint: "use a local folder, share, s3:// or ftp:// destination"})
+				}
+			}
+		}
+	}
+
+	problems = append(problems, c.validateDependencies()...)
+	problems = append(problems, c.validateCards()...)
+	problems = append(problems, c.validateWebhooks()...)
+	problems = append(problems, c.validateEmail()...)
+	problems = append(problems, c.validateHooks()...)
+	problems = append(problems, c.validateProcessors()...)
+	problems = append(problems, checkTranscode("transcode", c.Transcode)...)
+	problems = append(problems, checkTransform("transform", c.Transform)...)
+	problems = append(problems, checkCompress("compress", c.Compress)...)
+	problems = append(problems, checkOrder("order", c.Order)...)
+	problems = append(problems, checkApp(c.App)...)
+	problems = append(problems, checkHTTPOptions(c.HTTP)...)
+	problems = append(problems, checkUpdateFeed(c.UpdateFeed)...)
+
 	// Clamp workers to a reasonable range.
 	// Too few workers underutilizes resources; too many causes contention.
 	if c.Workers < 1 {
@@ -121,10 +408,28 @@ func (c *Config) Validate() error {
 	if c.MaxRetries < 0 {
 		c.MaxRetries = 0
 	}
+	if c.StableChecks < 0 {
+		c.StableChecks = 0
+	}
 
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
 	return nil
 }
 
+// SourcePatterns returns Source followed by Sources, skipping empty entries.
+// Entries may be directories or glob patterns.
+func (c *Config) SourcePatterns() []string {
+	var patterns []string
+	for _, p := range append([]string{c.Source}, c.Sources...) {
+		if strings.TrimSpace(p) != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
 // HasExtensionFilter checks if extension filtering is enabled.
 // When enabled, only files with matching extensions will be copied.
 func (c *Config) HasExtensionFilter() bool {
@@ -155,6 +460,25 @@ func (c *Config) IsExtensionAllowed(ext string) bool {
 	return false
 }
 
+// IsExcluded reports whether the file or folder at relPath (relative to the
+// source) matches an Exclude pattern. Patterns are matched case-insensitively
+// against both the name and the slash-separated relative path, so "*.tmp"
+// excludes temp files anywhere and "raw/*" only those under raw.
+func (c *Config) IsExcluded(relPath string) bool {
+	rel := strings.ToLower(filepath.ToSlash(relPath))
+	name := path.Base(rel)
+	for _, pattern := range c.Exclude {
+		pattern = strings.ToLower(filepath.ToSlash(strings.TrimSpace(pattern)))
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // GetEnabledGroups returns only the groups that are enabled.
 // This is used when processing copy operations to skip disabled groups.
 func (c *Config) GetEnabledGroups() []CopyGroup {
@@ -167,10 +491,87 @@ func (c *Config) GetEnabledGroups() []CopyGroup {
 	return enabled
 }
 
-// AddGroup adds a new copy group to the configuration.
-// The group ID should be unique to allow proper identification.
-func (c *Config) AddGroup(group CopyGroup) {
+// AddGroup adds a new copy group to the configuration and returns it as
+// stored. Empty group and destination IDs are filled with generated UUIDs;
+// an ID already used by another group is an error.
+func (c *Config) AddGroup(group CopyGroup) (CopyGroup, error) {
+	if group.ID == "" {
+		group.ID = NewID()
+	}
+	if c.FindGroup(group.ID) != nil {
+		return group, fmt.Errorf("group ID %q is already used", group.ID)
+	}
+	group.Destinations = withDestinationIDs(append([]Destination(nil), group.Destinations...))
 	c.Groups = append(c.Groups, group)
+	return group, nil
+}
+
+// UpdateGroup replaces the group that has the same ID as group, keeping its
+// position so the run order of equal-priority groups does not change.
+// Empty destination IDs are filled with generated UUIDs.
+func (c *Config) UpdateGroup(group CopyGroup) error {
+	existing := c.FindGroup(group.ID)
+	if existing == nil {
+		return fmt.Errorf("group %q not found", group.ID)
+	}
+	group.Destinations = withDestinationIDs(append([]Destination(nil), group.Destinations...))
+	*existing = group
+	return nil
+}
+
+// DuplicateGroup adds a copy of the group with the given ID right after it
+// and returns the copy, with new group and destination IDs and " (copy)"
+// added to its name. The copy is disabled and has no schedule, so it does
+// not copy into the same destinations until it has been edited.
+func (c *Config) DuplicateGroup(groupID string) (CopyGroup, error) {
+	i := slices.IndexFunc(c.Groups, func(g CopyGroup) bool { return g.ID == groupID })
+	if i < 0 {
+		return CopyGroup{}, fmt.Errorf("group %q not found", groupID)
+	}
+	dup := c.Groups[i]
+	dup.ID = NewID()
+	dup.Name += " (copy)"
+	dup.Enabled = false
+	dup.Schedule = ""
+	dup.Extensions = slices.Clone(dup.Extensions)
+	dup.Exclude = slices.Clone(dup.Exclude)
+	dup.DependsOn = slices.Clone(dup.DependsOn)
+	dup.Destinations = slices.Clone(dup.Destinations)
+	for j := range dup.Destinations {
+		dup.Destinations[j].ID = NewID()
+	}
+	c.Groups = slices.Insert(c.Groups, i+1, dup)
+	return dup, nil
+}
+
+// EnsureIDs fills empty group and destination IDs with generated UUIDs,
+// so groups written by hand can still be addressed by the CLI and the GUI.
+func (c *Config) EnsureIDs() {
+	for i := range c.Groups {
+		if c.Groups[i].ID == "" {
+			c.Groups[i].ID = NewID()
+		}
+		c.Groups[i].Destinations = withDestinationIDs(c.Groups[i].Destinations)
+	}
+}
+
+// withDestinationIDs fills empty destination IDs in place.
+func withDestinationIDs(dests []Destination) []Destination {
+	for i := range dests {
+		if dests[i].ID == "" {
+			dests[i].ID = NewID()
+		}
+	}
+	return dests
+}
+
+// NewID returns a random (version 4) UUID for a group or destination.
+func NewID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) // never returns an error
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
 }
 
 // RemoveGroup removes a group by its ID.
@@ -195,3 +596,137 @@ func (c *Config) FindGroup(groupID string) *CopyGroup {
 	}
 	return nil
 }
+
+// ForDestination returns a copy of the configuration targeting a single
+// destination of a group, with the group's and destination's overrides
+// applied. The copier
+// works on one source/destination pair, so group runs create one of these
+// per enabled destination.
+func (c *Config) ForDestination(group CopyGroup, dest Destination) *Config {
+	cfg := *c
+	cfg.Groups = nil
+	cfg.Source = group.Source
+	cfg.Sources = nil
+	cfg.URLsFrom = ""
+	cfg.Destination = dest.Path
+	cfg.Overwrite = dest.Overwrite
+	cfg.Extensions = append([]string(nil), c.Extensions...)
+	cfg.Exclude = append([]string(nil), c.Exclude...)
+
+	// Group overrides
+	if group.Workers > 0 {
+		cfg.Workers = group.Workers
+	}
+	if group.MaxRetries != nil {
+		cfg.MaxRetries = *group.MaxRetries
+	}
+	if len(group.Extensions) > 0 {
+		cfg.Extensions = append([]string(nil), group.Extensions...)
+	}
+	if len(group.Exclude) > 0 {
+		cfg.Exclude = append([]string(nil), group.Exclude...)
+	}
+	if group.Recursive != nil {
+		cfg.Recursive = *group.Recursive
+	}
+	if group.Incremental != nil {
+		cfg.Incremental = *group.Incremental
+	}
+	if group.S3 != nil {
+		cfg.SourceS3 = group.S3
+	}
+	if group.SFTP != nil {
+		cfg.SourceSFTP = group.SFTP
+	}
+
+	// Destination overrides
+	if dest.S3 != nil {
+		cfg.S3 = dest.S3
+	}
+	if dest.SMB != nil {
+		cfg.SMB = dest.SMB
+	}
+	if dest.FTP != nil {
+		cfg.FTP = dest.FTP
+	}
+	cfg.DestinationType = dest.Type
+	cfg.Zip = dest.Zip
+	if len(dest.Extensions) > 0 {
+		cfg.Extensions = append([]string(nil), dest.Extensions...)
+	}
+	if len(dest.Exclude) > 0 {
+		cfg.Exclude = append([]string(nil), dest.Exclude...)
+	}
+	if len(dest.Transcode) > 0 {
+		cfg.Transcode = dest.Transcode
+	}
+	if dest.Transform != nil {
+		cfg.Transform = dest.Transform
+	}
+	if dest.Compress != "" {
+		cfg.Compress = dest.Compress
+	}
+	return &cfg
+}
+
+// ProfileNames returns the names of the configured profiles, sorted.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile copies the named profile into the top-level settings and
+// marks it active. Returns an error if no such profile exists.
+func (c *Config) ApplyProfile(name string) error {
+	p, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+
+	c.Source = p.Source
+	c.Sources = append([]string(nil), p.Sources...)
+	c.Destination = p.Destination
+	c.Overwrite = p.Overwrite
+	c.Extensions = append([]string{}, p.Extensions...)
+	c.ActiveProfile = name
+	return nil
+}
+
+// SaveProfile stores the current top-level settings as the named profile,
+// replacing any profile with the same name, and marks it active.
+func (c *Config) SaveProfile(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]Profile)
+	}
+	c.Profiles[name] = Profile{
+		Source:      c.Source,
+		Sources:     append([]string(nil), c.Sources...),
+		Destination: c.Destination,
+		Overwrite:   c.Overwrite,
+		Extensions:  append([]string(nil), c.Extensions...),
+	}
+	c.ActiveProfile = name
+	return nil
+}
+
+// DeleteProfile removes the named profile.
+// Returns true if a profile was removed, false if the name was not found.
+func (c *Config) DeleteProfile(name string) bool {
+	if _, ok := c.Profiles[name]; !ok {
+		return false
+	}
+	delete(c.Profiles, name)
+	if c.ActiveProfile == name {
+		c.ActiveProfile = ""
+	}
+	return true
+}
@@ -137,6 +137,14 @@ func TestValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "extra sources only",
+			config: &Config{
+				Sources:     []string{"/media/card1", "/media/card2"},
+				Destination: "/path/to/dest",
+			},
+			expectError: false,
+		},
 		{
 			name: "workers too low - auto fix",
 			config: &Config{
@@ -469,3 +477,247 @@ func TestDestinationStruct(t *testing.T) {
 		t.Error("Expected Enabled to be true")
 	}
 }
+
+func TestForDestination(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workers = 7
+	cfg.Extensions = []string{".jpg"}
+	group := CopyGroup{
+		ID:     "g1",
+		Source: "/src",
+		Destinations: []Destination{
+			{ID: "d1", Path: "/dst", Overwrite: true, Enabled: true},
+		},
+		Enabled: true,
+	}
+	cfg.AddGroup(group)
+
+	destCfg := cfg.ForDestination(group, group.Destinations[0])
+
+	if destCfg.Source != "/src" || destCfg.Destination != "/dst" {
+		t.Errorf("Expected /src -> /dst, got %s -> %s", destCfg.Source, destCfg.Destination)
+	}
+	if !destCfg.Overwrite {
+		t.Error("Expected destination overwrite setting to apply")
+	}
+	if destCfg.Workers != 7 {
+		t.Errorf("Expected global workers to carry over, got %d", destCfg.Workers)
+	}
+	if len(destCfg.Groups) != 0 {
+		t.Error("Expected per-destination config to have no groups")
+	}
+
+	destCfg.Extensions[0] = ".png"
+	if cfg.Extensions[0] != ".jpg" {
+		t.Error("Modifying the derived config must not change the original")
+	}
+}
+
+func TestSourcePatterns(t *testing.T) {
+	cfg := &Config{Source: "/a", Sources: []string{"", "/b/2024-*", "  "}}
+	got := cfg.SourcePatterns()
+	if len(got) != 2 || got[0] != "/a" || got[1] != "/b/2024-*" {
+		t.Errorf("Unexpected patterns: %v", got)
+	}
+
+	if got := (&Config{}).SourcePatterns(); len(got) != 0 {
+		t.Errorf("Expected no patterns, got %v", got)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Source = "E:\\DCIM"
+	cfg.Destination = "\\\\nas\\inbox"
+	cfg.Extensions = []string{".jpg"}
+	if err := cfg.SaveProfile("card-to-nas"); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+
+	cfg.Source = "\\\\nas\\inbox"
+	cfg.Destination = "F:\\Backup"
+	cfg.Overwrite = true
+	cfg.Extensions = nil
+	if err := cfg.SaveProfile("nas-to-external"); err != nil {
+		t.Fatalf("SaveProfile failed: %v", err)
+	}
+
+	if names := cfg.ProfileNames(); len(names) != 2 || names[0] != "card-to-nas" {
+		t.Errorf("Unexpected profile names: %v", names)
+	}
+
+	if err := cfg.ApplyProfile("card-to-nas"); err != nil {
+		t.Fatalf("ApplyProfile failed: %v", err)
+	}
+	if cfg.Source != "E:\\DCIM" || cfg.Overwrite || len(cfg.Extensions) != 1 || cfg.ActiveProfile != "card-to-nas" {
+		t.Errorf("Profile not applied: %+v", cfg)
+	}
+
+	// Profiles survive a save/load round trip.
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+	loaded, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if loaded.Profiles["nas-to-external"].Destination != "F:\\Backup" {
+		t.Errorf("Profile lost in round trip: %+v", loaded.Profiles)
+	}
+
+	if err := cfg.ApplyProfile("missing"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+	if err := cfg.SaveProfile("  "); err == nil {
+		t.Error("Expected error for empty profile name")
+	}
+	if !cfg.DeleteProfile("card-to-nas") || cfg.ActiveProfile != "" {
+		t.Error("Expected active profile to be deleted")
+	}
+	if cfg.DeleteProfile("card-to-nas") {
+		t.Error("Expected second delete to report false")
+	}
+}
+
+func TestForDestinationGroupOverrides(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workers = 10
+	cfg.MaxRetries = 3
+	cfg.Extensions = []string{".jpg"}
+	cfg.Recursive = true
+
+	retries, recursive, incremental := 0, false, true
+	group := CopyGroup{
+		ID:          "videos",
+		Source:      "/src",
+		Enabled:     true,
+		Workers:     2,
+		MaxRetries:  &retries,
+		Extensions:  []string{".mp4"},
+		Exclude:     []string{"*.tmp"},
+		Recursive:   &recursive,
+		Incremental: &incremental,
+	}
+	destCfg := cfg.ForDestination(group, Destination{Path: "/nas"})
+
+	if destCfg.Workers != 2 || destCfg.MaxRetries != 0 || destCfg.Recursive || !destCfg.Incremental {
+		t.Errorf("Expected group overrides to apply, got workers=%d retries=%d recursive=%v incremental=%v",
+			destCfg.Workers, destCfg.MaxRetries, destCfg.Recursive, destCfg.Incremental)
+	}
+	if len(destCfg.Extensions) != 1 || destCfg.Extensions[0] != ".mp4" {
+		t.Errorf("Expected group extensions, got %v", destCfg.Extensions)
+	}
+	if !destCfg.IsExcluded("clip.tmp") {
+		t.Error("Expected group exclude patterns to apply")
+	}
+
+	destCfg = cfg.ForDestination(group, Destination{Path: "/share", Extensions: []string{".jpg"}, Exclude: []string{"raw"}})
+	if destCfg.Extensions[0] != ".jpg" || !destCfg.IsExcluded("raw") || destCfg.IsExcluded("clip.tmp") {
+		t.Errorf("Expected destination filters to replace the group's, got %v / %v", destCfg.Extensions, destCfg.Exclude)
+	}
+
+	// Unset overrides keep the global values.
+	destCfg = cfg.ForDestination(CopyGroup{Source: "/src"}, Destination{Path: "/ssd"})
+	if destCfg.Workers != 10 || destCfg.MaxRetries != 3 || !destCfg.Recursive || destCfg.Incremental || destCfg.Extensions[0] != ".jpg" {
+		t.Errorf("Expected global settings without overrides, got %+v", destCfg)
+	}
+}
+
+func TestIsExcluded(t *testing.T) {
+	cfg := &Config{Exclude: []string{"*.tmp", ".thumbnails", "raw/*"}}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "a.tmp", want: true},
+		{path: filepath.Join("2024", "A.TMP"), want: true},
+		{path: ".thumbnails", want: true},
+		{path: filepath.Join("raw", "a.cr3"), want: true},
+		{path: filepath.Join("2024", "raw", "a.cr3"), want: false},
+		{path: "a.jpg", want: false},
+	}
+	for _, tt := range tests {
+		if got := cfg.IsExcluded(tt.path); got != tt.want {
+			t.Errorf("IsExcluded(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAddAndUpdateGroup(t *testing.T) {
+	cfg := DefaultConfig()
+
+	added, err := cfg.AddGroup(CopyGroup{Name: "Videos", Source: "/v", Destinations: []Destination{{Path: "/nas"}}})
+	if err != nil {
+		t.Fatalf("AddGroup failed: %v", err)
+	}
+	if len(added.ID) != 36 || added.Destinations[0].ID == "" {
+		t.Errorf("Expected generated UUIDs, got group %q and destination %q", added.ID, added.Destinations[0].ID)
+	}
+	if _, err := cfg.AddGroup(CopyGroup{ID: added.ID}); err == nil {
+		t.Error("Expected an error for a duplicate group ID")
+	}
+
+	added.Name = "Videos to NAS"
+	added.Destinations = append(added.Destinations, Destination{Path: "/usb"})
+	if err := cfg.UpdateGroup(added); err != nil {
+		t.Fatalf("UpdateGroup failed: %v", err)
+	}
+	updated := cfg.FindGroup(added.ID)
+	if updated.Name != "Videos to NAS" || len(updated.Destinations) != 2 || updated.Destinations[1].ID == "" {
+		t.Errorf("Unexpected updated group: %+v", updated)
+	}
+	if added.Destinations[1].ID != "" {
+		t.Error("UpdateGroup must not modify the caller's destinations")
+	}
+
+	if err := cfg.UpdateGroup(CopyGroup{ID: "missing"}); err == nil {
+		t.Error("Expected an error when updating an unknown group")
+	}
+}
+
+func TestDuplicateGroup(t *testing.T) {
+	cfg := DefaultConfig()
+	first, _ := cfg.AddGroup(CopyGroup{Name: "Cards", Source: "/card", Enabled: true, Schedule: "@daily",
+		Exclude: []string{"*.tmp"}, Destinations: []Destination{{Path: "/nas"}}})
+	last, _ := cfg.AddGroup(CopyGroup{Name: "Videos", Source: "/v"})
+
+	dup, err := cfg.DuplicateGroup(first.ID)
+	if err != nil {
+		t.Fatalf("DuplicateGroup failed: %v", err)
+	}
+	if dup.ID == first.ID || dup.Destinations[0].ID == first.Destinations[0].ID {
+		t.Error("Expected new group and destination IDs")
+	}
+	if dup.Name != "Cards (copy)" || dup.Enabled || dup.Schedule != "" || dup.Destinations[0].Path != "/nas" {
+		t.Errorf("Unexpected duplicate: %+v", dup)
+	}
+	if ids := []string{cfg.Groups[0].ID, cfg.Groups[1].ID, cfg.Groups[2].ID}; ids[1] != dup.ID || ids[2] != last.ID {
+		t.Errorf("Expected the duplicate right after the original, got %v", ids)
+	}
+	cfg.Groups[1].Exclude[0] = "*.xmp"
+	if cfg.Groups[0].Exclude[0] != "*.tmp" {
+		t.Error("Editing the duplicate must not change the original")
+	}
+
+	if _, err := cfg.DuplicateGroup("missing"); err == nil {
+		t.Error("Expected an error when duplicating an unknown group")
+	}
+}
+
+func TestLoadFromFileGeneratesIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "groups:\n  - name: Hand written\n    source: /in\n    destinations:\n      - path: /out\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	if cfg.Groups[0].ID == "" || cfg.Groups[0].Destinations[0].ID == "" {
+		t.Errorf("Expected IDs to be generated, got %+v", cfg.Groups[0])
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// Email sends batch summaries by SMTP, for unattended runs nobody watches.
+type Email struct {
+	// Host is the SMTP server, e.g. "smtp.office365.com".
+	Host string `yaml:"host" json:"host" toml:"host"`
+	// Port defaults to 587 (STARTTLS); 465 connects with TLS right away.
+	Port int `yaml:"port,omitempty" json:"port,omitempty" toml:"port,omitempty"`
+	// Username and Password authenticate with the server; without a
+	// username no authentication is attempted. Password may reference an
+	// environment variable, e.g. "${SMTP_PASSWORD}".
+	Username string `yaml:"username,omitempty" json:"username,omitempty" toml:"username,omitempty"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty" toml:"password,omitempty"`
+	// From is the sender address; empty uses Username.
+	From string   `yaml:"from,omitempty" json:"from,omitempty" toml:"from,omitempty"`
+	To   []string `yaml:"to" json:"to" toml:"to"`
+	// Events lists the events to send, as for webhooks; empty sends
+	// complete and failure, since a mail per started batch is noise.
+	Events []string `yaml:"events,omitempty" json:"events,omitempty" toml:"events,omitempty"`
+}
+
+// Address returns the server's host:port.
+func (e Email) Address() string {
+	port := e.Port
+	if port == 0 {
+		port = 587
+	}
+	return fmt.Sprintf("%s:%d", e.Host, port)
+}
+
+// Credentials returns the user name and the password, with ${VAR} and
+// %VAR% references expanded.
+func (e Email) Credentials() (username, password string) {
+	return e.Username, expandVars(e.Password)
+}
+
+// Sender returns the From address.
+func (e Email) Sender() string {
+	if e.From != "" {
+		return e.From
+	}
+	return e.Username
+}
+
+// Wants reports whether the summary of event should be mailed.
+func (e Email) Wants(event string) bool {
+	if len(e.Events) == 0 {
+		return event == EventComplete || event == EventFailure
+	}
+	return Webhook{Events: e.Events}.Wants(event)
+}
+
+func (c *Config) validateEmail() []Problem {
+	e := c.Email
+	if e == nil {
+		return nil
+	}
+	var problems []Problem
+	if strings.TrimSpace(e.Host) == "" {
+		problems = append(problems, Problem{Field: "email.host", Message: "is required", Hint: "set the SMTP server, e.g. smtp.office365.com"})
+	}
+	if e.Port < 0 || e.Port > 65535 {
+		problems = append(problems, Problem{Field: "email.port", Message: fmt.Sprintf("%d is not a port", e.Port), Hint: "use 587 for STARTTLS or 465 for TLS"})
+	}
+	if len(e.To) == 0 {
+		problems = append(problems, Problem{Field: "email.to", Message: "is required", Hint: "list the addresses to send the reports to"})
+	}
+	for _, to := range e.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			problems = append(problems, Problem{Field: "email.to", Message: fmt.Sprintf("%q is not an email address", to), Hint: "use addresses such as it@example.com"})
+		}
+	}
+	if sender := e.Sender(); sender == "" {
+		problems = append(problems, Problem{Field: "email.from", Message: "is required without a username", Hint: "set the sender address"})
+	} else if _, err := mail.ParseAddress(sender); err != nil {
+		problems = append(problems, Problem{Field: "email.from", Message: fmt.Sprintf("%q is not an email address", sender), Hint: "set from: to the sender address when the username is not one"})
+	}
+	for _, event := range e.Events {
+		switch strings.ToLower(event) {
+		case EventStart, EventComplete, EventFailure, EventBatch:
+		default:
+			problems = append(problems, Problem{Field: "email.events", Message: fmt.Sprintf("unknown event %q", event), Hint: `use "start", "complete", "failure" or "batch"`})
+		}
+	}
+	return problems
+}
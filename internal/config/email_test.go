@@ -0,0 +1,55 @@
+package config
+
+import "testing"
+
+func TestValidateEmail(t *testing.T) {
+	valid := Email{Host: "smtp.example.com", Username: "nas@example.com", To: []string{"it@example.com"}}
+	tests := []struct {
+		name    string
+		edit    func(*Email)
+		problem string // field of the expected problem; empty for none
+	}{
+		{"valid", func(*Email) {}, ""},
+		{"display names", func(e *Email) { e.From = "Copy Image <nas@example.com>"; e.To = []string{"IT <it@example.com>"} }, ""},
+		{"missing host", func(e *Email) { e.Host = "" }, "email.host"},
+		{"bad port", func(e *Email) { e.Port = 70000 }, "email.port"},
+		{"no recipients", func(e *Email) { e.To = nil }, "email.to"},
+		{"bad recipient", func(e *Email) { e.To = []string{"it"} }, "email.to"},
+		{"no sender", func(e *Email) { e.Username = "" }, "email.from"},
+		{"user name is not an address", func(e *Email) { e.Username = "nas" }, "email.from"},
+		{"unknown event", func(e *Email) { e.Events = []string{"done"} }, "email.events"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := valid
+			tt.edit(&e)
+			problems := (&Config{Email: &e}).validateEmail()
+			if tt.problem == "" {
+				if len(problems) != 0 {
+					t.Errorf("Expected no problems, got %v", problems)
+				}
+				return
+			}
+			if len(problems) != 1 || problems[0].Field != tt.problem {
+				t.Errorf("Expected a problem with %s, got %v", tt.problem, problems)
+			}
+		})
+	}
+}
+
+func TestEmailDefaults(t *testing.T) {
+	e := Email{Host: "smtp.example.com", Username: "nas@example.com"}
+	if got := e.Address(); got != "smtp.example.com:587" {
+		t.Errorf("Address() = %q", got)
+	}
+	if e.Sender() != "nas@example.com" {
+		t.Errorf("Sender() = %q", e.Sender())
+	}
+	if e.Wants(EventStart) || e.Wants(EventBatch) || !e.Wants(EventComplete) || !e.Wants(EventFailure) {
+		t.Error("Expected only end events to be mailed by default")
+	}
+	e.Events = []string{"start"}
+	if !e.Wants(EventStart) || e.Wants(EventFailure) {
+		t.Error("Expected the listed events to be mailed")
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvPrefix prefixes the environment variables that override config keys,
+// e.g. COPYIMAGE_SOURCE or COPYIMAGE_MAX_RETRIES.
+const EnvPrefix = "COPYIMAGE_"
+
+// EnvName returns the environment variable that overrides key.
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+// ApplyEnv overrides settings from COPYIMAGE_* environment variables, one
+// per key returned by Keys. This lets CI jobs reuse a shared config file
+// and change only what differs on that machine. Every variable is tried;
+// the returned error lists the ones with invalid values.
+func (c *Config) ApplyEnv() error {
+	var errs []error
+	for _, key := range Keys() {
+		value, ok := os.LookupEnv(EnvName(key))
+		if !ok {
+			continue
+		}
+		if err := c.Set(key, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", EnvName(key), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ExpandEnv replaces ${VAR} and %VAR% references in every path setting,
+// including group and profile paths, so one config file works on machines
+// with different user folders. References to unset variables are kept as
+// written, which makes the resulting "not found" error point at them.
+func (c *Config) ExpandEnv() {
+	c.Source = expandVars(c.Source)
+	c.Destination = expandVars(c.Destination)
+	for i := range c.Sources {
+		c.Sources[i] = expandVars(c.Sources[i])
+	}
+	c.URLsFrom = expandVars(c.URLsFrom)
+
+	for i := range c.Groups {
+		g := &c.Groups[i]
+		g.Source = expandVars(g.Source)
+		for j := range g.Destinations {
+			g.Destinations[j].Path = expandVars(g.Destinations[j].Path)
+		}
+	}
+
+	for name, p := range c.Profiles {
+		p.Source = expandVars(p.Source)
+		p.Destination = expandVars(p.Destination)
+		for i := range p.Sources {
+			p.Sources[i] = expandVars(p.Sources[i])
+		}
+		c.Profiles[name] = p
+	}
+}
+
+// expandVars expands ${VAR} and %VAR% in s. A bare $VAR is deliberately not
+// expanded because "$" is common in Windows admin shares like \\nas\d$.
+func expandVars(s string) string {
+	if !strings.ContainsAny(s, "$%") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if name, n := varRef(s[i:]); n > 0 {
+			if value, ok := os.LookupEnv(name); ok {
+				b.WriteString(value)
+				i += n
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return b.String()
+}
+
+// varRef reports the variable name referenced at the start of s and the
+// length of the reference, or 0 if s does not start with one.
+func varRef(s string) (string, int) {
+	var open, closing string
+	switch {
+	case strings.HasPrefix(s, "${"):
+		open, closing = "${", "}"
+	case strings.HasPrefix(s, "%"):
+		open, closing = "%", "%"
+	default:
+		return "", 0
+	}
+
+	name, _, found := strings.Cut(s[len(open):], closing)
+	if !found || !isVarName(name) {
+		return "", 0
+	}
+	return name, len(open) + len(name) + len(closing)
+}
+
+// isVarName reports whether s is a valid environment variable name.
+func isVarName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandVars(t *testing.T) {
+	t.Setenv("PHOTO_INBOX", "/srv/inbox")
+	t.Setenv("USERPROFILE", `C:\Users\an`)
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "braces", in: "${PHOTO_INBOX}/2024", want: "/srv/inbox/2024"},
+		{name: "percent", in: `%USERPROFILE%\Pictures`, want: `C:\Users\an\Pictures`},
+		{name: "unset kept", in: "${COPYIMAGE_TEST_UNSET}/x", want: "${COPYIMAGE_TEST_UNSET}/x"},
+		{name: "bare dollar kept", in: `\\nas\d$\photos`, want: `\\nas\d$\photos`},
+		{name: "lone percent", in: "100% done", want: "100% done"},
+		{name: "no vars", in: "/photos", want: "/photos"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandVars(tt.in); got != tt.want {
+				t.Errorf("expandVars(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("PHOTO_INBOX", "/srv/inbox")
+
+	cfg := DefaultConfig()
+	cfg.Source = "${PHOTO_INBOX}"
+	cfg.Sources = []string{"${PHOTO_INBOX}/extra"}
+	cfg.Groups = []CopyGroup{{
+		Source:       "${PHOTO_INBOX}/g",
+		Destinations: []Destination{{Path: "${PHOTO_INBOX}/d"}},
+	}}
+	cfg.Profiles = map[string]Profile{"p": {Source: "${PHOTO_INBOX}/p"}}
+
+	cfg.ExpandEnv()
+
+	for _, got := range []string{cfg.Source, cfg.Sources[0], cfg.Groups[0].Source, cfg.Groups[0].Destinations[0].Path, cfg.Profiles["p"].Source} {
+		if !strings.HasPrefix(got, "/srv/inbox") {
+			t.Errorf("Expected %q to be expanded", got)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Setenv("COPYIMAGE_SOURCE", "/ci/source")
+	t.Setenv("COPYIMAGE_MAX_RETRIES", "7")
+	t.Setenv("COPYIMAGE_EXTENSIONS", ".jpg,.png")
+
+	cfg := DefaultConfig()
+	if err := cfg.ApplyEnv(); err != nil {
+		t.Fatalf("ApplyEnv failed: %v", err)
+	}
+	if cfg.Source != "/ci/source" || cfg.MaxRetries != 7 || len(cfg.Extensions) != 2 {
+		t.Errorf("Environment overrides not applied: %+v", cfg)
+	}
+
+	t.Setenv("COPYIMAGE_WORKERS", "many")
+	err := cfg.ApplyEnv()
+	if err == nil || !strings.Contains(err.Error(), "COPYIMAGE_WORKERS") {
+		t.Errorf("Expected an error naming COPYIMAGE_WORKERS, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import "fmt"
+
+// Orders in which files are handed to the copy workers (Config.Order).
+const (
+	// OrderName copies files by path, so runs are reproducible.
+	OrderName = "name"
+	// OrderSizeAsc copies the smallest files first, so most files land
+	// early.
+	OrderSizeAsc = "size-asc"
+	// OrderSizeDesc copies the largest files first: the long copies start
+	// while the other workers take the small ones, which shortens mixed
+	// batches of videos and photos.
+	OrderSizeDesc = "size-desc"
+	// OrderMtime copies the oldest files first, in shooting order.
+	OrderMtime = "mtime"
+	// OrderRandom shuffles the files.
+	OrderRandom = "random"
+)
+
+// checkOrder checks the file order at field.
+func checkOrder(field, order string) []Problem {
+	switch order {
+	case "", OrderName, OrderSizeAsc, OrderSizeDesc, OrderMtime, OrderRandom:
+		return nil
+	}
+	return []Problem{{Field: field, Message: fmt.Sprintf("unknown order %q", order), Hint: `use "name", "size-asc", "size-desc", "mtime" or "random", or remove it to keep the scan order`}}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the file format of a config file.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+)
+
+// FormatFromPath returns the format implied by the file extension, or ""
+// if the extension is not recognized.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	case ".toml":
+		return FormatTOML
+	}
+	return ""
+}
+
+// sniffFormat guesses the format from the first meaningful line, for files
+// without a recognized extension. YAML is the default since it is what
+// the app writes.
+func sniffFormat(data []byte) Format {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "{"):
+			return FormatJSON
+		case strings.HasPrefix(line, "["):
+			// A top-level YAML config is a mapping, so a bracket can only
+			// be a TOML table header.
+			return FormatTOML
+		}
+		eq := strings.Index(line, "=")
+		colon := strings.Index(line, ":")
+		if eq >= 0 && (colon < 0 || eq < colon) {
+			return FormatTOML
+		}
+		return FormatYAML
+	}
+	return FormatYAML
+}
+
+// decode parses data in the given format into c.
+func (c *Config) decode(data []byte, format Format) error {
+	switch format {
+	case FormatJSON:
+		return json.Unmarshal(data, c)
+	case FormatTOML:
+		return toml.Unmarshal(data, c)
+	default:
+		return yaml.Unmarshal(data, c)
+	}
+}
+
+// encode serializes c in the given format.
+func (c *Config) encode(format Format) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case FormatTOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatYAML, "":
+		return yaml.Marshal(c)
+	}
+	return nil, fmt.Errorf("unsupported config format %q", format)
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadFromFileFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "yaml",
+			file:    "config.yml",
+			content: "source: /in\ndestination: /out\nmax_retries: 5\nextensions: [.jpg]\n",
+		},
+		{
+			name:    "json",
+			file:    "config.json",
+			content: `{"source": "/in", "destination": "/out", "maxRetries": 5, "extensions": [".jpg"]}`,
+		},
+		{
+			name:    "toml",
+			file:    "config.toml",
+			content: "source = \"/in\"\ndestination = \"/out\"\nmax_retries = 5\nextensions = [\".jpg\"]\n",
+		},
+		{
+			name:    "sniffed json",
+			file:    "config",
+			content: `{"source": "/in", "destination": "/out", "maxRetries": 5, "extensions": [".jpg"]}`,
+		},
+		{
+			name:    "sniffed toml",
+			file:    "config.conf",
+			content: "# generated\nsource = \"/in\"\ndestination = \"/out\"\nmax_retries = 5\nextensions = [\".jpg\"]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			cfg, err := LoadFromFile(path)
+			if err != nil {
+				t.Fatalf("LoadFromFile failed: %v", err)
+			}
+			if cfg.Source != "/in" || cfg.Destination != "/out" || cfg.MaxRetries != 5 || len(cfg.Extensions) != 1 {
+				t.Errorf("Unexpected config: %+v", cfg)
+			}
+			if cfg.Workers != 10 {
+				t.Errorf("Expected default Workers=10 to be kept, got %d", cfg.Workers)
+			}
+		})
+	}
+}
+
+func TestSaveToFileRoundTrip(t *testing.T) {
+	for _, file := range []string{"config.yaml", "config.json", "config.toml"} {
+		t.Run(file, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Source = "/in"
+			cfg.Destination = "/out"
+			cfg.Extensions = []string{".jpg", ".png"}
+			cfg.AddGroup(CopyGroup{
+				ID:           "g1",
+				Source:       "/g",
+				Enabled:      true,
+				Destinations: []Destination{{ID: "d1", Path: "/d", Enabled: true}},
+			})
+			if err := cfg.SaveProfile("card"); err != nil {
+				t.Fatalf("SaveProfile failed: %v", err)
+			}
+
+			path := filepath.Join(t.TempDir(), file)
+			if err := cfg.SaveToFile(path); err != nil {
+				t.Fatalf("SaveToFile failed: %v", err)
+			}
+			loaded, err := LoadFromFile(path)
+			if err != nil {
+				t.Fatalf("LoadFromFile failed: %v", err)
+			}
+			loaded.format = cfg.format
+			if !reflect.DeepEqual(loaded, cfg) {
+				t.Errorf("Round trip mismatch:\n got %+v\nwant %+v", loaded, cfg)
+			}
+		})
+	}
+}
+
+func TestSaveToFileKeepsLoadedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "copyimage.conf")
+	if err := os.WriteFile(path, []byte(`{"source": "/in", "destination": "/out"}`), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadFromFile failed: %v", err)
+	}
+	cfg.Workers = 4
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "{") {
+		t.Errorf("Expected the config to be saved as JSON again, got:\n%s", data)
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+)
+
+// FTP TLS modes.
+const (
+	FTPExplicitTLS = "explicit" // AUTH TLS on the normal port (FTPES)
+	FTPImplicitTLS = "implicit" // TLS from the first byte, port 990
+)
+
+// FTPOptions configure destinations given as ftp://host[:port]/path URLs.
+// Transfers always use passive mode, which works through NAT.
+type FTPOptions struct {
+	// Username empty logs in anonymously.
+	Username string `yaml:"username,omitempty" json:"username,omitempty" toml:"username,omitempty"`
+	// Password may reference an environment variable, e.g. "${LAB_FTP}".
+	Password string `yaml:"password,omitempty" json:"password,omitempty" toml:"password,omitempty"`
+
+	// TLS is "explicit", "implicit" or empty for plain FTP.
+	TLS string `yaml:"tls,omitempty" json:"tls,omitempty" toml:"tls,omitempty"`
+	// InsecureSkipVerify accepts self-signed server certificates.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty" json:"insecureSkipVerify,omitempty" toml:"insecure_skip_verify,omitempty"`
+	// DisableEPSV uses PASV only, for old servers and firewalls that
+	// mishandle extended passive mode.
+	DisableEPSV bool `yaml:"disable_epsv,omitempty" json:"disableEPSV,omitempty" toml:"disable_epsv,omitempty"`
+}
+
+// Credentials returns the user name and the password, with ${VAR} and
+// %VAR% references expanded, or the anonymous login.
+func (o FTPOptions) Credentials() (username, password string) {
+	if o.Username == "" {
+		return "anonymous", "anonymous"
+	}
+	return o.Username, expandVars(o.Password)
+}
+
+// IsFTP reports whether path is an ftp:// URL, possibly cleaned by
+// filepath.Join into ftp:/host/... form.
+func IsFTP(path string) bool {
+	return strings.HasPrefix(strings.ToLower(filepath.ToSlash(path)), "ftp:/")
+}
+
+// IsRemote reports whether path is the URL of a remote source or
+// destination rather than a file system path.
+func IsRemote(path string) bool {
+	return IsS3(path) || IsFTP(path) || IsSFTP(path)
+}
+
+// ParseFTP splits an ftp://host[:port]/path URL (or a path joined below
+// one) into the server address, with the default port for tls added, and
+// the absolute path on the server.
+func ParseFTP(path, tls string) (addr, remote string, err error) {
+	if !IsFTP(path) {
+		return "", "", fmt.Errorf("not an ftp:// URL: %s", path)
+	}
+	rest := strings.TrimLeft(filepath.ToSlash(path)[len("ftp:"):], "/")
+	host, remote, _ := strings.Cut(rest, "/")
+	if host == "" {
+		return "", "", fmt.Errorf("missing server name in %s", path)
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		port := "21"
+		if tls == FTPImplicitTLS {
+			port = "990"
+		}
+		host = net.JoinHostPort(host, port)
+	}
+	return host, "/" + strings.Trim(remote, "/"), nil
+}
+
+// checkFTP returns the problems of a destination that is an ftp:// URL.
+func checkFTP(field, path string, opts *FTPOptions) []Problem {
+	if !IsFTP(path) {
+		if opts != nil {
+			return []Problem{{Field: field, Message: "has ftp options but is not an ftp:// URL", Hint: "remove ftp, or use ftp://host/folder"}}
+		}
+		return nil
+	}
+	var problems []Problem
+	tls := ""
+	if opts != nil {
+		tls = opts.TLS
+	}
+	if _, _, err := ParseFTP(path, tls); err != nil {
+		problems = append(problems, Problem{Field: field, Message: err.Error(), Hint: "use ftp://host/folder or ftp://host:2121/folder"})
+	}
+	switch tls {
+	case "", FTPExplicitTLS, FTPImplicitTLS:
+	default:
+		problems = append(problems, Problem{Field: field, Message: fmt.Sprintf("unknown ftp.tls %q", tls), Hint: "use explicit, implicit, or remove it for plain FTP"})
+	}
+	return problems
+}
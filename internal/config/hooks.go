@@ -0,0 +1,28 @@
+package config
+
+import "fmt"
+
+// Hooks are shell commands run around each batch (the files of one source
+// copied to one destination), e.g. to mount a share before copying or to
+// start an indexing script afterwards. They run with sh -c, or cmd /C on
+// Windows, and get the batch in COPYIMAGE_* environment variables. Dry
+// runs write nothing and run no hooks.
+type Hooks struct {
+	// PreCopy runs after the scan, before anything is written; when it
+	// fails the batch is not copied and counts as failed.
+	PreCopy string `yaml:"pre_copy,omitempty" json:"preCopy,omitempty" toml:"pre_copy,omitempty"`
+	// PostCopy runs after every batch, whether or not files failed.
+	PostCopy string `yaml:"post_copy,omitempty" json:"postCopy,omitempty" toml:"post_copy,omitempty"`
+	// OnFailure runs after a batch with failed files, one that was
+	// cancelled, or one whose PreCopy failed.
+	OnFailure string `yaml:"on_failure,omitempty" json:"onFailure,omitempty" toml:"on_failure,omitempty"`
+	// Timeout is how many seconds a command may run; 0 means 600.
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty" toml:"timeout,omitempty"`
+}
+
+func (c *Config) validateHooks() []Problem {
+	if c.Hooks == nil || c.Hooks.Timeout >= 0 {
+		return nil
+	}
+	return []Problem{{Field: "hooks.timeout", Message: fmt.Sprintf("%d is negative", c.Hooks.Timeout), Hint: "use a number of seconds, or 0 for the default of 600"}}
+}
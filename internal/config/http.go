@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// IsHTTP reports whether path is an http:// or https:// URL.
+func IsHTTP(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// HTTPOptions configure how the app reaches the internet for updates,
+// for networks that only allow it through a proxy, often one that
+// inspects TLS with a certificate of its own.
+type HTTPOptions struct {
+	// Proxy is the proxy URL, e.g. "http://proxy.corp:8080"; a user and
+	// password may be part of it. Empty uses HTTPS_PROXY, HTTP_PROXY and
+	// NO_PROXY from the environment.
+	Proxy string `yaml:"proxy,omitempty" json:"proxy,omitempty" toml:"proxy,omitempty"`
+	// CAFile is a PEM file of certificate authorities to trust besides
+	// the system's, such as the enterprise CA of an inspecting proxy.
+	CAFile string `yaml:"ca_file,omitempty" json:"caFile,omitempty" toml:"ca_file,omitempty"`
+}
+
+// ProxyURL returns the configured proxy, empty for the environment's. o
+// may be nil.
+func (o *HTTPOptions) ProxyURL() string {
+	if o == nil {
+		return ""
+	}
+	return o.Proxy
+}
+
+// CAPath returns the extra certificate authorities file, if any. o may be
+// nil.
+func (o *HTTPOptions) CAPath() string {
+	if o == nil {
+		return ""
+	}
+	return o.CAFile
+}
+
+// checkHTTPOptions returns the problems of the HTTP options o, which may
+// be nil.
+func checkHTTPOptions(o *HTTPOptions) []Problem {
+	if o.ProxyURL() == "" {
+		return nil
+	}
+	u, err := url.Parse(o.Proxy)
+	if err == nil && u.Host == "" {
+		err = fmt.Errorf("no host")
+	}
+	if err == nil {
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			err = fmt.Errorf("unsupported scheme %q", u.Scheme)
+		}
+	}
+	if err != nil {
+		return []Problem{{Field: "http.proxy", Message: fmt.Sprintf("invalid proxy: %v", err), Hint: `use a URL such as "http://proxy.example.com:8080"`}}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keys returns the names of the top-level settings that can be read and
+// changed with Get and Set. Names match the YAML keys in config.yaml.
+func Keys() []string {
+	var keys []string
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if key, ok := settableKey(t.Field(i)); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the value of a top-level setting formatted as a string.
+// Lists are joined with commas, the same format Set accepts.
+func (c *Config) Get(key string) (string, error) {
+	field, err := c.field(key)
+	if err != nil {
+		return "", err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Int:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Slice:
+		return strings.Join(field.Interface().([]string), ","), nil
+	}
+	return "", fmt.Errorf("unsupported config key: %s", key)
+}
+
+// Set changes a top-level setting from its string representation.
+// This lets the CLI (and environment overrides) update any scalar setting
+// without a dedicated flag for each one.
+func (c *Config) Set(key, value string) error {
+	field, err := c.field(key)
+	if err != nil {
+		return err
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", key, value)
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a number, got %q", key, value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		if items == nil {
+			items = []string{}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config key: %s", key)
+	}
+
+	return nil
+}
+
+// field finds the struct field backing a settable key.
+func (c *Config) field(key string) (reflect.Value, error) {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := settableKey(t.Field(i)); ok && name == key {
+			return v.Field(i), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("unknown config key %q (valid keys: %s)", key, strings.Join(Keys(), ", "))
+}
+
+// settableKey returns the YAML key of a field if it is a scalar or string
+// list that can be expressed on a command line.
+func settableKey(f reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "" || name == "-" {
+		return "", false
+	}
+
+	switch f.Type.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int:
+		return name, true
+	case reflect.Slice:
+		return name, f.Type.Elem().Kind() == reflect.String
+	}
+	return "", false
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	keys := strings.Join(Keys(), ",")
+
+	for _, want := range []string{"source", "destination", "workers", "overwrite", "extensions", "max_retries", "dry_run"} {
+		if !strings.Contains(keys, want) {
+			t.Errorf("Expected key %q in %s", want, keys)
+		}
+	}
+	if strings.Contains(keys, "groups") {
+		t.Error("Groups should not be settable as a scalar key")
+	}
+}
+
+func TestSetAndGet(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		want  string
+	}{
+		{key: "source", value: "/photos", want: "/photos"},
+		{key: "workers", value: "25", want: "25"},
+		{key: "overwrite", value: "true", want: "true"},
+		{key: "extensions", value: ".jpg, .png,,", want: ".jpg,.png"},
+		{key: "max_retries", value: "0", want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			cfg := DefaultConfig()
+			if err := cfg.Set(tt.key, tt.value); err != nil {
+				t.Fatalf("Set failed: %v", err)
+			}
+			got, err := cfg.Get(tt.key)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetInvalid(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if err := cfg.Set("worker", "10"); err == nil {
+		t.Error("Expected error for unknown key")
+	}
+	if err := cfg.Set("workers", "many"); err == nil {
+		t.Error("Expected error for non-numeric workers")
+	}
+	if err := cfg.Set("dry_run", "maybe"); err == nil {
+		t.Error("Expected error for invalid bool")
+	}
+	if _, err := cfg.Get("groups"); err == nil {
+		t.Error("Expected error getting a non-scalar key")
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the default config file name.
+const FileName = "config.yaml"
+
+// appDir is the folder created under the user config directory.
+const appDir = "copy-image"
+
+// fileNames are the names Locate looks for in each folder, in order.
+var fileNames = []string{FileName, "config.yml", "config.json", "config.toml"}
+
+// SearchDirs returns the folders Locate searches, in order: the current
+// directory, the user config directory (e.g. %AppData%\copy-image or
+// ~/.config/copy-image) and the executable's directory. Folders that cannot
+// be determined are left out.
+func SearchDirs() []string {
+	var dirs []string
+	if wd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, wd)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, appDir))
+	}
+	if exe, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(exe))
+	}
+	return dirs
+}
+
+// Locate returns the config file to load. An explicit path (e.g. from a
+// -config flag) wins; a relative one that does not exist in the current
+// directory is also tried next to the executable. Without a path, the
+// first config file found in SearchDirs is used. It returns "" when no
+// config file exists, in which case callers use DefaultConfig.
+func Locate(path string) string {
+	if path != "" {
+		if fileExists(path) || filepath.IsAbs(path) {
+			return existing(path)
+		}
+		if exe, err := os.Executable(); err == nil {
+			return existing(filepath.Join(filepath.Dir(exe), path))
+		}
+		return ""
+	}
+
+	for _, dir := range SearchDirs() {
+		for _, name := range fileNames {
+			if p := filepath.Join(dir, name); fileExists(p) {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// DefaultPath returns where a new config file is written when none was
+// found: the user config directory, which is writable even when the app is
+// installed to Program Files. It falls back to FileName in the current
+// directory if the user config directory is unknown.
+func DefaultPath() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, appDir, FileName)
+	}
+	return FileName
+}
+
+// DataPath returns the path of a file the app keeps next to the default
+// config file, such as the scheduler's last-run state.
+func DataPath(name string) string {
+	return filepath.Join(filepath.Dir(DefaultPath()), name)
+}
+
+// SaveToDefault saves c to DefaultPath, creating its folder, and returns
+// the path written.
+func (c *Config) SaveToDefault() (string, error) {
+	path := DefaultPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return path, c.SaveToFile(path)
+}
+
+// existing returns path if it is an existing file, or "".
+func existing(path string) string {
+	if fileExists(path) {
+		return path
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
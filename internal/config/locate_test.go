@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	cwd := t.TempDir()
+	home := t.TempDir()
+	t.Chdir(cwd)
+	// UserConfigDir reads XDG_CONFIG_HOME on Linux and AppData on Windows.
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("AppData", home)
+	userDir := filepath.Join(home, "copy-image")
+
+	write := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("workers: 2\n"), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+
+	if got := Locate(""); got != "" {
+		t.Errorf("Expected no config, got %q", got)
+	}
+	if got, want := DefaultPath(), filepath.Join(userDir, FileName); got != want {
+		t.Errorf("DefaultPath() = %q, want %q", got, want)
+	}
+
+	write(filepath.Join(userDir, "config.json"))
+	if got, want := Locate(""), filepath.Join(userDir, "config.json"); got != want {
+		t.Errorf("Expected the user config file %q, got %q", want, got)
+	}
+
+	// The current directory wins over the user config directory.
+	write(filepath.Join(cwd, FileName))
+	if got, want := Locate(""), filepath.Join(cwd, FileName); got != want {
+		t.Errorf("Expected the current directory's file %q, got %q", want, got)
+	}
+
+	// An explicit path wins over discovery, but must exist.
+	explicit := filepath.Join(t.TempDir(), "other.toml")
+	if got := Locate(explicit); got != "" {
+		t.Errorf("Expected missing explicit path to yield \"\", got %q", got)
+	}
+	write(explicit)
+	if got := Locate(explicit); got != explicit {
+		t.Errorf("Expected explicit path %q, got %q", explicit, got)
+	}
+}
+
+func TestSaveToDefault(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("AppData", home)
+
+	path, err := DefaultConfig().SaveToDefault()
+	if err != nil {
+		t.Fatalf("SaveToDefault failed: %v", err)
+	}
+	if path != DefaultPath() {
+		t.Errorf("Expected %q, got %q", DefaultPath(), path)
+	}
+	if _, err := LoadFromFile(path); err != nil {
+		t.Errorf("Failed to load saved config: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import "maps"
+
+// Merge applies a shared settings bundle (see the desktop app's
+// ImportConfig) to c. Groups replace those with the same ID and are
+// otherwise appended; profiles replace those with the same name. The
+// bundle's filters (extensions, exclude, recursive) replace c's, since
+// they define what a standard setup copies. Machine-specific settings
+// (source, destination, workers, language, ...) are kept.
+//
+// c's groups and profiles are not modified in place, so c may be a
+// shallow copy of a config that is still in use.
+func (c *Config) Merge(from *Config) {
+	groups := append([]CopyGroup(nil), c.Groups...)
+	for _, g := range from.Groups {
+		g.Destinations = append([]Destination(nil), g.Destinations...)
+		replaced := false
+		for i := range groups {
+			if groups[i].ID == g.ID {
+				groups[i] = g
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			groups = append(groups, g)
+		}
+	}
+	c.Groups = groups
+
+	if len(from.Profiles) > 0 {
+		profiles := maps.Clone(c.Profiles)
+		if profiles == nil {
+			profiles = make(map[string]Profile, len(from.Profiles))
+		}
+		maps.Copy(profiles, from.Profiles)
+		c.Profiles = profiles
+	}
+
+	c.Extensions = append([]string{}, from.Extensions...)
+	c.Exclude = append([]string(nil), from.Exclude...)
+	c.Recursive = from.Recursive
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	local := DefaultConfig()
+	local.Source = `C:\Card`
+	local.Workers = 4
+	local.Profiles = map[string]Profile{"mine": {Source: "a"}, "shared": {Source: "old"}}
+	local.Groups = []CopyGroup{
+		{ID: "keep", Name: "Local only"},
+		{ID: "shared", Name: "Old name"},
+	}
+	original := local.Groups[1]
+	before := *local
+
+	bundle := DefaultConfig()
+	bundle.Source = `D:\Elsewhere`
+	bundle.Workers = 20
+	bundle.Extensions = []string{".cr3"}
+	bundle.Exclude = []string{"*.tmp"}
+	bundle.Recursive = true
+	bundle.Profiles = map[string]Profile{"shared": {Source: "new"}, "team": {Source: "t"}}
+	bundle.Groups = []CopyGroup{
+		{ID: "shared", Name: "New name"},
+		{ID: "team", Name: "Team group"},
+	}
+
+	merged := before
+	merged.Merge(bundle)
+
+	var ids []string
+	for _, g := range merged.Groups {
+		ids = append(ids, g.ID+":"+g.Name)
+	}
+	if want := []string{"keep:Local only", "shared:New name", "team:Team group"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Groups = %v, want %v", ids, want)
+	}
+	if got := merged.ProfileNames(); !reflect.DeepEqual(got, []string{"mine", "shared", "team"}) {
+		t.Errorf("Profiles = %v", got)
+	}
+	if merged.Profiles["shared"].Source != "new" {
+		t.Errorf("Expected the bundle's profile to win, got %+v", merged.Profiles["shared"])
+	}
+	if !reflect.DeepEqual(merged.Extensions, bundle.Extensions) || !reflect.DeepEqual(merged.Exclude, bundle.Exclude) || !merged.Recursive {
+		t.Errorf("Expected the bundle's filters, got ext=%v exclude=%v recursive=%v", merged.Extensions, merged.Exclude, merged.Recursive)
+	}
+	if merged.Source != `C:\Card` || merged.Workers != 4 {
+		t.Errorf("Expected machine settings to be kept, got source=%q workers=%d", merged.Source, merged.Workers)
+	}
+
+	// The config the copy was taken from is untouched.
+	if !reflect.DeepEqual(local.Groups[1], original) {
+		t.Errorf("Merge modified the original groups: %+v", local.Groups[1])
+	}
+	if local.Profiles["shared"].Source != "old" || len(local.Profiles) != 2 {
+		t.Errorf("Merge modified the original profiles: %v", local.Profiles)
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// IsMTP reports whether path is an mtp:// URL naming a phone or camera
+// connected over USB, possibly cleaned by filepath.Join into mtp:/device/...
+// form.
+func IsMTP(path string) bool {
+	return strings.HasPrefix(strings.ToLower(filepath.ToSlash(path)), "mtp:/")
+}
+
+// ParseMTP splits an mtp://<device>/<storage>/<folders> URL (or a path
+// joined below one) into the device's friendly name, as Explorer shows it,
+// and the slash-separated path on the device, "" for the device itself.
+func ParseMTP(path string) (device, rel string, err error) {
+	if !IsMTP(path) {
+		return "", "", fmt.Errorf("not an mtp:// URL: %s", path)
+	}
+	rest := strings.TrimLeft(filepath.ToSlash(path)[len("mtp:"):], "/")
+	device, rel, _ = strings.Cut(rest, "/")
+	if device == "" {
+		return "", "", fmt.Errorf("missing device name in %s", path)
+	}
+	return device, strings.Trim(rel, "/"), nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrderGroups returns groups in execution order: a group runs after the
+// groups it depends on, and among the groups that are ready the one with
+// the highest priority runs first, ties keeping their order in the config.
+// Dependencies on groups that are not in the list are treated as met, so
+// callers can order a selection of groups. Returns an error if the
+// dependencies form a cycle.
+func OrderGroups(groups []CopyGroup) ([]CopyGroup, error) {
+	index := make(map[string]int, len(groups))
+	for i, g := range groups {
+		index[g.ID] = i
+	}
+
+	done := make([]bool, len(groups))
+	ordered := make([]CopyGroup, 0, len(groups))
+	for len(ordered) < len(groups) {
+		next := -1
+		for i, g := range groups {
+			if done[i] || !dependenciesDone(g, index, done) {
+				continue
+			}
+			if next < 0 || g.Priority > groups[next].Priority {
+				next = i
+			}
+		}
+		if next < 0 {
+			var stuck []string
+			for i, g := range groups {
+				if !done[i] {
+					stuck = append(stuck, g.ID)
+				}
+			}
+			return nil, fmt.Errorf("groups %s depend on each other in a cycle", strings.Join(stuck, ", "))
+		}
+		done[next] = true
+		ordered = append(ordered, groups[next])
+	}
+	return ordered, nil
+}
+
+// dependenciesDone reports whether every dependency of g that is in index
+// has already been ordered.
+func dependenciesDone(g CopyGroup, index map[string]int, done []bool) bool {
+	for _, dep := range g.DependsOn {
+		if i, ok := index[dep]; ok && !done[i] {
+			return false
+		}
+	}
+	return true
+}
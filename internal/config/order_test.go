@@ -0,0 +1,89 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOrderGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  []CopyGroup
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "config order by default",
+			groups: []CopyGroup{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+			want:   "a,b,c",
+		},
+		{
+			name:   "higher priority first",
+			groups: []CopyGroup{{ID: "a"}, {ID: "b", Priority: 5}, {ID: "c", Priority: 10}},
+			want:   "c,b,a",
+		},
+		{
+			name: "dependencies before priority",
+			groups: []CopyGroup{
+				{ID: "thumbs", Priority: 10, DependsOn: []string{"import"}},
+				{ID: "import"},
+				{ID: "videos", Priority: 5},
+			},
+			want: "videos,import,thumbs",
+		},
+		{
+			name:   "dependency outside the list is met",
+			groups: []CopyGroup{{ID: "b", DependsOn: []string{"missing"}}, {ID: "a"}},
+			want:   "b,a",
+		},
+		{
+			name:    "cycle",
+			groups:  []CopyGroup{{ID: "a", DependsOn: []string{"b"}}, {ID: "b", DependsOn: []string{"a"}}, {ID: "c"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, err := OrderGroups(tt.groups)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "a, b") {
+					t.Errorf("Expected a cycle error naming a and b, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OrderGroups failed: %v", err)
+			}
+			ids := make([]string, len(ordered))
+			for i, g := range ordered {
+				ids[i] = g.ID
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("Expected order %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	cfg := &Config{Groups: []CopyGroup{
+		{ID: "import", Source: "/in", DependsOn: []string{"import"}},
+		{ID: "thumbs", Source: "/in", DependsOn: []string{"imprt"}},
+	}}
+	problems := Problems(cfg.Validate())
+	if len(problems) != 2 {
+		t.Fatalf("Expected 2 problems, got %v", problems)
+	}
+	if problems[1].Hint != `did you mean "import"?` {
+		t.Errorf("Expected a suggestion for the misspelled ID, got %q", problems[1].Hint)
+	}
+
+	cfg = &Config{Groups: []CopyGroup{
+		{ID: "a", Source: "/in", DependsOn: []string{"b"}},
+		{ID: "b", Source: "/in", DependsOn: []string{"a"}},
+	}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("Expected a cycle problem, got %v", err)
+	}
+}
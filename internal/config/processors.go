@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Stages of the exec processor (Processor.Stage).
+const (
+	StageInspect   = "inspect"   // before the copy; a failing command rejects the file
+	StageTransform = "transform" // the command's output is written instead of the file
+	StageCheck     = "check"     // after the copy; a failing command removes the copy
+)
+
+// Image validation modes (Config.ValidateImages).
+const (
+	ValidateHeader = "header" // decode the header and look for the end of the data
+	ValidateFull   = "full"   // decode the whole image
+)
+
+// Processor is a file processor run on every file copied, such as a
+// virus scanner or a check rejecting corrupt images. Processors run in
+// the order they are listed.
+type Processor struct {
+	// Name is the registered processor to run; "exec" runs Command.
+	Name string `yaml:"name" json:"name" toml:"name"`
+	// Extensions limits the processor to these file types; empty runs it
+	// on every file.
+	Extensions []string `yaml:"extensions,omitempty" json:"extensions,omitempty" toml:"extensions,omitempty"`
+
+	// Command is the exec processor's command line, run with sh -c, or
+	// cmd /C on Windows, with the file in COPYIMAGE_* environment
+	// variables.
+	Command string `yaml:"command,omitempty" json:"command,omitempty" toml:"command,omitempty"`
+	// Stage is when the exec processor runs: inspect (the default),
+	// transform or check.
+	Stage string `yaml:"stage,omitempty" json:"stage,omitempty" toml:"stage,omitempty"`
+	// Timeout is how many seconds the command may take per file; 0 means
+	// 600.
+	Timeout int `yaml:"timeout,omitempty" json:"timeout,omitempty" toml:"timeout,omitempty"`
+
+	// Options configure processors registered by programs embedding the
+	// copier.
+	Options map[string]string `yaml:"options,omitempty" json:"options,omitempty" toml:"options,omitempty"`
+}
+
+// ExecStage returns the stage the exec processor runs at.
+func (p Processor) ExecStage() string {
+	if p.Stage == "" {
+		return StageInspect
+	}
+	return p.Stage
+}
+
+// Applies reports whether the processor runs on a file named name.
+func (p Processor) Applies(name string) bool {
+	if len(p.Extensions) == 0 {
+		return true
+	}
+	return (&Config{Extensions: p.Extensions}).IsExtensionAllowed(filepath.Ext(name))
+}
+
+// validateProcessors checks what can be checked without the registry;
+// unknown names are reported when a copier is set up.
+func (c *Config) validateProcessors() []Problem {
+	var problems []Problem
+	switch c.ValidateImages {
+	case "", ValidateHeader, ValidateFull:
+	default:
+		problems = append(problems, Problem{Field: "validate_images", Message: fmt.Sprintf("unknown mode %q", c.ValidateImages), Hint: `use "header", or "full" to decode whole images`})
+	}
+	for i, p := range c.Processors {
+		field := fmt.Sprintf("processors[%d]", i)
+		if p.Name == "" {
+			problems = append(problems, Problem{Field: field + ".name", Message: "is required", Hint: `use "exec" to run a command`})
+		}
+		for j, ext := range p.Extensions {
+			if problem, ok := checkExtension(fmt.Sprintf("%s.extensions[%d]", field, j), ext); !ok {
+				problems = append(problems, problem)
+			}
+		}
+		if p.Timeout < 0 {
+			problems = append(problems, Problem{Field: field + ".timeout", Message: fmt.Sprintf("%d is negative", p.Timeout), Hint: "use a number of seconds, or 0 for the default of 600"})
+		}
+		if p.Name != "exec" {
+			continue
+		}
+		if p.Command == "" {
+			problems = append(problems, Problem{Field: field + ".command", Message: "is required", Hint: "set the command to run for each file"})
+		}
+		switch p.ExecStage() {
+		case StageInspect, StageTransform, StageCheck:
+		default:
+			problems = append(problems, Problem{Field: field + ".stage", Message: fmt.Sprintf("unknown stage %q", p.Stage), Hint: "use inspect, transform or check"})
+		}
+	}
+	return problems
+}
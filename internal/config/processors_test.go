@@ -0,0 +1,54 @@
+package config
+
+import "testing"
+
+func TestValidateProcessors(t *testing.T) {
+	tests := []struct {
+		name      string
+		processor Processor
+		problem   string // field of the expected problem; empty for none
+	}{
+		{"exec", Processor{Name: "exec", Command: "clamscan --no-summary \"$COPYIMAGE_FILE\""}, ""},
+		{"registered elsewhere", Processor{Name: "jpeg-check", Options: map[string]string{"full": "true"}}, ""},
+		{"missing name", Processor{Command: "true"}, "processors[0].name"},
+		{"missing command", Processor{Name: "exec"}, "processors[0].command"},
+		{"unknown stage", Processor{Name: "exec", Command: "true", Stage: "during"}, "processors[0].stage"},
+		{"negative timeout", Processor{Name: "exec", Command: "true", Timeout: -1}, "processors[0].timeout"},
+		{"bad extension", Processor{Name: "exec", Command: "true", Extensions: []string{"*.jpg"}}, "processors[0].extensions[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := (&Config{Processors: []Processor{tt.processor}}).validateProcessors()
+			if tt.problem == "" {
+				if len(problems) != 0 {
+					t.Errorf("Expected no problems, got %v", problems)
+				}
+				return
+			}
+			if len(problems) != 1 || problems[0].Field != tt.problem {
+				t.Errorf("Expected a problem with %s, got %v", tt.problem, problems)
+			}
+		})
+	}
+}
+
+func TestValidateImagesMode(t *testing.T) {
+	for mode, ok := range map[string]bool{"": true, ValidateHeader: true, ValidateFull: true, "deep": false} {
+		problems := (&Config{ValidateImages: mode}).validateProcessors()
+		if (len(problems) == 0) != ok {
+			t.Errorf("validate_images %q: unexpected problems %v", mode, problems)
+		}
+	}
+}
+
+func TestProcessorApplies(t *testing.T) {
+	p := Processor{Name: "exec", Extensions: []string{"jpg", ".PNG"}}
+	for name, want := range map[string]bool{"a.JPG": true, "b.png": true, "c.cr3": false} {
+		if got := p.Applies(name); got != want {
+			t.Errorf("Applies(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if !(Processor{Name: "exec"}).Applies("c.cr3") {
+		t.Error("Expected a processor without extensions to apply to every file")
+	}
+}
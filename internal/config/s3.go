@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// S3Options configure destinations given as s3://bucket/prefix URLs, on
+// AWS or any S3-compatible service such as MinIO or Wasabi.
+type S3Options struct {
+	// Endpoint is the service host, e.g. "s3.wasabisys.com" or
+	// "minio.local:9000"; empty means AWS.
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty" toml:"endpoint,omitempty"`
+	Region   string `yaml:"region,omitempty" json:"region,omitempty" toml:"region,omitempty"`
+
+	// Profile selects the credentials in ~/.aws/credentials. Empty uses
+	// the AWS_* (or MINIO_*) environment variables, then the default
+	// profile, then the instance role.
+	Profile string `yaml:"profile,omitempty" json:"profile,omitempty" toml:"profile,omitempty"`
+
+	// StorageClass of the uploaded objects, e.g. "STANDARD_IA" or
+	// "GLACIER"; empty uses the bucket's default.
+	StorageClass string `yaml:"storage_class,omitempty" json:"storageClass,omitempty" toml:"storage_class,omitempty"`
+
+	// PartSizeMB is the size of the parts large files are uploaded in
+	// (multipart upload); 0 means 16 MB. Objects can have 10,000 parts.
+	PartSizeMB int `yaml:"part_size_mb,omitempty" json:"partSizeMB,omitempty" toml:"part_size_mb,omitempty"`
+
+	// Insecure uses plain HTTP, for MinIO servers on a local network.
+	Insecure bool `yaml:"insecure,omitempty" json:"insecure,omitempty" toml:"insecure,omitempty"`
+}
+
+// IsS3 reports whether path is an s3:// URL, possibly cleaned by
+// filepath.Join into s3:/bucket/... form.
+func IsS3(path string) bool {
+	return strings.HasPrefix(strings.ToLower(filepath.ToSlash(path)), "s3:/")
+}
+
+// ParseS3 splits an s3://bucket/prefix URL (or a path joined below one)
+// into the bucket and the key prefix, without leading or trailing slashes.
+func ParseS3(path string) (bucket, prefix string, err error) {
+	if !IsS3(path) {
+		return "", "", fmt.Errorf("not an s3:// URL: %s", path)
+	}
+	rest := strings.TrimLeft(filepath.ToSlash(path)[len("s3:"):], "/")
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("missing bucket name in %s", path)
+	}
+	return bucket, strings.Trim(prefix, "/"), nil
+}
+
+// checkS3 returns the problems of a destination that is an s3:// URL.
+func checkS3(field, path string, opts *S3Options) []Problem {
+	if !IsS3(path) {
+		return nil
+	}
+	var problems []Problem
+	if _, _, err := ParseS3(path); err != nil {
+		problems = append(problems, Problem{Field: field, Message: err.Error(), Hint: "use s3://bucket or s3://bucket/prefix"})
+	}
+	if opts != nil && opts.PartSizeMB != 0 && (opts.PartSizeMB < 5 || opts.PartSizeMB > 5120) {
+		problems = append(problems, Problem{Field: field, Message: "s3.part_size_mb must be between 5 and 5120", Hint: "remove it to use 16 MB parts"})
+	}
+	return problems
+}
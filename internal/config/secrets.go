@@ -0,0 +1,150 @@
+package config
+
+import (
+	"net/url"
+	"slices"
+)
+
+// Redacted returns a copy of c without its secrets: the passwords of the
+// connections, zip archives and mail server, the webhook URLs past their
+// host and the header values, and the password of the proxy. It is the
+// copy to keep or hand out where the config file's secrets are not
+// expected, such as the run history. c is not modified.
+func (c *Config) Redacted() *Config {
+	r := *c
+	r.SMB = redactSMB(c.SMB)
+	r.FTP = redactFTP(c.FTP)
+	r.Zip = redactZip(c.Zip)
+	r.SourceSFTP = redactSFTP(c.SourceSFTP)
+
+	if c.Groups != nil {
+		r.Groups = make([]CopyGroup, len(c.Groups))
+	}
+	for i, g := range c.Groups {
+		g.SFTP = redactSFTP(g.SFTP)
+		g.Destinations = slices.Clone(g.Destinations)
+		for j, d := range g.Destinations {
+			d.SMB = redactSMB(d.SMB)
+			d.FTP = redactFTP(d.FTP)
+			d.Zip = redactZip(d.Zip)
+			g.Destinations[j] = d
+		}
+		r.Groups[i] = g
+	}
+
+	if c.Webhooks != nil {
+		r.Webhooks = make([]Webhook, len(c.Webhooks))
+	}
+	for i, w := range c.Webhooks {
+		w.URL = redactURL(w.URL, true)
+		if w.Headers != nil {
+			headers := make(map[string]string, len(w.Headers))
+			for name := range w.Headers {
+				headers[name] = ""
+			}
+			w.Headers = headers
+		}
+		r.Webhooks[i] = w
+	}
+	if c.Email != nil {
+		email := *c.Email
+		email.Password = ""
+		r.Email = &email
+	}
+	if c.HTTP != nil {
+		opts := *c.HTTP
+		opts.Proxy = redactURL(opts.Proxy, false)
+		r.HTTP = &opts
+	}
+	return &r
+}
+
+// RestoreSecrets puts back into a config that was Redacted the passwords
+// its connections have in current, the config in use now, looking them up
+// by groupID and the destination path. Secrets that current no longer has
+// for the same group and destination stay blank.
+func (c *Config) RestoreSecrets(current *Config, groupID string) {
+	from := current
+	if g := current.FindGroup(groupID); g != nil {
+		from = nil
+		for _, d := range g.Destinations {
+			if d.Path == c.Destination {
+				from = current.ForDestination(*g, d)
+				break
+			}
+		}
+	} else if current.Destination != c.Destination {
+		from = nil
+	}
+	if from == nil {
+		return
+	}
+	if c.SMB != nil && from.SMB != nil && c.SMB.Username == from.SMB.Username {
+		c.SMB.Password = from.SMB.Password
+	}
+	if c.FTP != nil && from.FTP != nil && c.FTP.Username == from.FTP.Username {
+		c.FTP.Password = from.FTP.Password
+	}
+	if c.Zip != nil && from.Zip != nil {
+		c.Zip.Password = from.Zip.Password
+	}
+	if c.SourceSFTP != nil && from.SourceSFTP != nil && c.SourceSFTP.Username == from.SourceSFTP.Username {
+		c.SourceSFTP.Password = from.SourceSFTP.Password
+	}
+}
+
+func redactSMB(o *SMBOptions) *SMBOptions {
+	if o == nil {
+		return nil
+	}
+	r := *o
+	r.Password = ""
+	return &r
+}
+
+func redactFTP(o *FTPOptions) *FTPOptions {
+	if o == nil {
+		return nil
+	}
+	r := *o
+	r.Password = ""
+	return &r
+}
+
+func redactZip(o *ZipOptions) *ZipOptions {
+	if o == nil {
+		return nil
+	}
+	r := *o
+	r.Password = ""
+	return &r
+}
+
+func redactSFTP(o *SFTPOptions) *SFTPOptions {
+	if o == nil {
+		return nil
+	}
+	r := *o
+	r.Password = ""
+	return &r
+}
+
+// redactURL drops the password from rawURL and, with path set, its path
+// and query too, which for Slack and Teams webhooks are the secret. A URL
+// that does not parse is dropped whole.
+func redactURL(rawURL string, path bool) string {
+	if rawURL == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	if u.User != nil {
+		u.User = url.User(u.User.Username())
+	}
+	if path {
+		u.Path, u.RawPath, u.RawQuery, u.Fragment = "", "", "", ""
+	}
+	return u.String()
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+)
+
+// SFTPOptions configure sources given as sftp://host[:port]/path URLs.
+// The server's key must be in KnownHosts, as with ssh.
+type SFTPOptions struct {
+	Username string `yaml:"username" json:"username" toml:"username"`
+	// Password may reference an environment variable, e.g. "${SFTP_PW}".
+	// Without it, KeyFile is used.
+	Password string `yaml:"password,omitempty" json:"password,omitempty" toml:"password,omitempty"`
+	// KeyFile is a private key; empty tries ~/.ssh/id_ed25519, id_ecdsa
+	// and id_rsa.
+	KeyFile string `yaml:"key_file,omitempty" json:"keyFile,omitempty" toml:"key_file,omitempty"`
+	// KnownHosts is the known_hosts file; empty uses ~/.ssh/known_hosts.
+	KnownHosts string `yaml:"known_hosts,omitempty" json:"knownHosts,omitempty" toml:"known_hosts,omitempty"`
+}
+
+// Credentials returns the user name and the password, with ${VAR} and
+// %VAR% references expanded.
+func (o SFTPOptions) Credentials() (username, password string) {
+	return o.Username, expandVars(o.Password)
+}
+
+// IsSFTP reports whether path is an sftp:// URL, possibly cleaned by
+// filepath.Join into sftp:/host/... form.
+func IsSFTP(path string) bool {
+	return strings.HasPrefix(strings.ToLower(filepath.ToSlash(path)), "sftp:/")
+}
+
+// ParseSFTP splits an sftp://host[:port]/path URL (or a path joined below
+// one) into the server address, with port 22 added when missing, and the
+// absolute path on the server.
+func ParseSFTP(path string) (addr, remote string, err error) {
+	if !IsSFTP(path) {
+		return "", "", fmt.Errorf("not an sftp:// URL: %s", path)
+	}
+	rest := strings.TrimLeft(filepath.ToSlash(path)[len("sftp:"):], "/")
+	host, remote, _ := strings.Cut(rest, "/")
+	if host == "" {
+		return "", "", fmt.Errorf("missing server name in %s", path)
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "22")
+	}
+	return host, "/" + strings.Trim(remote, "/"), nil
+}
+
+// remoteRoot returns what a source path is read from: the bucket of an
+// s3:// URL, the server of an sftp:// one, the device of an mtp:// one, or
+// "" for local paths.
+func remoteRoot(path string) string {
+	switch {
+	case IsS3(path):
+		bucket, _, _ := ParseS3(path)
+		return "s3://" + bucket
+	case IsSFTP(path):
+		addr, _, _ := ParseSFTP(path)
+		return "sftp://" + addr
+	case IsMTP(path):
+		device, _, _ := ParseMTP(path)
+		return "mtp://" + device
+	}
+	return ""
+}
+
+// sourceOnly returns the scheme of path when it can only be read from,
+// as with sftp:// and mtp:// URLs, and "" otherwise.
+func sourceOnly(path string) string {
+	switch {
+	case IsSFTP(path):
+		return "sftp://"
+	case IsMTP(path):
+		return "mtp://"
+	}
+	return ""
+}
+
+// checkSources returns the problems of the source patterns of a copy: all
+// must be read from the same place, and remote ones must be valid.
+func checkSources(field string, sources []string, s3 *S3Options, sftp *SFTPOptions) []Problem {
+	var problems []Problem
+	for i, source := range sources {
+		if i > 0 && remoteRoot(source) != remoteRoot(sources[0]) {
+			problems = append(problems, Problem{Field: field, Message: fmt.Sprintf("%s is not on the same server as %s", source, sources[0]), Hint: "copy from each server in its own group"})
+		}
+		switch {
+		case IsS3(source):
+			problems = append(problems, checkS3(field, source, s3)...)
+		case IsSFTP(source):
+			if _, _, err := ParseSFTP(source); err != nil {
+				problems = append(problems, Problem{Field: field, Message: err.Error(), Hint: "use sftp://host/folder or sftp://host:2222/folder"})
+			}
+			if sftp == nil || strings.TrimSpace(sftp.Username) == "" {
+				problems = append(problems, Problem{Field: field, Message: "sftp.username is required", Hint: "set the user to log in as"})
+			}
+		case IsMTP(source):
+			if _, _, err := ParseMTP(source); err != nil {
+				problems = append(problems, Problem{Field: field, Message: err.Error(), Hint: "use mtp://<device name>/<storage>/DCIM"})
+			}
+		}
+	}
+	return problems
+}
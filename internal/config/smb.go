@@ -0,0 +1,48 @@
+package config
+
+import "strings"
+
+// SMBOptions are the credentials for a \\server\share destination that is
+// not already connected, e.g. when running as a service or on a machine
+// that never mapped the share.
+type SMBOptions struct {
+	// Username is "user" or "DOMAIN\user".
+	Username string `yaml:"username" json:"username" toml:"username"`
+	// Password may reference an environment variable, e.g.
+	// "${NAS_PASSWORD}", so it need not be stored in the config file.
+	Password string `yaml:"password,omitempty" json:"password,omitempty" toml:"password,omitempty"`
+}
+
+// Credentials returns the user name and the password, with ${VAR} and
+// %VAR% references expanded. Expanding only here keeps the reference,
+// not the secret, in a config saved back by the app.
+func (o SMBOptions) Credentials() (username, password string) {
+	return o.Username, expandVars(o.Password)
+}
+
+// ShareRoot returns the \\server\share part of a UNC path, or "" when path
+// is not one.
+func ShareRoot(path string) string {
+	if !strings.HasPrefix(path, `\\`) && !strings.HasPrefix(path, "//") {
+		return ""
+	}
+	parts := strings.FieldsFunc(path[2:], func(r rune) bool { return r == '\\' || r == '/' })
+	if len(parts) < 2 {
+		return ""
+	}
+	return `\\` + parts[0] + `\` + parts[1]
+}
+
+// checkSMB returns the problems of a destination with SMB credentials.
+func checkSMB(field, path string, opts *SMBOptions) []Problem {
+	if opts == nil {
+		return nil
+	}
+	if ShareRoot(path) == "" {
+		return []Problem{{Field: field, Message: "has smb credentials but is not a \\\\server\\share path", Hint: "remove smb, or use the UNC path of the share"}}
+	}
+	if strings.TrimSpace(opts.Username) == "" {
+		return []Problem{{Field: field, Message: "smb.username is required", Hint: `use "user" or "DOMAIN\user"`}}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formats converted without a command: those with a Go decoder, and
+// those with a Go encoder. HEIC and AVIF sources and WebP output need a
+// command such as ImageMagick.
+var (
+	decodableFormats = map[string]bool{"jpeg": true, "png": true, "gif": true, "bmp": true, "tiff": true, "webp": true}
+	encodableFormats = map[string]bool{"jpeg": true, "png": true, "gif": true, "bmp": true, "tiff": true}
+)
+
+// formatExtensions maps the extensions of image files to their format.
+var formatExtensions = map[string]string{
+	".jpg": "jpeg", ".jpeg": "jpeg", ".png": "png", ".gif": "gif", ".bmp": "bmp",
+	".tif": "tiff", ".tiff": "tiff", ".webp": "webp", ".heic": "heic", ".heif": "heic", ".avif": "avif",
+}
+
+// TranscodeRule converts files of some types to another format while they
+// are copied, e.g. iPhone HEIC photos to JPEG for a client gallery.
+type TranscodeRule struct {
+	// From lists the extensions of the files converted, e.g. [heic].
+	From []string `yaml:"from" json:"from" toml:"from"`
+	// To is the format written: jpeg, png, gif, bmp, tiff or webp. The
+	// copy gets the format's extension.
+	To string `yaml:"to" json:"to" toml:"to"`
+	// Quality is the JPEG quality, 1 to 100; 0 means 90.
+	Quality int `yaml:"quality,omitempty" json:"quality,omitempty" toml:"quality,omitempty"`
+	// Command converts the file on its stdin to To on its stdout instead,
+	// for formats Go cannot read or write (HEIC sources, WebP output). It
+	// gets the quality in COPYIMAGE_QUALITY.
+	Command string `yaml:"command,omitempty" json:"command,omitempty" toml:"command,omitempty"`
+}
+
+// Format returns the format written, with aliases such as jpg resolved.
+func (r TranscodeRule) Format() string {
+	to := strings.ToLower(strings.TrimSpace(r.To))
+	if f, ok := formatExtensions["."+to]; ok {
+		return f
+	}
+	return to
+}
+
+// Extension returns the extension of the files written.
+func (r TranscodeRule) Extension() string {
+	switch f := r.Format(); f {
+	case "jpeg":
+		return ".jpg"
+	case "tiff":
+		return ".tif"
+	default:
+		return "." + f
+	}
+}
+
+// JPEGQuality returns the quality to encode JPEG files with.
+func (r TranscodeRule) JPEGQuality() int {
+	if r.Quality == 0 {
+		return 90
+	}
+	return r.Quality
+}
+
+// Builtin reports whether the rule's conversions need no command.
+func (r TranscodeRule) Builtin() bool {
+	if !encodableFormats[r.Format()] {
+		return false
+	}
+	for _, ext := range r.From {
+		if !decodableFormats[formatExtensions[normalizeExtension(ext)]] {
+			return false
+		}
+	}
+	return true
+}
+
+// Processor returns the processor entry selecting the files r converts.
+func (r TranscodeRule) Processor() Processor {
+	return Processor{Name: "transcode", Extensions: r.From}
+}
+
+func normalizeExtension(ext string) string {
+	return "." + strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+}
+
+// checkTranscode checks the rules at field, e.g. transcode or
+// groups[0].destinations[1].transcode.
+func checkTranscode(field string, rules []TranscodeRule) []Problem {
+	var problems []Problem
+	for i, r := range rules {
+		prefix := fmt.Sprintf("%s[%d]", field, i)
+		if len(r.From) == 0 {
+			problems = append(problems, Problem{Field: prefix + ".from", Message: "is required", Hint: "list the extensions to convert, e.g. [heic]"})
+		}
+		for j, ext := range r.From {
+			if p, ok := checkExtension(fmt.Sprintf("%s.from[%d]", prefix, j), ext); !ok {
+				problems = append(problems, p)
+			}
+		}
+		switch {
+		case r.To == "":
+			problems = append(problems, Problem{Field: prefix + ".to", Message: "is required", Hint: "use jpeg, png, gif, bmp, tiff or webp"})
+		case !encodableFormats[r.Format()] && r.Format() != "webp":
+			problems = append(problems, Problem{Field: prefix + ".to", Message: fmt.Sprintf("unknown format %q", r.To), Hint: "use jpeg, png, gif, bmp, tiff or webp"})
+		case r.Command == "" && len(r.From) > 0 && !r.Builtin():
+			problems = append(problems, Problem{Field: prefix + ".command", Message: "is required for " + strings.Join(r.From, ", ") + " to " + r.Format(), Hint: `convert with a tool such as ImageMagick, e.g. "magick - jpeg:-"`})
+		}
+		if r.Quality < 0 || r.Quality > 100 {
+			problems = append(problems, Problem{Field: prefix + ".quality", Message: fmt.Sprintf("%d is not between 1 and 100", r.Quality), Hint: "use 0 for the default of 90"})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,47 @@
+package config
+
+import "testing"
+
+func TestCheckTranscode(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    TranscodeRule
+		problem string // field of the expected problem; empty for none
+	}{
+		{"png to jpeg", TranscodeRule{From: []string{"png", ".TIF"}, To: "jpg", Quality: 85}, ""},
+		{"heic with a command", TranscodeRule{From: []string{"heic"}, To: "jpeg", Command: "magick - jpeg:-"}, ""},
+		{"heic without a command", TranscodeRule{From: []string{"heic"}, To: "jpeg"}, "transcode[0].command"},
+		{"webp without a command", TranscodeRule{From: []string{"png"}, To: "webp"}, "transcode[0].command"},
+		{"missing from", TranscodeRule{To: "jpeg"}, "transcode[0].from"},
+		{"unknown format", TranscodeRule{From: []string{"png"}, To: "jxl"}, "transcode[0].to"},
+		{"bad quality", TranscodeRule{From: []string{"png"}, To: "jpeg", Quality: 101}, "transcode[0].quality"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := checkTranscode("transcode", []TranscodeRule{tt.rule})
+			if tt.problem == "" {
+				if len(problems) != 0 {
+					t.Errorf("Expected no problems, got %v", problems)
+				}
+				return
+			}
+			if len(problems) != 1 || problems[0].Field != tt.problem {
+				t.Errorf("Expected a problem with %s, got %v", tt.problem, problems)
+			}
+		})
+	}
+}
+
+func TestForDestinationTranscode(t *testing.T) {
+	base := DefaultConfig()
+	base.Transcode = []TranscodeRule{{From: []string{"png"}, To: "jpeg"}}
+	group := CopyGroup{ID: "g", Source: "/src"}
+
+	if cfg := base.ForDestination(group, Destination{Path: "/archive"}); len(cfg.Transcode) != 1 || cfg.Transcode[0].From[0] != "png" {
+		t.Errorf("Expected the global rules, got %+v", cfg.Transcode)
+	}
+	gallery := Destination{Path: "/gallery", Transcode: []TranscodeRule{{From: []string{"heic"}, To: "jpeg", Command: "magick - jpeg:-"}}}
+	if cfg := base.ForDestination(group, gallery); len(cfg.Transcode) != 1 || cfg.Transcode[0].From[0] != "heic" {
+		t.Errorf("Expected the destination's rules, got %+v", cfg.Transcode)
+	}
+}
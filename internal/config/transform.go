@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Transform changes the images written to a destination, e.g. to deliver
+// previews while the archive gets originals.
+type Transform struct {
+	// Resize downscales images.
+	Resize *Resize `yaml:"resize,omitempty" json:"resize,omitempty" toml:"resize,omitempty"`
+	// StripMetadata removes EXIF, XMP and IPTC metadata, such as GPS
+	// positions and camera serial numbers, keeping only the orientation.
+	// Files it cannot be removed from (other than JPEG, PNG, WebP, GIF and
+	// BMP) fail rather than be delivered with their metadata.
+	StripMetadata bool `yaml:"strip_metadata,omitempty" json:"stripMetadata,omitempty" toml:"strip_metadata,omitempty"`
+	// Watermark marks images, e.g. proofs sent to clients.
+	Watermark *Watermark `yaml:"watermark,omitempty" json:"watermark,omitempty" toml:"watermark,omitempty"`
+}
+
+// Resize downscales JPEG, PNG, BMP and TIFF images that are larger than
+// MaxSize, keeping their format and aspect ratio. Smaller images and
+// other files are copied as they are.
+type Resize struct {
+	// MaxSize is the longest side, in pixels, of the images written.
+	MaxSize int `yaml:"max_size" json:"maxSize" toml:"max_size"`
+	// Quality is the JPEG quality, 1 to 100; 0 means 90.
+	Quality int `yaml:"quality,omitempty" json:"quality,omitempty" toml:"quality,omitempty"`
+}
+
+// Watermark positions.
+const (
+	PositionTopLeft     = "top-left"
+	PositionTopRight    = "top-right"
+	PositionBottomLeft  = "bottom-left"
+	PositionBottomRight = "bottom-right"
+	PositionCenter      = "center"
+)
+
+var positions = []string{PositionTopLeft, PositionTopRight, PositionBottomLeft, PositionBottomRight, PositionCenter}
+
+// Watermark draws a PNG image or a line of text over JPEG, PNG, BMP and
+// TIFF images. Other files cannot be marked and fail, so that no
+// unmarked original reaches the destination.
+type Watermark struct {
+	// Image is the path of a PNG file, usually with transparency.
+	Image string `yaml:"image,omitempty" json:"image,omitempty" toml:"image,omitempty"`
+	// Text is drawn in white with a dark outline when Image is empty.
+	Text string `yaml:"text,omitempty" json:"text,omitempty" toml:"text,omitempty"`
+	// Position is top-left, top-right, bottom-left, bottom-right (the
+	// default) or center.
+	Position string `yaml:"position,omitempty" json:"position,omitempty" toml:"position,omitempty"`
+	// Opacity is from 0 (invisible) to 1; 0 means 0.5.
+	Opacity float64 `yaml:"opacity,omitempty" json:"opacity,omitempty" toml:"opacity,omitempty"`
+	// Size is the width of the watermark as a percentage of the image
+	// width, so it looks the same on any image; 0 means 20.
+	Size int `yaml:"size,omitempty" json:"size,omitempty" toml:"size,omitempty"`
+	// Quality is the JPEG quality, 1 to 100; 0 means 90.
+	Quality int `yaml:"quality,omitempty" json:"quality,omitempty" toml:"quality,omitempty"`
+}
+
+// Alpha returns the opacity, 0.5 when it is not set.
+func (w Watermark) Alpha() float64 {
+	if w.Opacity == 0 {
+		return 0.5
+	}
+	return w.Opacity
+}
+
+// Scale returns the width of the watermark as a fraction of the image
+// width, 0.2 when Size is not set.
+func (w Watermark) Scale() float64 {
+	if w.Size == 0 {
+		return 0.2
+	}
+	return float64(w.Size) / 100
+}
+
+// Anchor returns the position, bottom-right when it is not set.
+func (w Watermark) Anchor() string {
+	if w.Position == "" {
+		return PositionBottomRight
+	}
+	return w.Position
+}
+
+// checkTransform checks t at field, e.g. transform or
+// groups[0].destinations[1].transform.
+func checkTransform(field string, t *Transform) []Problem {
+	if t == nil {
+		return nil
+	}
+	problems := checkWatermark(field+".watermark", t.Watermark)
+	if t.Resize == nil {
+		return problems
+	}
+	if t.Resize.MaxSize <= 0 {
+		problems = append(problems, Problem{Field: field + ".resize.max_size", Message: "must be a positive number of pixels", Hint: "e.g. 2048 for previews"})
+	}
+	if t.Resize.Quality < 0 || t.Resize.Quality > 100 {
+		problems = append(problems, Problem{Field: field + ".resize.quality", Message: fmt.Sprintf("%d is not between 1 and 100", t.Resize.Quality), Hint: "use 0 for the default of 90"})
+	}
+	return problems
+}
+
+func checkWatermark(field string, w *Watermark) []Problem {
+	if w == nil {
+		return nil
+	}
+	var problems []Problem
+	switch {
+	case w.Image == "" && w.Text == "":
+		problems = append(problems, Problem{Field: field, Message: "needs an image or a text", Hint: "e.g. text: PROOF"})
+	case w.Image != "" && w.Text != "":
+		problems = append(problems, Problem{Field: field, Message: "has both an image and a text", Hint: "remove one of them"})
+	case w.Image != "" && !strings.EqualFold(filepath.Ext(w.Image), ".png"):
+		problems = append(problems, Problem{Field: field + ".image", Message: fmt.Sprintf("%q is not a PNG file", w.Image), Hint: "save the watermark as PNG to keep its transparency"})
+	}
+	if w.Position != "" && !slices.Contains(positions, w.Position) {
+		problems = append(problems, Problem{Field: field + ".position", Message: fmt.Sprintf("unknown position %q", w.Position), Hint: "use one of " + strings.Join(positions, ", ")})
+	}
+	if w.Opacity < 0 || w.Opacity > 1 {
+		problems = append(problems, Problem{Field: field + ".opacity", Message: fmt.Sprintf("%g is not between 0 and 1", w.Opacity), Hint: "e.g. 0.3 for a discreet watermark"})
+	}
+	if w.Size < 0 || w.Size > 100 {
+		problems = append(problems, Problem{Field: field + ".size", Message: fmt.Sprintf("%d is not a percentage of the image width", w.Size), Hint: "use 0 for the default of 20"})
+	}
+	if w.Quality < 0 || w.Quality > 100 {
+		problems = append(problems, Problem{Field: field + ".quality", Message: fmt.Sprintf("%d is not between 1 and 100", w.Quality), Hint: "use 0 for the default of 90"})
+	}
+	return problems
+}
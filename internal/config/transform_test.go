@@ -0,0 +1,49 @@
+package config
+
+import "testing"
+
+func TestCheckTransform(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform *Transform
+		problem   string // field of the expected problem; empty for none
+	}{
+		{"none", nil, ""},
+		{"previews", &Transform{Resize: &Resize{MaxSize: 2048, Quality: 80}}, ""},
+		{"missing size", &Transform{Resize: &Resize{Quality: 80}}, "transform.resize.max_size"},
+		{"bad quality", &Transform{Resize: &Resize{MaxSize: 2048, Quality: -1}}, "transform.resize.quality"},
+		{"text watermark", &Transform{Watermark: &Watermark{Text: "PROOF", Position: PositionCenter, Opacity: 0.3}}, ""},
+		{"image watermark", &Transform{Watermark: &Watermark{Image: "logo.PNG", Size: 10}}, ""},
+		{"empty watermark", &Transform{Watermark: &Watermark{Opacity: 0.3}}, "transform.watermark"},
+		{"jpeg watermark", &Transform{Watermark: &Watermark{Image: "logo.jpg"}}, "transform.watermark.image"},
+		{"bad position", &Transform{Watermark: &Watermark{Text: "PROOF", Position: "middle"}}, "transform.watermark.position"},
+		{"bad opacity", &Transform{Watermark: &Watermark{Text: "PROOF", Opacity: 30}}, "transform.watermark.opacity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := checkTransform("transform", tt.transform)
+			if tt.problem == "" {
+				if len(problems) != 0 {
+					t.Errorf("Expected no problems, got %v", problems)
+				}
+				return
+			}
+			if len(problems) != 1 || problems[0].Field != tt.problem {
+				t.Errorf("Expected a problem with %s, got %v", tt.problem, problems)
+			}
+		})
+	}
+}
+
+func TestForDestinationTransform(t *testing.T) {
+	base := DefaultConfig()
+	group := CopyGroup{ID: "g", Source: "/src"}
+	previews := Destination{Path: "/previews", Transform: &Transform{Resize: &Resize{MaxSize: 2048}}}
+
+	if cfg := base.ForDestination(group, Destination{Path: "/archive"}); cfg.Transform != nil {
+		t.Errorf("Expected originals for the archive, got %+v", cfg.Transform)
+	}
+	if cfg := base.ForDestination(group, previews); cfg.Transform == nil || cfg.Transform.Resize.MaxSize != 2048 {
+		t.Errorf("Expected previews, got %+v", cfg.Transform)
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UpdateFeed is where the app and the CLI look for new releases, for
+// internal forks that distribute their own builds. Unset, they look in the
+// public GitHub repository.
+type UpdateFeed struct {
+	// GitHub is the REST API URL of a GitHub Enterprise server, e.g.
+	// "https://github.example.com/api/v3"; empty is api.github.com.
+	GitHub string `yaml:"github,omitempty" json:"github,omitempty" toml:"github,omitempty"`
+	// Repo is the repository releases are published in, "owner/name";
+	// empty is the public one.
+	Repo string `yaml:"repo,omitempty" json:"repo,omitempty" toml:"repo,omitempty"`
+	// URL is a generic JSON feed used instead of GitHub: an array of
+	// releases with the fields of GitHub's (tag_name, prerelease, body,
+	// published_at, and assets with name and browser_download_url).
+	URL string `yaml:"url,omitempty" json:"url,omitempty" toml:"url,omitempty"`
+}
+
+// checkUpdateFeed returns the problems of the update feed f, which may be
+// nil.
+func checkUpdateFeed(f *UpdateFeed) []Problem {
+	if f == nil {
+		return nil
+	}
+	var problems []Problem
+	if f.URL != "" && (f.GitHub != "" || f.Repo != "") {
+		problems = append(problems, Problem{Field: "update_feed", Message: "both a feed URL and a GitHub repository are set", Hint: "use either url, or github and repo"})
+	}
+	for _, u := range []struct{ field, url string }{{"update_feed.url", f.URL}, {"update_feed.github", f.GitHub}} {
+		if u.url != "" && !IsHTTP(u.url) {
+			problems = append(problems, Problem{Field: u.field, Message: fmt.Sprintf("%q is not an http(s) URL", u.url)})
+		}
+	}
+	if owner, name, ok := strings.Cut(f.Repo, "/"); f.Repo != "" && (!ok || owner == "" || name == "" || strings.Contains(name, "/")) {
+		problems = append(problems, Problem{Field: "update_feed.repo", Message: fmt.Sprintf("invalid repository %q", f.Repo), Hint: `use "owner/name"`})
+	}
+	return problems
+}
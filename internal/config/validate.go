@@ -0,0 +1,333 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a single issue found in a configuration, with the setting it
+// concerns and, when there is an obvious fix, a hint.
+type Problem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+func (p Problem) String() string {
+	s := strings.TrimSpace(p.Field + " " + p.Message)
+	if p.Hint != "" {
+		s += " (" + p.Hint + ")"
+	}
+	return s
+}
+
+// ValidationError lists every problem found in a configuration.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0].String()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problems:", len(e.Problems))
+	for _, p := range e.Problems {
+		b.WriteString("\n  - " + p.String())
+	}
+	return b.String()
+}
+
+// Problems returns the problems behind err: those of a *ValidationError in
+// its chain, or err itself as a single problem. It returns nil for nil.
+func Problems(err error) []Problem {
+	if err == nil {
+		return nil
+	}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return verr.Problems
+	}
+	return []Problem{{Message: err.Error()}}
+}
+
+// checkExtension reports whether ext looks like a file extension such as
+// ".jpg". Glob patterns and paths are common mistakes, so they get a hint.
+func checkExtension(field, ext string) (Problem, bool) {
+	clean := strings.TrimPrefix(strings.TrimSpace(ext), ".")
+	switch {
+	case clean == "":
+		return Problem{Field: field, Message: "is empty", Hint: "remove it"}, false
+	case strings.ContainsAny(clean, `*?/\`):
+		base := clean[strings.LastIndexAny(clean, `*?/\.`)+1:]
+		return Problem{Field: field, Message: fmt.Sprintf("%q is not a file extension", ext), Hint: fmt.Sprintf("write %q", "."+base)}, false
+	}
+	return Problem{}, true
+}
+
+// checkPattern reports whether pattern is a valid exclude glob.
+func checkPattern(field, pattern string) (Problem, bool) {
+	if strings.TrimSpace(pattern) == "" {
+		return Problem{Field: field, Message: "is empty", Hint: "remove it"}, false
+	}
+	if _, err := path.Match(filepath.ToSlash(pattern), ""); err != nil {
+		return Problem{Field: field, Message: fmt.Sprintf("%q is not a valid pattern", pattern), Hint: "check for an unclosed ["}, false
+	}
+	return Problem{}, true
+}
+
+// validateOverrides checks the group's and its destinations' overrides of
+// the global settings.
+// prefix is the group's field path, e.g. groups[0].
+func (g CopyGroup) validateOverrides(prefix string) []Problem {
+	var problems []Problem
+	if g.Workers < 0 {
+		problems = append(problems, Problem{Field: prefix + ".workers", Message: "must not be negative", Hint: "remove it to use the global workers"})
+	}
+	if g.MaxRetries != nil && *g.MaxRetries < 0 {
+		problems = append(problems, Problem{Field: prefix + ".max_retries", Message: "must not be negative", Hint: "use 0 to disable retries"})
+	}
+	for i, ext := range g.Extensions {
+		if p, ok := checkExtension(fmt.Sprintf("%s.extensions[%d]", prefix, i), ext); !ok {
+			problems = append(problems, p)
+		}
+	}
+	for i, pattern := range g.Exclude {
+		if p, ok := checkPattern(fmt.Sprintf("%s.exclude[%d]", prefix, i), pattern); !ok {
+			problems = append(problems, p)
+		}
+	}
+	if g.Schedule != "" {
+		if _, err := cron.ParseStandard(g.Schedule); err != nil {
+			problems = append(problems, Problem{Field: prefix + ".schedule", Message: err.Error(), Hint: `use minute hour day month weekday, e.g. "0 2 * * *", or "@daily"`})
+		}
+	}
+	for j, d := range g.Destinations {
+		for i, ext := range d.Extensions {
+			if p, ok := checkExtension(fmt.Sprintf("%s.destinations[%d].extensions[%d]", prefix, j, i), ext); !ok {
+				problems = append(problems, p)
+			}
+		}
+		for i, pattern := range d.Exclude {
+			if p, ok := checkPattern(fmt.Sprintf("%s.destinations[%d].exclude[%d]", prefix, j, i), pattern); !ok {
+				problems = append(problems, p)
+			}
+		}
+		problems = append(problems, checkTranscode(fmt.Sprintf("%s.destinations[%d].transcode", prefix, j), d.Transcode)...)
+		problems = append(problems, checkTransform(fmt.Sprintf("%s.destinations[%d].transform", prefix, j), d.Transform)...)
+		problems = append(problems, checkCompress(fmt.Sprintf("%s.destinations[%d].compress", prefix, j), d.Compress)...)
+	}
+	return problems
+}
+
+// validateIDs checks that every group has an ID used by no other group and
+// that destination IDs are unique within their group.
+func (c *Config) validateIDs() []Problem {
+	var problems []Problem
+	groupAt := make(map[string]int, len(c.Groups))
+	for i, g := range c.Groups {
+		field := fmt.Sprintf("groups[%d].id", i)
+		if first, ok := groupAt[g.ID]; ok {
+			problems = append(problems, Problem{Field: field, Message: fmt.Sprintf("%q is also used by groups[%d]", g.ID, first), Hint: "give each group a unique id, or remove it to generate one"})
+		} else if g.ID == "" {
+			problems = append(problems, Problem{Field: field, Message: "is required", Hint: "set a unique id"})
+		} else {
+			groupAt[g.ID] = i
+		}
+
+		destAt := make(map[string]int, len(g.Destinations))
+		for j, d := range g.Destinations {
+			if d.ID == "" {
+				continue
+			}
+			if first, ok := destAt[d.ID]; ok {
+				problems = append(problems, Problem{Field: fmt.Sprintf("groups[%d].destinations[%d].id", i, j), Message: fmt.Sprintf("%q is also used by destinations[%d]", d.ID, first), Hint: "give each destination a unique id"})
+				continue
+			}
+			destAt[d.ID] = j
+		}
+	}
+	return problems
+}
+
+// validateDependencies checks that depends_on names existing groups and
+// that the dependencies can be ordered.
+func (c *Config) validateDependencies() []Problem {
+	ids := make(map[string]bool, len(c.Groups))
+	names := make([]string, 0, len(c.Groups))
+	for _, g := range c.Groups {
+		ids[g.ID] = true
+		names = append(names, g.ID)
+	}
+
+	var problems []Problem
+	for i, g := range c.Groups {
+		for j, dep := range g.DependsOn {
+			field := fmt.Sprintf("groups[%d].depends_on[%d]", i, j)
+			switch {
+			case dep == g.ID:
+				problems = append(problems, Problem{Field: field, Message: "is the group itself", Hint: "remove it"})
+			case !ids[dep]:
+				p := unknownKey(field, dep, names)
+				p.Message = fmt.Sprintf("%q is not a group ID", dep)
+				if !strings.HasPrefix(p.Hint, "did you mean") {
+					p.Hint = "valid group IDs: " + strings.Join(names, ", ")
+				}
+				problems = append(problems, p)
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	if _, err := OrderGroups(c.Groups); err != nil {
+		problems = append(problems, Problem{Field: "groups", Message: err.Error(), Hint: "remove one of the depends_on entries"})
+	}
+	return problems
+}
+
+// unknownKeys returns a problem for every key in data that does not map to
+// a setting, with a "did you mean" hint for likely typos. It walks a
+// generic decoding of the file so all typos are reported at once and nested
+// keys get a full path like groups[0].destinations[1].pth.
+func unknownKeys(data []byte, format Format) []Problem {
+	var raw any
+	var err error
+	switch format {
+	case FormatJSON:
+		err = json.Unmarshal(data, &raw)
+	case FormatTOML:
+		var m map[string]any
+		err = toml.Unmarshal(data, &m)
+		raw = m
+	default:
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil
+	}
+
+	tag := string(format)
+	if format == "" {
+		tag = string(FormatYAML)
+	}
+	var problems []Problem
+	walkKeys(reflect.TypeOf(Config{}), reflect.ValueOf(raw), tag, "", &problems)
+	return problems
+}
+
+// walkKeys compares the keys of the decoded value v against the fields of
+// type t, recursing into nested structs, slices and maps.
+func walkKeys(t reflect.Type, v reflect.Value, tag, path string, problems *[]Problem) {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		walkKeys(t.Elem(), v, tag, path, problems)
+	case reflect.Slice:
+		if v.Kind() != reflect.Slice {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			walkKeys(t.Elem(), v.Index(i), tag, fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+	case reflect.Map:
+		if v.Kind() != reflect.Map {
+			return
+		}
+		for _, k := range v.MapKeys() {
+			walkKeys(t.Elem(), v.MapIndex(k), tag, joinPath(path, fmt.Sprint(k.Interface())), problems)
+		}
+	case reflect.Struct:
+		if v.Kind() != reflect.Map {
+			return
+		}
+		fields := map[string]reflect.Type{}
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get(tag), ",")
+			if name != "" && name != "-" {
+				fields[name] = t.Field(i).Type
+			}
+		}
+
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface()) })
+		for _, k := range keys {
+			key := fmt.Sprint(k.Interface())
+			ft, ok := fields[key]
+			if !ok {
+				names := make([]string, 0, len(fields))
+				for name := range fields {
+					names = append(names, name)
+				}
+				*problems = append(*problems, unknownKey(joinPath(path, key), key, names))
+				continue
+			}
+			walkKeys(ft, v.MapIndex(k), tag, joinPath(path, key), problems)
+		}
+	}
+}
+
+// unknownKey builds the problem for an unknown key, suggesting the closest
+// of the valid names when it is only a small typo away.
+func unknownKey(path, key string, names []string) Problem {
+	p := Problem{Field: path, Message: "is not a known setting"}
+
+	sort.Strings(names)
+	best, bestDist := "", 3
+	for _, name := range names {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	if best != "" {
+		p.Hint = fmt.Sprintf("did you mean %q?", best)
+	} else {
+		p.Hint = "valid keys: " + strings.Join(names, ", ")
+	}
+	return p
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
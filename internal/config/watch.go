@@ -0,0 +1,62 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long Watch waits for writes to settle. Editors often
+// save in several steps (truncate, write, rename), which would otherwise
+// be read half-written.
+const watchDebounce = 200 * time.Millisecond
+
+// Watch calls onChange with the reloaded config each time the file at path
+// changes, until ctx is cancelled. A file that fails to load is passed with
+// its error, exactly as LoadFromFile returns it, so callers can keep their
+// current settings. It returns once the watch is set up.
+//
+// The folder is watched rather than the file, so saves that replace the
+// file (write to a temp file, then rename) keep being noticed.
+func Watch(ctx context.Context, path string, onChange func(*Config, error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+
+	go func() {
+		defer func() { _ = watcher.Close() }()
+
+		name := filepath.Clean(path)
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == name && !event.Has(fsnotify.Chmod) {
+					timer.Reset(watchDebounce)
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			case <-timer.C:
+				onChange(LoadFromFile(path))
+			}
+		}
+	}()
+	return nil
+}
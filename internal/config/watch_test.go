@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := DefaultConfig().SaveToFile(path); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan *Config, 10)
+	errs := make(chan error, 10)
+	err := Watch(ctx, path, func(cfg *Config, err error) {
+		if err != nil {
+			errs <- err
+			return
+		}
+		changes <- cfg
+	})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	// Unrelated files in the same folder are ignored.
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "other.txt"), []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.Exclude = []string{"*.tmp"}
+	if err := cfg.SaveToFile(path); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	select {
+	case got := <-changes:
+		if len(got.Exclude) != 1 || got.Exclude[0] != "*.tmp" {
+			t.Errorf("Expected the reloaded exclude, got %v", got.Exclude)
+		}
+	case err := <-errs:
+		t.Fatalf("Unexpected reload error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the change")
+	}
+
+	if err := os.WriteFile(path, []byte("workers: [oops\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	select {
+	case <-errs:
+	case got := <-changes:
+		t.Errorf("Expected an error for an invalid file, got %+v", got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the change")
+	}
+}
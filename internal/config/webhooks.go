@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+)
+
+// Batch events a webhook can subscribe to (Webhook.Events).
+const (
+	EventStart    = "start"    // a batch is about to be copied
+	EventComplete = "complete" // a batch finished without failures
+	EventFailure  = "failure"  // a batch finished with failed files or was cancelled
+	// EventBatch is sent once `groups run` ran all its groups, with the
+	// summary of each group and destination. Only sent when listed, as
+	// each batch already sent complete or failure.
+	EventBatch = "batch"
+)
+
+// Webhook is an HTTP endpoint told about batches as they start and end,
+// such as a Slack or Teams incoming webhook.
+type Webhook struct {
+	// URL receives a POST per event. It may reference environment
+	// variables, e.g. "${SLACK_WEBHOOK}", to keep the secret out of the file.
+	URL string `yaml:"url" json:"url" toml:"url"`
+	// Events lists the events to send (start, complete, failure, batch);
+	// empty sends all of them but batch.
+	Events []string `yaml:"events,omitempty" json:"events,omitempty" toml:"events,omitempty"`
+	// Headers are added to each request; values may reference environment
+	// variables, e.g. "Bearer ${TOKEN}".
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty" toml:"headers,omitempty"`
+	// Template is a Go text/template for the request body, executed with
+	// the event; empty sends the event as JSON. The json function quotes a
+	// value for use inside a JSON payload.
+	Template string `yaml:"template,omitempty" json:"template,omitempty" toml:"template,omitempty"`
+}
+
+// Endpoint returns URL with ${VAR} and %VAR% references expanded.
+func (w Webhook) Endpoint() string {
+	return expandVars(w.URL)
+}
+
+// HeaderValues returns Headers with references in the values expanded.
+func (w Webhook) HeaderValues() map[string]string {
+	headers := make(map[string]string, len(w.Headers))
+	for name, value := range w.Headers {
+		headers[name] = expandVars(value)
+	}
+	return headers
+}
+
+// Wants reports whether the webhook subscribes to event.
+func (w Webhook) Wants(event string) bool {
+	if len(w.Events) == 0 {
+		return event != EventBatch
+	}
+	for _, e := range w.Events {
+		if strings.EqualFold(e, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookFuncs are the functions available to webhook templates.
+var WebhookFuncs = template.FuncMap{
+	// json quotes a value so names with quotes or newlines cannot break
+	// the payload: {"text": {{json .Destination}}}.
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ParseTemplate parses the body template, or returns nil without one.
+func (w Webhook) ParseTemplate() (*template.Template, error) {
+	if w.Template == "" {
+		return nil, nil
+	}
+	return template.New("webhook").Funcs(WebhookFuncs).Parse(w.Template)
+}
+
+func (c *Config) validateWebhooks() []Problem {
+	var problems []Problem
+	for i, w := range c.Webhooks {
+		field := fmt.Sprintf("webhooks[%d]", i)
+		// Only a literal URL can be checked; references are resolved when
+		// the webhook fires.
+		if strings.TrimSpace(w.URL) == "" {
+			problems = append(problems, Problem{Field: field + ".url", Message: "is required", Hint: "set the URL to POST to, e.g. a Slack incoming webhook"})
+		} else if !strings.ContainsAny(w.URL, "$%") {
+			if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				problems = append(problems, Problem{Field: field + ".url", Message: fmt.Sprintf("%q is not an http(s) URL", w.URL), Hint: "use a URL such as https://hooks.slack.com/services/..."})
+			}
+		}
+		for _, e := range w.Events {
+			switch strings.ToLower(e) {
+			case EventStart, EventComplete, EventFailure, EventBatch:
+			default:
+				problems = append(problems, Problem{Field: field + ".events", Message: fmt.Sprintf("unknown event %q", e), Hint: `use "start", "complete", "failure" or "batch"`})
+			}
+		}
+		if _, err := w.ParseTemplate(); err != nil {
+			problems = append(problems, Problem{Field: field + ".template", Message: err.Error(), Hint: "fix the Go template syntax"})
+		}
+	}
+	return problems
+}
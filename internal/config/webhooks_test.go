@@ -0,0 +1,63 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateWebhooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    Webhook
+		problem string // field of the expected problem; empty for none
+	}{
+		{"valid", Webhook{URL: "https://hooks.slack.com/services/T/B/X", Events: []string{"complete", "Failure"}}, ""},
+		{"env reference", Webhook{URL: "${SLACK_WEBHOOK}"}, ""},
+		{"missing url", Webhook{}, "webhooks[0].url"},
+		{"not http", Webhook{URL: "ftp://example.com/hook"}, "webhooks[0].url"},
+		{"unknown event", Webhook{URL: "https://example.com", Events: []string{"finished"}}, "webhooks[0].events"},
+		{"bad template", Webhook{URL: "https://example.com", Template: "{{.Event"}, "webhooks[0].template"},
+		{"unknown function", Webhook{URL: "https://example.com", Template: "{{yaml .}}"}, "webhooks[0].template"},
+		{"json function", Webhook{URL: "https://example.com", Template: `{"text": {{json .Destination}}}`}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Webhooks: []Webhook{tt.hook}}
+			problems := c.validateWebhooks()
+			if tt.problem == "" {
+				if len(problems) != 0 {
+					t.Errorf("Expected no problems, got %v", problems)
+				}
+				return
+			}
+			if len(problems) != 1 || problems[0].Field != tt.problem {
+				t.Errorf("Expected a problem with %s, got %v", tt.problem, problems)
+			}
+		})
+	}
+}
+
+func TestWebhookWantsAndExpansion(t *testing.T) {
+	t.Setenv("HOOK_HOST", "hooks.example.com")
+	w := Webhook{URL: "https://${HOOK_HOST}/x", Headers: map[string]string{"X-Host": "${HOOK_HOST}"}}
+	if !w.Wants(EventStart) || !w.Wants(EventFailure) {
+		t.Error("Expected a webhook without events to want all of them")
+	}
+	if w.Wants(EventBatch) {
+		t.Error("Expected batch events to be sent only when listed")
+	}
+	w.Events = []string{"failure"}
+	if w.Wants(EventComplete) || !w.Wants(EventFailure) {
+		t.Error("Expected only failures to be wanted")
+	}
+	if got := w.Endpoint(); got != "https://hooks.example.com/x" {
+		t.Errorf("Endpoint() = %q", got)
+	}
+	if got := w.HeaderValues()["X-Host"]; got != "hooks.example.com" {
+		t.Errorf("HeaderValues() = %q", got)
+	}
+	// The config keeps the reference, so saving it does not write the secret.
+	if !strings.Contains(w.URL, "${HOOK_HOST}") {
+		t.Error("Expected the URL to be left as written")
+	}
+}
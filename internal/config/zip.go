@@ -0,0 +1,54 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// TypeZip makes a destination a zip archive instead of a folder: Path is
+// the archive, e.g. deliveries/job123.zip, and the files copied are its
+// entries.
+const TypeZip = "zip"
+
+// ZipOptions configure zip archive destinations.
+type ZipOptions struct {
+	// Password encrypts the entries with AES-256 (WinZip AES, which 7-Zip
+	// and WinZip open). It may reference an environment variable, e.g.
+	// "${DELIVERY_PASSWORD}". Empty writes a plain archive.
+	Password string `yaml:"password,omitempty" json:"password,omitempty" toml:"password,omitempty"`
+}
+
+// Secret returns the password with ${VAR} and %VAR% references expanded.
+func (o ZipOptions) Secret() string {
+	return expandVars(o.Password)
+}
+
+// IsArchive reports whether the destination is a zip archive.
+func (c *Config) IsArchive() bool {
+	return c.DestinationType == TypeZip
+}
+
+// LockDir returns the folder holding the session lock of the destination:
+// the destination itself, or the folder of an archive.
+func (c *Config) LockDir() string {
+	if c.IsArchive() {
+		return filepath.Dir(c.Destination)
+	}
+	return c.Destination
+}
+
+// checkZip returns the problems of the destination at path of type typ,
+// set at field and typeField.
+func checkZip(field, typeField, path, typ string, opts *ZipOptions) []Problem {
+	switch {
+	case typ != "" && typ != TypeZip:
+		return []Problem{{Field: typeField, Message: "unknown destination type " + typ, Hint: `use "zip", or remove type for a folder`}}
+	case typ == "" && opts != nil:
+		return []Problem{{Field: field, Message: "has zip options but is not a zip destination", Hint: "add type: zip, or remove zip"}}
+	case typ == TypeZip && IsRemote(path):
+		return []Problem{{Field: field, Message: "zip archives are written to local folders and shares only", Hint: `e.g. D:\Deliveries\job123.zip`}}
+	case typ == TypeZip && !strings.EqualFold(filepath.Ext(path), ".zip"):
+		return []Problem{{Field: field, Message: "is not a .zip file", Hint: "name the archive, e.g. deliveries/job123.zip"}}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package copier
+
+import (
+	"encoding/json"
+)
+
+// BatchSummary holds the results of running several copy groups, such as
+// RunGroups does: each group with the summary of each of its destinations.
+// As JSON it carries the totals of every level, so the GUI, report files
+// and webhooks need not add them up themselves.
+type BatchSummary struct {
+	Groups []GroupSummary
+}
+
+// Failed reports whether a group did not run or some files failed.
+func (b BatchSummary) Failed() bool {
+	for _, g := range b.Groups {
+		if g.Failed() {
+			return true
+		}
+	}
+	return false
+}
+
+// Total adds up the groups into a single CopySummary.
+func (b BatchSummary) Total() CopySummary {
+	total := CopySummary{FailedFiles: make([]FailedFile, 0)}
+	for _, g := range b.Groups {
+		total.add(g.Total())
+	}
+	return total
+}
+
+// add adds the counts and files of o to s. Durations add up too, as
+// destinations and groups run one after another.
+func (s *CopySummary) add(o CopySummary) {
+	s.TotalFiles += o.TotalFiles
+	s.Successful += o.Successful
+	s.Failed += o.Failed
+	s.Skipped += o.Skipped
+	s.Corrupt += o.Corrupt
+	s.Duration += o.Duration
+	s.TotalBytes += o.TotalBytes
+	s.OverwrittenBytes += o.OverwrittenBytes
+	s.SkippedBytes += o.SkippedBytes
+	s.Created = append(s.Created, o.Created...)
+	s.FailedFiles = append(s.FailedFiles, o.FailedFiles...)
+}
+
+// MarshalJSON writes the summary like the CLI's JSON summary, with the
+// duration in seconds. Created is left out: it is only kept for undo.
+func (s CopySummary) MarshalJSON() ([]byte, error) {
+	failed := s.FailedFiles
+	if failed == nil {
+		failed = []FailedFile{}
+	}
+	return json.Marshal(struct {
+		TotalFiles       int          `json:"totalFiles"`
+		Successful       int          `json:"successful"`
+		Failed           int          `json:"failed"`
+		Skipped          int          `json:"skipped"`
+		Corrupt          int          `json:"corrupt"`
+		Duration         float64      `json:"duration"`
+		TotalBytes       int64        `json:"totalBytes"`
+		OverwrittenBytes int64        `json:"overwrittenBytes"`
+		SkippedBytes     int64        `json:"skippedBytes"`
+		FailedFiles      []FailedFile `json:"failedFiles"`
+	}{s.TotalFiles, s.Successful, s.Failed, s.Skipped, s.Corrupt, s.Duration.Seconds(), s.TotalBytes, s.OverwrittenBytes, s.SkippedBytes, failed})
+}
+
+// MarshalJSON writes the group with its total and, when it did not run,
+// why.
+func (g GroupSummary) MarshalJSON() ([]byte, error) {
+	out := struct {
+		GroupID      string               `json:"groupId"`
+		GroupName    string               `json:"groupName,omitempty"`
+		Error        string               `json:"error,omitempty"`
+		Destinations []DestinationSummary `json:"destinations"`
+		Total        CopySummary          `json:"total"`
+	}{GroupID: g.GroupID, GroupName: g.GroupName, Destinations: g.Destinations, Total: g.Total()}
+	if g.Err != nil {
+		out.Error = g.Err.Error()
+	}
+	if out.Destinations == nil {
+		out.Destinations = []DestinationSummary{}
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON writes the groups with the total of the batch.
+func (b BatchSummary) MarshalJSON() ([]byte, error) {
+	groups := b.Groups
+	if groups == nil {
+		groups = []GroupSummary{}
+	}
+	return json.Marshal(struct {
+		Groups []GroupSummary `json:"groups"`
+		Total  CopySummary    `json:"total"`
+		Failed bool           `json:"failed"`
+	}{groups, b.Total(), b.Failed()})
+}
@@ -0,0 +1,71 @@
+package copier
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBatchSummary(t *testing.T) {
+	boom := errors.New("boom")
+	batch := BatchSummary{Groups: []GroupSummary{
+		{GroupID: "cards", Destinations: []DestinationSummary{
+			{DestinationID: "nas", Path: "/nas", Summary: CopySummary{TotalFiles: 3, Successful: 2, Failed: 1, Duration: time.Second, FailedFiles: []FailedFile{{Path: "/card/a.jpg", Err: boom}}}},
+			{DestinationID: "usb", Path: "/usb", Summary: CopySummary{TotalFiles: 3, Successful: 1, Skipped: 2, Duration: time.Second}},
+		}},
+		{GroupID: "phone", Err: boom},
+	}}
+
+	total := batch.Total()
+	if total.TotalFiles != 6 || total.Successful != 3 || total.Failed != 1 || total.Skipped != 2 || total.Duration != 2*time.Second {
+		t.Errorf("Unexpected total: %+v", total)
+	}
+	if len(total.FailedFiles) != 1 || total.FailedFiles[0].String() != "a.jpg: boom → /nas" || !errors.Is(total.FailedFiles[0].Err, boom) {
+		t.Errorf("Expected the failure with its destination, got %v", total.FailureLines())
+	}
+	if got := batch.Groups[0].Destinations[0].Summary.FailedFiles[0].Err; got != boom {
+		t.Errorf("Expected the destination's failure to be left as it was, got %v", got)
+	}
+	if !batch.Failed() {
+		t.Error("Expected the batch to have failed")
+	}
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var got struct {
+		Groups []struct {
+			GroupID      string `json:"groupId"`
+			Error        string `json:"error"`
+			Destinations []struct {
+				DestinationID string `json:"destinationId"`
+				Summary       struct {
+					TotalFiles int `json:"totalFiles"`
+				} `json:"summary"`
+			} `json:"destinations"`
+			Total struct {
+				TotalFiles int     `json:"totalFiles"`
+				Duration   float64 `json:"duration"`
+			} `json:"total"`
+		} `json:"groups"`
+		Total struct {
+			Failed      int               `json:"failed"`
+			FailedFiles []json.RawMessage `json:"failedFiles"`
+		} `json:"total"`
+		Failed bool `json:"failed"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(got.Groups) != 2 || len(got.Groups[0].Destinations) != 2 || got.Groups[0].Destinations[1].DestinationID != "usb" {
+		t.Fatalf("Unexpected groups: %s", data)
+	}
+	if g := got.Groups[0]; g.Destinations[0].Summary.TotalFiles != 3 || g.Total.TotalFiles != 6 || g.Total.Duration != 2 {
+		t.Errorf("Unexpected totals of the group: %s", data)
+	}
+	if got.Groups[1].Error != "boom" || got.Total.Failed != 1 || len(got.Total.FailedFiles) != 1 || !got.Failed {
+		t.Errorf("Unexpected batch: %s", data)
+	}
+}
@@ -0,0 +1,244 @@
+package copier
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+)
+
+// batchHashes collects the hashes of the files copied in a batch for the
+// checksum manifest (Config.Checksums) and the MHL file (Config.MHL).
+type batchHashes struct {
+	algos []string // distinct algorithms to compute
+
+	mu    sync.Mutex
+	start time.Time // when the first file of the batch was hashed
+	files []hashedFile
+}
+
+// hashedFile is a copied file with the hashes of its content.
+type hashedFile struct {
+	source  string
+	size    int64
+	modTime time.Time
+	hashed  time.Time
+	sums    map[string]string // algorithm → hex hash
+}
+
+// newBatchHashes returns the collector for cfg, or nil when nothing needs
+// hashes.
+func newBatchHashes(cfg *config.Config) *batchHashes {
+	var algos []string
+	if cfg.Checksums != "" {
+		algos = append(algos, cfg.Checksums)
+	}
+	if cfg.MHL && cfg.Checksums != config.ChecksumMD5 {
+		algos = append(algos, config.ChecksumMD5)
+	}
+	if len(algos) == 0 {
+		return nil
+	}
+	return &batchHashes{algos: algos}
+}
+
+// newHashes returns a fresh hash for each algorithm. A SHA-256 hash the
+// copier computes anyway is passed as sha to be reused.
+func (b *batchHashes) newHashes(sha hash.Hash) map[string]hash.Hash {
+	hashes := make(map[string]hash.Hash, len(b.algos))
+	for _, algo := range b.algos {
+		switch {
+		case algo == config.ChecksumMD5:
+			hashes[algo] = md5.New()
+		case sha != nil:
+			hashes[algo] = sha
+		default:
+			hashes[algo] = sha256.New()
+		}
+	}
+	return hashes
+}
+
+func (b *batchHashes) add(f hashedFile) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.files) == 0 {
+		b.start = f.hashed
+	}
+	b.files = append(b.files, f)
+}
+
+// take returns the files hashed so far and starts over, so each batch
+// reports only its own files.
+func (b *batchHashes) take() (time.Time, []hashedFile) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	start, files := b.start, b.files
+	b.files = nil
+	return start, files
+}
+
+// recordHashes remembers a copied file with its hashes for the batch's
+// manifests.
+func (c *Copier) recordHashes(sourcePath string, size int64, hashes map[string]hash.Hash) {
+	f := hashedFile{source: sourcePath, size: size, hashed: time.Now(), sums: make(map[string]string, len(hashes))}
+	for algo, h := range hashes {
+		f.sums[algo] = hex.EncodeToString(h.Sum(nil))
+	}
+	if info, err := c.src.Stat(sourcePath); err == nil {
+		f.modTime = info.ModTime()
+	}
+	c.hashes.add(f)
+}
+
+// writeManifests writes the checksum manifest and the MHL file of the
+// files copied since the last call. Failing to write them is logged but
+// never fails the batch.
+func (c *Copier) writeManifests() {
+	if c.hashes == nil || c.config.DryRun {
+		return
+	}
+	start, files := c.hashes.take()
+	if len(files) == 0 {
+		return
+	}
+	if c.config.Checksums != "" {
+		c.writeChecksums(files)
+	}
+	if c.config.MHL {
+		if err := c.writeMHL(start, files); err != nil {
+			c.logger.Warn("MHL file not written", "error", err)
+		}
+	}
+}
+
+// hashWriter returns a writer feeding each distinct non-nil hash, or nil
+// when there is none.
+func hashWriter(hashes ...hash.Hash) io.Writer {
+	var writers []io.Writer
+	for i, h := range hashes {
+		if h != nil && !containsHash(hashes[:i], h) {
+			writers = append(writers, h)
+		}
+	}
+	switch len(writers) {
+	case 0:
+		return nil
+	case 1:
+		return writers[0]
+	}
+	return io.MultiWriter(writers...)
+}
+
+func containsHash(hashes []hash.Hash, h hash.Hash) bool {
+	for _, other := range hashes {
+		if other == h {
+			return true
+		}
+	}
+	return false
+}
+
+// writeChecksums adds files to the manifests in the destination and, if
+// configured, in each source folder. Manifests accumulate across batches:
+// files copied again get their new hash and the others keep theirs.
+func (c *Copier) writeChecksums(files []hashedFile) {
+	algo := c.config.Checksums
+	name := config.ChecksumFile(algo)
+	toDestination, toSource := c.config.ChecksumTargets()
+
+	if toDestination {
+		entries := make(map[string]string, len(files))
+		for _, f := range files {
+			entries[c.relDest(c.DestPath(f.source))] = f.sums[algo]
+		}
+		if err := mergeChecksums(c.dst, filepath.Join(c.config.Destination, name), entries); err != nil {
+			c.logger.Warn("checksum manifest not written", "error", err)
+		}
+	}
+	if toSource {
+		for _, root := range c.roots() {
+			entries := make(map[string]string)
+			for _, f := range files {
+				if rel, err := filepath.Rel(root, f.source); err == nil && filepath.IsLocal(rel) {
+					entries[rel] = f.sums[algo]
+				}
+			}
+			if len(entries) == 0 {
+				continue
+			}
+			if err := mergeChecksums(c.src, filepath.Join(root, name), entries); err != nil {
+				c.logger.Warn("checksum manifest not written", "error", err)
+			}
+		}
+	}
+}
+
+// mergeChecksums updates the manifest at path with entries, keyed by the
+// path relative to the manifest's folder, and rewrites it sorted by path.
+func mergeChecksums(s storage.Storage, path string, entries map[string]string) error {
+	existing, err := readChecksums(s, path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	for rel, sum := range entries {
+		existing[filepath.ToSlash(rel)] = sum
+	}
+
+	names := make([]string, 0, len(existing))
+	for name := range existing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	f, err := s.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, name := range names {
+		// Two spaces: text mode in sha256sum's format.
+		_, _ = fmt.Fprintf(w, "%s  %s\n", existing[name], name)
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readChecksums reads a manifest in sha256sum/md5sum format into a map of
+// path to hash. It returns an empty map with the error when it cannot be
+// read.
+func readChecksums(s storage.Storage, path string) (map[string]string, error) {
+	sums := make(map[string]string)
+	f, err := s.Open(path)
+	if err != nil {
+		return sums, err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		sum, name, ok := strings.Cut(scanner.Text(), " ")
+		if !ok || sum == "" {
+			continue
+		}
+		// " *name" marks binary mode, "  name" text mode.
+		sums[strings.TrimPrefix(strings.TrimPrefix(name, " "), "*")] = sum
+	}
+	return sums, scanner.Err()
+}
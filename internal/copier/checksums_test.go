@@ -0,0 +1,103 @@
+package copier
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"copy-image/internal/config"
+)
+
+func TestChecksumManifest(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "100CANON"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{"a.jpg": "first", "100CANON/b.jpg": "second"}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(src, filepath.FromSlash(name)), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = src
+	cfg.Destination = dst
+	cfg.Recursive = true
+	cfg.Checksums = config.ChecksumSHA256
+	cfg.ChecksumsIn = config.ChecksumsInBoth
+	c := New(cfg)
+	list, err := c.GetFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary := c.CopyFilesParallelWithEvents(context.Background(), list, nil); summary.Successful != 2 {
+		t.Fatalf("Expected 2 files copied, got %+v", summary)
+	}
+
+	sum := func(data string) string {
+		h := sha256.Sum256([]byte(data))
+		return hex.EncodeToString(h[:])
+	}
+	want := sum("second") + "  100CANON/b.jpg\n" + sum("first") + "  a.jpg\n"
+	for _, dir := range []string{dst, src} {
+		got, err := os.ReadFile(filepath.Join(dir, "checksums.sha256"))
+		if err != nil || string(got) != want {
+			t.Errorf("Manifest in %s = %q, %v, want %q", dir, got, err, want)
+		}
+	}
+
+	// The next batch adds to the manifest, and the manifest in the source
+	// is not copied as an image.
+	if err := os.WriteFile(filepath.Join(src, "c.jpg"), []byte("third"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	list, err = c.GetFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("Expected the manifest to be left out of the scan, got %v", list)
+	}
+	c.CopyFilesParallelWithEvents(context.Background(), list, nil)
+	want += sum("third") + "  c.jpg\n"
+	got, _ := os.ReadFile(filepath.Join(dst, "checksums.sha256"))
+	if string(got) != want {
+		t.Errorf("Manifest after a second batch = %q, want %q", got, want)
+	}
+}
+
+func TestChecksumManifestMD5(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "a.jpg"), []byte("jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A hash from an earlier delivery, in binary mode, is kept.
+	if err := os.WriteFile(filepath.Join(dst, "checksums.md5"), []byte("0123 *old.jpg\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = src
+	cfg.Destination = dst
+	cfg.Checksums = config.ChecksumMD5
+	c := New(cfg)
+	c.OnResult(func(CopyResult) {}) // hashes SHA-256 as well
+	c.CopyFilesParallelWithEvents(context.Background(), []string{filepath.Join(src, "a.jpg")}, nil)
+
+	h := md5.Sum([]byte("jpeg"))
+	want := hex.EncodeToString(h[:]) + "  a.jpg\n0123  old.jpg\n"
+	got, err := os.ReadFile(filepath.Join(dst, "checksums.md5"))
+	if err != nil || string(got) != want {
+		t.Errorf("Manifest = %q, %v, want %q", got, err, want)
+	}
+	if _, err := os.Stat(filepath.Join(src, "checksums.md5")); !os.IsNotExist(err) {
+		t.Error("The source should only get a manifest when asked")
+	}
+}
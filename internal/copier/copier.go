@@ -2,19 +2,33 @@ package copier
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
-	"os"
+	"io/fs"
+	"log/slog"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"copy-image/internal/config"
+	"copy-image/internal/imagecheck"
+	"copy-image/internal/logging"
+	"copy-image/internal/manifest"
+	"copy-image/internal/processor"
+	"copy-image/internal/sanitize"
+	"copy-image/internal/storage"
 	"copy-image/internal/utils"
 
 	"github.com/schollz/progressbar/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // CopyResult represents the result of a single file copy operation.
@@ -24,218 +38,723 @@ type CopyResult struct {
 	Success  bool
 	Skipped  bool
 	Error    error
+
+	// Created is set on success when the destination file did not exist
+	// before the copy.
+	Created *CreatedFile
+
+	// Unchanged is set with Skipped when the incremental manifest shows
+	// the file was already copied, rather than because it exists.
+	Unchanged bool
+
+	// OriginalName is the source name when the filename policy gave the
+	// destination file a different one.
+	OriginalName string
+
+	// Action is what a dry-run found the copy would do: ActionCopy,
+	// ActionOverwrite, ActionSkip or ActionConflict. Empty outside dry-runs.
+	Action string
+
+	// The fields below are filled in by CopyFileWithRetry for result logs.
+	SourcePath string
+	DestPath   string
+	Bytes      int64         // bytes written by the successful attempt
+	Duration   time.Duration // time spent on the file, including retries
+	Retries    int           // attempts made after the first one failed
+	Hash       string        // hex SHA-256 of the copied content, when a ResultHandler is set
+
+	// OverwrittenBytes is the size of the destination file the copy
+	// replaced; SkippedBytes the size of a skipped source file.
+	OverwrittenBytes int64
+	SkippedBytes     int64
 }
 
 // CopySummary represents the aggregate results of a batch copy operation.
 // It provides statistics for reporting progress to users.
 type CopySummary struct {
-	TotalFiles  int
-	Successful  int
-	Failed      int
-	Skipped     int
-	Duration    time.Duration
-	FailedFiles []string
+	TotalFiles int
+	Successful int
+	Failed     int
+	Skipped    int
+	Duration   time.Duration
+	// FailedFiles are the files that could not be copied, with why.
+	FailedFiles []FailedFile
+
+	// TotalBytes counts the bytes written; OverwrittenBytes those of the
+	// destination files they replaced and SkippedBytes those of the files
+	// skipped. A dry run counts what it would write and replace.
+	TotalBytes       int64
+	OverwrittenBytes int64
+	SkippedBytes     int64
+
+	// Corrupt counts the failed files rejected as corrupt images
+	// (Config.ValidateImages).
+	Corrupt int
+
+	// Created lists the files the batch added to the destination (not
+	// those it overwrote), so the batch can be undone.
+	Created []CreatedFile
+}
+
+// CreatedFile is a destination file created by a copy, with its size and
+// modification time right after the copy so an undo can tell whether it
+// was changed since.
+type CreatedFile struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
 }
 
-// ProgressCallback is a function type for reporting copy progress.
-// It receives the current count, total count, current filename, and status.
-type ProgressCallback func(current int, total int, fileName string, status string)
+// ResultHandler receives the full result of every file as soon as it is
+// done. It is called from worker goroutines and must be safe for
+// concurrent use.
+type ResultHandler func(result CopyResult)
 
 // Copier handles file copying operations with support for parallel execution,
 // retry logic, and progress reporting.
 type Copier struct {
-	config  *config.Config
-	results []CopyResult
+	config   *config.Config
+	results  []CopyResult
+	onResult ResultHandler
+	logger   *slog.Logger
+	metrics  Metrics
+	manifest *manifest.Manifest
+	hashes   *batchHashes // nil unless manifests are written
+
+	processors *processor.Chain // nil unless processors are configured
+
+	// Where files are read from and written to; local folders by default.
+	src, dst storage.Storage
+	dstErr   error // why the destination could not be set up
+
+	rootsOnce sync.Once
+	rootDirs  []string
 }
 
 // New creates a new Copier instance with the given configuration.
 // The copier is stateless between copy operations, so the same instance
 // can be reused for multiple copy batches.
+// Sources and destinations given as URLs are read from and written to
+// those servers, and shares with smb credentials are connected here. A
+// source that cannot be reached fails the scan.
 func New(cfg *config.Config) *Copier {
+	src, err := storage.ForSource(cfg)
+	if err != nil {
+		src = storage.Unavailable(err)
+	}
+	dst, err := storage.ForDestination(cfg)
+	if err != nil {
+		dst = storage.Unavailable(err)
+	}
+	// Nothing may be copied without the checks the user configured.
+	processors, perr := newProcessors(cfg)
+	if perr != nil && err == nil {
+		err = perr
+		dst = storage.Unavailable(err)
+	}
 	return &Copier{
-		config:  cfg,
-		results: make([]CopyResult, 0),
+		config:     cfg,
+		results:    make([]CopyResult, 0),
+		logger:     logging.Discard(),
+		metrics:    noMetrics{},
+		src:        src,
+		dst:        dst,
+		dstErr:     err,
+		hashes:     newBatchHashes(cfg),
+		processors: processors,
 	}
 }
 
-// GetFiles retrieves all files from the source directory that match
-// the extension filter (if configured). Only regular files are returned;
-// directories are not included.
-func (c *Copier) GetFiles() ([]string, error) {
-	if !utils.DirExists(c.config.Source) {
-		return nil, fmt.Errorf("source directory does not exist: %s", c.config.Source)
+// Err returns why the destination could not be set up, such as a share
+// refusing the credentials or an unknown processor, or nil. Copying
+// anyway fails every file with that error; callers check it first to
+// report it once.
+func (c *Copier) Err() error {
+	return c.dstErr
+}
+
+// Destination returns the directory this copier writes to.
+func (c *Copier) Destination() string {
+	return c.config.Destination
+}
+
+// Config returns the configuration the copier runs with. Callers must not
+// change it.
+func (c *Copier) Config() *config.Config {
+	return c.config
+}
+
+// OnResult registers fn to receive each file's result during parallel
+// copies. Setting a handler also enables hashing of the copied content,
+// which costs CPU, so it is off unless someone consumes the results.
+func (c *Copier) OnResult(fn ResultHandler) {
+	c.onResult = fn
+}
+
+// SetManifest makes the copier skip files that m shows were already copied
+// unchanged and record in m the files it copies (incremental mode). The
+// caller saves m after the batch.
+func (c *Copier) SetManifest(m *manifest.Manifest) {
+	c.manifest = m
+}
+
+// SetStorage makes the copier read sources from src and write to dst
+// instead of the local file system. Paths in the config are paths in
+// those storages. Watching and waiting for stable files work on local
+// sources only.
+func (c *Copier) SetStorage(src, dst storage.Storage) {
+	c.src = src
+	c.dst = dst
+}
+
+// SetSourceFS makes the copier read sources from fsys, such as an
+// fstest.MapFS in tests, with the configured source as its root.
+// Additional sources must lie below it.
+func (c *Copier) SetSourceFS(fsys fs.FS) {
+	c.src = storage.NewFS(fsys, c.config.Source)
+}
+
+// AddProcessor runs p on every file after the configured processors, for
+// programs embedding the copier with processors of their own.
+func (c *Copier) AddProcessor(p processor.FileProcessor) {
+	if c.processors == nil {
+		c.processors = &processor.Chain{}
 	}
+	c.processors.Add(p, config.Processor{})
+}
 
-	var files []string
-	entries, err := os.ReadDir(c.config.Source)
+// SetLogger makes the copier log per-file results (info), failures (warn)
+// and retry attempts (debug) to l. By default nothing is logged.
+func (c *Copier) SetLogger(l *slog.Logger) {
+	c.logger = l
+}
+
+// GetFiles retrieves all files from the source directories that match
+// the extension filter (if configured) and no exclude pattern. Only regular
+// files are returned; directories are not included, and their contents are
+// only when Recursive is set. When several sources (or a glob matching
+// several folders) are configured, their files are merged and a file
+// reached through more than one source is returned once. With URLsFrom
+// set, the URLs listed in that file are returned instead.
+func (c *Copier) GetFiles() ([]string, error) {
+	return c.GetFilesContext(context.Background())
+}
+
+// GetFilesContext is GetFiles stopping with ctx's error once ctx is
+// cancelled, so a scan of a huge source can be abandoned.
+func (c *Copier) GetFilesContext(ctx context.Context) ([]string, error) {
+	if c.config.URLsFrom != "" {
+		return c.urlFiles()
+	}
+	dirs, err := c.sourceDirs()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read source directory: %w", err)
+		return nil, err
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if name := filepath.Base(path); config.IsChecksumFile(name) || name == VerifiedMarker {
+			return
 		}
-
-		fileName := entry.Name()
-		ext := strings.ToLower(filepath.Ext(fileName))
+		ext := strings.ToLower(filepath.Ext(path))
 
 		// Skip files that don't match the extension filter
 		if c.config.HasExtensionFilter() && !c.config.IsExtensionAllowed(ext) {
-			continue
+			return
+		}
+
+		key := path
+		if abs, err := filepath.Abs(path); err == nil {
+			key = abs
+		}
+		if seen[key] {
+			return
 		}
+		seen[key] = true
+		files = append(files, path)
+	}
 
-		files = append(files, filepath.Join(c.config.Source, fileName))
+	sc := newScanner(ctx)
+	for _, dir := range dirs {
+		// Folders are read concurrently, in the order the file system
+		// returns their entries; sort each source's files as a walk by
+		// name would, so the order is the same on every run.
+		from := len(files)
+		err := c.scanTree(ctx, dir, sc, func(path string) {
+			sc.mu.Lock()
+			defer sc.mu.Unlock()
+			add(path)
+			sc.files = len(files)
+		})
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source directory: %w", err)
+		}
+		slices.SortFunc(files[from:], comparePaths)
 	}
+	sc.done()
 
 	return files, nil
 }
 
+// Filter returns the files that pass the copier's extension filter and
+// exclude patterns, for file lists scanned with broader settings. Excludes
+// are matched against the path relative to the source, including each of
+// its folders, as a recursive scan would.
+func (c *Copier) Filter(files []string) []string {
+	filtered := make([]string, 0, len(files))
+	for _, f := range files {
+		if c.wanted(f) {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered
+}
+
+// wanted reports whether path passes the extension filter and no exclude
+// pattern matches it.
+func (c *Copier) wanted(path string) bool {
+	ext := strings.ToLower(filepath.Ext(baseName(path)))
+	if c.config.HasExtensionFilter() && !c.config.IsExtensionAllowed(ext) {
+		return false
+	}
+	return !c.excluded(path)
+}
+
+// excluded reports whether path or one of its folders below the source
+// matches an exclude pattern.
+func (c *Copier) excluded(path string) bool {
+	if len(c.config.Exclude) == 0 {
+		return false
+	}
+	rel := baseName(path)
+	for _, root := range c.roots() {
+		if r, err := filepath.Rel(root, path); err == nil && filepath.IsLocal(r) {
+			rel = r
+			break
+		}
+	}
+	for p := rel; p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+		if c.config.IsExcluded(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// DestPath returns where sourcePath is copied to. Files are written flat
+// into the destination, except in recursive mode where their folder
+// relative to the source is kept so same-named files in different
+// subfolders do not collide. Files converted to another format get its
+// extension.
+func (c *Copier) DestPath(sourcePath string) string {
+	return c.processors.Rename(baseName(sourcePath), c.destPath(sourcePath))
+}
+
+func (c *Copier) destPath(sourcePath string) string {
+	// The policy was checked by Validate; an unknown one keeps names.
+	policy, _ := sanitize.Parse(c.config.FilenamePolicy)
+	if c.config.Recursive {
+		for _, root := range c.roots() {
+			rel, err := filepath.Rel(root, sourcePath)
+			if err == nil && filepath.IsLocal(rel) {
+				return filepath.Join(c.config.Destination, policy.Path(rel))
+			}
+		}
+	}
+	return filepath.Join(c.config.Destination, policy.Name(baseName(sourcePath)))
+}
+
+// baseName returns the name of the source file at path: its base name, or
+// the name in the path of an http(s) URL.
+func baseName(path string) string {
+	if config.IsHTTP(path) {
+		return storage.URLName(path)
+	}
+	return filepath.Base(path)
+}
+
+// roots returns the expanded source directories, computed once. Files may
+// come from a different copier (group runs scan once for all
+// destinations), so they are derived from the config rather than a scan.
+func (c *Copier) roots() []string {
+	c.rootsOnce.Do(func() {
+		c.rootDirs, _ = c.sourceDirs()
+	})
+	return c.rootDirs
+}
+
+// sourceDirs expands the configured source patterns into directories.
+// A pattern naming an existing directory is used as is, so folder names
+// containing glob characters such as '[' still work.
+func (c *Copier) sourceDirs() ([]string, error) {
+	patterns := c.config.SourcePatterns()
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("source directory does not exist: %s", c.config.Source)
+	}
+
+	var dirs []string
+	for _, pattern := range patterns {
+		if storage.IsDir(c.src, pattern) {
+			dirs = append(dirs, pattern)
+			continue
+		}
+		if !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("source directory does not exist: %s", pattern)
+		}
+
+		// Clean drops the trailing separator of patterns like D:\Camera\2024-*\
+		matches, err := storage.Glob(c.src, filepath.Clean(pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid source pattern %q: %w", pattern, err)
+		}
+		matched := 0
+		for _, m := range matches {
+			if storage.IsDir(c.src, m) {
+				dirs = append(dirs, m)
+				matched++
+			}
+		}
+		if matched == 0 {
+			return nil, fmt.Errorf("no source directory matches: %s", pattern)
+		}
+	}
+	return dirs, nil
+}
+
 // CopyFile copies a single file from source to the configured destination.
-// If overwrite is false and the destination file exists, the copy is skipped.
-// The function ensures the destination directory exists before copying.
+// If overwrite is false and the destination file exists, nothing is copied
+// and ErrDestinationExists is returned. The function ensures the destination directory exists before copying.
 func (c *Copier) CopyFile(ctx context.Context, sourcePath string, overwrite bool) error {
+	_, err := c.copyFile(ctx, sourcePath, overwrite, nil, false)
+	return err
+}
+
+// copyFile implements CopyFile and returns the number of bytes written.
+// When h is not nil the copied content is also written to it, e.g. a hash. With resume
+// set, a destination that can resume uploads continues the partial file
+// an earlier attempt left instead of starting over.
+func (c *Copier) copyFile(ctx context.Context, sourcePath string, overwrite bool, h io.Writer, resume bool) (written int64, err error) {
 	// Check for cancellation before starting
-	if err := ctx.Err(); err != nil {
-		return err
+	if ctx.Err() != nil {
+		return 0, cancelled(ctx)
 	}
 
-	fileName := filepath.Base(sourcePath)
-	destPath := filepath.Join(c.config.Destination, fileName)
+	destPath := c.DestPath(sourcePath)
 
-	// Skip if file exists and we're not overwriting
-	if utils.FileExists(destPath) && !overwrite {
-		return nil
-	}
-
-	// Check if source file is locked by another process
-	if utils.IsFileLocked(sourcePath) {
-		return fmt.Errorf("file is locked by another process")
+	var offset int64
+	resumer, canResume := c.dst.(storage.Resumer)
+	if resume && canResume {
+		if info, err := c.dst.Stat(destPath); err == nil {
+			offset = info.Size()
+		}
 	}
 
-	// Ensure destination directory exists
-	if err := utils.EnsureDir(c.config.Destination); err != nil {
-		return fmt.Errorf("failed to create destination directory: %w", err)
+	// Skip if file exists and we're not overwriting
+	if offset == 0 && storage.Exists(c.dst, destPath) && !overwrite {
+		return 0, ErrDestinationExists
 	}
 
 	// Open source file for reading
-	srcFile, err := os.Open(sourcePath)
+	srcFile, err := c.src.Open(sourcePath)
 	if err != nil {
-		return fmt.Errorf("failed to open source file: %w", err)
+		if err = utils.Classify(err); errors.Is(err, utils.ErrLocked) {
+			return 0, fmt.Errorf("%w: %w", ErrSourceLocked, err)
+		}
+		return 0, fmt.Errorf("failed to open source file: %w", err)
 	}
 	defer func() { _ = srcFile.Close() }()
+	// Closing the source on cancellation also ends a read stuck on a slow
+	// network share or server.
+	stop := context.AfterFunc(ctx, func() { _ = srcFile.Close() })
+	defer stop()
+	var src io.Reader = srcFile
+	if c.processors != nil {
+		f := processor.File{Name: baseName(sourcePath), Source: sourcePath, Dest: destPath}
+		transformed, err := c.processors.Transform(ctx, f, srcFile)
+		if err != nil {
+			return 0, err
+		}
+		defer func() { _ = transformed.Close() }()
+		src = transformed
+	}
 
-	// Create destination file
-	dstFile, err := os.Create(destPath)
+	// Create destination file, and its directory if needed
+	var dstFile storage.File
+	if offset > 0 {
+		// The part already stored is read again only for the hash.
+		var skipped io.Writer = io.Discard
+		if h != nil {
+			skipped = h
+		}
+		if _, err := io.CopyN(skipped, src, offset); err != nil {
+			return 0, fmt.Errorf("failed to read source file: %w", err)
+		}
+		c.logger.Debug("resuming upload", "file", destPath, "offset", offset)
+		dstFile, err = resumer.Resume(destPath, offset)
+	} else {
+		dstFile, err = c.dst.Create(destPath)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
+		return 0, fmt.Errorf("failed to create destination file: %w", err)
 	}
 	defer func() {
+		// A copy cut short by cancellation is of no use: remove it once it
+		// is closed, as a later run starts the file over.
+		if err != nil && ctx.Err() != nil {
+			_ = c.dst.Remove(destPath)
+		}
+	}()
+	defer func() {
+		// A failed copy is given up on where the destination can, rather
+		// than stored truncated.
+		if a, ok := dstFile.(storage.Aborter); ok && err != nil {
+			_ = a.Abort(err)
+			return
+		}
 		// Capture close errors - they may indicate write failures
 		if cerr := dstFile.Close(); cerr != nil && err == nil {
 			err = fmt.Errorf("failed to close destination file: %w", cerr)
 		}
 	}()
 
-	// Copy content using buffered I/O
-	// Only CopyBuffer allows cancellation if we implement a custom reader,
-	// but standard Copy respects context if passed to a wrapper, or we just check before.
-	// For now, we stick to io.Copy but at least we checked context at start.
-	// A more advanced version would use a cancelable reader.
-	_, err = io.Copy(dstFile, srcFile)
+	var dst io.Writer = dstFile
+	if h != nil {
+		dst = io.MultiWriter(dstFile, h)
+	}
+
+	// Copy content using buffered I/O. The reader checks ctx before every
+	// chunk, so cancelling stops a large file within a moment instead of
+	// after it was copied.
+	written, err = io.Copy(dst, &contextReader{ctx: ctx, r: src, read: offset, onRead: progressFrom(ctx)})
+	written += offset
 	if err != nil {
-		return fmt.Errorf("failed to copy file content: %w", err)
+		// Cancelling closes the source, so the read may fail otherwise.
+		if ctx.Err() != nil {
+			return written, cancelled(ctx)
+		}
+		return written, fmt.Errorf("failed to copy file content: %w", err)
 	}
 
 	// Sync to ensure data is flushed to disk
 	// This is important for data integrity, especially on network drives
 	if err := dstFile.Sync(); err != nil {
-		return fmt.Errorf("failed to sync file: %w", err)
+		return written, fmt.Errorf("failed to sync file: %w", err)
 	}
 
-	return nil
+	return written, nil
+}
+
+// contextReader reads from r until ctx is cancelled, passing the bytes
+// read so far to onRead if it is not nil.
+type contextReader struct {
+	ctx    context.Context
+	r      io.Reader
+	read   int64
+	onRead func(read int64)
 }
 
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p)
+	if n > 0 && r.onRead != nil {
+		r.read += int64(n)
+		r.onRead(r.read)
+	}
+	return n, err
+}
+
+// stableInterval and stableTimeout tune the wait for files still being
+// written (Config.StableChecks): the time between checks, and how long a
+// file may keep changing before its copy fails. Tests shorten them.
+var (
+	stableInterval = time.Second
+	stableTimeout  = 10 * time.Minute
+)
+
 // CopyFileWithRetry attempts to copy a file with automatic retries on failure.
 // It uses exponential backoff between retries to handle transient errors
-// like network hiccups or temporary file locks.
+// like network hiccups or temporary file locks. In a batch, a destination
+// gone offline pauses the attempts rather than using them up.
 func (c *Copier) CopyFileWithRetry(ctx context.Context, sourcePath string) CopyResult {
-	fileName := filepath.Base(sourcePath)
-	destPath := filepath.Join(c.config.Destination, fileName)
+	startTime := time.Now()
+	fileName := baseName(sourcePath)
+	destPath := c.DestPath(sourcePath)
+
+	result := CopyResult{
+		FileName:   fileName,
+		SourcePath: sourcePath,
+		DestPath:   destPath,
+	}
+	if filepath.Base(destPath) != fileName {
+		result.OriginalName = fileName
+	}
+	ctx, span := tracer().Start(ctx, "copy file", trace.WithAttributes(
+		attribute.String("file.name", fileName),
+		attribute.String("file.source", sourcePath),
+		attribute.String("file.destination", destPath),
+	))
+	finish := func() CopyResult {
+		if result.Skipped {
+			if info, err := c.src.Stat(sourcePath); err == nil {
+				result.SkippedBytes = info.Size()
+			}
+		}
+		result.Duration = time.Since(startTime)
+		endFileSpan(span, result)
+		return result
+	}
+
+	if c.unchanged(sourcePath) {
+		result.Skipped = true
+		result.Unchanged = true
+		return finish()
+	}
+
+	offline := breakerFrom(ctx)
+	if err := offline.pause(ctx); err != nil {
+		result.Error = err
+		return finish()
+	}
 
 	// Check if we should skip this file
-	if utils.FileExists(destPath) && !c.config.Overwrite {
-		return CopyResult{
-			FileName: fileName,
-			Success:  false,
-			Skipped:  true,
-			Error:    nil,
+	destInfo, err := c.dst.Stat(destPath)
+	existed := err == nil
+	if existed && !c.config.Overwrite {
+		// Remember it so later incremental runs need not look at the
+		// destination again.
+		c.remember(sourcePath, "")
+		result.Skipped = true
+		return finish()
+	}
+
+	if _, local := c.src.(storage.Local); local && c.config.StableChecks > 0 {
+		err := utils.WaitStable(ctx, sourcePath, c.config.StableChecks, stableInterval, stableTimeout)
+		if err != nil {
+			result.Error = err
+			return finish()
 		}
 	}
 
+	f := processor.File{Name: fileName, Source: sourcePath, Dest: destPath, Open: func() (io.ReadCloser, error) {
+		return c.src.Open(sourcePath)
+	}}
+	if err := c.processors.Inspect(ctx, f); err != nil {
+		if errors.Is(err, processor.ErrSkip) {
+			result.Skipped = true
+		} else {
+			result.Error = err
+		}
+		return finish()
+	}
+
 	var lastErr error
+	partial := false // an attempt failed after writing part of the file
 	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
 		// Check context before each attempt
-		if err := ctx.Err(); err != nil {
-			return CopyResult{
-				FileName: fileName,
-				Success:  false,
-				Skipped:  false,
-				Error:    err,
+		if ctx.Err() != nil {
+			result.Error = cancelled(ctx)
+			return finish()
+		}
+		if err := offline.pause(ctx); err != nil {
+			result.Error = err
+			return finish()
+		}
+
+		var h hash.Hash
+		if c.onResult != nil || c.manifest != nil {
+			h = sha256.New()
+		}
+		all := []hash.Hash{h}
+		var hashes map[string]hash.Hash
+		if c.hashes != nil {
+			hashes = c.hashes.newHashes(h)
+			for _, mh := range hashes {
+				all = append(all, mh)
 			}
 		}
 
-		err := c.CopyFile(ctx, sourcePath, c.config.Overwrite)
+		// The partial file of a failed attempt is ours to replace.
+		n, err := c.copyFile(ctx, sourcePath, c.config.Overwrite || partial, hashWriter(all...), partial)
+		partial = partial || n > 0
+		err = utils.Classify(err)
 		if err == nil {
-			return CopyResult{
-				FileName: fileName,
-				Success:  true,
-				Skipped:  false,
-				Error:    nil,
+			if err := c.processors.Check(ctx, f); err != nil {
+				// A rejected copy must not stay where it would be used.
+				_ = c.dst.Remove(destPath)
+				result.Error = err
+				return finish()
 			}
+			result.Success = true
+			result.Bytes = n
+			if h != nil {
+				result.Hash = hex.EncodeToString(h.Sum(nil))
+			}
+			if hashes != nil {
+				c.recordHashes(sourcePath, n, hashes)
+			}
+			c.remember(sourcePath, result.Hash)
+			if existed {
+				result.OverwrittenBytes = destInfo.Size()
+			} else if info, err := c.dst.Stat(destPath); err == nil {
+				result.Created = &CreatedFile{Path: destPath, Size: info.Size(), ModTime: info.ModTime()}
+			}
+			return finish()
 		}
 		lastErr = err
+		if errors.Is(err, ErrDestinationExists) {
+			// Another program wrote the file since it was looked for.
+			c.remember(sourcePath, "")
+			result.Skipped = true
+			return finish()
+		}
+		if offline.offline(err) {
+			// The destination went away, not just this file: try again
+			// once it is back, without using up a retry.
+			attempt--
+			continue
+		}
+		if errors.Is(err, ErrCancelled) || !utils.Retryable(err) {
+			// A missing file or denied access does not fix itself.
+			break
+		}
 
 		// Exponential backoff
 		if attempt < c.config.MaxRetries {
+			c.logger.Debug("copy attempt failed, retrying", "file", fileName,
+				"attempt", attempt+1, "maxRetries", c.config.MaxRetries, "error", err)
 			select {
 			case <-ctx.Done():
-				return CopyResult{
-					FileName: fileName,
-					Success:  false,
-					Skipped:  false,
-					Error:    ctx.Err(),
-				}
+				result.Error = cancelled(ctx)
+				return finish()
 			case <-time.After(time.Duration(attempt+1) * 100 * time.Millisecond):
 				// Continue to next attempt
 			}
+			result.Retries++
+			c.metrics.Retried()
 		}
 	}
 
-	return CopyResult{
-		FileName: fileName,
-		Success:  false,
-		Skipped:  false,
-		Error:    lastErr,
+	if partial {
+		// What the failed attempts left is kept between them to resume
+		// from, but a truncated file must not outlast them: FTP servers
+		// store what arrived before the connection broke.
+		_ = c.dst.Remove(destPath)
 	}
+	result.Error = lastErr
+	return finish()
 }
 
 // CopyFilesParallel copies multiple files concurrently using a worker pool.
-// This version is for CLI mode - it uses a terminal progress bar.
-func (c *Copier) CopyFilesParallel(files []string) CopySummary {
-	startTime := time.Now()
-
-	var (
-		successful int32
-		failed     int32
-		skipped    int32
-		wg         sync.WaitGroup
-		failedMu   sync.Mutex
-	)
-
-	failedFiles := make([]string, 0)
-	semaphore := make(chan struct{}, c.config.Workers)
-
+// This version is for CLI mode - it uses a terminal progress bar. Once
+// ctx is cancelled, the files being copied stop and no new ones start, so
+// Ctrl+C in the CLI ends the batch cleanly.
+func (c *Copier) CopyFilesParallel(ctx context.Context, files []string) CopySummary {
 	// Create terminal progress bar for CLI mode
 	bar := progressbar.NewOptions(len(files),
 		progressbar.OptionEnableColorCodes(true),
@@ -251,137 +770,230 @@ func (c *Copier) CopyFilesParallel(files []string) CopySummary {
 			BarEnd:        "]",
 		}))
 
-	for _, file := range files {
-		wg.Add(1)
-		go func(f string) {
-			defer wg.Done()
-			semaphore <- struct{}{}        // Acquire worker slot
-			defer func() { <-semaphore }() // Release worker slot
-
-			if c.config.DryRun {
-				fmt.Printf("  [DRY-RUN] Would copy: %s\n", filepath.Base(f))
-				atomic.AddInt32(&successful, 1)
-			} else {
-				// CLI mode doesn't have a cancellation context yet, using Background
-				result := c.CopyFileWithRetry(context.Background(), f)
-
-				if result.Success {
-					atomic.AddInt32(&successful, 1)
-				} else if result.Skipped {
-					atomic.AddInt32(&skipped, 1)
-				} else {
-					atomic.AddInt32(&failed, 1)
-					failedMu.Lock()
-					failedFiles = append(failedFiles, fmt.Sprintf("%s: %v", result.FileName, result.Error))
-					failedMu.Unlock()
-				}
-			}
-
-			_ = bar.Add(1)
-		}(file)
-	}
-
-	wg.Wait()
+	events, wait := Dispatch(func(ev Event) {
+		f, ok := ev.(FileFinished)
+		if !ok {
+			return
+		}
+		if c.config.DryRun {
+			printDryRun(f.Result)
+		}
+		_ = bar.Add(1)
+	})
+	summary := c.CopyFilesParallelWithEvents(ctx, files, events)
+	wait()
 	_ = bar.Finish()
 	fmt.Println() // New line after progress bar
-
-	return CopySummary{
-		TotalFiles:  len(files),
-		Successful:  int(successful),
-		Failed:      int(failed),
-		Skipped:     int(skipped),
-		Duration:    time.Since(startTime),
-		FailedFiles: failedFiles,
-	}
+	return summary
 }
 
-// CopyFilesParallelWithEvents copies files concurrently with progress callbacks.
-// This version is designed for GUI mode (Wails) - instead of printing to terminal,
-// it calls the provided callback function to report progress.
+// CopyFilesParallelWithEvents copies files concurrently, sending what
+// happens to events: FileStarted, FileProgress and FileFinished for every
+// file, then BatchFinished. The GUI, the CLI output and the API consume
+// the same events, usually through Dispatch. Sends wait for the consumer,
+// so it must keep reading until BatchFinished; events may be nil.
 //
 // The context parameter allows cancellation of the operation. When cancelled,
-// in-progress copies will complete but no new copies will start.
-func (c *Copier) CopyFilesParallelWithEvents(ctx context.Context, files []string, onProgress ProgressCallback) CopySummary {
+// no new copies start, and the files being copied stop and are removed.
+func (c *Copier) CopyFilesParallelWithEvents(ctx context.Context, files []string, events chan<- Event) CopySummary {
 	startTime := time.Now()
+	ctx, span := c.startBatchSpan(ctx, len(files))
 
 	var (
 		successful int32
 		failed     int32
 		skipped    int32
+		corrupt    int32
 		processed  int32
+		written    int64
+		replaced   int64
+		passed     int64 // bytes of skipped files
 		wg         sync.WaitGroup
-		failedMu   sync.Mutex
+		mu         sync.Mutex // guards failedFiles and created
 	)
 
-	failedFiles := make([]string, 0)
+	failedFiles := make([]FailedFile, 0)
+	var created []CreatedFile
 	semaphore := make(chan struct{}, c.config.Workers)
+	c.metrics.Queued(len(files))
 	total := len(files)
+	var dry *dryRunBatch
+	var offline *breaker
+	if c.config.DryRun {
+		dry = c.newDryRunBatch()
+	} else {
+		offline = c.newBreaker(ctx, events)
+	}
 
-	for _, file := range files {
-		// Check for cancellation before starting new work
+	// Files are handed to the workers one at a time, as slots free up,
+	// so they start in the configured order.
+feed:
+	for i, file := range c.ordered(files) {
+		// Stop starting new work once cancelled, also while waiting for a
+		// worker slot
+		if ctx.Err() != nil {
+			c.metrics.Queued(i - total)
+			break
+		}
 		select {
+		case semaphore <- struct{}{}:
 		case <-ctx.Done():
-			// Context cancelled - stop processing new files
-			break
-		default:
-			// Continue processing
+			c.metrics.Queued(i - total)
+			break feed
 		}
 
 		wg.Add(1)
 		go func(f string) {
 			defer wg.Done()
-
-			// Acquire worker slot (or wait for one to become available)
-			select {
-			case semaphore <- struct{}{}:
-				defer func() { <-semaphore }()
-			case <-ctx.Done():
-				// Cancelled while waiting for a worker slot
-				return
+			defer func() { <-semaphore }()
+			c.startWork()
+			defer c.metrics.Busy(-1)
+
+			send(events, FileStarted{Path: f, Total: total})
+			var result CopyResult
+			if dry != nil {
+				result = c.dryRun(f, dry)
+				c.reportDryRun(result)
+			} else {
+				fctx := withBreaker(ctx, offline)
+				if events != nil {
+					fctx = withProgress(fctx, fileProgress(events, f))
+				}
+				result = c.CopyFileWithRetry(fctx, f)
+				c.reportResult(result)
 			}
 
-			fileName := filepath.Base(f)
+			atomic.AddInt64(&written, result.Bytes)
+			atomic.AddInt64(&replaced, result.OverwrittenBytes)
+			atomic.AddInt64(&passed, result.SkippedBytes)
 			var status string
-
-			if c.config.DryRun {
-				status = "success"
+			if result.Success {
+				status = StatusSuccess
 				atomic.AddInt32(&successful, 1)
+				if result.Created != nil {
+					mu.Lock()
+					created = append(created, *result.Created)
+					mu.Unlock()
+				}
+			} else if result.Skipped {
+				status = StatusSkipped
+				atomic.AddInt32(&skipped, 1)
 			} else {
-				result := c.CopyFileWithRetry(ctx, f)
-
-				if result.Success {
-					status = "success"
-					atomic.AddInt32(&successful, 1)
-				} else if result.Skipped {
-					status = "skipped"
-					atomic.AddInt32(&skipped, 1)
-				} else {
-					status = "failed"
-					atomic.AddInt32(&failed, 1)
-					failedMu.Lock()
-					failedFiles = append(failedFiles, fmt.Sprintf("%s: %v", result.FileName, result.Error))
-					failedMu.Unlock()
+				status = StatusFailed
+				atomic.AddInt32(&failed, 1)
+				if errors.Is(result.Error, imagecheck.ErrCorrupt) {
+					atomic.AddInt32(&corrupt, 1)
 				}
+				failure := c.failedFile(result)
+				mu.Lock()
+				failedFiles = append(failedFiles, failure)
+				mu.Unlock()
 			}
 
-			// Report progress via callback
 			current := int(atomic.AddInt32(&processed, 1))
-			if onProgress != nil {
-				onProgress(current, total, fileName, status)
-			}
+			send(events, FileFinished{Result: result, Status: status, Current: current, Total: total})
 		}(file)
 	}
 
 	wg.Wait()
+	offline.close()
+	c.writeManifests()
 
-	return CopySummary{
+	summary := CopySummary{
 		TotalFiles:  total,
 		Successful:  int(successful),
 		Failed:      int(failed),
 		Skipped:     int(skipped),
 		Duration:    time.Since(startTime),
 		FailedFiles: failedFiles,
+		Corrupt:     int(corrupt),
+		Created:     created,
+
+		TotalBytes:       written,
+		OverwrittenBytes: replaced,
+		SkippedBytes:     passed,
+	}
+	c.commit(&summary)
+	endBatchSpan(span, summary)
+	send(events, BatchFinished{Summary: summary})
+	return summary
+}
+
+// commit stores the files of a destination written only at the end of a
+// batch, such as a zip archive. When that fails, the files copied are
+// lost, so they count as failed.
+func (c *Copier) commit(summary *CopySummary) {
+	committer, ok := c.dst.(storage.Committer)
+	if !ok {
+		return
+	}
+	// Undo removes files, which cannot be done inside an archive.
+	summary.Created = nil
+	if err := committer.Commit(); err != nil {
+		c.logger.Error("destination not written", "destination", c.config.Destination, "error", err)
+		summary.Failed += summary.Successful
+		summary.Successful = 0
+		summary.TotalBytes, summary.OverwrittenBytes = 0, 0
+		summary.FailedFiles = append(summary.FailedFiles, FailedFile{Dest: c.config.Destination, Err: err})
+	}
+}
+
+// reportResult logs result and passes it to the registered ResultHandler.
+func (c *Copier) reportResult(result CopyResult) {
+	switch {
+	case result.Success:
+		c.logger.Info("copied", "file", result.FileName, "bytes", result.Bytes, "duration", result.Duration)
+	case result.Skipped && result.Unchanged:
+		c.logger.Info("skipped", "file", result.FileName, "reason", "unchanged")
+	case result.Skipped:
+		c.logger.Info("skipped", "file", result.FileName, "reason", "exists")
+	default:
+		c.logger.Warn("copy failed", "file", result.FileName, "error", result.Error)
+	}
+	if result.OriginalName != "" {
+		c.logger.Debug("name adapted to the destination", "file", result.OriginalName, "as", filepath.Base(result.DestPath))
+	}
+
+	c.metrics.Done(result)
+	if c.onResult != nil {
+		c.onResult(result)
+	}
+}
+
+// startWork moves a file from the queue to a busy worker in the metrics.
+func (c *Copier) startWork() {
+	c.metrics.Queued(-1)
+	c.metrics.Busy(1)
+}
+
+// unchanged reports whether the incremental manifest shows sourcePath was
+// already copied and has not changed since.
+func (c *Copier) unchanged(sourcePath string) bool {
+	if c.manifest == nil {
+		return false
 	}
+	info, err := c.src.Stat(sourcePath)
+	return err == nil && c.manifest.Unchanged(sourcePath, info)
+}
+
+// remember records in the incremental manifest that sourcePath is now in
+// the destination. hash may be empty when the file was not copied.
+func (c *Copier) remember(sourcePath, hash string) {
+	if c.manifest == nil {
+		return
+	}
+	if info, err := c.src.Stat(sourcePath); err == nil {
+		c.manifest.Record(sourcePath, info, hash)
+	}
+}
+
+// Throughput returns the bytes written per second of the batch, 0 when
+// it took no time.
+func (s CopySummary) Throughput() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.TotalBytes) / s.Duration.Seconds()
 }
 
 // PrintSummary prints a formatted summary of the copy operation to stdout.
@@ -392,7 +1004,13 @@ func (s *CopySummary) PrintSummary() {
 	fmt.Printf("Successful:  %d ✓\n", s.Successful)
 	fmt.Printf("Failed:      %d ✗\n", s.Failed)
 	fmt.Printf("Skipped:     %d ⊘\n", s.Skipped)
+	if s.Corrupt > 0 {
+		fmt.Printf("Corrupt:     %d ⚠\n", s.Corrupt)
+	}
 	fmt.Printf("Duration:    %.2fs\n", s.Duration.Seconds())
+	if s.TotalBytes > 0 {
+		fmt.Printf("Copied:      %s at %s/s\n", utils.FormatBytes(s.TotalBytes), utils.FormatBytes(int64(s.Throughput())))
+	}
 	fmt.Println("==============================")
 
 	if len(s.FailedFiles) > 0 {
@@ -1,12 +1,24 @@
 package copier
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"copy-image/internal/config"
+	"copy-image/internal/logging"
+	"copy-image/internal/manifest"
+	"copy-image/internal/storage"
+	"copy-image/internal/utils"
 )
 
 func TestNew(t *testing.T) {
@@ -92,8 +104,8 @@ func TestCopyFileNoOverwrite(t *testing.T) {
 
 	// Test copy without overwrite
 	err := c.CopyFile(context.Background(), srcFile, false)
-	if err != nil {
-		t.Errorf("CopyFile failed: %v", err)
+	if !errors.Is(err, ErrDestinationExists) {
+		t.Errorf("Expected ErrDestinationExists, got %v", err)
 	}
 
 	// Verify original content was preserved
@@ -199,7 +211,7 @@ func TestCopyFilesParallel(t *testing.T) {
 
 	c := New(cfg)
 
-	summary := c.CopyFilesParallel(filePaths)
+	summary := c.CopyFilesParallel(context.Background(), filePaths)
 
 	if summary.TotalFiles != 3 {
 		t.Errorf("Expected TotalFiles=3, got %d", summary.TotalFiles)
@@ -336,7 +348,7 @@ func TestCopyFilesParallelWithSkip(t *testing.T) {
 
 	c := New(cfg)
 
-	summary := c.CopyFilesParallel([]string{srcFile1, srcFile2})
+	summary := c.CopyFilesParallel(context.Background(), []string{srcFile1, srcFile2})
 
 	if summary.TotalFiles != 2 {
 		t.Errorf("Expected TotalFiles=2, got %d", summary.TotalFiles)
@@ -375,7 +387,7 @@ func TestCopyFilesParallelDryRun(t *testing.T) {
 
 	c := New(cfg)
 
-	summary := c.CopyFilesParallel(filePaths)
+	summary := c.CopyFilesParallel(context.Background(), filePaths)
 
 	if summary.TotalFiles != 2 {
 		t.Errorf("Expected TotalFiles=2, got %d", summary.TotalFiles)
@@ -442,6 +454,24 @@ func TestCopyFileWithRetryFailed(t *testing.T) {
 	}
 }
 
+func TestCopyFileWithRetryPermanentError(t *testing.T) {
+	srcDir := t.TempDir()
+	cfg := &config.Config{
+		Source:      srcDir,
+		Destination: t.TempDir(),
+		Workers:     1,
+		MaxRetries:  3, // 600ms of backoff if it were retried
+	}
+
+	result := New(cfg).CopyFileWithRetry(context.Background(), filepath.Join(srcDir, "nonexistent.txt"))
+	if !errors.Is(result.Error, utils.ErrNotFound) {
+		t.Errorf("Expected a not-found error, got %v", result.Error)
+	}
+	if result.Duration > 300*time.Millisecond {
+		t.Errorf("A missing file was retried (took %v)", result.Duration)
+	}
+}
+
 func TestCopySummaryPrintSummary(t *testing.T) {
 	// Test with no failures
 	summary := &CopySummary{
@@ -450,7 +480,7 @@ func TestCopySummaryPrintSummary(t *testing.T) {
 		Failed:      3,
 		Skipped:     2,
 		Duration:    5 * 1000000000, // 5 seconds in nanoseconds
-		FailedFiles: []string{"file1.txt: error1", "file2.txt: error2"},
+		FailedFiles: []FailedFile{{Path: "file1.txt", Err: errors.New("error1")}, {Path: "file2.txt", Err: errors.New("error2")}},
 	}
 
 	// Just call PrintSummary to ensure it doesn't panic
@@ -465,7 +495,7 @@ func TestCopySummaryPrintSummaryNoFailures(t *testing.T) {
 		Failed:      0,
 		Skipped:     0,
 		Duration:    1 * 1000000000,
-		FailedFiles: []string{},
+		FailedFiles: []FailedFile{},
 	}
 
 	// Should run without panic
@@ -574,7 +604,7 @@ func TestCopyFilesParallelWithMultipleWorkers(t *testing.T) {
 
 	c := New(cfg)
 
-	summary := c.CopyFilesParallel(filePaths)
+	summary := c.CopyFilesParallel(context.Background(), filePaths)
 
 	if summary.TotalFiles != numFiles {
 		t.Errorf("Expected TotalFiles=%d, got %d", numFiles, summary.TotalFiles)
@@ -613,7 +643,7 @@ func TestCopySummaryFields(t *testing.T) {
 		Failed:      3,
 		Skipped:     2,
 		Duration:    2 * 1000000000,
-		FailedFiles: []string{"a.txt", "b.txt"},
+		FailedFiles: []FailedFile{{Path: "a.txt"}, {Path: "b.txt"}},
 	}
 
 	if summary.TotalFiles != 50 {
@@ -648,7 +678,7 @@ func TestCopyFilesParallelEmptyList(t *testing.T) {
 	c := New(cfg)
 
 	// Empty file list
-	summary := c.CopyFilesParallel([]string{})
+	summary := c.CopyFilesParallel(context.Background(), []string{})
 
 	if summary.TotalFiles != 0 {
 		t.Errorf("Expected TotalFiles=0, got %d", summary.TotalFiles)
@@ -832,7 +862,7 @@ func TestCopyFilesParallelWithFailed(t *testing.T) {
 
 	// Include one real file and one non-existent file
 	fakeFile := filepath.Join(srcDir, "nonexistent.txt")
-	summary := c.CopyFilesParallel([]string{realFile, fakeFile})
+	summary := c.CopyFilesParallel(context.Background(), []string{realFile, fakeFile})
 
 	if summary.TotalFiles != 2 {
 		t.Errorf("Expected TotalFiles=2, got %d", summary.TotalFiles)
@@ -846,6 +876,9 @@ func TestCopyFilesParallelWithFailed(t *testing.T) {
 	if len(summary.FailedFiles) != 1 {
 		t.Errorf("Expected 1 failed file, got %d", len(summary.FailedFiles))
 	}
+	if paths := summary.FailedPaths(); len(paths) != 1 || paths[0] != fakeFile {
+		t.Errorf("Expected the path of the failed file, got %v", paths)
+	}
 }
 
 func TestCopyFileLargeContent(t *testing.T) {
@@ -897,7 +930,7 @@ func TestCopySummaryDuration(t *testing.T) {
 		Failed:      0,
 		Skipped:     0,
 		Duration:    5500000000, // 5.5 seconds in nanoseconds
-		FailedFiles: []string{},
+		FailedFiles: []FailedFile{},
 	}
 
 	// Test Duration.Seconds() calculation
@@ -945,3 +978,694 @@ func TestGetFilesWithMixedExtensions(t *testing.T) {
 		t.Errorf("Expected 4 files (.jpg and .jpeg), got %d", len(files))
 	}
 }
+
+func TestCopyFilesParallelCancelled(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+
+	var files []string
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		path := filepath.Join(srcDir, name)
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		files = append(files, path)
+	}
+
+	cfg := &config.Config{
+		Source:      srcDir,
+		Destination: dstDir,
+		Workers:     1,
+		Overwrite:   true,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	summary := New(cfg).CopyFilesParallel(ctx, files)
+
+	if summary.Successful != 0 {
+		t.Errorf("Expected no files copied after cancellation, got %d", summary.Successful)
+	}
+	entries, _ := os.ReadDir(dstDir)
+	if len(entries) != 0 {
+		t.Errorf("Expected empty destination, found %d entries", len(entries))
+	}
+}
+
+func TestOnResultReportsDetails(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	content := []byte("hello world")
+	if err := os.WriteFile(filepath.Join(srcDir, "a.jpg"), content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+	cfg.Workers = 2
+	c := New(cfg)
+
+	var (
+		mu      sync.Mutex
+		results []CopyResult
+	)
+	c.OnResult(func(r CopyResult) {
+		mu.Lock()
+		results = append(results, r)
+		mu.Unlock()
+	})
+
+	files, err := c.GetFiles()
+	if err != nil {
+		t.Fatalf("GetFiles failed: %v", err)
+	}
+	c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(results))
+	}
+	r := results[0]
+	// sha256("hello world")
+	const expectedHash = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	if !r.Success || r.Bytes != int64(len(content)) || r.Hash != expectedHash {
+		t.Errorf("Unexpected result: %+v", r)
+	}
+	if r.DestPath != filepath.Join(dstDir, "a.jpg") {
+		t.Errorf("Expected dest path %s, got %s", filepath.Join(dstDir, "a.jpg"), r.DestPath)
+	}
+
+	// A second run skips the file and still reports it.
+	results = nil
+	c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+	if len(results) != 1 || !results[0].Skipped {
+		t.Errorf("Expected one skipped result, got %+v", results)
+	}
+}
+
+func TestGetFilesMultipleSources(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"2024-01", "2024-02", "2023-12"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, dir+".jpg"), []byte(dir), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		source      string
+		sources     []string
+		expected    int
+		expectError bool
+	}{
+		{"glob", filepath.Join(root, "2024-*"), nil, 2, false},
+		{"glob with trailing separator", filepath.Join(root, "2024-*") + string(filepath.Separator), nil, 2, false},
+		{"glob plus directory", filepath.Join(root, "2024-*"), []string{filepath.Join(root, "2023-12")}, 3, false},
+		{"overlapping sources are de-duplicated", filepath.Join(root, "2024-01"), []string{filepath.Join(root, "2024-*")}, 2, false},
+		{"glob without matches", filepath.Join(root, "1999-*"), nil, 0, true},
+		{"missing directory", filepath.Join(root, "2024-01"), []string{filepath.Join(root, "nope")}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Source = tt.source
+			cfg.Sources = tt.sources
+			files, err := New(cfg).GetFiles()
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(files) != tt.expected {
+				t.Errorf("Expected %d files, got %d: %v", tt.expected, len(files), files)
+			}
+		})
+	}
+}
+
+func TestCopierLogsResults(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "a.jpg"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+	c := New(cfg)
+
+	var buf bytes.Buffer
+	c.SetLogger(logging.New(&buf, logging.Verbose, false))
+	c.CopyFilesParallelWithEvents(context.Background(), []string{filepath.Join(srcDir, "a.jpg")}, nil)
+
+	if !strings.Contains(buf.String(), "msg=copied file=a.jpg") {
+		t.Errorf("Expected a per-file log line, got %q", buf.String())
+	}
+}
+
+func TestGetFilesRecursiveWithExclude(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	for _, name := range []string{
+		"top.jpg",
+		"skip.tmp",
+		filepath.Join("2024", "a.jpg"),
+		filepath.Join("2024", "nested", "top.jpg"),
+		filepath.Join(".thumbnails", "t.jpg"),
+	} {
+		path := filepath.Join(srcDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+	cfg.Exclude = []string{"*.tmp", ".thumbnails"}
+
+	flat, err := New(cfg).GetFiles()
+	if err != nil {
+		t.Fatalf("GetFiles failed: %v", err)
+	}
+	if len(flat) != 1 {
+		t.Errorf("Expected only top.jpg without recursion, got %v", flat)
+	}
+
+	cfg.Recursive = true
+	c := New(cfg)
+	files, err := c.GetFiles()
+	if err != nil {
+		t.Fatalf("GetFiles failed: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 files, got %v", files)
+	}
+
+	summary := c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+	if summary.Successful != 3 {
+		t.Errorf("Expected 3 files copied, got %+v", summary)
+	}
+	// Same-named files in different folders must not collide.
+	for _, name := range []string{"top.jpg", filepath.Join("2024", "nested", "top.jpg"), filepath.Join("2024", "a.jpg")} {
+		if _, err := os.Stat(filepath.Join(dstDir, name)); err != nil {
+			t.Errorf("Expected %s in destination: %v", name, err)
+		}
+	}
+}
+
+func TestIncrementalManifest(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+	cfg.Incremental = true
+	manifests := t.TempDir()
+
+	runOnce := func() CopySummary {
+		t.Helper()
+		m, err := manifest.Open(manifests, dstDir)
+		if err != nil {
+			t.Fatalf("Failed to open manifest: %v", err)
+		}
+		c := New(cfg)
+		c.SetManifest(m)
+		files, err := c.GetFiles()
+		if err != nil {
+			t.Fatal(err)
+		}
+		summary := c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+		if err := m.Save(); err != nil {
+			t.Fatalf("Failed to save manifest: %v", err)
+		}
+		return summary
+	}
+
+	if s := runOnce(); s.Successful != 2 {
+		t.Fatalf("Expected the first run to copy everything, got %+v", s)
+	}
+
+	// The destination is append-only: copied files are moved away.
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		if err := os.Remove(filepath.Join(dstDir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := runOnce(); s.Successful != 0 || s.Skipped != 2 {
+		t.Errorf("Expected unchanged files to be skipped, got %+v", s)
+	}
+
+	if err := os.WriteFile(filepath.Join(srcDir, "a.jpg"), []byte("edited a.jpg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if s := runOnce(); s.Successful != 1 || s.Skipped != 1 {
+		t.Errorf("Expected only the changed file to be copied, got %+v", s)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "b.jpg")); !os.IsNotExist(err) {
+		t.Error("Expected the unchanged file not to be copied again")
+	}
+}
+
+func TestCopySummaryCreated(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	for _, name := range []string{"new.jpg", "old.jpg"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, "old.jpg"), []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+	cfg.Overwrite = true
+	c := New(cfg)
+	files, err := c.GetFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary := c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+	if summary.Successful != 2 {
+		t.Fatalf("Expected both files to be copied, got %+v", summary)
+	}
+	if len(summary.Created) != 1 || summary.Created[0].Path != filepath.Join(dstDir, "new.jpg") || summary.Created[0].Size != int64(len("new.jpg")) {
+		t.Errorf("Expected only the new file to be listed as created, got %+v", summary.Created)
+	}
+}
+
+func TestFilenamePolicy(t *testing.T) {
+	if filepath.Separator == '\\' {
+		t.Skip("the source names are illegal on Windows")
+	}
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(srcDir, "trip: day 1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	source := filepath.Join(srcDir, "trip: day 1", "12:30?.jpg")
+	if err := os.WriteFile(source, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+	cfg.Recursive = true
+	cfg.FilenamePolicy = "windows"
+	c := New(cfg)
+
+	result := c.CopyFileWithRetry(context.Background(), source)
+	want := filepath.Join(dstDir, "trip_ day 1", "12_30_.jpg")
+	if !result.Success || result.DestPath != want {
+		t.Fatalf("CopyFileWithRetry() = %+v, want a copy to %s", result, want)
+	}
+	if result.OriginalName != "12:30?.jpg" {
+		t.Errorf("OriginalName = %q, want the source name", result.OriginalName)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Errorf("Expected the sanitized copy: %v", err)
+	}
+}
+
+func TestStableChecksWaitForWriter(t *testing.T) {
+	defer func(interval time.Duration) { stableInterval = interval }(stableInterval)
+	stableInterval = 20 * time.Millisecond
+
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	source := filepath.Join(srcDir, "a.mov")
+	f, err := os.Create(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { _ = f.Close() }()
+		for range 5 {
+			_, _ = f.WriteString("chunk")
+			time.Sleep(15 * time.Millisecond)
+		}
+	}()
+
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+	cfg.StableChecks = 3
+	result := New(cfg).CopyFileWithRetry(context.Background(), source)
+	<-done
+	if !result.Success {
+		t.Fatalf("CopyFileWithRetry() = %+v", result)
+	}
+	if result.Bytes != 25 {
+		t.Errorf("Copied %d bytes, want the complete file (25)", result.Bytes)
+	}
+}
+
+func TestCopyWithMemoryStorage(t *testing.T) {
+	src, dst := storage.NewMemory(), storage.NewMemory()
+	srcDir := filepath.Join(string(filepath.Separator), "card")
+	dstDir := filepath.Join(string(filepath.Separator), "backup")
+	for _, name := range []string{"a.jpg", filepath.Join("DCIM", "b.jpg"), "notes.txt"} {
+		if err := src.WriteFile(filepath.Join(srcDir, name), []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dst.WriteFile(filepath.Join(dstDir, "a.jpg"), []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+	cfg.Recursive = true
+	cfg.Extensions = []string{".jpg"}
+	c := New(cfg)
+	c.SetStorage(src, dst)
+
+	files, err := c.GetFiles()
+	if err != nil {
+		t.Fatalf("GetFiles failed: %v", err)
+	}
+	summary := c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+	if summary.Successful != 1 || summary.Skipped != 1 || summary.Failed != 0 {
+		t.Errorf("Expected 1 copied and 1 skipped, got %+v", summary)
+	}
+	r, err := dst.Open(filepath.Join(dstDir, "DCIM", "b.jpg"))
+	if err != nil {
+		t.Fatalf("Expected the copy in the destination storage: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	report, err := c.Verify(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if report.OK != 1 || len(report.Mismatched) != 1 {
+		t.Errorf("Expected b.jpg intact and a.jpg different, got %+v", report)
+	}
+}
+
+// droppingStorage is a Memory destination whose first upload breaks off
+// after limit bytes, keeping what arrived, like an FTP server whose
+// connection dropped. It can resume uploads.
+type droppingStorage struct {
+	*storage.Memory
+	limit     int
+	dropped   bool
+	resumedAt int64
+}
+
+func (d *droppingStorage) Create(path string) (storage.File, error) {
+	if d.dropped {
+		return d.Memory.Create(path)
+	}
+	d.dropped = true
+	return &droppingFile{m: d.Memory, path: path, limit: d.limit}, nil
+}
+
+func (d *droppingStorage) Resume(path string, offset int64) (storage.File, error) {
+	d.resumedAt = offset
+	r, err := d.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	f := &droppingFile{m: d.Memory, path: path, limit: -1}
+	if _, err := f.buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+type droppingFile struct {
+	m     *storage.Memory
+	path  string
+	buf   bytes.Buffer
+	limit int // -1 for no limit
+}
+
+func (f *droppingFile) Write(p []byte) (int, error) {
+	if f.limit >= 0 && f.buf.Len()+len(p) > f.limit {
+		n, _ := f.buf.Write(p[:f.limit-f.buf.Len()])
+		return n, &utils.FileError{Kind: utils.ErrNetwork, Err: errors.New("connection reset")}
+	}
+	return f.buf.Write(p)
+}
+
+func (f *droppingFile) Sync() error { return nil }
+
+func (f *droppingFile) Close() error { return f.m.WriteFile(f.path, f.buf.Bytes()) }
+
+func TestCopyResumesDroppedUpload(t *testing.T) {
+	src := storage.NewMemory()
+	dst := &droppingStorage{Memory: storage.NewMemory(), limit: 400}
+	srcPath := filepath.Join(string(filepath.Separator), "card", "big.jpg")
+	content := bytes.Repeat([]byte("0123456789"), 100)
+	if err := src.WriteFile(srcPath, content); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = filepath.Dir(srcPath)
+	cfg.Destination = filepath.Join(string(filepath.Separator), "lab")
+	c := New(cfg)
+	c.SetStorage(src, dst)
+	c.OnResult(func(CopyResult) {})
+
+	result := c.CopyFileWithRetry(context.Background(), srcPath)
+	if !result.Success || result.Bytes != int64(len(content)) {
+		t.Fatalf("Expected the copy to succeed with %d bytes, got %+v", len(content), result)
+	}
+	if dst.resumedAt != 400 {
+		t.Errorf("Expected the upload to resume at 400, got %d", dst.resumedAt)
+	}
+	r, err := dst.Open(c.DestPath(srcPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	var got bytes.Buffer
+	_, _ = got.ReadFrom(r)
+	if !bytes.Equal(got.Bytes(), content) {
+		t.Errorf("Expected the resumed file to match the source, got %d bytes", got.Len())
+	}
+	sum := sha256.Sum256(content)
+	if result.Hash != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the hash of the whole file, got %s", result.Hash)
+	}
+}
+
+func TestFailedUploadLeavesNoPartialFile(t *testing.T) {
+	src := storage.NewMemory()
+	// The dropped upload is stored up to where it broke, as FTP servers do.
+	dst := &droppingStorage{Memory: storage.NewMemory(), limit: 400}
+	srcPath := filepath.Join(string(filepath.Separator), "card", "big.jpg")
+	if err := src.WriteFile(srcPath, bytes.Repeat([]byte("0123456789"), 100)); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = filepath.Dir(srcPath)
+	cfg.Destination = filepath.Join(string(filepath.Separator), "lab")
+	cfg.MaxRetries = 0
+	c := New(cfg)
+	c.SetStorage(src, dst)
+
+	result := c.CopyFileWithRetry(context.Background(), srcPath)
+	if result.Success {
+		t.Fatal("Expected the copy to fail")
+	}
+	if storage.Exists(dst, c.DestPath(srcPath)) {
+		t.Error("Expected the partial upload to be removed after the last attempt")
+	}
+}
+
+// cancellingStorage is a Memory destination that cancels the copy once the
+// first chunk of a file was written, like a user pressing Cancel halfway.
+type cancellingStorage struct {
+	*storage.Memory
+	cancel context.CancelFunc
+}
+
+func (s *cancellingStorage) Create(path string) (storage.File, error) {
+	f, err := s.Memory.Create(path)
+	return cancellingFile{f, s.cancel}, err
+}
+
+type cancellingFile struct {
+	storage.File
+	cancel context.CancelFunc
+}
+
+func (f cancellingFile) Write(p []byte) (int, error) {
+	f.cancel()
+	return f.File.Write(p)
+}
+
+func TestCancelStopsFileInFlight(t *testing.T) {
+	src := storage.NewMemory()
+	ctx, cancel := context.WithCancel(context.Background())
+	dst := &cancellingStorage{Memory: storage.NewMemory(), cancel: cancel}
+	srcPath := filepath.Join(string(filepath.Separator), "card", "clip.mp4")
+	if err := src.WriteFile(srcPath, bytes.Repeat([]byte("x"), 1<<20)); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = filepath.Dir(srcPath)
+	cfg.Destination = filepath.Join(string(filepath.Separator), "backup")
+	c := New(cfg)
+	c.SetStorage(src, dst)
+
+	summary := c.CopyFilesParallelWithEvents(ctx, []string{srcPath}, nil)
+	if summary.Successful != 0 || summary.Failed != 1 {
+		t.Errorf("Expected the file to stop, got %+v", summary)
+	}
+	if storage.Exists(dst, c.DestPath(srcPath)) {
+		t.Error("Expected the partial copy to be removed")
+	}
+}
+
+func TestCopyFromFS(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Source = filepath.Join(string(filepath.Separator), "card")
+	cfg.Destination = filepath.Join(string(filepath.Separator), "backup")
+	cfg.Recursive = true
+	cfg.Extensions = []string{".jpg"}
+	c := New(cfg)
+	dst := storage.NewMemory()
+	c.SetStorage(nil, dst)
+	c.SetSourceFS(fstest.MapFS{
+		"a.jpg":          {Data: []byte("a")},
+		"DCIM/b.jpg":     {Data: []byte("b")},
+		"DCIM/notes.txt": {Data: []byte("n")},
+	})
+
+	files, err := c.GetFiles()
+	if err != nil || len(files) != 2 {
+		t.Fatalf("Expected the 2 JPEGs, got %v, %v", files, err)
+	}
+	summary := c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+	if summary.Successful != 2 {
+		t.Errorf("Expected 2 copies, got %+v", summary)
+	}
+	if !storage.Exists(dst, filepath.Join(cfg.Destination, "DCIM", "b.jpg")) {
+		t.Error("Expected the subfolder copied")
+	}
+}
+
+func TestGetFilesContextCancelled(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(srcDir, "DCIM"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "DCIM", "a.jpg"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, recursive := range []bool{false, true} {
+		cfg := &config.Config{Source: srcDir, Destination: t.TempDir(), Recursive: recursive}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := New(cfg).GetFilesContext(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("recursive=%v: expected context.Canceled, got %v", recursive, err)
+		}
+	}
+}
+
+func TestCopySummaryBytes(t *testing.T) {
+	root := string(filepath.Separator)
+	card, backup := filepath.Join(root, "card"), filepath.Join(root, "backup")
+	for _, overwrite := range []bool{false, true} {
+		for _, dryRun := range []bool{false, true} {
+			src, dst := storage.NewMemory(), storage.NewMemory()
+			_ = src.WriteFile(filepath.Join(card, "new.jpg"), []byte("12345"))
+			_ = src.WriteFile(filepath.Join(card, "old.jpg"), []byte("1234567"))
+			_ = dst.WriteFile(filepath.Join(backup, "old.jpg"), []byte("12"))
+
+			cfg := config.DefaultConfig()
+			cfg.Source, cfg.Destination = card, backup
+			cfg.Overwrite, cfg.DryRun = overwrite, dryRun
+			c := New(cfg)
+			c.SetStorage(src, dst)
+
+			summary := c.CopyFilesParallelWithEvents(context.Background(), []string{filepath.Join(card, "new.jpg"), filepath.Join(card, "old.jpg")}, nil)
+			want := CopySummary{TotalBytes: 5, SkippedBytes: 7}
+			if overwrite {
+				want = CopySummary{TotalBytes: 12, OverwrittenBytes: 2}
+			}
+			if summary.TotalBytes != want.TotalBytes || summary.OverwrittenBytes != want.OverwrittenBytes || summary.SkippedBytes != want.SkippedBytes {
+				t.Errorf("overwrite=%v dryRun=%v: expected %d written, %d overwritten, %d skipped bytes, got %d, %d, %d", overwrite, dryRun,
+					want.TotalBytes, want.OverwrittenBytes, want.SkippedBytes, summary.TotalBytes, summary.OverwrittenBytes, summary.SkippedBytes)
+			}
+		}
+	}
+	if got := (CopySummary{TotalBytes: 3000, Duration: 2 * time.Second}).Throughput(); got != 1500 {
+		t.Errorf("Expected 1500 bytes/s, got %v", got)
+	}
+}
+
+// abortingStorage is a Memory destination whose files fail to write, like
+// an upload whose connection broke, and tell whether they were aborted.
+type abortingStorage struct {
+	*storage.Memory
+	aborted, closed bool
+}
+
+func (s *abortingStorage) Create(path string) (storage.File, error) {
+	return abortingFile{s}, nil
+}
+
+type abortingFile struct{ s *abortingStorage }
+
+func (f abortingFile) Write(p []byte) (int, error) { return 0, os.ErrPermission }
+func (f abortingFile) Sync() error                 { return nil }
+func (f abortingFile) Close() error                { f.s.closed = true; return nil }
+func (f abortingFile) Abort(error) error           { f.s.aborted = true; return nil }
+
+func TestFailedCopyAbortsUpload(t *testing.T) {
+	src := storage.NewMemory()
+	dst := &abortingStorage{Memory: storage.NewMemory()}
+	srcPath := filepath.Join(string(filepath.Separator), "card", "a.jpg")
+	if err := src.WriteFile(srcPath, []byte("photo")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = filepath.Dir(srcPath)
+	cfg.Destination = filepath.Join(string(filepath.Separator), "backup")
+	c := New(cfg)
+	c.SetStorage(src, dst)
+
+	if err := c.CopyFile(context.Background(), srcPath, false); err == nil {
+		t.Fatal("Expected the copy to fail")
+	}
+	if !dst.aborted || dst.closed {
+		t.Errorf("Expected the upload aborted rather than stored, got aborted=%v closed=%v", dst.aborted, dst.closed)
+	}
+}
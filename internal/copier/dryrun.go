@@ -0,0 +1,155 @@
+package copier
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+
+	"copy-image/internal/utils"
+)
+
+// Actions a dry-run reports for a file (CopyResult.Action).
+const (
+	// ActionCopy: the file is new to the destination.
+	ActionCopy = "copy"
+	// ActionOverwrite: the file replaces one at the destination.
+	ActionOverwrite = "overwrite"
+	// ActionSkip: the file is at the destination already, or unchanged
+	// since the last incremental copy.
+	ActionSkip = "skip"
+	// ActionConflict: another file of the batch goes to the same
+	// destination, e.g. IMG_0001.JPG from two cards copied flat. The real
+	// copy would skip the second one, or overwrite the first with it.
+	ActionConflict = "conflict"
+)
+
+// dryRunBatch is what the files of a dry-run share: the destinations
+// taken so far, to find conflicts, and the space they would use.
+type dryRunBatch struct {
+	mu      sync.Mutex
+	claimed map[string]string // destination path → source path
+	free    int64             // -1 when unknown
+	used    int64
+}
+
+func (c *Copier) newDryRunBatch() *dryRunBatch {
+	return &dryRunBatch{claimed: make(map[string]string), free: c.freeBytes()}
+}
+
+// dryRun decides, as CopyFileWithRetry would, what copying sourcePath
+// would do, without writing anything: whether it is unchanged or missing,
+// whether it is renamed, conflicts with another file of the batch, would
+// skip or overwrite an existing file, and whether it fits. Processors are
+// not asked, since some read whole files to decide.
+func (c *Copier) dryRun(sourcePath string, batch *dryRunBatch) CopyResult {
+	result := CopyResult{
+		FileName:   baseName(sourcePath),
+		SourcePath: sourcePath,
+		DestPath:   c.DestPath(sourcePath),
+	}
+	if filepath.Base(result.DestPath) != result.FileName {
+		result.OriginalName = result.FileName
+	}
+
+	info, err := c.src.Stat(sourcePath)
+	if err != nil {
+		result.Error = fmt.Errorf("failed to open source file: %w", utils.Classify(err))
+		return result
+	}
+	if c.manifest != nil && c.manifest.Unchanged(sourcePath, info) {
+		result.Action, result.Skipped, result.Unchanged = ActionSkip, true, true
+		result.SkippedBytes = info.Size()
+		return result
+	}
+
+	batch.mu.Lock()
+	other, conflict := batch.claimed[result.DestPath]
+	if !conflict {
+		batch.claimed[result.DestPath] = sourcePath
+	}
+	batch.mu.Unlock()
+
+	var existing fs.FileInfo
+	if !conflict {
+		existing, _ = c.dst.Stat(result.DestPath)
+	}
+	switch {
+	case conflict:
+		// The file first copied there is the one found at the destination.
+		c.logger.Warn("would conflict", "file", result.FileName, "with", other, "destination", result.DestPath)
+		result.Action = ActionConflict
+		if !c.config.Overwrite {
+			result.Skipped, result.SkippedBytes = true, info.Size()
+			return result
+		}
+	case existing != nil && !c.config.Overwrite:
+		result.Action, result.Skipped, result.SkippedBytes = ActionSkip, true, info.Size()
+		return result
+	case existing != nil:
+		result.Action, result.OverwrittenBytes = ActionOverwrite, existing.Size()
+	default:
+		result.Action = ActionCopy
+	}
+
+	// Overwritten files count in full, as in Plan: the old file is only
+	// gone once the new one is written.
+	batch.mu.Lock()
+	fits := batch.free < 0 || batch.used+info.Size() <= batch.free
+	if fits {
+		batch.used += info.Size()
+	}
+	batch.mu.Unlock()
+	if !fits {
+		result.Error = ErrInsufficientSpace
+		return result
+	}
+
+	result.Success = true
+	result.Bytes = info.Size()
+	return result
+}
+
+// reportDryRun logs and reports what a dry-run found the copy of a file
+// would do.
+func (c *Copier) reportDryRun(result CopyResult) {
+	switch {
+	case result.Error != nil:
+		c.logger.Warn("would fail", "file", result.FileName, "error", result.Error)
+	case result.Skipped && result.Unchanged:
+		c.logger.Info("would skip", "file", result.FileName, "reason", "unchanged")
+	case result.Skipped:
+		c.logger.Info("would skip", "file", result.FileName, "reason", "exists")
+	case result.Action == ActionConflict:
+		c.logger.Info("would overwrite", "file", result.FileName, "bytes", result.Bytes, "reason", "conflict")
+	default:
+		c.logger.Info("would "+result.Action, "file", result.FileName, "bytes", result.Bytes)
+	}
+	if result.OriginalName != "" {
+		c.logger.Info("would rename", "file", result.OriginalName, "as", filepath.Base(result.DestPath))
+	}
+	if c.onResult != nil {
+		c.onResult(result)
+	}
+}
+
+// printDryRun prints the line of the CLI for what a dry-run found the
+// copy of a file would do. Files skipped as usual are not listed.
+func printDryRun(result CopyResult) {
+	name := result.FileName
+	if result.OriginalName != "" {
+		name += " as " + filepath.Base(result.DestPath)
+	}
+	switch {
+	case result.Error != nil:
+		fmt.Printf("  [DRY-RUN] Would fail: %s: %v\n", name, result.Error)
+	case result.Action == ActionConflict && result.Skipped:
+		fmt.Printf("  [DRY-RUN] Would skip, same destination as another file: %s\n", name)
+	case result.Action == ActionConflict:
+		fmt.Printf("  [DRY-RUN] Would overwrite another file of the batch: %s\n", name)
+	case result.Action == ActionOverwrite:
+		fmt.Printf("  [DRY-RUN] Would overwrite: %s\n", name)
+	case result.Action == ActionCopy:
+		fmt.Printf("  [DRY-RUN] Would copy: %s\n", name)
+	}
+}
@@ -0,0 +1,104 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"copy-image/internal/config"
+)
+
+func TestDryRunActions(t *testing.T) {
+	root, dstDir := t.TempDir(), t.TempDir()
+	write := func(path, data string) string {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	cardA, cardB := filepath.Join(root, "a"), filepath.Join(root, "b")
+	files := []string{
+		write(filepath.Join(cardA, "new.jpg"), "new"),
+		write(filepath.Join(cardA, "old.jpg"), "old"),
+		write(filepath.Join(cardA, "same.jpg"), "first"),
+		write(filepath.Join(cardB, "same.jpg"), "second"),
+		filepath.Join(cardA, "gone.jpg"),
+	}
+	write(filepath.Join(dstDir, "old.jpg"), "there")
+
+	tests := []struct {
+		name      string
+		overwrite bool
+		want      map[string]string // source → action, "" for an error
+		success   int
+		skipped   int
+	}{
+		{"keep existing", false, map[string]string{
+			"a/new.jpg": ActionCopy, "a/old.jpg": ActionSkip, "a/same.jpg": ActionCopy, "b/same.jpg": ActionConflict, "a/gone.jpg": "",
+		}, 2, 2},
+		{"overwrite", true, map[string]string{
+			"a/new.jpg": ActionCopy, "a/old.jpg": ActionOverwrite, "a/same.jpg": ActionCopy, "b/same.jpg": ActionConflict, "a/gone.jpg": "",
+		}, 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Source: cardA, Sources: []string{cardB}, Destination: dstDir, Workers: 1, Order: config.OrderName, DryRun: true, Overwrite: tt.overwrite}
+			c := New(cfg)
+			got := map[string]string{}
+			c.OnResult(func(r CopyResult) {
+				rel, _ := filepath.Rel(root, r.SourcePath)
+				got[filepath.ToSlash(rel)] = r.Action
+			})
+			summary := c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+
+			for source, action := range tt.want {
+				if got[source] != action {
+					t.Errorf("%s: expected %q, got %q", source, action, got[source])
+				}
+			}
+			if summary.Successful != tt.success || summary.Skipped != tt.skipped || summary.Failed != 1 {
+				t.Errorf("Unexpected summary %+v", summary)
+			}
+		})
+	}
+
+	// Nothing was written.
+	entries, err := os.ReadDir(dstDir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected only the file already there, got %v (%v)", entries, err)
+	}
+}
+
+func TestDryRunSpace(t *testing.T) {
+	srcDir := t.TempDir()
+	var files []string
+	for _, name := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+		path := filepath.Join(srcDir, name)
+		if err := os.WriteFile(path, []byte("12345"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	c := New(&config.Config{Source: srcDir, Destination: t.TempDir(), Workers: 1, DryRun: true})
+	batch := &dryRunBatch{claimed: map[string]string{}, free: 12}
+
+	var fits int
+	for _, f := range files {
+		result := c.dryRun(f, batch)
+		switch {
+		case result.Success:
+			fits++
+		case !errors.Is(result.Error, ErrInsufficientSpace):
+			t.Errorf("Expected %s not to fit, got %v", result.FileName, result.Error)
+		}
+	}
+	if fits != 2 {
+		t.Errorf("Expected 2 files to fit in 12 bytes, got %d", fits)
+	}
+}
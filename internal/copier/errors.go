@@ -0,0 +1,78 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"copy-image/internal/utils"
+)
+
+// Errors of a copy, matched with errors.Is on the errors of CopyFile and
+// VerifyFile and on CopyResult.Error, so callers branch on what went wrong
+// rather than on messages. The kinds of utils.Classify, such as
+// utils.ErrNetwork, are matched the same way.
+var (
+	// ErrDestinationExists is returned by CopyFile without overwrite for a
+	// file already at the destination. CopyFileWithRetry reports such
+	// files as Skipped instead.
+	ErrDestinationExists = errors.New("destination file exists")
+	// ErrSourceLocked is matched when another program holds the source
+	// file open, e.g. an editor or a camera tool still importing it.
+	ErrSourceLocked = errors.New("source file is locked")
+	// ErrChecksumMismatch is matched when a copy's content differs from its
+	// source.
+	ErrChecksumMismatch = errors.New("copy differs from the source")
+	// ErrCancelled is matched when the copy was stopped by cancelling its
+	// context; context.Canceled is matched too.
+	ErrCancelled = errors.New("copy cancelled")
+	// ErrInsufficientSpace is matched when the destination is full, or a
+	// dry-run found a file would not fit.
+	ErrInsufficientSpace = utils.ErrDiskFull
+	// ErrDestinationOffline is matched when the destination went offline
+	// during a batch and did not come back within config.OfflineWait.
+	ErrDestinationOffline = errors.New("destination is offline")
+)
+
+// Error codes for frontends, which get errors as text (ErrorCode).
+const (
+	CodeExists     = "exists"
+	CodeLocked     = "locked"
+	CodeChecksum   = "checksum"
+	CodeCancelled  = "cancelled"
+	CodeNoSpace    = "no-space"
+	CodeNotFound   = "not-found"
+	CodePermission = "permission"
+	CodeNetwork    = "network"
+	CodeOffline    = "offline"
+)
+
+// ErrorCode returns the code of the kind of err, such as CodeNoSpace, or
+// "" for nil and unknown errors.
+func ErrorCode(err error) string {
+	for _, k := range []struct {
+		err  error
+		code string
+	}{
+		{ErrCancelled, CodeCancelled},
+		{ErrDestinationExists, CodeExists},
+		{ErrSourceLocked, CodeLocked},
+		{utils.ErrLocked, CodeLocked},
+		{ErrChecksumMismatch, CodeChecksum},
+		{ErrInsufficientSpace, CodeNoSpace},
+		{utils.ErrNotFound, CodeNotFound},
+		{utils.ErrPermission, CodePermission},
+		{ErrDestinationOffline, CodeOffline},
+		{utils.ErrNetwork, CodeNetwork},
+	} {
+		if errors.Is(err, k.err) {
+			return k.code
+		}
+	}
+	return ""
+}
+
+// cancelled returns the error of a copy stopped because ctx was cancelled.
+func cancelled(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrCancelled, context.Cause(ctx))
+}
@@ -0,0 +1,135 @@
+package copier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+	"copy-image/internal/utils"
+)
+
+// lockedStorage is a Memory source whose files another program holds.
+type lockedStorage struct {
+	*storage.Memory
+}
+
+func (lockedStorage) Open(path string) (io.ReadCloser, error) {
+	return nil, &utils.FileError{Kind: utils.ErrLocked, Err: errors.New("sharing violation")}
+}
+
+// fullStorage is a Memory destination with no space left.
+type fullStorage struct {
+	*storage.Memory
+}
+
+func (s fullStorage) Create(path string) (storage.File, error) {
+	f, err := s.Memory.Create(path)
+	return fullFile{f}, err
+}
+
+type fullFile struct {
+	storage.File
+}
+
+func (fullFile) Write(p []byte) (int, error) {
+	return 0, &utils.FileError{Kind: utils.ErrDiskFull, Err: errors.New("no space left on device")}
+}
+
+func TestCopyErrors(t *testing.T) {
+	srcPath := filepath.Join(string(filepath.Separator), "card", "a.jpg")
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	locked := func(m *storage.Memory) storage.Storage { return lockedStorage{m} }
+	full := func(m *storage.Memory) storage.Storage { return fullStorage{m} }
+	tests := []struct {
+		name     string
+		src, dst func(*storage.Memory) storage.Storage // nil for the Memory itself
+		exists   bool
+		ctx      context.Context
+		want     error
+		code     string
+	}{
+		{"locked source", locked, nil, false, context.Background(), ErrSourceLocked, CodeLocked},
+		{"destination full", nil, full, false, context.Background(), ErrInsufficientSpace, CodeNoSpace},
+		{"cancelled", nil, nil, false, cancelledCtx, ErrCancelled, CodeCancelled},
+		{"destination exists", nil, nil, true, context.Background(), ErrDestinationExists, CodeExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newCopier := func() *Copier {
+				srcMem, dstMem := storage.NewMemory(), storage.NewMemory()
+				_ = srcMem.WriteFile(srcPath, []byte("data"))
+				if tt.exists {
+					_ = dstMem.WriteFile(srcPath, []byte("old"))
+				}
+				var src, dst storage.Storage = srcMem, dstMem
+				if tt.src != nil {
+					src = tt.src(srcMem)
+				}
+				if tt.dst != nil {
+					dst = tt.dst(dstMem)
+				}
+				cfg := config.DefaultConfig()
+				cfg.Source = filepath.Dir(srcPath)
+				cfg.Destination = filepath.Dir(srcPath) // the same path in another storage
+				cfg.MaxRetries = 1
+				c := New(cfg)
+				c.SetStorage(src, dst)
+				return c
+			}
+
+			err := newCopier().CopyFile(tt.ctx, srcPath, false)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, err)
+			}
+			if code := ErrorCode(err); code != tt.code {
+				t.Errorf("Expected code %q, got %q", tt.code, code)
+			}
+
+			result := newCopier().CopyFileWithRetry(tt.ctx, srcPath)
+			if tt.want == ErrDestinationExists {
+				if !result.Skipped || result.Error != nil {
+					t.Errorf("Expected the file skipped, got %+v", result)
+				}
+				return
+			}
+			if !errors.Is(result.Error, tt.want) {
+				t.Errorf("Expected %v, got %+v", tt.want, result)
+			}
+			// Only locks may go away by themselves.
+			if retried := result.Retries > 0; retried != (tt.want == ErrSourceLocked) {
+				t.Errorf("Unexpected retries for %v: %d", tt.want, result.Retries)
+			}
+		})
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	src, dst := storage.NewMemory(), storage.NewMemory()
+	srcPath := filepath.Join(string(filepath.Separator), "card", "a.jpg")
+	_ = src.WriteFile(srcPath, []byte("original"))
+
+	cfg := config.DefaultConfig()
+	cfg.Source = filepath.Dir(srcPath)
+	cfg.Destination = filepath.Join(string(filepath.Separator), "backup")
+	c := New(cfg)
+	c.SetStorage(src, dst)
+
+	if err := c.VerifyFile(srcPath, true); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("Expected a missing copy, got %v", err)
+	}
+	_ = dst.WriteFile(c.DestPath(srcPath), bytes.ToUpper([]byte("original")))
+	if err := c.VerifyFile(srcPath, false); err != nil {
+		t.Errorf("Expected the sizes to match, got %v", err)
+	}
+	err := c.VerifyFile(srcPath, true)
+	if !errors.Is(err, ErrChecksumMismatch) || ErrorCode(err) != CodeChecksum {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package copier
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Event is what CopyFilesParallelWithEvents sends while a batch runs: a
+// FileStarted, FileProgress, FileFinished, DestinationOffline,
+// DestinationOnline or BatchFinished. Consumers switch on the type and
+// ignore the events they have no use for, so new ones can be added
+// without touching them.
+type Event interface {
+	event()
+}
+
+// Statuses of a finished file (FileFinished.Status).
+const (
+	StatusSuccess = "success"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// FileStarted is sent when a worker takes a file.
+type FileStarted struct {
+	Path  string
+	Total int // files in the batch
+}
+
+// FileProgress is sent while a file is copied, at most every
+// progressInterval. Bytes counts from the start of the current attempt.
+type FileProgress struct {
+	Path  string
+	Bytes int64
+}
+
+// FileFinished is sent when a file was copied, skipped or failed.
+// Current counts the files finished so far, this one included.
+type FileFinished struct {
+	Result  CopyResult
+	Status  string // StatusSuccess, StatusSkipped or StatusFailed
+	Current int
+	Total   int
+}
+
+// DestinationOffline is sent when the destination became unreachable
+// during a batch, such as a share behind a VPN that dropped. The workers
+// pause until DestinationOnline, or for Wait, after which the files left
+// fail with ErrDestinationOffline.
+type DestinationOffline struct {
+	Path string
+	Err  error
+	Wait time.Duration
+}
+
+// DestinationOnline is sent when the destination came back after
+// DestinationOffline and the workers resume.
+type DestinationOnline struct {
+	Path     string
+	Downtime time.Duration
+}
+
+// BatchFinished is the last event of a batch.
+type BatchFinished struct {
+	Summary CopySummary
+}
+
+func (FileStarted) event()        {}
+func (FileProgress) event()       {}
+func (FileFinished) event()       {}
+func (DestinationOffline) event() {}
+func (DestinationOnline) event()  {}
+func (BatchFinished) event()      {}
+
+// Percent returns how much of the batch is done, from 0 to 100.
+func (f FileFinished) Percent() float64 {
+	if f.Total == 0 {
+		return 100
+	}
+	return float64(f.Current) / float64(f.Total) * 100
+}
+
+// progressInterval is how often FileProgress is sent for a file: often
+// enough for a progress bar, rarely enough not to slow the copy.
+const progressInterval = 100 * time.Millisecond
+
+// dispatchBuffer is how many events may wait for slow handlers before
+// the workers wait for them.
+const dispatchBuffer = 64
+
+// Dispatch returns a channel for CopyFilesParallelWithEvents that passes
+// every event to each handler, in the order they were sent, on one
+// goroutine; handlers therefore need no locking of their own. Call wait
+// once the copy returned: it closes the channel and returns when all the
+// events were handled.
+func Dispatch(handlers ...func(Event)) (events chan<- Event, wait func()) {
+	ch := make(chan Event, dispatchBuffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range ch {
+			for _, h := range handlers {
+				h(ev)
+			}
+		}
+	}()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { close(ch) })
+		<-done
+	}
+}
+
+// send sends ev on events unless events is nil.
+func send(events chan<- Event, ev Event) {
+	if events != nil {
+		events <- ev
+	}
+}
+
+// progressKey is the context key of the function copyFile reports the
+// bytes read to.
+type progressKey struct{}
+
+// withProgress returns ctx making copyFile call fn with the bytes of the
+// file read so far.
+func withProgress(ctx context.Context, fn func(read int64)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFrom returns the function set by withProgress, or nil.
+func progressFrom(ctx context.Context) func(read int64) {
+	fn, _ := ctx.Value(progressKey{}).(func(int64))
+	return fn
+}
+
+// fileProgress returns the function sending FileProgress for path on
+// events, at most every progressInterval. Attempts of a file run one
+// after the other, so it is never called concurrently.
+func fileProgress(events chan<- Event, path string) func(read int64) {
+	var last time.Time
+	return func(read int64) {
+		if now := time.Now(); now.Sub(last) >= progressInterval {
+			last = now
+			events <- FileProgress{Path: path, Bytes: read}
+		}
+	}
+}
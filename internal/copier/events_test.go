@@ -0,0 +1,76 @@
+package copier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"copy-image/internal/config"
+)
+
+func TestCopyEvents(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	var files []string
+	for _, name := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+		path := filepath.Join(srcDir, name)
+		if err := os.WriteFile(path, []byte("data of "+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	files = append(files, filepath.Join(srcDir, "missing.jpg"))
+
+	cfg := &config.Config{Source: srcDir, Destination: dstDir, Workers: 2}
+	c := New(cfg)
+
+	// Two consumers see the same events.
+	var all, finished []Event
+	events, wait := Dispatch(
+		func(ev Event) { all = append(all, ev) },
+		func(ev Event) {
+			if _, ok := ev.(FileFinished); ok {
+				finished = append(finished, ev)
+			}
+		},
+	)
+	summary := c.CopyFilesParallelWithEvents(context.Background(), files, events)
+	wait()
+
+	counts := map[string]int{}
+	statuses := map[string]int{}
+	for _, ev := range all {
+		switch ev := ev.(type) {
+		case FileStarted:
+			counts["started"]++
+		case FileProgress:
+			counts["progress"]++
+			if ev.Bytes <= 0 {
+				t.Errorf("Expected bytes read in %+v", ev)
+			}
+		case FileFinished:
+			counts["finished"]++
+			statuses[ev.Status]++
+			if ev.Total != len(files) || ev.Current < 1 || ev.Current > len(files) {
+				t.Errorf("Unexpected count in %+v", ev)
+			}
+		case BatchFinished:
+			counts["batch"]++
+			if ev.Summary.Successful != summary.Successful || ev.Summary.Failed != summary.Failed {
+				t.Errorf("Expected the summary returned, got %+v", ev.Summary)
+			}
+		}
+	}
+	if counts["started"] != 4 || counts["finished"] != 4 || counts["batch"] != 1 || counts["progress"] < 3 {
+		t.Errorf("Unexpected events %v", counts)
+	}
+	if statuses[StatusSuccess] != 3 || statuses[StatusFailed] != 1 {
+		t.Errorf("Unexpected statuses %v", statuses)
+	}
+	if len(finished) != 4 {
+		t.Errorf("Expected every consumer to get the events, got %d", len(finished))
+	}
+	if _, ok := all[len(all)-1].(BatchFinished); !ok {
+		t.Errorf("Expected BatchFinished last, got %T", all[len(all)-1])
+	}
+}
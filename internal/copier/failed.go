@@ -0,0 +1,92 @@
+package copier
+
+import (
+	"encoding/json"
+)
+
+// FailedFile is a file a batch could not copy. Without a Path it stands
+// for files lost together, such as those of an archive that could not be
+// written; without a Path or Dest, for a batch that failed as a whole.
+type FailedFile struct {
+	Path string // source path
+	Dest string // destination path
+	Err  error
+	// Attempts is how often the copy was tried, 0 when it never started.
+	Attempts int
+	// Bytes is the size of the source file, 0 when unknown.
+	Bytes int64
+}
+
+// String returns the line printed for the file, its name and error.
+func (f FailedFile) String() string {
+	msg := "unknown error"
+	if f.Err != nil {
+		msg = f.Err.Error()
+	}
+	name := f.Path
+	if name == "" {
+		name = f.Dest
+	}
+	if name == "" {
+		return msg
+	}
+	return baseName(name) + ": " + msg
+}
+
+// MarshalJSON writes the error as its message and code (ErrorCode), for
+// the frontend and reports.
+func (f FailedFile) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Path      string `json:"path"`
+		Dest      string `json:"dest,omitempty"`
+		Error     string `json:"error"`
+		ErrorCode string `json:"errorCode,omitempty"`
+		Attempts  int    `json:"attempts"`
+		Bytes     int64  `json:"bytes"`
+	}{Path: f.Path, Dest: f.Dest, ErrorCode: ErrorCode(f.Err), Attempts: f.Attempts, Bytes: f.Bytes}
+	if f.Err != nil {
+		out.Error = f.Err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// failedFile returns the failure of the copy that returned result.
+func (c *Copier) failedFile(result CopyResult) FailedFile {
+	f := FailedFile{Path: result.SourcePath, Dest: result.DestPath, Err: result.Error, Attempts: result.Retries + 1}
+	if info, err := c.src.Stat(result.SourcePath); err == nil {
+		f.Bytes = info.Size()
+	}
+	return f
+}
+
+// FailedPaths returns the source paths of the files that failed, so they
+// can be copied again without the rest of the batch.
+func (s CopySummary) FailedPaths() []string {
+	var paths []string
+	for _, f := range s.FailedFiles {
+		if f.Path != "" {
+			paths = append(paths, f.Path)
+		}
+	}
+	return paths
+}
+
+// FailureLines returns the lines of the failed files (FailedFile.String),
+// for messages and reports made of text. It is never nil.
+func (s CopySummary) FailureLines() []string {
+	lines := make([]string, len(s.FailedFiles))
+	for i, f := range s.FailedFiles {
+		lines[i] = f.String()
+	}
+	return lines
+}
+
+// AllFailed returns the failures of files that all failed with err before
+// they were tried, such as those for a busy destination.
+func AllFailed(files []string, err error) []FailedFile {
+	failed := make([]FailedFile, len(files))
+	for i, f := range files {
+		failed[i] = FailedFile{Path: f, Err: err}
+	}
+	return failed
+}
@@ -0,0 +1,94 @@
+package copier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+)
+
+func TestFailedFileString(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name string
+		file FailedFile
+		want string
+	}{
+		{"path", FailedFile{Path: filepath.Join("card", "a.jpg"), Dest: filepath.Join("backup", "b.jpg"), Err: boom}, "a.jpg: boom"},
+		{"destination only", FailedFile{Dest: filepath.Join("backup", "photos.zip"), Err: boom}, "photos.zip: boom"},
+		{"whole batch", FailedFile{Err: boom}, "boom"},
+		{"no error", FailedFile{Path: "a.jpg"}, "a.jpg: unknown error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.file.String(); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFailedFileJSON(t *testing.T) {
+	data, err := json.Marshal(FailedFile{Path: "/card/a.jpg", Err: ErrSourceLocked, Attempts: 3, Bytes: 42})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	want := map[string]any{"path": "/card/a.jpg", "error": ErrSourceLocked.Error(), "errorCode": CodeLocked, "attempts": 3.0, "bytes": 42.0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSummaryFailedFiles(t *testing.T) {
+	srcMem := storage.NewMemory()
+	locked := filepath.Join(string(filepath.Separator), "card", "a.jpg")
+	_ = srcMem.WriteFile(locked, []byte("data"))
+	missing := filepath.Join(string(filepath.Separator), "card", "gone.jpg")
+
+	cfg := config.DefaultConfig()
+	cfg.Source = filepath.Dir(locked)
+	cfg.Destination = filepath.Join(string(filepath.Separator), "backup")
+	cfg.MaxRetries = 1
+	cfg.Workers = 1
+	c := New(cfg)
+	c.SetStorage(lockedStorage{srcMem}, storage.NewMemory())
+
+	summary := c.CopyFilesParallelWithEvents(context.Background(), []string{locked, missing}, nil)
+	if len(summary.FailedFiles) != 2 {
+		t.Fatalf("Expected 2 failed files, got %+v", summary.FailedFiles)
+	}
+	byPath := make(map[string]FailedFile)
+	for _, f := range summary.FailedFiles {
+		byPath[f.Path] = f
+	}
+	if f := byPath[locked]; !errors.Is(f.Err, ErrSourceLocked) || f.Attempts != 2 || f.Bytes != 4 || f.Dest != c.DestPath(locked) {
+		t.Errorf("Unexpected failure of the locked file: %+v", f)
+	}
+	if f := byPath[missing]; f.Err == nil || f.Bytes != 0 {
+		t.Errorf("Unexpected failure of the missing file: %+v", f)
+	}
+	if paths := summary.FailedPaths(); len(paths) != 2 {
+		t.Errorf("Expected both paths, got %v", paths)
+	}
+	if lines := (CopySummary{}).FailureLines(); lines == nil {
+		t.Error("Expected no lines to be an empty list, got nil")
+	}
+}
+
+func TestAllFailed(t *testing.T) {
+	busy := errors.New("destination busy")
+	got := AllFailed([]string{"a.jpg", "b.jpg"}, busy)
+	want := []FailedFile{{Path: "a.jpg", Err: busy}, {Path: "b.jpg", Err: busy}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
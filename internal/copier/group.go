@@ -0,0 +1,206 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/lock"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DestinationSummary holds the result of copying a group to one destination.
+type DestinationSummary struct {
+	DestinationID string      `json:"destinationId"`
+	Path          string      `json:"path"`
+	Summary       CopySummary `json:"summary"`
+}
+
+// GroupSummary holds the results of running a copy group against all of its
+// enabled destinations.
+type GroupSummary struct {
+	GroupID      string
+	GroupName    string
+	Destinations []DestinationSummary
+
+	// Err is set by RunGroups when the group did not run: its source
+	// could not be scanned, it was already running or a group it depends
+	// on failed.
+	Err error
+}
+
+// Failed reports whether the group did not run or some files failed.
+func (g GroupSummary) Failed() bool {
+	return g.Err != nil || g.Total().Failed > 0
+}
+
+// Total adds up the per-destination summaries into a single CopySummary.
+func (g GroupSummary) Total() CopySummary {
+	total := CopySummary{FailedFiles: make([]FailedFile, 0)}
+	for _, d := range g.Destinations {
+		s := d.Summary
+		// Say which destination, as the group's files are listed together.
+		s.FailedFiles = make([]FailedFile, len(d.Summary.FailedFiles))
+		for i, f := range d.Summary.FailedFiles {
+			f.Err = fmt.Errorf("%w → %s", f.Err, d.Path)
+			s.FailedFiles[i] = f
+		}
+		total.add(s)
+	}
+	return total
+}
+
+// DestinationRunner copies files with a copier configured for a single
+// destination. CLI and GUI pass different runners so each can report
+// progress its own way (terminal bar vs. events).
+type DestinationRunner func(ctx context.Context, c *Copier, files []string) CopySummary
+
+// groupLocksKey is the context key of the groupLocks RunGroup takes.
+type groupLocksKey struct{}
+
+// groupLocks is where the run locks of groups are kept and how long to
+// wait for a busy one.
+type groupLocks struct {
+	dir  string
+	wait time.Duration
+}
+
+// WithGroupLocks returns ctx making RunGroup, and so RunGroups, hold the
+// run lock of a group, kept below dir, while it runs. A group already
+// running elsewhere, say a scheduled run of a group started by hand, is
+// waited for up to wait and then not run, with an error wrapping
+// lock.ErrGroupRunning. Dry runs take no lock.
+func WithGroupLocks(ctx context.Context, dir string, wait time.Duration) context.Context {
+	return context.WithValue(ctx, groupLocksKey{}, groupLocks{dir: dir, wait: wait})
+}
+
+// RunGroup scans the group's source once and copies the files to each
+// enabled destination in order, narrowed by the destination's own filters.
+// Disabled destinations are skipped.
+// Destinations that have not started yet are skipped once ctx is cancelled.
+func RunGroup(ctx context.Context, base *config.Config, group config.CopyGroup, run DestinationRunner) (GroupSummary, error) {
+	result := GroupSummary{
+		GroupID:   group.ID,
+		GroupName: group.Name,
+	}
+	// The scan shows up as the time before the first batch span.
+	ctx, span := tracer().Start(ctx, "copy group", trace.WithAttributes(
+		attribute.String("group.id", group.ID),
+		attribute.String("group.name", group.Name),
+	))
+	defer span.End()
+
+	if locks, ok := ctx.Value(groupLocksKey{}).(groupLocks); ok && !base.DryRun {
+		lease, err := lock.AcquireGroup(ctx, locks.dir, group.ID, lock.Options{Wait: locks.wait})
+		if err != nil && ctx.Err() != nil {
+			return result, nil
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "group locked")
+			if !errors.Is(err, lock.ErrGroupRunning) {
+				// The error already names the group otherwise.
+				err = fmt.Errorf("group %q: %w", group.ID, err)
+			}
+			return result, err
+		}
+		defer func() { _ = lease.Release() }()
+	}
+
+	// Scan with the group's overrides (extensions, excludes, recursion).
+	// When a destination has its own filters the scan must not drop files
+	// it wants, so filtering is then left to each destination.
+	scanCfg := base.ForDestination(group, config.Destination{})
+	for _, dest := range group.Destinations {
+		if dest.Enabled && dest.HasFilters() {
+			scanCfg.Extensions = nil
+			scanCfg.Exclude = nil
+			break
+		}
+	}
+	files, err := New(scanCfg).GetFilesContext(ctx)
+	if err != nil && ctx.Err() != nil {
+		// Cancelled while scanning: like after the scan, no destination
+		// runs.
+		return result, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "scan failed")
+		return result, fmt.Errorf("group %q: %w", group.ID, err)
+	}
+	span.SetAttributes(attribute.Int("copy.files", len(files)))
+
+	for _, dest := range group.Destinations {
+		if !dest.Enabled {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			break
+		}
+
+		c := New(base.ForDestination(group, dest))
+		result.Destinations = append(result.Destinations, DestinationSummary{
+			DestinationID: dest.ID,
+			Path:          dest.Path,
+			Summary:       run(ctx, c, c.Filter(files)),
+		})
+	}
+
+	return result, nil
+}
+
+// ErrDependencyFailed is the Err of a group skipped by RunGroups because a
+// group it depends on failed.
+var ErrDependencyFailed = errors.New("dependency failed")
+
+// RunGroups runs the given groups one after another in the order of
+// config.OrderGroups, and returns their summaries in that order. A group whose dependency did not run or had failed
+// files is skipped, since it usually relies on that group's output.
+// Groups that have not started yet are not run once ctx is cancelled.
+// onStart, if not nil, is called before each group runs. It may change base
+// and the group, e.g. to apply a config file that was edited meanwhile.
+func RunGroups(ctx context.Context, base *config.Config, groups []config.CopyGroup, run DestinationRunner, onStart func(*config.CopyGroup)) (BatchSummary, error) {
+	ordered, err := config.OrderGroups(groups)
+	if err != nil {
+		return BatchSummary{}, err
+	}
+
+	failed := make(map[string]bool)
+	var batch BatchSummary
+	for _, group := range ordered {
+		if ctx.Err() != nil {
+			break
+		}
+
+		var summary GroupSummary
+		if dep := failedDependency(group, failed); dep != "" {
+			summary = GroupSummary{GroupID: group.ID, GroupName: group.Name}
+			summary.Err = fmt.Errorf("group %q: %w: %s", group.ID, ErrDependencyFailed, dep)
+		} else {
+			if onStart != nil {
+				onStart(&group)
+			}
+			summary, err = RunGroup(ctx, base, group, run)
+			summary.Err = err
+		}
+		failed[group.ID] = summary.Failed()
+		batch.Groups = append(batch.Groups, summary)
+	}
+	return batch, nil
+}
+
+// failedDependency returns the first dependency of group that failed.
+func failedDependency(group config.CopyGroup, failed map[string]bool) string {
+	for _, dep := range group.DependsOn {
+		if failed[dep] {
+			return dep
+		}
+	}
+	return ""
+}
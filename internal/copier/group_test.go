@@ -0,0 +1,331 @@
+package copier
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/lock"
+)
+
+func runWithEvents(ctx context.Context, c *Copier, files []string) CopySummary {
+	return c.CopyFilesParallelWithEvents(ctx, files, nil)
+}
+
+func TestRunGroup(t *testing.T) {
+	srcDir := t.TempDir()
+	dst1 := t.TempDir()
+	dst2 := t.TempDir()
+	disabled := filepath.Join(t.TempDir(), "disabled")
+
+	for _, name := range []string{"a.jpg", "b.jpg", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	// Pre-existing file in dst2 that must be skipped (overwrite disabled).
+	if err := os.WriteFile(filepath.Join(dst2, "a.jpg"), []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to create existing file: %v", err)
+	}
+
+	base := config.DefaultConfig()
+	base.Extensions = []string{".jpg"}
+	group := config.CopyGroup{
+		ID:      "g1",
+		Name:    "Test group",
+		Source:  srcDir,
+		Enabled: true,
+		Destinations: []config.Destination{
+			{ID: "d1", Path: dst1, Overwrite: true, Enabled: true},
+			{ID: "d2", Path: dst2, Overwrite: false, Enabled: true},
+			{ID: "d3", Path: disabled, Enabled: false},
+		},
+	}
+
+	summary, err := RunGroup(context.Background(), base, group, runWithEvents)
+	if err != nil {
+		t.Fatalf("RunGroup failed: %v", err)
+	}
+
+	if len(summary.Destinations) != 2 {
+		t.Fatalf("Expected 2 destination summaries, got %d", len(summary.Destinations))
+	}
+	if summary.Destinations[0].Summary.Successful != 2 {
+		t.Errorf("Expected 2 files copied to d1, got %d", summary.Destinations[0].Summary.Successful)
+	}
+	if summary.Destinations[1].Summary.Skipped != 1 {
+		t.Errorf("Expected 1 skipped file in d2, got %d", summary.Destinations[1].Summary.Skipped)
+	}
+	if _, err := os.Stat(disabled); !os.IsNotExist(err) {
+		t.Error("Disabled destination must not be created")
+	}
+
+	total := summary.Total()
+	if total.TotalFiles != 4 || total.Successful != 3 || total.Skipped != 1 {
+		t.Errorf("Unexpected totals: %+v", total)
+	}
+}
+
+func TestRunGroupMissingSource(t *testing.T) {
+	group := config.CopyGroup{ID: "g1", Source: "/non/existent/source"}
+
+	if _, err := RunGroup(context.Background(), config.DefaultConfig(), group, runWithEvents); err == nil {
+		t.Error("Expected error for missing group source")
+	}
+}
+
+func TestRunGroupCancelled(t *testing.T) {
+	srcDir := t.TempDir()
+	group := config.CopyGroup{
+		ID:           "g1",
+		Source:       srcDir,
+		Destinations: []config.Destination{{ID: "d1", Path: t.TempDir(), Enabled: true}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	summary, err := RunGroup(ctx, config.DefaultConfig(), group, runWithEvents)
+	if err != nil {
+		t.Fatalf("RunGroup failed: %v", err)
+	}
+	if len(summary.Destinations) != 0 {
+		t.Errorf("Expected no destinations to run after cancellation, got %d", len(summary.Destinations))
+	}
+}
+
+func TestRunGroupLocked(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "a.jpg"), []byte("a"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	group := config.CopyGroup{
+		ID:           "g1",
+		Source:       srcDir,
+		Destinations: []config.Destination{{ID: "d1", Path: t.TempDir(), Enabled: true}},
+	}
+	locks := t.TempDir()
+	ctx := WithGroupLocks(context.Background(), locks, 0)
+
+	held, err := lock.AcquireGroup(context.Background(), locks, group.ID, lock.Options{})
+	if err != nil {
+		t.Fatalf("AcquireGroup failed: %v", err)
+	}
+	summary, err := RunGroup(ctx, config.DefaultConfig(), group, runWithEvents)
+	if !errors.Is(err, lock.ErrGroupRunning) || len(summary.Destinations) != 0 {
+		t.Fatalf("Expected the running group to be refused, got %v (%+v)", err, summary)
+	}
+	dry := config.DefaultConfig()
+	dry.DryRun = true
+	if _, err := RunGroup(ctx, dry, group, runWithEvents); err != nil {
+		t.Errorf("Expected a dry run to take no lock, got %v", err)
+	}
+	_ = held.Release()
+
+	summary, err = RunGroup(ctx, config.DefaultConfig(), group, func(ctx context.Context, c *Copier, files []string) CopySummary {
+		if _, err := lock.AcquireGroup(ctx, locks, group.ID, lock.Options{}); !errors.Is(err, lock.ErrGroupRunning) {
+			t.Errorf("Expected the group locked while it runs, got %v", err)
+		}
+		return runWithEvents(ctx, c, files)
+	})
+	if err != nil || summary.Total().Successful != 1 {
+		t.Fatalf("Expected the group to run once free, got %v (%+v)", err, summary)
+	}
+	again, err := lock.AcquireGroup(context.Background(), locks, group.ID, lock.Options{})
+	if err != nil {
+		t.Fatalf("Expected the lock released after the run, got %v", err)
+	}
+	_ = again.Release()
+}
+
+func TestRunGroupOverrides(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	for _, name := range []string{"a.jpg", "clip.mp4", filepath.Join("sub", "b.mp4")} {
+		path := filepath.Join(srcDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	base := config.DefaultConfig()
+	base.Extensions = []string{".jpg"}
+	recursive := true
+	group := config.CopyGroup{
+		ID:           "videos",
+		Source:       srcDir,
+		Enabled:      true,
+		Workers:      2,
+		Extensions:   []string{".mp4"},
+		Recursive:    &recursive,
+		Destinations: []config.Destination{{ID: "nas", Path: dstDir, Enabled: true}},
+	}
+
+	var workers int
+	summary, err := RunGroup(context.Background(), base, group, func(ctx context.Context, c *Copier, files []string) CopySummary {
+		workers = c.config.Workers
+		return runWithEvents(ctx, c, files)
+	})
+	if err != nil {
+		t.Fatalf("RunGroup failed: %v", err)
+	}
+	if workers != 2 {
+		t.Errorf("Expected the group's 2 workers, got %d", workers)
+	}
+	if total := summary.Total(); total.Successful != 2 {
+		t.Errorf("Expected both videos copied, got %+v", total)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "sub", "b.mp4")); err != nil {
+		t.Errorf("Expected sub/b.mp4 in destination: %v", err)
+	}
+}
+
+func TestRunGroups(t *testing.T) {
+	newGroup := func(id string, priority int, deps ...string) config.CopyGroup {
+		src := t.TempDir()
+		if err := os.WriteFile(filepath.Join(src, id+".jpg"), []byte(id), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		return config.CopyGroup{
+			ID:           id,
+			Source:       src,
+			Enabled:      true,
+			Priority:     priority,
+			DependsOn:    deps,
+			Destinations: []config.Destination{{ID: "d", Path: t.TempDir(), Enabled: true}},
+		}
+	}
+	broken := config.CopyGroup{ID: "broken", Source: "/non/existent/source", Enabled: true}
+
+	groups := []config.CopyGroup{
+		newGroup("thumbs", 10, "import"),
+		newGroup("import", 0),
+		newGroup("videos", 5),
+		broken,
+		newGroup("after-broken", 0, "broken"),
+	}
+
+	var started []string
+	batch, err := RunGroups(context.Background(), config.DefaultConfig(), groups, runWithEvents, func(g *config.CopyGroup) {
+		started = append(started, g.ID)
+	})
+	if err != nil {
+		t.Fatalf("RunGroups failed: %v", err)
+	}
+
+	if got := strings.Join(started, ","); got != "videos,import,thumbs,broken" {
+		t.Errorf("Unexpected run order: %s", got)
+	}
+	summaries := batch.Groups
+	if len(summaries) != 5 {
+		t.Fatalf("Expected 5 summaries, got %d", len(summaries))
+	}
+	last := summaries[4]
+	if last.GroupID != "after-broken" || !errors.Is(last.Err, ErrDependencyFailed) {
+		t.Errorf("Expected after-broken to be skipped for its failed dependency, got %+v", last)
+	}
+	if summaries[2].Failed() {
+		t.Errorf("Expected thumbs to succeed, got %+v", summaries[2])
+	}
+	if summaries[3].Err == nil || errors.Is(summaries[3].Err, ErrDependencyFailed) {
+		t.Errorf("Expected the broken group to report its scan error, got %v", summaries[3].Err)
+	}
+	if !batch.Failed() {
+		t.Error("Expected the batch to have failed")
+	}
+}
+
+func TestRunGroupDestinationFilters(t *testing.T) {
+	srcDir := t.TempDir()
+	archive := t.TempDir()
+	shared := t.TempDir()
+	for _, name := range []string{"a.cr3", "a.jpg", "b.jpg", filepath.Join("edits", "c.jpg")} {
+		path := filepath.Join(srcDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	base := config.DefaultConfig()
+	base.Extensions = []string{".cr3"}
+	recursive := true
+	group := config.CopyGroup{
+		ID:        "shoot",
+		Source:    srcDir,
+		Enabled:   true,
+		Recursive: &recursive,
+		Destinations: []config.Destination{
+			{ID: "archive", Path: archive, Enabled: true},
+			{ID: "shared", Path: shared, Enabled: true, Extensions: []string{".jpg"}, Exclude: []string{"edits"}},
+		},
+	}
+
+	summary, err := RunGroup(context.Background(), base, group, runWithEvents)
+	if err != nil {
+		t.Fatalf("RunGroup failed: %v", err)
+	}
+	if got := summary.Destinations[0].Summary.Successful; got != 1 {
+		t.Errorf("Expected only the RAW file in the archive, got %d", got)
+	}
+	if got := summary.Destinations[1].Summary.Successful; got != 2 {
+		t.Errorf("Expected a.jpg and b.jpg on the shared drive, got %d", got)
+	}
+	if _, err := os.Stat(filepath.Join(shared, "edits", "c.jpg")); !os.IsNotExist(err) {
+		t.Error("Excluded folder must not be copied to the shared drive")
+	}
+}
+
+func TestRunGroupZipDestination(t *testing.T) {
+	srcDir := t.TempDir()
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archive := filepath.Join(t.TempDir(), "deliveries", "job.zip")
+	group := config.CopyGroup{ID: "g", Source: srcDir, Enabled: true, Destinations: []config.Destination{
+		{ID: "client", Path: archive, Type: config.TypeZip, Enabled: true},
+	}}
+	cfg := config.DefaultConfig()
+	cfg.Checksums = "sha256"
+
+	for run := 1; run <= 2; run++ {
+		result, err := RunGroup(context.Background(), cfg, group, runWithEvents)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The second run finds the files in the archive.
+		if s := result.Total(); s.Successful+s.Skipped != 2 || s.Failed != 0 || (run == 2) != (s.Skipped == 2) {
+			t.Errorf("Run %d: unexpected summary %+v", run, s)
+		}
+	}
+
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	if got := strings.Join(names, ","); got != "a.jpg,b.jpg,checksums.sha256" {
+		t.Errorf("Expected the files and their checksums, got %s", got)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(archive)); len(entries) != 1 {
+		t.Errorf("Expected only the archive next to it, got %v", entries)
+	}
+}
@@ -0,0 +1,32 @@
+package copier
+
+// Metrics receives what the copier is doing as it happens, for monitoring
+// long-running modes (see package metrics). Methods are called from worker
+// goroutines and must be safe for concurrent use.
+type Metrics interface {
+	// Queued adds delta to the number of files waiting for a worker: the
+	// batch size when a batch starts, -1 as each file is picked up or
+	// dropped because the batch was cancelled.
+	Queued(delta int)
+	// Busy adds delta to the number of workers copying a file.
+	Busy(delta int)
+	// Retried counts one more attempt at a file after a failed one.
+	Retried()
+	// Done receives the result of every file copied, skipped or failed.
+	// Dry-runs are not reported.
+	Done(result CopyResult)
+}
+
+// noMetrics is the default Metrics, which drops everything.
+type noMetrics struct{}
+
+func (noMetrics) Queued(int)      {}
+func (noMetrics) Busy(int)        {}
+func (noMetrics) Retried()        {}
+func (noMetrics) Done(CopyResult) {}
+
+// SetMetrics makes the copier report its queue, workers and results to m.
+// By default nothing is reported.
+func (c *Copier) SetMetrics(m Metrics) {
+	c.metrics = m
+}
@@ -0,0 +1,111 @@
+package copier
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+)
+
+// recordingMetrics keeps what a copier reports, for tests.
+type recordingMetrics struct {
+	mu                      sync.Mutex
+	queued, busy, maxBusy   int
+	retries                 int
+	copied, skipped, failed int
+}
+
+func (m *recordingMetrics) Queued(delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queued += delta
+}
+
+func (m *recordingMetrics) Busy(delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.busy += delta
+	m.maxBusy = max(m.maxBusy, m.busy)
+}
+
+func (m *recordingMetrics) Retried() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func (m *recordingMetrics) Done(r CopyResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case r.Success:
+		m.copied++
+	case r.Skipped:
+		m.skipped++
+	default:
+		m.failed++
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	src := storage.NewMemory()
+	root := filepath.Join(string(filepath.Separator), "card")
+	var files []string
+	for _, name := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+		path := filepath.Join(root, name)
+		if err := src.WriteFile(path, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	dst := storage.NewMemory()
+	if err := dst.WriteFile(filepath.Join(string(filepath.Separator), "lab", "c.jpg"), []byte("c.jpg")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = root
+	cfg.Destination = filepath.Join(string(filepath.Separator), "lab")
+	cfg.Workers = 2
+	c := New(cfg)
+	c.SetStorage(src, dst)
+	m := &recordingMetrics{}
+	c.SetMetrics(m)
+
+	files = append(files, filepath.Join(root, "gone.jpg"))
+	c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+
+	if m.copied != 2 || m.skipped != 1 || m.failed != 1 {
+		t.Errorf("Expected 2 copied, 1 skipped and 1 failed, got %+v", m)
+	}
+	// Every file left the queue and every worker finished.
+	if m.queued != 0 || m.busy != 0 || m.maxBusy < 1 || m.maxBusy > cfg.Workers {
+		t.Errorf("Expected the queue and workers back at zero, got %+v", m)
+	}
+}
+
+func TestMetricsCountRetries(t *testing.T) {
+	src := storage.NewMemory()
+	dst := &droppingStorage{Memory: storage.NewMemory(), limit: 400}
+	srcPath := filepath.Join(string(filepath.Separator), "card", "big.jpg")
+	if err := src.WriteFile(srcPath, bytes.Repeat([]byte("0123456789"), 100)); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = filepath.Dir(srcPath)
+	cfg.Destination = filepath.Join(string(filepath.Separator), "lab")
+	c := New(cfg)
+	c.SetStorage(src, dst)
+	m := &recordingMetrics{}
+	c.SetMetrics(m)
+
+	c.CopyFilesParallel(context.Background(), []string{srcPath})
+	if m.retries != 1 || m.copied != 1 {
+		t.Errorf("Expected one retry and one copy, got %+v", m)
+	}
+}
@@ -0,0 +1,129 @@
+package copier
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+)
+
+// mhlDate is the date format of MHL files.
+const mhlDate = "2006-01-02T15:04:05Z"
+
+// mhlHashList is the root of an MHL v1.1 document.
+type mhlHashList struct {
+	XMLName xml.Name       `xml:"hashlist"`
+	Version string         `xml:"version,attr"`
+	Creator mhlCreatorInfo `xml:"creatorinfo"`
+	Hashes  []mhlHash      `xml:"hash"`
+}
+
+type mhlCreatorInfo struct {
+	Username   string `xml:"username"`
+	Hostname   string `xml:"hostname"`
+	Tool       string `xml:"tool"`
+	StartDate  string `xml:"startdate"`
+	FinishDate string `xml:"finishdate"`
+}
+
+type mhlHash struct {
+	File         string `xml:"file"`
+	Size         int64  `xml:"size"`
+	LastModified string `xml:"lastmodificationdate,omitempty"`
+	MD5          string `xml:"md5"`
+	HashDate     string `xml:"hashdate"`
+}
+
+// writeMHL writes the MHL file of a batch into the destination root, named
+// like offload tools name theirs: <folder>_<date>_<time>.mhl. Paths are
+// relative to the destination.
+func (c *Copier) writeMHL(start time.Time, files []hashedFile) error {
+	list := mhlHashList{
+		Version: "1.1",
+		Creator: mhlCreatorInfo{
+			Username:   currentUser(),
+			Tool:       "copy-image",
+			StartDate:  start.UTC().Format(mhlDate),
+			FinishDate: time.Now().UTC().Format(mhlDate),
+		},
+	}
+	list.Creator.Hostname, _ = os.Hostname()
+	for _, f := range files {
+		h := mhlHash{
+			File:     filepath.ToSlash(c.relDest(c.DestPath(f.source))),
+			Size:     f.size,
+			MD5:      f.sums[config.ChecksumMD5],
+			HashDate: f.hashed.UTC().Format(mhlDate),
+		}
+		if !f.modTime.IsZero() {
+			h.LastModified = f.modTime.UTC().Format(mhlDate)
+		}
+		list.Hashes = append(list.Hashes, h)
+	}
+	sort.Slice(list.Hashes, func(i, j int) bool { return list.Hashes[i].File < list.Hashes[j].File })
+
+	path := c.mhlPath(start)
+	f, err := c.dst.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := encodeMHL(f, list); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// mhlPath returns a name for the MHL file of a batch started at start
+// that no earlier batch used.
+func (c *Copier) mhlPath(start time.Time) string {
+	folder := filepath.Base(filepath.Clean(c.config.Destination))
+	if folder == "." || folder == string(filepath.Separator) || strings.HasSuffix(folder, ":") {
+		folder = "copy-image"
+	}
+	base := folder + "_" + start.Format("2006-01-02_150405")
+	path := filepath.Join(c.config.Destination, base+".mhl")
+	for i := 2; storage.Exists(c.dst, path); i++ {
+		path = filepath.Join(c.config.Destination, fmt.Sprintf("%s_%d.mhl", base, i))
+	}
+	return path
+}
+
+func encodeMHL(w io.Writer, list mhlHashList) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(list); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// currentUser returns the login name for the creator info, without the
+// domain Windows prefixes it with.
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	if _, name, ok := strings.Cut(u.Username, `\`); ok {
+		return name
+	}
+	return u.Username
+}
+
+// isMHLFile reports whether name is a Media Hash List.
+func isMHLFile(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".mhl")
+}
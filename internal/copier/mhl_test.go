@@ -0,0 +1,87 @@
+package copier
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+func TestMHL(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "A001")
+	if err := os.MkdirAll(filepath.Join(src, "CLIPS"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{"CLIPS/A001C001.mov": "clip", "A001.xml": "meta"} {
+		if err := os.WriteFile(filepath.Join(src, filepath.FromSlash(name)), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = src
+	cfg.Destination = dst
+	cfg.Recursive = true
+	cfg.MHL = true
+	cfg.Checksums = config.ChecksumSHA256
+	c := New(cfg)
+	files, err := c.GetFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+
+	matches, _ := filepath.Glob(filepath.Join(dst, "A001_*.mhl"))
+	if len(matches) != 1 {
+		t.Fatalf("Expected one MHL file, got %v", matches)
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "<?xml") {
+		t.Errorf("Expected an XML declaration, got %.40q", data)
+	}
+	var list mhlHashList
+	if err := xml.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Version != "1.1" || len(list.Hashes) != 2 {
+		t.Fatalf("Unexpected hash list %+v", list)
+	}
+	clip := list.Hashes[1]
+	sum := md5.Sum([]byte("clip"))
+	if clip.File != "CLIPS/A001C001.mov" || clip.Size != 4 || clip.MD5 != hex.EncodeToString(sum[:]) || clip.LastModified == "" {
+		t.Errorf("Unexpected entry %+v", clip)
+	}
+
+	// The SHA-256 manifest is still written alongside.
+	if _, err := os.Stat(filepath.Join(dst, "checksums.sha256")); err != nil {
+		t.Error(err)
+	}
+	report, err := c.Verify(context.Background(), false)
+	if err != nil || len(report.Extra) != 0 {
+		t.Errorf("Verify() should not count manifests as extra files, got %+v, %v", report, err)
+	}
+
+	// A batch in the same second gets a name of its own.
+	at := time.Date(2024, 5, 1, 9, 30, 0, 0, time.Local)
+	first := c.mhlPath(at)
+	if filepath.Base(first) != "A001_2024-05-01_093000.mhl" {
+		t.Errorf("mhlPath() = %s", first)
+	}
+	if err := os.WriteFile(first, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if second := c.mhlPath(at); filepath.Base(second) != "A001_2024-05-01_093000_2.mhl" {
+		t.Errorf("mhlPath() of a second batch = %s", second)
+	}
+}
@@ -0,0 +1,169 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"copy-image/internal/utils"
+)
+
+// DefaultOfflineWait is how long a batch waits for its destination to come
+// back when config.OfflineWait is 0: long enough for a VPN or Wi-Fi to
+// reconnect, short enough for an unattended run to still end.
+const DefaultOfflineWait = 5 * time.Minute
+
+// offlinePoll is how often a batch whose destination went offline looks
+// for it again.
+var offlinePoll = 5 * time.Second
+
+// breaker pauses a batch while its destination is offline. Without it,
+// every file left fails on its own after burning its retries, seconds
+// after the share went away.
+type breaker struct {
+	c      *Copier
+	ctx    context.Context // of the batch
+	events chan<- Event
+	wait   time.Duration
+
+	mu   sync.Mutex
+	back chan struct{} // closed when an outage ends; nil while online
+	err  error         // set once the destination stayed offline too long
+	wg   sync.WaitGroup
+}
+
+// newBreaker returns the breaker of a batch run under ctx, or nil when
+// config.OfflineWait is negative. Its methods do nothing on nil.
+func (c *Copier) newBreaker(ctx context.Context, events chan<- Event) *breaker {
+	if c.config.OfflineWait < 0 {
+		return nil
+	}
+	wait := time.Duration(c.config.OfflineWait) * time.Second
+	if wait == 0 {
+		wait = DefaultOfflineWait
+	}
+	return &breaker{c: c, ctx: ctx, events: events, wait: wait}
+}
+
+// breakerKey is the context key of the breaker of a batch.
+type breakerKey struct{}
+
+// withBreaker returns ctx making CopyFileWithRetry pause with b.
+func withBreaker(ctx context.Context, b *breaker) context.Context {
+	return context.WithValue(ctx, breakerKey{}, b)
+}
+
+// breakerFrom returns the breaker set by withBreaker, or nil.
+func breakerFrom(ctx context.Context) *breaker {
+	b, _ := ctx.Value(breakerKey{}).(*breaker)
+	return b
+}
+
+// pause blocks while the destination is offline. It returns the error to
+// fail the file with when the destination did not come back in time or
+// ctx was cancelled meanwhile.
+func (b *breaker) pause(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	for {
+		b.mu.Lock()
+		back, err := b.back, b.err
+		b.mu.Unlock()
+		if err != nil || back == nil {
+			return err
+		}
+		select {
+		case <-back:
+		case <-ctx.Done():
+			return cancelled(ctx)
+		}
+	}
+}
+
+// offline reports whether err, the error of a copy attempt, came from the
+// destination going offline rather than from the file. An outage then
+// starts unless one already did, and the attempt is to be made again once
+// pause returns.
+func (b *breaker) offline(err error) bool {
+	if b == nil || !errors.Is(err, utils.ErrNetwork) {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.back != nil || b.err != nil {
+		return true
+	}
+	// Looked up while holding mu, so the workers failing at once wait for
+	// one answer instead of asking the share each.
+	cause := b.unreachable()
+	if cause == nil {
+		return false
+	}
+	b.back = make(chan struct{})
+	b.wg.Add(1)
+	go b.poll(cause)
+	return true
+}
+
+// poll looks for the destination until it is back, the wait is over or
+// the batch is cancelled, and then ends the outage.
+func (b *breaker) poll(cause error) {
+	defer b.wg.Done()
+	dest := b.c.config.Destination
+	start := time.Now()
+	b.c.logger.Warn("destination offline, waiting for it", "destination", dest, "error", cause, "wait", b.wait)
+	send(b.events, DestinationOffline{Path: dest, Err: cause, Wait: b.wait})
+
+	ticker := time.NewTicker(offlinePoll)
+	defer ticker.Stop()
+	timeout := time.NewTimer(b.wait)
+	defer timeout.Stop()
+	var err error
+wait:
+	for {
+		select {
+		case <-b.ctx.Done():
+			// The workers stop on their own; the batch may be over.
+			break wait
+		case <-timeout.C:
+			err = fmt.Errorf("%w: %s did not come back within %s: %w", ErrDestinationOffline, dest, b.wait, cause)
+			b.c.logger.Error("destination still offline, failing the files left", "destination", dest, "error", cause)
+			break wait
+		case <-ticker.C:
+			if b.unreachable() == nil {
+				downtime := time.Since(start)
+				b.c.logger.Info("destination back, resuming", "destination", dest, "downtime", downtime)
+				send(b.events, DestinationOnline{Path: dest, Downtime: downtime})
+				break wait
+			}
+		}
+	}
+
+	b.mu.Lock()
+	b.err = err
+	close(b.back)
+	b.back = nil
+	b.mu.Unlock()
+}
+
+// unreachable returns the error of looking up the destination when it
+// failed for the network, and nil when the destination answered, even
+// that it does not exist yet.
+func (b *breaker) unreachable() error {
+	_, err := b.c.dst.Stat(b.c.config.Destination)
+	if err = utils.Classify(err); errors.Is(err, utils.ErrNetwork) {
+		return err
+	}
+	return nil
+}
+
+// close waits for the outage being polled for, if any, to end. The batch
+// calls it before sending BatchFinished.
+func (b *breaker) close() {
+	if b != nil {
+		b.wg.Wait()
+	}
+}
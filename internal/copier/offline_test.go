@@ -0,0 +1,143 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+	"copy-image/internal/utils"
+)
+
+// offlineStorage is a Memory destination that cannot be reached while
+// down is set.
+type offlineStorage struct {
+	*storage.Memory
+	down *atomic.Bool
+}
+
+func (s offlineStorage) err() error {
+	if s.down.Load() {
+		return &utils.FileError{Kind: utils.ErrNetwork, Err: errors.New("the network name is no longer available")}
+	}
+	return nil
+}
+
+func (s offlineStorage) Create(path string) (storage.File, error) {
+	if err := s.err(); err != nil {
+		return nil, err
+	}
+	return s.Memory.Create(path)
+}
+
+func (s offlineStorage) Stat(path string) (fs.FileInfo, error) {
+	if err := s.err(); err != nil {
+		return nil, err
+	}
+	return s.Memory.Stat(path)
+}
+
+// offlineBatch copies 6 files to a destination that is offline from the
+// start, returning the summary, the results and the events of the batch.
+func offlineBatch(t *testing.T, wait int, down *atomic.Bool) (CopySummary, []CopyResult, []Event) {
+	t.Helper()
+	poll := offlinePoll
+	offlinePoll = 10 * time.Millisecond
+	t.Cleanup(func() { offlinePoll = poll })
+
+	src := storage.NewMemory()
+	var files []string
+	for i := range 6 {
+		path := filepath.Join(string(filepath.Separator), "card", fmt.Sprintf("%d.jpg", i))
+		_ = src.WriteFile(path, []byte("data"))
+		files = append(files, path)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Source = filepath.Dir(files[0])
+	cfg.Destination = filepath.Join(string(filepath.Separator), "nas")
+	cfg.Workers = 3
+	cfg.MaxRetries = 1
+	cfg.OfflineWait = wait
+	c := New(cfg)
+	c.SetStorage(src, offlineStorage{storage.NewMemory(), down})
+
+	var results []CopyResult
+	var events []Event
+	ch, done := Dispatch(func(ev Event) {
+		switch ev := ev.(type) {
+		case FileFinished:
+			results = append(results, ev.Result)
+		case DestinationOffline, DestinationOnline:
+			events = append(events, ev)
+		}
+	})
+	summary := c.CopyFilesParallelWithEvents(context.Background(), files, ch)
+	done()
+	return summary, results, events
+}
+
+func TestOfflineDestinationResumes(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	time.AfterFunc(100*time.Millisecond, func() { down.Store(false) })
+
+	summary, results, events := offlineBatch(t, 0, &down)
+	if summary.Successful != 6 || summary.Failed != 0 {
+		t.Fatalf("Expected every file copied once the destination was back, got %+v", summary)
+	}
+	for _, r := range results {
+		if r.Retries != 0 {
+			t.Errorf("Expected the outage to use up no retries, %s used %d", r.FileName, r.Retries)
+		}
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected one outage, got %+v", events)
+	}
+	if _, ok := events[0].(DestinationOffline); !ok {
+		t.Errorf("Expected DestinationOffline first, got %T", events[0])
+	}
+	if on, ok := events[1].(DestinationOnline); !ok || on.Downtime <= 0 {
+		t.Errorf("Expected DestinationOnline with the downtime, got %+v", events[1])
+	}
+}
+
+func TestOfflineDestinationGivesUp(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	start := time.Now()
+
+	summary, results, events := offlineBatch(t, 1, &down)
+	if summary.Failed != 6 {
+		t.Fatalf("Expected every file to fail, got %+v", summary)
+	}
+	for _, r := range results {
+		if !errors.Is(r.Error, ErrDestinationOffline) || ErrorCode(r.Error) != CodeOffline || r.Retries != 0 {
+			t.Errorf("Expected %s to fail as offline without retries, got %v (%d retries)", r.FileName, r.Error, r.Retries)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected the batch to end once the wait was over, took %s", elapsed)
+	}
+	if len(events) != 1 {
+		t.Errorf("Expected one DestinationOffline, got %+v", events)
+	}
+}
+
+func TestOfflineWaitDisabled(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+
+	summary, results, events := offlineBatch(t, -1, &down)
+	if summary.Failed != 6 || len(events) != 0 {
+		t.Fatalf("Expected the files to fail without waiting, got %+v and %+v", summary, events)
+	}
+	if r := results[0]; errors.Is(r.Error, ErrDestinationOffline) || !errors.Is(r.Error, utils.ErrNetwork) {
+		t.Errorf("Expected the network error, got %v", r.Error)
+	}
+}
@@ -0,0 +1,106 @@
+package copier
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"copy-image/internal/storage"
+)
+
+// Ways to seal the source once an offload was verified (Seal).
+const (
+	SealNone     = "none"
+	SealReadOnly = "readonly" // clear the write permission of every file
+	SealMarker   = "marker"   // write VerifiedMarker into each source folder
+)
+
+// VerifiedMarker is the file SealMarker writes. Scans skip it so offloading
+// the same card to a second destination does not copy it along.
+const VerifiedMarker = ".verified"
+
+// CheckSeal returns an error unless mode is a known seal mode; empty means
+// SealNone.
+func CheckSeal(mode string) error {
+	switch mode {
+	case "", SealNone, SealReadOnly, SealMarker:
+		return nil
+	}
+	return fmt.Errorf("unknown seal mode %q: use %q, %q or %q", mode, SealNone, SealReadOnly, SealMarker)
+}
+
+// Seal protects the source files of a verified offload so a card is not
+// formatted or edited before its copies were checked: SealReadOnly makes
+// the files read-only (the read-only attribute on Windows), SealMarker
+// records the offload in a .verified file in each source folder. Callers
+// only seal after VerifyFiles found every file intact.
+func (c *Copier) Seal(mode string, files []string) error {
+	switch mode {
+	case "", SealNone:
+		return nil
+	case SealReadOnly:
+		return c.sealReadOnly(files)
+	case SealMarker:
+		return c.writeMarkers(files, time.Now())
+	}
+	return CheckSeal(mode)
+}
+
+func (c *Copier) sealReadOnly(files []string) error {
+	if _, local := c.src.(storage.Local); !local {
+		return errors.New("read-only sealing needs a local source; use the marker instead")
+	}
+	var errs []error
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err == nil {
+			err = os.Chmod(path, info.Mode().Perm()&^0o222)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to seal %s: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeMarkers writes VerifiedMarker into each source folder holding one
+// of files, saying when, where to and how many of its files were verified.
+func (c *Copier) writeMarkers(files []string, at time.Time) error {
+	counts := make(map[string]int)
+	for _, path := range files {
+		for _, root := range c.roots() {
+			if rel, err := filepath.Rel(root, path); err == nil && filepath.IsLocal(rel) {
+				counts[root]++
+				break
+			}
+		}
+	}
+
+	var errs []error
+	for _, root := range c.roots() {
+		if counts[root] == 0 {
+			continue
+		}
+		content := fmt.Sprintf("verified: %s\ndestination: %s\nfiles: %d\n",
+			at.Format(time.RFC3339), c.config.Destination, counts[root])
+		if err := writeFile(c.src, filepath.Join(root, VerifiedMarker), []byte(content)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write %s marker: %w", VerifiedMarker, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeFile replaces the file at path in s with data.
+func writeFile(s storage.Storage, path string, data []byte) error {
+	f, err := s.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
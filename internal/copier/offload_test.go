@@ -0,0 +1,145 @@
+package copier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+func TestVerifyFiles(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	for name, content := range map[string]string{"a.jpg": "a", "b.jpg": "b"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, "a.jpg"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, "other.jpg"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+
+	// Only the given files count, and extras are not looked for.
+	report, err := New(cfg).VerifyFiles(context.Background(), []string{filepath.Join(srcDir, "a.jpg")}, true)
+	if err != nil {
+		t.Fatalf("VerifyFiles failed: %v", err)
+	}
+	if !report.Clean() || report.Checked != 1 || report.OK != 1 || len(report.Extra) != 0 {
+		t.Errorf("Unexpected report for a copied file: %+v", report)
+	}
+
+	report, err = New(cfg).VerifyFiles(context.Background(), []string{filepath.Join(srcDir, "b.jpg")}, true)
+	if err != nil {
+		t.Fatalf("VerifyFiles failed: %v", err)
+	}
+	if report.Clean() || strings.Join(report.Missing, ",") != "b.jpg" {
+		t.Errorf("Expected b.jpg to be missing, got %+v", report)
+	}
+}
+
+func TestSeal(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+	}{
+		{"none", SealNone},
+		{"read-only", SealReadOnly},
+		{"marker", SealMarker},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcDir := t.TempDir()
+			file := filepath.Join(srcDir, "a.jpg")
+			if err := os.WriteFile(file, []byte("a"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg := config.DefaultConfig()
+			cfg.Source = srcDir
+			cfg.Destination = t.TempDir()
+			c := New(cfg)
+
+			if err := c.Seal(tt.mode, []string{file}); err != nil {
+				t.Fatalf("Seal failed: %v", err)
+			}
+			t.Cleanup(func() { _ = os.Chmod(file, 0644) })
+
+			info, err := os.Stat(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			readOnly := info.Mode().Perm()&0o222 == 0
+			if readOnly != (tt.mode == SealReadOnly) {
+				t.Errorf("Expected read-only %v, got mode %v", tt.mode == SealReadOnly, info.Mode())
+			}
+
+			marker, err := os.ReadFile(filepath.Join(srcDir, VerifiedMarker))
+			if tt.mode != SealMarker {
+				if err == nil {
+					t.Errorf("Unexpected %s marker", VerifiedMarker)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Marker not written: %v", err)
+			}
+			if !strings.Contains(string(marker), "files: 1\n") || !strings.Contains(string(marker), cfg.Destination) {
+				t.Errorf("Unexpected marker content:\n%s", marker)
+			}
+
+			// A later scan of the card leaves the marker out.
+			files, err := c.GetFiles()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != 1 || files[0] != file {
+				t.Errorf("Expected only %s to be scanned, got %v", file, files)
+			}
+		})
+	}
+}
+
+func TestWriteMarkersCountsPerSource(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.Source = first
+	cfg.Sources = []string{second}
+	cfg.Destination = t.TempDir()
+
+	files := []string{filepath.Join(first, "a.jpg"), filepath.Join(first, "b.jpg")}
+	if err := New(cfg).writeMarkers(files, time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("writeMarkers failed: %v", err)
+	}
+	marker, err := os.ReadFile(filepath.Join(first, VerifiedMarker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(marker), "verified: 2024-05-01T09:30:00Z\n") || !strings.Contains(string(marker), "files: 2\n") {
+		t.Errorf("Unexpected marker content:\n%s", marker)
+	}
+	// Nothing was offloaded from the second source.
+	if _, err := os.Stat(filepath.Join(second, VerifiedMarker)); err == nil {
+		t.Error("Expected no marker in a source without offloaded files")
+	}
+}
+
+func TestCheckSeal(t *testing.T) {
+	for _, mode := range []string{"", SealNone, SealReadOnly, SealMarker} {
+		if err := CheckSeal(mode); err != nil {
+			t.Errorf("CheckSeal(%q) = %v", mode, err)
+		}
+	}
+	if err := CheckSeal("lock"); err == nil {
+		t.Error("Expected an unknown mode to be rejected")
+	}
+}
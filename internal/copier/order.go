@@ -0,0 +1,55 @@
+package copier
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+// ordered returns files in the order of Config.Order, leaving files itself
+// alone. Files that cannot be stat'ed sort as empty and old; copying them
+// reports the error. Equal sizes and times fall back to the path so the
+// order is the same on every run.
+func (c *Copier) ordered(files []string) []string {
+	order := c.config.Order
+	if order == "" || len(files) < 2 {
+		return files
+	}
+	sorted := slices.Clone(files)
+	if order == config.OrderRandom {
+		rand.Shuffle(len(sorted), func(i, j int) { sorted[i], sorted[j] = sorted[j], sorted[i] })
+		return sorted
+	}
+	if order == config.OrderName {
+		slices.Sort(sorted)
+		return sorted
+	}
+
+	type stat struct {
+		size    int64
+		modTime time.Time
+	}
+	stats := make(map[string]stat, len(sorted))
+	for _, f := range sorted {
+		if info, err := c.src.Stat(f); err == nil {
+			stats[f] = stat{info.Size(), info.ModTime()}
+		}
+	}
+	slices.SortFunc(sorted, func(a, b string) int {
+		sa, sb := stats[a], stats[b]
+		var by int
+		switch order {
+		case config.OrderSizeAsc:
+			by = cmp.Compare(sa.size, sb.size)
+		case config.OrderSizeDesc:
+			by = cmp.Compare(sb.size, sa.size)
+		case config.OrderMtime:
+			by = sa.modTime.Compare(sb.modTime)
+		}
+		return cmp.Or(by, cmp.Compare(a, b))
+	})
+	return sorted
+}
@@ -0,0 +1,78 @@
+package copier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+func TestOrder(t *testing.T) {
+	srcDir := t.TempDir()
+	// name, size, age in hours
+	specs := []struct {
+		name string
+		size int
+		age  int
+	}{
+		{"b.jpg", 30, 1},
+		{"a.mov", 100, 2},
+		{"c.jpg", 10, 3},
+		{"d.jpg", 30, 4},
+	}
+	var files []string
+	for _, s := range specs {
+		path := filepath.Join(srcDir, s.name)
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", s.size)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(-time.Duration(s.age) * time.Hour)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"", []string{"b.jpg", "a.mov", "c.jpg", "d.jpg"}},
+		{config.OrderName, []string{"a.mov", "b.jpg", "c.jpg", "d.jpg"}},
+		{config.OrderSizeAsc, []string{"c.jpg", "b.jpg", "d.jpg", "a.mov"}},
+		{config.OrderSizeDesc, []string{"a.mov", "b.jpg", "d.jpg", "c.jpg"}},
+		{config.OrderMtime, []string{"d.jpg", "c.jpg", "a.mov", "b.jpg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			// One worker copies the files in the order they are handed out.
+			cfg := &config.Config{Source: srcDir, Destination: t.TempDir(), Workers: 1, Order: tt.order}
+			c := New(cfg)
+			var got []string
+			c.OnResult(func(r CopyResult) { got = append(got, filepath.Base(r.SourcePath)) })
+			summary := c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+			if summary.Successful != len(files) {
+				t.Fatalf("Expected every file copied, got %+v", summary)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	// A random order still has every file, and the caller's slice is
+	// left as it was.
+	cfg := &config.Config{Source: srcDir, Destination: t.TempDir(), Workers: 1, Order: config.OrderRandom}
+	shuffled := New(cfg).ordered(files)
+	if !slices.Equal(slices.Sorted(slices.Values(shuffled)), slices.Sorted(slices.Values(files))) {
+		t.Errorf("Expected the same files shuffled, got %v", shuffled)
+	}
+	if filepath.Base(files[0]) != "b.jpg" {
+		t.Error("Expected the files passed in unchanged")
+	}
+}
@@ -0,0 +1,69 @@
+package copier
+
+import (
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+	"copy-image/internal/utils"
+)
+
+// Plan is what copying a batch would do, worked out without copying, so
+// it can be confirmed first.
+type Plan struct {
+	// Copy are the source files new to the destination, Overwrite those
+	// replacing a file there and Skip those left alone: unchanged since
+	// the last incremental copy, or already there without overwrite.
+	Copy      []string `json:"copy"`
+	Overwrite []string `json:"overwrite"`
+	Skip      []string `json:"skip"`
+	// Bytes is the size of the files copied or overwritten.
+	Bytes int64 `json:"bytes"`
+	// FreeBytes is the space left at the destination, -1 when it cannot
+	// be told, e.g. on S3 or a phone.
+	FreeBytes int64 `json:"freeBytes"`
+}
+
+// Fits reports whether the copies fit in the free space, as far as it is
+// known. Overwritten files are counted in full.
+func (p Plan) Fits() bool {
+	return p.FreeBytes < 0 || p.Bytes <= p.FreeBytes
+}
+
+// Plan decides, like CopyFileWithRetry, what copying files would do. It
+// looks at the destination but writes nothing. Processors are not asked,
+// since some read whole files to decide.
+func (c *Copier) Plan(files []string) Plan {
+	plan := Plan{Copy: []string{}, Overwrite: []string{}, Skip: []string{}}
+	for _, f := range files {
+		if c.unchanged(f) {
+			plan.Skip = append(plan.Skip, f)
+			continue
+		}
+		exists := storage.Exists(c.dst, c.DestPath(f))
+		switch {
+		case exists && !c.config.Overwrite:
+			plan.Skip = append(plan.Skip, f)
+			continue
+		case exists:
+			plan.Overwrite = append(plan.Overwrite, f)
+		default:
+			plan.Copy = append(plan.Copy, f)
+		}
+		if info, err := c.src.Stat(f); err == nil {
+			plan.Bytes += info.Size()
+		}
+	}
+
+	plan.FreeBytes = c.freeBytes()
+	return plan
+}
+
+// freeBytes returns the space left at the destination, or -1 when it
+// cannot be told.
+func (c *Copier) freeBytes() int64 {
+	if dest := c.config.Destination; !config.IsRemote(dest) && !config.IsMTP(dest) {
+		if free, err := utils.FreeSpace(c.config.LockDir()); err == nil {
+			return int64(free)
+		}
+	}
+	return -1
+}
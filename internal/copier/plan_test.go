@@ -0,0 +1,51 @@
+package copier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"copy-image/internal/config"
+)
+
+func TestPlan(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	var files []string
+	for _, name := range []string{"new.jpg", "old.jpg"} {
+		path := filepath.Join(srcDir, name)
+		if err := os.WriteFile(path, []byte("12345"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	if err := os.WriteFile(filepath.Join(dstDir, "old.jpg"), []byte("1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                   string
+		overwrite              bool
+		copy, overwrites, skip int
+		bytes                  int64
+	}{
+		{"keep existing", false, 1, 0, 1, 5},
+		{"overwrite", true, 1, 1, 0, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Source: srcDir, Destination: dstDir, Workers: 1, Overwrite: tt.overwrite}
+			plan := New(cfg).Plan(files)
+			if len(plan.Copy) != tt.copy || len(plan.Overwrite) != tt.overwrites || len(plan.Skip) != tt.skip || plan.Bytes != tt.bytes {
+				t.Errorf("Unexpected plan %+v", plan)
+			}
+			if plan.FreeBytes <= 0 || !plan.Fits() {
+				t.Errorf("Expected the free space of the destination, got %d", plan.FreeBytes)
+			}
+		})
+	}
+
+	// Nothing was copied.
+	if _, err := os.Stat(filepath.Join(dstDir, "new.jpg")); err == nil {
+		t.Error("Expected Plan not to copy")
+	}
+}
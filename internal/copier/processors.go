@@ -0,0 +1,54 @@
+package copier
+
+import (
+	"copy-image/internal/compress"
+	"copy-image/internal/config"
+	"copy-image/internal/imagecheck"
+	"copy-image/internal/metadata"
+	"copy-image/internal/processor"
+	"copy-image/internal/resize"
+	"copy-image/internal/transcode"
+	"copy-image/internal/watermark"
+)
+
+// newProcessors returns the processors cfg asks for: image validation
+// first, so nothing is done with a corrupt file, then the processors
+// listed in the config, then the conversions changing what is written.
+// It returns nil when there are none.
+func newProcessors(cfg *config.Config) (*processor.Chain, error) {
+	listed, err := processor.New(cfg.Processors)
+	if err != nil {
+		return nil, err
+	}
+	chain := &processor.Chain{}
+	if cfg.ValidateImages != "" {
+		chain.Add(imagecheck.Processor(cfg.ValidateImages), config.Processor{})
+	}
+	chain.Append(listed)
+	for _, rule := range cfg.Transcode {
+		chain.Add(transcode.New(rule), rule.Processor())
+	}
+	if cfg.Transform != nil && cfg.Transform.Resize != nil {
+		chain.Add(resize.New(*cfg.Transform.Resize), config.Processor{})
+	}
+	if cfg.Transform != nil && cfg.Transform.Watermark != nil {
+		mark, err := watermark.New(*cfg.Transform.Watermark)
+		if err != nil {
+			return nil, err
+		}
+		chain.Add(mark, config.Processor{})
+	}
+	// Last, as resizing and converting may have been done by commands
+	// copying the metadata.
+	if cfg.Transform != nil && cfg.Transform.StripMetadata {
+		chain.Add(metadata.Processor(), config.Processor{})
+	}
+	// After everything else, which works on the uncompressed content.
+	if cfg.Compress != "" {
+		chain.Add(compress.New(cfg.Compress), config.Processor{})
+	}
+	if chain.Empty() {
+		return nil, nil
+	}
+	return chain, nil
+}
@@ -0,0 +1,205 @@
+package copier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+)
+
+// testProcessor rejects, skips or upper-cases files by name.
+type testProcessor struct{}
+
+func (testProcessor) Name() string { return "test" }
+
+func (testProcessor) Inspect(_ context.Context, f processor.File) error {
+	switch {
+	case strings.HasPrefix(f.Name, "corrupt"):
+		return errors.New("corrupt")
+	case strings.HasPrefix(f.Name, "dup"):
+		return processor.ErrSkip
+	}
+	return nil
+}
+
+func (testProcessor) Transform(_ context.Context, f processor.File, r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(bytes.ToUpper(data)), nil
+}
+
+func (testProcessor) Check(_ context.Context, f processor.File) error {
+	if strings.HasPrefix(f.Name, "infected") {
+		return errors.New("infected")
+	}
+	return nil
+}
+
+func TestProcessors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		success bool
+		skipped bool
+		copied  string // content at the destination; empty for none
+	}{
+		{"transformed", "a.jpg", true, false, "HELLO"},
+		{"rejected before", "corrupt.jpg", false, false, ""},
+		{"skipped", "dup.jpg", false, true, ""},
+		{"rejected after", "infected.jpg", false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcDir, dstDir := t.TempDir(), t.TempDir()
+			src := filepath.Join(srcDir, tt.file)
+			if err := os.WriteFile(src, []byte("hello"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c := New(&config.Config{Source: srcDir, Destination: dstDir, Workers: 1})
+			c.AddProcessor(testProcessor{})
+
+			result := c.CopyFileWithRetry(context.Background(), src)
+			if result.Success != tt.success || result.Skipped != tt.skipped {
+				t.Errorf("Expected success %v and skipped %v, got %+v", tt.success, tt.skipped, result)
+			}
+			if !tt.success && !tt.skipped && result.Error == nil {
+				t.Error("Expected an error for a rejected file")
+			}
+			data, err := os.ReadFile(filepath.Join(dstDir, tt.file))
+			if tt.copied == "" {
+				if err == nil {
+					t.Errorf("Expected no copy, found %q", data)
+				}
+			} else if string(data) != tt.copied {
+				t.Errorf("Expected %q at the destination, got %q (%v)", tt.copied, data, err)
+			}
+		})
+	}
+}
+
+func TestProcessorExtensions(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	for _, name := range []string{"corrupt.jpg", "corrupt.png"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := New(&config.Config{Source: srcDir, Destination: dstDir, Workers: 1})
+	c.processors = &processor.Chain{}
+	c.processors.Add(testProcessor{}, config.Processor{Extensions: []string{"png"}})
+
+	if result := c.CopyFileWithRetry(context.Background(), filepath.Join(srcDir, "corrupt.jpg")); !result.Success {
+		t.Errorf("Expected the processor not to run on a JPEG, got %+v", result)
+	}
+	if result := c.CopyFileWithRetry(context.Background(), filepath.Join(srcDir, "corrupt.png")); result.Success {
+		t.Error("Expected the processor to reject the PNG")
+	}
+}
+
+func TestUnknownProcessor(t *testing.T) {
+	c := New(&config.Config{Source: t.TempDir(), Destination: t.TempDir(), Processors: []config.Processor{{Name: "nope"}}})
+	if err := c.Err(); err == nil || !strings.Contains(err.Error(), `unknown processor "nope"`) {
+		t.Errorf("Expected an unknown processor to fail the copier, got %v", err)
+	}
+}
+
+func TestValidateImagesCountsCorrupt(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	files := map[string][]byte{
+		"header.png": {0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}, // a signature without a header
+		"broken.jpg": {0xFF, 0xD8, 0xFF},
+		"notes.txt":  []byte("not an image"),
+	}
+	var paths []string
+	for name, data := range files {
+		path := filepath.Join(srcDir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	c := New(&config.Config{Source: srcDir, Destination: dstDir, Workers: 2, ValidateImages: config.ValidateHeader})
+
+	summary := c.CopyFilesParallelWithEvents(context.Background(), paths, nil)
+	if summary.Corrupt != 2 || summary.Failed != 2 || summary.Successful != 1 {
+		t.Errorf("Expected two corrupt images and the text file copied, got %+v", summary)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "broken.jpg")); err == nil {
+		t.Error("Expected the corrupt image not to be copied")
+	}
+}
+
+func TestTranscodeRenamesCopies(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(srcDir, "a.png")
+	if err := os.WriteFile(src, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := New(&config.Config{Source: srcDir, Destination: dstDir, Workers: 1,
+		Transcode: []config.TranscodeRule{{From: []string{"png"}, To: "jpeg"}}})
+
+	if got := c.DestPath(src); got != filepath.Join(dstDir, "a.jpg") {
+		t.Errorf("DestPath = %q", got)
+	}
+	if result := c.CopyFileWithRetry(context.Background(), src); !result.Success {
+		t.Fatalf("Copy failed: %v", result.Error)
+	}
+	data, err := os.ReadFile(filepath.Join(dstDir, "a.jpg"))
+	if err != nil || !bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		t.Errorf("Expected a JPEG copy, got %v", err)
+	}
+	// The converted copy counts as existing on the next run.
+	if result := c.CopyFileWithRetry(context.Background(), src); !result.Skipped {
+		t.Errorf("Expected the second copy to be skipped, got %+v", result)
+	}
+}
+
+func TestResizeOneDestination(t *testing.T) {
+	srcDir, archive, previews := t.TempDir(), t.TempDir(), t.TempDir()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 300, 200))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "a.png"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	group := config.CopyGroup{ID: "g", Source: srcDir, Enabled: true, Destinations: []config.Destination{
+		{ID: "archive", Path: archive, Enabled: true},
+		{ID: "previews", Path: previews, Enabled: true, Transform: &config.Transform{Resize: &config.Resize{MaxSize: 60}}},
+	}}
+	cfg := config.DefaultConfig()
+	_, err := RunGroup(context.Background(), cfg, group, func(ctx context.Context, c *Copier, files []string) CopySummary {
+		return c.CopyFilesParallelWithEvents(ctx, files, nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for dir, width := range map[string]int{archive: 300, previews: 60} {
+		f, err := os.Open(filepath.Join(dir, "a.png"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, _, err := image.DecodeConfig(f)
+		_ = f.Close()
+		if err != nil || img.Width != width {
+			t.Errorf("%s: expected a width of %d, got %d (%v)", dir, width, img.Width, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package copier
+
+import (
+	"cmp"
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"copy-image/internal/storage"
+)
+
+// scanBatch is how many entries of a folder a scan reads at a time, so a
+// folder of 300k files on a share is never held in memory at once.
+const scanBatch = 1000
+
+// scanWorkers bounds the folders of a recursive scan read at once. Most
+// of a scan of a share or an archive disk is waiting for the next listing,
+// so a few folders in flight cut the wait before the copy starts.
+const scanWorkers = 8
+
+// ScanProgress is what a scan reports while it reads the source: the
+// folder being read, the entries seen so far and how many of them are
+// files to copy.
+type ScanProgress struct {
+	Dir     string
+	Entries int
+	Files   int
+	// Done is set on the last report, once the scan finished.
+	Done bool
+}
+
+// scanProgressKey is the context key of the function scans report their
+// progress to.
+type scanProgressKey struct{}
+
+// WithScanProgress returns ctx making the scans run under it, such as
+// GetFilesContext and those of RunGroup, call fn with their progress, at
+// most every progressInterval and once when done. Folders are read
+// concurrently, but fn is never called concurrently.
+func WithScanProgress(ctx context.Context, fn func(ScanProgress)) context.Context {
+	return context.WithValue(ctx, scanProgressKey{}, fn)
+}
+
+// scanner counts what a scan saw and reports it.
+type scanner struct {
+	fn func(ScanProgress)
+
+	mu      sync.Mutex // held while counting and reporting, and by adders
+	last    time.Time
+	dir     string
+	entries int
+	files   int
+}
+
+func newScanner(ctx context.Context) *scanner {
+	fn, _ := ctx.Value(scanProgressKey{}).(func(ScanProgress))
+	return &scanner{fn: fn}
+}
+
+// seen counts n entries read from dir.
+func (s *scanner) seen(dir string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dir = dir
+	s.entries += n
+	if s.fn == nil {
+		return
+	}
+	if now := time.Now(); now.Sub(s.last) >= progressInterval {
+		s.last = now
+		s.fn(ScanProgress{Dir: dir, Entries: s.entries, Files: s.files})
+	}
+}
+
+// done reports the scan finished.
+func (s *scanner) done() {
+	if s.fn != nil {
+		s.fn(ScanProgress{Dir: s.dir, Entries: s.entries, Files: s.files, Done: true})
+	}
+}
+
+// scanTree calls add for the files of root and, with Recursive, of its
+// folders, reading up to scanWorkers folders at once. add may be called
+// concurrently. The first error stops the scan and is returned.
+func (c *Copier) scanTree(ctx context.Context, root string, sc *scanner, add func(path string)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex // guards first
+		first error
+	)
+	workers := make(chan struct{}, scanWorkers-1) // the caller is one of them
+	var visit func(dir string)
+	visit = func(dir string) {
+		err := c.scanDir(ctx, root, dir, sc, add, func(sub string) {
+			// Read the folder on a free worker, or right away: waiting for
+			// one could wait for this very folder to be read.
+			select {
+			case workers <- struct{}{}:
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { <-workers }()
+					visit(sub)
+				}()
+			default:
+				visit(sub)
+			}
+		})
+		if err != nil {
+			mu.Lock()
+			if first == nil {
+				first = err
+				cancel()
+			}
+			mu.Unlock()
+		}
+	}
+	visit(root)
+	wg.Wait()
+	return first
+}
+
+// scanDir calls add for the files of dir, a folder below the source root,
+// and with Recursive descend for its folders, skipping excluded files and
+// not descending into excluded folders. Entries are filtered as they
+// arrive, a batch at a time, until ctx is cancelled.
+func (c *Copier) scanDir(ctx context.Context, root, dir string, sc *scanner, add, descend func(path string)) error {
+	return storage.ListBatches(c.src, dir, scanBatch, func(entries []fs.DirEntry) error {
+		sc.seen(dir, len(entries))
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			path := filepath.Join(dir, entry.Name())
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			if c.config.IsExcluded(rel) {
+				continue
+			}
+			switch {
+			case entry.IsDir():
+				if c.config.Recursive {
+					descend(path)
+				}
+			case !c.config.Recursive || entry.Type().IsRegular():
+				add(path)
+			}
+		}
+		return nil
+	})
+}
+
+// comparePaths orders paths as a walk of their folders by name would:
+// the separator sorts before any other character, so "a/b" comes before
+// "a-b" like folder "a" comes before file "a-b".
+func comparePaths(a, b string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		ca, cb := a[i], b[i]
+		if ca == cb {
+			continue
+		}
+		if ca == filepath.Separator {
+			return -1
+		}
+		if cb == filepath.Separator {
+			return 1
+		}
+		return cmp.Compare(ca, cb)
+	}
+	return cmp.Compare(len(a), len(b))
+}
@@ -0,0 +1,137 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+)
+
+func TestScanBatchesAndProgress(t *testing.T) {
+	src := t.TempDir()
+	write := func(rel string) {
+		path := filepath.Join(src, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// More entries than a batch, so the folder is read in several.
+	for i := range scanBatch + 10 {
+		write(fmt.Sprintf("bulk/%05d.jpg", i))
+	}
+	for _, rel := range []string{"a-b.jpg", "a/x.jpg", "b.jpg", "skip/y.jpg", "notes.txt"} {
+		write(rel)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = src
+	cfg.Recursive = true
+	cfg.Extensions = []string{".jpg"}
+	cfg.Exclude = []string{"skip"}
+
+	var reports []ScanProgress
+	ctx := WithScanProgress(context.Background(), func(p ScanProgress) { reports = append(reports, p) })
+	files, err := New(cfg).GetFilesContext(ctx)
+	if err != nil {
+		t.Fatalf("GetFilesContext failed: %v", err)
+	}
+
+	if len(files) != scanBatch+13 {
+		t.Fatalf("Expected %d files, got %d", scanBatch+13, len(files))
+	}
+	// Ordered as a walk by name: folder "a" before "a-b.jpg".
+	want := []string{filepath.Join(src, "a", "x.jpg"), filepath.Join(src, "a-b.jpg"), filepath.Join(src, "b.jpg")}
+	if !slices.Equal(files[:3], want) {
+		t.Errorf("Expected %v first, got %v", want, files[:3])
+	}
+	if !slices.IsSortedFunc(files, comparePaths) {
+		t.Error("Expected the files in walk order")
+	}
+
+	if len(reports) == 0 {
+		t.Fatal("Expected scan progress")
+	}
+	last := reports[len(reports)-1]
+	// The files, and bulk, a, skip and notes.txt; skip is not read.
+	if !last.Done || last.Files != len(files) || last.Entries != len(files)+4 {
+		t.Errorf("Unexpected last report: %+v", last)
+	}
+}
+
+func TestComparePaths(t *testing.T) {
+	sep := string(filepath.Separator)
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"a" + sep + "b", "a-b", -1},
+		{"a-b", "a" + sep + "b", 1},
+		{"a", "a" + sep + "b", -1},
+		{"b", "a" + sep + "z", 1},
+		{"x", "x", 0},
+	}
+	for _, tt := range tests {
+		if got := comparePaths(tt.a, tt.b); got != tt.want {
+			t.Errorf("comparePaths(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// failingList is a Memory source whose folder bad cannot be listed.
+type failingList struct {
+	*storage.Memory
+	bad string
+}
+
+func (s failingList) List(dir string) ([]fs.DirEntry, error) {
+	if dir == s.bad {
+		return nil, errors.New("share went away")
+	}
+	return s.Memory.List(dir)
+}
+
+func TestScanFoldersConcurrently(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "archive")
+	mem := storage.NewMemory()
+	var want []string
+	for d := range 40 {
+		for f := range 15 {
+			path := filepath.Join(root, fmt.Sprintf("%02d", d), "raw", fmt.Sprintf("%02d.jpg", f))
+			_ = mem.WriteFile(path, []byte("x"))
+			want = append(want, path)
+		}
+	}
+	slices.SortFunc(want, comparePaths)
+
+	cfg := config.DefaultConfig()
+	cfg.Source = root
+	cfg.Recursive = true
+	for range 3 {
+		c := New(cfg)
+		c.SetStorage(mem, storage.NewMemory())
+		files, err := c.GetFilesContext(context.Background())
+		if err != nil {
+			t.Fatalf("GetFilesContext failed: %v", err)
+		}
+		if !slices.Equal(files, want) {
+			t.Fatalf("Expected the %d files in walk order, got %d: %v", len(want), len(files), files[:min(5, len(files))])
+		}
+	}
+
+	c := New(cfg)
+	c.SetStorage(failingList{mem, filepath.Join(root, "17", "raw")}, storage.NewMemory())
+	if _, err := c.GetFilesContext(context.Background()); err == nil || !strings.Contains(err.Error(), "share went away") {
+		t.Errorf("Expected the folder's error, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package copier
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer returns the tracer of the batch and file spans. It comes from
+// the global TracerProvider, which does nothing until tracing is set up
+// (see package tracing), so spans cost next to nothing by default. It is
+// looked up for each span rather than once, as a tracer only follows the
+// first provider set after it was made.
+func tracer() trace.Tracer {
+	return otel.Tracer("copy-image/internal/copier")
+}
+
+// startBatchSpan starts the span covering a batch of files.
+func (c *Copier) startBatchSpan(ctx context.Context, files int) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "copy batch", trace.WithAttributes(
+		attribute.String("copy.destination", c.config.Destination),
+		attribute.Int("copy.files", files),
+		attribute.Int("copy.workers", c.config.Workers),
+		attribute.Bool("copy.dry_run", c.config.DryRun),
+	))
+}
+
+// endBatchSpan records the outcome of a batch on its span and ends it.
+func endBatchSpan(span trace.Span, summary CopySummary) {
+	span.SetAttributes(
+		attribute.Int("copy.successful", summary.Successful),
+		attribute.Int("copy.skipped", summary.Skipped),
+		attribute.Int("copy.failed", summary.Failed),
+	)
+	if summary.Failed > 0 {
+		span.SetStatus(codes.Error, "some files failed")
+	}
+	span.End()
+}
+
+// endFileSpan records the outcome of a file on its span and ends it.
+func endFileSpan(span trace.Span, result CopyResult) {
+	status := "copied"
+	switch {
+	case result.Skipped:
+		status = "skipped"
+	case !result.Success:
+		status = "failed"
+	}
+	span.SetAttributes(
+		attribute.String("file.status", status),
+		attribute.Int64("file.size", result.Bytes),
+		attribute.Int("file.retries", result.Retries),
+	)
+	if result.Error != nil {
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Error.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,80 @@
+package copier
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	src := storage.NewMemory()
+	root := filepath.Join(string(filepath.Separator), "card")
+	files := []string{filepath.Join(root, "a.jpg"), filepath.Join(root, "gone.jpg")}
+	if err := src.WriteFile(files[0], []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Source = root
+	cfg.Destination = filepath.Join(string(filepath.Separator), "lab")
+	c := New(cfg)
+	c.SetStorage(src, storage.NewMemory())
+
+	c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+
+	spans := recorder.Ended()
+	var batch sdktrace.ReadOnlySpan
+	fileSpans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range spans {
+		switch s.Name() {
+		case "copy batch":
+			batch = s
+		case "copy file":
+			for _, kv := range s.Attributes() {
+				if kv.Key == "file.name" {
+					fileSpans[kv.Value.AsString()] = s
+				}
+			}
+		}
+	}
+	if batch == nil || len(fileSpans) != 2 {
+		t.Fatalf("Expected a batch span and 2 file spans, got %d spans", len(spans))
+	}
+	for name, s := range fileSpans {
+		if s.Parent().SpanID() != batch.SpanContext().SpanID() {
+			t.Errorf("Span of %s is not a child of the batch span", name)
+		}
+	}
+
+	attrs := func(s sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		m := map[attribute.Key]attribute.Value{}
+		for _, kv := range s.Attributes() {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+	ok := attrs(fileSpans["a.jpg"])
+	if ok["file.status"].AsString() != "copied" || ok["file.size"].AsInt64() != 5 || ok["file.destination"].AsString() != filepath.Join(cfg.Destination, "a.jpg") {
+		t.Errorf("Unexpected attributes for a.jpg: %v", ok)
+	}
+	if attrs(fileSpans["gone.jpg"])["file.status"].AsString() != "failed" || len(fileSpans["gone.jpg"].Events()) == 0 {
+		t.Error("Expected the failed file's span to record its error")
+	}
+	b := attrs(batch)
+	if b["copy.files"].AsInt64() != 2 || b["copy.successful"].AsInt64() != 1 || b["copy.failed"].AsInt64() != 1 {
+		t.Errorf("Unexpected batch attributes: %v", b)
+	}
+}
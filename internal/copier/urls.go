@@ -0,0 +1,43 @@
+package copier
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"copy-image/internal/config"
+)
+
+// urlFiles reads the URL list of Config.URLsFrom: one http(s) URL per
+// line, with blank lines and lines starting with '#' ignored. URLs are
+// filtered by the extension in their path and returned once each.
+func (c *Copier) urlFiles() ([]string, error) {
+	f, err := os.Open(c.config.URLsFrom)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open URL list: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var urls []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		u := strings.TrimSpace(scanner.Text())
+		if u == "" || strings.HasPrefix(u, "#") {
+			continue
+		}
+		if !config.IsHTTP(u) {
+			return nil, fmt.Errorf("%s:%d: not an http(s) URL: %s", c.config.URLsFrom, line, u)
+		}
+		if seen[u] || !c.wanted(u) {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read URL list: %w", err)
+	}
+	return urls, nil
+}
@@ -0,0 +1,86 @@
+package copier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"copy-image/internal/config"
+)
+
+func TestCopyURLList(t *testing.T) {
+	var flaky atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/img/a.jpg":
+			_, _ = w.Write([]byte("jpeg"))
+		case "/img/busy.jpg":
+			// A busy CDN recovers on retry.
+			if flaky.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte("later"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	list := filepath.Join(t.TempDir(), "urls.txt")
+	lines := []string{
+		"# product shots",
+		server.URL + "/img/a.jpg?w=1200",
+		server.URL + "/img/a.jpg?w=1200",
+		server.URL + "/img/busy.jpg",
+		server.URL + "/img/missing.jpg",
+		server.URL + "/readme.txt",
+		"",
+	}
+	if err := os.WriteFile(list, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.URLsFrom = list
+	cfg.Destination = t.TempDir()
+	cfg.Extensions = []string{".jpg"}
+	cfg.MaxRetries = 2
+	c := New(cfg)
+
+	files, err := c.GetFiles()
+	if err != nil {
+		t.Fatalf("GetFiles failed: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 image URLs once each, got %v", files)
+	}
+
+	summary := c.CopyFilesParallelWithEvents(context.Background(), files, nil)
+	if summary.Successful != 2 || summary.Failed != 1 {
+		t.Errorf("Expected 2 downloaded and the missing one failed, got %+v", summary)
+	}
+	if data, err := os.ReadFile(filepath.Join(cfg.Destination, "a.jpg")); err != nil || string(data) != "jpeg" {
+		t.Errorf("Expected a.jpg named after the URL path, got %q, %v", data, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(cfg.Destination, "busy.jpg")); err != nil || string(data) != "later" {
+		t.Errorf("Expected busy.jpg after a retry, got %q, %v", data, err)
+	}
+}
+
+func TestURLListRejectsPaths(t *testing.T) {
+	list := filepath.Join(t.TempDir(), "urls.txt")
+	if err := os.WriteFile(list, []byte("https://example.com/a.jpg\nC:\\photos\\b.jpg\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.URLsFrom = list
+	if _, err := New(cfg).GetFiles(); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("Expected an error pointing at line 2, got %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package copier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"copy-image/internal/compress"
+	"copy-image/internal/config"
+	"copy-image/internal/lock"
+	"copy-image/internal/storage"
+	"copy-image/internal/utils"
+)
+
+// VerifyReport lists how a destination differs from its source. Paths are
+// relative to the destination.
+type VerifyReport struct {
+	Destination string `json:"destination"`
+	Hashed      bool   `json:"hashed"`  // contents were compared, not just sizes
+	Checked     int    `json:"checked"` // source files compared
+	OK          int    `json:"ok"`
+
+	Missing    []string `json:"missing"`    // in the source only
+	Extra      []string `json:"extra"`      // in the destination only
+	Mismatched []string `json:"mismatched"` // different size or content
+}
+
+// Clean reports whether the destination holds every source file intact.
+// Extra files do not count: destinations often collect several sources.
+func (r *VerifyReport) Clean() bool {
+	return len(r.Missing) == 0 && len(r.Mismatched) == 0
+}
+
+// WriteCSV writes the differences as status,path rows, for spreadsheets.
+func (r *VerifyReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"status", "path"})
+	for _, group := range []struct {
+		status string
+		paths  []string
+	}{{"missing", r.Missing}, {"extra", r.Extra}, {"mismatched", r.Mismatched}} {
+		for _, p := range group.paths {
+			_ = cw.Write([]string{group.status, p})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Export writes the report to path, as CSV when the name ends in .csv and
+// as indented JSON otherwise.
+func (r *VerifyReport) Export(path string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to write report: %w", cerr)
+		}
+	}()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return r.WriteCSV(f)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Verify compares the destination with the source without copying: every
+// source file (after filters) must exist at its DestPath with the same
+// size, and with hash set the same SHA-256. Compressed copies
+// (Config.Compress) are decompressed and always hashed, as their size
+// tells nothing. Destination files that no
+// source file maps to, among those the filters would pick, are extra.
+// Hashing uses the configured number of workers.
+func (c *Copier) Verify(ctx context.Context, hash bool) (*VerifyReport, error) {
+	files, err := c.GetFilesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report, expected, err := c.compare(ctx, files, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.walkDestination(func(path string) {
+		if !expected[path] {
+			report.Extra = append(report.Extra, c.relDest(path))
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(report.Extra)
+	return report, nil
+}
+
+// VerifyFiles is Verify for the given source files only, such as those of
+// a batch that was just copied. Extra destination files are not looked for.
+func (c *Copier) VerifyFiles(ctx context.Context, files []string, hash bool) (*VerifyReport, error) {
+	report, _, err := c.compare(ctx, files, hash)
+	return report, err
+}
+
+// compare checks files against their DestPath for Verify and VerifyFiles.
+// It also returns the destination paths that were expected.
+func (c *Copier) compare(ctx context.Context, files []string, hash bool) (*VerifyReport, map[string]bool, error) {
+	compressed := c.config.Compress != ""
+	hash = hash || compressed
+	report := &VerifyReport{
+		Destination: c.config.Destination,
+		Hashed:      hash,
+		Missing:     []string{},
+		Extra:       []string{},
+		Mismatched:  []string{},
+	}
+	expected := make(map[string]bool, len(files))
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, max(c.config.Workers, 1))
+	)
+	// Hashing workers update the report while the loop goes on.
+	add := func(list *[]string, name string) {
+		mu.Lock()
+		*list = append(*list, name)
+		mu.Unlock()
+	}
+	ok := func() {
+		mu.Lock()
+		report.OK++
+		mu.Unlock()
+	}
+
+	// record adds the outcome of VerifyFile to the report.
+	record := func(name string, err error) {
+		switch {
+		case err == nil:
+			ok()
+		case errors.Is(err, ErrChecksumMismatch):
+			add(&report.Mismatched, name)
+		default:
+			add(&report.Missing, name)
+		}
+	}
+
+	for _, src := range files {
+		if err := ctx.Err(); err != nil {
+			break
+		}
+		dst := c.DestPath(src)
+		expected[dst] = true
+		name := c.relDest(dst)
+		report.Checked++
+
+		if _, err := c.src.Stat(src); err != nil {
+			// Vanished since the scan; nothing to compare.
+			continue
+		}
+		if !hash {
+			record(name, c.VerifyFile(src, false))
+			continue
+		}
+
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			record(name, c.VerifyFile(src, true))
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(report.Missing)
+	sort.Strings(report.Mismatched)
+	return report, expected, nil
+}
+
+// walkDestination calls fn for the destination files a copy could have
+// written: those passing the filters, in subfolders only in recursive
+// mode. Lock files, checksum manifests and MHL files are left out.
+func (c *Copier) walkDestination(fn func(path string)) error {
+	root := c.config.Destination
+	if !storage.IsDir(c.dst, root) {
+		// Nothing was copied yet: every file is missing, none extra.
+		return nil
+	}
+	err := storage.Walk(c.dst, root, func(path string, d fs.DirEntry) error {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if !c.config.Recursive || c.config.IsExcluded(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		switch {
+		case !d.Type().IsRegular(), d.Name() == lock.FileName, config.IsChecksumFile(d.Name()), isMHLFile(d.Name()), c.config.IsExcluded(rel):
+		case c.config.HasExtensionFilter() && !c.config.IsExtensionAllowed(ext):
+		default:
+			fn(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read destination directory: %w", err)
+	}
+	return nil
+}
+
+// relDest returns path relative to the destination, for reports.
+func (c *Copier) relDest(path string) string {
+	if rel, err := filepath.Rel(c.config.Destination, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// VerifyFile compares the copy of sourcePath with it: their sizes, and
+// with hash set their SHA-256. Compressed copies are always hashed, as
+// their size tells nothing. The error matches ErrChecksumMismatch when
+// they differ, and utils.ErrNotFound when either is missing.
+func (c *Copier) VerifyFile(sourcePath string, hash bool) error {
+	dst := c.DestPath(sourcePath)
+	srcInfo, err := c.src.Stat(sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to read source file: %w", utils.Classify(err))
+	}
+	dstInfo, err := c.dst.Stat(dst)
+	if err != nil {
+		return fmt.Errorf("failed to read copy: %w", utils.Classify(err))
+	}
+	compressed := c.config.Compress != ""
+	if !compressed && dstInfo.Size() != srcInfo.Size() {
+		return fmt.Errorf("%s: %w: %d bytes instead of %d", c.relDest(dst), ErrChecksumMismatch, dstInfo.Size(), srcInfo.Size())
+	}
+	if (hash || compressed) && !c.sameContent(sourcePath, dst) {
+		return fmt.Errorf("%s: %w", c.relDest(dst), ErrChecksumMismatch)
+	}
+	return nil
+}
+
+// sameContent reports whether the source and destination files have the
+// same SHA-256.
+func (c *Copier) sameContent(src, dst string) bool {
+	ha, err := hashFile(c.src, src)
+	if err != nil {
+		return false
+	}
+	var dstStorage storage.Storage = c.dst
+	if c.config.Compress != "" {
+		dstStorage = decompressed{c.dst, c.config.Compress}
+	}
+	hb, err := hashFile(dstStorage, dst)
+	return err == nil && ha == hb
+}
+
+// decompressed is a destination whose files are read decompressed.
+type decompressed struct {
+	storage.Storage
+	algo string
+}
+
+func (d decompressed) Open(path string) (io.ReadCloser, error) {
+	f, err := d.Storage.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return compress.NewReader(d.algo, f)
+}
+
+// hashFile returns the hex SHA-256 of the file at path in s.
+func hashFile(s storage.Storage, path string) (string, error) {
+	f, err := s.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,142 @@
+package copier
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/lock"
+)
+
+func TestVerify(t *testing.T) {
+	srcDir := t.TempDir()
+	dstDir := t.TempDir()
+	write := func(dir, name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(srcDir, "ok.jpg", "same")
+	write(dstDir, "ok.jpg", "same")
+	write(srcDir, "missing.jpg", "m")
+	write(srcDir, "size.jpg", "short")
+	write(dstDir, "size.jpg", "longer content")
+	write(srcDir, "content.jpg", "aaaa")
+	write(dstDir, "content.jpg", "bbbb")
+	write(dstDir, "extra.jpg", "x")
+	write(dstDir, "notes.txt", "not an image")
+	write(dstDir, lock.FileName, "{}")
+
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+	cfg.Extensions = []string{".jpg"}
+
+	tests := []struct {
+		name       string
+		hash       bool
+		ok         int
+		mismatched []string
+	}{
+		{"sizes only", false, 2, []string{"size.jpg"}},
+		{"with hashes", true, 1, []string{"content.jpg", "size.jpg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := New(cfg).Verify(context.Background(), tt.hash)
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if report.Checked != 4 || report.OK != tt.ok {
+				t.Errorf("Expected 4 checked and %d ok, got %d and %d", tt.ok, report.Checked, report.OK)
+			}
+			if strings.Join(report.Missing, ",") != "missing.jpg" {
+				t.Errorf("Unexpected missing files: %v", report.Missing)
+			}
+			if strings.Join(report.Extra, ",") != "extra.jpg" {
+				t.Errorf("Unexpected extra files: %v", report.Extra)
+			}
+			if strings.Join(report.Mismatched, ",") != strings.Join(tt.mismatched, ",") {
+				t.Errorf("Expected mismatched %v, got %v", tt.mismatched, report.Mismatched)
+			}
+			if report.Clean() {
+				t.Error("Expected the report not to be clean")
+			}
+		})
+	}
+
+	report, err := New(cfg).Verify(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	want := "status,path\nmissing,missing.jpg\nextra,extra.jpg\nmismatched,size.jpg\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestVerifyMissingDestination(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(srcDir, "a.jpg"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = filepath.Join(t.TempDir(), "not-yet")
+
+	report, err := New(cfg).Verify(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(report.Missing) != 1 || len(report.Extra) != 0 {
+		t.Errorf("Expected everything to be missing, got %+v", report)
+	}
+}
+
+func TestVerifyCompressed(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	for name, content := range map[string]string{"a.tif": "aaaa", "b.tif": "bbbb"} {
+		if err := os.WriteFile(filepath.Join(srcDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := config.DefaultConfig()
+	cfg.Source = srcDir
+	cfg.Destination = dstDir
+	cfg.Extensions = []string{".tif"}
+	cfg.Compress = config.CompressZstd
+
+	c := New(cfg)
+	for _, name := range []string{"a.tif", "b.tif"} {
+		if result := c.CopyFileWithRetry(context.Background(), filepath.Join(srcDir, name)); !result.Success {
+			t.Fatalf("Copy failed: %v", result.Error)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "a.tif.zst")); err != nil {
+		t.Fatalf("Expected a compressed copy: %v", err)
+	}
+	// The source changed after the copy: only a decompressed hash tells.
+	if err := os.WriteFile(filepath.Join(srcDir, "b.tif"), []byte("cccc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := New(cfg).Verify(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if report.Checked != 2 || report.OK != 1 || strings.Join(report.Mismatched, ",") != "b.tif.zst" {
+		t.Errorf("Expected b.tif.zst to mismatch, got %+v", report)
+	}
+	if len(report.Extra) != 0 {
+		t.Errorf("Expected no extra files, got %v", report.Extra)
+	}
+}
@@ -0,0 +1,234 @@
+package copier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+	"copy-image/internal/utils"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchOptions tune how Watch decides that a new file is complete.
+type WatchOptions struct {
+	// Debounce is how long a file must go without change events before
+	// it is checked.
+	Debounce time.Duration
+
+	// Settle is how long the file's size and modification time must stay
+	// the same before it is copied. Cameras and tethering software often
+	// write a file in several steps with pauses in between.
+	Settle time.Duration
+}
+
+// DefaultWatchOptions returns the options used by `copyimage watch` and the
+// desktop app.
+func DefaultWatchOptions() WatchOptions {
+	return WatchOptions{
+		Debounce: 500 * time.Millisecond,
+		Settle:   2 * time.Second,
+	}
+}
+
+// pendingFile is a file that changed but has not been copied yet.
+type pendingFile struct {
+	lastEvent time.Time
+	size      int64
+	modTime   time.Time
+	checkedAt time.Time // when size and modTime were recorded; zero if never
+}
+
+// Watch monitors the source folders and calls onReady with the files that
+// were created or changed and are no longer being written, until ctx is
+// cancelled. Files are filtered like GetFiles, and in recursive mode new
+// subfolders are watched as they appear. onReady runs on Watch's goroutine,
+// so batches never overlap; changes made meanwhile are picked up after it
+// returns. Files already in the source when Watch starts are not reported.
+func (c *Copier) Watch(ctx context.Context, opts WatchOptions, onReady func(files []string)) error {
+	if _, local := c.src.(storage.Local); !local {
+		return errors.New("watch mode needs a local source folder")
+	}
+	dirs, err := c.sourceDirs()
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch source: %w", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	pending := make(map[string]*pendingFile)
+	for _, dir := range dirs {
+		if err := c.watchDir(watcher, dir, nil); err != nil {
+			return err
+		}
+	}
+
+	tick := time.NewTicker(max(opts.Debounce/2, 10*time.Millisecond))
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			c.watchEvent(watcher, event, pending)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			c.logger.Warn("watch error", "error", err)
+		case now := <-tick.C:
+			if ready := readyFiles(pending, now, opts); len(ready) > 0 {
+				onReady(ready)
+			}
+		}
+	}
+}
+
+// watchEvent records a file change in pending. In recursive mode a new
+// folder is watched too, and files already in it (e.g. a folder moved in
+// or copied with its contents) become pending.
+func (c *Copier) watchEvent(watcher *fsnotify.Watcher, event fsnotify.Event, pending map[string]*pendingFile) {
+	path := event.Name
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		// Renamed files reappear under their new name with a Create.
+		delete(pending, path)
+		return
+	}
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	mark := func(p string) {
+		if c.wanted(p) {
+			pending[p] = &pendingFile{lastEvent: time.Now()}
+		}
+	}
+	switch {
+	case info.IsDir() && event.Has(fsnotify.Create) && c.config.Recursive && !c.excluded(path):
+		if err := c.watchDir(watcher, path, mark); err != nil {
+			c.logger.Warn("cannot watch folder", "path", path, "error", err)
+		}
+	case info.Mode().IsRegular():
+		if p, ok := pending[path]; ok {
+			p.lastEvent = time.Now()
+		} else {
+			mark(path)
+		}
+	}
+}
+
+// watchDir adds dir to watcher, and in recursive mode every subfolder not
+// excluded. If found is not nil it is called with the files found there.
+func (c *Copier) watchDir(watcher *fsnotify.Watcher, dir string, found func(path string)) error {
+	if !c.config.Recursive {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+		return nil
+	}
+
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && c.excluded(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if err := watcher.Add(path); err != nil {
+				return fmt.Errorf("failed to watch %s: %w", path, err)
+			}
+		} else if found != nil && d.Type().IsRegular() {
+			found(path)
+		}
+		return nil
+	})
+}
+
+// readyFiles removes and returns, sorted, the pending files that are
+// complete: quiet for opts.Debounce, unchanged for opts.Settle, not locked
+// and not open for writing by another program (detectable on Windows only).
+// Files that cannot be read for other reasons are copied and fail visibly.
+// Files that disappeared are dropped.
+func readyFiles(pending map[string]*pendingFile, now time.Time, opts WatchOptions) []string {
+	var ready []string
+	for path, p := range pending {
+		if now.Sub(p.lastEvent) < opts.Debounce {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			delete(pending, path)
+			continue
+		}
+		if p.checkedAt.IsZero() || info.Size() != p.size || !info.ModTime().Equal(p.modTime) {
+			p.size, p.modTime, p.checkedAt = info.Size(), info.ModTime(), now
+			continue
+		}
+		if now.Sub(p.checkedAt) < opts.Settle || utils.IsFileLocked(path) || utils.IsFileBeingWritten(path) {
+			continue
+		}
+		delete(pending, path)
+		ready = append(ready, path)
+	}
+	sort.Strings(ready)
+	return ready
+}
+
+// WatchGroup watches the group's source like Watch and copies each batch
+// of new files to every enabled destination, narrowed by the
+// destination's own filters. onBatch, if not nil, receives the result of
+// each batch.
+func WatchGroup(ctx context.Context, base *config.Config, group config.CopyGroup, opts WatchOptions, run DestinationRunner, onBatch func(GroupSummary)) error {
+	watchCfg := base.ForDestination(group, config.Destination{})
+	for _, dest := range group.Destinations {
+		if dest.Enabled && dest.HasFilters() {
+			watchCfg.Extensions = nil
+			watchCfg.Exclude = nil
+			break
+		}
+	}
+
+	err := New(watchCfg).Watch(ctx, opts, func(files []string) {
+		result := GroupSummary{GroupID: group.ID, GroupName: group.Name}
+		for _, dest := range group.Destinations {
+			if !dest.Enabled || ctx.Err() != nil {
+				continue
+			}
+			c := New(base.ForDestination(group, dest))
+			result.Destinations = append(result.Destinations, DestinationSummary{
+				DestinationID: dest.ID,
+				Path:          dest.Path,
+				Summary:       run(ctx, c, c.Filter(files)),
+			})
+		}
+		if onBatch != nil {
+			onBatch(result)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("group %q: %w", group.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package copier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+func TestWatch(t *testing.T) {
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "old.jpg"), []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Source = src
+	cfg.Destination = t.TempDir()
+	cfg.Recursive = true
+	cfg.Extensions = []string{".jpg"}
+	cfg.Exclude = []string{"skip"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	batches := make(chan []string, 10)
+	done := make(chan error, 1)
+	opts := WatchOptions{Debounce: 20 * time.Millisecond, Settle: 50 * time.Millisecond}
+	go func() {
+		done <- New(cfg).Watch(ctx, opts, func(files []string) { batches <- files })
+	}()
+	time.Sleep(100 * time.Millisecond) // let Watch add its watches
+
+	for _, dir := range []string{"sub", "skip"} {
+		if err := os.Mkdir(filepath.Join(src, dir), 0755); err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+	}
+	time.Sleep(100 * time.Millisecond) // let Watch pick up the new folders
+	for _, name := range []string{"new.jpg", "notes.txt", filepath.Join("sub", "deep.jpg"), filepath.Join("skip", "x.jpg")} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	got := make(map[string]bool)
+	deadline := time.After(5 * time.Second)
+	for len(got) < 2 {
+		select {
+		case files := <-batches:
+			for _, f := range files {
+				rel, _ := filepath.Rel(src, f)
+				got[rel] = true
+			}
+		case <-deadline:
+			t.Fatalf("Timed out; got %v", got)
+		}
+	}
+	// Give stray files time to show up before checking nothing else came.
+	time.Sleep(200 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Watch returned error: %v", err)
+	}
+	close(batches)
+	for files := range batches {
+		for _, f := range files {
+			rel, _ := filepath.Rel(src, f)
+			got[rel] = true
+		}
+	}
+
+	want := map[string]bool{"new.jpg": true, filepath.Join("sub", "deep.jpg"): true}
+	if len(got) != len(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	for f := range want {
+		if !got[f] {
+			t.Errorf("Expected %s to be reported, got %v", f, got)
+		}
+	}
+}
+
+func TestReadyFilesWaitsForSettle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "growing.jpg")
+	if err := os.WriteFile(path, []byte("part"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	opts := WatchOptions{Debounce: time.Second, Settle: time.Second}
+	start := time.Now()
+	pending := map[string]*pendingFile{path: {lastEvent: start}}
+
+	if ready := readyFiles(pending, start.Add(500*time.Millisecond), opts); len(ready) != 0 {
+		t.Errorf("Expected nothing before the debounce, got %v", ready)
+	}
+	// The first check only records the size.
+	if ready := readyFiles(pending, start.Add(time.Second), opts); len(ready) != 0 {
+		t.Errorf("Expected nothing on the first check, got %v", ready)
+	}
+
+	// The file grows: the settle time starts over.
+	if err := os.WriteFile(path, []byte("part and more"), 0644); err != nil {
+		t.Fatalf("Failed to grow test file: %v", err)
+	}
+	if ready := readyFiles(pending, start.Add(2*time.Second), opts); len(ready) != 0 {
+		t.Errorf("Expected a growing file not to be ready, got %v", ready)
+	}
+	if ready := readyFiles(pending, start.Add(3*time.Second), opts); len(ready) != 1 {
+		t.Errorf("Expected the settled file to be ready, got %v", ready)
+	}
+	if len(pending) != 0 {
+		t.Errorf("Expected the ready file to leave pending, got %v", pending)
+	}
+}
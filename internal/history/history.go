@@ -0,0 +1,234 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// FileName is the name of the history database, kept next to the config
+// file.
+const FileName = "history.db"
+
+// openTimeout is how long to wait for another process (the desktop app,
+// a service or a second CLI) that is writing to the database.
+const openTimeout = 5 * time.Second
+
+var runsBucket = []byte("runs")
+
+// ErrNotFound is returned by Get for an unknown run ID.
+var ErrNotFound = errors.New("run not found")
+
+// Kinds of runs, telling what started them.
+const (
+	KindCopy     = "copy"
+	KindGroup    = "group"
+	KindWatch    = "watch"
+	KindSchedule = "schedule"
+	KindCard     = "card"
+	KindOffload  = "offload"
+	KindAPI      = "api"   // started through `copyimage serve`
+	KindRetry    = "retry" // the failed files of an earlier run
+)
+
+// Run records one batch: a copy to one destination.
+type Run struct {
+	ID          uint64    `json:"id"`
+	Kind        string    `json:"kind"`
+	GroupID     string    `json:"groupId,omitempty"`
+	Sources     []string  `json:"sources"`
+	Destination string    `json:"destination"`
+	Start       time.Time `json:"start"`
+	Duration    float64   `json:"duration"` // seconds
+	DryRun      bool      `json:"dryRun"`
+	Cancelled   bool      `json:"cancelled,omitempty"`
+
+	TotalFiles int      `json:"totalFiles"`
+	Successful int      `json:"successful"`
+	Failed     int      `json:"failed"`
+	Skipped    int      `json:"skipped"`
+	Failures   []string `json:"failures,omitempty"`
+	// FailedPaths are the source paths of the failed files, which a retry
+	// copies again.
+	FailedPaths []string `json:"failedPaths,omitempty"`
+
+	// Created lists the files the run added to the destination, which
+	// are the ones Undo removes.
+	Created []copier.CreatedFile `json:"created,omitempty"`
+	// UndoneAt is set once the run was undone.
+	UndoneAt *time.Time `json:"undoneAt,omitempty"`
+
+	// Config is the effective configuration the batch ran with, without
+	// its secrets (see config.Config.Redacted).
+	Config *config.Config `json:"config,omitempty"`
+}
+
+// NewRun describes the batch c copied, which started at start, with the
+// copier's settings, redacted, as the config snapshot.
+func NewRun(kind, groupID string, c *copier.Copier, start time.Time, summary copier.CopySummary) *Run {
+	cfg := c.Config()
+	return &Run{
+		Kind:        kind,
+		GroupID:     groupID,
+		Sources:     cfg.SourcePatterns(),
+		Destination: cfg.Destination,
+		Start:       start,
+		Duration:    time.Since(start).Seconds(),
+		DryRun:      cfg.DryRun,
+		TotalFiles:  summary.TotalFiles,
+		Successful:  summary.Successful,
+		Failed:      summary.Failed,
+		Skipped:     summary.Skipped,
+		Failures:    summary.FailureLines(),
+		FailedPaths: summary.FailedPaths(),
+		Created:     summary.Created,
+		Config:      cfg.Redacted(),
+	}
+}
+
+// Finished reports whether every file of the run was copied or skipped.
+func (r Run) Finished() bool {
+	return !r.Cancelled && r.Failed == 0
+}
+
+// DB is the run history stored at a path. The file is opened for each
+// call rather than kept open, because only one process at a time may
+// open it and the app, services and CLI commands share it.
+type DB struct {
+	path string
+}
+
+// Open returns the history stored at path. The file is created on the
+// first Add.
+func Open(path string) *DB {
+	return &DB{path: path}
+}
+
+// Add stores run, assigning its ID.
+func (d *DB) Add(run *Run) error {
+	if err := os.MkdirAll(filepath.Dir(d.path), 0700); err != nil {
+		return fmt.Errorf("failed to create history folder: %w", err)
+	}
+	return d.update(func(b *bolt.Bucket) error {
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		run.ID = id
+		data, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		return b.Put(key(id), data)
+	})
+}
+
+// List returns up to limit runs, newest first (all runs if limit <= 0).
+// The config snapshot and the file lists are left out to keep it light;
+// use Get for those.
+func (d *DB) List(limit int) ([]Run, error) {
+	var runs []Run
+	err := d.view(func(b *bolt.Bucket) error {
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil && (limit <= 0 || len(runs) < limit); k, v = c.Prev() {
+			var r Run
+			if err := json.Unmarshal(v, &r); err != nil {
+				return fmt.Errorf("run %d: %w", binary.BigEndian.Uint64(k), err)
+			}
+			r.Config = nil
+			r.Failures = nil
+			r.FailedPaths = nil
+			r.Created = nil
+			runs = append(runs, r)
+		}
+		return nil
+	})
+	return runs, err
+}
+
+// Last returns the latest run with its details, or ErrNotFound when there
+// is none.
+func (d *DB) Last() (*Run, error) {
+	runs, err := d.List(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, ErrNotFound
+	}
+	return d.Get(runs[0].ID)
+}
+
+// Get returns the run with the given ID, including its details.
+func (d *DB) Get(id uint64) (*Run, error) {
+	var run *Run
+	err := d.view(func(b *bolt.Bucket) error {
+		v := b.Get(key(id))
+		if v == nil {
+			return nil
+		}
+		run = &Run{}
+		return json.Unmarshal(v, run)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return run, nil
+}
+
+// update runs fn in a write transaction on the runs bucket.
+func (d *DB) update(fn func(b *bolt.Bucket) error) error {
+	db, err := bolt.Open(d.path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	return db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(runsBucket)
+		if err != nil {
+			return err
+		}
+		return fn(b)
+	})
+}
+
+// view runs fn in a read transaction on the runs bucket. Without a
+// history file there are no runs and fn is not called.
+func (d *DB) view(fn func(b *bolt.Bucket) error) error {
+	if _, err := os.Stat(d.path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	db, err := bolt.Open(d.path, 0600, &bolt.Options{Timeout: openTimeout, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	return db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(runsBucket)
+		if b == nil {
+			return nil
+		}
+		return fn(b)
+	})
+}
+
+// key encodes id so runs sort in insertion order.
+func key(id uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, id)
+	return k
+}
@@ -0,0 +1,74 @@
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+func TestAddListGet(t *testing.T) {
+	db := Open(filepath.Join(t.TempDir(), "sub", FileName))
+
+	runs, err := db.List(0)
+	if err != nil || len(runs) != 0 {
+		t.Fatalf("Expected an empty history, got %v, %v", runs, err)
+	}
+	if _, err := db.Last(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected no last run, got %v", err)
+	}
+
+	for i, failed := range []int{0, 2, 0} {
+		run := &Run{
+			Kind:        "copy",
+			Destination: "/dst",
+			Start:       time.Date(2026, 3, 10+i, 2, 0, 0, 0, time.UTC),
+			TotalFiles:  10,
+			Successful:  10 - failed,
+			Failed:      failed,
+			Config:      config.DefaultConfig(),
+		}
+		if failed > 0 {
+			run.Failures = []string{"a.jpg: disk full"}
+			run.FailedPaths = []string{"/src/a.jpg"}
+		}
+		if err := db.Add(run); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		if run.ID != uint64(i+1) {
+			t.Errorf("Expected ID %d, got %d", i+1, run.ID)
+		}
+	}
+
+	runs, err = db.List(2)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != 3 || runs[1].ID != 2 {
+		t.Fatalf("Expected the two newest runs, got %+v", runs)
+	}
+	if runs[1].Config != nil || runs[1].Failures != nil || runs[1].FailedPaths != nil {
+		t.Error("Expected List to leave out the details")
+	}
+	if runs[1].Finished() || !runs[0].Finished() {
+		t.Error("Expected only the run without failures to be finished")
+	}
+
+	run, err := db.Get(2)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if run.Config == nil || len(run.Failures) != 1 || !run.Start.Equal(time.Date(2026, 3, 11, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the full run, got %+v", run)
+	}
+
+	if last, err := db.Last(); err != nil || last.ID != 3 || last.Config == nil {
+		t.Errorf("Expected the details of run 3, got %+v, %v", last, err)
+	}
+
+	if _, err := db.Get(42); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
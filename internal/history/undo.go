@@ -0,0 +1,125 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"copy-image/internal/config"
+	"copy-image/internal/storage"
+	"copy-image/internal/utils"
+)
+
+// ErrAlreadyUndone is returned by Undo for a run that was undone before.
+var ErrAlreadyUndone = errors.New("run was already undone")
+
+// ErrArchive is returned by Undo for a run into a zip archive, whose
+// entries are not removed one by one.
+var ErrArchive = errors.New("undo does not apply to zip archives")
+
+// UndoResult tells what undoing a run did with each file it had created.
+type UndoResult struct {
+	Removed []string `json:"removed"`
+	Missing []string `json:"missing"` // already deleted or moved away
+	Kept    []string `json:"kept"`    // changed since the run, left alone
+	Failed  []string `json:"failed"`  // "path: error"
+}
+
+// Target returns the settings of the run's destination, with the passwords
+// current has for it, to open its storage with storage.ForDestination.
+// Runs recorded without their settings get those of a local folder.
+func (r *Run) Target(current *config.Config) *config.Config {
+	if r.Config == nil {
+		return &config.Config{Destination: r.Destination}
+	}
+	// Redacted copies the connection settings, which get the passwords.
+	cfg := r.Config.Redacted()
+	cfg.RestoreSecrets(current, r.GroupID)
+	return cfg
+}
+
+// Undo removes the files the run created from dst, the storage of its
+// destination, and marks it undone if none failed to be removed. Files that existed before the run are
+// never in its list, and files changed since (different size or
+// modification time) are kept, so undoing cannot lose anything but the
+// copies. Folders left empty are removed up to the run's destination.
+// With useTrash, files in local folders go to the recycle bin. Callers
+// should hold the destination's session lock.
+func (d *DB) Undo(id uint64, dst storage.Storage, useTrash bool) (*UndoResult, error) {
+	run, err := d.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if run.UndoneAt != nil {
+		return nil, fmt.Errorf("%w: %d", ErrAlreadyUndone, id)
+	}
+	if _, ok := dst.(storage.Committer); ok {
+		return nil, ErrArchive
+	}
+
+	_, local := dst.(storage.Local)
+	result := &UndoResult{Removed: []string{}, Missing: []string{}, Kept: []string{}, Failed: []string{}}
+	for _, f := range run.Created {
+		info, err := dst.Stat(f.Path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			result.Missing = append(result.Missing, f.Path)
+			continue
+		case err != nil:
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: %v", f.Path, err))
+			continue
+		case info.Size() != f.Size || !info.ModTime().Equal(f.ModTime):
+			result.Kept = append(result.Kept, f.Path)
+			continue
+		}
+		if local {
+			err = utils.Delete(f.Path, useTrash)
+		} else {
+			err = dst.Remove(f.Path)
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, fmt.Sprintf("%s: %v", f.Path, err))
+			continue
+		}
+		result.Removed = append(result.Removed, f.Path)
+		removeEmptyDirs(dst, filepath.Dir(f.Path), run.Destination)
+	}
+
+	if len(result.Failed) > 0 {
+		// Left as it was, the run can be undone again once the files
+		// that failed can be removed.
+		return result, nil
+	}
+	now := time.Now()
+	run.UndoneAt = &now
+	err = d.update(func(b *bolt.Bucket) error {
+		data, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		return b.Put(key(id), data)
+	})
+	return result, err
+}
+
+// removeEmptyDirs removes dir and its parents from dst while they are
+// empty, stopping at root, which is kept.
+func removeEmptyDirs(dst storage.Storage, dir, root string) {
+	root = filepath.Clean(root)
+	for {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == "." || !filepath.IsLocal(rel) {
+			return
+		}
+		if dst.Remove(dir) != nil {
+			// Not empty (or not ours to remove).
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,189 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/storage"
+)
+
+func TestUndo(t *testing.T) {
+	db := Open(filepath.Join(t.TempDir(), FileName))
+	dst := t.TempDir()
+
+	write := func(rel, content string) copier.CreatedFile {
+		t.Helper()
+		path := filepath.Join(dst, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return copier.CreatedFile{Path: path, Size: info.Size(), ModTime: info.ModTime()}
+	}
+
+	nested := write(filepath.Join("2024", "a.jpg"), "a")
+	edited := write("b.jpg", "b")
+	gone := write("c.jpg", "c")
+	write("old.jpg", "was there before")
+
+	run := &Run{Kind: KindCopy, Destination: dst, Start: time.Now(), Created: []copier.CreatedFile{nested, edited, gone}}
+	if err := db.Add(run); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(edited.Path, []byte("edited after the copy"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(gone.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := db.Undo(run.ID, storage.Local{}, false)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if len(result.Removed) != 1 || len(result.Kept) != 1 || len(result.Missing) != 1 || len(result.Failed) != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "2024")); !os.IsNotExist(err) {
+		t.Error("Expected the emptied folder to be removed")
+	}
+	for _, name := range []string{"b.jpg", "old.jpg"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err != nil {
+			t.Errorf("Expected %s to be kept: %v", name, err)
+		}
+	}
+
+	got, err := db.Get(run.ID)
+	if err != nil || got.UndoneAt == nil {
+		t.Errorf("Expected the run to be marked undone, got %+v, %v", got, err)
+	}
+	if _, err := db.Undo(run.ID, storage.Local{}, false); !errors.Is(err, ErrAlreadyUndone) {
+		t.Errorf("Expected ErrAlreadyUndone, got %v", err)
+	}
+	if _, err := db.Undo(99, storage.Local{}, false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestUndoRemote(t *testing.T) {
+	db := Open(filepath.Join(t.TempDir(), FileName))
+	// The copier joins file names below the URL with filepath.Join, and
+	// the storage finds them there.
+	dest := "s3://photos/2024"
+	bucket := storage.NewMemory()
+	path := filepath.Join(dest, "card", "a.jpg")
+	if err := bucket.WriteFile(path, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	info, err := bucket.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Destination = dest
+	run := &Run{Kind: KindCopy, Destination: dest, Config: cfg, Start: time.Now(),
+		Created: []copier.CreatedFile{{Path: path, Size: info.Size(), ModTime: info.ModTime()}}}
+	if err := db.Add(run); err != nil {
+		t.Fatal(err)
+	}
+	if got := run.Target(config.DefaultConfig()); got.Destination != dest {
+		t.Errorf("Target() = %q, want the run's destination", got.Destination)
+	}
+
+	result, err := db.Undo(run.ID, bucket, true)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if len(result.Removed) != 1 || storage.Exists(bucket, path) {
+		t.Errorf("Expected the object to be removed from the bucket, got %+v", result)
+	}
+}
+
+func TestTargetRestoresPasswords(t *testing.T) {
+	current := config.DefaultConfig()
+	current.Destination = "ftp://lab.example/orders"
+	current.FTP = &config.FTPOptions{Username: "up", Password: "ftp-pw"}
+
+	// History keeps the run's settings without their passwords.
+	run := &Run{Destination: current.Destination, Config: current.Redacted()}
+	if got := run.Target(current); got.FTP == nil || got.FTP.Password != "ftp-pw" {
+		t.Errorf("Expected the FTP password restored to log in for undo, got %+v", got.FTP)
+	}
+	if run.Config.FTP.Password != "" {
+		t.Error("Target modified the recorded settings")
+	}
+
+	old := &Run{Destination: "/backup"}
+	if got := old.Target(current); got.Destination != "/backup" || got.IsArchive() {
+		t.Errorf("Expected a local folder for runs without settings, got %+v", got)
+	}
+}
+
+// lockedStorage is a Memory destination whose files cannot be removed,
+// like files held open by another program.
+type lockedStorage struct{ *storage.Memory }
+
+func (lockedStorage) Remove(string) error { return os.ErrPermission }
+
+func TestUndoFailedCanBeRetried(t *testing.T) {
+	db := Open(filepath.Join(t.TempDir(), FileName))
+	dst := storage.NewMemory()
+	path := filepath.Join(string(filepath.Separator), "backup", "a.jpg")
+	if err := dst.WriteFile(path, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	info, err := dst.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := &Run{Kind: KindCopy, Destination: filepath.Dir(path), Start: time.Now(),
+		Created: []copier.CreatedFile{{Path: path, Size: info.Size(), ModTime: info.ModTime()}}}
+	if err := db.Add(run); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := db.Undo(run.ID, lockedStorage{dst}, false)
+	if err != nil || len(result.Failed) != 1 {
+		t.Fatalf("Expected the file to fail, got %+v, %v", result, err)
+	}
+	if got, _ := db.Get(run.ID); got.UndoneAt != nil {
+		t.Error("Expected a run with files left not to be marked undone")
+	}
+
+	result, err = db.Undo(run.ID, dst, false)
+	if err != nil || len(result.Removed) != 1 {
+		t.Errorf("Expected the retry to remove the file, got %+v, %v", result, err)
+	}
+	if got, _ := db.Get(run.ID); got.UndoneAt == nil {
+		t.Error("Expected the run to be marked undone once its files are gone")
+	}
+}
+
+func TestUndoArchive(t *testing.T) {
+	db := Open(filepath.Join(t.TempDir(), FileName))
+	archive := filepath.Join(t.TempDir(), "job.zip")
+	run := &Run{Kind: KindCopy, Destination: archive, Start: time.Now(),
+		Created: []copier.CreatedFile{{Path: filepath.Join(archive, "a.jpg")}}}
+	if err := db.Add(run); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Undo(run.ID, storage.NewZip(archive, nil), false); !errors.Is(err, ErrArchive) {
+		t.Errorf("Expected ErrArchive, got %v", err)
+	}
+	if got, _ := db.Get(run.ID); got.UndoneAt != nil {
+		t.Error("Expected the run not to be marked undone")
+	}
+}
@@ -0,0 +1,188 @@
+// Package hooks runs the commands configured in hooks (pre_copy, post_copy
+// and on_failure) around each batch, with the batch described in COPYIMAGE_*
+// environment variables.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+	"copy-image/internal/shell"
+)
+
+// defaultTimeout bounds a command when hooks.timeout is not set.
+const defaultTimeout = 10 * time.Minute
+
+// Names of the hooks, also passed to the commands as COPYIMAGE_HOOK.
+const (
+	PreCopy   = "pre_copy"
+	PostCopy  = "post_copy"
+	OnFailure = "on_failure"
+)
+
+// Hooks runs the hook commands of a config. A nil Hooks, or one without
+// commands, runs nothing.
+type Hooks struct {
+	hooks config.Hooks
+}
+
+// New returns the Hooks of cfg.
+func New(cfg *config.Config) *Hooks {
+	if cfg.Hooks == nil {
+		return nil
+	}
+	return &Hooks{hooks: *cfg.Hooks}
+}
+
+// Batch describes a batch to the commands.
+type Batch struct {
+	Kind    string // what started the batch, as in the run history
+	GroupID string
+	Copier  *copier.Copier
+	Files   int
+}
+
+// Before runs pre_copy for b. Its error means the batch must not be
+// copied.
+func (h *Hooks) Before(ctx context.Context, b Batch) error {
+	if h == nil || h.hooks.PreCopy == "" || b.Copier.Config().DryRun {
+		return nil
+	}
+	return h.run(ctx, PreCopy, h.hooks.PreCopy, b.env(PreCopy))
+}
+
+// After runs post_copy for a batch that ended with summary, then
+// on_failure when files failed or the batch was cancelled. Both are run;
+// the error lists those that failed.
+func (h *Hooks) After(ctx context.Context, b Batch, summary copier.CopySummary, cancelled bool) error {
+	return h.after(ctx, b, summary, cancelled, summary.Failed > 0 || cancelled)
+}
+
+func (h *Hooks) after(ctx context.Context, b Batch, summary copier.CopySummary, cancelled, failed bool) error {
+	if h == nil || b.Copier.Config().DryRun {
+		return nil
+	}
+	// The batch may have ended because ctx was cancelled; still run them.
+	ctx = context.WithoutCancel(ctx)
+	env := append(b.env(""), summaryEnv(summary, cancelled)...)
+	var errs []error
+	if h.hooks.PostCopy != "" {
+		errs = append(errs, h.run(ctx, PostCopy, h.hooks.PostCopy, withHook(env, PostCopy)))
+	}
+	if h.hooks.OnFailure != "" && failed {
+		errs = append(errs, h.run(ctx, OnFailure, h.hooks.OnFailure, withHook(env, OnFailure)))
+	}
+	return errors.Join(errs...)
+}
+
+// Wrap returns run with the hooks run around each batch. groupID is read
+// when a batch starts and may be nil. When pre_copy fails, the files are
+// not copied and count as failed; other failing commands are passed to
+// warn and do not change the batch.
+func (h *Hooks) Wrap(kind string, groupID *string, run copier.DestinationRunner, warn func(error)) copier.DestinationRunner {
+	if h == nil || h.hooks.PreCopy == "" && h.hooks.PostCopy == "" && h.hooks.OnFailure == "" {
+		return run
+	}
+	return func(ctx context.Context, c *copier.Copier, files []string) copier.CopySummary {
+		b := Batch{Kind: kind, Copier: c, Files: len(files)}
+		if groupID != nil {
+			b.GroupID = *groupID
+		}
+		summary, err := h.Run(ctx, b, func() copier.CopySummary { return run(ctx, c, files) })
+		if err != nil {
+			warn(err)
+		}
+		return summary
+	}
+}
+
+// Run copies the batch b with copyFiles between its hooks. A failing
+// pre_copy skips copyFiles and fails every file; the error is that of
+// the commands run after it.
+func (h *Hooks) Run(ctx context.Context, b Batch, copyFiles func() copier.CopySummary) (copier.CopySummary, error) {
+	if err := h.Before(ctx, b); err != nil {
+		return aborted(b, err), h.Aborted(ctx, b, err)
+	}
+	summary := copyFiles()
+	return summary, h.After(ctx, b, summary, ctx.Err() != nil)
+}
+
+// Aborted runs on_failure for a batch that was not copied because
+// pre_copy failed with err, for callers running Before themselves.
+func (h *Hooks) Aborted(ctx context.Context, b Batch, err error) error {
+	return h.after(ctx, b, aborted(b, err), false, true)
+}
+
+// aborted is the summary of a batch whose pre_copy failed with err.
+func aborted(b Batch, err error) copier.CopySummary {
+	return copier.CopySummary{TotalFiles: b.Files, Failed: b.Files, FailedFiles: []copier.FailedFile{{Err: err}}}
+}
+
+// run runs command through the shell with env added to the environment.
+func (h *Hooks) run(ctx context.Context, name, command string, env []string) error {
+	timeout := defaultTimeout
+	if h.hooks.Timeout > 0 {
+		timeout = time.Duration(h.hooks.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shell.Command(ctx, command)
+	cmd.Env = append(os.Environ(), env...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Do not wait for children that keep the output open.
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if tail := shell.Tail(out.Bytes()); tail != "" {
+		return fmt.Errorf("%s hook failed: %w: %s", name, err, tail)
+	}
+	return fmt.Errorf("%s hook failed: %w", name, err)
+}
+
+// env returns the variables describing b before it is copied.
+func (b Batch) env(hook string) []string {
+	cfg := b.Copier.Config()
+	env := []string{
+		"COPYIMAGE_KIND=" + b.Kind,
+		"COPYIMAGE_GROUP=" + b.GroupID,
+		"COPYIMAGE_SOURCE=" + strings.Join(cfg.SourcePatterns(), string(os.PathListSeparator)),
+		"COPYIMAGE_DESTINATION=" + cfg.Destination,
+		"COPYIMAGE_FILES=" + strconv.Itoa(b.Files),
+	}
+	return withHook(env, hook)
+}
+
+func withHook(env []string, hook string) []string {
+	if hook == "" {
+		return env
+	}
+	return append(env[:len(env):len(env)], "COPYIMAGE_HOOK="+hook)
+}
+
+// summaryEnv returns the variables describing how a batch ended.
+func summaryEnv(s copier.CopySummary, cancelled bool) []string {
+	return []string{
+		"COPYIMAGE_TOTAL=" + strconv.Itoa(s.TotalFiles),
+		"COPYIMAGE_SUCCESSFUL=" + strconv.Itoa(s.Successful),
+		"COPYIMAGE_FAILED=" + strconv.Itoa(s.Failed),
+		"COPYIMAGE_SKIPPED=" + strconv.Itoa(s.Skipped),
+		"COPYIMAGE_DURATION=" + strconv.FormatFloat(s.Duration.Seconds(), 'f', 1, 64),
+		"COPYIMAGE_CANCELLED=" + strconv.FormatBool(cancelled),
+	}
+}
@@ -0,0 +1,154 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+)
+
+// newHooks returns hooks for a config copying /media/card to /nas/photos.
+func newHooks(t *testing.T, h config.Hooks, dryRun bool) (*Hooks, Batch) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test commands use sh")
+	}
+	cfg := config.DefaultConfig()
+	cfg.Source = "/media/card"
+	cfg.Destination = "/nas/photos"
+	cfg.DryRun = dryRun
+	cfg.Hooks = &h
+	return New(cfg), Batch{Kind: "copy", GroupID: "cards", Copier: copier.New(cfg), Files: 3}
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func TestRunEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	pre, post := filepath.Join(dir, "pre"), filepath.Join(dir, "post")
+	h, b := newHooks(t, config.Hooks{
+		PreCopy:  `echo "$COPYIMAGE_HOOK $COPYIMAGE_GROUP $COPYIMAGE_SOURCE $COPYIMAGE_DESTINATION $COPYIMAGE_FILES" > ` + pre,
+		PostCopy: `echo "$COPYIMAGE_HOOK $COPYIMAGE_SUCCESSFUL $COPYIMAGE_FAILED $COPYIMAGE_CANCELLED" > ` + post,
+	}, false)
+
+	summary, err := h.Run(context.Background(), b, func() copier.CopySummary {
+		return copier.CopySummary{TotalFiles: 3, Successful: 3}
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if summary.Successful != 3 {
+		t.Errorf("Expected the copy's summary, got %+v", summary)
+	}
+	if got := readFile(t, pre); got != "pre_copy cards /media/card /nas/photos 3\n" {
+		t.Errorf("pre_copy saw %q", got)
+	}
+	if got := readFile(t, post); got != "post_copy 3 0 false\n" {
+		t.Errorf("post_copy saw %q", got)
+	}
+}
+
+func TestRunPreCopyFails(t *testing.T) {
+	dir := t.TempDir()
+	failure := filepath.Join(dir, "failure")
+	h, b := newHooks(t, config.Hooks{
+		PreCopy:   "echo share not mounted; exit 3",
+		OnFailure: "echo $COPYIMAGE_FAILED > " + failure,
+	}, false)
+
+	copied := false
+	summary, err := h.Run(context.Background(), b, func() copier.CopySummary {
+		copied = true
+		return copier.CopySummary{}
+	})
+	if err != nil {
+		t.Errorf("Expected on_failure to succeed, got %v", err)
+	}
+	if copied {
+		t.Error("Expected the batch not to be copied")
+	}
+	if summary.Failed != 3 || len(summary.FailedFiles) != 1 || !strings.Contains(summary.FailedFiles[0].String(), "share not mounted") {
+		t.Errorf("Expected every file to fail with the command's output, got %+v", summary)
+	}
+	if got := readFile(t, failure); got != "3\n" {
+		t.Errorf("Expected on_failure to run, got %q", got)
+	}
+}
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		name      string
+		summary   copier.CopySummary
+		cancelled bool
+		failure   bool
+	}{
+		{"complete", copier.CopySummary{TotalFiles: 3, Successful: 3}, false, false},
+		{"failed files", copier.CopySummary{TotalFiles: 3, Successful: 2, Failed: 1}, false, true},
+		{"cancelled", copier.CopySummary{TotalFiles: 3, Successful: 1}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			post, failure := filepath.Join(dir, "post"), filepath.Join(dir, "failure")
+			h, b := newHooks(t, config.Hooks{PostCopy: "touch " + post, OnFailure: "touch " + failure}, false)
+			if err := h.After(context.Background(), b, tt.summary, tt.cancelled); err != nil {
+				t.Fatalf("After failed: %v", err)
+			}
+			if _, err := os.Stat(post); err != nil {
+				t.Error("Expected post_copy to run")
+			}
+			if _, err := os.Stat(failure); (err == nil) != tt.failure {
+				t.Errorf("Expected on_failure to run: %v", tt.failure)
+			}
+		})
+	}
+}
+
+func TestAfterErrors(t *testing.T) {
+	h, b := newHooks(t, config.Hooks{PostCopy: "echo index failed >&2; exit 1", OnFailure: "exit 2"}, false)
+	err := h.After(context.Background(), b, copier.CopySummary{TotalFiles: 1, Failed: 1}, false)
+	if err == nil || !strings.Contains(err.Error(), "post_copy hook failed") || !strings.Contains(err.Error(), "index failed") || !strings.Contains(err.Error(), "on_failure hook failed") {
+		t.Errorf("Expected both commands in the error, got %v", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	h, b := newHooks(t, config.Hooks{PreCopy: "sleep 10", Timeout: 1}, false)
+	err := h.Before(context.Background(), b)
+	if err == nil || !strings.Contains(err.Error(), "timed out after 1s") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+}
+
+func TestDryRunSkipsHooks(t *testing.T) {
+	h, b := newHooks(t, config.Hooks{PreCopy: "exit 1", PostCopy: "exit 1"}, true)
+	summary, err := h.Run(context.Background(), b, func() copier.CopySummary {
+		return copier.CopySummary{TotalFiles: 3, Successful: 3}
+	})
+	if err != nil || summary.Successful != 3 {
+		t.Errorf("Expected no hooks in a dry run, got %+v %v", summary, err)
+	}
+}
+
+func TestNilHooks(t *testing.T) {
+	h := New(config.DefaultConfig())
+	if h != nil {
+		t.Fatal("Expected no hooks without a hooks section")
+	}
+	run := func(context.Context, *copier.Copier, []string) copier.CopySummary { return copier.CopySummary{} }
+	if h.Wrap("copy", nil, run, func(error) {}) == nil {
+		t.Error("Expected Wrap to return the runner")
+	}
+}
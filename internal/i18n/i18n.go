@@ -0,0 +1,132 @@
+// Package i18n holds the message catalogs for user-facing text shown by
+// the CLI and the desktop app. Catalogs are JSON files embedded in the
+// binary so the frontend can receive exactly the same strings.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Supported languages. English is the fallback for missing keys.
+const (
+	English    = "en"
+	Vietnamese = "vi"
+)
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs maps a language to its messages, loaded once at startup.
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	for _, lang := range []string{English, Vietnamese} {
+		data, err := localeFS.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog %s: %v", lang, err))
+		}
+		msgs := make(map[string]string)
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", lang, err))
+		}
+		result[lang] = msgs
+	}
+	return result
+}
+
+// Supported returns the languages that have a catalog.
+func Supported() []string {
+	return []string{English, Vietnamese}
+}
+
+// Resolve picks the language to use: the configured one when it is
+// supported, otherwise the operating system's language, otherwise English.
+// An empty configured value means "follow the OS".
+func Resolve(configured string) string {
+	if lang := normalize(configured); lang != "" {
+		return lang
+	}
+	if lang := normalize(Detect()); lang != "" {
+		return lang
+	}
+	return English
+}
+
+// Detect returns the user's locale as reported by the environment
+// (LC_ALL, LC_MESSAGES, LANG) or the OS, e.g. "vi_VN.UTF-8" or "vi-VN".
+// It returns "" when nothing is set.
+func Detect() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" && v != "C" && v != "POSIX" {
+			return v
+		}
+	}
+	return systemLocale()
+}
+
+// normalize reduces a locale such as "vi_VN.UTF-8" to a supported language
+// code, or "" if it is not supported.
+func normalize(locale string) string {
+	lang := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	return ""
+}
+
+// Catalog translates message keys for one language.
+type Catalog struct {
+	lang string
+}
+
+// New returns the catalog for lang, falling back to English when lang is
+// not supported.
+func New(lang string) *Catalog {
+	if normalize(lang) == "" {
+		lang = English
+	}
+	return &Catalog{lang: normalize(lang)}
+}
+
+// Language returns the catalog's language code.
+func (c *Catalog) Language() string {
+	return c.lang
+}
+
+// T returns the message for key formatted with args. Keys missing from
+// the catalog fall back to English and then to the key itself, so a
+// forgotten translation never hides a message.
+func (c *Catalog) T(key string, args ...any) string {
+	msg, ok := catalogs[c.lang][key]
+	if !ok {
+		msg, ok = catalogs[English][key]
+	}
+	if !ok {
+		msg = key
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Messages returns a copy of every message in the catalog, with English
+// filling any gaps. The desktop app passes this to the frontend.
+func (c *Catalog) Messages() map[string]string {
+	msgs := make(map[string]string, len(catalogs[English]))
+	for k, v := range catalogs[English] {
+		msgs[k] = v
+	}
+	for k, v := range catalogs[c.lang] {
+		msgs[k] = v
+	}
+	return msgs
+}
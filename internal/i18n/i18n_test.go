@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"testing"
+)
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for _, lang := range Supported() {
+		for key := range catalogs[English] {
+			if _, ok := catalogs[lang][key]; !ok {
+				t.Errorf("%s catalog is missing %q", lang, key)
+			}
+		}
+		for key := range catalogs[lang] {
+			if _, ok := catalogs[English][key]; !ok {
+				t.Errorf("%s catalog has %q which English lacks", lang, key)
+			}
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		lang       string
+		expected   string
+	}{
+		{"configured wins", "vi", "en_US.UTF-8", Vietnamese},
+		{"configured with region", "en-GB", "vi_VN.UTF-8", English},
+		{"follows LANG", "", "vi_VN.UTF-8", Vietnamese},
+		{"unsupported configured falls back to LANG", "fr", "vi_VN", Vietnamese},
+		{"unsupported everywhere", "", "de_DE.UTF-8", English},
+		{"C locale", "", "C", English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LC_ALL", "")
+			t.Setenv("LC_MESSAGES", "")
+			t.Setenv("LANG", tt.lang)
+			if got := Resolve(tt.configured); got != tt.expected {
+				t.Errorf("Resolve(%q) with LANG=%q = %q, expected %q", tt.configured, tt.lang, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCatalogT(t *testing.T) {
+	vi := New(Vietnamese)
+	if got := vi.T("cli.found_files", 3); got != "📁 Tìm thấy 3 file(s)" {
+		t.Errorf("Unexpected translation: %q", got)
+	}
+	if got := New(English).T("cli.found_files", 3); got != "📁 Found 3 file(s)" {
+		t.Errorf("Unexpected translation: %q", got)
+	}
+	if got := vi.T("no.such.key"); got != "no.such.key" {
+		t.Errorf("Expected missing key to be returned as is, got %q", got)
+	}
+	if New("klingon").Language() != English {
+		t.Error("Expected unsupported language to fall back to English")
+	}
+	if len(vi.Messages()) != len(catalogs[English]) {
+		t.Error("Messages should contain every key")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package i18n
+
+// systemLocale is only needed on Windows; elsewhere the LANG variables
+// checked by Detect already describe the user's locale.
+func systemLocale() string {
+	return ""
+}
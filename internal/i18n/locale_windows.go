@@ -0,0 +1,25 @@
+//go:build windows
+
+package i18n
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// systemLocale returns the user's default locale name (e.g. "vi-VN") from
+// Windows, where the LANG variables are normally not set.
+func systemLocale() string {
+	proc := syscall.NewLazyDLL("kernel32.dll").NewProc("GetUserDefaultLocaleName")
+	if proc.Find() != nil {
+		return ""
+	}
+
+	// LOCALE_NAME_MAX_LENGTH is 85 characters including the terminator.
+	buf := make([]uint16, 85)
+	n, _, _ := proc.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if n == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf)
+}
@@ -0,0 +1,122 @@
+// Package imagecheck detects corrupt and truncated images before they are
+// copied, so a broken file from a failing card is reported rather than
+// archived as if it were good.
+package imagecheck
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"path/filepath"
+	"strings"
+
+	// Decoders for the formats checked.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+)
+
+// ErrCorrupt is matched by the error of a file that is not a valid image.
+var ErrCorrupt = errors.New("corrupt image")
+
+// tailSize is how much of the end of a file is searched for the marker
+// ending its image data. Some cameras append their own data after it.
+const tailSize = 64 << 10
+
+// supported are the extensions of the formats checked. Other files,
+// including RAW files, are copied unchecked.
+var supported = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true,
+	".webp": true, ".bmp": true, ".tif": true, ".tiff": true,
+}
+
+// Supported reports whether the file named name is of a format Check
+// knows.
+func Supported(name string) bool {
+	return supported[strings.ToLower(filepath.Ext(name))]
+}
+
+// Check reads the image in r and returns an error matching ErrCorrupt when
+// it is not valid. With full set the whole image is decoded, which catches
+// damage inside the image data but costs as much CPU and memory as opening
+// it in a viewer. Otherwise only its header is decoded and JPEG and PNG
+// files are checked for the marker ending them, which catches the usual
+// truncated file.
+func Check(r io.Reader, full bool) error {
+	if full {
+		if _, _, err := image.Decode(bufio.NewReader(r)); err != nil {
+			return fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		return nil
+	}
+
+	tail := &tailBuffer{}
+	_, format, err := image.DecodeConfig(bufio.NewReader(io.TeeReader(r, tail)))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	var end []byte
+	switch format {
+	case "jpeg":
+		end = []byte{0xFF, 0xD9} // EOI
+	case "png":
+		end = []byte("IEND")
+	default:
+		return nil
+	}
+	if _, err := io.Copy(tail, r); err != nil {
+		return err
+	}
+	if !bytes.Contains(tail.buf, end) {
+		return fmt.Errorf("%w: %s data is truncated", ErrCorrupt, format)
+	}
+	return nil
+}
+
+// tailBuffer keeps the last tailSize bytes written to it.
+type tailBuffer struct {
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - tailSize; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// Processor returns the processor checking images before they are copied,
+// for mode config.ValidateHeader or config.ValidateFull.
+func Processor(mode string) processor.FileProcessor {
+	return checker{full: mode == config.ValidateFull}
+}
+
+type checker struct {
+	full bool
+}
+
+func (checker) Name() string { return "validate" }
+
+func (c checker) Inspect(ctx context.Context, f processor.File) error {
+	if !Supported(f.Name) || f.Open == nil {
+		return nil
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	return Check(r, c.full)
+}
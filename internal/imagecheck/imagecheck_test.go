@@ -0,0 +1,93 @@
+package imagecheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+)
+
+func encoded(t *testing.T, format string) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for x := range 64 {
+		img.Set(x, x%48, color.RGBA{R: uint8(x * 4), A: 255})
+	}
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheck(t *testing.T) {
+	jpg, pngData := encoded(t, "jpeg"), encoded(t, "png")
+	tests := []struct {
+		name    string
+		data    []byte
+		corrupt bool // in header mode
+		full    bool // corrupt in full mode
+	}{
+		{"jpeg", jpg, false, false},
+		{"png", pngData, false, false},
+		{"jpeg with trailing data", append(append([]byte(nil), jpg...), "SEFT"...), false, false},
+		{"truncated jpeg", jpg[:len(jpg)*2/3], true, true},
+		{"truncated png", pngData[:len(pngData)-20], true, true},
+		{"zeroed", make([]byte, 4096), true, true},
+		{"empty", nil, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Check(bytes.NewReader(tt.data), false); errors.Is(err, ErrCorrupt) != tt.corrupt {
+				t.Errorf("Header check: expected corrupt %v, got %v", tt.corrupt, err)
+			}
+			if err := Check(bytes.NewReader(tt.data), true); errors.Is(err, ErrCorrupt) != tt.full {
+				t.Errorf("Full check: expected corrupt %v, got %v", tt.full, err)
+			}
+		})
+	}
+}
+
+func TestSupported(t *testing.T) {
+	for name, want := range map[string]bool{"a.JPG": true, "b.webp": true, "c.tiff": true, "d.cr3": false, "e.heic": false} {
+		if Supported(name) != want {
+			t.Errorf("Supported(%q) = %v, want %v", name, !want, want)
+		}
+	}
+}
+
+func TestProcessor(t *testing.T) {
+	data := encoded(t, "jpeg")
+	open := func(b []byte) func() (io.ReadCloser, error) {
+		return func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil }
+	}
+	c := &processor.Chain{}
+	c.Add(Processor(config.ValidateHeader), config.Processor{})
+
+	if err := c.Inspect(context.Background(), processor.File{Name: "a.jpg", Open: open(data)}); err != nil {
+		t.Errorf("Expected a valid JPEG to pass, got %v", err)
+	}
+	err := c.Inspect(context.Background(), processor.File{Name: "b.jpg", Open: open(data[:100])})
+	if !errors.Is(err, ErrCorrupt) || !strings.Contains(err.Error(), "processor validate") {
+		t.Errorf("Expected a truncated JPEG to be rejected, got %v", err)
+	}
+	// Formats it does not know are not opened.
+	if err := c.Inspect(context.Background(), processor.File{Name: "c.cr3", Open: open(nil)}); err != nil {
+		t.Errorf("Expected a RAW file to pass unchecked, got %v", err)
+	}
+}
@@ -0,0 +1,357 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+// FileName is the name of the lock file created inside a locked directory.
+// It starts with a dot so it is hidden on Unix and easy to filter out.
+const FileName = ".copyimage.lock"
+
+// DefaultTTL is how long a lease stays valid without a heartbeat. A session
+// that crashes (or a machine that loses the share) leaves a stale lock that
+// other instances may take over once this much time has passed.
+const DefaultTTL = 2 * time.Minute
+
+// clockSkew is how far the clocks of the hosts sharing a lock, and of the
+// server the lock is on, may disagree: a lock is only stale once it has
+// not been refreshed for its TTL plus this, by every clock that can tell.
+const clockSkew = time.Minute
+
+// ErrLocked is returned (wrapped in a *LockedError) when another session
+// holds the lock.
+var ErrLocked = errors.New("destination is in use by another copy session")
+
+// Info describes the session holding a lock. It is stored as JSON in the
+// lock file so operators can see who is writing to a share.
+type Info struct {
+	Token     string    `json:"token"`
+	Host      string    `json:"host"`
+	PID       int       `json:"pid"`
+	Started   time.Time `json:"started"`
+	Heartbeat time.Time `json:"heartbeat"`
+}
+
+// LockedError reports the session that currently holds a lock.
+type LockedError struct {
+	Dir    string
+	Holder Info
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s is in use by another copy session (host %s, pid %d, since %s)",
+		e.Dir, e.Holder.Host, e.Holder.PID, e.Holder.Started.Format(time.RFC3339))
+}
+
+// Unwrap allows errors.Is(err, ErrLocked).
+func (e *LockedError) Unwrap() error {
+	return ErrLocked
+}
+
+// ErrGroupRunning is returned (wrapped in a *GroupRunningError) when
+// another run of the same copy group holds its run lock.
+var ErrGroupRunning = errors.New("group is already running")
+
+// GroupRunningError reports the session already running a copy group.
+type GroupRunningError struct {
+	GroupID string
+	Holder  Info
+}
+
+func (e *GroupRunningError) Error() string {
+	return fmt.Sprintf("group %q is already running (host %s, pid %d, since %s)",
+		e.GroupID, e.Holder.Host, e.Holder.PID, e.Holder.Started.Format(time.RFC3339))
+}
+
+// Unwrap allows errors.Is(err, ErrGroupRunning).
+func (e *GroupRunningError) Unwrap() error {
+	return ErrGroupRunning
+}
+
+// Options controls how Acquire behaves when the lock is taken.
+type Options struct {
+	// TTL is the lease duration; zero means DefaultTTL.
+	TTL time.Duration
+	// Wait is how long to queue for a busy lock. Zero refuses immediately.
+	Wait time.Duration
+	// PollInterval is how often a queued Acquire retries; zero means one second.
+	PollInterval time.Duration
+}
+
+// Lease is a held lock. The lock file is refreshed in the background until
+// Release is called.
+type Lease struct {
+	path string
+	info Info
+	ttl  time.Duration
+
+	mu       sync.Mutex
+	stop     chan struct{}
+	done     chan struct{}
+	released bool
+}
+
+// Acquire takes the session lock for dir, creating dir if needed.
+// If another live session holds the lock, Acquire waits up to opts.Wait
+// for it to be released and then returns a *LockedError. s3:// and ftp://
+// URLs get a lease that locks nothing.
+func Acquire(ctx context.Context, dir string, opts Options) (*Lease, error) {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if config.IsRemote(dir) {
+		// Object stores cannot create a file exclusively, and FTP servers
+		// do not reliably, so remote destinations are not locked.
+		return &Lease{released: true}, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	host, _ := os.Hostname()
+	info := Info{
+		Token: newToken(),
+		Host:  host,
+		PID:   os.Getpid(),
+	}
+	path := filepath.Join(dir, FileName)
+	deadline := time.Now().Add(opts.Wait)
+
+	for {
+		info.Started = time.Now()
+		info.Heartbeat = info.Started
+
+		err := create(path, info)
+		if err == nil {
+			l := &Lease{
+				path: path,
+				info: info,
+				ttl:  opts.TTL,
+				stop: make(chan struct{}),
+				done: make(chan struct{}),
+			}
+			go l.heartbeat()
+			return l, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		holder, readErr := read(path)
+		if errors.Is(readErr, os.ErrNotExist) {
+			// Released between our create attempt and the read; try again.
+			continue
+		}
+		if stale(path, holder, readErr, opts.TTL) {
+			// The holder stopped refreshing its lease; take over.
+			takeOver(path, holder.Token)
+			continue
+		}
+
+		lockedErr := &LockedError{Dir: dir, Holder: holder}
+		if time.Now().After(deadline) {
+			return nil, lockedErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(opts.PollInterval):
+		}
+	}
+}
+
+// AcquireGroup takes the run lock of the copy group id, kept in a folder
+// of its own below dir, so a scheduled run and a manual one of the same
+// group never copy the same files at once, even from separate processes.
+// It waits like Acquire and returns a *GroupRunningError if the group is
+// still running.
+func AcquireGroup(ctx context.Context, dir, id string, opts Options) (*Lease, error) {
+	lease, err := Acquire(ctx, filepath.Join(dir, groupDir(id)), opts)
+	var locked *LockedError
+	if errors.As(err, &locked) {
+		return nil, &GroupRunningError{GroupID: id, Holder: locked.Holder}
+	}
+	return lease, err
+}
+
+// groupDir returns the name of the folder of the run lock of group id.
+// Characters that are not safe in a file name on every system are
+// escaped, so distinct IDs never share a lock.
+func groupDir(id string) string {
+	var b strings.Builder
+	b.WriteString("group-")
+	for _, c := range []byte(id) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// Inspect returns the holder of the lock in dir, or nil if it is not locked.
+func Inspect(dir string) (*Info, error) {
+	info, err := read(filepath.Join(dir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Info returns the details written to the lock file.
+func (l *Lease) Info() Info {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.info
+}
+
+// Release stops the heartbeat and removes the lock file if this session
+// still owns it. It is safe to call more than once.
+func (l *Lease) Release() error {
+	l.mu.Lock()
+	if l.released {
+		l.mu.Unlock()
+		return nil
+	}
+	l.released = true
+	l.mu.Unlock()
+
+	close(l.stop)
+	<-l.done
+
+	// Never delete a lock that another session took over after our lease expired.
+	if holder, err := read(l.path); err == nil && holder.Token != l.info.Token {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
+
+// heartbeat refreshes the lease until Release is called.
+func (l *Lease) heartbeat() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			holder, err := read(l.path)
+			if err != nil || holder.Token != l.info.Token {
+				// Lost the lock (e.g. the share was unreachable for longer
+				// than the TTL); do not overwrite the new owner's file.
+				continue
+			}
+			l.mu.Lock()
+			l.info.Heartbeat = time.Now()
+			info := l.info
+			l.mu.Unlock()
+			_ = write(l.path, info)
+		}
+	}
+}
+
+// stale reports whether the lock file at path, read as holder (or not
+// read because of readErr), has not been refreshed for longer than ttl.
+// The heartbeat is by the holder's clock and the file's time by that of
+// the server, so both must be old by more than clockSkew as well; a file
+// that cannot be parsed, maybe being written by its owner, goes by its
+// time alone.
+func stale(path string, holder Info, readErr error, ttl time.Duration) bool {
+	limit := ttl + clockSkew
+	if readErr == nil && time.Since(holder.Heartbeat) <= limit {
+		return false
+	}
+	st, err := os.Stat(path)
+	return err == nil && time.Since(st.ModTime()) > limit
+}
+
+// takeOver removes the stale lock file at path, which held token when it
+// was read, unless another session replaced it since. Two sessions can
+// find the same lock stale: the file is renamed to a name of this session
+// first, which only one of them can do, and deleted only if it still has
+// token. A lock taken meanwhile is put back.
+func takeOver(path, token string) {
+	claimed := path + "." + newToken() + ".stale"
+	if err := os.Rename(path, claimed); err != nil {
+		return
+	}
+	got, err := read(claimed)
+	if err == nil && got.Token != token {
+		// A live lock: put it back, unless yet another session created
+		// one since, which then holds the lock.
+		_ = create(path, got)
+	}
+	_ = os.Remove(claimed)
+}
+
+// create writes the lock file only if it does not exist yet.
+func create(path string, info Info) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	encErr := json.NewEncoder(f).Encode(info)
+	closeErr := f.Close()
+	if encErr != nil {
+		_ = os.Remove(path)
+		return encErr
+	}
+	return closeErr
+}
+
+// write replaces the contents of an existing lock file.
+func write(path string, info Info) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// read parses the lock file at path.
+func read(path string) (Info, error) {
+	var info Info
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("failed to parse lock file: %w", err)
+	}
+	return info, nil
+}
+
+// newToken returns a random identifier for a lease.
+func newToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,204 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireAndRelease(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dest")
+
+	lease, err := Acquire(context.Background(), dir, Options{})
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	holder, err := Inspect(dir)
+	if err != nil || holder == nil {
+		t.Fatalf("Expected lock holder, got %v (%v)", holder, err)
+	}
+	if holder.PID != os.Getpid() || holder.Token != lease.Info().Token {
+		t.Errorf("Unexpected holder: %+v", holder)
+	}
+
+	if err := lease.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if err := lease.Release(); err != nil {
+		t.Errorf("Second Release should be a no-op, got %v", err)
+	}
+	if holder, _ := Inspect(dir); holder != nil {
+		t.Error("Expected lock file to be removed")
+	}
+}
+
+func TestAcquireRefusesWhenHeld(t *testing.T) {
+	dir := t.TempDir()
+
+	lease, err := Acquire(context.Background(), dir, Options{})
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer func() { _ = lease.Release() }()
+
+	_, err = Acquire(context.Background(), dir, Options{})
+	if !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked, got %v", err)
+	}
+	var lockedErr *LockedError
+	if !errors.As(err, &lockedErr) || lockedErr.Holder.Token != lease.Info().Token {
+		t.Errorf("Expected LockedError with holder info, got %v", err)
+	}
+}
+
+func TestAcquireWaitsForRelease(t *testing.T) {
+	dir := t.TempDir()
+
+	lease, err := Acquire(context.Background(), dir, Options{})
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = lease.Release()
+	}()
+
+	second, err := Acquire(context.Background(), dir, Options{Wait: 2 * time.Second, PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected queued Acquire to succeed, got %v", err)
+	}
+	_ = second.Release()
+}
+
+func TestAcquireTakesOverStaleLock(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, FileName)
+	stale := Info{Token: "old", Host: "other", PID: 1, Started: time.Now().Add(-time.Hour), Heartbeat: time.Now().Add(-time.Hour)}
+	if err := write(path, stale); err != nil {
+		t.Fatalf("Failed to write stale lock: %v", err)
+	}
+
+	// The file was just written: the holder's clock may be behind.
+	if _, err := Acquire(context.Background(), dir, Options{TTL: time.Minute}); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected a lock refreshed by the file's time to be kept, got %v", err)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	lease, err := Acquire(context.Background(), dir, Options{TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("Expected stale lock to be taken over, got %v", err)
+	}
+	defer func() { _ = lease.Release() }()
+
+	if lease.Info().Token == "old" {
+		t.Error("Expected a new lease token")
+	}
+}
+
+func TestTakeOverKeepsReplacedLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+
+	// Another session took the stale lock over first.
+	fresh := Info{Token: "fresh", Heartbeat: time.Now()}
+	if err := write(path, fresh); err != nil {
+		t.Fatal(err)
+	}
+	takeOver(path, "old")
+	if holder, err := read(path); err != nil || holder.Token != "fresh" {
+		t.Errorf("Expected the fresh lock kept, got %+v, %v", holder, err)
+	}
+
+	takeOver(path, "fresh")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the stale lock removed, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no file left behind, got %d", len(entries))
+	}
+}
+
+func TestAcquireCancelledWhileWaiting(t *testing.T) {
+	dir := t.TempDir()
+
+	lease, err := Acquire(context.Background(), dir, Options{})
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	defer func() { _ = lease.Release() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	_, err = Acquire(ctx, dir, Options{Wait: time.Minute, PollInterval: 10 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context error, got %v", err)
+	}
+}
+
+func TestReleaseKeepsForeignLock(t *testing.T) {
+	dir := t.TempDir()
+
+	lease, err := Acquire(context.Background(), dir, Options{})
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	// Simulate another session taking over after our lease expired.
+	other := Info{Token: "other", Heartbeat: time.Now()}
+	if err := write(filepath.Join(dir, FileName), other); err != nil {
+		t.Fatalf("Failed to overwrite lock: %v", err)
+	}
+
+	if err := lease.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	holder, _ := Inspect(dir)
+	if holder == nil || holder.Token != "other" {
+		t.Error("Release must not remove a lock owned by another session")
+	}
+}
+
+func TestAcquireGroup(t *testing.T) {
+	dir := t.TempDir()
+
+	lease, err := AcquireGroup(context.Background(), dir, "cards", Options{})
+	if err != nil {
+		t.Fatalf("AcquireGroup failed: %v", err)
+	}
+	_, err = AcquireGroup(context.Background(), dir, "cards", Options{})
+	var running *GroupRunningError
+	if !errors.As(err, &running) || running.GroupID != "cards" || running.Holder.Token != lease.Info().Token {
+		t.Fatalf("Expected GroupRunningError with holder info, got %v", err)
+	}
+	if !errors.Is(err, ErrGroupRunning) || errors.Is(err, ErrLocked) {
+		t.Errorf("Expected only ErrGroupRunning, got %v", err)
+	}
+
+	// Other groups, even with IDs that escape alike, run meanwhile.
+	for _, id := range []string{"phone", "cards/2", "cards%2F2", ".."} {
+		other, err := AcquireGroup(context.Background(), dir, id, Options{})
+		if err != nil {
+			t.Fatalf("AcquireGroup(%q) failed: %v", id, err)
+		}
+		defer func() { _ = other.Release() }()
+	}
+
+	if err := lease.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	again, err := AcquireGroup(context.Background(), dir, "cards", Options{})
+	if err != nil {
+		t.Fatalf("Expected the released group to run again, got %v", err)
+	}
+	_ = again.Release()
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is a log record as shown by the desktop app's log panel.
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"` // "debug", "info", "warn" or "error"
+	Message string    `json:"message"`
+	// File is the file the record is about, if any.
+	File string `json:"file,omitempty"`
+	// Attrs are the other attributes, such as the error, as text.
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+// Feed is a slog.Handler that keeps the latest entries and passes every
+// entry to a function as it is logged, so a GUI can show a live log
+// without a log file.
+type Feed struct {
+	*feed
+	attrs  []slog.Attr
+	prefix string // the groups, joined with "."
+}
+
+// feed is the state shared by a Feed and the handlers derived from it.
+type feed struct {
+	level   slog.Leveler
+	onEntry func(Entry)
+
+	mu      sync.Mutex
+	entries []Entry // a ring of cap(entries) once full
+	next    int
+}
+
+// NewFeed returns a handler keeping the last size entries at level or
+// above. onEntry, if not nil, is called with each entry; it must not log.
+func NewFeed(size int, level slog.Leveler, onEntry func(Entry)) *Feed {
+	return &Feed{feed: &feed{level: level, onEntry: onEntry, entries: make([]Entry, 0, max(size, 1))}}
+}
+
+func (f *Feed) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= f.level.Level()
+}
+
+func (f *Feed) Handle(_ context.Context, r slog.Record) error {
+	e := Entry{Time: r.Time, Level: strings.ToLower(r.Level.String()), Message: r.Message}
+	// Attributes added with WithAttrs already carry their groups.
+	for _, a := range f.attrs {
+		f.addAttr(&e, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		f.addAttr(&e, f.prefix, a)
+		return true
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.entries) < cap(f.entries) {
+		f.entries = append(f.entries, e)
+	} else {
+		f.entries[f.next] = e
+		f.next = (f.next + 1) % len(f.entries)
+	}
+	// Still locked, so entries are passed on in order.
+	if f.onEntry != nil {
+		f.onEntry(e)
+	}
+	return nil
+}
+
+// addAttr adds a to e, the file attribute as its File.
+func (f *Feed) addAttr(e *Entry, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, g := range a.Value.Group() {
+			f.addAttr(e, prefix, g)
+		}
+		return
+	}
+	key := prefix + a.Key
+	if key == "file" {
+		e.File = a.Value.String()
+		return
+	}
+	if e.Attrs == nil {
+		e.Attrs = make(map[string]string)
+	}
+	e.Attrs[key] = a.Value.String()
+}
+
+func (f *Feed) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := *f
+	for _, a := range attrs {
+		if f.prefix != "" {
+			a.Key = f.prefix + a.Key
+		}
+		next.attrs = append(next.attrs[:len(next.attrs):len(next.attrs)], a)
+	}
+	return &next
+}
+
+func (f *Feed) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return f
+	}
+	next := *f
+	next.prefix += name + "."
+	return &next
+}
+
+// Recent returns up to the last n entries, oldest first; all of them if
+// n <= 0.
+func (f *Feed) Recent(n int) []Entry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	all := append(append([]Entry(nil), f.entries[f.next:]...), f.entries[:f.next]...)
+	if n > 0 && n < len(all) {
+		all = all[len(all)-n:]
+	}
+	return all
+}
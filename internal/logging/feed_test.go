@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestFeed(t *testing.T) {
+	var streamed []Entry
+	feed := NewFeed(2, slog.LevelInfo, func(e Entry) { streamed = append(streamed, e) })
+	log := slog.New(feed).With("batch", 1)
+
+	log.Debug("hidden")
+	log.Info("copied", "file", "a.jpg", "bytes", 10)
+	log.Warn("copy failed", "file", "b.jpg", "error", errors.New("disk full"))
+	log.WithGroup("s3").Error("upload failed", "bucket", "photos")
+
+	if len(streamed) != 3 {
+		t.Fatalf("Expected 3 entries streamed, got %d", len(streamed))
+	}
+	recent := feed.Recent(0)
+	if len(recent) != 2 || recent[0].Message != "copy failed" || recent[1].Message != "upload failed" {
+		t.Fatalf("Expected the last 2 entries, oldest first, got %+v", recent)
+	}
+	if e := recent[0]; e.Level != "warn" || e.File != "b.jpg" || e.Attrs["error"] != "disk full" || e.Attrs["batch"] != "1" {
+		t.Errorf("Unexpected entry: %+v", e)
+	}
+	if got := recent[1].Attrs["s3.bucket"]; got != "photos" {
+		t.Errorf("Expected the group in the key, got %v", recent[1].Attrs)
+	}
+	if last := feed.Recent(1); len(last) != 1 || last[0].Message != "upload failed" {
+		t.Errorf("Expected only the last entry, got %+v", last)
+	}
+}
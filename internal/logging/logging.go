@@ -0,0 +1,55 @@
+// Package logging configures the structured logger shared by the CLI and
+// the copier. Verbosity is a small integer so it maps directly onto the
+// -q, -v and -vv command-line flags.
+package logging
+
+import (
+	"io"
+	"log/slog"
+)
+
+// Verbosity levels selected by the CLI flags.
+const (
+	Quiet   = -1 // -q: only errors and the final summary
+	Normal  = 0  // default output
+	Verbose = 1  // -v: one line per file
+	Debug   = 2  // -vv: retry details as well
+)
+
+// Level returns the minimum log level shown at verbosity v. Per-file
+// failures are logged as warnings because the batch carries on; they are
+// already listed in the summary, so only -v and above show them as lines.
+func Level(v int) slog.Level {
+	switch {
+	case v >= Debug:
+		return slog.LevelDebug
+	case v == Verbose:
+		return slog.LevelInfo
+	default:
+		return slog.LevelError
+	}
+}
+
+// New returns a logger writing to w at verbosity v. JSON output keeps the
+// logs machine-readable next to -output json/ndjson; otherwise a compact
+// key=value format without timestamps is used for terminals.
+func New(w io.Writer, v int, json bool) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: Level(v)}
+	if json {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+
+	opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return a
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// Discard returns a logger that drops everything. It is the default for
+// library code until a caller opts in.
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestVerbosityLevels(t *testing.T) {
+	tests := []struct {
+		name      string
+		verbosity int
+		expected  []string
+	}{
+		{"quiet", Quiet, []string{"error"}},
+		{"normal", Normal, []string{"error"}},
+		{"verbose", Verbose, []string{"info", "warn", "error"}},
+		{"debug", Debug, []string{"debug", "info", "warn", "error"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := New(&buf, tt.verbosity, false)
+			log.Debug("debug")
+			log.Info("info")
+			log.Warn("warn")
+			log.Error("error")
+
+			out := buf.String()
+			if strings.Contains(out, "time=") {
+				t.Errorf("Text output should not include timestamps: %s", out)
+			}
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			if len(lines) != len(tt.expected) {
+				t.Fatalf("Expected %d lines, got %d: %s", len(tt.expected), len(lines), out)
+			}
+			for i, msg := range tt.expected {
+				if !strings.Contains(lines[i], "msg="+msg) {
+					t.Errorf("Line %d: expected msg=%s, got %s", i, msg, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf, Verbose, true).Info("copied", "file", "a.jpg")
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("Expected JSON log line: %v (%s)", err, buf.String())
+	}
+	if rec["msg"] != "copied" || rec["file"] != "a.jpg" {
+		t.Errorf("Unexpected record: %v", rec)
+	}
+}
@@ -0,0 +1,176 @@
+// Package manifest remembers which source files were copied to a
+// destination, so incremental runs copy only new or changed files without
+// looking at the destination, which may be append-only (files are moved
+// away after the copy) or too slow to compare.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DirName is the folder, next to the config file, holding one manifest
+// per destination.
+const DirName = "manifests"
+
+// Entry is what was known about a source file when it was copied.
+type Entry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Hash    string    `json:"hash,omitempty"` // hex SHA-256, empty if unknown
+}
+
+// Manifest holds the entries of the files copied to one destination,
+// keyed by absolute source path. It is safe for concurrent use.
+type Manifest struct {
+	path        string
+	destination string
+
+	mu    sync.Mutex
+	files map[string]Entry
+	dirty bool
+}
+
+// file is the JSON layout of a manifest file.
+type file struct {
+	Destination string           `json:"destination"`
+	Files       map[string]Entry `json:"files"`
+}
+
+// Open reads the manifest of destination from dir. A missing file is an
+// empty manifest: the first incremental run copies everything.
+func Open(dir, destination string) (*Manifest, error) {
+	destination = key(destination)
+	m := &Manifest{
+		path:        pathFor(dir, destination),
+		destination: destination,
+		files:       make(map[string]Entry),
+	}
+	data, err := os.ReadFile(m.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", m.path, err)
+	}
+	if f.Files != nil {
+		m.files = f.Files
+	}
+	return m, nil
+}
+
+// Len returns the number of files in the manifest.
+func (m *Manifest) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.files)
+}
+
+// Unchanged reports whether path was copied before and has not changed
+// since. Size and modification time decide, except when only the time
+// changed (the file was touched or restored from a backup): then the
+// content is hashed and compared with the recorded hash.
+func (m *Manifest) Unchanged(path string, info fs.FileInfo) bool {
+	key := key(path)
+	m.mu.Lock()
+	e, ok := m.files[key]
+	m.mu.Unlock()
+
+	switch {
+	case !ok || e.Size != info.Size():
+		return false
+	case e.ModTime.Equal(info.ModTime()):
+		return true
+	case e.Hash == "":
+		return false
+	}
+
+	hash, err := HashFile(path)
+	if err != nil || hash != e.Hash {
+		return false
+	}
+	// Remember the new time so the file is not hashed again next run.
+	m.Record(path, info, hash)
+	return true
+}
+
+// Record stores what was copied from path. hash may be empty.
+func (m *Manifest) Record(path string, info fs.FileInfo, hash string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[key(path)] = Entry{Size: info.Size(), ModTime: info.ModTime(), Hash: hash}
+	m.dirty = true
+}
+
+// Save writes the manifest if it changed. The file is replaced atomically
+// so an interrupted save never loses the previous runs.
+func (m *Manifest) Save() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(file{Destination: m.destination, Files: m.files})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0700); err != nil {
+		return fmt.Errorf("failed to create manifest folder: %w", err)
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, m.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	m.dirty = false
+	return nil
+}
+
+// HashFile returns the hex SHA-256 of the file at path.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// pathFor returns the manifest file in dir for destination. The name is
+// derived from the destination path, which may contain characters that
+// are not allowed in file names.
+func pathFor(dir, destination string) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(destination)))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// key returns the absolute form of path, so the same file is found
+// whichever way the source was written.
+func key(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,79 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(t.TempDir(), "a.jpg")
+	if err := os.WriteFile(src, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stat := func() os.FileInfo {
+		t.Helper()
+		info, err := os.Stat(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+
+	m, err := Open(dir, "/dst")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if m.Unchanged(src, stat()) {
+		t.Error("Expected a file missing from the manifest to be new")
+	}
+	hash, err := HashFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Record(src, stat(), hash)
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	m, err = Open(dir, "/dst")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if m.Len() != 1 || !m.Unchanged(src, stat()) {
+		t.Fatal("Expected the saved file to be unchanged")
+	}
+	if other, _ := Open(dir, "/other"); other.Len() != 0 {
+		t.Error("Expected each destination to have its own manifest")
+	}
+
+	// Touching the file keeps it unchanged; editing it does not.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(src, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if !m.Unchanged(src, stat()) {
+		t.Error("Expected a touched file with the same content to be unchanged")
+	}
+	if err := os.WriteFile(src, []byte("modified"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(src, later, later.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if m.Unchanged(src, stat()) {
+		t.Error("Expected an edited file to be changed")
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(pathFor(dir, key("/dst")), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir, "/dst"); err == nil {
+		t.Error("Expected an error for a corrupt manifest")
+	}
+}
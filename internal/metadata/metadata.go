@@ -0,0 +1,319 @@
+// Package metadata removes EXIF, XMP and IPTC metadata from images while
+// they are copied (config.Transform.StripMetadata), so that files given to
+// clients do not reveal where and with what they were taken. Only the
+// orientation is kept, so images still display the right way up.
+//
+// The pixels are not decoded: segments and chunks are dropped from the file
+// as it is, so stripping is fast and lossless.
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+
+	"copy-image/internal/processor"
+)
+
+// ErrUnsupported is matched by the error for a file whose metadata cannot
+// be removed. Such files fail rather than be copied with their metadata.
+var ErrUnsupported = errors.New("cannot remove metadata from this type of file")
+
+// Processor returns the processor stripping metadata from the files copied.
+func Processor() processor.FileProcessor {
+	return stripper{}
+}
+
+type stripper struct{}
+
+func (stripper) Name() string { return "strip_metadata" }
+
+func (stripper) Transform(ctx context.Context, f processor.File, r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	out, err := Strip(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Name, err)
+	}
+	return bytes.NewReader(out), nil
+}
+
+// Strip returns data without its metadata. The format is read from the
+// content, as an earlier processor may have converted the file: JPEG, PNG
+// and WebP files are stripped, BMP and GIF files, which hold no camera
+// metadata, are returned as they are, and other files fail with
+// ErrUnsupported.
+func Strip(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		return stripJPEG(data)
+	case bytes.HasPrefix(data, pngSignature):
+		return stripPNG(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return stripWebP(data)
+	case bytes.HasPrefix(data, []byte("BM")), bytes.HasPrefix(data, []byte("GIF8")):
+		return data, nil
+	}
+	return nil, ErrUnsupported
+}
+
+var errTruncated = errors.New("image data is truncated")
+
+// JPEG markers.
+const (
+	markerSOI  = 0xD8
+	markerEOI  = 0xD9
+	markerSOS  = 0xDA
+	markerAPP0 = 0xE0
+	markerAPP1 = 0xE1
+	markerAPP2 = 0xE2
+	markerAPPE = 0xEE
+	markerCOM  = 0xFE
+)
+
+var exifHeader = []byte("Exif\x00\x00")
+
+// stripJPEG keeps the segments needed to display the image (JFIF, ICC
+// profiles and the Adobe color transform) and drops the other
+// application segments and comments, which is where EXIF, XMP, IPTC and
+// maker data live. Data after the end of the image, such as the extra
+// images of MPO files, is dropped as well.
+func stripJPEG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, markerSOI)
+	orientation := 0
+	exifAt := -1 // where the orientation is written: after JFIF, if any
+	pos := 2
+	for {
+		// Markers may be padded with any number of 0xFF bytes.
+		for pos+1 < len(data) && data[pos] == 0xFF && data[pos+1] == 0xFF {
+			pos++
+		}
+		if pos+2 > len(data) || data[pos] != 0xFF {
+			return nil, errTruncated
+		}
+		marker := data[pos+1]
+		if marker == markerEOI {
+			out = append(out, 0xFF, markerEOI)
+			break
+		}
+		if pos+4 > len(data) {
+			return nil, errTruncated
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end < pos+4 || end > len(data) {
+			return nil, errTruncated
+		}
+		segment := data[pos:end]
+		payload := segment[4:]
+
+		switch {
+		case marker == markerAPP1 && bytes.HasPrefix(payload, exifHeader):
+			if o := readOrientation(payload[len(exifHeader):]); o > 1 {
+				orientation = o
+			}
+		case marker == markerAPP0:
+			out = append(out, segment...)
+			if exifAt < 0 {
+				exifAt = len(out)
+			}
+		case marker == markerAPP2 && bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00")),
+			marker == markerAPPE && bytes.HasPrefix(payload, []byte("Adobe")):
+			out = append(out, segment...)
+		case marker >= markerAPP0 && marker <= 0xEF, marker == markerCOM:
+			// Dropped.
+		default:
+			out = append(out, segment...)
+		}
+		pos = end
+
+		if marker == markerSOS {
+			scan, err := scanLength(data[pos:])
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, data[pos:pos+scan]...)
+			pos += scan
+		}
+	}
+
+	if orientation == 0 {
+		return out, nil
+	}
+	if exifAt < 0 {
+		exifAt = 2
+	}
+	payload := append(append([]byte{}, exifHeader...), orientationTIFF(orientation)...)
+	segment := []byte{0xFF, markerAPP1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(payload)))
+	segment = append(segment, payload...)
+	return slices.Insert(out, exifAt, segment...), nil
+}
+
+// scanLength returns the length of the entropy-coded data at the start of
+// data, which ends at the first marker other than a stuffed 0xFF byte or a
+// restart marker.
+func scanLength(data []byte) (int, error) {
+	for i := 0; i+1 < len(data); i++ {
+		if data[i] != 0xFF {
+			continue
+		}
+		next := data[i+1]
+		if next != 0 && next != 0xFF && (next < 0xD0 || next > 0xD7) {
+			return i, nil
+		}
+	}
+	return 0, errTruncated
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// strippedChunks are the PNG chunks holding metadata: EXIF, text (which
+// includes XMP) and the modification time.
+var strippedChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// stripPNG drops the metadata chunks of a PNG file, keeping the
+// orientation of an eXIf chunk in a new one before the image data.
+func stripPNG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	orientation := 0
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, errTruncated
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		kind := string(data[pos+4 : pos+8])
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errTruncated
+		}
+		if kind == "eXIf" {
+			if o := readOrientation(data[pos+8 : pos+8+length]); o > 1 {
+				orientation = o
+			}
+		}
+		if kind == "IDAT" && orientation > 0 {
+			out = appendPNGChunk(out, "eXIf", orientationTIFF(orientation))
+			orientation = 0
+		}
+		if !strippedChunks[kind] {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+		if kind == "IEND" {
+			break
+		}
+	}
+	return out, nil
+}
+
+func appendPNGChunk(out []byte, kind string, data []byte) []byte {
+	out = binary.BigEndian.AppendUint32(out, uint32(len(data)))
+	start := len(out)
+	out = append(out, kind...)
+	out = append(out, data...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out[start:]))
+}
+
+// VP8X flags telling that a WebP file has EXIF or XMP chunks.
+const (
+	webpEXIF = 0x08
+	webpXMP  = 0x04
+)
+
+// stripWebP drops the EXIF and XMP chunks of an extended WebP file and
+// clears their flags. Simple WebP files hold no metadata.
+func stripWebP(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	pos := 12
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, errTruncated
+		}
+		kind := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2 // chunks are padded to an even size
+		if size < 0 || end > len(data) {
+			return nil, errTruncated
+		}
+		switch kind {
+		case "EXIF", "XMP ":
+			// Dropped.
+		case "VP8X":
+			chunk := append([]byte{}, data[pos:end]...)
+			if size > 0 {
+				chunk[8] &^= webpEXIF | webpXMP
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// orientationTag is the EXIF tag of the orientation, and tiffShort the
+// type of its value.
+const (
+	orientationTag = 0x0112
+	tiffShort      = 3
+)
+
+// readOrientation returns the orientation, 1 to 8, in the first IFD of
+// the TIFF-structured EXIF data, or 0 when it has none.
+func readOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := range count {
+		entry := ifd + 2 + 12*i
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == orientationTag && order.Uint16(tiff[entry+2:]) == tiffShort {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// orientationTIFF returns EXIF data holding nothing but the orientation.
+func orientationTIFF(orientation int) []byte {
+	tiff := []byte("MM\x00\x2A\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // one entry
+	tiff = binary.BigEndian.AppendUint16(tiff, orientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, tiffShort)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1) // one value
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0)                     // padding of the value
+	return binary.BigEndian.AppendUint32(tiff, 0) // no next IFD
+}
@@ -0,0 +1,164 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifWithOrientation returns little-endian EXIF data holding the
+// orientation and a camera serial number.
+func exifWithOrientation(orientation int) []byte {
+	tiff := []byte("II\x2A\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientationTag)
+	tiff = binary.LittleEndian.AppendUint16(tiff, tiffShort)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint32(tiff, uint32(orientation))
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0xA431) // body serial number
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)      // ASCII
+	tiff = binary.LittleEndian.AppendUint32(tiff, 4)
+	tiff = append(tiff, "SN9\x00"...)
+	return binary.LittleEndian.AppendUint32(tiff, 0)
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(payload)))
+	return append(segment, payload...)
+}
+
+// testJPEG returns a JPEG with EXIF, XMP and a comment, followed by
+// trailing data.
+func testJPEG(t *testing.T, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+	data := append([]byte{}, plain[:2]...)
+	data = append(data, jpegSegment(markerAPP0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))...)
+	data = append(data, jpegSegment(markerAPP1, append(append([]byte{}, exifHeader...), exifWithOrientation(orientation)...))...)
+	data = append(data, jpegSegment(markerAPP1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>SN9</x:xmpmeta>"))...)
+	data = append(data, jpegSegment(markerCOM, []byte("SN9"))...)
+	data = append(data, plain[2:]...)
+	return append(data, "SN9 trailer"...)
+}
+
+func pngChunk(kind string, data []byte) []byte {
+	return appendPNGChunk(nil, kind, data)
+}
+
+// testPNG returns a PNG with an eXIf chunk and text before its image data.
+func testPNG(t *testing.T, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 8))); err != nil {
+		t.Fatal(err)
+	}
+	plain := buf.Bytes()
+	ihdrEnd := len(pngSignature) + 12 + 13
+	data := append([]byte{}, plain[:ihdrEnd]...)
+	data = append(data, pngChunk("eXIf", exifWithOrientation(orientation))...)
+	data = append(data, pngChunk("tEXt", []byte("Comment\x00SN9"))...)
+	return append(data, plain[ihdrEnd:]...)
+}
+
+func testWebP(t *testing.T) []byte {
+	t.Helper()
+	chunk := func(kind string, data []byte) []byte {
+		c := append([]byte(kind), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+		c = append(c, data...)
+		if len(data)%2 == 1 {
+			c = append(c, 0)
+		}
+		return c
+	}
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", []byte{webpEXIF | webpXMP, 0, 0, 0, 15, 0, 0, 7, 0, 0})...)
+	body = append(body, chunk("VP8L", []byte("pixels"))...)
+	body = append(body, chunk("EXIF", exifWithOrientation(1))...)
+	body = append(body, chunk("XMP ", []byte("<x:xmpmeta>SN9</x:xmpmeta>"))...)
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func TestStrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		format      string // decoded from the output; "" when it is not decoded
+		orientation int    // kept in the output
+	}{
+		{"jpeg", testJPEG(t, 6), "jpeg", 6},
+		{"upright jpeg", testJPEG(t, 1), "jpeg", 0},
+		{"png", testPNG(t, 8), "png", 8},
+		{"webp", testWebP(t), "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Strip(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(out, []byte("SN9")) {
+				t.Errorf("Expected the metadata to be removed, got %q", out)
+			}
+			if tt.format != "" {
+				if _, format, err := image.Decode(bytes.NewReader(out)); err != nil || format != tt.format {
+					t.Errorf("Expected a valid %s, got %s (%v)", tt.format, format, err)
+				}
+			}
+			if got := orientationOf(out); got != tt.orientation {
+				t.Errorf("Expected orientation %d, got %d", tt.orientation, got)
+			}
+		})
+	}
+}
+
+// orientationOf returns the orientation kept in a stripped JPEG or PNG.
+func orientationOf(data []byte) int {
+	if i := bytes.Index(data, exifHeader); i >= 0 {
+		return readOrientation(data[i+len(exifHeader):])
+	}
+	if i := bytes.Index(data, []byte("eXIf")); i >= 4 {
+		length := binary.BigEndian.Uint32(data[i-4:])
+		chunk := data[i : i+4+int(length)]
+		if crc32.ChecksumIEEE(chunk) != binary.BigEndian.Uint32(data[i+len(chunk):]) {
+			return -1
+		}
+		return readOrientation(chunk[4:])
+	}
+	return 0
+}
+
+func TestStripWebPFlags(t *testing.T) {
+	out, err := Strip(testWebP(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags := out[20]; flags&(webpEXIF|webpXMP) != 0 {
+		t.Errorf("Expected the metadata flags to be cleared, got %08b", flags)
+	}
+	if size := binary.LittleEndian.Uint32(out[4:]); int(size) != len(out)-8 {
+		t.Errorf("Expected a RIFF size of %d, got %d", len(out)-8, size)
+	}
+}
+
+func TestStripUnsupported(t *testing.T) {
+	if _, err := Strip([]byte("II\x2A\x00 raw data")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if _, err := Strip(testJPEG(t, 1)[:100]); err == nil {
+		t.Error("Expected an error for a truncated JPEG")
+	}
+	gif := []byte("GIF89a data")
+	if out, err := Strip(gif); err != nil || !bytes.Equal(out, gif) {
+		t.Errorf("Expected a GIF to be copied as it is, got %q (%v)", out, err)
+	}
+}
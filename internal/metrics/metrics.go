@@ -0,0 +1,135 @@
+// Package metrics exposes what long-running modes (watch, the scheduler
+// and the service) are copying as Prometheus metrics, so monitoring can
+// alert when imports fail or stall.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"copy-image/internal/copier"
+)
+
+// Path is where Start exposes the metrics.
+const Path = "/metrics"
+
+// Collector records the files copied by every copier it is attached to
+// (copier.SetMetrics) and implements copier.Metrics.
+type Collector struct {
+	registry *prometheus.Registry
+
+	files    *prometheus.CounterVec
+	bytes    prometheus.Counter
+	retries  prometheus.Counter
+	queued   prometheus.Gauge
+	busy     prometheus.Gauge
+	duration prometheus.Histogram
+	lastCopy prometheus.Gauge
+}
+
+// New returns a Collector with its metrics registered, along with the Go
+// runtime and process metrics Prometheus usually shows next to them.
+func New() *Collector {
+	c := &Collector{
+		registry: prometheus.NewRegistry(),
+		files: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "copyimage_files_total",
+			Help: "Files processed, by status (copied, skipped or failed).",
+		}, []string{"status"}),
+		bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "copyimage_bytes_copied_total",
+			Help: "Bytes written by successful copies.",
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "copyimage_retries_total",
+			Help: "Attempts made again after a failed copy attempt.",
+		}),
+		queued: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "copyimage_queue_depth",
+			Help: "Files waiting for a worker.",
+		}),
+		busy: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "copyimage_active_workers",
+			Help: "Workers currently copying a file.",
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "copyimage_file_duration_seconds",
+			Help: "Time spent on each file, including retries.",
+			// From small JPEGs on a local disk to large videos over a share.
+			Buckets: prometheus.ExponentialBuckets(0.01, 4, 9),
+		}),
+		lastCopy: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "copyimage_last_copy_timestamp_seconds",
+			Help: "Unix time of the last successful copy, for alerts on stalled imports.",
+		}),
+	}
+	// Start every status at zero so rate() and absent alerts work before
+	// the first failure.
+	for _, status := range []string{"copied", "skipped", "failed"} {
+		c.files.WithLabelValues(status)
+	}
+	c.registry.MustRegister(c.files, c.bytes, c.retries, c.queued, c.busy, c.duration, c.lastCopy,
+		collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return c
+}
+
+// Queued implements copier.Metrics.
+func (c *Collector) Queued(delta int) { c.queued.Add(float64(delta)) }
+
+// Busy implements copier.Metrics.
+func (c *Collector) Busy(delta int) { c.busy.Add(float64(delta)) }
+
+// Retried implements copier.Metrics.
+func (c *Collector) Retried() { c.retries.Inc() }
+
+// Done implements copier.Metrics.
+func (c *Collector) Done(result copier.CopyResult) {
+	switch {
+	case result.Success:
+		c.files.WithLabelValues("copied").Inc()
+		c.bytes.Add(float64(result.Bytes))
+		c.lastCopy.SetToCurrentTime()
+	case result.Skipped:
+		c.files.WithLabelValues("skipped").Inc()
+	default:
+		c.files.WithLabelValues("failed").Inc()
+	}
+	c.duration.Observe(result.Duration.Seconds())
+}
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus
+// text format.
+func (c *Collector) Handler() http.Handler {
+	return promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{})
+}
+
+// Start listens on addr (e.g. ":9464") and serves the metrics at Path in
+// the background until ctx is cancelled. A port already in use is
+// reported right away rather than once the first scrape fails.
+func (c *Collector) Start(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, c.Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() { _ = srv.Serve(ln) }()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	return nil
+}
+
+var _ copier.Metrics = (*Collector)(nil)
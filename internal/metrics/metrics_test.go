@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"copy-image/internal/copier"
+)
+
+func TestCollector(t *testing.T) {
+	c := New()
+	c.Queued(3)
+	c.Queued(-1)
+	c.Busy(1)
+	c.Retried()
+	c.Done(copier.CopyResult{Success: true, Bytes: 1024, Duration: 20 * time.Millisecond})
+	c.Done(copier.CopyResult{Skipped: true})
+	c.Done(copier.CopyResult{Error: errors.New("disk full"), Duration: time.Second})
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`copyimage_files_total{status="copied"} 1`,
+		`copyimage_files_total{status="skipped"} 1`,
+		`copyimage_files_total{status="failed"} 1`,
+		"copyimage_bytes_copied_total 1024",
+		"copyimage_retries_total 1",
+		"copyimage_queue_depth 2",
+		"copyimage_active_workers 1",
+		"copyimage_file_duration_seconds_count 3",
+		"copyimage_last_copy_timestamp_seconds ",
+		"go_goroutines ",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in the metrics, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestCollectorStartsAtZero(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	if !strings.Contains(rec.Body.String(), `copyimage_files_total{status="failed"} 0`) {
+		t.Errorf("Expected the failed counter before any failure, got:\n%s", rec.Body.String())
+	}
+}
+
+func TestStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Find a free port, then serve on it.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	c := New()
+	if err := c.Start(ctx, addr); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	// The port is now taken.
+	if err := New().Start(ctx, addr); err == nil {
+		t.Error("Expected an error for a port in use")
+	}
+
+	resp, err := http.Get("http://" + addr + Path)
+	if err != nil {
+		t.Fatalf("Scrape failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "copyimage_queue_depth 0") {
+		t.Errorf("Unexpected scrape: %d\n%s", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,183 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/utils"
+)
+
+// maxMailedFailures caps the failed files listed in a mail; a card that
+// failed entirely would otherwise produce a mail servers reject.
+const maxMailedFailures = 500
+
+// mail sends the report of e to the configured recipients.
+func mail(ctx context.Context, e config.Email, ev Event) error {
+	host, _, err := net.SplitHostPort(e.Address())
+	if err != nil {
+		return err
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if strings.HasSuffix(e.Address(), ":465") {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", e.Address())
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", e.Address())
+	}
+	if err != nil {
+		return err
+	}
+	// net/smtp has no context support; the deadline bounds the whole
+	// conversation instead.
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if username, password := e.Credentials(); username != "" {
+		// PlainAuth refuses to send the password without TLS, except
+		// to localhost.
+		if err := client.Auth(smtp.PlainAuth("", username, password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(e.Sender()); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %s: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message(e, ev)); err != nil {
+		_ = w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// message returns the mail for ev: a subject saying how the batch ended
+// and a plain-text body with the summary and the failed files.
+func message(e config.Email, ev Event) []byte {
+	var body bytes.Buffer
+	qp := quotedprintable.NewWriter(&body)
+	_, _ = qp.Write([]byte(reportText(ev)))
+	_ = qp.Close()
+
+	var b bytes.Buffer
+	header := func(name, value string) { fmt.Fprintf(&b, "%s: %s\r\n", name, value) }
+	header("From", e.Sender())
+	header("To", strings.Join(e.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", subject(ev)))
+	header("Date", ev.Time.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	b.WriteString("\r\n")
+	b.Write(body.Bytes())
+	return b.Bytes()
+}
+
+func subject(ev Event) string {
+	what := ev.Destination
+	switch {
+	case ev.Batch != nil:
+		what = fmt.Sprintf("%d group(s)", len(ev.Batch.Groups))
+	case ev.GroupID != "":
+		what = ev.GroupID + " → " + ev.Destination
+	}
+	switch {
+	case ev.Summary == nil:
+		return fmt.Sprintf("[copy-image] %s: copying %d files to %s", ev.Host, ev.Files, what)
+	case ev.Cancelled:
+		return fmt.Sprintf("[copy-image] %s: cancelled copy to %s", ev.Host, what)
+	case ev.Summary.Failed > 0:
+		return fmt.Sprintf("[copy-image] %s: %d of %d files failed to copy to %s", ev.Host, ev.Summary.Failed, ev.Summary.TotalFiles, what)
+	}
+	return fmt.Sprintf("[copy-image] %s: %d files copied to %s", ev.Host, ev.Summary.Successful, what)
+}
+
+func reportText(ev Event) string {
+	var b strings.Builder
+	line := func(format string, args ...any) { fmt.Fprintf(&b, format+"\r\n", args...) }
+	line("Host:        %s", ev.Host)
+	line("Run:         %s", ev.Kind)
+	if ev.GroupID != "" {
+		line("Group:       %s", ev.GroupID)
+	}
+	if ev.Batch == nil {
+		line("Source:      %s", ev.Source)
+		line("Destination: %s", ev.Destination)
+	}
+	line("Time:        %s", ev.Time.Format(time.RFC1123))
+	if ev.DryRun {
+		line("Dry run:     nothing was written")
+	}
+	s := ev.Summary
+	if s == nil {
+		line("Files:       %d", ev.Files)
+		return b.String()
+	}
+	if ev.Cancelled {
+		line("Cancelled before all files were copied.")
+	}
+	line("")
+	line("Total:       %d", s.TotalFiles)
+	line("Copied:      %d", s.Successful)
+	line("Skipped:     %d", s.Skipped)
+	line("Failed:      %d", s.Failed)
+	line("Duration:    %s", (time.Duration(s.Duration * float64(time.Second))).Round(time.Second))
+	if s.TotalBytes > 0 {
+		line("Written:     %s", utils.FormatBytes(s.TotalBytes))
+	}
+	if ev.Batch != nil {
+		line("")
+		for _, g := range ev.Batch.Groups {
+			if g.Err != nil {
+				line("%s: %v", g.GroupID, g.Err)
+				continue
+			}
+			line("%s:", g.GroupID)
+			for _, d := range g.Destinations {
+				line("  %s: %d copied, %d skipped, %d failed", d.Path, d.Summary.Successful, d.Summary.Skipped, d.Summary.Failed)
+			}
+		}
+	}
+	if len(s.FailedFiles) == 0 {
+		return b.String()
+	}
+	line("")
+	line("Failed files:")
+	for i, f := range s.FailedFiles {
+		if i == maxMailedFailures {
+			line("  ... and %d more", len(s.FailedFiles)-i)
+			break
+		}
+		line("  %s", f)
+	}
+	return b.String()
+}
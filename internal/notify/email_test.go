@@ -0,0 +1,193 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+)
+
+// smtpServer is a minimal SMTP server that records the envelope and the
+// data of each mail it accepts.
+type smtpServer struct {
+	addr  string
+	mails chan smtpMail
+}
+
+type smtpMail struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	s := &smtpServer{addr: ln.Addr().String(), mails: make(chan smtpMail, 4)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP")
+	var m smtpMail
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO":
+			_ = tp.PrintfLine("250-localhost")
+			_ = tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			m.auth = arg
+			_ = tp.PrintfLine("235 ok")
+		case "MAIL":
+			m.from = arg
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			m.to = append(m.to, arg)
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			m.data = string(data)
+			s.mails <- m
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 unknown")
+		}
+	}
+}
+
+func emailConfig(t *testing.T, addr string) *config.Email {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// PlainAuth only sends a password without TLS to localhost.
+	return &config.Email{Host: "localhost", Port: p, To: []string{"it@example.com", "ops@example.com"}}
+}
+
+func TestEmailReport(t *testing.T) {
+	srv := newSMTPServer(t)
+	t.Setenv("SMTP_PASSWORD", "s3cret")
+	email := emailConfig(t, srv.addr)
+	email.Username = "nas@example.com"
+	email.Password = "${SMTP_PASSWORD}"
+	n := New(&config.Config{Email: email})
+	c := testCopier()
+
+	// Start events are not mailed by default.
+	if err := n.Started(context.Background(), "schedule", "photos", c, 3); err != nil {
+		t.Fatalf("Started failed: %v", err)
+	}
+	summary := copier.CopySummary{TotalFiles: 3, Successful: 2, Failed: 1, FailedFiles: []copier.FailedFile{{Path: "/cards/IMG_0001.CR3", Err: errors.New("disk full")}}, Duration: 90 * time.Second}
+	if err := n.Finished(context.Background(), "schedule", "photos", c, summary, false); err != nil {
+		t.Fatalf("Finished failed: %v", err)
+	}
+
+	var m smtpMail
+	select {
+	case m = <-srv.mails:
+	case <-time.After(5 * time.Second):
+		t.Fatal("No mail received")
+	}
+	if len(srv.mails) != 0 {
+		t.Error("Expected a single mail")
+	}
+	if m.from != "FROM:<nas@example.com>" || strings.Join(m.to, ",") != "TO:<it@example.com>,TO:<ops@example.com>" {
+		t.Errorf("Unexpected envelope: %+v", m)
+	}
+	if !strings.HasPrefix(m.auth, "PLAIN ") {
+		t.Errorf("Expected PLAIN authentication, got %q", m.auth)
+	}
+
+	// DotReader turns the CRLF line endings into LF.
+	header, body, _ := strings.Cut(m.data, "\n\n")
+	if !strings.Contains(header, "Subject: =?utf-8?q?") || !strings.Contains(header, "To: it@example.com, ops@example.com") {
+		t.Errorf("Unexpected headers:\n%s", header)
+	}
+	text, err := io.ReadAll(quotedprintable.NewReader(bufio.NewReader(strings.NewReader(body))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Group:       photos", "Failed:      1", "Duration:    1m30s", "IMG_0001.CR3: disk full"} {
+		if !strings.Contains(string(text), want) {
+			t.Errorf("Expected %q in the body:\n%s", want, text)
+		}
+	}
+}
+
+func TestSubject(t *testing.T) {
+	base := Event{Host: "nas", Destination: `D:\Photos`, Files: 4}
+	tests := []struct {
+		name    string
+		event   func(Event) Event
+		subject string
+	}{
+		{"start", func(e Event) Event { return e }, `[copy-image] nas: copying 4 files to D:\Photos`},
+		{"complete", func(e Event) Event { e.Summary = &Summary{TotalFiles: 4, Successful: 4}; return e }, `[copy-image] nas: 4 files copied to D:\Photos`},
+		{"failure", func(e Event) Event {
+			e.GroupID = "cards"
+			e.Summary = &Summary{TotalFiles: 4, Successful: 3, Failed: 1}
+			return e
+		}, `[copy-image] nas: 1 of 4 files failed to copy to cards → D:\Photos`},
+		{"cancelled", func(e Event) Event { e.Cancelled = true; e.Summary = &Summary{}; return e }, `[copy-image] nas: cancelled copy to D:\Photos`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subject(tt.event(base)); got != tt.subject {
+				t.Errorf("subject() = %q, want %q", got, tt.subject)
+			}
+		})
+	}
+}
+
+func TestReportCapsFailedFiles(t *testing.T) {
+	failed := make([]string, maxMailedFailures+20)
+	for i := range failed {
+		failed[i] = fmt.Sprintf("IMG_%04d.JPG: read error", i)
+	}
+	text := reportText(Event{Summary: &Summary{Failed: len(failed), FailedFiles: failed}})
+	if !strings.Contains(text, "... and 20 more") || strings.Contains(text, fmt.Sprintf("IMG_%04d", maxMailedFailures)) {
+		t.Errorf("Expected the list to stop after %d files", maxMailedFailures)
+	}
+}
@@ -0,0 +1,244 @@
+// Package notify tells webhooks, such as Slack or Teams incoming webhooks,
+// and email recipients when copy batches start, complete or fail.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+)
+
+// timeout bounds each request so an unreachable endpoint cannot hold up
+// the copy for long.
+const timeout = 10 * time.Second
+
+// Summary is the result of a batch, shaped like the CLI's JSON summary.
+type Summary struct {
+	TotalFiles  int      `json:"totalFiles"`
+	Successful  int      `json:"successful"`
+	Failed      int      `json:"failed"`
+	Skipped     int      `json:"skipped"`
+	FailedFiles []string `json:"failedFiles"`
+	Duration    float64  `json:"duration"` // in seconds
+	TotalBytes  int64    `json:"totalBytes"`
+}
+
+// Event is what webhooks receive: the JSON body by default, or the data of
+// their template.
+type Event struct {
+	Event       string    `json:"event"` // config.EventStart, EventComplete or EventFailure
+	Time        time.Time `json:"time"`
+	Host        string    `json:"host"`
+	Kind        string    `json:"kind"` // what started the batch, as in the run history
+	GroupID     string    `json:"groupId,omitempty"`
+	Source      string    `json:"source,omitempty"`      // empty for batch events
+	Destination string    `json:"destination,omitempty"` // empty for batch events
+	Files       int       `json:"files"`
+	DryRun      bool      `json:"dryRun,omitempty"`
+	Cancelled   bool      `json:"cancelled,omitempty"`
+	Summary     *Summary  `json:"summary,omitempty"` // nil for start events
+	// Batch is the summary of each group and destination, for batch
+	// events; Summary is then its total.
+	Batch *copier.BatchSummary `json:"batch,omitempty"`
+}
+
+// Notifier sends events to the webhooks and the email recipients of a
+// config. A nil Notifier, or one with neither, sends nothing.
+type Notifier struct {
+	hooks  []config.Webhook
+	email  *config.Email
+	client *http.Client
+	dryRun bool
+}
+
+// New returns a Notifier for the webhooks and email settings of cfg.
+func New(cfg *config.Config) *Notifier {
+	return &Notifier{hooks: cfg.Webhooks, email: cfg.Email, client: &http.Client{Timeout: timeout}, dryRun: cfg.DryRun}
+}
+
+// Started sends the start event of a batch of files about to be copied
+// by c.
+func (n *Notifier) Started(ctx context.Context, kind, groupID string, c *copier.Copier, files int) error {
+	e := newEvent(config.EventStart, kind, groupID, c)
+	e.Files = files
+	return n.Send(ctx, e)
+}
+
+// Finished sends the complete event of a batch, or the failure event when
+// files failed or the batch was cancelled.
+func (n *Notifier) Finished(ctx context.Context, kind, groupID string, c *copier.Copier, summary copier.CopySummary, cancelled bool) error {
+	event := config.EventComplete
+	if summary.Failed > 0 || cancelled {
+		event = config.EventFailure
+	}
+	e := newEvent(event, kind, groupID, c)
+	e.Files = summary.TotalFiles
+	e.Cancelled = cancelled
+	e.Summary = newSummary(summary)
+	// The batch may have ended because ctx was cancelled; still say so.
+	return n.Send(context.WithoutCancel(ctx), e)
+}
+
+// Batch sends the batch event once several groups ran, with the summary
+// of each group and destination.
+func (n *Notifier) Batch(ctx context.Context, kind string, batch copier.BatchSummary, cancelled bool) error {
+	if n == nil {
+		return nil
+	}
+	host, _ := os.Hostname()
+	total := batch.Total()
+	return n.Send(ctx, Event{
+		Event:     config.EventBatch,
+		Time:      time.Now(),
+		Host:      host,
+		Kind:      kind,
+		Files:     total.TotalFiles,
+		DryRun:    n.dryRun,
+		Cancelled: cancelled,
+		Summary:   newSummary(total),
+		Batch:     &batch,
+	})
+}
+
+func newSummary(summary copier.CopySummary) *Summary {
+	return &Summary{
+		TotalFiles:  summary.TotalFiles,
+		Successful:  summary.Successful,
+		Failed:      summary.Failed,
+		Skipped:     summary.Skipped,
+		FailedFiles: summary.FailureLines(),
+		Duration:    summary.Duration.Seconds(),
+		TotalBytes:  summary.TotalBytes,
+	}
+}
+
+// Wrap returns run with start and end events sent around each batch, for
+// group, watch and scheduled runs. groupID is read when a batch starts
+// and may be nil. Failing to notify is passed to warn and never fails the
+// batch.
+func (n *Notifier) Wrap(kind string, groupID *string, run copier.DestinationRunner, warn func(error)) copier.DestinationRunner {
+	if n == nil || (len(n.hooks) == 0 && n.email == nil) {
+		return run
+	}
+	return func(ctx context.Context, c *copier.Copier, files []string) copier.CopySummary {
+		id := ""
+		if groupID != nil {
+			id = *groupID
+		}
+		if err := n.Started(ctx, kind, id, c, len(files)); err != nil {
+			warn(err)
+		}
+		summary := run(ctx, c, files)
+		if err := n.Finished(ctx, kind, id, c, summary, ctx.Err() != nil); err != nil {
+			warn(err)
+		}
+		return summary
+	}
+}
+
+// Send posts e to every webhook subscribed to its event and mails it when
+// email wants it. All of them are tried; the error lists those that failed.
+func (n *Notifier) Send(ctx context.Context, e Event) error {
+	if n == nil {
+		return nil
+	}
+	var errs []error
+	for _, hook := range n.hooks {
+		if !hook.Wants(e.Event) {
+			continue
+		}
+		if err := n.post(ctx, hook, e); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", redact(hook.Endpoint()), err))
+		}
+	}
+	if n.email != nil && n.email.Wants(e.Event) {
+		if err := mail(ctx, *n.email, e); err != nil {
+			errs = append(errs, fmt.Errorf("email via %s: %w", n.email.Address(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) post(ctx context.Context, hook config.Webhook, e Event) error {
+	body, err := render(hook, e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.Endpoint(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "copy-image")
+	for name, value := range hook.HeaderValues() {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// The error repeats the whole URL; keep only the cause.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("server answered %s", resp.Status)
+	}
+	return nil
+}
+
+// render returns the request body for e: the webhook's template executed
+// with e, or e as JSON.
+func render(hook config.Webhook, e Event) ([]byte, error) {
+	tmpl, err := hook.ParseTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	if tmpl == nil {
+		return json.Marshal(e)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, e); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func newEvent(event, kind, groupID string, c *copier.Copier) Event {
+	host, _ := os.Hostname()
+	cfg := c.Config()
+	return Event{
+		Event:       event,
+		Time:        time.Now(),
+		Host:        host,
+		Kind:        kind,
+		GroupID:     groupID,
+		Source:      cfg.Source,
+		Destination: cfg.Destination,
+		DryRun:      cfg.DryRun,
+	}
+}
+
+// redact leaves the path and query out of a webhook URL in messages:
+// for Slack and Teams they are the secret.
+func redact(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	return u.Scheme + "://" + u.Host
+}
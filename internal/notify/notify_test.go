@@ -0,0 +1,201 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+)
+
+// hookServer records the requests it receives.
+type hookServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	response int
+}
+
+func newHookServer(t *testing.T) *hookServer {
+	s := &hookServer{response: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		s.headers = append(s.headers, r.Header.Clone())
+		code := s.response
+		s.mu.Unlock()
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func testCopier() *copier.Copier {
+	cfg := config.DefaultConfig()
+	cfg.Source = "/card"
+	cfg.Destination = "/nas/photos"
+	return copier.New(cfg)
+}
+
+func TestFinishedSendsSummary(t *testing.T) {
+	srv := newHookServer(t)
+	n := New(&config.Config{Webhooks: []config.Webhook{{URL: srv.URL}}})
+
+	summary := copier.CopySummary{TotalFiles: 3, Successful: 2, Failed: 1, FailedFiles: []copier.FailedFile{{Path: "/cards/a.jpg", Err: errors.New("disk full")}}, Duration: 1500 * time.Millisecond}
+	if err := n.Finished(context.Background(), "group", "photos", testCopier(), summary, false); err != nil {
+		t.Fatalf("Finished failed: %v", err)
+	}
+	if len(srv.bodies) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(srv.bodies))
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(srv.bodies[0]), &e); err != nil {
+		t.Fatalf("Body is not an event: %v\n%s", err, srv.bodies[0])
+	}
+	if e.Event != config.EventFailure || e.Kind != "group" || e.GroupID != "photos" || e.Destination != "/nas/photos" {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+	if e.Summary == nil || e.Summary.Failed != 1 || e.Summary.Duration != 1.5 || len(e.Summary.FailedFiles) != 1 {
+		t.Errorf("Unexpected summary: %+v", e.Summary)
+	}
+	if ct := srv.headers[0].Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected a JSON content type, got %q", ct)
+	}
+}
+
+func TestBatchSendsGroups(t *testing.T) {
+	srv := newHookServer(t)
+	all := newHookServer(t)
+	n := New(&config.Config{Webhooks: []config.Webhook{{URL: srv.URL, Events: []string{"batch"}}, {URL: all.URL}}})
+
+	batch := copier.BatchSummary{Groups: []copier.GroupSummary{{GroupID: "photos", Destinations: []copier.DestinationSummary{
+		{DestinationID: "nas", Path: "/nas/photos", Summary: copier.CopySummary{TotalFiles: 2, Successful: 2}},
+	}}}}
+	if err := n.Batch(context.Background(), "group", batch, false); err != nil {
+		t.Fatalf("Batch failed: %v", err)
+	}
+	if len(all.bodies) != 0 {
+		t.Errorf("Expected batch events only for webhooks listing them, got %v", all.bodies)
+	}
+	if len(srv.bodies) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(srv.bodies))
+	}
+	var e struct {
+		Event   string   `json:"event"`
+		Summary *Summary `json:"summary"`
+		Batch   struct {
+			Groups []struct {
+				GroupID      string `json:"groupId"`
+				Destinations []struct {
+					Path string `json:"path"`
+				} `json:"destinations"`
+			} `json:"groups"`
+		} `json:"batch"`
+	}
+	if err := json.Unmarshal([]byte(srv.bodies[0]), &e); err != nil {
+		t.Fatalf("Body is not an event: %v\n%s", err, srv.bodies[0])
+	}
+	if e.Event != config.EventBatch || e.Summary == nil || e.Summary.Successful != 2 {
+		t.Errorf("Unexpected event: %s", srv.bodies[0])
+	}
+	if len(e.Batch.Groups) != 1 || e.Batch.Groups[0].GroupID != "photos" || len(e.Batch.Groups[0].Destinations) != 1 {
+		t.Errorf("Expected the groups and their destinations, got %s", srv.bodies[0])
+	}
+}
+
+func TestEventsFilter(t *testing.T) {
+	srv := newHookServer(t)
+	n := New(&config.Config{Webhooks: []config.Webhook{{URL: srv.URL, Events: []string{"failure"}}}})
+	c := testCopier()
+
+	_ = n.Started(context.Background(), "copy", "", c, 10)
+	_ = n.Finished(context.Background(), "copy", "", c, copier.CopySummary{TotalFiles: 10, Successful: 10}, false)
+	if len(srv.bodies) != 0 {
+		t.Fatalf("Expected no request for start and complete, got %v", srv.bodies)
+	}
+	// A cancelled batch is a failure even without failed files.
+	_ = n.Finished(context.Background(), "copy", "", c, copier.CopySummary{TotalFiles: 10, Successful: 4}, true)
+	if len(srv.bodies) != 1 || !strings.Contains(srv.bodies[0], `"cancelled":true`) {
+		t.Errorf("Expected the cancelled batch to be sent, got %v", srv.bodies)
+	}
+}
+
+func TestTemplateAndHeaders(t *testing.T) {
+	srv := newHookServer(t)
+	t.Setenv("HOOK_TOKEN", "s3cret")
+	n := New(&config.Config{Webhooks: []config.Webhook{{
+		URL:      srv.URL,
+		Headers:  map[string]string{"Authorization": "Bearer ${HOOK_TOKEN}"},
+		Template: `{"text": {{json (printf "%s: %d files to %s" .Event .Files .Destination)}}}`,
+	}}})
+	if err := n.Started(context.Background(), "watch", "", testCopier(), 7); err != nil {
+		t.Fatalf("Started failed: %v", err)
+	}
+	want := `{"text": "start: 7 files to /nas/photos"}`
+	if len(srv.bodies) != 1 || srv.bodies[0] != want {
+		t.Errorf("Expected body %s, got %v", want, srv.bodies)
+	}
+	if got := srv.headers[0].Get("Authorization"); got != "Bearer s3cret" {
+		t.Errorf("Expected the expanded header, got %q", got)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	srv := newHookServer(t)
+	srv.response = http.StatusForbidden
+	n := New(&config.Config{Webhooks: []config.Webhook{{URL: srv.URL + "/services/SECRET"}, {URL: "http://127.0.0.1:1/hook"}}})
+
+	err := n.Started(context.Background(), "copy", "", testCopier(), 1)
+	if err == nil {
+		t.Fatal("Expected errors from both webhooks")
+	}
+	if !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected the status in the error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "SECRET") || strings.Contains(err.Error(), "/hook") {
+		t.Errorf("Expected the URL paths to be left out of the error, got %v", err)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	srv := newHookServer(t)
+	n := New(&config.Config{Webhooks: []config.Webhook{{URL: srv.URL}, {URL: "http://127.0.0.1:1"}}})
+	var warnings []error
+	groupID := "photos"
+	run := n.Wrap("group", &groupID, func(context.Context, *copier.Copier, []string) copier.CopySummary {
+		return copier.CopySummary{TotalFiles: 1, Successful: 1}
+	}, func(err error) { warnings = append(warnings, err) })
+
+	summary := run(context.Background(), testCopier(), []string{"a.jpg"})
+	if summary.Successful != 1 {
+		t.Errorf("Expected the batch summary to pass through, got %+v", summary)
+	}
+	if len(srv.bodies) != 2 || !strings.Contains(srv.bodies[0], `"event":"start"`) || !strings.Contains(srv.bodies[1], `"event":"complete"`) {
+		t.Errorf("Expected a start and a complete event, got %v", srv.bodies)
+	}
+	// The unreachable webhook is reported but does not fail the batch.
+	if len(warnings) != 2 {
+		t.Errorf("Expected 2 warnings, got %v", warnings)
+	}
+
+	// Without webhooks the runner is returned as is.
+	var none *Notifier
+	called := false
+	none.Wrap("copy", nil, func(context.Context, *copier.Copier, []string) copier.CopySummary {
+		called = true
+		return copier.CopySummary{}
+	}, func(err error) { t.Error(errors.Join(err)) })(context.Background(), testCopier(), nil)
+	if !called {
+		t.Error("Expected the runner to run")
+	}
+}
@@ -0,0 +1,175 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/shell"
+)
+
+// defaultTimeout bounds a command when the processor sets no timeout.
+const defaultTimeout = 10 * time.Minute
+
+func init() {
+	Register("exec", newExec)
+}
+
+// execProcessor runs a command from the config at one stage. The file is
+// passed in COPYIMAGE_FILE (the source when inspecting, the copy when
+// checking), COPYIMAGE_SOURCE_FILE, COPYIMAGE_DEST_FILE and
+// COPYIMAGE_NAME. A transform command reads the content on stdin and
+// writes the content to store on stdout.
+type execProcessor struct {
+	command string
+	timeout time.Duration
+	env     []string
+}
+
+// execInspector, execTransformer and execChecker give the exec processor
+// only the method of its stage.
+type (
+	execInspector   struct{ *execProcessor }
+	execTransformer struct{ *execProcessor }
+	execChecker     struct{ *execProcessor }
+)
+
+func newExec(cfg config.Processor) (FileProcessor, error) {
+	if cfg.Command == "" {
+		return nil, errors.New("exec needs a command")
+	}
+	e := &execProcessor{command: cfg.Command, timeout: defaultTimeout}
+	if cfg.Timeout > 0 {
+		e.timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	switch cfg.ExecStage() {
+	case config.StageInspect:
+		return execInspector{e}, nil
+	case config.StageTransform:
+		return execTransformer{e}, nil
+	case config.StageCheck:
+		return execChecker{e}, nil
+	}
+	return nil, fmt.Errorf("unknown stage %q", cfg.Stage)
+}
+
+// Command returns a transformer piping files through command, with env
+// added to its environment, for processors converting files with
+// external tools.
+func Command(command string, env ...string) Transformer {
+	return execTransformer{&execProcessor{command: command, timeout: defaultTimeout, env: env}}
+}
+
+func (e *execProcessor) Name() string { return "exec" }
+
+func (e execInspector) Inspect(ctx context.Context, f File) error {
+	return e.run(ctx, f, f.Source)
+}
+
+func (e execChecker) Check(ctx context.Context, f File) error {
+	return e.run(ctx, f, f.Dest)
+}
+
+// run runs the command for f, rejecting f when it fails.
+func (e *execProcessor) run(ctx context.Context, f File, file string) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	cmd := e.cmd(ctx, f, file)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	return e.result(ctx, cmd.Run(), out.Bytes())
+}
+
+func (e execTransformer) Transform(ctx context.Context, f File, r io.Reader) (io.Reader, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	cmd := e.cmd(ctx, f, f.Source)
+	cmd.Stdin = r
+	out := &commandOutput{e: e.execProcessor, ctx: ctx, cmd: cmd, cancel: cancel}
+	cmd.Stderr = &out.stderr
+	stdout, err := cmd.StdoutPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	out.stdout = stdout
+	return out, nil
+}
+
+func (e *execProcessor) cmd(ctx context.Context, f File, file string) *exec.Cmd {
+	cmd := shell.Command(ctx, e.command)
+	cmd.Env = append(os.Environ(),
+		"COPYIMAGE_FILE="+file,
+		"COPYIMAGE_NAME="+f.Name,
+		"COPYIMAGE_SOURCE_FILE="+f.Source,
+		"COPYIMAGE_DEST_FILE="+f.Dest,
+	)
+	cmd.Env = append(cmd.Env, e.env...)
+	// Do not wait for children that keep the output open.
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
+// result turns how the command ended into the processor's error.
+func (e *execProcessor) result(ctx context.Context, err error, out []byte) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", e.timeout)
+	}
+	if tail := shell.Tail(out); tail != "" {
+		return fmt.Errorf("%w: %s", err, tail)
+	}
+	return err
+}
+
+// commandOutput is the stdout of a transform command. Its end reports
+// whether the command succeeded, so a failing command fails the copy
+// rather than storing truncated content.
+type commandOutput struct {
+	e      *execProcessor
+	ctx    context.Context
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	stdout io.Reader
+	stderr bytes.Buffer
+
+	once sync.Once
+	err  error
+}
+
+func (o *commandOutput) Read(p []byte) (int, error) {
+	n, err := o.stdout.Read(p)
+	if err == io.EOF {
+		if werr := o.wait(); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// Close stops the command if it is still running.
+func (o *commandOutput) Close() error {
+	o.cancel()
+	_ = o.wait()
+	return nil
+}
+
+func (o *commandOutput) wait() error {
+	o.once.Do(func() {
+		o.err = o.e.result(o.ctx, o.cmd.Wait(), o.stderr.Bytes())
+		o.cancel()
+	})
+	return o.err
+}
@@ -0,0 +1,244 @@
+// Package processor runs file processors on the files being copied: code
+// that inspects a file before it is copied and may reject it, transforms
+// its content on the way, or checks the copy once it is written. Programs
+// embedding the copier register their own processors with Register; the
+// exec processor runs a command from the config, so users can plug in a
+// virus scanner or their own checks without forking the tool.
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"copy-image/internal/config"
+)
+
+// ErrSkip is returned by an Inspector to skip a file rather than fail it.
+var ErrSkip = errors.New("skipped by a processor")
+
+// File is the file being copied.
+type File struct {
+	Name   string // base name of the source
+	Source string // path in the source storage
+	Dest   string // path in the destination storage
+
+	// Open opens the source's content, wherever it is stored.
+	Open func() (io.ReadCloser, error)
+}
+
+// FileProcessor is a processor. It does its work by implementing one or
+// more of Inspector, Transformer and Checker.
+type FileProcessor interface {
+	// Name identifies the processor in errors.
+	Name() string
+}
+
+// Inspector is implemented by processors looking at a file before it is
+// copied. An error rejects the file: it is not copied and counts as
+// failed, or as skipped when the error is ErrSkip.
+type Inspector interface {
+	Inspect(ctx context.Context, f File) error
+}
+
+// Transformer is implemented by processors changing the content written.
+// Transform returns the content to write for f, read from r. A returned
+// reader that is also an io.Closer is closed once the copy is done. An
+// error from the reader fails the attempt, which is retried like any
+// other copy error.
+type Transformer interface {
+	Transform(ctx context.Context, f File, r io.Reader) (io.Reader, error)
+}
+
+// Renamer is implemented by processors changing the name of the copy,
+// such as a conversion to another format. Rename returns the path to
+// write instead of dest. It must not depend on the file's content: it is
+// also used to find copies made earlier.
+type Renamer interface {
+	Rename(dest string) string
+}
+
+// Checker is implemented by processors looking at the copy once it is
+// written. An error rejects the file: the copy is removed and the file
+// counts as failed.
+type Checker interface {
+	Check(ctx context.Context, f File) error
+}
+
+// Factory creates a processor from its entry in the config.
+type Factory func(cfg config.Processor) (FileProcessor, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+// Register makes a processor available under name in the config's
+// processors list. It is meant to be called from init functions and
+// panics if name is registered twice.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, dup := factories[name]; dup {
+		panic("processor: Register called twice for " + name)
+	}
+	factories[name] = factory
+}
+
+// Registered returns the names of the registered processors, sorted.
+func Registered() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Chain is the processors of a config, run in order. A nil Chain runs
+// nothing.
+type Chain struct {
+	steps []step
+}
+
+type step struct {
+	p   FileProcessor
+	cfg config.Processor
+}
+
+// New creates the processors configured in cfgs. It returns nil when
+// there are none.
+func New(cfgs []config.Processor) (*Chain, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	c := &Chain{}
+	for i, cfg := range cfgs {
+		mu.RLock()
+		factory := factories[cfg.Name]
+		mu.RUnlock()
+		if factory == nil {
+			return nil, fmt.Errorf("processors[%d]: unknown processor %q (known: %v)", i, cfg.Name, Registered())
+		}
+		p, err := factory(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("processors[%d]: %w", i, err)
+		}
+		c.Add(p, cfg)
+	}
+	return c, nil
+}
+
+// Add appends p, run on the files cfg applies to.
+func (c *Chain) Add(p FileProcessor, cfg config.Processor) {
+	c.steps = append(c.steps, step{p: p, cfg: cfg})
+}
+
+// Append appends the processors of other, which may be nil.
+func (c *Chain) Append(other *Chain) {
+	if other != nil {
+		c.steps = append(c.steps, other.steps...)
+	}
+}
+
+// Empty reports whether c runs nothing.
+func (c *Chain) Empty() bool {
+	return c == nil || len(c.steps) == 0
+}
+
+// each calls fn for the processors applying to f, stopping at the first
+// error.
+func (c *Chain) each(f File, fn func(p FileProcessor) error) error {
+	if c == nil {
+		return nil
+	}
+	for _, s := range c.steps {
+		if !s.cfg.Applies(f.Name) {
+			continue
+		}
+		if err := fn(s.p); err != nil {
+			if errors.Is(err, ErrSkip) {
+				return err
+			}
+			return fmt.Errorf("processor %s: %w", s.p.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Inspect runs the inspectors on f before it is copied.
+func (c *Chain) Inspect(ctx context.Context, f File) error {
+	return c.each(f, func(p FileProcessor) error {
+		if i, ok := p.(Inspector); ok {
+			return i.Inspect(ctx, f)
+		}
+		return nil
+	})
+}
+
+// Transform passes r, the content of f, through the transformers. The
+// returned reader must be closed; closing it does not close r.
+func (c *Chain) Transform(ctx context.Context, f File, r io.Reader) (io.ReadCloser, error) {
+	out := &transformed{Reader: r}
+	err := c.each(f, func(p FileProcessor) error {
+		t, ok := p.(Transformer)
+		if !ok {
+			return nil
+		}
+		next, err := t.Transform(ctx, f, out.Reader)
+		if err != nil {
+			return err
+		}
+		if closer, ok := next.(io.Closer); ok {
+			out.closers = append(out.closers, closer)
+		}
+		out.Reader = next
+		return nil
+	})
+	if err != nil {
+		_ = out.Close()
+		return nil, err
+	}
+	return out, nil
+}
+
+// Rename returns where the file named name, bound for dest, is written.
+func (c *Chain) Rename(name, dest string) string {
+	_ = c.each(File{Name: name}, func(p FileProcessor) error {
+		if r, ok := p.(Renamer); ok {
+			dest = r.Rename(dest)
+		}
+		return nil
+	})
+	return dest
+}
+
+// Check runs the checkers on f once it was copied.
+func (c *Chain) Check(ctx context.Context, f File) error {
+	return c.each(f, func(p FileProcessor) error {
+		if ch, ok := p.(Checker); ok {
+			return ch.Check(ctx, f)
+		}
+		return nil
+	})
+}
+
+// transformed is the output of the last transformer, closing all of them.
+type transformed struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close closes the transformers' readers, last first.
+func (t *transformed) Close() error {
+	var errs []error
+	for i := len(t.closers) - 1; i >= 0; i-- {
+		errs = append(errs, t.closers[i].Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"copy-image/internal/config"
+)
+
+func skipOnWindows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test commands use sh")
+	}
+}
+
+func TestNewUnknown(t *testing.T) {
+	_, err := New([]config.Processor{{Name: "exec", Command: "true"}, {Name: "virus-scan"}})
+	if err == nil || !strings.Contains(err.Error(), "processors[1]") {
+		t.Errorf("Expected the unknown processor to be reported, got %v", err)
+	}
+	if c, err := New(nil); c != nil || err != nil {
+		t.Errorf("Expected no chain without processors, got %v %v", c, err)
+	}
+}
+
+// unregister removes name from the registry once the test is done, so the
+// test can run again in the same process.
+func unregister(t *testing.T, name string) {
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(factories, name)
+	})
+}
+
+func TestRegister(t *testing.T) {
+	unregister(t, "test-register")
+	Register("test-register", func(config.Processor) (FileProcessor, error) { return nil, errors.New("bad options") })
+	if names := Registered(); !strings.Contains(strings.Join(names, " "), "exec test-register") {
+		t.Errorf("Unexpected registered processors: %v", names)
+	}
+	if _, err := New([]config.Processor{{Name: "test-register"}}); err == nil || !strings.Contains(err.Error(), "bad options") {
+		t.Errorf("Expected the factory's error, got %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a name twice to panic")
+		}
+	}()
+	Register("test-register", nil)
+}
+
+func TestExecInspect(t *testing.T) {
+	skipOnWindows(t)
+	tests := []struct {
+		command string
+		reject  string // part of the expected error; empty to accept
+	}{
+		{`test "$COPYIMAGE_FILE" = /src/a.jpg && test "$COPYIMAGE_DEST_FILE" = /dst/a.jpg`, ""},
+		{`echo "$COPYIMAGE_NAME: Eicar-Test-Signature FOUND"; exit 1`, "a.jpg: Eicar-Test-Signature FOUND"},
+	}
+	for _, tt := range tests {
+		c, err := New([]config.Processor{{Name: "exec", Command: tt.command}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = c.Inspect(context.Background(), File{Name: "a.jpg", Source: "/src/a.jpg", Dest: "/dst/a.jpg"})
+		if tt.reject == "" && err != nil {
+			t.Errorf("%s: expected the file to be accepted, got %v", tt.command, err)
+		}
+		if tt.reject != "" && (err == nil || !strings.Contains(err.Error(), "processor exec: exit status 1: "+tt.reject)) {
+			t.Errorf("%s: expected the file to be rejected, got %v", tt.command, err)
+		}
+		// Inspectors do not check copies.
+		if err := c.Check(context.Background(), File{Name: "a.jpg"}); err != nil {
+			t.Errorf("Expected no checker, got %v", err)
+		}
+	}
+}
+
+func TestExecCheck(t *testing.T) {
+	skipOnWindows(t)
+	dst := filepath.Join(t.TempDir(), "a.jpg")
+	if err := os.WriteFile(dst, []byte("copy"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := New([]config.Processor{{Name: "exec", Stage: config.StageCheck, Command: `grep -q copy "$COPYIMAGE_FILE"`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Check(context.Background(), File{Name: "a.jpg", Source: "/src/a.jpg", Dest: dst}); err != nil {
+		t.Errorf("Expected the copy to pass, got %v", err)
+	}
+	if err := c.Check(context.Background(), File{Name: "b.jpg", Dest: dst + ".missing"}); err == nil {
+		t.Error("Expected a missing copy to fail")
+	}
+}
+
+func TestExecTransform(t *testing.T) {
+	skipOnWindows(t)
+	tests := []struct {
+		command string
+		want    string
+		fails   bool
+	}{
+		{"tr a-z A-Z", "HELLO", false},
+		{"cat; exit 3", "", true},
+		{"sleep 5", "", true},
+	}
+	for _, tt := range tests {
+		c, err := New([]config.Processor{{Name: "exec", Stage: config.StageTransform, Command: tt.command, Timeout: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := c.Transform(context.Background(), File{Name: "a.txt"}, strings.NewReader("hello"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		_ = r.Close()
+		if tt.fails {
+			if err == nil {
+				t.Errorf("%s: expected the transform to fail", tt.command)
+			}
+			continue
+		}
+		if err != nil || string(data) != tt.want {
+			t.Errorf("%s: expected %q, got %q (%v)", tt.command, tt.want, data, err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Package queue runs copy jobs lined up in the desktop app, such as
+// several card imports, in order and a limited number at a time.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+// Status is where a job is in the queue.
+type Status string
+
+const (
+	Queued    Status = "queued"
+	Running   Status = "running"
+	Done      Status = "done"
+	Failed    Status = "failed"
+	Cancelled Status = "cancelled"
+)
+
+// finished reports whether a job with status s is over.
+func (s Status) finished() bool {
+	return s == Done || s == Failed || s == Cancelled
+}
+
+// Job is a job in the queue.
+type Job struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Status   Status     `json:"status"`
+	Added    time.Time  `json:"added"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	// Result is what the job returned, such as the copy result shown by
+	// the frontend; Error why it failed.
+	Result any    `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RunFunc runs a job. It returns the job's result and, if it failed, an
+// error. ctx is cancelled by Cancel.
+type RunFunc func(ctx context.Context) (any, error)
+
+// ErrNotFound is matched by the error for an unknown job ID.
+var ErrNotFound = errors.New("job not found")
+
+// entry is a job and what runs it.
+type entry struct {
+	Job
+	run    RunFunc
+	cancel context.CancelFunc
+}
+
+// Queue runs jobs in the order they were added (or reordered), at most
+// workers at a time. Finished jobs stay listed until Clear.
+type Queue struct {
+	ctx      context.Context
+	workers  int
+	onChange func([]Job)
+
+	mu      sync.Mutex
+	entries []*entry
+	running int
+}
+
+// New returns a queue running jobs under ctx, at most workers at a time
+// (at least one). onChange, if not nil, is called with the jobs whenever
+// one is added, starts, finishes, is cancelled or moved; it must not call
+// back into the queue.
+func New(ctx context.Context, workers int, onChange func([]Job)) *Queue {
+	return &Queue{ctx: ctx, workers: max(workers, 1), onChange: onChange}
+}
+
+// Add queues a job named name that run runs, and returns it.
+func (q *Queue) Add(name string, run RunFunc) Job {
+	q.mu.Lock()
+	e := &entry{Job: Job{ID: config.NewID(), Name: name, Status: Queued, Added: time.Now()}, run: run}
+	q.entries = append(q.entries, e)
+	job := e.Job
+	q.next()
+	q.changed()
+	return job
+}
+
+// List returns the jobs: finished, running and queued, in queue order.
+func (q *Queue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.list()
+}
+
+// Cancel removes a queued job from the line, or stops a running one. A
+// finished job is left as it is.
+func (q *Queue) Cancel(id string) error {
+	q.mu.Lock()
+	e := q.find(id)
+	if e == nil {
+		q.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	switch e.Status {
+	case Queued:
+		e.Status = Cancelled
+		now := time.Now()
+		e.Finished = &now
+	case Running:
+		// The job is marked cancelled once its run returns.
+		e.cancel()
+	}
+	q.changed()
+	return nil
+}
+
+// Reorder moves the queued jobs with the given IDs to the front of the
+// line, in that order. Other queued jobs keep their order after them.
+func (q *Queue) Reorder(ids []string) error {
+	q.mu.Lock()
+	moved := make([]*entry, 0, len(ids))
+	for _, id := range ids {
+		e := q.find(id)
+		if e == nil {
+			q.mu.Unlock()
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		if e.Status != Queued {
+			q.mu.Unlock()
+			return fmt.Errorf("job %s is %s, only queued jobs can be moved", id, e.Status)
+		}
+		if !slices.Contains(moved, e) {
+			moved = append(moved, e)
+		}
+	}
+
+	// Jobs that started or finished keep their place before the line.
+	var order []*entry
+	for _, e := range q.entries {
+		if e.Status != Queued {
+			order = append(order, e)
+		}
+	}
+	order = append(order, moved...)
+	for _, e := range q.entries {
+		if e.Status == Queued && !slices.Contains(moved, e) {
+			order = append(order, e)
+		}
+	}
+	q.entries = order
+	q.changed()
+	return nil
+}
+
+// Clear removes the finished jobs from the list.
+func (q *Queue) Clear() {
+	q.mu.Lock()
+	q.entries = slices.DeleteFunc(q.entries, func(e *entry) bool { return e.Status.finished() })
+	q.changed()
+}
+
+// next starts queued jobs while fewer than workers run. q.mu is held.
+func (q *Queue) next() {
+	for _, e := range q.entries {
+		if q.running >= q.workers {
+			return
+		}
+		if e.Status != Queued {
+			continue
+		}
+		ctx, cancel := context.WithCancel(q.ctx)
+		now := time.Now()
+		e.Status, e.Started, e.cancel = Running, &now, cancel
+		q.running++
+		go q.run(ctx, e)
+	}
+}
+
+// run runs the job of e and starts the next one.
+func (q *Queue) run(ctx context.Context, e *entry) {
+	result, err := e.run(ctx)
+	cancelled := ctx.Err() != nil
+	e.cancel()
+
+	q.mu.Lock()
+	now := time.Now()
+	e.Finished, e.Result = &now, result
+	switch {
+	case cancelled:
+		e.Status = Cancelled
+	case err != nil:
+		e.Status, e.Error = Failed, err.Error()
+	default:
+		e.Status = Done
+	}
+	q.running--
+	q.next()
+	q.changed()
+}
+
+// changed calls onChange with the jobs, then unlocks q.mu. The lock is
+// held meanwhile so listeners see the changes in order.
+func (q *Queue) changed() {
+	defer q.mu.Unlock()
+	if q.onChange != nil {
+		q.onChange(q.list())
+	}
+}
+
+func (q *Queue) list() []Job {
+	jobs := make([]Job, len(q.entries))
+	for i, e := range q.entries {
+		jobs[i] = e.Job
+	}
+	return jobs
+}
+
+func (q *Queue) find(id string) *entry {
+	for _, e := range q.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
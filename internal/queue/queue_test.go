@@ -0,0 +1,123 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor polls until cond holds, failing the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func statuses(q *Queue) string {
+	var s []string
+	for _, j := range q.List() {
+		s = append(s, j.Name+":"+string(j.Status))
+	}
+	return strings.Join(s, ",")
+}
+
+func TestQueueRunsInOrder(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		ran     []string
+		changes int
+	)
+	release := make(chan struct{})
+	q := New(context.Background(), 1, func([]Job) { changes++ })
+	job := func(name string, err error) RunFunc {
+		return func(ctx context.Context) (any, error) {
+			<-release
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+			return name + " result", err
+		}
+	}
+
+	q.Add("a", job("a", nil))
+	b := q.Add("b", job("b", errors.New("disk full")))
+	c := q.Add("c", job("c", nil))
+	if got := statuses(q); got != "a:running,b:queued,c:queued" {
+		t.Fatalf("Expected one job running, got %s", got)
+	}
+	if err := q.Reorder([]string{c.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Reorder([]string{q.List()[0].ID}); err == nil {
+		t.Error("Expected an error when moving a running job")
+	}
+	if err := q.Reorder([]string{"missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	close(release)
+
+	waitFor(t, func() bool { return statuses(q) == "a:done,c:done,b:failed" })
+	if strings.Join(ran, ",") != "a,c,b" {
+		t.Errorf("Expected a, c then b, got %v", ran)
+	}
+	for _, j := range q.List() {
+		if j.ID == b.ID && (j.Error != "disk full" || j.Result != "b result" || j.Finished == nil) {
+			t.Errorf("Unexpected failed job: %+v", j)
+		}
+	}
+	if changes != 7 { // 3 added, 1 moved, 3 finished
+		t.Errorf("Expected 7 changes, got %d", changes)
+	}
+
+	q.Clear()
+	if len(q.List()) != 0 {
+		t.Errorf("Expected Clear to remove finished jobs, got %s", statuses(q))
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	q := New(context.Background(), 1, nil)
+	running := q.Add("running", func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	queued := q.Add("queued", func(ctx context.Context) (any, error) {
+		t.Error("A cancelled job must not run")
+		return nil, nil
+	})
+
+	if err := q.Cancel(queued.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Cancel(running.ID); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return statuses(q) == "running:cancelled,queued:cancelled" })
+	if err := q.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQueueWorkers(t *testing.T) {
+	release := make(chan struct{})
+	q := New(context.Background(), 2, nil)
+	for _, name := range []string{"a", "b", "c"} {
+		q.Add(name, func(ctx context.Context) (any, error) {
+			<-release
+			return nil, nil
+		})
+	}
+	if got := statuses(q); got != "a:running,b:running,c:queued" {
+		t.Errorf("Expected two jobs running, got %s", got)
+	}
+	close(release)
+	waitFor(t, func() bool { return statuses(q) == "a:done,b:done,c:done" })
+}
@@ -0,0 +1,149 @@
+// Package recent remembers the folders used as sources and destinations,
+// and the folders the user pinned, so the desktop app's folder pickers can
+// offer them with one click.
+package recent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FileName is the name of the file, next to the config file, holding the
+// folders.
+const FileName = "folders.json"
+
+// Max is how many recent folders of each kind are kept.
+const Max = 10
+
+// Kinds of recent folders.
+const (
+	Source      = "source"
+	Destination = "destination"
+)
+
+// Folders are the recent and pinned folders, most recent first.
+type Folders struct {
+	Sources      []string `json:"sources"`
+	Destinations []string `json:"destinations"`
+	Favorites    []string `json:"favorites"`
+}
+
+// Store keeps Folders in a file. Its methods may be called concurrently.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns a store keeping the folders in the file at path, which
+// is created by the first change.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Get returns the folders, empty if none were recorded yet.
+func (s *Store) Get() (Folders, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// Use records path as the most recent folder of kind (Source or
+// Destination).
+func (s *Store) Use(kind, path string) error {
+	if path == "" {
+		return nil
+	}
+	return s.update(func(f *Folders) error {
+		var list *[]string
+		switch kind {
+		case Source:
+			list = &f.Sources
+		case Destination:
+			list = &f.Destinations
+		default:
+			return fmt.Errorf("unknown folder kind %q", kind)
+		}
+		*list = append([]string{path}, remove(*list, path)...)
+		if len(*list) > Max {
+			*list = (*list)[:Max]
+		}
+		return nil
+	})
+}
+
+// Pin adds path to the favorites, after the ones already pinned.
+func (s *Store) Pin(path string) error {
+	if path == "" {
+		return errors.New("folder path is required")
+	}
+	return s.update(func(f *Folders) error {
+		f.Favorites = append(remove(f.Favorites, path), path)
+		return nil
+	})
+}
+
+// Unpin removes path from the favorites.
+func (s *Store) Unpin(path string) error {
+	return s.update(func(f *Folders) error {
+		f.Favorites = remove(f.Favorites, path)
+		return nil
+	})
+}
+
+// update loads the folders, changes them with fn and saves them.
+func (s *Store) update(fn func(*Folders) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(&f); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+func (s *Store) load() (Folders, error) {
+	f := Folders{Sources: []string{}, Destinations: []string{}, Favorites: []string{}}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return f, err
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return f, nil
+}
+
+// remove returns list without path. On Windows paths are compared
+// ignoring case, so D:\Photos and d:\photos\ are the same folder.
+func remove(list []string, path string) []string {
+	return slices.DeleteFunc(list, func(p string) bool { return samePath(p, path) })
+}
+
+func samePath(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
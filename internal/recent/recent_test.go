@@ -0,0 +1,61 @@
+package recent
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", FileName)
+	s := NewStore(path)
+
+	f, err := s.Get()
+	if err != nil || len(f.Sources) != 0 || f.Favorites == nil {
+		t.Fatalf("Expected no folders yet, got %+v, %v", f, err)
+	}
+
+	for _, dir := range []string{"/card", "/dump", "/card/"} {
+		if err := s.Use(Source, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Use(Destination, "/nas"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Use("archive", "/x"); err == nil {
+		t.Error("Expected an error for an unknown kind")
+	}
+	_ = s.Pin("/nas")
+	_ = s.Pin("/usb")
+	_ = s.Pin("/nas")
+	_ = s.Unpin("/usb")
+
+	f, err = NewStore(path).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(f.Sources, ","); got != "/card/,/dump" {
+		t.Errorf("Expected the card first and once, got %s", got)
+	}
+	if got := strings.Join(f.Destinations, ","); got != "/nas" {
+		t.Errorf("Expected /nas, got %s", got)
+	}
+	if got := strings.Join(f.Favorites, ","); got != "/nas" {
+		t.Errorf("Expected only /nas pinned, got %s", got)
+	}
+}
+
+func TestStoreKeepsMax(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), FileName))
+	for i := range Max + 5 {
+		if err := s.Use(Destination, fmt.Sprintf("/out/%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, _ := s.Get()
+	if len(f.Destinations) != Max || f.Destinations[0] != fmt.Sprintf("/out/%d", Max+4) {
+		t.Errorf("Expected the %d most recent folders, got %v", Max, f.Destinations)
+	}
+}
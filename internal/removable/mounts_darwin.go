@@ -0,0 +1,22 @@
+package removable
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// mounts returns the volumes in /Volumes. The startup disk is a symlink
+// to / there and is left out.
+func mounts() ([]Drive, error) {
+	entries, err := os.ReadDir("/Volumes")
+	if err != nil {
+		return nil, err
+	}
+	var drives []Drive
+	for _, e := range entries {
+		if e.IsDir() {
+			drives = append(drives, Drive{Path: filepath.Join("/Volumes", e.Name()), Label: e.Name()})
+		}
+	}
+	return drives, nil
+}
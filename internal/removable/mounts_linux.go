@@ -0,0 +1,58 @@
+package removable
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// mounts returns the drives the desktop mounted, which udisks puts under
+// /media or /run/media.
+func mounts() ([]Drive, error) {
+	f, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return parseMounts(f)
+}
+
+// parseMounts reads a mount table in the fstab format of /proc/mounts.
+func parseMounts(r io.Reader) ([]Drive, error) {
+	var drives []Drive
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		dir := unescapeMount(fields[1])
+		if strings.HasPrefix(dir, "/media/") || strings.HasPrefix(dir, "/run/media/") {
+			drives = append(drives, Drive{Path: dir, Label: filepath.Base(dir)})
+		}
+	}
+	return drives, scanner.Err()
+}
+
+// unescapeMount decodes the octal escapes (\040 for a space) the kernel
+// writes for blanks and backslashes in mount points.
+func unescapeMount(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,23 @@
+package removable
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMounts(t *testing.T) {
+	table := `/dev/nvme0n1p2 / ext4 rw,relatime 0 0
+proc /proc proc rw,nosuid 0 0
+/dev/sdb1 /media/me/EOS_DIGITAL vfat rw,nosuid,nodev 0 0
+/dev/sdc1 /run/media/me/My\040Card exfat rw,nosuid,nodev 0 0
+`
+	drives, err := parseMounts(strings.NewReader(table))
+	want := []Drive{
+		{Path: "/media/me/EOS_DIGITAL", Label: "EOS_DIGITAL"},
+		{Path: "/run/media/me/My Card", Label: "My Card"},
+	}
+	if err != nil || !reflect.DeepEqual(drives, want) {
+		t.Errorf("parseMounts() = %v, %v, want %v", drives, err, want)
+	}
+}
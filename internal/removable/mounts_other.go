@@ -0,0 +1,12 @@
+//go:build !windows && !linux && !darwin
+
+package removable
+
+import (
+	"errors"
+	"runtime"
+)
+
+func mounts() ([]Drive, error) {
+	return nil, errors.New("detecting drives is not supported on " + runtime.GOOS)
+}
@@ -0,0 +1,58 @@
+//go:build !windows
+
+package removable
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// pollInterval is how often the mounted drives are compared. udev
+// announces a card before the desktop mounts it, so the mount table is
+// what tells when its files can be read.
+const pollInterval = 2 * time.Second
+
+// Watch calls onInsert for each removable drive mounted after it starts,
+// until ctx is cancelled. It returns once the drives present are known.
+func Watch(ctx context.Context, onInsert func(Drive)) error {
+	known, err := mounts()
+	if err != nil {
+		return fmt.Errorf("failed to list drives: %w", err)
+	}
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current, err := mounts()
+			if err != nil {
+				continue
+			}
+			for _, d := range added(known, current) {
+				onInsert(d)
+			}
+			known = current
+		}
+	}()
+	return nil
+}
+
+// added returns the drives of current that are not in known.
+func added(known, current []Drive) []Drive {
+	seen := make(map[string]bool, len(known))
+	for _, d := range known {
+		seen[d.Path] = true
+	}
+	var drives []Drive
+	for _, d := range current {
+		if !seen[d.Path] {
+			drives = append(drives, d)
+		}
+	}
+	return drives
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package removable
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAdded(t *testing.T) {
+	known := []Drive{{Path: "/media/me/BACKUP"}, {Path: "/media/me/EOS_DIGITAL"}}
+	current := []Drive{{Path: "/media/me/BACKUP"}, {Path: "/media/me/NIKON"}}
+	want := []Drive{{Path: "/media/me/NIKON"}}
+	if got := added(known, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("added() = %v, want %v", got, want)
+	}
+}
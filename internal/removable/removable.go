@@ -0,0 +1,25 @@
+// Package removable notices memory cards and USB drives as they are
+// inserted, so the app can offer to import them.
+package removable
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Drive is a mounted removable drive.
+type Drive struct {
+	// Path is where its files are: a drive root such as E:\ on Windows,
+	// the mount point elsewhere.
+	Path string `json:"path"`
+	// Label is the volume label; on Linux and macOS, the name of the
+	// mount point, which the desktop derives from the label.
+	Label string `json:"label"`
+}
+
+// HasDCIM reports whether the drive has a DCIM folder at its root, as
+// every camera card does.
+func (d Drive) HasDCIM() bool {
+	info, err := os.Stat(filepath.Join(d.Path, "DCIM"))
+	return err == nil && info.IsDir()
+}
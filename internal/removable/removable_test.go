@@ -0,0 +1,20 @@
+package removable
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHasDCIM(t *testing.T) {
+	card := Drive{Path: t.TempDir()}
+	if card.HasDCIM() {
+		t.Error("An empty drive has no DCIM folder")
+	}
+	if err := os.Mkdir(filepath.Join(card.Path, "DCIM"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !card.HasDCIM() {
+		t.Error("Expected the DCIM folder to be found")
+	}
+}
@@ -0,0 +1,171 @@
+package removable
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32               = windows.NewLazySystemDLL("user32.dll")
+	procRegisterClassExW = user32.NewProc("RegisterClassExW")
+	procCreateWindowExW  = user32.NewProc("CreateWindowExW")
+	procDefWindowProcW   = user32.NewProc("DefWindowProcW")
+	procGetMessageW      = user32.NewProc("GetMessageW")
+	procDispatchMessageW = user32.NewProc("DispatchMessageW")
+	procPostMessageW     = user32.NewProc("PostMessageW")
+	procPostQuitMessage  = user32.NewProc("PostQuitMessage")
+)
+
+// Messages and structures of WM_DEVICECHANGE (winuser.h, dbt.h).
+const (
+	wmDestroy        = 0x0002
+	wmClose          = 0x0010
+	wmDeviceChange   = 0x0219
+	dbtDeviceArrival = 0x8000
+	dbtDevtypVolume  = 0x2
+	dbtfNet          = 0x2
+)
+
+// devBroadcastVolume is DEV_BROADCAST_VOLUME.
+type devBroadcastVolume struct {
+	size       uint32
+	deviceType uint32
+	reserved   uint32
+	unitMask   uint32
+	flags      uint16
+}
+
+// wndClassEx is WNDCLASSEXW.
+type wndClassEx struct {
+	size       uint32
+	style      uint32
+	wndProc    uintptr
+	clsExtra   int32
+	wndExtra   int32
+	instance   windows.Handle
+	icon       windows.Handle
+	cursor     windows.Handle
+	background windows.Handle
+	menuName   *uint16
+	className  *uint16
+	iconSm     windows.Handle
+}
+
+// msg is MSG.
+type msg struct {
+	hwnd    uintptr
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	pt      struct{ x, y int32 }
+	private uint32
+}
+
+var (
+	className = windows.StringToUTF16Ptr("copy-image-drives")
+	wndProc   = windows.NewCallback(windowProc)
+	register  = sync.OnceValue(func() error {
+		wc := wndClassEx{wndProc: wndProc, className: className}
+		wc.size = uint32(unsafe.Sizeof(wc))
+		if r, _, err := procRegisterClassExW.Call(uintptr(unsafe.Pointer(&wc))); r == 0 {
+			return fmt.Errorf("failed to register window class: %w", err)
+		}
+		return nil
+	})
+
+	// handlers are the onInsert of each watching window.
+	handlers sync.Map // window handle → func(Drive)
+)
+
+// Watch calls onInsert for each drive mounted after it starts, until ctx
+// is cancelled. It returns once watching is set up.
+//
+// Windows broadcasts WM_DEVICECHANGE when a volume arrives, including a
+// card put into a built-in reader, but only to top-level windows, so a
+// hidden one is created for it on a thread of its own.
+func Watch(ctx context.Context, onInsert func(Drive)) error {
+	if err := register(); err != nil {
+		return err
+	}
+	ready := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		hwnd, _, err := procCreateWindowExW.Call(0, uintptr(unsafe.Pointer(className)), 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+		if hwnd == 0 {
+			ready <- fmt.Errorf("failed to watch drives: %w", err)
+			return
+		}
+		handlers.Store(hwnd, onInsert)
+		defer handlers.Delete(hwnd)
+		ready <- nil
+
+		// Closing the window ends the loop below through WM_DESTROY.
+		stop := context.AfterFunc(ctx, func() {
+			_, _, _ = procPostMessageW.Call(hwnd, wmClose, 0, 0)
+		})
+		defer stop()
+		var m msg
+		for {
+			if r, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0); int32(r) <= 0 {
+				return
+			}
+			_, _, _ = procDispatchMessageW.Call(uintptr(unsafe.Pointer(&m)))
+		}
+	}()
+	return <-ready
+}
+
+func windowProc(hwnd, message, wParam, lParam uintptr) uintptr {
+	switch {
+	case message == wmDestroy:
+		_, _, _ = procPostQuitMessage.Call(0)
+		return 0
+	case message == wmDeviceChange && wParam == dbtDeviceArrival && lParam != 0:
+		vol := *(**devBroadcastVolume)(unsafe.Pointer(&lParam))
+		if vol.deviceType != dbtDevtypVolume || vol.flags&dbtfNet != 0 {
+			break
+		}
+		if fn, ok := handlers.Load(hwnd); ok {
+			onInsert := fn.(func(Drive))
+			for _, d := range volumes(vol.unitMask) {
+				// Matching reads the drive; keep the message loop free.
+				go onInsert(d)
+			}
+		}
+	}
+	r, _, _ := procDefWindowProcW.Call(hwnd, message, wParam, lParam)
+	return r
+}
+
+// volumes returns the drives of the letters set in a unit mask.
+func volumes(mask uint32) []Drive {
+	var drives []Drive
+	for i := 0; i < 26; i++ {
+		if mask&(1<<i) == 0 {
+			continue
+		}
+		root := string(rune('A'+i)) + `:\`
+		drives = append(drives, Drive{Path: root, Label: volumeLabel(root)})
+	}
+	return drives
+}
+
+func volumeLabel(root string) string {
+	p, err := windows.UTF16PtrFromString(root)
+	if err != nil {
+		return ""
+	}
+	buf := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumeInformation(p, &buf[0], uint32(len(buf)), nil, nil, nil, nil, 0); err != nil {
+		return ""
+	}
+	return windows.UTF16ToString(buf)
+}
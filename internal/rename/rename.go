@@ -0,0 +1,425 @@
+package rename
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// UndoLogPrefix is the file name prefix of undo logs written by Apply.
+// Files with this prefix are never renamed themselves.
+const UndoLogPrefix = ".copyimage-rename-"
+
+// FileInfo holds the attributes of a file that templates can refer to.
+type FileInfo struct {
+	Path    string
+	ModTime time.Time
+}
+
+// Template is a parsed rename template.
+//
+// Supported tokens:
+//
+//	{name}          original file name without extension
+//	{ext}           original extension without the dot
+//	{parent}        name of the containing directory
+//	{seq} {seq:N}   1-based sequence number, optionally zero-padded to N digits
+//	{date} {date:F} file modification date; F uses YYYY, MM, DD, hh, mm, ss
+//
+// The original extension is always kept, so a template only describes the
+// base name of the file.
+type Template struct {
+	raw   string
+	parts []part
+}
+
+// part is either a literal string or a token with an optional argument.
+type part struct {
+	literal string
+	token   string
+	arg     string
+}
+
+// Parse parses a rename template and reports unknown or unterminated tokens.
+func Parse(tmpl string) (*Template, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		return nil, fmt.Errorf("template is empty")
+	}
+
+	t := &Template{raw: tmpl}
+	rest := tmpl
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t.parts = append(t.parts, part{literal: rest})
+			break
+		}
+		if open > 0 {
+			t.parts = append(t.parts, part{literal: rest[:open]})
+		}
+
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated token in template %q", tmpl)
+		}
+		body := rest[open+1 : open+end]
+		rest = rest[open+end+1:]
+
+		token, arg, _ := strings.Cut(body, ":")
+		switch token {
+		case "name", "ext", "parent", "date":
+		case "seq":
+			if arg != "" {
+				if n, err := strconv.Atoi(arg); err != nil || n < 1 || n > 12 {
+					return nil, fmt.Errorf("invalid sequence width %q", arg)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown token {%s} in template", token)
+		}
+		t.parts = append(t.parts, part{token: token, arg: arg})
+	}
+
+	return t, nil
+}
+
+// String returns the original template text.
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Render produces the new file name for a file at the given 1-based sequence position.
+func (t *Template) Render(info FileInfo, seq int) string {
+	base := filepath.Base(info.Path)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+
+	var b strings.Builder
+	for _, p := range t.parts {
+		switch p.token {
+		case "":
+			b.WriteString(p.literal)
+		case "name":
+			b.WriteString(name)
+		case "ext":
+			b.WriteString(strings.TrimPrefix(ext, "."))
+		case "parent":
+			b.WriteString(filepath.Base(filepath.Dir(info.Path)))
+		case "seq":
+			width := 0
+			if p.arg != "" {
+				width, _ = strconv.Atoi(p.arg)
+			}
+			fmt.Fprintf(&b, "%0*d", width, seq)
+		case "date":
+			layout := p.arg
+			if layout == "" {
+				layout = "YYYYMMDD"
+			}
+			b.WriteString(formatDate(info.ModTime, layout))
+		}
+	}
+
+	return b.String() + ext
+}
+
+// formatDate formats t using a human-friendly layout (YYYY-MM-DD style)
+// instead of Go's reference time, which users find confusing in templates.
+func formatDate(t time.Time, layout string) string {
+	r := strings.NewReplacer(
+		"YYYY", "2006",
+		"MM", "01",
+		"DD", "02",
+		"hh", "15",
+		"mm", "04",
+		"ss", "05",
+	)
+	return t.Format(r.Replace(layout))
+}
+
+// Op is a single planned rename inside a directory.
+type Op struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Temp is the name the file has between the two phases of Apply,
+	// recorded in the undo log so an interrupted batch can be undone.
+	Temp string `json:"temp,omitempty"`
+}
+
+// Plan computes the renames needed to apply tmpl to every regular file in dir.
+// Files are processed in name order so sequence numbers are reproducible.
+// Files whose name would not change are left out of the plan. An error is
+// returned if two files would end up with the same name or a target name is
+// already used by a file or folder that is not being renamed. Names that
+// differ only in case are the same name where the file system ignores case.
+func Plan(dir string, tmpl *Template, filter func(name string) bool) ([]Op, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	all := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		all = append(all, entry.Name())
+	}
+	key := func(name string) string { return name }
+	if ignoresCase(dir, all) {
+		key = strings.ToLower
+	}
+
+	var names []string
+	existing := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		existing[key(name)] = true
+		if entry.IsDir() {
+			continue
+		}
+		if strings.HasPrefix(name, UndoLogPrefix) {
+			continue
+		}
+		if filter != nil && !filter(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ops []Op
+	targets := make(map[string]string)
+	renamed := make(map[string]bool)
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", name, err)
+		}
+
+		newName := tmpl.Render(FileInfo{Path: path, ModTime: info.ModTime()}, i+1)
+		if strings.ContainsAny(newName, `/\`) {
+			return nil, fmt.Errorf("template produced a path instead of a file name: %q", newName)
+		}
+		if other, ok := targets[key(newName)]; ok {
+			return nil, fmt.Errorf("%s and %s would both be renamed to %s", other, name, newName)
+		}
+		targets[key(newName)] = name
+
+		if newName == name {
+			continue
+		}
+		renamed[key(name)] = true
+		ops = append(ops, Op{From: name, To: newName})
+	}
+
+	// A target that already exists is only acceptable when that file is
+	// itself being renamed away (or is the very same file).
+	for _, op := range ops {
+		if existing[key(op.To)] && !renamed[key(op.To)] {
+			return nil, fmt.Errorf("cannot rename %s to %s: file already exists", op.From, op.To)
+		}
+	}
+
+	return ops, nil
+}
+
+// ignoresCase reports whether dir is on a file system that ignores the
+// case of names, like NTFS and, by default, APFS, by looking one of names
+// up with its case swapped. Without a name to try, it goes by the OS.
+func ignoresCase(dir string, names []string) bool {
+	for _, name := range names {
+		swapped := swapCase(name)
+		if swapped == name {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		other, err := os.Stat(filepath.Join(dir, swapped))
+		return err == nil && os.SameFile(info, other)
+	}
+	return runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+}
+
+// swapCase returns s with its upper and lower case letters swapped.
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+// UndoLog records an applied rename batch so it can be reverted later.
+type UndoLog struct {
+	Dir       string    `json:"dir"`
+	Template  string    `json:"template"`
+	AppliedAt time.Time `json:"appliedAt"`
+	Ops       []Op      `json:"ops"`
+}
+
+// Apply executes the planned renames in dir and writes an undo log next to
+// the files. It returns the path of the undo log.
+//
+// Renames go through temporary names first so chains and swaps
+// (a→b, b→a) cannot clobber each other. If a rename fails, the files are
+// put back under their original names and no undo log is kept; if even
+// that fails, the undo log is returned with the error, and Undo finishes
+// putting them back.
+func Apply(dir string, tmpl *Template, ops []Op) (string, error) {
+	if len(ops) == 0 {
+		return "", nil
+	}
+
+	appliedAt := time.Now()
+	log := UndoLog{
+		Dir:       dir,
+		Template:  tmpl.String(),
+		AppliedAt: appliedAt,
+		Ops:       withTemps(ops, appliedAt),
+	}
+	logPath := filepath.Join(dir, fmt.Sprintf("%s%s.json", UndoLogPrefix, log.AppliedAt.Format("20060102-150405")))
+
+	// Write the undo log before touching any file so a crash midway can
+	// still be recovered from.
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize undo log: %w", err)
+	}
+	if err := os.WriteFile(logPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write undo log: %w", err)
+	}
+
+	if err := renameAll(dir, log.Ops); err != nil {
+		if interrupted(dir, log.Ops) {
+			return logPath, err
+		}
+		_ = os.Remove(logPath)
+		return "", err
+	}
+	return logPath, nil
+}
+
+// Undo reverts the renames recorded in the undo log at logPath and removes
+// the log once every file is back under its original name.
+func Undo(logPath string) ([]Op, error) {
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read undo log: %w", err)
+	}
+
+	var log UndoLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("failed to parse undo log: %w", err)
+	}
+
+	// The directory in the log may be relative to where the rename ran,
+	// so the log's own location is the reliable reference.
+	dir := filepath.Dir(logPath)
+
+	reverse := make([]Op, 0, len(log.Ops))
+	for _, op := range log.Ops {
+		reverse = append(reverse, Op{From: op.To, To: op.From})
+	}
+
+	if interrupted(dir, log.Ops) {
+		// Apply stopped midway and could not put the files back.
+		if err := restore(dir, log.Ops); err != nil {
+			return nil, err
+		}
+	} else if err := renameAll(dir, withTemps(reverse, time.Now())); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(logPath); err != nil {
+		return reverse, fmt.Errorf("failed to remove undo log: %w", err)
+	}
+	return reverse, nil
+}
+
+// withTemps returns a copy of ops with unique temporary names, made from
+// the time at.
+func withTemps(ops []Op, at time.Time) []Op {
+	stamp := strconv.FormatInt(at.UnixNano(), 36)
+	out := make([]Op, len(ops))
+	for i, op := range ops {
+		op.Temp = fmt.Sprintf(".%s.%d.renaming", stamp, i)
+		out[i] = op
+	}
+	return out
+}
+
+// renameAll performs ops in two phases via their temporary names. If a
+// rename fails, it puts the files back under their original names.
+func renameAll(dir string, ops []Op) error {
+	for _, op := range ops {
+		if err := os.Rename(filepath.Join(dir, op.From), filepath.Join(dir, op.Temp)); err != nil {
+			err = fmt.Errorf("failed to rename %s: %w", op.From, err)
+			return errors.Join(err, restore(dir, ops))
+		}
+	}
+
+	for _, op := range ops {
+		if err := os.Rename(filepath.Join(dir, op.Temp), filepath.Join(dir, op.To)); err != nil {
+			err = fmt.Errorf("failed to rename %s to %s: %w", op.From, op.To, err)
+			return errors.Join(err, restore(dir, ops))
+		}
+	}
+
+	return nil
+}
+
+// interrupted reports whether renameAll stopped midway through ops,
+// leaving files under their temporary names.
+func interrupted(dir string, ops []Op) bool {
+	return firstTemp(dir, ops) < len(ops)
+}
+
+// firstTemp returns the index of the first op whose file is under its
+// temporary name, len(ops) if none is. renameAll renames the files in
+// order in both phases, so the ops before it are done with both when it
+// stopped in the second phase, and with neither in the first.
+func firstTemp(dir string, ops []Op) int {
+	for i, op := range ops {
+		if op.Temp == "" {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(dir, op.Temp)); err == nil {
+			return i
+		}
+	}
+	return len(ops)
+}
+
+// restore puts the files of ops, after renameAll stopped midway, back
+// under their original names: the files already renamed go back to their
+// temporary name first, so that none takes the original name of another.
+func restore(dir string, ops []Op) error {
+	first := firstTemp(dir, ops)
+	if first == len(ops) {
+		return nil
+	}
+	var errs []error
+	for _, op := range ops[:first] {
+		if err := os.Rename(filepath.Join(dir, op.To), filepath.Join(dir, op.Temp)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", op.From, err))
+		}
+	}
+	for _, op := range ops {
+		if _, err := os.Lstat(filepath.Join(dir, op.Temp)); err != nil {
+			continue
+		}
+		if err := os.Rename(filepath.Join(dir, op.Temp), filepath.Join(dir, op.From)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s from %s: %w", op.From, op.Temp, err))
+		}
+	}
+	return errors.Join(errs...)
+}
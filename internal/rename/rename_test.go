@@ -0,0 +1,287 @@
+package rename
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, n := range names {
+		if err := os.WriteFile(filepath.Join(dir, n), []byte(n), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", n, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr bool
+	}{
+		{name: "literal only", tmpl: "photo"},
+		{name: "all tokens", tmpl: "{parent}_{date:YYYY-MM-DD}_{seq:4}_{name}.{ext}"},
+		{name: "empty", tmpl: "  ", wantErr: true},
+		{name: "unknown token", tmpl: "{camera}", wantErr: true},
+		{name: "unterminated", tmpl: "{name", wantErr: true},
+		{name: "bad seq width", tmpl: "{seq:x}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.tmpl)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", tt.tmpl, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	info := FileInfo{
+		Path:    filepath.Join("shoots", "wedding", "IMG_0001.JPG"),
+		ModTime: time.Date(2024, 3, 9, 14, 5, 7, 0, time.UTC),
+	}
+
+	tests := []struct {
+		tmpl string
+		seq  int
+		want string
+	}{
+		{tmpl: "{name}", seq: 1, want: "IMG_0001.JPG"},
+		{tmpl: "{parent}_{seq:3}", seq: 7, want: "wedding_007.JPG"},
+		{tmpl: "{date}", seq: 1, want: "20240309.JPG"},
+		{tmpl: "{date:YYYY-MM-DD_hhmmss}", seq: 1, want: "2024-03-09_140507.JPG"},
+		{tmpl: "{name}-{ext}", seq: 1, want: "IMG_0001-JPG.JPG"},
+		{tmpl: "img{seq}", seq: 12, want: "img12.JPG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			tmpl, err := Parse(tt.tmpl)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if got := tmpl.Render(info, tt.seq); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanIsSortedAndSkipsUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "b.jpg", "a.jpg", "c.png")
+
+	tmpl, _ := Parse("img_{seq:2}")
+	ops, err := Plan(dir, tmpl, nil)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	want := []Op{
+		{From: "a.jpg", To: "img_01.jpg"},
+		{From: "b.jpg", To: "img_02.jpg"},
+		{From: "c.png", To: "img_03.png"},
+	}
+	if len(ops) != len(want) {
+		t.Fatalf("Expected %d ops, got %v", len(want), ops)
+	}
+	for i := range want {
+		if ops[i] != want[i] {
+			t.Errorf("ops[%d] = %v, want %v", i, ops[i], want[i])
+		}
+	}
+
+	// Names already matching the template produce no ops.
+	keep, _ := Parse("{name}")
+	ops, err = Plan(dir, keep, nil)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(ops) != 0 {
+		t.Errorf("Expected no ops, got %v", ops)
+	}
+}
+
+func TestPlanWithFilter(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a.jpg", "b.txt")
+
+	tmpl, _ := Parse("x_{name}")
+	ops, err := Plan(dir, tmpl, func(name string) bool { return strings.HasSuffix(name, ".jpg") })
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(ops) != 1 || ops[0].From != "a.jpg" {
+		t.Errorf("Expected only a.jpg to be renamed, got %v", ops)
+	}
+}
+
+func TestPlanDetectsCollisions(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a.jpg", "b.jpg")
+
+	tmpl, _ := Parse("same")
+	if _, err := Plan(dir, tmpl, nil); err == nil {
+		t.Error("Expected collision error when two files map to the same name")
+	}
+
+	// Target already used by a file that is not part of the plan.
+	dir2 := t.TempDir()
+	writeFiles(t, dir2, "a.jpg", "taken.jpg")
+	taken, _ := Parse("taken")
+	if _, err := Plan(dir2, taken, func(name string) bool { return name == "a.jpg" }); err == nil {
+		t.Error("Expected error when target already exists")
+	}
+
+	// Target already used by a folder.
+	dir3 := t.TempDir()
+	writeFiles(t, dir3, "a.jpg")
+	if err := os.Mkdir(filepath.Join(dir3, "album.jpg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	album, _ := Parse("album")
+	if _, err := Plan(dir3, album, nil); err == nil {
+		t.Error("Expected error when target is a folder")
+	}
+
+	// Target differing only in case from another file, which is the same
+	// name where the file system ignores case.
+	dir4 := t.TempDir()
+	writeFiles(t, dir4, "a.jpg", "Taken.jpg")
+	_, err := Plan(dir4, taken, func(name string) bool { return name == "a.jpg" })
+	if insensitive := ignoresCase(dir4, []string{"a.jpg"}); (err != nil) != insensitive {
+		t.Errorf("Case-insensitive file system %v, got error %v", insensitive, err)
+	}
+}
+
+func TestApplyRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a.jpg", "b.jpg", "c.jpg")
+	// b.jpg cannot go where a non-empty folder is.
+	if err := os.MkdirAll(filepath.Join(dir, "2.jpg", "kept"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ops := []Op{{From: "a.jpg", To: "1.jpg"}, {From: "b.jpg", To: "2.jpg"}, {From: "c.jpg", To: "a.jpg"}}
+	tmpl, _ := Parse("{seq}")
+	logPath, err := Apply(dir, tmpl, ops)
+	if err == nil {
+		t.Fatal("Expected Apply to fail")
+	}
+	if logPath != "" {
+		t.Errorf("Expected no undo log once the files are back, got %s", logPath)
+	}
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if strings.Join(names, ",") != "2.jpg,a.jpg,b.jpg,c.jpg" {
+		t.Errorf("Expected the original names back, got %v", names)
+	}
+	for _, name := range []string{"a.jpg", "b.jpg", "c.jpg"} {
+		if data, _ := os.ReadFile(filepath.Join(dir, name)); string(data) != name {
+			t.Errorf("Expected %s to hold its own content, got %q", name, data)
+		}
+	}
+}
+
+func TestUndoInterrupted(t *testing.T) {
+	// Apply stopped in its second phase: a.jpg is renamed, b.jpg is still
+	// under its temporary name.
+	dir := t.TempDir()
+	writeFiles(t, dir, "1.jpg", ".x.1.renaming")
+	log := UndoLog{Dir: dir, Ops: []Op{
+		{From: "a.jpg", To: "1.jpg", Temp: ".x.0.renaming"},
+		{From: "b.jpg", To: "a.jpg", Temp: ".x.1.renaming"},
+	}}
+	data, _ := json.Marshal(log)
+	logPath := filepath.Join(dir, UndoLogPrefix+"x.json")
+	if err := os.WriteFile(logPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Undo(logPath); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	for name, content := range map[string]string{"a.jpg": "1.jpg", "b.jpg": ".x.1.renaming"} {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(data) != content {
+			t.Errorf("Expected %s to hold %q, got %q (%v)", name, content, data, err)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("Expected only a.jpg and b.jpg left, got %d entries", len(entries))
+	}
+}
+
+func TestApplyAndUndo(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a.jpg", "b.jpg")
+
+	tmpl, _ := Parse("{seq}")
+	ops, err := Plan(dir, tmpl, nil)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	logPath, err := Apply(dir, tmpl, ops)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if !strings.HasPrefix(filepath.Base(logPath), UndoLogPrefix) {
+		t.Errorf("Unexpected undo log name: %s", logPath)
+	}
+
+	for name, content := range map[string]string{"1.jpg": "a.jpg", "2.jpg": "b.jpg"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != content {
+			t.Errorf("Expected %s to contain %q, got %q (%v)", name, content, data, err)
+		}
+	}
+
+	// The undo log must not be picked up by a subsequent plan.
+	again, err := Plan(dir, tmpl, nil)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("Expected undo log to be ignored, got %v", again)
+	}
+
+	if _, err := Undo(logPath); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != name {
+			t.Errorf("Expected %s to be restored, got %q (%v)", name, data, err)
+		}
+	}
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Error("Expected undo log to be removed after undo")
+	}
+}
+
+func TestApplySwap(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "1.jpg", "2.jpg")
+
+	ops := []Op{{From: "1.jpg", To: "2.jpg"}, {From: "2.jpg", To: "1.jpg"}}
+	tmpl, _ := Parse("{name}")
+	if _, err := Apply(dir, tmpl, ops); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "2.jpg"))
+	if string(data) != "1.jpg" {
+		t.Errorf("Expected swap to preserve content, got %q", data)
+	}
+}
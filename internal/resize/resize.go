@@ -0,0 +1,58 @@
+// Package resize downscales images while they are copied
+// (config.Resize), so a destination can receive previews while another
+// gets the originals.
+package resize
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/disintegration/imaging"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+	"copy-image/internal/transcode"
+)
+
+// resized are the formats downscaled, keeping their format. GIFs, whose
+// animation would be lost, and WebP files, which cannot be written, are
+// copied as they are.
+var resized = map[string]bool{"jpeg": true, "png": true, "bmp": true, "tiff": true}
+
+// New returns the processor downscaling images to r.
+func New(r config.Resize) processor.FileProcessor {
+	return resizer{r}
+}
+
+type resizer struct {
+	config.Resize
+}
+
+func (resizer) Name() string { return "resize" }
+
+// Transform downscales the image in r. The format is read from the
+// content, as an earlier processor may have converted the file.
+func (z resizer) Transform(ctx context.Context, f processor.File, r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !resized[format] || max(cfg.Width, cfg.Height) <= z.MaxSize {
+		return bytes.NewReader(data), nil
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("cannot resize %s: %w", f.Name, err)
+	}
+	img = imaging.Fit(img, z.MaxSize, z.MaxSize, imaging.Lanczos)
+	var out bytes.Buffer
+	if err := transcode.Encode(&out, img, config.TranscodeRule{To: format, Quality: z.Quality}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
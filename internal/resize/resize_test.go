@@ -0,0 +1,64 @@
+package resize
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+)
+
+func encode(t *testing.T, format string, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		width, height int // of the output; 0 when it is the input unchanged
+		format        string
+	}{
+		{"landscape jpeg", encode(t, "jpeg", 400, 200), 100, 50, "jpeg"},
+		{"portrait png", encode(t, "png", 120, 300), 40, 100, "png"},
+		{"small enough", encode(t, "jpeg", 100, 80), 0, 0, ""},
+		{"not an image", []byte("RAW data"), 0, 0, ""},
+	}
+	p := New(config.Resize{MaxSize: 100, Quality: 80}).(processor.Transformer)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := p.Transform(context.Background(), processor.File{Name: "a"}, bytes.NewReader(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, _ := io.ReadAll(r)
+			if tt.width == 0 {
+				if !bytes.Equal(out, tt.data) {
+					t.Error("Expected the file to be copied as it is")
+				}
+				return
+			}
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(out))
+			if err != nil || format != tt.format || cfg.Width != tt.width || cfg.Height != tt.height {
+				t.Errorf("Expected a %dx%d %s, got %dx%d %s (%v)", tt.width, tt.height, tt.format, cfg.Width, cfg.Height, format, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package sanitize adapts file names to the destination file system.
+// Cameras and Macs produce names that Windows shares cannot hold (":" or
+// "?" in names) or that look the same but differ byte-wise (macOS writes
+// Unicode names decomposed, as NFD), which makes copies fail or show up
+// twice.
+package sanitize
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Policy selects how destination names are adapted.
+type Policy string
+
+const (
+	// None keeps names exactly as they are in the source.
+	None Policy = ""
+	// NFC normalizes names to the composed Unicode form Windows and Linux
+	// tools expect.
+	NFC Policy = "nfc"
+	// Windows normalizes like NFC and also replaces what NTFS and SMB
+	// shares reject: the characters <>:"/\|?* and control characters,
+	// trailing dots and spaces, and reserved names such as CON or COM1.
+	Windows Policy = "windows"
+)
+
+// Parse returns the policy named s ("", "none", "nfc" or "windows").
+func Parse(s string) (Policy, error) {
+	switch p := Policy(strings.ToLower(strings.TrimSpace(s))); p {
+	case None, NFC, Windows:
+		return p, nil
+	case "none":
+		return None, nil
+	default:
+		return None, fmt.Errorf("unknown filename policy %q (expected none, nfc or windows)", s)
+	}
+}
+
+// Path applies the policy to each element of a relative path.
+func (p Policy) Path(rel string) string {
+	if p == None {
+		return rel
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	for i, part := range parts {
+		parts[i] = p.Name(part)
+	}
+	return strings.Join(parts, string(filepath.Separator))
+}
+
+// Name applies the policy to a single file or folder name.
+func (p Policy) Name(name string) string {
+	if p == None || name == "" || name == "." || name == ".." {
+		return name
+	}
+	name = norm.NFC.String(name)
+	if p != Windows {
+		return name
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	// Windows silently drops trailing dots and spaces, so "a." and "a"
+	// would be the same file.
+	if trimmed := strings.TrimRight(name, ". "); trimmed != name {
+		name = trimmed + "_"
+	}
+	if isReserved(name) {
+		base, ext, _ := strings.Cut(name, ".")
+		name = base + "_"
+		if ext != "" {
+			name += "." + ext
+		}
+	}
+	return name
+}
+
+// isReserved reports whether name is a device name Windows reserves,
+// which stays reserved with any extension ("nul.jpg").
+func isReserved(name string) bool {
+	base, _, _ := strings.Cut(name, ".")
+	switch strings.ToUpper(strings.TrimRight(base, " ")) {
+	case "CON", "PRN", "AUX", "NUL",
+		"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+		"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9":
+		return true
+	}
+	return false
+}
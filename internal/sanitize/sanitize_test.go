@@ -0,0 +1,54 @@
+package sanitize
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestName(t *testing.T) {
+	decomposed := "Cafe\u0301.jpg" // "Café" as macOS writes it
+	tests := []struct {
+		name   string
+		policy Policy
+		input  string
+		want   string
+	}{
+		{"none keeps names", None, decomposed, decomposed},
+		{"nfc composes", NFC, decomposed, "Café.jpg"},
+		{"nfc keeps illegal characters", NFC, "a:b.jpg", "a:b.jpg"},
+		{"windows composes", Windows, decomposed, "Café.jpg"},
+		{"windows replaces illegal characters", Windows, `12:30 "best"?.jpg`, "12_30 _best__.jpg"},
+		{"windows replaces control characters", Windows, "a\tb.jpg", "a_b.jpg"},
+		{"windows keeps trailing dots visible", Windows, "folder.. ", "folder_"},
+		{"windows renames reserved names", Windows, "nul.jpg", "nul_.jpg"},
+		{"windows renames reserved names without extension", Windows, "COM1", "COM1_"},
+		{"windows keeps longer names", Windows, "console.jpg", "console.jpg"},
+		{"windows keeps dot entries", Windows, "..", ".."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Name(tt.input); got != tt.want {
+				t.Errorf("Name(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	rel := filepath.Join("2024: trip", "a?.jpg")
+	want := filepath.Join("2024_ trip", "a_.jpg")
+	if got := Windows.Path(rel); got != want {
+		t.Errorf("Path(%q) = %q, want %q", rel, got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for input, want := range map[string]Policy{"": None, "none": None, "NFC": NFC, " windows ": Windows} {
+		if got, err := Parse(input); err != nil || got != want {
+			t.Errorf("Parse(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+	if _, err := Parse("mac"); err == nil {
+		t.Error("Parse(mac) should fail")
+	}
+}
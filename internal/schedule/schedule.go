@@ -0,0 +1,235 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"copy-image/internal/config"
+
+	"github.com/robfig/cron/v3"
+)
+
+// StateFile is the name of the file, next to the default config file,
+// where the last run of each job is kept across restarts.
+const StateFile = "schedule.json"
+
+// Job is a copy group that runs on a schedule.
+type Job struct {
+	ID   string
+	Spec string
+}
+
+// Jobs returns a job for every enabled group of cfg that has a schedule.
+func Jobs(cfg *config.Config) []Job {
+	var jobs []Job
+	for _, g := range cfg.GetEnabledGroups() {
+		if g.Schedule != "" {
+			jobs = append(jobs, Job{ID: g.ID, Spec: g.Schedule})
+		}
+	}
+	return jobs
+}
+
+// Run records the outcome of one scheduled run.
+type Run struct {
+	Start    time.Time `json:"start"`
+	Duration float64   `json:"duration"` // seconds
+	OK       bool      `json:"ok"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Status describes a scheduled job for the frontend and
+// `copyimage schedule list`.
+type Status struct {
+	ID       string    `json:"id"`
+	Schedule string    `json:"schedule"`
+	NextRun  time.Time `json:"nextRun"`
+	LastRun  *Run      `json:"lastRun,omitempty"`
+}
+
+// RunFunc runs the job with the given ID. A non-nil error marks the run as
+// failed in its status.
+type RunFunc func(ctx context.Context, id string) error
+
+// job is a parsed Job and its next run time.
+type job struct {
+	spec  string
+	sched cron.Schedule
+	next  time.Time
+}
+
+// Scheduler runs jobs when they are due, one at a time so overnight groups
+// never compete for the same disks.
+type Scheduler struct {
+	run       RunFunc
+	statePath string
+
+	mu   sync.Mutex
+	jobs map[string]*job
+	last map[string]Run
+	wake chan struct{}
+}
+
+// New creates a scheduler that calls run for due jobs. The last run of each
+// job is loaded from and saved to statePath; with an empty statePath it is
+// only kept in memory.
+func New(run RunFunc, statePath string) *Scheduler {
+	s := &Scheduler{
+		run:       run,
+		statePath: statePath,
+		jobs:      make(map[string]*job),
+		last:      make(map[string]Run),
+		wake:      make(chan struct{}, 1),
+	}
+	if statePath != "" {
+		if data, err := os.ReadFile(statePath); err == nil {
+			// A corrupt state file only loses the last-run display.
+			_ = json.Unmarshal(data, &s.last)
+		}
+	}
+	return s
+}
+
+// SetJobs replaces the scheduled jobs, e.g. after the config changed. Jobs
+// whose schedule is unchanged keep their next run time. Jobs with an
+// invalid schedule are left out and reported in the returned error.
+func (s *Scheduler) SetJobs(jobs []Job) error {
+	now := time.Now()
+	var errs []error
+
+	s.mu.Lock()
+	next := make(map[string]*job, len(jobs))
+	for _, j := range jobs {
+		if old, ok := s.jobs[j.ID]; ok && old.spec == j.Spec {
+			next[j.ID] = old
+			continue
+		}
+		sched, err := cron.ParseStandard(j.Spec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("group %q: invalid schedule %q: %w", j.ID, j.Spec, err))
+			continue
+		}
+		next[j.ID] = &job{spec: j.Spec, sched: sched, next: sched.Next(now)}
+	}
+	s.jobs = next
+	s.mu.Unlock()
+
+	// Let Start recompute when it next has to wake up.
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return errors.Join(errs...)
+}
+
+// Status returns the scheduled jobs, soonest first.
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, 0, len(s.jobs))
+	for id, j := range s.jobs {
+		st := Status{ID: id, Schedule: j.spec, NextRun: j.next}
+		if r, ok := s.last[id]; ok {
+			st.LastRun = &r
+		}
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(i, k int) bool {
+		if !statuses[i].NextRun.Equal(statuses[k].NextRun) {
+			return statuses[i].NextRun.Before(statuses[k].NextRun)
+		}
+		return statuses[i].ID < statuses[k].ID
+	})
+	return statuses
+}
+
+// Start runs due jobs until ctx is cancelled. When the computer was asleep
+// past a job's time the job runs once on wake-up; the runs it missed are
+// not repeated.
+func (s *Scheduler) Start(ctx context.Context) {
+	for {
+		id, at := s.nextJob()
+		var timer *time.Timer
+		var due <-chan time.Time // nil without jobs: wait for SetJobs
+		if id != "" {
+			timer = time.NewTimer(time.Until(at))
+			due = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+			return
+		case <-s.wake:
+			stopTimer(timer)
+		case <-due:
+			s.runJob(ctx, id)
+		}
+	}
+}
+
+// stopTimer stops t if a job was due.
+func stopTimer(t *time.Timer) {
+	if t != nil {
+		t.Stop()
+	}
+}
+
+// nextJob returns the job due first, or "" when there are none.
+func (s *Scheduler) nextJob() (string, time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var id string
+	var at time.Time
+	for jid, j := range s.jobs {
+		if id == "" || j.next.Before(at) || j.next.Equal(at) && jid < id {
+			id, at = jid, j.next
+		}
+	}
+	return id, at
+}
+
+// runJob runs the job, records the outcome and schedules its next run.
+func (s *Scheduler) runJob(ctx context.Context, id string) {
+	start := time.Now()
+	err := s.run(ctx, id)
+	r := Run{Start: start, Duration: time.Since(start).Seconds(), OK: err == nil}
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	s.last[id] = r
+	if j, ok := s.jobs[id]; ok {
+		j.next = j.sched.Next(time.Now())
+	}
+	s.mu.Unlock()
+
+	s.saveState()
+}
+
+// saveState writes the last runs to statePath.
+func (s *Scheduler) saveState() {
+	if s.statePath == "" {
+		return
+	}
+	s.mu.Lock()
+	data, err := json.MarshalIndent(s.last, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(s.statePath), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(s.statePath, data, 0600)
+}
@@ -0,0 +1,103 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+func TestJobs(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Groups = []config.CopyGroup{
+		{ID: "nightly", Enabled: true, Schedule: "0 2 * * *"},
+		{ID: "manual", Enabled: true},
+		{ID: "off", Enabled: false, Schedule: "@daily"},
+	}
+	jobs := Jobs(cfg)
+	if len(jobs) != 1 || jobs[0] != (Job{ID: "nightly", Spec: "0 2 * * *"}) {
+		t.Errorf("Expected only the enabled scheduled group, got %v", jobs)
+	}
+}
+
+func TestSetJobs(t *testing.T) {
+	s := New(func(context.Context, string) error { return nil }, "")
+	err := s.SetJobs([]Job{
+		{ID: "nightly", Spec: "0 2 * * *"},
+		{ID: "hourly", Spec: "@hourly"},
+		{ID: "broken", Spec: "61 * * * *"},
+	})
+	if err == nil {
+		t.Error("Expected an error for the invalid schedule")
+	}
+
+	status := s.Status()
+	if len(status) != 2 {
+		t.Fatalf("Expected 2 valid jobs, got %v", status)
+	}
+	if status[0].ID != "hourly" || status[1].ID != "nightly" {
+		t.Errorf("Expected jobs soonest first, got %v", status)
+	}
+	if next := status[1].NextRun; next.Hour() != 2 || next.Minute() != 0 || !next.After(time.Now()) {
+		t.Errorf("Unexpected next run for nightly: %v", next)
+	}
+
+	// Unchanged jobs keep their next run.
+	before := status[1].NextRun
+	if err := s.SetJobs([]Job{{ID: "nightly", Spec: "0 2 * * *"}}); err != nil {
+		t.Fatalf("SetJobs failed: %v", err)
+	}
+	if status := s.Status(); len(status) != 1 || !status[0].NextRun.Equal(before) {
+		t.Errorf("Expected nightly to keep %v, got %v", before, status)
+	}
+}
+
+func TestStartRunsDueJobs(t *testing.T) {
+	state := filepath.Join(t.TempDir(), StateFile)
+	ran := make(chan string, 10)
+	s := New(func(_ context.Context, id string) error {
+		ran <- id
+		return errors.New("2 file(s) failed")
+	}, state)
+	if err := s.SetJobs([]Job{{ID: "often", Spec: "@every 1s"}}); err != nil {
+		t.Fatalf("SetJobs failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+
+	select {
+	case id := <-ran:
+		if id != "often" {
+			t.Errorf("Expected job often to run, got %q", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the job to run")
+	}
+	cancel()
+	<-done
+
+	status := s.Status()
+	if len(status) != 1 || status[0].LastRun == nil {
+		t.Fatalf("Expected the last run to be recorded, got %v", status)
+	}
+	if last := status[0].LastRun; last.OK || last.Error != "2 file(s) failed" {
+		t.Errorf("Expected a failed last run, got %+v", last)
+	}
+
+	// The last run survives a restart.
+	reloaded := New(nil, state)
+	if err := reloaded.SetJobs([]Job{{ID: "often", Spec: "@every 1s"}}); err != nil {
+		t.Fatalf("SetJobs failed: %v", err)
+	}
+	if st := reloaded.Status(); st[0].LastRun == nil || st[0].LastRun.Error != "2 file(s) failed" {
+		t.Errorf("Expected the saved last run, got %+v", st[0].LastRun)
+	}
+}
@@ -0,0 +1,53 @@
+// Package shell runs the command lines users write in the config, such as
+// hooks and exec processors, with the system's shell, and shows files in
+// the system's file manager.
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// maxOutput is how much of a failed command's output Tail keeps.
+const maxOutput = 1 << 10
+
+// Tail returns the end of a command's output on one line, for error
+// messages.
+func Tail(out []byte) string {
+	s := strings.TrimSpace(string(out))
+	if len(s) > maxOutput {
+		s = "…" + s[len(s)-maxOutput:]
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Reveal shows path in the file manager: a folder is opened, and a file is
+// selected in its folder where the file manager can do that. For a file
+// that does not exist (yet), such as a copy that failed, its folder is
+// opened.
+func Reveal(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		dir := filepath.Dir(path)
+		if d, derr := os.Stat(dir); derr != nil || !d.IsDir() {
+			return err
+		}
+		return start(openCommand(dir))
+	}
+	if info.IsDir() {
+		return start(openCommand(path))
+	}
+	return start(selectCommand(path))
+}
+
+// start runs cmd without waiting for it: file managers may keep running,
+// and explorer.exe exits with 1 even when it succeeded.
+var start = func(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
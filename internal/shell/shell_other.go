@@ -0,0 +1,32 @@
+//go:build !windows
+
+package shell
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// Command returns the command running command with sh.
+func Command(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// openCommand returns the command opening the folder dir.
+func openCommand(dir string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("open", dir)
+	}
+	return exec.Command("xdg-open", dir)
+}
+
+// selectCommand returns the command showing the file at path. Finder
+// selects it; xdg-open can only open its folder.
+func selectCommand(path string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("open", "-R", path)
+	}
+	return exec.Command("xdg-open", filepath.Dir(path))
+}
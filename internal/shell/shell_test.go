@@ -0,0 +1,50 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestReveal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Explorer's command line is not inspected here")
+	}
+	var ran []string
+	orig := start
+	t.Cleanup(func() { start = orig })
+	start = func(cmd *exec.Cmd) error {
+		ran = append(ran, cmd.Args[len(cmd.Args)-1])
+		return nil
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.jpg")
+	if err := os.WriteFile(file, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Finder selects the file; xdg-open opens its folder.
+	shown := dir
+	if runtime.GOOS == "darwin" {
+		shown = file
+	}
+	tests := []struct {
+		name, path, want string
+	}{
+		{"folder", dir, dir},
+		{"file", file, shown},
+		{"missing file", filepath.Join(dir, "failed.jpg"), dir},
+	}
+	for _, tt := range tests {
+		ran = nil
+		if err := Reveal(tt.path); err != nil || len(ran) != 1 || ran[0] != tt.want {
+			t.Errorf("%s: expected %s to be shown, got %v (%v)", tt.name, tt.want, ran, err)
+		}
+	}
+
+	if err := Reveal(filepath.Join(dir, "gone", "a.jpg")); err == nil {
+		t.Error("Expected an error when the folder does not exist either")
+	}
+}
@@ -0,0 +1,43 @@
+//go:build windows
+
+package shell
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+// Command returns the command running command with cmd.exe. The command
+// line is passed as written: Go's argument quoting would escape quotes in
+// a way cmd does not understand.
+func Command(ctx context.Context, command string) *exec.Cmd {
+	comspec := os.Getenv("ComSpec")
+	if comspec == "" {
+		comspec = filepath.Join(os.Getenv("SystemRoot"), "System32", "cmd.exe")
+	}
+	cmd := exec.CommandContext(ctx, comspec)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: syscall.EscapeArg(comspec) + ` /d /s /c "` + command + `"`}
+	return cmd
+}
+
+// openCommand returns the command opening the folder dir in Explorer.
+func openCommand(dir string) *exec.Cmd {
+	return exec.Command(explorer(), dir)
+}
+
+// selectCommand returns the command selecting the file at path in
+// Explorer. Explorer wants the path quoted after /select, which Go's
+// argument quoting does not do.
+func selectCommand(path string) *exec.Cmd {
+	exe := explorer()
+	cmd := exec.Command(exe)
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: syscall.EscapeArg(exe) + ` /select,"` + path + `"`}
+	return cmd
+}
+
+func explorer() string {
+	return filepath.Join(os.Getenv("SystemRoot"), "explorer.exe")
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// FS reads sources from an fs.FS, such as an fstest.MapFS in tests or an
+// embedded or zipped folder. The copier sees the files below a root path,
+// e.g. its configured source; paths outside it do not exist. It is
+// read-only.
+type FS struct {
+	fsys fs.FS
+	root string
+}
+
+// NewFS returns the storage showing fsys at root.
+func NewFS(fsys fs.FS, root string) *FS {
+	return &FS{fsys: fsys, root: filepath.Clean(root)}
+}
+
+// name returns the fs.FS name of path, or false outside the root.
+func (f *FS) name(path string) (string, bool) {
+	rel, err := filepath.Rel(f.root, filepath.Clean(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func (f *FS) Open(path string) (io.ReadCloser, error) {
+	name, ok := f.name(path)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	return f.fsys.Open(name)
+}
+
+func (f *FS) Create(path string) (File, error) {
+	return nil, &fs.PathError{Op: "create", Path: path, Err: errors.ErrUnsupported}
+}
+
+func (f *FS) Stat(path string) (fs.FileInfo, error) {
+	name, ok := f.name(path)
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+	}
+	return fs.Stat(f.fsys, name)
+}
+
+// List returns the entries of dir, sorted by name as fs.ReadDir does.
+func (f *FS) List(dir string) ([]fs.DirEntry, error) {
+	name, ok := f.name(dir)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrNotExist}
+	}
+	return fs.ReadDir(f.fsys, name)
+}
+
+func (f *FS) Remove(path string) error {
+	return &fs.PathError{Op: "remove", Path: path, Err: errors.ErrUnsupported}
+}
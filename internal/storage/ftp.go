@@ -0,0 +1,341 @@
+package storage
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/textproto"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+
+	"copy-image/internal/config"
+)
+
+// ftpTimeout bounds connecting and each reply from the server.
+const ftpTimeout = 30 * time.Second
+
+// FTP stores files on an FTP or FTPS server, for print labs and other
+// services that accept nothing else. Paths are ftp://host[:port]/path URLs
+// (or their filepath.Join form). Each worker uses its own connection from
+// a pool; a connection the server dropped, typically after idling, is
+// replaced and the operation tried once more.
+type FTP struct {
+	addr string
+	opts config.FTPOptions
+
+	mu   sync.Mutex
+	idle []*ftp.ServerConn
+	made map[string]bool // folders known to exist
+}
+
+// NewFTP logs in to the server of the ftp:// URL root, so wrong
+// credentials are reported once rather than for every file.
+func NewFTP(root string, opts *config.FTPOptions) (*FTP, error) {
+	if opts == nil {
+		opts = &config.FTPOptions{}
+	}
+	addr, _, err := config.ParseFTP(root, opts.TLS)
+	if err != nil {
+		return nil, err
+	}
+	s := &FTP{addr: addr, opts: *opts, made: map[string]bool{"/": true}}
+	c, err := s.dial()
+	if err != nil {
+		return nil, err
+	}
+	s.release(c, nil)
+	return s, nil
+}
+
+// dial opens and logs in a new connection. Transfers use passive mode
+// (EPSV, or PASV when it is disabled).
+func (s *FTP) dial() (*ftp.ServerConn, error) {
+	options := []ftp.DialOption{
+		ftp.DialWithTimeout(ftpTimeout),
+		ftp.DialWithDisabledEPSV(s.opts.DisableEPSV),
+	}
+	host, _, _ := net.SplitHostPort(s.addr)
+	tlsConfig := &tls.Config{ServerName: host, InsecureSkipVerify: s.opts.InsecureSkipVerify}
+	switch s.opts.TLS {
+	case config.FTPExplicitTLS:
+		options = append(options, ftp.DialWithExplicitTLS(tlsConfig))
+	case config.FTPImplicitTLS:
+		options = append(options, ftp.DialWithTLS(tlsConfig))
+	}
+
+	c, err := ftp.Dial(s.addr, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", s.addr, err)
+	}
+	user, password := s.opts.Credentials()
+	if err := c.Login(user, password); err != nil {
+		_ = c.Quit()
+		return nil, fmt.Errorf("failed to log in to %s as %s: %w", s.addr, user, err)
+	}
+	return c, nil
+}
+
+// get returns an idle connection, or a new one.
+func (s *FTP) get() (*ftp.ServerConn, error) {
+	s.mu.Lock()
+	if n := len(s.idle); n > 0 {
+		c := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.mu.Unlock()
+		return c, nil
+	}
+	s.mu.Unlock()
+	return s.dial()
+}
+
+// release returns c to the pool after an operation that ended with err,
+// or closes it when err shows the connection is broken.
+func (s *FTP) release(c *ftp.ServerConn, err error) {
+	if err != nil && !isReply(err) {
+		_ = c.Quit()
+		return
+	}
+	s.mu.Lock()
+	s.idle = append(s.idle, c)
+	s.mu.Unlock()
+}
+
+// try runs op on a connection, and once more on a new one when the first
+// turns out to be dropped. Unless it fails, the connection is returned
+// for the caller to release.
+func (s *FTP) try(op func(*ftp.ServerConn) error) (*ftp.ServerConn, error) {
+	for attempt := 0; ; attempt++ {
+		c, err := s.get()
+		if err != nil {
+			return nil, err
+		}
+		err = op(c)
+		if err == nil {
+			return c, nil
+		}
+		s.release(c, err)
+		if isReply(err) || attempt > 0 {
+			return nil, err
+		}
+	}
+}
+
+// do runs op like try and releases the connection.
+func (s *FTP) do(op func(*ftp.ServerConn) error) error {
+	c, err := s.try(op)
+	if c != nil {
+		s.release(c, nil)
+	}
+	return err
+}
+
+// remote returns the path on the server of p, checking it is on this
+// server.
+func (s *FTP) remote(p string) (string, error) {
+	addr, remote, err := config.ParseFTP(p, s.opts.TLS)
+	if err != nil {
+		return "", err
+	}
+	if addr != s.addr {
+		return "", fmt.Errorf("%s is not on server %s", p, s.addr)
+	}
+	return remote, nil
+}
+
+// Open downloads the file at p.
+func (s *FTP) Open(p string) (io.ReadCloser, error) {
+	remote, err := s.remote(p)
+	if err != nil {
+		return nil, err
+	}
+	var resp *ftp.Response
+	c, err := s.try(func(c *ftp.ServerConn) (err error) {
+		resp, err = c.Retr(remote)
+		return err
+	})
+	if err != nil {
+		return nil, s.pathError("open", p, err)
+	}
+	return &ftpDownload{Response: resp, s: s, conn: c}, nil
+}
+
+// Create uploads what is written to the file at p, creating its folders
+// as needed. The file is complete when Close returns without error.
+func (s *FTP) Create(p string) (File, error) {
+	return s.upload("create", p, 0)
+}
+
+// Resume continues an upload that was cut off, writing from offset on
+// (REST and STOR), so a dropped connection does not send a large file
+// again from the start.
+func (s *FTP) Resume(p string, offset int64) (File, error) {
+	return s.upload("resume", p, offset)
+}
+
+func (s *FTP) upload(op, p string, offset int64) (File, error) {
+	remote, err := s.remote(p)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.try(func(c *ftp.ServerConn) error {
+		return s.mkdirAll(c, path.Dir(remote))
+	})
+	if err != nil {
+		return nil, s.pathError(op, p, err)
+	}
+
+	// Aborting closes the data connection, which servers cannot tell from
+	// the end of the file: the caller removes what they stored.
+	r, w := io.Pipe()
+	u := &pipeUpload{w: w, done: make(chan error, 1)}
+	go func() {
+		err := c.StorFrom(remote, r, uint64(offset))
+		// Unblock writers if the upload failed early.
+		_ = r.CloseWithError(err)
+		s.release(c, err)
+		u.done <- s.pathError(op, p, err)
+	}()
+	return u, nil
+}
+
+// mkdirAll creates dir and its parents. Refusals are ignored since most
+// mean the folder exists; if not, storing the file reports it.
+func (s *FTP) mkdirAll(c *ftp.ServerConn, dir string) error {
+	s.mu.Lock()
+	made := s.made[dir]
+	s.mu.Unlock()
+	if made {
+		return nil
+	}
+	if err := s.mkdirAll(c, path.Dir(dir)); err != nil {
+		return err
+	}
+	if err := c.MakeDir(dir); err != nil && !isReply(err) {
+		return err
+	}
+	s.mu.Lock()
+	s.made[dir] = true
+	s.mu.Unlock()
+	return nil
+}
+
+// Stat describes the file or folder at p, found by listing its folder
+// since not every server supports MLST.
+func (s *FTP) Stat(p string) (fs.FileInfo, error) {
+	remote, err := s.remote(p)
+	if err != nil {
+		return nil, err
+	}
+	if remote == "/" {
+		return memInfo{name: "/", dir: true}, nil
+	}
+	entries, err := s.list(path.Dir(remote))
+	if err != nil {
+		return nil, s.pathError("stat", p, err)
+	}
+	name := path.Base(remote)
+	for _, e := range entries {
+		if e.Name() == name {
+			return e.Info()
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+}
+
+// List returns the files and folders directly in dir.
+func (s *FTP) List(dir string) ([]fs.DirEntry, error) {
+	remote, err := s.remote(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.list(remote)
+	if err != nil {
+		return nil, s.pathError("readdir", dir, err)
+	}
+	return entries, nil
+}
+
+func (s *FTP) list(remote string) ([]fs.DirEntry, error) {
+	var list []*ftp.Entry
+	err := s.do(func(c *ftp.ServerConn) (err error) {
+		list, err = c.List(remote)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]fs.DirEntry, 0, len(list))
+	for _, e := range list {
+		if e.Name == "." || e.Name == ".." {
+			continue
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(memInfo{
+			name:    path.Base(e.Name),
+			size:    int64(e.Size),
+			modTime: e.Time,
+			dir:     e.Type == ftp.EntryTypeFolder,
+		}))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Remove deletes the file or empty folder at p.
+func (s *FTP) Remove(p string) error {
+	remote, err := s.remote(p)
+	if err != nil {
+		return err
+	}
+	err = s.do(func(c *ftp.ServerConn) error {
+		err := c.Delete(remote)
+		if isReply(err) && c.RemoveDir(remote) == nil {
+			return nil
+		}
+		return err
+	})
+	return s.pathError("remove", p, err)
+}
+
+// pathError wraps an FTP error like the os package does, with 550 replies
+// (no such file, in most servers) matching fs.ErrNotExist and refused
+// logins fs.ErrPermission.
+func (s *FTP) pathError(op, p string, err error) error {
+	var reply *textproto.Error
+	switch {
+	case err == nil:
+		return nil
+	case !errors.As(err, &reply):
+	case reply.Code == ftp.StatusFileUnavailable:
+		err = fmt.Errorf("%w (%v)", fs.ErrNotExist, err)
+	case reply.Code == ftp.StatusNotLoggedIn, reply.Code == ftp.StatusInvalidCredentials, reply.Code == ftp.StatusStorNeedAccount:
+		err = fmt.Errorf("%w (%v)", fs.ErrPermission, err)
+	}
+	return &fs.PathError{Op: op, Path: p, Err: err}
+}
+
+// isReply reports whether err is a reply from the server, which leaves
+// the connection usable, rather than a broken connection.
+func isReply(err error) bool {
+	var reply *textproto.Error
+	return errors.As(err, &reply)
+}
+
+// ftpDownload returns its connection to the pool once the file is read.
+type ftpDownload struct {
+	*ftp.Response
+	s    *FTP
+	conn *ftp.ServerConn
+}
+
+func (d *ftpDownload) Close() error {
+	err := d.Response.Close()
+	d.s.release(d.conn, err)
+	return err
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"net/textproto"
+	"path/filepath"
+	"testing"
+
+	"copy-image/internal/config"
+)
+
+func TestFTPRemote(t *testing.T) {
+	s := &FTP{addr: "lab.example:21"}
+	// The copier joins file names below the URL with filepath.Join.
+	remote, err := s.remote(filepath.Join("ftp://lab.example/orders", "42", "a.jpg"))
+	if err != nil || remote != "/orders/42/a.jpg" {
+		t.Errorf("remote() = %q, %v, want /orders/42/a.jpg", remote, err)
+	}
+	if _, err := s.remote("ftp://other.example/a.jpg"); err == nil {
+		t.Error("remote() of another server should fail")
+	}
+}
+
+func TestFTPPathError(t *testing.T) {
+	s := &FTP{}
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{&textproto.Error{Code: 550, Msg: "No such file"}, fs.ErrNotExist},
+		{&textproto.Error{Code: 530, Msg: "Login incorrect"}, fs.ErrPermission},
+	}
+	for _, tt := range tests {
+		if err := s.pathError("open", "ftp://lab/a.jpg", tt.err); !errors.Is(err, tt.want) {
+			t.Errorf("pathError(%v) = %v, want %v", tt.err, err, tt.want)
+		}
+	}
+	if s.pathError("open", "a", nil) != nil {
+		t.Error("pathError(nil) should be nil")
+	}
+	if isReply(errors.New("connection reset")) || !isReply(&textproto.Error{Code: 550}) {
+		t.Error("isReply() should tell replies from broken connections")
+	}
+}
+
+func TestNewFTPUnreachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	_, err = ForDestination(&config.Config{Destination: "ftp://" + addr + "/orders"})
+	if err == nil {
+		t.Error("ForDestination() should fail when the server cannot be reached")
+	}
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+// HTTP downloads files given as http:// or https:// URLs. It is read-only
+// and cannot list folders, so the URLs come from a list
+// (Config.URLsFrom).
+type HTTP struct {
+	client *http.Client
+}
+
+// NewHTTP returns a storage downloading with its own client. There is no
+// overall timeout, which would cut off large files on slow links; only
+// waiting for the server to answer is bounded.
+func NewHTTP() *HTTP {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	return &HTTP{client: &http.Client{Transport: transport}}
+}
+
+// Open downloads the file at the URL.
+func (s *HTTP) Open(u string) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, u)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: u, Err: err}
+	}
+	return resp.Body, nil
+}
+
+// Stat describes the file at the URL from a HEAD request. Servers that
+// refuse HEAD report a file of unknown (zero) size.
+func (s *HTTP) Stat(u string) (fs.FileInfo, error) {
+	info := memInfo{name: URLName(u)}
+	resp, err := s.do(http.MethodHead, u)
+	var status statusError
+	switch {
+	case errors.As(err, &status) && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented):
+		return info, nil
+	case err != nil:
+		return nil, &fs.PathError{Op: "stat", Path: u, Err: err}
+	}
+	_ = resp.Body.Close()
+	info.size = max(resp.ContentLength, 0)
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.modTime = t
+	}
+	return info, nil
+}
+
+// Create is not supported: URLs are only a source.
+func (s *HTTP) Create(u string) (File, error) {
+	return nil, &fs.PathError{Op: "create", Path: u, Err: errors.ErrUnsupported}
+}
+
+// List is not supported: web servers have no standard folder listing.
+func (s *HTTP) List(u string) ([]fs.DirEntry, error) {
+	return nil, &fs.PathError{Op: "readdir", Path: u, Err: errors.ErrUnsupported}
+}
+
+// Remove is not supported: URLs are only a source.
+func (s *HTTP) Remove(u string) error {
+	return &fs.PathError{Op: "remove", Path: u, Err: errors.ErrUnsupported}
+}
+
+// do sends a request and returns the response of a 2xx status. Missing
+// files match fs.ErrNotExist and refused ones fs.ErrPermission; other
+// statuses, such as 503 from a busy server, are worth retrying.
+func (s *HTTP) do(method, u string) (*http.Response, error) {
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "copy-image")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	status := statusError(resp.StatusCode)
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return nil, fmt.Errorf("%w (%v)", fs.ErrNotExist, status)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("%w (%v)", fs.ErrPermission, status)
+	}
+	return nil, status
+}
+
+// statusError is an unexpected HTTP status.
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("HTTP %d %s", int(e), http.StatusText(int(e)))
+}
+
+// URLName returns the file name of an http(s) URL: the last element of
+// its path, unescaped, without the query. URLs ending in "/" have none.
+func URLName(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return path.Base(u)
+	}
+	name := path.Base(parsed.Path)
+	if name == "/" || name == "." {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Local is the local file system, including mapped drives and mounted
+// network shares.
+type Local struct{}
+
+// Open opens the file for reading.
+func (Local) Open(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+// Create creates or truncates the file, creating its folder as needed.
+func (Local) Create(path string) (File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// Stat describes the file, following symbolic links.
+func (Local) Stat(path string) (fs.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// List returns the entries of dir sorted by name.
+func (Local) List(dir string) ([]fs.DirEntry, error) {
+	return os.ReadDir(dir)
+}
+
+// ListBatches calls fn with the entries of dir, n at a time, unsorted.
+func (Local) ListBatches(dir string, n int, fn func([]fs.DirEntry) error) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	for {
+		entries, err := f.ReadDir(n)
+		if len(entries) > 0 {
+			if err := fn(entries); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Remove deletes the file or empty folder.
+func (Local) Remove(path string) error {
+	return os.Remove(path)
+}
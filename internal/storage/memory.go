@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory is a file system held in memory, for tests. Folders exist
+// implicitly while they contain files. It is safe for concurrent use.
+type Memory struct {
+	mu    sync.Mutex
+	files map[string]memFile
+}
+
+type memFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemory returns an empty in-memory file system.
+func NewMemory() *Memory {
+	return &Memory{files: make(map[string]memFile)}
+}
+
+// WriteFile stores data at path, replacing any existing file.
+func (m *Memory) WriteFile(path string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[filepath.Clean(path)] = memFile{data: bytes.Clone(data), modTime: time.Now()}
+	return nil
+}
+
+// Open opens the file for reading. The content is a snapshot: later
+// writes do not show.
+func (m *Memory) Open(path string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	return io.NopCloser(bytes.NewReader(f.data)), nil
+}
+
+// Create returns a file whose content is stored when it is closed.
+func (m *Memory) Create(path string) (File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	if m.isDir(path) {
+		return nil, &fs.PathError{Op: "create", Path: path, Err: fs.ErrExist}
+	}
+	m.files[path] = memFile{modTime: time.Now()}
+	return &memWriter{m: m, path: path}, nil
+}
+
+// Stat describes the file or folder.
+func (m *Memory) Stat(path string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	if f, ok := m.files[path]; ok {
+		return memInfo{name: filepath.Base(path), size: int64(len(f.data)), modTime: f.modTime}, nil
+	}
+	if m.isDir(path) {
+		return memInfo{name: filepath.Base(path), dir: true}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+}
+
+// List returns the files and folders directly in dir, sorted by name.
+func (m *Memory) List(dir string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dir = filepath.Clean(dir)
+	if !m.isDir(dir) {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrNotExist}
+	}
+
+	children := make(map[string]fs.DirEntry)
+	prefix := dirPrefix(dir)
+	for path, f := range m.files {
+		rest, ok := strings.CutPrefix(path, prefix)
+		if !ok {
+			continue
+		}
+		if name, _, nested := strings.Cut(rest, string(filepath.Separator)); nested {
+			children[name] = fs.FileInfoToDirEntry(memInfo{name: name, dir: true})
+		} else {
+			children[name] = fs.FileInfoToDirEntry(memInfo{name: name, size: int64(len(f.data)), modTime: f.modTime})
+		}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, entry := range children {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Remove deletes the file. Folders disappear with their last file.
+func (m *Memory) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	if _, ok := m.files[path]; !ok {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+	}
+	delete(m.files, path)
+	return nil
+}
+
+// isDir reports whether some file is below path. m.mu must be held.
+func (m *Memory) isDir(path string) bool {
+	prefix := dirPrefix(path)
+	for p := range m.files {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// dirPrefix returns dir followed by one separator, for prefix matching.
+func dirPrefix(dir string) string {
+	if strings.HasSuffix(dir, string(filepath.Separator)) {
+		return dir
+	}
+	return dir + string(filepath.Separator)
+}
+
+// memWriter buffers a file created in Memory until it is closed.
+type memWriter struct {
+	m    *Memory
+	path string
+	buf  bytes.Buffer
+}
+
+func (w *memWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *memWriter) Sync() error {
+	return nil
+}
+
+func (w *memWriter) Close() error {
+	return w.m.WriteFile(w.path, w.buf.Bytes())
+}
+
+// memInfo describes a file or folder in Memory.
+type memInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
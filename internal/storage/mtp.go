@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"sync"
+
+	"copy-image/internal/config"
+)
+
+// MTP reads the files of a phone or camera connected over USB as a media
+// device (MTP or PTP), which gets no drive letter. Paths are
+// mtp://<device>/<storage>/<folders> URLs (or their filepath.Join form),
+// e.g. mtp://Pixel 7/Internal shared storage/DCIM. It is read-only.
+//
+// Devices address files by object IDs, not paths, so each folder listed is
+// remembered and paths are resolved one folder at a time.
+type MTP struct {
+	device string
+	conn   mtpConn
+
+	mu      sync.Mutex
+	objects map[string]mtpObject // by path on the device, "" for the device
+}
+
+// MTPDevice is a phone or camera connected as a media device.
+type MTPDevice struct {
+	Name     string   `json:"name"`     // friendly name, as in mtp:// URLs
+	Storages []string `json:"storages"` // e.g. "Internal shared storage"
+	// Sources are the mtp:// URLs of the DCIM folders, where cameras and
+	// phones keep their photos, ready to use as a source.
+	Sources []string `json:"sources"`
+}
+
+// mtpObject is a file, folder or storage on a device.
+type mtpObject struct {
+	id   string
+	name string
+	dir  bool
+	size int64
+}
+
+// mtpConn is an open session with a device, implemented with Windows
+// Portable Devices.
+type mtpConn interface {
+	// children returns the objects directly inside the one with id.
+	children(id string) ([]mtpObject, error)
+	// open reads the content of a file.
+	open(id string) (io.ReadCloser, error)
+}
+
+// mtpRootID is the object ID of the device itself, whose children are its
+// storages.
+const mtpRootID = "DEVICE"
+
+// NewMTP opens the device named in the mtp:// URL root.
+func NewMTP(root string) (*MTP, error) {
+	device, _, err := config.ParseMTP(root)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := openMTP(device)
+	if err != nil {
+		return nil, err
+	}
+	return newMTP(device, conn), nil
+}
+
+func newMTP(device string, conn mtpConn) *MTP {
+	return &MTP{
+		device:  device,
+		conn:    conn,
+		objects: map[string]mtpObject{"": {id: mtpRootID, name: device, dir: true}},
+	}
+}
+
+// MTPDevices returns the connected media devices with their storages.
+func MTPDevices() ([]MTPDevice, error) {
+	names, err := mtpDeviceNames()
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]MTPDevice, 0, len(names))
+	for _, name := range names {
+		conn, err := openMTP(name)
+		if err != nil {
+			// Locked phones refuse to open until they allow file transfer;
+			// list them so the user knows they were seen.
+			devices = append(devices, MTPDevice{Name: name, Storages: []string{}, Sources: []string{}})
+			continue
+		}
+		devices = append(devices, newMTP(name, conn).describe())
+	}
+	return devices, nil
+}
+
+// describe lists the storages of the device and their DCIM folders.
+func (s *MTP) describe() MTPDevice {
+	device := MTPDevice{Name: s.device, Storages: []string{}, Sources: []string{}}
+	storages, _ := s.list("")
+	for _, storage := range storages {
+		device.Storages = append(device.Storages, storage.name)
+		if obj, err := s.object(path.Join(storage.name, "DCIM")); err == nil && obj.dir {
+			device.Sources = append(device.Sources, "mtp://"+path.Join(s.device, storage.name, "DCIM"))
+		}
+	}
+	return device
+}
+
+// Open reads the file.
+func (s *MTP) Open(p string) (io.ReadCloser, error) {
+	obj, err := s.lookup(p)
+	if err == nil && obj.dir {
+		err = errors.New("is a directory")
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: p, Err: err}
+	}
+	r, err := s.conn.open(obj.id)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: p, Err: err}
+	}
+	return r, nil
+}
+
+// Stat describes the file, folder or storage. Devices do not report
+// modification times reliably, so they are left zero.
+func (s *MTP) Stat(p string) (fs.FileInfo, error) {
+	obj, err := s.lookup(p)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: p, Err: err}
+	}
+	return memInfo{name: obj.name, size: obj.size, dir: obj.dir}, nil
+}
+
+// List returns the files and folders directly in dir, sorted by name.
+func (s *MTP) List(dir string) ([]fs.DirEntry, error) {
+	rel, err := s.rel(dir)
+	if err == nil {
+		var objects []mtpObject
+		if objects, err = s.list(rel); err == nil {
+			entries := make([]fs.DirEntry, len(objects))
+			for i, obj := range objects {
+				entries[i] = fs.FileInfoToDirEntry(memInfo{name: obj.name, size: obj.size, dir: obj.dir})
+			}
+			sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+			return entries, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "readdir", Path: dir, Err: err}
+}
+
+// Create is not supported: devices are only a source.
+func (s *MTP) Create(p string) (File, error) {
+	return nil, &fs.PathError{Op: "create", Path: p, Err: errors.ErrUnsupported}
+}
+
+// Remove is not supported: importing never deletes from the device.
+func (s *MTP) Remove(p string) error {
+	return &fs.PathError{Op: "remove", Path: p, Err: errors.ErrUnsupported}
+}
+
+// rel returns the path on the device of an mtp:// URL.
+func (s *MTP) rel(p string) (string, error) {
+	device, rel, err := config.ParseMTP(p)
+	if err != nil {
+		return "", err
+	}
+	if device != s.device {
+		return "", fmt.Errorf("%s is not on %s", p, s.device)
+	}
+	return rel, nil
+}
+
+// lookup returns the object at an mtp:// URL, listing its parent folders
+// the first time.
+func (s *MTP) lookup(p string) (mtpObject, error) {
+	rel, err := s.rel(p)
+	if err != nil {
+		return mtpObject{}, err
+	}
+	return s.object(rel)
+}
+
+func (s *MTP) object(rel string) (mtpObject, error) {
+	s.mu.Lock()
+	obj, ok := s.objects[rel]
+	s.mu.Unlock()
+	if ok {
+		return obj, nil
+	}
+
+	parent := path.Dir(rel)
+	if parent == "." {
+		parent = ""
+	}
+	if _, err := s.list(parent); err != nil {
+		return mtpObject{}, err
+	}
+	s.mu.Lock()
+	obj, ok = s.objects[rel]
+	s.mu.Unlock()
+	if !ok {
+		return mtpObject{}, fs.ErrNotExist
+	}
+	return obj, nil
+}
+
+// list returns the objects in the folder at rel and remembers them.
+func (s *MTP) list(rel string) ([]mtpObject, error) {
+	dir, err := s.object(rel)
+	if err != nil {
+		return nil, err
+	}
+	if !dir.dir {
+		return nil, errors.New("not a directory")
+	}
+	objects, err := s.conn.children(dir.id)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	for _, obj := range objects {
+		s.objects[path.Join(rel, obj.name)] = obj
+	}
+	s.mu.Unlock()
+	return objects, nil
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package storage
+
+import "errors"
+
+// errMTPUnsupported explains the alternative on systems without Windows
+// Portable Devices, where the desktop already mounts phones and cameras.
+var errMTPUnsupported = errors.New("mtp:// sources need Windows; elsewhere mount the device (e.g. with gvfs or jmtpfs) and use its mount point")
+
+func openMTP(string) (mtpConn, error) {
+	return nil, errMTPUnsupported
+}
+
+func mtpDeviceNames() ([]string, error) {
+	return nil, errMTPUnsupported
+}
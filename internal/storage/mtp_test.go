@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeDevice serves a tree of objects by ID and counts listings.
+type fakeDevice struct {
+	tree     map[string][]mtpObject
+	data     map[string]string
+	listings int
+}
+
+func (d *fakeDevice) children(id string) ([]mtpObject, error) {
+	d.listings++
+	return d.tree[id], nil
+}
+
+func (d *fakeDevice) open(id string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(d.data[id])), nil
+}
+
+func TestMTP(t *testing.T) {
+	device := &fakeDevice{
+		tree: map[string][]mtpObject{
+			mtpRootID: {{id: "s1", name: "Internal shared storage", dir: true}},
+			"s1":      {{id: "o1", name: "DCIM", dir: true}, {id: "o2", name: "Music", dir: true}},
+			"o1":      {{id: "o3", name: "Camera", dir: true}},
+			"o3":      {{id: "o5", name: "IMG_2.jpg", size: 2}, {id: "o4", name: "IMG_1.jpg", size: 4}},
+		},
+		data: map[string]string{"o4": "jpeg"},
+	}
+	s := newMTP("Pixel 7", device)
+	root := "mtp://Pixel 7/Internal shared storage/DCIM"
+
+	var files []string
+	err := Walk(s, root, func(path string, d fs.DirEntry) error {
+		if !d.IsDir() {
+			files = append(files, filepath.ToSlash(path))
+		}
+		return nil
+	})
+	want := "mtp:/Pixel 7/Internal shared storage/DCIM/Camera/IMG_1.jpg,mtp:/Pixel 7/Internal shared storage/DCIM/Camera/IMG_2.jpg"
+	if err != nil || strings.Join(files, ",") != want {
+		t.Fatalf("Walk() found %v, %v, want %s", files, err, want)
+	}
+	listings := device.listings
+
+	name := filepath.Join(root, "Camera", "IMG_1.jpg")
+	info, err := s.Stat(name)
+	if err != nil || info.Size() != 4 || info.IsDir() {
+		t.Errorf("Stat() = %v, %v", info, err)
+	}
+	r, err := s.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	if string(data) != "jpeg" {
+		t.Errorf("Open() read %q", data)
+	}
+	if device.listings != listings {
+		t.Errorf("Paths already listed should not be listed again, got %d more listings", device.listings-listings)
+	}
+
+	if _, err := s.Stat(filepath.Join(root, "Camera", "missing.jpg")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat() of a missing file = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := s.Open("mtp://Galaxy S24/DCIM/a.jpg"); err == nil {
+		t.Error("Paths on another device should fail")
+	}
+	device.tree[mtpRootID] = append(device.tree[mtpRootID], mtpObject{id: "s2", name: "SD card", dir: true})
+	described := newMTP("Pixel 7", device).describe()
+	if strings.Join(described.Storages, ",") != "Internal shared storage,SD card" ||
+		strings.Join(described.Sources, ",") != "mtp://Pixel 7/Internal shared storage/DCIM" {
+		t.Errorf("describe() = %+v, want the DCIM folder of the internal storage only", described)
+	}
+
+	if _, err := s.Create(filepath.Join(root, "new.jpg")); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Create() = %v, want errors.ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,445 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	ole32                = windows.NewLazySystemDLL("ole32.dll")
+	procCoCreateInstance = ole32.NewProc("CoCreateInstance")
+)
+
+// Class, interface and property IDs of Windows Portable Devices
+// (PortableDeviceApi.h, PortableDevice.h).
+var (
+	clsidPortableDeviceManager = mustGUID("{0af10cec-2ecd-4b92-9581-34f6ae0637f3}")
+	iidPortableDeviceManager   = mustGUID("{a1567595-4c2f-4574-a6fa-ecef917b9a40}")
+	clsidPortableDeviceFTM     = mustGUID("{f7c0039a-4762-488a-b4b3-760ef9a1ba9b}")
+	iidPortableDevice          = mustGUID("{625e2df8-6392-4cf0-9ad1-3cfa5f17775c}")
+	clsidPortableDeviceValues  = mustGUID("{0c15d503-d017-47ce-9016-7b3f978721cc}")
+	iidPortableDeviceValues    = mustGUID("{6848f6f2-3155-4f86-b6f5-263eeeab3143}")
+
+	contentTypeFolder           = mustGUID("{27e2e392-a111-48e0-ab0c-e17705a05f85}")
+	contentTypeFunctionalObject = mustGUID("{99ed0160-17ff-4c44-9d98-1d7a6f941921}")
+
+	objectProperties      = mustGUID("{ef6b490d-5cd8-437a-affc-da8b60ee4a3c}")
+	keyObjectName         = propertyKey{objectProperties, 4}
+	keyObjectContentType  = propertyKey{objectProperties, 7}
+	keyObjectSize         = propertyKey{objectProperties, 11}
+	keyObjectOriginalName = propertyKey{objectProperties, 12}
+	keyResourceDefault    = propertyKey{mustGUID("{e81e79be-34f0-41bf-b53f-f1a06ae87842}"), 0}
+)
+
+// Vtable slots of the methods used, counting the three of IUnknown.
+const (
+	methodRelease = 2
+
+	managerGetDevices            = 3 // IPortableDeviceManager
+	managerGetDeviceFriendlyName = 5
+
+	deviceOpen    = 3 // IPortableDevice
+	deviceContent = 5
+
+	contentEnumObjects = 3 // IPortableDeviceContent
+	contentProperties  = 4
+	contentTransfer    = 5
+
+	enumNext = 3 // IEnumPortableDeviceObjectIDs
+
+	propertiesGetValues = 5 // IPortableDeviceProperties
+
+	valuesGetStringValue               = 8 // IPortableDeviceValues
+	valuesGetUnsignedLargeIntegerValue = 14
+	valuesGetGUIDValue                 = 28
+
+	resourcesGetStream = 5 // IPortableDeviceResources
+
+	streamRead = 3 // IStream
+)
+
+const (
+	clsctxInprocServer = 0x1
+	stgmRead           = 0x0
+)
+
+// propertyKey is PROPERTYKEY.
+type propertyKey struct {
+	fmtid windows.GUID
+	pid   uint32
+}
+
+// comObject is a COM interface pointer: its first word is the vtable.
+type comObject struct {
+	vtbl *[32]uintptr
+}
+
+// call invokes a method, turning failed HRESULTs into errors.
+func (o *comObject) call(method int, args ...uintptr) error {
+	hr, _, _ := syscall.SyscallN(o.vtbl[method], append([]uintptr{uintptr(unsafe.Pointer(o))}, args...)...)
+	if int32(hr) < 0 {
+		return windows.Errno(hr)
+	}
+	return nil
+}
+
+func (o *comObject) release() {
+	if o != nil {
+		_, _, _ = syscall.SyscallN(o.vtbl[methodRelease], uintptr(unsafe.Pointer(o)))
+	}
+}
+
+func createInstance(clsid, iid *windows.GUID) (*comObject, error) {
+	var obj *comObject
+	hr, _, _ := procCoCreateInstance.Call(uintptr(unsafe.Pointer(clsid)), 0, clsctxInprocServer,
+		uintptr(unsafe.Pointer(iid)), uintptr(unsafe.Pointer(&obj)))
+	if int32(hr) < 0 {
+		return nil, windows.Errno(hr)
+	}
+	return obj, nil
+}
+
+func mustGUID(s string) windows.GUID {
+	g, err := windows.GUIDFromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// comCalls feeds a single OS thread that has joined the multithreaded
+// apartment. Goroutines move between threads, and a thread that never
+// initialized COM cannot use its objects, so every WPD call goes through
+// onCOMThread. Devices transfer one object at a time anyway.
+var comCalls = sync.OnceValue(func() chan func() {
+	calls := make(chan func())
+	go func() {
+		runtime.LockOSThread()
+		_ = windows.CoInitializeEx(0, windows.COINIT_MULTITHREADED)
+		for fn := range calls {
+			fn()
+		}
+	}()
+	return calls
+})
+
+func onCOMThread(fn func() error) error {
+	done := make(chan error, 1)
+	comCalls() <- func() { done <- fn() }
+	return <-done
+}
+
+// Open devices by friendly name. Sessions stay open for the life of the
+// process, since storages have no Close; a device is opened again after
+// an error, as when it was unplugged.
+var (
+	wpdMu      sync.Mutex
+	wpdDevices = map[string]*wpdDevice{}
+)
+
+// wpdDevice is a session with a device through Windows Portable Devices.
+type wpdDevice struct {
+	name       string
+	device     *comObject // IPortableDevice
+	content    *comObject // IPortableDeviceContent
+	properties *comObject // IPortableDeviceProperties
+	resources  *comObject // IPortableDeviceResources
+}
+
+func openMTP(name string) (mtpConn, error) {
+	wpdMu.Lock()
+	defer wpdMu.Unlock()
+	if d, ok := wpdDevices[name]; ok {
+		return d, nil
+	}
+	d := &wpdDevice{name: name}
+	if err := onCOMThread(d.connect); err != nil {
+		return nil, err
+	}
+	wpdDevices[name] = d
+	return d, nil
+}
+
+func mtpDeviceNames() ([]string, error) {
+	var names []string
+	err := onCOMThread(func() error {
+		manager, err := createInstance(&clsidPortableDeviceManager, &iidPortableDeviceManager)
+		if err != nil {
+			return fmt.Errorf("failed to start Windows Portable Devices: %w", err)
+		}
+		defer manager.release()
+		ids, err := deviceIDs(manager)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if name, err := friendlyName(manager, id); err == nil && name != "" {
+				names = append(names, name)
+			}
+		}
+		return nil
+	})
+	return names, err
+}
+
+// deviceIDs returns the Plug and Play IDs of the connected devices.
+func deviceIDs(manager *comObject) ([]string, error) {
+	var count uint32
+	if err := manager.call(managerGetDevices, 0, uintptr(unsafe.Pointer(&count))); err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	ptrs := make([]*uint16, count)
+	if err := manager.call(managerGetDevices, uintptr(unsafe.Pointer(&ptrs[0])), uintptr(unsafe.Pointer(&count))); err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+	ids := make([]string, 0, count)
+	for _, p := range ptrs[:count] {
+		ids = append(ids, windows.UTF16PtrToString(p))
+		windows.CoTaskMemFree(unsafe.Pointer(p))
+	}
+	return ids, nil
+}
+
+// friendlyName returns the name Explorer shows for a device.
+func friendlyName(manager *comObject, id string) (string, error) {
+	idp, err := windows.UTF16PtrFromString(id)
+	if err != nil {
+		return "", err
+	}
+	var n uint32
+	if err := manager.call(managerGetDeviceFriendlyName, uintptr(unsafe.Pointer(idp)), 0, uintptr(unsafe.Pointer(&n))); err != nil {
+		return "", err
+	}
+	if n == 0 {
+		return "", nil
+	}
+	buf := make([]uint16, n)
+	err = manager.call(managerGetDeviceFriendlyName, uintptr(unsafe.Pointer(idp)), uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&n)))
+	runtime.KeepAlive(idp)
+	if err != nil {
+		return "", err
+	}
+	return windows.UTF16ToString(buf), nil
+}
+
+// connect finds the device by name and opens a session with it.
+func (d *wpdDevice) connect() (err error) {
+	manager, err := createInstance(&clsidPortableDeviceManager, &iidPortableDeviceManager)
+	if err != nil {
+		return fmt.Errorf("failed to start Windows Portable Devices: %w", err)
+	}
+	defer manager.release()
+	ids, err := deviceIDs(manager)
+	if err != nil {
+		return err
+	}
+	var id string
+	for _, candidate := range ids {
+		if name, err := friendlyName(manager, candidate); err == nil && name == d.name {
+			id = candidate
+			break
+		}
+	}
+	if id == "" {
+		return fmt.Errorf("no device named %q is connected", d.name)
+	}
+
+	defer func() {
+		if err != nil {
+			d.resources.release()
+			d.properties.release()
+			d.content.release()
+			d.device.release()
+		}
+	}()
+	if d.device, err = createInstance(&clsidPortableDeviceFTM, &iidPortableDevice); err != nil {
+		return err
+	}
+	clientInfo, err := createInstance(&clsidPortableDeviceValues, &iidPortableDeviceValues)
+	if err != nil {
+		return err
+	}
+	defer clientInfo.release()
+	idp, err := windows.UTF16PtrFromString(id)
+	if err != nil {
+		return err
+	}
+	err = d.device.call(deviceOpen, uintptr(unsafe.Pointer(idp)), uintptr(unsafe.Pointer(clientInfo)))
+	runtime.KeepAlive(idp)
+	if err != nil {
+		// Phones refuse until they are unlocked and set to file transfer.
+		return fmt.Errorf("failed to open %s; unlock it and allow file transfer: %w", d.name, err)
+	}
+	if err = d.device.call(deviceContent, uintptr(unsafe.Pointer(&d.content))); err != nil {
+		return err
+	}
+	if err = d.content.call(contentProperties, uintptr(unsafe.Pointer(&d.properties))); err != nil {
+		return err
+	}
+	return d.content.call(contentTransfer, uintptr(unsafe.Pointer(&d.resources)))
+}
+
+// forget drops the session after an error, so the next use reconnects.
+// The objects are not released: other goroutines may still hold them.
+func (d *wpdDevice) forget() {
+	wpdMu.Lock()
+	defer wpdMu.Unlock()
+	if wpdDevices[d.name] == d {
+		delete(wpdDevices, d.name)
+	}
+}
+
+func (d *wpdDevice) children(id string) ([]mtpObject, error) {
+	var objects []mtpObject
+	err := onCOMThread(func() error {
+		parent, err := windows.UTF16PtrFromString(id)
+		if err != nil {
+			return err
+		}
+		var enum *comObject
+		err = d.content.call(contentEnumObjects, 0, uintptr(unsafe.Pointer(parent)), 0, uintptr(unsafe.Pointer(&enum)))
+		runtime.KeepAlive(parent)
+		if err != nil {
+			return err
+		}
+		defer enum.release()
+
+		ptrs := make([]*uint16, 64)
+		for {
+			var fetched uint32
+			if err := enum.call(enumNext, uintptr(len(ptrs)), uintptr(unsafe.Pointer(&ptrs[0])), uintptr(unsafe.Pointer(&fetched))); err != nil {
+				return err
+			}
+			if fetched == 0 {
+				return nil
+			}
+			ids := make([]string, fetched)
+			for i, p := range ptrs[:fetched] {
+				ids[i] = windows.UTF16PtrToString(p)
+				windows.CoTaskMemFree(unsafe.Pointer(p))
+			}
+			for _, id := range ids {
+				obj, err := d.object(id)
+				if err != nil {
+					return err
+				}
+				objects = append(objects, obj)
+			}
+		}
+	})
+	if err != nil {
+		d.forget()
+		return nil, err
+	}
+	return objects, nil
+}
+
+// object reads the properties of one object. Storages count as folders.
+func (d *wpdDevice) object(id string) (mtpObject, error) {
+	idp, err := windows.UTF16PtrFromString(id)
+	if err != nil {
+		return mtpObject{}, err
+	}
+	var values *comObject
+	err = d.properties.call(propertiesGetValues, uintptr(unsafe.Pointer(idp)), 0, uintptr(unsafe.Pointer(&values)))
+	runtime.KeepAlive(idp)
+	if err != nil {
+		return mtpObject{}, err
+	}
+	defer values.release()
+
+	obj := mtpObject{id: id}
+	var contentType windows.GUID
+	if values.call(valuesGetGUIDValue, uintptr(unsafe.Pointer(&keyObjectContentType)), uintptr(unsafe.Pointer(&contentType))) == nil {
+		obj.dir = contentType == contentTypeFolder || contentType == contentTypeFunctionalObject
+	}
+	// The display name of a photo often lacks its extension.
+	obj.name = stringValue(values, &keyObjectOriginalName)
+	if obj.name == "" {
+		obj.name = stringValue(values, &keyObjectName)
+	}
+	if obj.name == "" {
+		obj.name = id
+	}
+	var size uint64
+	if !obj.dir && values.call(valuesGetUnsignedLargeIntegerValue, uintptr(unsafe.Pointer(&keyObjectSize)), uintptr(unsafe.Pointer(&size))) == nil {
+		obj.size = int64(size)
+	}
+	return obj, nil
+}
+
+// stringValue returns a string property, or "" if the object has none.
+func stringValue(values *comObject, key *propertyKey) string {
+	var p *uint16
+	if values.call(valuesGetStringValue, uintptr(unsafe.Pointer(key)), uintptr(unsafe.Pointer(&p))) != nil || p == nil {
+		return ""
+	}
+	defer windows.CoTaskMemFree(unsafe.Pointer(p))
+	return windows.UTF16PtrToString(p)
+}
+
+func (d *wpdDevice) open(id string) (io.ReadCloser, error) {
+	var stream *comObject
+	err := onCOMThread(func() error {
+		idp, err := windows.UTF16PtrFromString(id)
+		if err != nil {
+			return err
+		}
+		var optimal uint32
+		err = d.resources.call(resourcesGetStream, uintptr(unsafe.Pointer(idp)), uintptr(unsafe.Pointer(&keyResourceDefault)),
+			stgmRead, uintptr(unsafe.Pointer(&optimal)), uintptr(unsafe.Pointer(&stream)))
+		runtime.KeepAlive(idp)
+		return err
+	})
+	if err != nil {
+		d.forget()
+		return nil, err
+	}
+	return &wpdReader{stream: stream}, nil
+}
+
+// wpdReader reads the content of a file through its IStream.
+type wpdReader struct {
+	stream *comObject
+}
+
+func (r *wpdReader) Read(p []byte) (int, error) {
+	if r.stream == nil {
+		return 0, errors.New("read after close")
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	var n uint32
+	err := onCOMThread(func() error {
+		err := r.stream.call(streamRead, uintptr(unsafe.Pointer(&p[0])), uintptr(len(p)), uintptr(unsafe.Pointer(&n)))
+		runtime.KeepAlive(p)
+		return err
+	})
+	if err != nil {
+		return int(n), err
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return int(n), nil
+}
+
+func (r *wpdReader) Close() error {
+	stream := r.stream
+	r.stream = nil
+	return onCOMThread(func() error {
+		stream.release()
+		return nil
+	})
+}
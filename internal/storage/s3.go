@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"copy-image/internal/config"
+)
+
+// defaultPartSize is the multipart upload part size when none is set. At
+// 16 MB with at most 10,000 parts, objects can reach 160 GB; each upload
+// in flight buffers one part.
+const defaultPartSize = 16 << 20
+
+// S3 stores files as objects of an S3-compatible bucket. Paths are
+// s3://bucket/key URLs (or their filepath.Join form, s3:/bucket/key);
+// folders are key prefixes ending in "/".
+type S3 struct {
+	client *minio.Client
+	bucket string
+	opts   config.S3Options
+}
+
+// NewS3 connects to the bucket of the s3:// URL root. No request is made
+// until the storage is used.
+func NewS3(root string, opts *config.S3Options) (*S3, error) {
+	bucket, _, err := config.ParseS3(root)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &config.S3Options{}
+	}
+	endpoint := opts.Endpoint
+	if endpoint == "" {
+		endpoint = "s3.amazonaws.com"
+	}
+
+	creds := credentials.NewFileAWSCredentials("", opts.Profile)
+	if opts.Profile == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+		})
+	}
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: !opts.Insecure,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", endpoint, err)
+	}
+	return &S3{client: client, bucket: bucket, opts: *opts}, nil
+}
+
+// key returns the object key of path, checking it is in the bucket.
+func (s *S3) key(p string) (string, error) {
+	bucket, key, err := config.ParseS3(p)
+	if err != nil {
+		return "", err
+	}
+	if bucket != s.bucket {
+		return "", fmt.Errorf("%s is not in bucket %s", p, s.bucket)
+	}
+	return key, nil
+}
+
+// Open reads the object at path.
+func (s *S3) Open(p string) (io.ReadCloser, error) {
+	key, err := s.key(p)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(context.Background(), s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.pathError("open", p, err)
+	}
+	// GetObject is lazy; Stat makes a missing object fail here.
+	if _, err := obj.Stat(); err != nil {
+		_ = obj.Close()
+		return nil, s.pathError("open", p, err)
+	}
+	return obj, nil
+}
+
+// Create uploads what is written to the object at path, in parts for
+// large files. The object appears when Close returns without error.
+func (s *S3) Create(p string) (File, error) {
+	key, err := s.key(p)
+	if err != nil {
+		return nil, err
+	}
+	partSize := uint64(defaultPartSize)
+	if s.opts.PartSizeMB > 0 {
+		partSize = uint64(s.opts.PartSizeMB) << 20
+	}
+
+	r, w := io.Pipe()
+	u := &pipeUpload{w: w, done: make(chan error, 1)}
+	go func() {
+		_, err := s.client.PutObject(context.Background(), s.bucket, key, r, -1, minio.PutObjectOptions{
+			StorageClass: s.opts.StorageClass,
+			PartSize:     partSize,
+		})
+		// Unblock writers if the upload failed early.
+		_ = r.CloseWithError(err)
+		u.done <- s.pathError("create", p, err)
+	}()
+	return u, nil
+}
+
+// Stat describes the object at path, or the folder when objects exist
+// below it.
+func (s *S3) Stat(p string) (fs.FileInfo, error) {
+	key, err := s.key(p)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		// The bucket root; a missing bucket fails on first use instead.
+		return memInfo{name: s.bucket, dir: true}, nil
+	}
+	name := path.Base(key)
+	info, err := s.client.StatObject(context.Background(), s.bucket, key, minio.StatObjectOptions{})
+	if err == nil {
+		return memInfo{name: name, size: info.Size, modTime: info.LastModified}, nil
+	}
+	if !isNotFound(err) {
+		return nil, s.pathError("stat", p, err)
+	}
+
+	// A folder exists as long as some object is below it.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: dirKey(key), MaxKeys: 1}) {
+		if obj.Err != nil {
+			return nil, s.pathError("stat", p, obj.Err)
+		}
+		return memInfo{name: name, dir: true}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+}
+
+// List returns the objects and folders directly below dir.
+func (s *S3) List(dir string) ([]fs.DirEntry, error) {
+	key, err := s.key(dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := dirKey(key)
+	var entries []fs.DirEntry
+	for obj := range s.client.ListObjects(context.Background(), s.bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if obj.Err != nil {
+			return nil, s.pathError("readdir", dir, obj.Err)
+		}
+		name := strings.TrimPrefix(obj.Key, prefix)
+		switch {
+		case name == "":
+			// The folder marker some tools create.
+		case strings.HasSuffix(name, "/"):
+			entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: strings.TrimSuffix(name, "/"), dir: true}))
+		default:
+			entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: name, size: obj.Size, modTime: obj.LastModified}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Remove deletes the object at path.
+func (s *S3) Remove(p string) error {
+	key, err := s.key(p)
+	if err != nil {
+		return err
+	}
+	return s.pathError("remove", p, s.client.RemoveObject(context.Background(), s.bucket, key, minio.RemoveObjectOptions{}))
+}
+
+// pathError wraps an S3 error like the os package does, with missing
+// objects matching fs.ErrNotExist and denied access fs.ErrPermission.
+func (s *S3) pathError(op, p string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case isNotFound(err):
+		err = fmt.Errorf("%w (%v)", fs.ErrNotExist, err)
+	case minio.ToErrorResponse(err).StatusCode == http.StatusForbidden:
+		err = fmt.Errorf("%w (%v)", fs.ErrPermission, err)
+	}
+	return &fs.PathError{Op: op, Path: p, Err: err}
+}
+
+// isNotFound reports whether err means the object or bucket is missing.
+func isNotFound(err error) bool {
+	switch minio.ToErrorResponse(err).Code {
+	case "NoSuchKey", "NoSuchBucket", "NotFound":
+		return true
+	}
+	return false
+}
+
+// dirKey returns the key prefix of the objects in folder key.
+func dirKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	return key + "/"
+}
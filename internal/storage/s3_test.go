@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"copy-image/internal/config"
+)
+
+func TestForDestination(t *testing.T) {
+	s, err := ForDestination(&config.Config{Destination: t.TempDir()})
+	if _, ok := s.(Local); err != nil || !ok {
+		t.Errorf("ForDestination(folder) = %T, %v, want Local", s, err)
+	}
+
+	s, err = ForDestination(&config.Config{
+		Destination: "s3://archive/studio",
+		S3:          &config.S3Options{Endpoint: "s3.wasabisys.com", Region: "us-east-1"},
+	})
+	if err != nil {
+		t.Fatalf("ForDestination(s3://) error = %v", err)
+	}
+	s3, ok := s.(*S3)
+	if !ok || s3.bucket != "archive" {
+		t.Fatalf("ForDestination(s3://) = %#v, want an S3 storage for the bucket", s)
+	}
+
+	// The copier joins file names below the URL with filepath.Join.
+	key, err := s3.key(filepath.Join("s3://archive/studio", "2024", "a.jpg"))
+	if err != nil || key != "studio/2024/a.jpg" {
+		t.Errorf("key() = %q, %v, want studio/2024/a.jpg", key, err)
+	}
+	if _, err := s3.key("s3://other/a.jpg"); err == nil {
+		t.Error("key() of another bucket should fail")
+	}
+
+	if _, err := ForDestination(&config.Config{Destination: "s3://"}); err == nil {
+		t.Error("ForDestination() without a bucket should fail")
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	boom := errors.New("boom")
+	s := Unavailable(boom)
+	if _, err := s.Stat("a"); !errors.Is(err, boom) {
+		t.Errorf("Stat() error = %v, want the setup error", err)
+	}
+	if Exists(s, "a") || IsDir(s, "a") {
+		t.Error("nothing should exist in an unavailable storage")
+	}
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"copy-image/internal/config"
+)
+
+// SFTP reads and writes files on an SSH server. Paths are
+// sftp://host[:port]/path URLs (or their filepath.Join form).
+type SFTP struct {
+	addr   string
+	client *sftp.Client
+}
+
+// NewSFTP connects to the server of the sftp:// URL root, checking its
+// key against known_hosts.
+func NewSFTP(root string, opts *config.SFTPOptions) (*SFTP, error) {
+	addr, _, err := config.ParseSFTP(root)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &config.SFTPOptions{}
+	}
+	home, _ := os.UserHomeDir()
+
+	knownHostsFile := opts.KnownHosts
+	if knownHostsFile == "" {
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKey, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+
+	username, password := opts.Credentials()
+	var auth []ssh.AuthMethod
+	if password != "" {
+		auth = append(auth, ssh.Password(password))
+	}
+	keyFiles := []string{opts.KeyFile}
+	if opts.KeyFile == "" {
+		keyFiles = []string{
+			filepath.Join(home, ".ssh", "id_ed25519"),
+			filepath.Join(home, ".ssh", "id_ecdsa"),
+			filepath.Join(home, ".ssh", "id_rsa"),
+		}
+	}
+	for _, name := range keyFiles {
+		signer, err := readKey(name)
+		if err != nil {
+			if opts.KeyFile != "" {
+				return nil, err
+			}
+			continue
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	conn, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            username,
+		Auth:            auth,
+		HostKeyCallback: hostKey,
+		Timeout:         ftpTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to start sftp on %s: %w", addr, err)
+	}
+	return &SFTP{addr: addr, client: client}, nil
+}
+
+// readKey loads an unencrypted private key.
+func readKey(name string) (ssh.Signer, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", name, err)
+	}
+	return signer, nil
+}
+
+// remote returns the path on the server of p, checking it is on this
+// server.
+func (s *SFTP) remote(p string) (string, error) {
+	addr, remote, err := config.ParseSFTP(p)
+	if err != nil {
+		return "", err
+	}
+	if addr != s.addr {
+		return "", fmt.Errorf("%s is not on server %s", p, s.addr)
+	}
+	return remote, nil
+}
+
+// Open opens the file for reading.
+func (s *SFTP) Open(p string) (io.ReadCloser, error) {
+	remote, err := s.remote(p)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.client.Open(remote)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: p, Err: err}
+	}
+	return f, nil
+}
+
+// Create creates or truncates the file, creating its folder as needed.
+func (s *SFTP) Create(p string) (File, error) {
+	remote, err := s.remote(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.client.MkdirAll(path.Dir(remote)); err != nil {
+		return nil, &fs.PathError{Op: "mkdir", Path: p, Err: err}
+	}
+	f, err := s.client.Create(remote)
+	if err != nil {
+		return nil, &fs.PathError{Op: "create", Path: p, Err: err}
+	}
+	return f, nil
+}
+
+// Stat describes the file or folder.
+func (s *SFTP) Stat(p string) (fs.FileInfo, error) {
+	remote, err := s.remote(p)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.client.Stat(remote)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: p, Err: err}
+	}
+	return info, nil
+}
+
+// List returns the entries of dir sorted by name. The listing carries
+// each file's size and type, so filtering needs no further requests.
+func (s *SFTP) List(dir string) ([]fs.DirEntry, error) {
+	remote, err := s.remote(dir)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := s.client.ReadDir(remote)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: err}
+	}
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = fs.FileInfoToDirEntry(info)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Remove deletes the file or empty folder.
+func (s *SFTP) Remove(p string) error {
+	remote, err := s.remote(p)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Remove(remote); err != nil {
+		return &fs.PathError{Op: "remove", Path: p, Err: err}
+	}
+	return nil
+}
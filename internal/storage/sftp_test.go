@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+
+	"copy-image/internal/config"
+)
+
+// pipeConn joins the two halves of an in-process connection.
+type pipeConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// newTestSFTP returns an SFTP storage served from the local file system
+// over a pipe, without SSH.
+func newTestSFTP(t *testing.T) *SFTP {
+	t.Helper()
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	server, err := sftp.NewServer(pipeConn{serverR, serverW})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = server.Serve() }()
+	client, err := sftp.NewClientPipe(clientR, clientW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		// Closing the server first ends the client's reader.
+		_ = server.Close()
+		_ = client.Close()
+	})
+	return &SFTP{addr: "nas:22", client: client}
+}
+
+func TestSFTP(t *testing.T) {
+	if filepath.Separator != '/' {
+		t.Skip("the test server serves the local file system by its slash paths")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.jpg"), []byte("jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := newTestSFTP(t)
+	root := "sftp://nas" + dir
+
+	entries, err := s.List(root)
+	if err != nil || len(entries) != 1 || entries[0].Name() != "a.jpg" || entries[0].IsDir() {
+		t.Fatalf("List() = %v, %v, want a.jpg", entries, err)
+	}
+
+	r, err := s.Open(filepath.Join(root, "a.jpg"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	_ = r.Close()
+	if string(data) != "jpeg" {
+		t.Errorf("Open() read %q, want jpeg", data)
+	}
+
+	f, err := s.Create(filepath.Join(root, "sub", "b.jpg"))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_, _ = f.Write([]byte("copy"))
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if info, err := s.Stat(filepath.Join(root, "sub", "b.jpg")); err != nil || info.Size() != 4 {
+		t.Errorf("Stat() = %v, %v, want 4 bytes", info, err)
+	}
+	if err := s.Remove(filepath.Join(root, "sub", "b.jpg")); err != nil {
+		t.Errorf("Remove() error = %v", err)
+	}
+	if Exists(s, filepath.Join(root, "sub", "b.jpg")) {
+		t.Error("Remove() should delete the file")
+	}
+
+	if _, err := s.Stat("sftp://other" + dir); err == nil {
+		t.Error("Stat() of another server should fail")
+	}
+}
+
+func TestForSource(t *testing.T) {
+	s, err := ForSource(&config.Config{Source: t.TempDir()})
+	if _, ok := s.(Local); err != nil || !ok {
+		t.Errorf("ForSource(folder) = %T, %v, want Local", s, err)
+	}
+	s, err = ForSource(&config.Config{Source: "s3://card-dumps/2024"})
+	if _, ok := s.(*S3); err != nil || !ok {
+		t.Errorf("ForSource(s3://) = %T, %v, want S3", s, err)
+	}
+	_, err = ForSource(&config.Config{
+		Source:     "sftp://nas/photos",
+		SourceSFTP: &config.SFTPOptions{Username: "copy", KnownHosts: filepath.Join(t.TempDir(), "missing")},
+	})
+	if err == nil {
+		t.Error("ForSource(sftp://) should fail without known hosts")
+	}
+}
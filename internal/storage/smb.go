@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"sync"
+
+	"copy-image/internal/config"
+	"copy-image/internal/utils"
+)
+
+// SMB is a \\server\share destination connected with its own credentials.
+// Files go through the UNC path like Local; when the session drops in the
+// middle of a batch (the NAS rebooted, the VPN reconnected) an operation
+// failing with a network error reconnects the share and is tried once more.
+type SMB struct {
+	Local
+	share string
+	opts  config.SMBOptions
+
+	mu         sync.Mutex
+	generation int // bumped on every reconnect
+	connect    func(reconnect bool) error
+}
+
+// NewSMB connects the share holding path with opts.
+func NewSMB(path string, opts config.SMBOptions) (*SMB, error) {
+	share := config.ShareRoot(path)
+	s := &SMB{share: share, opts: opts}
+	s.connect = func(reconnect bool) error {
+		username, password := opts.Credentials()
+		return connectShare(share, username, password, reconnect)
+	}
+	if err := s.connect(false); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Open opens the file for reading.
+func (s *SMB) Open(path string) (r io.ReadCloser, err error) {
+	err = s.retry(func() (err error) {
+		r, err = s.Local.Open(path)
+		return err
+	})
+	return r, err
+}
+
+// Create creates or truncates the file, creating its folder as needed.
+// A session dropping during the write fails that file; the copier's own
+// retry then reaches Create again, which reconnects.
+func (s *SMB) Create(path string) (f File, err error) {
+	err = s.retry(func() (err error) {
+		f, err = s.Local.Create(path)
+		return err
+	})
+	return f, err
+}
+
+// Stat describes the file.
+func (s *SMB) Stat(path string) (info fs.FileInfo, err error) {
+	err = s.retry(func() (err error) {
+		info, err = s.Local.Stat(path)
+		return err
+	})
+	return info, err
+}
+
+// List returns the entries of dir sorted by name.
+func (s *SMB) List(dir string) (entries []fs.DirEntry, err error) {
+	err = s.retry(func() (err error) {
+		entries, err = s.Local.List(dir)
+		return err
+	})
+	return entries, err
+}
+
+// ListBatches calls fn with the entries of dir, n at a time. Once entries
+// were passed on, a dropped session fails the listing: starting over would
+// pass them again.
+func (s *SMB) ListBatches(dir string, n int, fn func([]fs.DirEntry) error) error {
+	listed := false
+	var err error
+	return s.retry(func() error {
+		if listed {
+			return err
+		}
+		err = s.Local.ListBatches(dir, n, func(entries []fs.DirEntry) error {
+			listed = true
+			return fn(entries)
+		})
+		return err
+	})
+}
+
+// Remove deletes the file or empty folder.
+func (s *SMB) Remove(path string) error {
+	return s.retry(func() error { return s.Local.Remove(path) })
+}
+
+// retry runs op, and once more after reconnecting when it fails with a
+// network error.
+func (s *SMB) retry(op func() error) error {
+	s.mu.Lock()
+	seen := s.generation
+	s.mu.Unlock()
+
+	err := op()
+	if !errors.Is(utils.Classify(err), utils.ErrNetwork) {
+		return err
+	}
+	if cerr := s.reconnect(seen); cerr != nil {
+		return errors.Join(err, cerr)
+	}
+	return op()
+}
+
+// reconnect re-establishes the session unless another worker already did
+// since seen, so a drop seen by every worker at once reconnects only once.
+func (s *SMB) reconnect(seen int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != seen {
+		return nil
+	}
+	if err := s.connect(true); err != nil {
+		return err
+	}
+	s.generation++
+	return nil
+}
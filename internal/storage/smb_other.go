@@ -0,0 +1,11 @@
+//go:build !windows
+
+package storage
+
+import "fmt"
+
+// connectShare is only supported on Windows; elsewhere the share is
+// mounted by the system and its mount point used as the destination.
+func connectShare(share, _, _ string, _ bool) error {
+	return fmt.Errorf("connecting to %s with smb credentials needs Windows; mount the share and use its mount point instead", share)
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"copy-image/internal/utils"
+)
+
+// networkErr is what a write to a share that dropped fails with.
+func networkErr() error {
+	return &utils.FileError{Kind: utils.ErrNetwork, Err: errors.New("the network name is no longer available")}
+}
+
+func TestSMBReconnectsOnNetworkError(t *testing.T) {
+	connects := 0
+	s := &SMB{share: `\\nas\photos`, connect: func(reconnect bool) error {
+		if !reconnect {
+			t.Error("retry should reconnect, not connect")
+		}
+		connects++
+		return nil
+	}}
+
+	calls := 0
+	err := s.retry(func() error {
+		calls++
+		if calls == 1 {
+			return networkErr()
+		}
+		return nil
+	})
+	if err != nil || calls != 2 || connects != 1 {
+		t.Errorf("retry() = %v after %d calls and %d reconnects, want success after 2 and 1", err, calls, connects)
+	}
+
+	// Other errors are returned as they are.
+	calls = 0
+	err = s.retry(func() error {
+		calls++
+		return fs.ErrNotExist
+	})
+	if !errors.Is(err, fs.ErrNotExist) || calls != 1 || connects != 1 {
+		t.Errorf("retry() = %v after %d calls and %d reconnects, want not found at once", err, calls, connects)
+	}
+
+	// A failed reconnect is reported with the original error.
+	refused := errors.New("logon failure")
+	s.connect = func(bool) error { return refused }
+	err = s.retry(func() error { return networkErr() })
+	if !errors.Is(err, refused) {
+		t.Errorf("retry() = %v, want the reconnect error", err)
+	}
+}
+
+func TestSMBReconnectsOnce(t *testing.T) {
+	connects := 0
+	s := &SMB{connect: func(bool) error {
+		connects++
+		return nil
+	}}
+
+	// Two workers saw the same drop; only the first reconnects.
+	_ = s.reconnect(0)
+	_ = s.reconnect(0)
+	if connects != 1 {
+		t.Errorf("reconnected %d times, want 1", connects)
+	}
+}
+
+func TestSMBUsesLocalPaths(t *testing.T) {
+	s := &SMB{connect: func(bool) error { return nil }}
+	path := filepath.Join(t.TempDir(), "a", "b.jpg")
+	f, err := s.Create(path)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_ = f.Close()
+	if !Exists(s, path) {
+		t.Error("Create() should write through the share path")
+	}
+}
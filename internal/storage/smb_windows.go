@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	mpr                        = windows.NewLazySystemDLL("mpr.dll")
+	procWNetAddConnection2W    = mpr.NewProc("WNetAddConnection2W")
+	procWNetCancelConnection2W = mpr.NewProc("WNetCancelConnection2W")
+)
+
+// Constants of WNetAddConnection2 (winnetwk.h).
+const (
+	resourceTypeDisk = 0x1
+	connectTemporary = 0x4
+)
+
+// netResource is NETRESOURCEW.
+type netResource struct {
+	scope       uint32
+	typ         uint32
+	displayType uint32
+	usage       uint32
+	localName   *uint16
+	remoteName  *uint16
+	comment     *uint16
+	provider    *uint16
+}
+
+// connectShare establishes a session to share without a drive letter, like
+// `net use \\server\share /user:name`. The connection is temporary: it is
+// not restored at the next logon. With reconnect the stale session is
+// dropped first.
+func connectShare(share, username, password string, reconnect bool) error {
+	remote, err := syscall.UTF16PtrFromString(share)
+	if err != nil {
+		return err
+	}
+	if reconnect {
+		// Force: a dead session may still have open files.
+		_, _, _ = procWNetCancelConnection2W.Call(uintptr(unsafe.Pointer(remote)), 0, 1)
+	}
+
+	var user, pass *uint16
+	if username != "" {
+		if user, err = syscall.UTF16PtrFromString(username); err != nil {
+			return err
+		}
+	}
+	if password != "" {
+		if pass, err = syscall.UTF16PtrFromString(password); err != nil {
+			return err
+		}
+	}
+	nr := netResource{typ: resourceTypeDisk, remoteName: remote}
+	r, _, _ := procWNetAddConnection2W.Call(uintptr(unsafe.Pointer(&nr)),
+		uintptr(unsafe.Pointer(pass)), uintptr(unsafe.Pointer(user)), connectTemporary)
+	switch errno := syscall.Errno(r); {
+	case r == 0:
+		return nil
+	case errors.Is(errno, windows.ERROR_SESSION_CREDENTIAL_CONFLICT):
+		return fmt.Errorf("failed to connect to %s: already connected as another user; run `net use %s /delete` first: %w", share, share, errno)
+	default:
+		return fmt.Errorf("failed to connect to %s: %w", share, errno)
+	}
+}
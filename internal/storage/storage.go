@@ -0,0 +1,270 @@
+// Package storage abstracts the file systems the copier reads from and
+// writes to, so that sources and destinations other than local folders
+// (and in-memory ones in tests) can plug in. Paths use the OS separator
+// on every backend.
+package storage
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"copy-image/internal/config"
+)
+
+// Storage is a file system holding sources or destinations.
+type Storage interface {
+	// Open opens the file at path for reading.
+	Open(path string) (io.ReadCloser, error)
+	// Create creates or truncates the file at path, creating its folder
+	// as needed.
+	Create(path string) (File, error)
+	// Stat describes the file or folder at path. Missing paths return an
+	// error matching fs.ErrNotExist.
+	Stat(path string) (fs.FileInfo, error)
+	// List returns the entries of the folder at path, sorted by name.
+	List(dir string) ([]fs.DirEntry, error)
+	// Remove deletes the file or empty folder at path.
+	Remove(path string) error
+}
+
+// File is a file being written. Its content is only guaranteed to be
+// stored once Close returns without error.
+type File interface {
+	io.WriteCloser
+	// Sync flushes the content written so far to stable storage.
+	Sync() error
+}
+
+// Resumer is implemented by storages that can continue an interrupted
+// upload instead of sending a large file again from the start.
+type Resumer interface {
+	// Resume opens the file at path for writing from offset, keeping the
+	// first offset bytes already stored.
+	Resume(path string, offset int64) (File, error)
+}
+
+// Aborter is implemented by files that can be given up on, such as
+// uploads, so that a copy that failed halfway is not stored as if it
+// were complete.
+type Aborter interface {
+	// Abort ends the file with err instead of storing it.
+	Abort(err error) error
+}
+
+// Committer is implemented by storages whose files only take their place
+// at the end of a batch, such as zip archives.
+type Committer interface {
+	// Commit stores the files written since the last call.
+	Commit() error
+}
+
+// BatchLister is implemented by storages that can list a folder a few
+// entries at a time, such as local folders and the shares mounted on them.
+// Listing a folder of 300k files on an SMB share at once stalls until all
+// of them arrived and holds them all in memory.
+type BatchLister interface {
+	// ListBatches calls fn with the entries of dir, at most n at a time,
+	// in the order the file system returns them. An error returned by fn
+	// stops the listing and is returned.
+	ListBatches(dir string, n int, fn func([]fs.DirEntry) error) error
+}
+
+// ListBatches lists dir with s's ListBatches, or with List in a single
+// batch for storages that cannot list part of a folder.
+func ListBatches(s Storage, dir string, n int, fn func([]fs.DirEntry) error) error {
+	if b, ok := s.(BatchLister); ok {
+		return b.ListBatches(dir, n, fn)
+	}
+	entries, err := s.List(dir)
+	if err != nil {
+		return err
+	}
+	return fn(entries)
+}
+
+// IsDir reports whether path is an existing folder in s.
+func IsDir(s Storage, path string) bool {
+	info, err := s.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// Exists reports whether path exists in s.
+func Exists(s Storage, path string) bool {
+	_, err := s.Stat(path)
+	return err == nil
+}
+
+// Walk calls fn for every file and folder below root, in lexical order,
+// like filepath.WalkDir without the call for root itself. fn may return
+// filepath.SkipDir to skip a folder; any other error stops the walk.
+func Walk(s Storage, root string, fn func(path string, d fs.DirEntry) error) error {
+	entries, err := s.List(root)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(root, entry.Name())
+		err := fn(path, entry)
+		if errors.Is(err, filepath.SkipDir) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if err := Walk(s, path, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Glob returns the paths in s matching pattern, like filepath.Glob.
+func Glob(s Storage, pattern string) ([]string, error) {
+	if _, ok := s.(Local); ok {
+		return filepath.Glob(pattern)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	if !hasMeta(pattern) {
+		if !Exists(s, pattern) {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+
+	dir, file := filepath.Split(pattern)
+	dir = filepath.Clean(dir)
+	dirs := []string{dir}
+	if hasMeta(dir) {
+		var err error
+		if dirs, err = Glob(s, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []string
+	for _, d := range dirs {
+		entries, err := s.List(d)
+		if err != nil {
+			// Like filepath.Glob, unreadable folders just do not match.
+			continue
+		}
+		for _, entry := range entries {
+			if ok, _ := filepath.Match(file, entry.Name()); ok {
+				matches = append(matches, filepath.Join(d, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// hasMeta reports whether path contains glob characters.
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, `*?[`)
+}
+
+// ForSource returns the storage holding the sources of cfg: the web for a
+// list of URLs, an S3 bucket for s3:// URLs, an SSH server for sftp://
+// URLs, a phone or camera for mtp:// URLs, and the local file system
+// otherwise. Validate makes sure all
+// sources are in one place.
+func ForSource(cfg *config.Config) (Storage, error) {
+	if cfg.URLsFrom != "" {
+		return NewHTTP(), nil
+	}
+	patterns := cfg.SourcePatterns()
+	if len(patterns) == 0 {
+		return Local{}, nil
+	}
+	switch source := patterns[0]; {
+	case config.IsS3(source):
+		return NewS3(source, cfg.SourceS3)
+	case config.IsSFTP(source):
+		return NewSFTP(source, cfg.SourceSFTP)
+	case config.IsMTP(source):
+		return NewMTP(source)
+	}
+	return Local{}, nil
+}
+
+// ForDestination returns the storage holding the destination of cfg: a
+// zip archive for zip destinations, an S3 bucket for s3:// URLs, an FTP server for ftp:// URLs, a share
+// connected with the smb credentials for UNC paths that have them, and
+// the local file system otherwise.
+func ForDestination(cfg *config.Config) (Storage, error) {
+	switch {
+	case cfg.IsArchive():
+		return NewZip(cfg.Destination, cfg.Zip), nil
+	case config.IsS3(cfg.Destination):
+		return NewS3(cfg.Destination, cfg.S3)
+	case config.IsFTP(cfg.Destination):
+		return NewFTP(cfg.Destination, cfg.FTP)
+	case cfg.SMB != nil && config.ShareRoot(cfg.Destination) != "":
+		return NewSMB(cfg.Destination, *cfg.SMB)
+	}
+	return Local{}, nil
+}
+
+// Unavailable returns a storage failing every operation with err, for
+// destinations that could not be set up, so their files fail one by one
+// like any other error.
+func Unavailable(err error) Storage {
+	return unavailable{err}
+}
+
+type unavailable struct{ err error }
+
+func (u unavailable) Open(string) (io.ReadCloser, error) { return nil, u.err }
+func (u unavailable) Create(string) (File, error)        { return nil, u.err }
+func (u unavailable) Stat(string) (fs.FileInfo, error)   { return nil, u.err }
+func (u unavailable) List(string) ([]fs.DirEntry, error) { return nil, u.err }
+func (u unavailable) Remove(string) error                { return u.err }
+
+// pipeUpload streams what is written to an upload running in another
+// goroutine, which reports its result on done.
+type pipeUpload struct {
+	w      *io.PipeWriter
+	done   chan error
+	err    error
+	closed bool
+}
+
+func (u *pipeUpload) Write(p []byte) (int, error) {
+	return u.w.Write(p)
+}
+
+// Sync does nothing: the upload completes when the file is closed.
+func (u *pipeUpload) Sync() error {
+	return nil
+}
+
+// Close finishes the upload and waits for its result.
+func (u *pipeUpload) Close() error {
+	if u.closed {
+		return u.err
+	}
+	u.closed = true
+	_ = u.w.Close()
+	u.err = <-u.done
+	return u.err
+}
+
+// Abort fails the upload with err, so that what was written so far is not
+// stored.
+func (u *pipeUpload) Abort(err error) error {
+	if u.closed {
+		return u.err
+	}
+	u.closed = true
+	_ = u.w.CloseWithError(err)
+	u.err = <-u.done
+	return u.err
+}
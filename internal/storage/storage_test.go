@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	root := filepath.Join(string(filepath.Separator), "card")
+	for _, name := range []string{"b.jpg", "a.jpg", filepath.Join("DCIM", "c.jpg")} {
+		if err := m.WriteFile(filepath.Join(root, name), []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := m.List(root)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"DCIM", "a.jpg", "b.jpg"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("List() = %v, want %v", names, want)
+	}
+	if !entries[0].IsDir() || entries[1].IsDir() {
+		t.Errorf("List() entry kinds are wrong: %v", entries)
+	}
+
+	if !IsDir(m, filepath.Join(root, "DCIM")) || IsDir(m, filepath.Join(root, "a.jpg")) {
+		t.Error("IsDir() is wrong")
+	}
+	if _, err := m.Stat(filepath.Join(root, "missing")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat() of a missing file error = %v, want fs.ErrNotExist", err)
+	}
+
+	f, err := m.Create(filepath.Join(root, "out", "d.jpg"))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_, _ = f.Write([]byte("data"))
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := m.Open(filepath.Join(root, "out", "d.jpg"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if data, _ := io.ReadAll(r); string(data) != "data" {
+		t.Errorf("read %q, want the written content", data)
+	}
+
+	if err := m.Remove(filepath.Join(root, "out", "d.jpg")); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if Exists(m, filepath.Join(root, "out")) {
+		t.Error("an emptied folder still exists")
+	}
+}
+
+func TestFS(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "card")
+	s := NewFS(fstest.MapFS{
+		"a.jpg":       {Data: []byte("a")},
+		"DCIM/b.jpg":  {Data: []byte("bb")},
+		"DCIM/c.jpg":  {Data: []byte("ccc")},
+		"MISC/x.json": {Data: []byte("{}")},
+	}, root)
+
+	entries, err := s.List(filepath.Join(root, "DCIM"))
+	if err != nil || len(entries) != 2 || entries[0].Name() != "b.jpg" {
+		t.Fatalf("List() = %v, %v", entries, err)
+	}
+	if info, err := s.Stat(filepath.Join(root, "DCIM", "c.jpg")); err != nil || info.Size() != 3 {
+		t.Errorf("Stat() = %v, %v", info, err)
+	}
+	if !IsDir(s, root) {
+		t.Error("the root is not a folder")
+	}
+	r, err := s.Open(filepath.Join(root, "a.jpg"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if data, _ := io.ReadAll(r); string(data) != "a" {
+		t.Errorf("read %q, want the file's content", data)
+	}
+	_ = r.Close()
+
+	if _, err := s.Stat(filepath.Join(string(filepath.Separator), "a.jpg")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat() outside the root error = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := s.Create(filepath.Join(root, "d.jpg")); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Create() error = %v, want errors.ErrUnsupported", err)
+	}
+}
+
+func TestWalkAndGlob(t *testing.T) {
+	m := NewMemory()
+	root := filepath.Join(string(filepath.Separator), "photos")
+	for _, name := range []string{
+		filepath.Join("2023-01", "a.jpg"),
+		filepath.Join("2024-01", "b.jpg"),
+		filepath.Join("2024-02", "skip", "c.jpg"),
+		filepath.Join("2024-02", "d.jpg"),
+	} {
+		if err := m.WriteFile(filepath.Join(root, name), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var walked []string
+	err := Walk(m, root, func(path string, d fs.DirEntry) error {
+		if d.Name() == "skip" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			walked = append(walked, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	want := []string{filepath.Join("2023-01", "a.jpg"), filepath.Join("2024-01", "b.jpg"), filepath.Join("2024-02", "d.jpg")}
+	if !reflect.DeepEqual(walked, want) {
+		t.Errorf("Walk() visited %v, want %v", walked, want)
+	}
+
+	matches, err := Glob(m, filepath.Join(root, "2024-*"))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if want := []string{filepath.Join(root, "2024-01"), filepath.Join(root, "2024-02")}; !reflect.DeepEqual(matches, want) {
+		t.Errorf("Glob() = %v, want %v", matches, want)
+	}
+	if _, err := Glob(m, "["); err == nil {
+		t.Error("Glob() of a bad pattern should fail")
+	}
+}
+
+func TestListBatches(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 5 {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.jpg", i)), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := NewMemory()
+	_ = m.WriteFile(filepath.Join(dir, "a.jpg"), nil)
+
+	for _, s := range []Storage{Local{}, m} {
+		var sizes []int
+		err := ListBatches(s, dir, 2, func(entries []fs.DirEntry) error {
+			sizes = append(sizes, len(entries))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%T: ListBatches failed: %v", s, err)
+		}
+		want := []int{2, 2, 1}
+		if _, ok := s.(*Memory); ok {
+			want = []int{1} // listed at once
+		}
+		if !slices.Equal(sizes, want) {
+			t.Errorf("%T: expected batches of %v, got %v", s, want, sizes)
+		}
+	}
+
+	stop := errors.New("stop")
+	if err := (Local{}).ListBatches(dir, 2, func([]fs.DirEntry) error { return stop }); err != stop {
+		t.Errorf("Expected the error of fn, got %v", err)
+	}
+}
+
+func TestPipeUploadAbort(t *testing.T) {
+	var stored []byte
+	upload := func() *pipeUpload {
+		r, w := io.Pipe()
+		u := &pipeUpload{w: w, done: make(chan error, 1)}
+		go func() {
+			data, err := io.ReadAll(r)
+			if err == nil {
+				stored = data
+			}
+			u.done <- err
+		}()
+		return u
+	}
+
+	u := upload()
+	_, _ = u.Write([]byte("half"))
+	cause := errors.New("source went away")
+	if err := u.Abort(cause); !errors.Is(err, cause) {
+		t.Errorf("Abort() = %v, want the upload to fail with %v", err, cause)
+	}
+	if stored != nil || !errors.Is(u.Close(), cause) {
+		t.Errorf("Expected nothing stored after Abort, got %q", stored)
+	}
+
+	u = upload()
+	_, _ = u.Write([]byte("whole"))
+	if err := u.Close(); err != nil || string(stored) != "whole" {
+		t.Errorf("Close() = %v, stored %q, want the whole file", err, stored)
+	}
+}
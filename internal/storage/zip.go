@@ -0,0 +1,466 @@
+package storage
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+const methodDeflate = zip.Deflate
+
+// deflated are the extensions of files that compress well. Photos and
+// videos are already compressed and are stored as they are, which is
+// much faster.
+var deflated = map[string]bool{
+	".tif": true, ".tiff": true, ".bmp": true, ".psd": true, ".xmp": true,
+	".txt": true, ".csv": true, ".json": true, ".xml": true, ".log": true,
+	".md5": true, ".sha256": true, ".mhl": true,
+}
+
+// Zip is a zip archive used as a destination: paths below the archive
+// path are its entries, as if it were a folder. Files are streamed one at
+// a time into a new archive next to it (path + ".part"), which replaces
+// the archive, with the entries of the previous one that were not written
+// again, when Commit is called at the end of the batch. Until then, the
+// previous archive stays as it was.
+type Zip struct {
+	path     string
+	password string
+
+	writing sync.Mutex // held while an entry is written
+
+	mu       sync.Mutex // guards the fields below
+	part     *os.File
+	w        *zip.Writer
+	method   uint16 // of the entry being written, for the AES compressor
+	written  []*zipEntry
+	live     map[string]*zipEntry // the written entries not removed
+	dirs     map[string]bool
+	previous map[string]*zip.File // the entries of the previous archive
+	archive  *zip.ReadCloser      // nil when there is none
+	loaded   bool                 // whether previous and archive are set
+	removed  map[string]bool      // entries of the previous archive removed
+}
+
+type zipEntry struct {
+	name    string
+	size    int64
+	modTime time.Time
+	removed bool
+}
+
+// NewZip returns the archive at path, encrypted with the password of
+// opts, if any.
+func NewZip(path string, opts *config.ZipOptions) *Zip {
+	z := &Zip{path: filepath.Clean(path)}
+	if opts != nil {
+		z.password = opts.Secret()
+	}
+	z.reset()
+	return z
+}
+
+func (z *Zip) reset() {
+	z.part, z.w = nil, nil
+	z.written = nil
+	z.live = make(map[string]*zipEntry)
+	z.dirs = make(map[string]bool)
+	z.removed = make(map[string]bool)
+	z.previous, z.archive, z.loaded = nil, nil, false
+}
+
+// entryName returns the name of the entry at p, "" for the archive itself,
+// and false when p is not in the archive.
+func (z *Zip) entryName(p string) (string, bool) {
+	rel, err := filepath.Rel(z.path, filepath.Clean(p))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// load opens the previous archive, if any. z.mu must be held.
+func (z *Zip) load() error {
+	if z.loaded {
+		return nil
+	}
+	z.previous = make(map[string]*zip.File)
+	archive, err := zip.OpenReader(z.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		z.loaded = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	for _, f := range archive.File {
+		z.previous[f.Name] = f
+		z.addDirs(f.Name)
+	}
+	z.archive, z.loaded = archive, true
+	return nil
+}
+
+// addDirs records the folders of the entry name. z.mu must be held.
+func (z *Zip) addDirs(name string) {
+	for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+		z.dirs[dir] = true
+	}
+}
+
+// find returns the description of the entry name. z.mu must be held.
+func (z *Zip) find(name string) (fs.FileInfo, bool) {
+	if e, ok := z.live[name]; ok {
+		return memInfo{name: path.Base(name), size: e.size, modTime: e.modTime}, true
+	}
+	if f, ok := z.previous[name]; ok && !z.removed[name] {
+		return memInfo{name: path.Base(name), size: int64(f.UncompressedSize64), modTime: f.Modified}, true
+	}
+	if z.dirs[name] {
+		return memInfo{name: path.Base(name), dir: true}, true
+	}
+	return nil, false
+}
+
+// Open opens an entry of the archive as it was at the last Commit.
+func (z *Zip) Open(p string) (io.ReadCloser, error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	name, ok := z.entryName(p)
+	if err := z.load(); err != nil {
+		return nil, err
+	}
+	f := z.previous[name]
+	if !ok || f == nil || z.removed[name] {
+		return nil, &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+	}
+	if f.Method != methodAES {
+		return f.Open()
+	}
+	method, ok := aesMethod(f.Extra)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported zip encryption", p)
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+	r, err := newAESReader(raw, int64(f.CompressedSize64), z.password, method)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return r, nil
+}
+
+// Create starts writing the entry at p. Entries are written one at a
+// time: Create waits until the file of the previous one is closed.
+func (z *Zip) Create(p string) (File, error) {
+	name, ok := z.entryName(p)
+	if !ok || name == "" {
+		return nil, &fs.PathError{Op: "create", Path: p, Err: fs.ErrInvalid}
+	}
+	z.writing.Lock()
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	w, err := z.create(name)
+	if err != nil {
+		z.writing.Unlock()
+		return nil, err
+	}
+	return &zipFile{z: z, w: w, entry: &zipEntry{name: name, modTime: time.Now()}}, nil
+}
+
+// create adds the entry name to the new archive. z.mu must be held.
+func (z *Zip) create(name string) (io.Writer, error) {
+	if err := z.open(); err != nil {
+		return nil, err
+	}
+	header := &zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()}
+	if deflated[strings.ToLower(path.Ext(name))] {
+		header.Method = methodDeflate
+	}
+	if z.password != "" {
+		z.method = header.Method
+		header.Extra = aesExtra(header.Method)
+		header.Method = methodAES
+		header.Flags |= 0x1 // encrypted
+	}
+	return z.w.CreateHeader(header)
+}
+
+// partPath is where the new archive is written until it is committed.
+func (z *Zip) partPath() string {
+	return z.path + ".part"
+}
+
+// open starts the new archive. z.mu must be held.
+func (z *Zip) open() error {
+	if z.w != nil {
+		return nil
+	}
+	if err := z.load(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(z.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	part, err := os.Create(z.partPath())
+	if err != nil {
+		return err
+	}
+	z.part, z.w = part, zip.NewWriter(part)
+	if z.password != "" {
+		z.w.RegisterCompressor(methodAES, func(w io.Writer) (io.WriteCloser, error) {
+			return newAESWriter(w, z.password, z.method)
+		})
+	}
+	return nil
+}
+
+// Stat describes an entry, a folder in the archive, or the archive
+// itself, which exists as a folder once it has entries.
+func (z *Zip) Stat(p string) (fs.FileInfo, error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	name, ok := z.entryName(p)
+	if err := z.load(); err != nil {
+		return nil, err
+	}
+	if ok && name == "" && (z.archive != nil || len(z.live) > 0) {
+		return memInfo{name: filepath.Base(z.path), dir: true}, nil
+	}
+	if ok && name != "" {
+		if info, found := z.find(name); found {
+			return info, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+}
+
+// List returns the entries and folders directly in the folder dir of the
+// archive, sorted by name.
+func (z *Zip) List(dir string) ([]fs.DirEntry, error) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	prefix, ok := z.entryName(dir)
+	if err := z.load(); err != nil {
+		return nil, err
+	}
+	if !ok || (prefix != "" && !z.dirs[prefix]) {
+		return nil, &fs.PathError{Op: "readdir", Path: dir, Err: fs.ErrNotExist}
+	}
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	children := make(map[string]fs.DirEntry)
+	add := func(name string) {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok || rest == "" {
+			return
+		}
+		child, _, _ := strings.Cut(rest, "/")
+		if _, seen := children[child]; seen {
+			return
+		}
+		if info, found := z.find(prefix + child); found {
+			children[child] = fs.FileInfoToDirEntry(info)
+		}
+	}
+	for name := range z.live {
+		add(name)
+	}
+	for name := range z.previous {
+		add(name)
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, entry := range children {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Remove removes the entry at p. Entries already written stay in the new
+// archive until Commit, which leaves them out.
+func (z *Zip) Remove(p string) error {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	name, ok := z.entryName(p)
+	if err := z.load(); err != nil {
+		return err
+	}
+	if e, found := z.live[name]; ok && found {
+		e.removed = true
+		delete(z.live, name)
+		return nil
+	}
+	if _, found := z.previous[name]; ok && found && !z.removed[name] {
+		z.removed[name] = true
+		return nil
+	}
+	return &fs.PathError{Op: "remove", Path: p, Err: fs.ErrNotExist}
+}
+
+// Commit replaces the archive with the new one, adding the entries of the
+// previous archive that were neither written again nor removed. It does
+// nothing when nothing changed.
+func (z *Zip) Commit() error {
+	z.writing.Lock()
+	defer z.writing.Unlock()
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if z.w == nil && len(z.removed) == 0 {
+		return nil
+	}
+	err := z.commit()
+	if z.part != nil {
+		_ = z.part.Close()
+		_ = os.Remove(z.partPath())
+	}
+	if z.archive != nil {
+		_ = z.archive.Close()
+	}
+	z.reset()
+	return err
+}
+
+// commit writes the new archive and renames it over the previous one.
+// z.mu must be held.
+func (z *Zip) commit() error {
+	if err := z.open(); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(z.previous))
+	for name := range z.previous {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if z.live[name] != nil || z.removed[name] {
+			continue
+		}
+		if err := z.w.Copy(z.previous[name]); err != nil {
+			return fmt.Errorf("failed to copy %s from the archive: %w", name, err)
+		}
+	}
+	if err := z.w.Close(); err != nil {
+		return err
+	}
+	if err := z.dropRemoved(); err != nil {
+		return err
+	}
+	if err := z.part.Sync(); err != nil {
+		return err
+	}
+	if err := z.part.Close(); err != nil {
+		return err
+	}
+	// Windows cannot replace a file that is open.
+	if z.archive != nil {
+		_ = z.archive.Close()
+		z.archive = nil
+	}
+	return os.Rename(z.partPath(), z.path)
+}
+
+// dropRemoved rewrites the new archive without the entries written and
+// then removed, such as the partial copies of failed attempts. Entries
+// cannot be taken out of a zip file, so this copies the others into
+// another one. z.mu must be held.
+func (z *Zip) dropRemoved() error {
+	drop := make(map[int]bool)
+	for i, e := range z.written {
+		if e.removed {
+			drop[i] = true
+		}
+	}
+	if len(drop) == 0 {
+		return nil
+	}
+	r, err := zip.OpenReader(z.partPath())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	out, err := os.Create(z.partPath() + "2")
+	if err != nil {
+		return err
+	}
+	w := zip.NewWriter(out)
+	for i, f := range r.File {
+		if drop[i] {
+			continue
+		}
+		if err := w.Copy(f); err != nil {
+			_ = out.Close()
+			_ = os.Remove(out.Name())
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		_ = out.Close()
+		_ = os.Remove(out.Name())
+		return err
+	}
+	_ = r.Close()
+	_ = z.part.Close()
+	if err := os.Rename(out.Name(), z.partPath()); err != nil {
+		_ = out.Close()
+		return err
+	}
+	z.part = out
+	return nil
+}
+
+// zipFile is an entry being written. Closing it lets the next one start.
+type zipFile struct {
+	z      *Zip
+	w      io.Writer
+	entry  *zipEntry
+	closed bool
+}
+
+func (f *zipFile) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	f.entry.size += int64(n)
+	return n, err
+}
+
+// Sync does nothing: the archive is synced when it is committed.
+func (f *zipFile) Sync() error {
+	return nil
+}
+
+func (f *zipFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	z := f.z
+	z.mu.Lock()
+	if old, ok := z.live[f.entry.name]; ok {
+		old.removed = true // written again
+	}
+	z.written = append(z.written, f.entry)
+	z.live[f.entry.name] = f.entry
+	z.addDirs(f.entry.name)
+	z.mu.Unlock()
+	z.writing.Unlock()
+	return nil
+}
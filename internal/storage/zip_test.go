@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"copy-image/internal/config"
+)
+
+func writeEntry(t *testing.T, s Storage, path, content string) {
+	t.Helper()
+	f, err := s.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(f, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readEntry(t *testing.T, s Storage, path string) string {
+	t.Helper()
+	r, err := s.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// entries returns the names of the entries in the archive at path.
+func entries(t *testing.T, path string) []string {
+	t.Helper()
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+	var names []string
+	for _, f := range r.File {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestZip(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "out", "job.zip")
+	z := NewZip(archive, nil)
+	if Exists(z, archive) {
+		t.Fatal("Expected no archive before the first copy")
+	}
+
+	writeEntry(t, z, filepath.Join(archive, "a.jpg"), "a")
+	writeEntry(t, z, filepath.Join(archive, "sub", "b.txt"), strings.Repeat("b", 1000))
+	writeEntry(t, z, filepath.Join(archive, "failed.jpg"), "partial")
+	if err := z.Remove(filepath.Join(archive, "failed.jpg")); err != nil {
+		t.Fatal(err)
+	}
+	if !IsDir(z, archive) || !IsDir(z, filepath.Join(archive, "sub")) || !Exists(z, filepath.Join(archive, "a.jpg")) {
+		t.Error("Expected the entries to exist before the commit")
+	}
+	if err := z.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(entries(t, archive), ","); got != "a.jpg,sub/b.txt" {
+		t.Errorf("Expected a.jpg and sub/b.txt, got %s", got)
+	}
+
+	// The next batch keeps the entries it does not write again.
+	writeEntry(t, z, filepath.Join(archive, "a.jpg"), "a2")
+	writeEntry(t, z, filepath.Join(archive, "c.jpg"), "c")
+	if err := z.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(entries(t, archive), ","); got != "a.jpg,c.jpg,sub/b.txt" {
+		t.Errorf("Expected a.jpg, c.jpg and sub/b.txt, got %s", got)
+	}
+	if got := readEntry(t, z, filepath.Join(archive, "a.jpg")); got != "a2" {
+		t.Errorf("Expected the new a.jpg, got %q", got)
+	}
+
+	list, err := z.List(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range list {
+		names = append(names, e.Name())
+	}
+	if got := strings.Join(names, ","); got != "a.jpg,c.jpg,sub" {
+		t.Errorf("Expected a.jpg, c.jpg and sub, got %s", got)
+	}
+}
+
+func TestZipPassword(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "job.zip")
+	z := NewZip(archive, &config.ZipOptions{Password: "secret"})
+	content := strings.Repeat("photo ", 5000)
+	writeEntry(t, z, filepath.Join(archive, "a.jpg"), content)
+	writeEntry(t, z, filepath.Join(archive, "notes.txt"), content)
+	if err := z.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range r.File {
+		if f.Method != methodAES || f.Flags&0x1 == 0 {
+			t.Errorf("%s: expected an encrypted entry, got method %d", f.Name, f.Method)
+		}
+		if raw, err := f.OpenRaw(); err == nil {
+			data, _ := io.ReadAll(raw)
+			if strings.Contains(string(data), "photo") {
+				t.Errorf("%s: expected encrypted data", f.Name)
+			}
+		}
+	}
+	_ = r.Close()
+
+	for _, name := range []string{"a.jpg", "notes.txt"} {
+		if got := readEntry(t, z, filepath.Join(archive, name)); got != content {
+			t.Errorf("%s: expected the content back, got %d bytes", name, len(got))
+		}
+	}
+	wrong := NewZip(archive, &config.ZipOptions{Password: "guess"})
+	if _, err := wrong.Open(filepath.Join(archive, "a.jpg")); !errors.Is(err, ErrPassword) {
+		t.Errorf("Expected ErrPassword, got %v", err)
+	}
+}
+
+func TestAESCTR(t *testing.T) {
+	// The counter is little-endian: it carries into the next byte.
+	ctr, err := newAESCTR(make([]byte, aesKeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctr.counter[0] = 0xFF
+	ctr.XORKeyStream(make([]byte, 1), make([]byte, 1))
+	if ctr.counter[0] != 0 || ctr.counter[1] != 1 {
+		t.Errorf("Expected the counter to carry, got %v", ctr.counter[:2])
+	}
+}
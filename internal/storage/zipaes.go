@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+)
+
+// WinZip AES encryption (https://www.winzip.com/en/support/aes-encryption/),
+// the zip encryption 7-Zip, WinZip and most archive tools open. Entries
+// are stored with method 99, the real method in an extra field, and their
+// data is a salt, a password verifier, the AES-CTR encrypted content and
+// an HMAC-SHA1 of it.
+const (
+	methodAES     = 99
+	aesExtraID    = 0x9901
+	aesStrength   = 3 // AES-256
+	aesKeySize    = 32
+	aesSaltSize   = 16
+	aesMACSize    = 10
+	aesIterations = 1000
+	// aesVersion 1 (AE-1) keeps the CRC of the content, which zip readers
+	// check as usual.
+	aesVersion = 1
+)
+
+// ErrPassword is matched by the error for an encrypted entry opened with
+// the wrong password.
+var ErrPassword = errors.New("wrong zip password")
+
+// aesExtra returns the extra field of an entry encrypted with AES and
+// compressed with method.
+func aesExtra(method uint16) []byte {
+	extra := binary.LittleEndian.AppendUint16(nil, aesExtraID)
+	extra = binary.LittleEndian.AppendUint16(extra, 7)
+	extra = binary.LittleEndian.AppendUint16(extra, aesVersion)
+	extra = append(extra, 'A', 'E', aesStrength)
+	return binary.LittleEndian.AppendUint16(extra, method)
+}
+
+// aesMethod returns the real compression method from the extra field of
+// an AES entry.
+func aesMethod(extra []byte) (uint16, bool) {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if 4+size > len(extra) {
+			break
+		}
+		if id == aesExtraID && size == 7 {
+			return binary.LittleEndian.Uint16(extra[9:]), true
+		}
+		extra = extra[4+size:]
+	}
+	return 0, false
+}
+
+// aesKeys derives the encryption key, the authentication key and the
+// password verifier from password and salt.
+func aesKeys(password string, salt []byte) (enc, auth, verifier []byte, err error) {
+	key, err := pbkdf2.Key(sha1.New, password, salt, aesIterations, 2*aesKeySize+2)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return key[:aesKeySize], key[aesKeySize : 2*aesKeySize], key[2*aesKeySize:], nil
+}
+
+// aesCTR is the counter mode of WinZip AES: the counter is little-endian
+// and starts at 1, unlike cipher.NewCTR.
+type aesCTR struct {
+	block   cipher.Block
+	counter [aes.BlockSize]byte
+	stream  [aes.BlockSize]byte
+	used    int
+}
+
+func newAESCTR(key []byte) (*aesCTR, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aesCTR{block: block, used: aes.BlockSize}, nil
+}
+
+func (c *aesCTR) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if c.used == aes.BlockSize {
+			for j := range c.counter {
+				c.counter[j]++
+				if c.counter[j] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.stream[:], c.counter[:])
+			c.used = 0
+		}
+		dst[i] = src[i] ^ c.stream[c.used]
+		c.used++
+	}
+}
+
+// aesWriter encrypts an entry, deflating it first unless it is stored.
+type aesWriter struct {
+	w       io.Writer
+	ctr     *aesCTR
+	mac     hash.Hash
+	deflate *flate.Writer
+	buf     []byte
+	header  []byte // the salt and the verifier, until they are written
+}
+
+// newAESWriter returns the writer encrypting an entry to w, which starts
+// with the salt and the password verifier.
+func newAESWriter(w io.Writer, password string, method uint16) (io.WriteCloser, error) {
+	salt := make([]byte, aesSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	enc, auth, verifier, err := aesKeys(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	ctr, err := newAESCTR(enc)
+	if err != nil {
+		return nil, err
+	}
+	// The zip writer creates the compressor before it writes the entry
+	// header, so the data only starts with the first write.
+	aw := &aesWriter{w: w, ctr: ctr, mac: hmac.New(sha1.New, auth), header: append(salt, verifier...)}
+	if method == methodDeflate {
+		aw.deflate, _ = flate.NewWriter(encrypter{aw}, flate.DefaultCompression)
+	}
+	return aw, nil
+}
+
+func (aw *aesWriter) Write(p []byte) (int, error) {
+	if aw.deflate != nil {
+		return aw.deflate.Write(p)
+	}
+	return aw.encrypt(p)
+}
+
+func (aw *aesWriter) encrypt(p []byte) (int, error) {
+	if err := aw.start(); err != nil {
+		return 0, err
+	}
+	if cap(aw.buf) < len(p) {
+		aw.buf = make([]byte, len(p))
+	}
+	buf := aw.buf[:len(p)]
+	aw.ctr.XORKeyStream(buf, p)
+	aw.mac.Write(buf)
+	return aw.w.Write(buf)
+}
+
+func (aw *aesWriter) start() error {
+	if aw.header == nil {
+		return nil
+	}
+	_, err := aw.w.Write(aw.header)
+	aw.header = nil
+	return err
+}
+
+// Close writes the authentication code. It does not close the
+// underlying writer.
+func (aw *aesWriter) Close() error {
+	if aw.deflate != nil {
+		if err := aw.deflate.Close(); err != nil {
+			return err
+		}
+	}
+	if err := aw.start(); err != nil {
+		return err
+	}
+	_, err := aw.w.Write(aw.mac.Sum(nil)[:aesMACSize])
+	return err
+}
+
+// encrypter is the aesWriter seen by the deflater.
+type encrypter struct{ aw *aesWriter }
+
+func (e encrypter) Write(p []byte) (int, error) { return e.aw.encrypt(p) }
+
+// newAESReader returns the content of an AES entry whose raw data, size
+// bytes long, is read from r. The authentication code is checked at the
+// end of the content.
+func newAESReader(r io.Reader, size int64, password string, method uint16) (io.ReadCloser, error) {
+	if size < aesSaltSize+2+aesMACSize {
+		return nil, errors.New("zip: encrypted entry is truncated")
+	}
+	header := make([]byte, aesSaltSize+2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	enc, auth, verifier, err := aesKeys(password, header[:aesSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(verifier, header[aesSaltSize:]) != 1 {
+		return nil, ErrPassword
+	}
+	ctr, err := newAESCTR(enc)
+	if err != nil {
+		return nil, err
+	}
+	ar := &aesReader{
+		r:   io.LimitReader(r, size-aesSaltSize-2-aesMACSize),
+		end: r,
+		ctr: ctr,
+		mac: hmac.New(sha1.New, auth),
+	}
+	if method == methodDeflate {
+		// The deflater may stop before the end of the data, and so before
+		// the code is checked.
+		return io.NopCloser(io.MultiReader(flate.NewReader(ar), drain{ar})), nil
+	}
+	return io.NopCloser(ar), nil
+}
+
+// drain reads what is left of r, returning only its error.
+type drain struct{ r io.Reader }
+
+func (d drain) Read([]byte) (int, error) {
+	if _, err := io.Copy(io.Discard, d.r); err != nil {
+		return 0, err
+	}
+	return 0, io.EOF
+}
+
+type aesReader struct {
+	r, end  io.Reader
+	ctr     *aesCTR
+	mac     hash.Hash
+	checked bool
+}
+
+func (ar *aesReader) Read(p []byte) (int, error) {
+	n, err := ar.r.Read(p)
+	ar.mac.Write(p[:n])
+	ar.ctr.XORKeyStream(p[:n], p[:n])
+	if err == io.EOF && !ar.checked {
+		ar.checked = true
+		code := make([]byte, aesMACSize)
+		if _, err := io.ReadFull(ar.end, code); err != nil {
+			return n, err
+		}
+		if !hmac.Equal(code, ar.mac.Sum(nil)[:aesMACSize]) {
+			return n, errors.New("zip: encrypted entry is corrupt")
+		}
+	}
+	return n, err
+}
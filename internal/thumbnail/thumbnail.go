@@ -0,0 +1,150 @@
+// Package thumbnail makes small JPEG previews of source images for the
+// desktop app's scan preview, kept in a folder so a source scanned again
+// shows at once.
+package thumbnail
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/jpeg"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/webp" // WebP sources
+
+	"copy-image/internal/storage"
+)
+
+// ErrUnsupported is matched by the error for files that cannot be decoded,
+// such as videos and raw files, which the frontend shows with an icon.
+var ErrUnsupported = errors.New("no thumbnail for this file")
+
+// quality is the JPEG quality of thumbnails: they are shown small.
+const quality = 75
+
+// Cache makes thumbnails and keeps them in a folder.
+type Cache struct {
+	dir string
+}
+
+// NewCache returns a cache keeping thumbnails in dir, which is created
+// when the first thumbnail is written.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultDir returns the thumbnail folder in the user cache directory, or
+// in the temporary directory if there is none.
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "copy-image", "thumbnails")
+}
+
+// Get returns a JPEG thumbnail, at most maxSize pixels wide and high, of
+// the file at path in src. A thumbnail is made again when the file's size
+// or modification time changed.
+func (c *Cache) Get(src storage.Storage, path string, maxSize int) ([]byte, error) {
+	info, err := src.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	cached := c.path(path, info, maxSize)
+	if data, err := os.ReadFile(cached); err == nil {
+		// Mark it used, for Prune.
+		now := time.Now()
+		_ = os.Chtimes(cached, now, now)
+		return data, nil
+	}
+
+	data, err := Make(src, path, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	// A thumbnail that cannot be kept is only made again next time.
+	_ = c.store(cached, data)
+	return data, nil
+}
+
+// Make returns a JPEG thumbnail of the file at path in src without the
+// cache.
+func Make(src storage.Storage, path string, maxSize int) ([]byte, error) {
+	r, err := src.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	img, err := imaging.Decode(r, imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), ErrUnsupported)
+	}
+	img = imaging.Fit(img, maxSize, maxSize, imaging.Linear)
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Prune removes the thumbnails not used for longer than age, so the folder
+// does not keep growing with sources long gone.
+func (c *Cache) Prune(age time.Duration) error {
+	entries, err := os.ReadDir(c.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-age)
+	for _, e := range entries {
+		info, err := e.Info()
+		if err == nil && !e.IsDir() && info.ModTime().Before(cutoff) {
+			_ = os.Remove(filepath.Join(c.dir, e.Name()))
+		}
+	}
+	return nil
+}
+
+// path returns where the thumbnail of the file at path is kept. The name
+// hashes everything the thumbnail depends on.
+func (c *Cache) path(path string, info fs.FileInfo, maxSize int) string {
+	h := sha256.New()
+	for _, s := range []string{path, strconv.FormatInt(info.Size(), 10), strconv.FormatInt(info.ModTime().UnixNano(), 10), strconv.Itoa(maxSize)} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return filepath.Join(c.dir, hex.EncodeToString(h.Sum(nil))+".jpg")
+}
+
+// store writes data to path through a temporary file, so a thumbnail being
+// written is never read half-finished by a concurrent Get.
+func (c *Cache) store(path string, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
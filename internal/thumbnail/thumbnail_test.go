@@ -0,0 +1,86 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"copy-image/internal/storage"
+)
+
+func writePNG(t *testing.T, path string, w, h int) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "a.png")
+	writePNG(t, src, 400, 200)
+	dir := t.TempDir()
+	c := NewCache(dir)
+
+	data, err := c.Get(storage.Local{}, src, 100)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width != 100 || cfg.Height != 50 {
+		t.Errorf("Expected a 100x50 JPEG, got %+v (%v)", cfg, err)
+	}
+	cached, _ := filepath.Glob(filepath.Join(dir, "*.jpg"))
+	if len(cached) != 1 {
+		t.Fatalf("Expected one cached thumbnail, got %v", cached)
+	}
+
+	// A changed file gets a new thumbnail.
+	writePNG(t, src, 200, 400)
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(src, later, later); err != nil {
+		t.Fatal(err)
+	}
+	data, err = c.Get(storage.Local{}, src, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _ := jpeg.DecodeConfig(bytes.NewReader(data)); cfg.Width != 50 || cfg.Height != 100 {
+		t.Errorf("Expected a 50x100 JPEG, got %+v", cfg)
+	}
+
+	// Prune keeps the thumbnails just used.
+	if err := c.Prune(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if cached, _ := filepath.Glob(filepath.Join(dir, "*.jpg")); len(cached) != 2 {
+		t.Errorf("Expected both thumbnails to be kept, got %v", cached)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	_ = os.Chtimes(cached[0], old, old)
+	if err := c.Prune(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if left, _ := filepath.Glob(filepath.Join(dir, "*.jpg")); len(left) != 1 {
+		t.Errorf("Expected the unused thumbnail to be removed, got %v", left)
+	}
+}
+
+func TestGetUnsupported(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "clip.mp4")
+	if err := os.WriteFile(src, []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCache(t.TempDir()).Get(storage.Local{}, src, 100); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
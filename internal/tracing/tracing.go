@@ -0,0 +1,86 @@
+// Package tracing sends OpenTelemetry traces of copy batches and files to
+// an OTLP collector (Jaeger, Tempo, Honeycomb, ...), to see where the time
+// of a long job goes. The copier creates the spans; this package only
+// decides where they are sent.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ServiceName identifies the program in traces.
+const ServiceName = "copy-image"
+
+// Enabled reports whether Setup would export anything: an endpoint was
+// given, or one of the standard OTLP endpoint variables is set.
+func Enabled(endpoint string) bool {
+	return endpoint != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup installs the global TracerProvider so spans are exported over
+// OTLP/HTTP to endpoint, the collector's base URL such as
+// http://localhost:4318 (/v1/traces is added when the URL has no path).
+// Without an endpoint the standard OTEL_EXPORTER_OTLP_* variables are
+// used, and when none is set tracing stays off. The returned function
+// sends the spans still buffered and must be called before exiting.
+func Setup(ctx context.Context, endpoint, version string) (func(context.Context) error, error) {
+	if !Enabled(endpoint) {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		u, err := endpointURL(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlptracehttp.WithEndpointURL(u))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up tracing: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(ServiceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		// Batches of 100k files make many spans; export them in the
+		// background rather than slowing the copy.
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// endpointURL turns the -otlp value into the traces URL: a missing scheme
+// means plain http, and a missing path the standard /v1/traces.
+func endpointURL(endpoint string) (string, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid OTLP endpoint %q: use a URL such as http://localhost:4318", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/traces"
+	}
+	return u.String(), nil
+}
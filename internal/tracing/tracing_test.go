@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEndpointURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{"http://localhost:4318", "http://localhost:4318/v1/traces", false},
+		{"localhost:4318", "http://localhost:4318/v1/traces", false},
+		{"https://otel.example.com/", "https://otel.example.com/v1/traces", false},
+		{"https://otel.example.com/custom/traces", "https://otel.example.com/custom/traces", false},
+		{"http://", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			got, err := endpointURL(tt.endpoint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("endpointURL(%q) error = %v, wantErr %v", tt.endpoint, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("endpointURL(%q) = %q, want %q", tt.endpoint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	if Enabled("") {
+		t.Fatal("Expected tracing to be off without an endpoint")
+	}
+	shutdown, err := Setup(context.Background(), "", "test")
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown failed: %v", err)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	if !Enabled("") {
+		t.Error("Expected the environment to turn tracing on")
+	}
+}
@@ -0,0 +1,98 @@
+// Package transcode converts images to another format while they are
+// copied (config.TranscodeRule), e.g. HEIC photos to JPEG for a client
+// gallery. Conversions run in the copy's worker, so no more of them run
+// at once than there are workers.
+package transcode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+
+	// WebP sources are decoded too.
+	_ "golang.org/x/image/webp"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+)
+
+// New returns the processor converting the files rule selects.
+func New(rule config.TranscodeRule) processor.FileProcessor {
+	t := &transcoder{rule: rule}
+	if rule.Command != "" {
+		t.command = processor.Command(rule.Command,
+			"COPYIMAGE_FORMAT="+rule.Format(),
+			"COPYIMAGE_QUALITY="+strconv.Itoa(rule.JPEGQuality()))
+	}
+	return t
+}
+
+type transcoder struct {
+	rule    config.TranscodeRule
+	command processor.Transformer // nil to convert in Go
+}
+
+func (t *transcoder) Name() string { return "transcode" }
+
+// Rename gives the copy the extension of the format written.
+func (t *transcoder) Rename(dest string) string {
+	return strings.TrimSuffix(dest, filepath.Ext(dest)) + t.rule.Extension()
+}
+
+func (t *transcoder) Transform(ctx context.Context, f processor.File, r io.Reader) (io.Reader, error) {
+	if t.command != nil {
+		return t.command.Transform(ctx, f, r)
+	}
+	img, err := imaging.Decode(r, imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert %s: %w", f.Name, err)
+	}
+	var out bytes.Buffer
+	if err := Encode(&out, img, t.rule); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Encode writes img in the rule's format.
+func Encode(w io.Writer, img image.Image, rule config.TranscodeRule) error {
+	switch rule.Format() {
+	case "jpeg":
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: rule.JPEGQuality()})
+	case "png":
+		return png.Encode(w, img)
+	case "gif":
+		return gif.Encode(w, img, nil)
+	case "bmp":
+		return bmp.Encode(w, img)
+	case "tiff":
+		return tiff.Encode(w, img, &tiff.Options{Compression: tiff.Deflate})
+	}
+	return fmt.Errorf("cannot write %s images", rule.Format())
+}
+
+// flatten draws img over white, as JPEG has no transparency and would
+// otherwise turn transparent areas black.
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	out := image.NewRGBA(img.Bounds())
+	draw.Draw(out, out.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Over)
+	return out
+}
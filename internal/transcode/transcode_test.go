@@ -0,0 +1,90 @@
+package transcode
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+)
+
+func pngImage(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 4))
+	img.Set(1, 1, color.NRGBA{R: 255, A: 255}) // the rest is transparent
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestTranscode(t *testing.T) {
+	tests := []struct {
+		to     string
+		format string
+		ext    string
+	}{
+		{"jpeg", "jpeg", ".jpg"},
+		{"jpg", "jpeg", ".jpg"},
+		{"gif", "gif", ".gif"},
+		{"bmp", "bmp", ".bmp"},
+		{"tiff", "tiff", ".tif"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.to, func(t *testing.T) {
+			p := New(config.TranscodeRule{From: []string{"png"}, To: tt.to, Quality: 80})
+			if got := p.(processor.Renamer).Rename("/out/a.png"); got != "/out/a"+tt.ext {
+				t.Errorf("Rename = %q, want %q", got, "/out/a"+tt.ext)
+			}
+			r, err := p.(processor.Transformer).Transform(context.Background(), processor.File{Name: "a.png"}, bytes.NewReader(pngImage(t)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, format, err := image.Decode(r)
+			if err != nil || format != tt.format {
+				t.Fatalf("Expected a %s image, got %q (%v)", tt.format, format, err)
+			}
+			if img.Bounds().Dx() != 8 || img.Bounds().Dy() != 4 {
+				t.Errorf("Unexpected size %v", img.Bounds())
+			}
+			if tt.format == "jpeg" {
+				// Transparent areas turn white, not black.
+				if r, _, _, _ := img.At(6, 3).RGBA(); r < 0xf000 {
+					t.Errorf("Expected a white background, got %v", img.At(6, 3))
+				}
+			}
+		})
+	}
+}
+
+func TestTranscodeInvalid(t *testing.T) {
+	p := New(config.TranscodeRule{From: []string{"png"}, To: "jpeg"})
+	_, err := p.(processor.Transformer).Transform(context.Background(), processor.File{Name: "a.png"}, strings.NewReader("not a png"))
+	if err == nil || !strings.Contains(err.Error(), "cannot convert a.png") {
+		t.Errorf("Expected a conversion error, got %v", err)
+	}
+}
+
+func TestTranscodeCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command uses sh")
+	}
+	p := New(config.TranscodeRule{From: []string{"heic"}, To: "jpeg", Quality: 75, Command: `cat; echo " $COPYIMAGE_FORMAT $COPYIMAGE_QUALITY"`})
+	r, err := p.(processor.Transformer).Transform(context.Background(), processor.File{Name: "a.heic"}, strings.NewReader("heic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	_ = r.(io.Closer).Close()
+	if err != nil || string(data) != "heic jpeg 75\n" {
+		t.Errorf("Unexpected output %q (%v)", data, err)
+	}
+}
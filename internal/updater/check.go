@@ -0,0 +1,147 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheFile is the file, in the app's data folder, a Checker keeps the
+// answer of its last check in.
+const CacheFile = "update-check.json"
+
+// Checker looks for new releases now and then, keeping the feed's last
+// answer in a file: checks are made only as often as configured, across
+// restarts, and are conditional on its ETag, so an unchanged answer does
+// not count against the API's rate limit of 60 requests an hour.
+type Checker struct {
+	Client  *http.Client
+	Feed    Feed
+	Channel string // ChannelStable or ChannelBeta
+	// CachePath is the file of the last answer; empty keeps none.
+	CachePath string
+}
+
+// cache is what a Checker keeps of its last check. The answer is kept as
+// it came so that one cached for another channel, which asks another URL,
+// is not mistaken for the current one.
+type cache struct {
+	CheckedAt time.Time       `json:"checkedAt"`
+	URL       string          `json:"url"`
+	ETag      string          `json:"etag,omitempty"`
+	Body      json.RawMessage `json:"body,omitempty"`
+}
+
+// Check returns the newest release on the channel, asking the feed
+// again only whether its answer changed since the last check.
+func (c *Checker) Check(ctx context.Context) (*Release, error) {
+	last := c.load()
+	var checked *cache
+	release, err := latest(ctx, c.Feed, c.Channel, func(ctx context.Context, url string) ([]byte, error) {
+		var err error
+		checked, err = c.fetch(ctx, url, last)
+		if err != nil {
+			return nil, err
+		}
+		return checked.Body, nil
+	})
+	if checked != nil {
+		// Even an answer that cannot be parsed counts as a check, so that
+		// a broken release is not asked for again at once.
+		_ = c.save(checked)
+	}
+	return release, err
+}
+
+// Cached returns the release found by the last check of the channel,
+// without asking the feed, and when that check was made; nil and the zero
+// time if it never was.
+func (c *Checker) Cached() (*Release, time.Time) {
+	last := c.load()
+	if last.URL != c.Feed.releasesURL(c.Channel) {
+		return nil, time.Time{}
+	}
+	release, err := latest(context.Background(), c.Feed, c.Channel, func(context.Context, string) ([]byte, error) {
+		return last.Body, nil
+	})
+	if err != nil {
+		return nil, last.CheckedAt
+	}
+	return release, last.CheckedAt
+}
+
+// LastChecked returns when the channel was last checked, the zero time if
+// it never was.
+func (c *Checker) LastChecked() time.Time {
+	last := c.load()
+	if last.URL != c.Feed.releasesURL(c.Channel) {
+		return time.Time{}
+	}
+	return last.CheckedAt
+}
+
+// fetch gets url, sending the ETag of last when it is the same URL; an
+// answer of 304 Not Modified returns last's body again.
+func (c *Checker) fetch(ctx context.Context, url string, last cache) (*cache, error) {
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if last.URL == url && last.ETag != "" && len(last.Body) > 0 {
+		req.Header.Set("If-None-Match", last.ETag)
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	now := time.Now()
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		last.CheckedAt = now
+		return &last, nil
+	case http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadata))
+		if err != nil {
+			return nil, err
+		}
+		return &cache{CheckedAt: now, URL: url, ETag: resp.Header.Get("ETag"), Body: body}, nil
+	}
+	return nil, fmt.Errorf("%s: status %d", url, resp.StatusCode)
+}
+
+// load returns the last answer kept, empty if there is none.
+func (c *Checker) load() cache {
+	var last cache
+	if c.CachePath == "" {
+		return last
+	}
+	data, err := os.ReadFile(c.CachePath)
+	if err != nil || json.Unmarshal(data, &last) != nil {
+		return cache{}
+	}
+	return last
+}
+
+func (c *Checker) save(last *cache) error {
+	if c.CachePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(last)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.CachePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.CachePath, data, 0644)
+}
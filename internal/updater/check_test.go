@@ -0,0 +1,79 @@
+package updater
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestChecker(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		tag      = "v1.2.0"
+		statuses []int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		etag := `"` + tag + `"`
+		status := http.StatusOK
+		if r.Header.Get("If-None-Match") == etag {
+			status = http.StatusNotModified
+		}
+		statuses = append(statuses, status)
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_, _ = w.Write([]byte(`{"tag_name": "` + tag + `"}`))
+		}
+	}))
+	defer srv.Close()
+
+	c := &Checker{Client: srv.Client(), Feed: Feed{API: srv.URL, Repo: "owner/app"}, Channel: ChannelStable, CachePath: filepath.Join(t.TempDir(), CacheFile)}
+	if !c.LastChecked().IsZero() {
+		t.Error("Expected no check made yet")
+	}
+	check := func(want string) {
+		t.Helper()
+		release, err := c.Check(context.Background())
+		if err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		if release.TagName != want {
+			t.Errorf("Check = %s, want %s", release.TagName, want)
+		}
+	}
+
+	check("v1.2.0")
+	check("v1.2.0")
+	mu.Lock()
+	tag = "v1.3.0"
+	mu.Unlock()
+	check("v1.3.0")
+
+	mu.Lock()
+	got := statuses
+	mu.Unlock()
+	want := []int{http.StatusOK, http.StatusNotModified, http.StatusOK}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected answers %v, got %v", want, got)
+	}
+	if c.LastChecked().IsZero() {
+		t.Error("Expected the check recorded")
+	}
+	if release, at := c.Cached(); release == nil || release.TagName != "v1.3.0" || !at.Equal(c.LastChecked()) {
+		t.Errorf("Expected v1.3.0 cached, got %v at %v", release, at)
+	}
+	// The answer kept is for the stable channel's URL.
+	c.Channel = ChannelBeta
+	if !c.LastChecked().IsZero() {
+		t.Error("Expected the beta channel never checked")
+	}
+	if release, _ := c.Cached(); release != nil {
+		t.Errorf("Expected nothing cached for the beta channel, got %v", release)
+	}
+}
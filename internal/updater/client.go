@@ -0,0 +1,69 @@
+package updater
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Timeouts of the client of NewClient. There is no overall timeout, which
+// would cut off a large download on a slow link; connecting, the TLS
+// handshake and waiting for the server to answer are bounded instead, and
+// release metadata is fetched under metadataTimeout.
+const (
+	dialTimeout           = 15 * time.Second
+	tlsHandshakeTimeout   = 15 * time.Second
+	responseHeaderTimeout = 30 * time.Second
+	metadataTimeout       = 30 * time.Second
+)
+
+// NewClient returns the HTTP client to check for and download updates
+// with. proxy is the URL of the proxy to go through; empty uses
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY from the environment. caFile is a
+// PEM file of certificate authorities trusted besides the system's, for
+// proxies that inspect TLS with an enterprise CA.
+func NewClient(proxy, caFile string) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = tlsHandshakeTimeout
+	transport.ResponseHeaderTimeout = responseHeaderTimeout
+	transport.Proxy = http.ProxyFromEnvironment
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	if caFile != "" {
+		pool, err := certPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// certPool returns the system's certificate authorities with those of the
+// PEM file caFile added.
+func certPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		// No system store to add to: trust the file alone.
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA file %s: no PEM certificates", caFile)
+	}
+	return pool, nil
+}
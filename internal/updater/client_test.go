@@ -0,0 +1,70 @@
+package updater
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewClientCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client, err := NewClient("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, err := client.Get(srv.URL); err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("A server with an unknown CA was trusted")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0644); err != nil {
+		t.Fatal(err)
+	}
+	client, err = NewClient("", caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("The CA file was not trusted: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient("", caFile); err == nil {
+		t.Error("A CA file without certificates was accepted")
+	}
+	if _, err := NewClient("", filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("A missing CA file was accepted")
+	}
+}
+
+func TestNewClientProxy(t *testing.T) {
+	var got string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(proxy.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get("http://releases.invalid/latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if got != "http://releases.invalid/latest" {
+		t.Errorf("The proxy got %q, want the release URL", got)
+	}
+}
@@ -0,0 +1,247 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Progress is what Download reports while it downloads.
+type Progress struct {
+	// Downloaded counts the bytes of the file so far, those of a download
+	// resumed included.
+	Downloaded int64
+	// Total is the size of the file, 0 when the server does not say.
+	Total int64
+	// BytesPerSecond is the speed of this download.
+	BytesPerSecond float64
+	// Done is set on the last report, once the file is complete.
+	Done bool
+}
+
+// Percent returns how much of the file was downloaded, from 0 to 100, or
+// 0 when its size is unknown.
+func (p Progress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return float64(p.Downloaded) / float64(p.Total) * 100
+}
+
+// downloadAttempts is how often Download tries, resuming where the
+// previous attempt broke off.
+const downloadAttempts = 5
+
+var (
+	// retryDelay is the wait before the second attempt, growing with each.
+	retryDelay = 2 * time.Second
+	// progressInterval is how often Download reports its progress.
+	progressInterval = 250 * time.Millisecond
+)
+
+// statusError is an HTTP status that retrying does not help with.
+type statusError struct {
+	status int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("download failed with status: %d", e.status)
+}
+
+// Download downloads url to path, calling progress, if not nil, at most
+// every progressInterval and once done. A file at path left by a download
+// that broke off earlier is resumed with a range request when the server
+// allows it, and a download breaking off now is resumed a few times before
+// Download gives up, so a large release over a poor connection neither
+// starts over nor fails at the first drop.
+func Download(ctx context.Context, client *http.Client, url, path string, progress func(Progress)) error {
+	d := &download{client: client, url: url, path: path, progress: progress, start: time.Now()}
+	var err error
+	for attempt := range downloadAttempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * retryDelay):
+			}
+		}
+		if err = d.attempt(ctx); err == nil || ctx.Err() != nil {
+			return err
+		}
+		if se := (*statusError)(nil); errors.As(err, &se) && se.status < http.StatusInternalServerError {
+			return err
+		}
+	}
+	return err
+}
+
+// download is a Download in progress.
+type download struct {
+	client   *http.Client
+	url      string
+	path     string
+	progress func(Progress)
+
+	start    time.Time
+	fetched  int64 // bytes downloaded by this Download
+	total    int64
+	reported time.Time
+}
+
+// attempt downloads the rest of the file once.
+func (d *download) attempt(ctx context.Context) error {
+	f, err := os.OpenFile(d.path, os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download update: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, total, ok := contentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			// Not the rest of our file; start over.
+			_ = f.Truncate(0)
+			return fmt.Errorf("unexpected range %q", resp.Header.Get("Content-Range"))
+		}
+		d.total = total
+	case http.StatusOK:
+		// The server sends the whole file, ignoring or refusing the range.
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		offset = 0
+		d.total = max(resp.ContentLength, 0)
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Complete already, or longer than the file now is.
+		if _, total, ok := contentRange(resp.Header.Get("Content-Range")); ok && total == offset {
+			d.total = total
+			d.report(offset, true)
+			return nil
+		}
+		_ = f.Truncate(0)
+		return fmt.Errorf("download failed with status: %d", resp.StatusCode)
+	default:
+		return &statusError{status: resp.StatusCode}
+	}
+
+	w := &progressWriter{w: f, d: d, n: offset}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to save update: %w", err)
+	}
+	if d.total > 0 && w.n != d.total {
+		return fmt.Errorf("download ended at %d of %d bytes", w.n, d.total)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close download file: %w", err)
+	}
+	d.total = w.n
+	d.report(w.n, true)
+	return nil
+}
+
+// report calls the progress function with n bytes of the file down, at
+// most every progressInterval unless done.
+func (d *download) report(n int64, done bool) {
+	if d.progress == nil {
+		return
+	}
+	now := time.Now()
+	if !done && now.Sub(d.reported) < progressInterval {
+		return
+	}
+	d.reported = now
+	p := Progress{Downloaded: n, Total: d.total, Done: done}
+	if elapsed := now.Sub(d.start).Seconds(); elapsed > 0 {
+		p.BytesPerSecond = float64(d.fetched) / elapsed
+	}
+	d.progress(p)
+}
+
+// progressWriter writes the downloaded bytes and reports them.
+type progressWriter struct {
+	w io.Writer
+	d *download
+	n int64 // bytes of the file written so far
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	w.d.fetched += int64(n)
+	w.d.report(w.n, false)
+	return n, err
+}
+
+// contentRange parses a Content-Range header, "bytes start-end/total" or
+// "bytes */total".
+func contentRange(h string) (start, total int64, ok bool) {
+	spec, ok := strings.CutPrefix(h, "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+	rng, size, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, false
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if rng == "*" {
+		return 0, total, true
+	}
+	first, _, _ := strings.Cut(rng, "-")
+	start, err = strconv.ParseInt(first, 10, 64)
+	return start, total, err == nil
+}
+
+// PartialPath returns where the download from downloadURL is kept until
+// it is installed, in the user's cache folder so that a download broken
+// off is resumed by the next update, even after a restart.
+func PartialPath(downloadURL string) (string, error) {
+	u, err := url.Parse(downloadURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid download URL: %w", err)
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	dir = filepath.Join(dir, "copy-image", "updates")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create download folder: %w", err)
+	}
+	// The tag keeps the builds of two releases apart.
+	tag := path.Base(path.Dir(u.Path))
+	return filepath.Join(dir, tag+"-"+path.Base(u.Path)+".part"), nil
+}
@@ -0,0 +1,138 @@
+package updater
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// release serves content, recording the Range header of each request.
+// The first breakAfter requests are broken off halfway.
+type release struct {
+	content    []byte
+	breakAfter int
+	noRanges   bool
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (r *release) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	r.ranges = append(r.ranges, req.Header.Get("Range"))
+	n := len(r.ranges)
+	r.mu.Unlock()
+
+	if req.URL.Path != "/v2.0.0/copyimage" {
+		http.NotFound(w, req)
+		return
+	}
+	if r.noRanges {
+		req.Header.Del("Range")
+	}
+	if n <= r.breakAfter {
+		w.Header().Set("Content-Length", strconv.Itoa(len(r.content)))
+		_, _ = w.Write(r.content[:len(r.content)/2])
+		panic(http.ErrAbortHandler)
+	}
+	http.ServeContent(w, req, "copyimage", time.Time{}, bytes.NewReader(r.content))
+}
+
+// requests returns the Range headers of the requests so far.
+func (r *release) requests() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.ranges...)
+}
+
+func serve(t *testing.T, r *release) string {
+	t.Helper()
+	delay := retryDelay
+	retryDelay = time.Millisecond
+	t.Cleanup(func() { retryDelay = delay })
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv.URL + "/v2.0.0/copyimage"
+}
+
+func TestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	tests := []struct {
+		name       string
+		release    *release
+		partial    []byte
+		wantRanges []string
+	}{
+		{"whole file", &release{}, nil, []string{""}},
+		{"resumes an earlier download", &release{}, content[:4000], []string{"bytes=4000-"}},
+		{"resumes a download broken off", &release{breakAfter: 1}, nil, []string{"", "bytes=50000-"}},
+		{"starts over without ranges", &release{noRanges: true}, content[:4000], []string{"bytes=4000-"}},
+		{"complete already", &release{}, content, []string{"bytes=100000-"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.release.content = content
+			url := serve(t, tt.release)
+			path := filepath.Join(t.TempDir(), "copyimage.part")
+			if tt.partial != nil {
+				if err := os.WriteFile(path, tt.partial, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var last Progress
+			if err := Download(context.Background(), http.DefaultClient, url, path, func(p Progress) { last = p }); err != nil {
+				t.Fatalf("Download failed: %v", err)
+			}
+			if got, _ := os.ReadFile(path); !bytes.Equal(got, content) {
+				t.Errorf("Expected the whole file, got %d bytes", len(got))
+			}
+			if !last.Done || last.Downloaded != int64(len(content)) || last.Percent() != 100 {
+				t.Errorf("Unexpected last progress: %+v", last)
+			}
+			if got := tt.release.requests(); len(got) != len(tt.wantRanges) || got[len(got)-1] != tt.wantRanges[len(tt.wantRanges)-1] {
+				t.Errorf("Expected requests %q, got %q", tt.wantRanges, got)
+			}
+		})
+	}
+}
+
+func TestDownloadGivesUp(t *testing.T) {
+	r := &release{}
+	url := serve(t, r)
+	path := filepath.Join(t.TempDir(), "copyimage.part")
+	if err := Download(context.Background(), http.DefaultClient, url+"-missing", path, nil); err == nil {
+		t.Fatal("Expected an error for a missing file")
+	}
+	if len(r.requests()) != 1 {
+		t.Errorf("Expected a missing file not to be retried, got %d requests", len(r.requests()))
+	}
+
+	r = &release{breakAfter: downloadAttempts}
+	url = serve(t, r)
+	if err := Download(context.Background(), http.DefaultClient, url, filepath.Join(t.TempDir(), "copyimage.part"), nil); err == nil {
+		t.Fatal("Expected an error once every attempt broke off")
+	}
+	if len(r.requests()) != downloadAttempts {
+		t.Errorf("Expected %d attempts, got %d", downloadAttempts, len(r.requests()))
+	}
+}
+
+func TestPartialPath(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	a, err := PartialPath("https://github.com/o/r/releases/download/v2.0.0/copyimage-cli-linux-amd64")
+	if err != nil {
+		t.Fatalf("PartialPath failed: %v", err)
+	}
+	b, _ := PartialPath("https://github.com/o/r/releases/download/v2.1.0/copyimage-cli-linux-amd64")
+	if a == b || filepath.Base(a) != "v2.0.0-copyimage-cli-linux-amd64.part" {
+		t.Errorf("Expected a file per release, got %q and %q", a, b)
+	}
+}
@@ -0,0 +1,225 @@
+package updater
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+// Update channels: which releases a user is offered.
+const (
+	ChannelStable = "stable" // releases only
+	ChannelBeta   = "beta"   // prereleases as well
+)
+
+// The public GitHub repository releases are looked up in by default.
+const (
+	GitHubAPI   = "https://api.github.com"
+	DefaultRepo = "hoangtran1411/copy-image"
+)
+
+// Feed is where releases are looked up: a GitHub repository, on github.com
+// or a GitHub Enterprise server, or a generic JSON feed.
+type Feed struct {
+	// API is the GitHub REST API URL; empty is GitHubAPI.
+	API string
+	// Repo is the repository, "owner/name"; empty is DefaultRepo.
+	Repo string
+	// URL is a JSON feed used instead of GitHub: an array of releases
+	// with GitHub's field names. Its checksums are expected next to the
+	// assets, as on GitHub.
+	URL string
+}
+
+// NewFeed returns the feed configured by f, which may be nil for the
+// public repository.
+func NewFeed(f *config.UpdateFeed) Feed {
+	if f == nil {
+		return Feed{}
+	}
+	return Feed{API: f.GitHub, Repo: f.Repo, URL: f.URL}
+}
+
+// releasesURL is the URL Latest gets for channel.
+func (f Feed) releasesURL(channel string) string {
+	if f.URL != "" {
+		return f.URL
+	}
+	api := cmp.Or(strings.TrimSuffix(f.API, "/"), GitHubAPI)
+	repo := cmp.Or(f.Repo, DefaultRepo)
+	if channel == ChannelBeta {
+		return fmt.Sprintf("%s/repos/%s/releases?per_page=%d", api, repo, betaReleases)
+	}
+	return fmt.Sprintf("%s/repos/%s/releases/latest", api, repo)
+}
+
+// String names the feed in messages.
+func (f Feed) String() string {
+	if f.URL != "" {
+		return f.URL
+	}
+	return cmp.Or(f.Repo, DefaultRepo)
+}
+
+// Release is a GitHub release, with only the fields the updater uses.
+type Release struct {
+	TagName    string `json:"tag_name"`
+	HTMLURL    string `json:"html_url"`
+	Prerelease bool   `json:"prerelease"`
+	Draft      bool   `json:"draft"`
+	// Body is the release notes, in GitHub markdown.
+	Body        string    `json:"body"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// Asset returns the asset of r called name.
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// CLIAssetName is the name the CLI for goos and goarch is released under,
+// such as "copyimage-cli-linux-amd64" or "copyimage-cli-windows-amd64.exe".
+func CLIAssetName(goos, goarch string) string {
+	name := "copyimage-cli-" + goos + "-" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// betaReleases is how many of the most recent releases the beta channel
+// looks through; a newer release than these is the latest anyway.
+const betaReleases = 20
+
+// Latest returns the newest release of feed on channel. On GitHub, the
+// stable channel asks for the latest release, which excludes prereleases;
+// the beta channel picks the newest of the recent releases, prereleases
+// included, as GitHub orders them by date rather than version. A JSON
+// feed is a list picked from the same way.
+func Latest(ctx context.Context, client *http.Client, feed Feed, channel string) (*Release, error) {
+	return latest(ctx, feed, channel, func(ctx context.Context, url string) ([]byte, error) {
+		return fetch(ctx, client, url)
+	})
+}
+
+// latest is Latest, getting the feed with get.
+func latest(ctx context.Context, feed Feed, channel string, get func(ctx context.Context, url string) ([]byte, error)) (*Release, error) {
+	body, err := get(ctx, feed.releasesURL(channel))
+	if err != nil {
+		return nil, err
+	}
+	if feed.URL == "" && channel != ChannelBeta {
+		var release Release
+		if err := json.Unmarshal(body, &release); err != nil {
+			return nil, fmt.Errorf("parse release: %w", err)
+		}
+		return &release, nil
+	}
+
+	var releases []Release
+	if err := json.Unmarshal(body, &releases); err != nil {
+		return nil, fmt.Errorf("parse releases: %w", err)
+	}
+	var newest *Release
+	for i, r := range releases {
+		if r.Draft || r.TagName == "" || r.Prerelease && channel != ChannelBeta {
+			continue
+		}
+		if newest == nil || Newer(r.TagName, newest.TagName) {
+			newest = &releases[i]
+		}
+	}
+	if newest == nil {
+		return nil, fmt.Errorf("%s has no releases", feed)
+	}
+	return newest, nil
+}
+
+// Newer reports whether version v1 is newer than v2, following semantic
+// versioning: "v1.2.3" or "1.2.3", where a prerelease such as
+// "v1.3.0-beta.2" comes before its release but after v1.2.x. Missing or
+// invalid numbers count as 0.
+func Newer(v1, v2 string) bool {
+	return compareVersions(v1, v2) > 0
+}
+
+// compareVersions returns -1, 0 or 1 as v1 is older than, the same as or
+// newer than v2.
+func compareVersions(v1, v2 string) int {
+	core1, pre1 := splitVersion(v1)
+	core2, pre2 := splitVersion(v2)
+	for i := range core1 {
+		if c := cmp.Compare(core1[i], core2[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case pre1 == "" && pre2 == "":
+		return 0
+	case pre1 == "":
+		return 1
+	case pre2 == "":
+		return -1
+	}
+	return comparePrerelease(strings.Split(pre1, "."), strings.Split(pre2, "."))
+}
+
+// splitVersion returns the major, minor and patch numbers of v and its
+// prerelease, with build metadata dropped.
+func splitVersion(v string) (core [3]int, pre string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+")
+	v, pre, _ = strings.Cut(v, "-")
+	for i, part := range strings.Split(v, ".") {
+		if i == len(core) {
+			break
+		}
+		// Best effort: an invalid number counts as 0.
+		core[i], _ = strconv.Atoi(part)
+	}
+	return core, pre
+}
+
+// comparePrerelease compares prerelease identifiers the way semantic
+// versioning does: numbers numerically and below words, words by their
+// bytes, and a shorter list of otherwise equal identifiers first.
+func comparePrerelease(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		n, errA := strconv.Atoi(a[i])
+		m, errB := strconv.Atoi(b[i])
+		var c int
+		switch {
+		case errA == nil && errB == nil:
+			c = cmp.Compare(n, m)
+		case errA == nil:
+			c = -1
+		case errB == nil:
+			c = 1
+		default:
+			c = strings.Compare(a[i], b[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
@@ -0,0 +1,157 @@
+package updater
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"copy-image/internal/config"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		v1, v2 string
+		want   bool
+	}{
+		{"v2.0.0", "v1.9.9", true},
+		{"v1.2", "v1.1.9", true},
+		{"v1.2.0", "v1.2", false},
+		{"1.0.1", "v1.0.0", true},
+		{"v1.3.0", "v1.3.0-beta.2", true},
+		{"v1.3.0-beta.2", "v1.3.0", false},
+		{"v1.3.0-beta.2", "v1.2.9", true},
+		{"v1.3.0-beta.10", "v1.3.0-beta.2", true},
+		{"v1.3.0-rc.1", "v1.3.0-beta.2", true},
+		{"v1.3.0-beta", "v1.3.0-beta.1", false},
+		{"v1.3.0-beta.1", "v1.3.0-1", true},
+		{"v1.3.0+build.5", "v1.3.0", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.v1, tt.v2); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.v1, tt.v2, got, tt.want)
+		}
+	}
+}
+
+func TestSplitVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		core    [3]int
+		pre     string
+	}{
+		{"1.2.3", [3]int{1, 2, 3}, ""},
+		{"v1.2", [3]int{1, 2, 0}, ""},
+		{"1", [3]int{1, 0, 0}, ""},
+		{"", [3]int{0, 0, 0}, ""},
+		{"1.2.3.4", [3]int{1, 2, 3}, ""},
+		{"a.b.c", [3]int{0, 0, 0}, ""},
+		{"v1.3.0-beta.2+build.7", [3]int{1, 3, 0}, "beta.2"},
+	}
+	for _, tt := range tests {
+		core, pre := splitVersion(tt.version)
+		if core != tt.core || pre != tt.pre {
+			t.Errorf("splitVersion(%q) = %v, %q, want %v, %q", tt.version, core, pre, tt.core, tt.pre)
+		}
+	}
+}
+
+func TestLatest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/app/releases/latest":
+			_, _ = w.Write([]byte(`{"tag_name": "v1.2.0", "body": "## Fixes\n- Faster", "published_at": "2026-03-01T10:00:00Z"}`))
+		case "/repos/owner/app/releases":
+			_, _ = w.Write([]byte(`[
+				{"tag_name": "v1.4.0-beta.1", "draft": true},
+				{"tag_name": "v1.2.1"},
+				{"tag_name": "v1.3.0-beta.2", "prerelease": true},
+				{"tag_name": "v1.2.0"}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	feed := Feed{API: srv.URL + "/", Repo: "owner/app"}
+
+	for channel, want := range map[string]string{ChannelStable: "v1.2.0", ChannelBeta: "v1.3.0-beta.2", "": "v1.2.0"} {
+		release, err := Latest(context.Background(), srv.Client(), feed, channel)
+		if err != nil {
+			t.Fatalf("Latest(%q): %v", channel, err)
+		}
+		if release.TagName != want {
+			t.Errorf("Latest(%q) = %s, want %s", channel, release.TagName, want)
+		}
+	}
+	release, err := Latest(context.Background(), srv.Client(), feed, ChannelStable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Body != "## Fixes\n- Faster" || !release.PublishedAt.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the release notes and date, got %q, %v", release.Body, release.PublishedAt)
+	}
+	if _, err := Latest(context.Background(), srv.Client(), Feed{API: srv.URL, Repo: "owner/missing"}, ChannelBeta); err == nil {
+		t.Error("Latest of a missing repository succeeded")
+	}
+}
+
+func TestLatestJSONFeed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"tag_name": "v1.2.0"},
+			{"tag_name": "v1.3.0-beta.1", "prerelease": true},
+			{"tag_name": "v1.2.1", "assets": [{"name": "copyimage.exe", "browser_download_url": "https://updates.example.com/v1.2.1/copyimage.exe"}]}
+		]`))
+	}))
+	defer srv.Close()
+
+	feed := Feed{URL: srv.URL + "/feed.json"}
+	release, err := Latest(context.Background(), srv.Client(), feed, ChannelStable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := release.Asset("copyimage.exe"); !ok || a.BrowserDownloadURL != "https://updates.example.com/v1.2.1/copyimage.exe" {
+		t.Errorf("Asset = %+v, %v", a, ok)
+	}
+	if _, ok := release.Asset("copyimage-cli-linux-amd64"); ok {
+		t.Error("Expected no Linux CLI asset")
+	}
+
+	for channel, want := range map[string]string{ChannelStable: "v1.2.1", ChannelBeta: "v1.3.0-beta.1"} {
+		release, err := Latest(context.Background(), srv.Client(), feed, channel)
+		if err != nil {
+			t.Fatalf("Latest(%q): %v", channel, err)
+		}
+		if release.TagName != want {
+			t.Errorf("Latest(%q) = %s, want %s", channel, release.TagName, want)
+		}
+	}
+}
+
+func TestFeedURL(t *testing.T) {
+	tests := []struct {
+		feed    Feed
+		channel string
+		want    string
+	}{
+		{Feed{}, ChannelStable, "https://api.github.com/repos/hoangtran1411/copy-image/releases/latest"},
+		{Feed{API: "https://github.example.com/api/v3/", Repo: "corp/copy-image"}, ChannelBeta, "https://github.example.com/api/v3/repos/corp/copy-image/releases?per_page=20"},
+		{Feed{URL: "https://updates.example.com/feed.json"}, ChannelBeta, "https://updates.example.com/feed.json"},
+	}
+	for _, tt := range tests {
+		if got := tt.feed.releasesURL(tt.channel); got != tt.want {
+			t.Errorf("%+v on %s: got %s, want %s", tt.feed, tt.channel, got, tt.want)
+		}
+	}
+	if got := CLIAssetName("windows", "amd64"); got != "copyimage-cli-windows-amd64.exe" {
+		t.Errorf("CLIAssetName = %s", got)
+	}
+	if got := CLIAssetName("darwin", "arm64"); got != "copyimage-cli-darwin-arm64" {
+		t.Errorf("CLIAssetName = %s", got)
+	}
+	if got := NewFeed(&config.UpdateFeed{GitHub: "https://github.example.com/api/v3", Repo: "corp/app"}); got != (Feed{API: "https://github.example.com/api/v3", Repo: "corp/app"}) {
+		t.Errorf("NewFeed = %+v", got)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package updater
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// relaunch execs the command from a shell once the running program
+// exited: a second instance would only hand its arguments to this one.
+func relaunch(name string, args ...string) error {
+	const script = `pid=$1; shift; while kill -0 "$pid" 2>/dev/null; do sleep 0.2; done; exec "$@"`
+	cmd := exec.Command("/bin/sh", append([]string{"-c", script, "sh", strconv.Itoa(os.Getpid()), name}, args...)...)
+	// In a session of its own, so it outlives the app and its terminal.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start restart: %w", err)
+	}
+	// Not waited for: it ends after the app does.
+	return cmd.Process.Release()
+}
@@ -0,0 +1,56 @@
+//go:build windows
+
+package updater
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// relaunch runs the command with a batch script once the running program
+// exited: a second instance would only hand its arguments to this one.
+func relaunch(name string, args ...string) error {
+	script, err := os.CreateTemp("", "update_copyimage_*.bat")
+	if err != nil {
+		return fmt.Errorf("failed to create restart script: %w", err)
+	}
+	path := script.Name()
+
+	quoted := make([]string, 0, len(args)+1)
+	for _, a := range append([]string{name}, args...) {
+		quoted = append(quoted, `"`+a+`"`)
+	}
+	// - the loop waits for the app to close
+	// - start launches the updated app
+	// - the (goto) trick deletes the script itself after execution
+	content := fmt.Sprintf(`@echo off
+:wait
+tasklist /fi "PID eq %d" 2>nul | find "%d" >nul && (timeout /t 1 /nobreak >nul & goto wait)
+start "" %s
+(goto) 2>nul & del "%%~f0"
+`, os.Getpid(), os.Getpid(), strings.Join(quoted, " "))
+
+	_, err = script.WriteString(content)
+	if closeErr := script.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to write restart script: %w", err)
+	}
+
+	// Detached, so it keeps running after the app exits.
+	cmd := exec.Command("cmd", "/c", path)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: 0x00000008, // DETACHED_PROCESS
+	}
+	if err := cmd.Start(); err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to start restart script: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// Package updater installs new releases of the desktop app and the CLI in
+// place of the running program, the way each platform allows.
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Updater replaces the running program with a downloaded build. Windows
+// cannot overwrite a running executable and macOS apps are bundles, so
+// each platform has its own; New picks the one of the running program.
+type Updater interface {
+	// Install puts the build downloaded to path in place of the running
+	// program, keeping the replaced one next to it with an ".old" suffix.
+	// The running program goes on unchanged until it exits.
+	Install(path string) error
+	// Restart starts the installed build as soon as the running program
+	// exited, so the caller quits right after.
+	Restart() error
+	// Rollback puts back the version Install replaced, keeping the one
+	// rolled back from as ".old" in turn, so that a rollback is undone
+	// the same way. Like Install, it takes effect at the next start.
+	Rollback() error
+	// Path is the executable or bundle updated.
+	Path() string
+}
+
+// ErrNoPrevious is matched by Rollback when there is no ".old" version to
+// go back to.
+var ErrNoPrevious = errors.New("no previous version to roll back to")
+
+// New returns the Updater of the running program: its executable, or on
+// macOS the .app bundle it runs from.
+func New() (Updater, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executable path: %w", err)
+	}
+	// Installed through a symlink, such as /usr/local/bin/copyimage, the
+	// file to replace is the one linked to.
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if app := bundlePath(exe); app != "" && runtime.GOOS == "darwin" {
+		return &bundle{path: app}, nil
+	}
+	return &binary{path: exe}, nil
+}
+
+// binary updates a program that is a single executable: the CLI, and the
+// desktop app on Windows and Linux.
+type binary struct {
+	path string
+}
+
+// Install copies the download next to the executable first, as it is
+// usually in a temporary folder on another drive, where renaming it into
+// place would fail. Renaming a running executable away works everywhere,
+// Windows included.
+func (b *binary) Install(path string) error {
+	next := b.path + ".new"
+	if err := copyExecutable(path, next); err != nil {
+		_ = os.Remove(next)
+		return err
+	}
+	return swap(b.path, next)
+}
+
+func (b *binary) Restart() error {
+	return relaunch(b.path)
+}
+
+func (b *binary) Rollback() error {
+	return restore(b.path)
+}
+
+func (b *binary) Path() string {
+	return b.path
+}
+
+// bundle updates a macOS .app bundle, from a zip holding the new bundle.
+type bundle struct {
+	path string
+}
+
+// Install unpacks the download next to the bundle and swaps the folders.
+func (b *bundle) Install(path string) error {
+	tmp, err := os.MkdirTemp(filepath.Dir(b.path), ".copyimage-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create update folder: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	if err := unzip(path, tmp); err != nil {
+		return fmt.Errorf("failed to unpack update: %w", err)
+	}
+	app, err := findBundle(tmp)
+	if err != nil {
+		return err
+	}
+	return swap(b.path, app)
+}
+
+func (b *bundle) Restart() error {
+	return relaunch("open", "-n", b.path)
+}
+
+func (b *bundle) Rollback() error {
+	return restore(b.path)
+}
+
+func (b *bundle) Path() string {
+	return b.path
+}
+
+// bundlePath returns the .app bundle exe runs from, or "" when it is not
+// the executable of a bundle.
+func bundlePath(exe string) string {
+	macOS := filepath.Dir(exe)
+	contents := filepath.Dir(macOS)
+	app := filepath.Dir(contents)
+	if filepath.Base(macOS) != "MacOS" || filepath.Base(contents) != "Contents" || !strings.HasSuffix(app, ".app") {
+		return ""
+	}
+	return app
+}
+
+// findBundle returns the .app bundle at the top of dir.
+func findBundle(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		if e.IsDir() && strings.HasSuffix(e.Name(), ".app") {
+			return filepath.Join(dir, e.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("the update holds no .app bundle")
+}
+
+// swap moves next into the place of current, which is kept with an
+// ".old" suffix. If next cannot be moved, current is put back.
+func swap(current, next string) error {
+	old := current + ".old"
+	if err := os.RemoveAll(old); err != nil {
+		return fmt.Errorf("failed to remove previous version: %w", err)
+	}
+	if err := os.Rename(current, old); err != nil {
+		return fmt.Errorf("failed to move current version: %w", err)
+	}
+	if err := os.Rename(next, current); err != nil {
+		_ = os.Rename(old, current)
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+	return nil
+}
+
+// restore swaps current with the ".old" version swap kept.
+func restore(current string) error {
+	old := current + ".old"
+	if _, err := os.Stat(old); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNoPrevious
+		}
+		return fmt.Errorf("failed to find previous version: %w", err)
+	}
+	// Moved aside under a third name until the previous version is in
+	// place, then kept as the ".old" one.
+	rolled := current + ".rollback"
+	if err := os.RemoveAll(rolled); err != nil {
+		return fmt.Errorf("failed to remove interrupted rollback: %w", err)
+	}
+	if err := os.Rename(current, rolled); err != nil {
+		return fmt.Errorf("failed to move current version: %w", err)
+	}
+	if err := os.Rename(old, current); err != nil {
+		_ = os.Rename(rolled, current)
+		return fmt.Errorf("failed to restore previous version: %w", err)
+	}
+	if err := os.Rename(rolled, old); err != nil {
+		return fmt.Errorf("failed to keep rolled back version: %w", err)
+	}
+	return nil
+}
+
+// copyExecutable copies src to dst, which it makes executable.
+func copyExecutable(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open update: %w", err)
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return fmt.Errorf("failed to create update file: %w", err)
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy update: %w", err)
+	}
+	return nil
+}
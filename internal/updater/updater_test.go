@@ -0,0 +1,206 @@
+package updater
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBinaryInstall(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "copyimage")
+	writeFile(t, exe, "v1")
+	download := filepath.Join(t.TempDir(), "copyimage_update_1")
+	if err := os.WriteFile(download, []byte("v2"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&binary{path: exe}).Install(download); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if got := readFile(t, exe); got != "v2" {
+		t.Errorf("Expected the new version installed, got %q", got)
+	}
+	if got := readFile(t, exe+".old"); got != "v1" {
+		t.Errorf("Expected the old version kept, got %q", got)
+	}
+	if _, err := os.Stat(exe + ".new"); !os.IsNotExist(err) {
+		t.Errorf("Expected no file left behind, got %v", err)
+	}
+	if info, err := os.Stat(exe); err != nil || runtime.GOOS != "windows" && info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected the new version to be executable, got %v (%v)", info.Mode(), err)
+	}
+
+	// A second update replaces the old version kept.
+	writeFile(t, download, "v3")
+	if err := (&binary{path: exe}).Install(download); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if got := readFile(t, exe+".old"); got != "v2" {
+		t.Errorf("Expected the previous version kept, got %q", got)
+	}
+}
+
+func TestSwapRestoresCurrent(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "copyimage")
+	writeFile(t, exe, "v1")
+	if err := swap(exe, filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("Expected an error for a missing update")
+	}
+	if got := readFile(t, exe); got != "v1" {
+		t.Errorf("Expected the current version put back, got %q", got)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "copyimage")
+	writeFile(t, exe, "v2")
+	b := &binary{path: exe}
+	if err := b.Rollback(); !errors.Is(err, ErrNoPrevious) {
+		t.Fatalf("Expected ErrNoPrevious without an old version, got %v", err)
+	}
+
+	writeFile(t, exe+".old", "v1")
+	if err := b.Rollback(); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if got := readFile(t, exe); got != "v1" {
+		t.Errorf("Expected the previous version restored, got %q", got)
+	}
+	if got := readFile(t, exe+".old"); got != "v2" {
+		t.Errorf("Expected the rolled back version kept, got %q", got)
+	}
+
+	// Rolling back again undoes the rollback.
+	if err := b.Rollback(); err != nil {
+		t.Fatalf("Second rollback failed: %v", err)
+	}
+	if got := readFile(t, exe); got != "v2" {
+		t.Errorf("Expected the rollback undone, got %q", got)
+	}
+	if _, err := os.Stat(exe + ".rollback"); !os.IsNotExist(err) {
+		t.Errorf("Expected no file left behind, got %v", err)
+	}
+}
+
+func TestBundlePath(t *testing.T) {
+	app := filepath.Join(string(filepath.Separator), "Applications", "Copy Image.app")
+	tests := []struct {
+		exe  string
+		want string
+	}{
+		{filepath.Join(app, "Contents", "MacOS", "copyimage"), app},
+		{filepath.Join(string(filepath.Separator), "usr", "local", "bin", "copyimage"), ""},
+		{filepath.Join(string(filepath.Separator), "Apps", "Contents", "MacOS", "copyimage"), ""},
+	}
+	for _, tt := range tests {
+		if got := bundlePath(tt.exe); got != tt.want {
+			t.Errorf("bundlePath(%q) = %q, want %q", tt.exe, got, tt.want)
+		}
+	}
+}
+
+// writeZip writes an archive with the files and symlinks given, by name.
+func writeZip(t *testing.T, files, links map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "update.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	add := func(name, content string, mode os.FileMode) {
+		h := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		h.SetMode(mode)
+		fw, err := w.CreateHeader(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = fw.Write([]byte(content))
+	}
+	for name, content := range files {
+		add(name, content, 0755)
+	}
+	for name, target := range links {
+		add(name, target, os.ModeSymlink|0777)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	return path
+}
+
+func TestBundleInstall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("app bundles and their symlinks are macOS only")
+	}
+	app := filepath.Join(t.TempDir(), "Copy Image.app")
+	exe := filepath.Join(app, "Contents", "MacOS", "copyimage")
+	writeFile(t, exe, "v1")
+	download := writeZip(t,
+		map[string]string{"Copy Image.app/Contents/MacOS/copyimage": "v2", "Copy Image.app/Contents/Info.plist": "plist"},
+		map[string]string{"Copy Image.app/Contents/Current": "MacOS"},
+	)
+
+	if err := (&bundle{path: app}).Install(download); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	if got := readFile(t, exe); got != "v2" {
+		t.Errorf("Expected the new bundle installed, got %q", got)
+	}
+	if got := readFile(t, filepath.Join(app+".old", "Contents", "MacOS", "copyimage")); got != "v1" {
+		t.Errorf("Expected the old bundle kept, got %q", got)
+	}
+	if target, err := os.Readlink(filepath.Join(app, "Contents", "Current")); err != nil || target != "MacOS" {
+		t.Errorf("Expected the symlink kept, got %q (%v)", target, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(app)); len(entries) != 2 {
+		t.Errorf("Expected only the bundles left, got %v", entries)
+	}
+}
+
+func TestBundleInstallRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"no bundle", map[string]string{"copyimage": "v2"}},
+		{"escaping name", map[string]string{"../evil.app/x": "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := filepath.Join(t.TempDir(), "Copy Image.app")
+			exe := filepath.Join(app, "Contents", "MacOS", "copyimage")
+			writeFile(t, exe, "v1")
+			if err := (&bundle{path: app}).Install(writeZip(t, tt.files, nil)); err == nil {
+				t.Fatal("Expected an error")
+			}
+			if got := readFile(t, exe); got != "v1" {
+				t.Errorf("Expected the bundle left as it was, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package updater
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ChecksumsFile is the asset of a release listing the SHA-256 of the
+// others, in the format of sha256sum.
+const ChecksumsFile = "checksums.txt"
+
+// SignatureFile is the asset of a release holding the minisign signature
+// of ChecksumsFile.
+const SignatureFile = ChecksumsFile + ".minisig"
+
+// PublicKey is the minisign public key the checksums of releases are
+// signed with, set for release builds with
+// -ldflags "-X copy-image/internal/updater.PublicKey=RW...". Builds
+// without it check the checksums only.
+var PublicKey = ""
+
+// Errors of Verify, matched with errors.Is.
+var (
+	// ErrNoChecksum is matched when the release lists no checksum for
+	// the download.
+	ErrNoChecksum = errors.New("the release lists no checksum for the download")
+	// ErrChecksumMismatch is matched when the download differs from what
+	// the release lists.
+	ErrChecksumMismatch = errors.New("the download does not match the release checksum")
+	// ErrBadSignature is matched when the checksums are not signed, or
+	// not by PublicKey.
+	ErrBadSignature = errors.New("the release checksums are not signed with the release key")
+)
+
+// maxMetadata bounds the checksums and signature files read, which are a
+// few hundred bytes.
+const maxMetadata = 1 << 20
+
+// Verify checks the file downloaded from downloadURL to path against the
+// checksums published next to it in its release, and with PublicKey set,
+// their signature. Anything missing or not matching refuses the update.
+func Verify(ctx context.Context, client *http.Client, downloadURL, path string) error {
+	name, sumsURL, err := sibling(downloadURL, ChecksumsFile)
+	if err != nil {
+		return err
+	}
+	sums, err := fetch(ctx, client, sumsURL)
+	if err != nil {
+		return fmt.Errorf("failed to download checksums: %w", err)
+	}
+	if PublicKey != "" {
+		_, sigURL, _ := sibling(downloadURL, SignatureFile)
+		sig, err := fetch(ctx, client, sigURL)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrBadSignature, err)
+		}
+		if err := VerifySignature(sums, sig, PublicKey); err != nil {
+			return err
+		}
+	}
+	sum, err := Checksum(sums, name)
+	if err != nil {
+		return err
+	}
+	return VerifyFile(path, sum)
+}
+
+// sibling returns the name of the asset at assetURL and the URL of the
+// asset named name in the same release.
+func sibling(assetURL, name string) (asset, siblingURL string, err error) {
+	u, err := url.Parse(assetURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid download URL: %w", err)
+	}
+	asset = path.Base(u.Path)
+	u.Path = path.Join(path.Dir(u.Path), name)
+	u.RawPath = ""
+	u.RawQuery = ""
+	return asset, u.String(), nil
+}
+
+// fetch returns the body of a small file at url.
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxMetadata))
+}
+
+// Checksum returns the SHA-256 listed for name in sums, a file in the
+// format of sha256sum: the hash, a space and a space or "*", the name.
+func Checksum(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		sum, file, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok || strings.TrimLeft(file, " *") != name {
+			continue
+		}
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+			return "", fmt.Errorf("%w: invalid checksum for %s", ErrNoChecksum, name)
+		}
+		return strings.ToLower(sum), nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNoChecksum, name)
+}
+
+// VerifyFile checks that the SHA-256 of the file at path is sum.
+func VerifyFile(path, sum string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to read download: %w", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != sum {
+		return fmt.Errorf("%w: got %s, want %s", ErrChecksumMismatch, got, sum)
+	}
+	return nil
+}
+
+// VerifySignature checks that sig, the contents of a minisign signature
+// file, signs message with the secret key of publicKey, a minisign public
+// key or the contents of its file. Both legacy and prehashed signatures
+// are accepted, and the trusted comment is checked too.
+func VerifySignature(message, sig []byte, publicKey string) error {
+	pk, err := decodeLine(lastLine(publicKey), 42, "Ed")
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+	keyID, key := pk[2:10], ed25519.PublicKey(pk[10:])
+
+	lines := strings.Split(strings.ReplaceAll(strings.TrimSpace(string(sig)), "\r\n", "\n"), "\n")
+	if len(lines) != 4 {
+		return fmt.Errorf("%w: malformed signature", ErrBadSignature)
+	}
+	s, err := decodeLine(lines[1], 74, "")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBadSignature, err)
+	}
+	if !bytes.Equal(s[2:10], keyID) {
+		return fmt.Errorf("%w: signed with another key", ErrBadSignature)
+	}
+	signed := message
+	switch string(s[:2]) {
+	case "Ed":
+	case "ED":
+		sum := blake2b.Sum512(message)
+		signed = sum[:]
+	default:
+		return fmt.Errorf("%w: unknown algorithm", ErrBadSignature)
+	}
+	if !ed25519.Verify(key, signed, s[10:]) {
+		return ErrBadSignature
+	}
+
+	comment, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return fmt.Errorf("%w: missing trusted comment", ErrBadSignature)
+	}
+	global, err := decodeLine(lines[3], ed25519.SignatureSize, "")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrBadSignature, err)
+	}
+	if !ed25519.Verify(key, append(append([]byte{}, s[10:]...), comment...), global) {
+		return fmt.Errorf("%w: trusted comment was changed", ErrBadSignature)
+	}
+	return nil
+}
+
+// decodeLine decodes a base64 line of a minisign file, checking its size
+// and, when not empty, its algorithm prefix.
+func decodeLine(line string, size int, alg string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(line))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != size || alg != "" && string(b[:2]) != alg {
+		return nil, errors.New("unexpected format")
+	}
+	return b, nil
+}
+
+// lastLine returns the last line of s that is not empty.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
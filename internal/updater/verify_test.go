@@ -0,0 +1,159 @@
+package updater
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignKey returns a new key pair, the public key as minisign writes
+// it.
+func minisignKey(t *testing.T) (string, ed25519.PrivateKey, []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID := []byte("8bytesid")
+	pk := append(append([]byte("Ed"), keyID...), pub...)
+	return "untrusted comment: minisign public key\n" + base64.StdEncoding.EncodeToString(pk), priv, keyID
+}
+
+// minisign signs message like minisign does, prehashed unless legacy.
+func minisign(priv ed25519.PrivateKey, keyID, message []byte, legacy bool, comment string) []byte {
+	alg, signed := "ED", message
+	if legacy {
+		alg = "Ed"
+	} else {
+		sum := blake2b.Sum512(message)
+		signed = sum[:]
+	}
+	sig := ed25519.Sign(priv, signed)
+	global := ed25519.Sign(priv, append(append([]byte{}, sig...), comment...))
+	return fmt.Appendf(nil, "untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append([]byte(alg), keyID...), sig...)),
+		comment, base64.StdEncoding.EncodeToString(global))
+}
+
+func TestVerifySignature(t *testing.T) {
+	pub, priv, keyID := minisignKey(t)
+	otherPub, _, _ := minisignKey(t)
+	message := []byte("checksums")
+
+	tests := []struct {
+		name string
+		sig  []byte
+		key  string
+		ok   bool
+	}{
+		{"prehashed", minisign(priv, keyID, message, false, "timestamp:1"), pub, true},
+		{"legacy", minisign(priv, keyID, message, true, "timestamp:1"), pub, true},
+		{"other message", minisign(priv, keyID, []byte("tampered"), false, "timestamp:1"), pub, false},
+		{"other key", minisign(priv, keyID, message, false, "timestamp:1"), otherPub, false},
+		{"malformed", []byte("not a signature"), pub, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(message, tt.sig, tt.key)
+			if tt.ok && err != nil {
+				t.Errorf("Expected a valid signature, got %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrBadSignature) {
+				t.Errorf("Expected ErrBadSignature, got %v", err)
+			}
+		})
+	}
+
+	// Changing the trusted comment breaks the global signature.
+	lines := strings.Split(string(minisign(priv, keyID, message, false, "timestamp:1")), "\n")
+	lines[2] = "trusted comment: timestamp:2"
+	if err := VerifySignature(message, []byte(strings.Join(lines, "\n")), pub); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Expected a changed trusted comment to be refused, got %v", err)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	sum := hex.EncodeToString(make([]byte, sha256.Size))
+	sums := []byte(sum + "  copyimage-cli-linux-amd64\n" + sum[:63] + "1 *copyimage-desktop-windows-amd64.exe\n")
+
+	if got, err := Checksum(sums, "copyimage-cli-linux-amd64"); err != nil || got != sum {
+		t.Errorf("Expected %s, got %s (%v)", sum, got, err)
+	}
+	if got, err := Checksum(sums, "copyimage-desktop-windows-amd64.exe"); err != nil || got != sum[:63]+"1" {
+		t.Errorf("Expected the binary mode entry, got %s (%v)", got, err)
+	}
+	if _, err := Checksum(sums, "copyimage-cli-darwin-arm64"); !errors.Is(err, ErrNoChecksum) {
+		t.Errorf("Expected ErrNoChecksum, got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	pub, priv, keyID := minisignKey(t)
+	binary := []byte("new build")
+	sum := sha256.Sum256(binary)
+	sums := []byte(hex.EncodeToString(sum[:]) + "  copyimage-cli-linux-amd64\n")
+
+	files := map[string][]byte{
+		"/releases/download/v2.0.0/" + ChecksumsFile: sums,
+		"/releases/download/v2.0.0/" + SignatureFile: minisign(priv, keyID, sums, false, "timestamp:1"),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+	downloadURL := srv.URL + "/releases/download/v2.0.0/copyimage-cli-linux-amd64"
+
+	download := filepath.Join(t.TempDir(), "download")
+	write := func(data []byte) {
+		if err := os.WriteFile(download, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	verify := func(key string) error {
+		old := PublicKey
+		PublicKey = key
+		defer func() { PublicKey = old }()
+		return Verify(context.Background(), srv.Client(), downloadURL, download)
+	}
+
+	write(binary)
+	if err := verify(pub); err != nil {
+		t.Fatalf("Expected the download to verify, got %v", err)
+	}
+	if err := verify(""); err != nil {
+		t.Errorf("Expected the checksum alone to verify without a key, got %v", err)
+	}
+
+	write([]byte("tampered build"))
+	if err := verify(pub); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+	}
+
+	write(binary)
+	delete(files, "/releases/download/v2.0.0/"+SignatureFile)
+	if err := verify(pub); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Expected a missing signature to be refused, got %v", err)
+	}
+	delete(files, "/releases/download/v2.0.0/"+ChecksumsFile)
+	if err := verify(""); err == nil {
+		t.Error("Expected missing checksums to be refused")
+	}
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FreeSpace returns the bytes available to the user on the volume holding
+// path. A path that does not exist yet, such as a destination folder
+// created by the first copy, is measured at its closest existing parent.
+func FreeSpace(path string) (uint64, error) {
+	path = filepath.Clean(path)
+	for {
+		_, err := os.Stat(path)
+		if err == nil {
+			return freeSpace(path)
+		}
+		parent := filepath.Dir(path)
+		if !errors.Is(err, fs.ErrNotExist) || parent == path {
+			return 0, err
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package utils
+
+import "golang.org/x/sys/unix"
+
+func freeSpace(path string) (uint64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	// Bavail leaves out the blocks reserved for root.
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFreeSpace(t *testing.T) {
+	dir := t.TempDir()
+	free, err := FreeSpace(dir)
+	if err != nil {
+		t.Fatalf("FreeSpace failed: %v", err)
+	}
+	if free == 0 {
+		t.Error("Expected some free space in the temporary directory")
+	}
+
+	// A folder yet to be created is measured on its parent's volume.
+	if _, err := FreeSpace(filepath.Join(dir, "not", "yet")); err != nil {
+		t.Errorf("Expected the free space of a missing folder, got %v", err)
+	}
+}
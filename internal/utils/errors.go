@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"errors"
+	"io/fs"
+	"syscall"
+)
+
+// Kinds of file errors, told apart so that only transient ones are retried
+// and users see what actually went wrong. Use errors.Is to test an error
+// returned by Classify.
+var (
+	ErrLocked     = errors.New("file is locked by another program")
+	ErrPermission = errors.New("access denied")
+	ErrNotFound   = errors.New("file not found")
+	ErrNetwork    = errors.New("network error")
+	ErrDiskFull   = errors.New("not enough space on the disk")
+)
+
+// FileError is an error with its kind (ErrLocked, ErrPermission,
+// ErrNotFound, ErrNetwork or ErrDiskFull), both of which errors.Is
+// matches.
+type FileError struct {
+	Kind error
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *FileError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Classify returns err as a *FileError when its kind is known, and err
+// itself otherwise (nil for nil).
+func Classify(err error) error {
+	if kind := kindOf(err); kind != nil {
+		return &FileError{Kind: kind, Err: err}
+	}
+	return err
+}
+
+// Retryable reports whether retrying the operation that failed with err
+// may help: locks are released and networks come back, but missing files,
+// denied access and full disks stay that way.
+func Retryable(err error) bool {
+	return !errors.Is(err, ErrPermission) && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrDiskFull)
+}
+
+// kindOf returns the kind of err, or nil when it is none of the known ones.
+// Locks and network errors are checked first: some of them also count as
+// permission errors for the os package.
+func kindOf(err error) error {
+	var fe *FileError
+	if err == nil || errors.As(err, &fe) {
+		return nil
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		if kind := errnoKind(errno); kind != nil {
+			return kind
+		}
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return ErrNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return ErrPermission
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package utils
+
+import "syscall"
+
+// errnoKind classifies the errors that fs.ErrPermission and fs.ErrNotExist
+// do not cover, or cover misleadingly.
+func errnoKind(errno syscall.Errno) error {
+	switch errno {
+	case syscall.EAGAIN, syscall.EBUSY, syscall.ETXTBSY:
+		return ErrLocked
+	case syscall.ESTALE, syscall.EHOSTDOWN, syscall.EHOSTUNREACH, syscall.ENETDOWN,
+		syscall.ENETUNREACH, syscall.ENETRESET, syscall.ECONNRESET, syscall.ECONNABORTED,
+		syscall.ETIMEDOUT:
+		return ErrNetwork
+	case syscall.ENOSPC, syscall.EDQUOT:
+		return ErrDiskFull
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package utils
+
+import "syscall"
+
+// Errors meaning a lock, a network failure and a full disk, for
+// TestClassify.
+const (
+	lockErrno     = syscall.EBUSY
+	networkErrno  = syscall.ENETUNREACH
+	diskFullErrno = syscall.ENOSPC
+)
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	_, notFound := os.Open("/non/existent/file.txt")
+	tests := []struct {
+		name      string
+		err       error
+		kind      error
+		retryable bool
+	}{
+		{"not found", notFound, ErrNotFound, false},
+		{"wrapped not found", fmt.Errorf("failed to open source file: %w", notFound), ErrNotFound, false},
+		{"permission", &fs.PathError{Op: "open", Path: "a", Err: fs.ErrPermission}, ErrPermission, false},
+		{"locked", &fs.PathError{Op: "open", Path: "a", Err: lockErrno}, ErrLocked, true},
+		{"network", &fs.PathError{Op: "read", Path: "a", Err: networkErrno}, ErrNetwork, true},
+		{"disk full", &fs.PathError{Op: "write", Path: "a", Err: diskFullErrno}, ErrDiskFull, false},
+		{"unknown", errors.New("disk full"), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.err)
+			if !errors.Is(got, tt.err) {
+				t.Errorf("Classify() = %v, does not wrap the original error", got)
+			}
+			for _, kind := range []error{ErrLocked, ErrPermission, ErrNotFound, ErrNetwork, ErrDiskFull} {
+				if is := errors.Is(got, kind); is != (kind == tt.kind) {
+					t.Errorf("errors.Is(%v, %v) = %v", got, kind, is)
+				}
+			}
+			if Retryable(got) != tt.retryable {
+				t.Errorf("Retryable(%v) = %v, want %v", got, !tt.retryable, tt.retryable)
+			}
+			if again := Classify(got); again != got {
+				t.Errorf("Classify() of a classified error = %v, want it unchanged", again)
+			}
+		})
+	}
+
+	if Classify(nil) != nil {
+		t.Error("Classify(nil) should be nil")
+	}
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// errnoKind classifies the Windows errors that fs.ErrPermission and
+// fs.ErrNotExist do not cover, or cover misleadingly.
+func errnoKind(errno syscall.Errno) error {
+	switch errno {
+	case windows.ERROR_SHARING_VIOLATION, windows.ERROR_LOCK_VIOLATION:
+		return ErrLocked
+	case windows.ERROR_BAD_NETPATH, windows.ERROR_BAD_NET_NAME, windows.ERROR_NETNAME_DELETED,
+		windows.ERROR_NETWORK_BUSY, windows.ERROR_DEV_NOT_EXIST, windows.ERROR_UNEXP_NET_ERR,
+		windows.ERROR_REM_NOT_LIST, windows.ERROR_SEM_TIMEOUT, windows.ERROR_NO_NETWORK,
+		windows.ERROR_NETWORK_UNREACHABLE, windows.ERROR_HOST_UNREACHABLE, windows.ERROR_CONNECTION_ABORTED:
+		return ErrNetwork
+	case windows.ERROR_NETWORK_ACCESS_DENIED:
+		return ErrPermission
+	case windows.ERROR_DISK_FULL, windows.ERROR_HANDLE_DISK_FULL:
+		return ErrDiskFull
+	}
+	return nil
+}
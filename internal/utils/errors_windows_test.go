@@ -0,0 +1,11 @@
+package utils
+
+import "golang.org/x/sys/windows"
+
+// Errors meaning a lock, a network failure and a full disk, for
+// TestClassify.
+const (
+	lockErrno     = windows.ERROR_SHARING_VIOLATION
+	networkErrno  = windows.ERROR_BAD_NETPATH
+	diskFullErrno = windows.ERROR_DISK_FULL
+)
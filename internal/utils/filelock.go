@@ -1,26 +1,29 @@
 package utils
 
 import (
+	"errors"
 	"os"
 )
 
 // IsFileLocked checks if a file is currently locked by another process
-// Returns true if the file is locked for reading, false otherwise.
+// Returns true if the file is locked for reading, false otherwise, even
+// when it cannot be read for another reason (see CheckReadable).
 // Note: We only check for read access because we only need to read the file to copy it.
 // Checking O_RDWR (Read/Write) causes "locked" errors if the file is Read-Only or
 // if the user doesn't have Write permissions (common on network shares).
 func IsFileLocked(filePath string) bool {
-	// Try to open for READ ONLY.
-	// If we can read it, we can copy it.
+	return errors.Is(CheckReadable(filePath), ErrLocked)
+}
+
+// CheckReadable returns nil if the file can be opened for reading, and
+// otherwise why not as a classified error (see Classify).
+func CheckReadable(filePath string) error {
 	file, err := os.Open(filePath)
 	if err != nil {
-		// Only consider it locked if we can't even read it.
-		// Detailed error checking could distinguish "locked" vs "permission denied",
-		// but for now, if we can't read it, we can't copy it anyway.
-		return true
+		return Classify(err)
 	}
 	_ = file.Close()
-	return false
+	return nil
 }
 
 // FileExists checks if a file exists at the given path
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package utils
+
+// IsFileBeingWritten always reports false: Unix systems do not tell whether
+// a file is open for writing without scanning every process, so callers
+// rely on its size settling instead.
+func IsFileBeingWritten(string) bool {
+	return false
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -22,9 +23,13 @@ func TestIsFileLocked(t *testing.T) {
 }
 
 func TestIsFileLockedNonExistent(t *testing.T) {
-	// Non-existent file should return true (like locked)
-	if !IsFileLocked("/non/existent/file.txt") {
-		t.Error("Expected true for non-existent file")
+	// A missing file is not locked, and CheckReadable says why it cannot
+	// be read.
+	if IsFileLocked("/non/existent/file.txt") {
+		t.Error("Expected false for non-existent file")
+	}
+	if err := CheckReadable("/non/existent/file.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("CheckReadable() error = %v, want ErrNotFound", err)
 	}
 }
 
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsFileBeingWritten reports whether another program has the file open for
+// writing. Opening it while denying others write access fails with a
+// sharing violation in that case.
+func IsFileBeingWritten(path string) bool {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return false
+	}
+	h, err := windows.CreateFile(p, windows.GENERIC_READ, windows.FILE_SHARE_READ, nil,
+		windows.OPEN_EXISTING, windows.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return errors.Is(err, windows.ERROR_SHARING_VIOLATION)
+	}
+	_ = windows.CloseHandle(h)
+	return false
+}
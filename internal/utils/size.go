@@ -0,0 +1,23 @@
+package utils
+
+import "fmt"
+
+// FormatBytes returns n as a size for people to read, in the decimal
+// units file managers and disk labels use: "512 B", "3.4 MB", "118 MB".
+func FormatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	v := float64(n) / float64(div)
+	// Three digits are enough; "118.3 MB" reads no better than "118 MB".
+	if v >= 100 {
+		return fmt.Sprintf("%.0f %cB", v, "kMGTPE"[exp])
+	}
+	return fmt.Sprintf("%.1f %cB", v, "kMGTPE"[exp])
+}
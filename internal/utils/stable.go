@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrStillWriting is returned by WaitStable for a file that kept changing.
+var ErrStillWriting = errors.New("file is still being written")
+
+// WaitStable waits until the file at path is complete: its size and
+// modification time stay the same over polls checks interval apart, and
+// no program has it open for writing where that can be detected. Cameras
+// and tethering software write files in bursts, and copying in between
+// produces a truncated copy.
+//
+// A file last modified longer ago than the polls would take is taken as
+// complete right away, so settled files cost a single stat. WaitStable
+// gives up with ErrStillWriting after timeout.
+func WaitStable(ctx context.Context, path string, polls int, interval, timeout time.Duration) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	window := time.Duration(polls) * interval
+	if time.Since(info.ModTime()) > window && !IsFileBeingWritten(path) {
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	unchanged := 0
+	for unchanged < polls || IsFileBeingWritten(path) {
+		if time.Now().After(deadline) {
+			return ErrStillWriting
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		next, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if next.Size() == info.Size() && next.ModTime().Equal(info.ModTime()) {
+			unchanged++
+		} else {
+			info, unchanged = next, 0
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWaitStable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.jpg")
+	if err := os.WriteFile(path, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Old files are complete without polling.
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := WaitStable(ctx, path, 3, time.Second, time.Minute); err != nil {
+		t.Fatalf("WaitStable() error = %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("WaitStable() polled a settled file")
+	}
+
+	// A file still growing is waited for until it stops.
+	if err := os.WriteFile(path, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return
+		}
+		defer func() { _ = f.Close() }()
+		for range 5 {
+			time.Sleep(20 * time.Millisecond)
+			_, _ = f.WriteString("more")
+		}
+	}()
+	if err := WaitStable(ctx, path, 3, 30*time.Millisecond, time.Minute); err != nil {
+		t.Fatalf("WaitStable() error = %v", err)
+	}
+	select {
+	case <-done:
+	default:
+		t.Error("WaitStable() returned while the file was still being written")
+	}
+	if info, _ := os.Stat(path); info.Size() != 21 {
+		t.Errorf("size = %d, want the complete file", info.Size())
+	}
+
+	// A file that keeps changing times out.
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i := 1; ; i++ {
+			select {
+			case <-stop:
+				return
+			case <-time.After(5 * time.Millisecond):
+				_ = os.WriteFile(path, make([]byte, i), 0644)
+			}
+		}
+	}()
+	err := WaitStable(ctx, path, 3, 20*time.Millisecond, 100*time.Millisecond)
+	close(stop)
+	<-stopped
+	if !errors.Is(err, ErrStillWriting) {
+		t.Errorf("WaitStable() error = %v, want ErrStillWriting", err)
+	}
+}
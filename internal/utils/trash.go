@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrNoTrash is returned by MoveToTrash for a file on a drive without a
+// recycle bin, such as a network share or a USB stick on Windows, which
+// would otherwise be deleted for good.
+var ErrNoTrash = errors.New("the drive has no recycle bin")
+
+// Delete removes the file at path, or with useTrash moves it to the OS
+// recycle bin so it can still be restored. Features that delete files
+// (undo, ...) go through here so the use_trash setting applies to all.
+func Delete(path string, useTrash bool) error {
+	if useTrash {
+		return MoveToTrash(path)
+	}
+	return os.Remove(path)
+}
+
+// MoveToTrash moves the file or folder at path to the recycle bin: the
+// Windows Recycle Bin, the macOS Trash, or the freedesktop.org trash on
+// Linux and other Unix systems.
+func MoveToTrash(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(abs); err != nil {
+		return err
+	}
+	if err := moveToTrash(abs); err != nil {
+		return fmt.Errorf("failed to move %s to the trash: %w", path, err)
+	}
+	return nil
+}
+
+// uniqueName returns name, or name with a number before its extension
+// ("a 2.jpg", "a 3.jpg", ...), for which taken reports false.
+func uniqueName(name string, taken func(name string) bool) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for i := 2; taken(candidate); i++ {
+		candidate = base + " " + strconv.Itoa(i) + ext
+	}
+	return candidate
+}
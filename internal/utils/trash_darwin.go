@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// moveToTrash moves path into ~/.Trash. Files on other volumes cannot be
+// renamed there and fail rather than being copied.
+func moveToTrash(path string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	trash := filepath.Join(home, ".Trash")
+	name := uniqueName(filepath.Base(path), func(name string) bool {
+		_, err := os.Lstat(filepath.Join(trash, name))
+		return err == nil
+	})
+	return os.Rename(path, filepath.Join(trash, name))
+}
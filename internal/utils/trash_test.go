@@ -0,0 +1,60 @@
+//go:build !windows && !darwin
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMoveToTrash(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	trash := filepath.Join(dir, "data", "Trash")
+
+	for i := range 2 {
+		path := filepath.Join(dir, "a b.jpg")
+		if err := os.WriteFile(path, []byte{byte(i)}, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := Delete(path, true); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if FileExists(path) {
+			t.Fatalf("%s still exists", path)
+		}
+	}
+
+	// The second file with the same name gets a unique one.
+	for _, name := range []string{"a b.jpg", "a b 2.jpg"} {
+		if !FileExists(filepath.Join(trash, "files", name)) {
+			t.Errorf("%s not in the trash", name)
+		}
+		info, err := os.ReadFile(filepath.Join(trash, "info", name+".trashinfo"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "Path=" + filepath.ToSlash(dir) + "/a%20b.jpg\n"
+		if !strings.HasPrefix(string(info), "[Trash Info]\n") || !strings.Contains(string(info), want) {
+			t.Errorf("trashinfo = %q, want it to contain %q", info, want)
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.jpg")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(path, false); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if FileExists(path) {
+		t.Error("file still exists")
+	}
+	if err := Delete(path, true); !os.IsNotExist(err) {
+		t.Errorf("Delete() of a missing file error = %v, want not exist", err)
+	}
+}
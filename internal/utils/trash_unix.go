@@ -0,0 +1,124 @@
+//go:build !windows && !darwin
+
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// moveToTrash follows the freedesktop.org Trash specification, so file
+// managers list the file and can restore it: the file goes to the home
+// trash when it is on the same file system, and otherwise to
+// $topdir/.Trash-$uid of its own file system.
+func moveToTrash(path string) error {
+	trash, relTo, err := trashDir(path)
+	if err != nil {
+		return err
+	}
+	files := filepath.Join(trash, "files")
+	info := filepath.Join(trash, "info")
+	for _, dir := range []string{files, info} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	stored := path
+	if relTo != "" {
+		if rel, err := filepath.Rel(relTo, path); err == nil {
+			stored = rel
+		}
+	}
+	content := "[Trash Info]\nPath=" + (&url.URL{Path: stored}).EscapedPath() +
+		"\nDeletionDate=" + time.Now().Format("2006-01-02T15:04:05") + "\n"
+
+	// Names are claimed by creating the .trashinfo file exclusively, so
+	// two processes trashing files with the same name do not collide.
+	taken := func(name string) bool {
+		_, errFile := os.Lstat(filepath.Join(files, name))
+		_, errInfo := os.Lstat(filepath.Join(info, name+".trashinfo"))
+		return errFile == nil || errInfo == nil
+	}
+	for {
+		name := uniqueName(filepath.Base(path), taken)
+		infoPath := filepath.Join(info, name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = f.WriteString(content)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(path, filepath.Join(files, name))
+		}
+		if err != nil {
+			_ = os.Remove(infoPath)
+		}
+		return err
+	}
+}
+
+// trashDir returns the trash to use for path and, for a trash at the top
+// of another file system, the directory stored paths are relative to.
+func trashDir(path string) (dir, relTo string, err error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	home := filepath.Join(dataHome, "Trash")
+
+	dev, err := device(filepath.Dir(path))
+	if err != nil {
+		return "", "", err
+	}
+	if homeDev, err := device(existingParent(home)); err == nil && homeDev == dev {
+		return home, "", nil
+	}
+
+	top := filepath.Dir(path)
+	for parent := filepath.Dir(top); parent != top; parent = filepath.Dir(top) {
+		if d, err := device(parent); err != nil || d != dev {
+			break
+		}
+		top = parent
+	}
+	return filepath.Join(top, ".Trash-"+strconv.Itoa(os.Getuid())), top, nil
+}
+
+// existingParent returns path or its closest existing parent.
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// device returns the ID of the file system holding path.
+func device(path string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Dev), nil //nolint:unconvert // Dev is not uint64 everywhere
+}
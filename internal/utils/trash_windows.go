@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procSHFileOperationW = windows.NewLazySystemDLL("shell32.dll").NewProc("SHFileOperationW")
+
+// Constants of SHFileOperation (shellapi.h).
+const (
+	foDelete           = 0x0003
+	fofSilent          = 0x0004
+	fofNoConfirmation  = 0x0010
+	fofAllowUndo       = 0x0040
+	fofNoErrorUI       = 0x0400
+	fofWantNukeWarning = 0x4000
+)
+
+// shFileOpStruct is SHFILEOPSTRUCTW, which has natural alignment on 64-bit
+// Windows.
+type shFileOpStruct struct {
+	hwnd                  uintptr
+	wFunc                 uint32
+	pFrom                 *uint16
+	pTo                   *uint16
+	fFlags                uint16
+	fAnyOperationsAborted int32
+	hNameMappings         uintptr
+	lpszProgressTitle     *uint16
+}
+
+// moveToTrash deletes path with FOF_ALLOWUNDO, which sends it to the
+// Recycle Bin without any dialog. Windows deletes files for good where
+// they cannot be recycled, silently with these flags, so files on drives
+// without a Recycle Bin, which are network shares and removable drives,
+// are refused with ErrNoTrash. A file too large for the Recycle Bin is
+// only deleted once the user confirms it, or else is kept.
+func moveToTrash(path string) error {
+	if err := checkRecyclable(path); err != nil {
+		return err
+	}
+
+	// pFrom is a list of paths ending with an extra NUL.
+	from, err := syscall.UTF16FromString(path)
+	if err != nil {
+		return err
+	}
+	from = append(from, 0)
+
+	op := shFileOpStruct{
+		wFunc:  foDelete,
+		pFrom:  &from[0],
+		fFlags: fofAllowUndo | fofNoConfirmation | fofNoErrorUI | fofSilent | fofWantNukeWarning,
+	}
+	r, _, _ := procSHFileOperationW.Call(uintptr(unsafe.Pointer(&op)))
+	switch {
+	case r != 0:
+		return fmt.Errorf("SHFileOperation error 0x%x", r)
+	case op.fAnyOperationsAborted != 0:
+		return fmt.Errorf("operation was aborted")
+	}
+	return nil
+}
+
+// checkRecyclable returns ErrNoTrash unless path is on a fixed drive, the
+// only kind with a Recycle Bin.
+func checkRecyclable(path string) error {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	root := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumePathName(p, &root[0], uint32(len(root))); err != nil {
+		return fmt.Errorf("find the drive of %s: %w", path, err)
+	}
+	if kind := windows.GetDriveType(&root[0]); kind != windows.DRIVE_FIXED {
+		return fmt.Errorf("%s: %w", windows.UTF16ToString(root), ErrNoTrash)
+	}
+	return nil
+}
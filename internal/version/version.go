@@ -0,0 +1,55 @@
+// Package version identifies the build of the desktop app and the CLI, so
+// both report, and compare releases against, the same version.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set for release builds with -ldflags, e.g.
+//
+//	-X copy-image/internal/version.Version=v2.2.0
+//	-X copy-image/internal/version.Commit=3f2a1c9
+//	-X copy-image/internal/version.Date=2026-05-04T10:00:00Z
+//
+// Builds without them report Version as it is here, and the commit and
+// date that Go records for builds from a git checkout, if any.
+var (
+	Version = "v2.1.5"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes the running build.
+type Info struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit,omitempty"`
+	// Date is when the build was made, or for builds from a git checkout,
+	// when its commit was, in RFC 3339 format.
+	Date      string `json:"date,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"` // e.g. "windows/amd64"
+}
+
+// Get returns the build info of the running program.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range build.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.Date == "":
+				info.Date = s.Value
+			}
+		}
+	}
+	return info
+}
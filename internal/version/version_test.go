@@ -0,0 +1,18 @@
+package version
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	saved := []string{Version, Commit, Date}
+	defer func() { Version, Commit, Date = saved[0], saved[1], saved[2] }()
+	Version, Commit, Date = "v9.1.0", "3f2a1c9", "2026-05-04T10:00:00Z"
+
+	info := Get()
+	want := Info{Version: "v9.1.0", Commit: "3f2a1c9", Date: "2026-05-04T10:00:00Z", GoVersion: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH}
+	if info != want {
+		t.Errorf("Get() = %+v, want %+v", info, want)
+	}
+}
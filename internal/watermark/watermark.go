@@ -0,0 +1,161 @@
+// Package watermark draws an image or a line of text over images while
+// they are copied (config.Watermark), so that proofs sent to clients are
+// marked while the archive gets the originals.
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"os"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+	"copy-image/internal/transcode"
+)
+
+// ErrUnsupported is matched by the error for a file that cannot be
+// watermarked. Such files fail rather than be copied unmarked.
+var ErrUnsupported = errors.New("cannot watermark this type of file")
+
+// marked are the formats watermarked, keeping their format.
+var marked = map[string]bool{"jpeg": true, "png": true, "bmp": true, "tiff": true}
+
+// textSize is the font size, in pixels, text is drawn at before being
+// scaled to the image like a watermark image.
+const textSize = 128
+
+// New returns the processor drawing w. The watermark image is read, or the
+// text drawn, once here rather than for every file.
+func New(w config.Watermark) (processor.FileProcessor, error) {
+	var mark image.Image
+	var err error
+	if w.Image != "" {
+		mark, err = load(w.Image)
+	} else {
+		mark, err = drawText(w.Text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("watermark: %w", err)
+	}
+	return watermarker{Watermark: w, mark: mark}, nil
+}
+
+type watermarker struct {
+	config.Watermark
+	mark image.Image
+}
+
+func (watermarker) Name() string { return "watermark" }
+
+// Transform draws the watermark over the image in r. The format is read
+// from the content, as an earlier processor may have converted the file.
+func (m watermarker) Transform(ctx context.Context, f processor.File, r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || !marked[format] {
+		return nil, fmt.Errorf("%s: %w", f.Name, ErrUnsupported)
+	}
+	// Oriented first, so that the watermark is where it is expected once
+	// the image is displayed.
+	img, err := imaging.Decode(bytes.NewReader(data), imaging.AutoOrientation(true))
+	if err != nil {
+		return nil, fmt.Errorf("cannot watermark %s: %w", f.Name, err)
+	}
+	var out bytes.Buffer
+	if err := transcode.Encode(&out, m.apply(img), config.TranscodeRule{To: format, Quality: m.Quality}); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// apply returns img with the watermark drawn over it.
+func (m watermarker) apply(img image.Image) image.Image {
+	bounds := img.Bounds()
+	width := max(1, int(math.Round(float64(bounds.Dx())*m.Scale())))
+	mark := imaging.Resize(m.mark, width, 0, imaging.Lanczos)
+
+	// The margin keeps the watermark off the edges, where it is easily
+	// cropped out.
+	margin := min(bounds.Dx(), bounds.Dy()) / 40
+	size := mark.Bounds().Size()
+	var at image.Point
+	switch m.Anchor() {
+	case config.PositionTopLeft:
+		at = image.Pt(margin, margin)
+	case config.PositionTopRight:
+		at = image.Pt(bounds.Dx()-size.X-margin, margin)
+	case config.PositionBottomLeft:
+		at = image.Pt(margin, bounds.Dy()-size.Y-margin)
+	case config.PositionCenter:
+		at = image.Pt((bounds.Dx()-size.X)/2, (bounds.Dy()-size.Y)/2)
+	default:
+		at = image.Pt(bounds.Dx()-size.X-margin, bounds.Dy()-size.Y-margin)
+	}
+
+	out := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(out, out.Bounds(), img, bounds.Min, draw.Src)
+	alpha := image.NewUniform(color.Alpha{A: uint8(math.Round(m.Alpha() * 255))})
+	draw.DrawMask(out, image.Rectangle{Min: at, Max: at.Add(size)}, mark, image.Point{}, alpha, image.Point{}, draw.Over)
+	return out
+}
+
+func load(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// drawText returns text drawn in white with a dark outline, which stays
+// readable over both light and dark areas.
+func drawText(text string) (image.Image, error) {
+	ttf, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, err
+	}
+	face, err := opentype.NewFace(ttf, &opentype.FaceOptions{Size: textSize, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = face.Close() }()
+
+	outline := textSize / 16
+	metrics := face.Metrics()
+	width := font.MeasureString(face, text).Ceil() + 2*outline
+	height := (metrics.Ascent + metrics.Descent).Ceil() + 2*outline
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	d := &font.Drawer{Dst: out, Face: face, Src: image.NewUniform(color.NRGBA{A: 160})}
+	baseline := outline + metrics.Ascent.Ceil()
+	for dy := -outline; dy <= outline; dy += outline {
+		for dx := -outline; dx <= outline; dx += outline {
+			d.Dot = fixed.P(outline+dx, baseline+dy)
+			d.DrawString(text)
+		}
+	}
+	d.Src = image.NewUniform(color.White)
+	d.Dot = fixed.P(outline, baseline)
+	d.DrawString(text)
+	return out, nil
+}
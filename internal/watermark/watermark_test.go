@@ -0,0 +1,123 @@
+package watermark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"copy-image/internal/config"
+	"copy-image/internal/processor"
+)
+
+var gray = color.NRGBA{R: 128, G: 128, B: 128, A: 255}
+
+func grayPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(gray), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// apply watermarks a gray 400x200 PNG.
+func apply(t *testing.T, w config.Watermark) image.Image {
+	t.Helper()
+	p, err := New(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := p.(processor.Transformer).Transform(context.Background(), processor.File{Name: "a.png"}, bytes.NewReader(grayPNG(t, 400, 200)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 400, 200) {
+		t.Fatalf("Expected a 400x200 image, got %v", img.Bounds())
+	}
+	return img
+}
+
+// changed returns the number of pixels in area that are no longer gray.
+func changed(img image.Image, area image.Rectangle) int {
+	n := 0
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			if color.NRGBAModel.Convert(img.At(x, y)) != gray {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestWatermarkImage(t *testing.T) {
+	logo := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(logo, logo.Bounds(), image.NewUniform(color.NRGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+	path := filepath.Join(t.TempDir(), "logo.png")
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, logo); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A 100x100 square in the middle, at 25% of the width.
+	img := apply(t, config.Watermark{Image: path, Position: config.PositionCenter, Size: 25})
+	if n := changed(img, image.Rect(150, 50, 250, 150)); n != 100*100 {
+		t.Errorf("Expected the center square to be marked, got %d pixels", n)
+	}
+	if n := changed(img, img.Bounds()); n != 100*100 {
+		t.Errorf("Expected nothing else to be marked, got %d pixels", n)
+	}
+	if c := color.NRGBAModel.Convert(img.At(200, 100)).(color.NRGBA); c.R <= gray.R || c.R == 255 {
+		t.Errorf("Expected red at half opacity, got %v", c)
+	}
+}
+
+func TestWatermarkText(t *testing.T) {
+	tests := []struct {
+		position string
+		area     image.Rectangle // the quarter of the image holding the text
+	}{
+		{config.PositionTopLeft, image.Rect(0, 0, 200, 100)},
+		{config.PositionTopRight, image.Rect(200, 0, 400, 100)},
+		{"", image.Rect(200, 100, 400, 200)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.position, func(t *testing.T) {
+			img := apply(t, config.Watermark{Text: "PROOF", Position: tt.position, Size: 40})
+			all, in := changed(img, img.Bounds()), changed(img, tt.area)
+			if all == 0 || in != all {
+				t.Errorf("Expected the text in %v, got %d of %d pixels there", tt.area, in, all)
+			}
+		})
+	}
+}
+
+func TestWatermarkUnsupported(t *testing.T) {
+	p, err := New(config.Watermark{Text: "PROOF"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.(processor.Transformer).Transform(context.Background(), processor.File{Name: "a.cr3"}, bytes.NewReader([]byte("RAW data")))
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if _, err := New(config.Watermark{Image: filepath.Join(t.TempDir(), "missing.png")}); err == nil {
+		t.Error("Expected an error for a missing watermark image")
+	}
+}
@@ -0,0 +1,146 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	"copy-image/internal/queue"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Kinds of queued jobs.
+const (
+	JobGroup = "group" // run the group GroupID
+	JobCard  = "card"  // import the card at Path with the card profile Profile
+	JobCopy  = "copy"  // copy Source to Destination with the current settings
+)
+
+// JobSpec describes a job to queue with EnqueueJob.
+type JobSpec struct {
+	Kind        string `json:"kind"`
+	GroupID     string `json:"groupId,omitempty"`
+	Path        string `json:"path,omitempty"`
+	Profile     string `json:"profile,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+}
+
+// startQueue creates the job queue. Jobs run one at a time, so queued card
+// imports and groups never compete for the same disks; every change emits
+// queue:changed with the jobs.
+func (a *App) startQueue() {
+	a.jobs = queue.New(a.ctx, 1, func(jobs []queue.Job) {
+		runtime.EventsEmit(a.ctx, "queue:changed", jobs)
+	})
+}
+
+// EnqueueJob adds a job to the queue and returns it. The job runs with the
+// settings current when it starts. Each job's Result is its CopyResult; a
+// job with failed files is failed.
+func (a *App) EnqueueJob(spec JobSpec) (queue.Job, error) {
+	var (
+		name string
+		run  func(ctx context.Context) CopyResult
+	)
+	switch spec.Kind {
+	case JobGroup:
+		group := a.currentConfig().FindGroup(spec.GroupID)
+		if group == nil {
+			return queue.Job{}, fmt.Errorf("group %q not found", spec.GroupID)
+		}
+		name = group.Name
+		if name == "" {
+			name = group.ID
+		}
+		run = func(ctx context.Context) CopyResult { return a.runGroupJob(ctx, spec.GroupID) }
+	case JobCard:
+		if a.currentConfig().FindCard(spec.Profile) == nil {
+			return queue.Job{}, fmt.Errorf("card profile %q not found", spec.Profile)
+		}
+		name = fmt.Sprintf("%s (%s)", spec.Profile, spec.Path)
+		run = func(ctx context.Context) CopyResult { return a.importCard(ctx, spec.Path, spec.Profile) }
+	case JobCopy:
+		if spec.Source == "" || spec.Destination == "" {
+			return queue.Job{}, fmt.Errorf("a copy job needs a source and a destination")
+		}
+		name = fmt.Sprintf("%s → %s", spec.Source, spec.Destination)
+		run = func(ctx context.Context) CopyResult { return a.runCopyJob(ctx, spec.Source, spec.Destination) }
+	default:
+		return queue.Job{}, fmt.Errorf("unknown job kind %q", spec.Kind)
+	}
+
+	return a.jobs.Add(name, func(ctx context.Context) (any, error) {
+		result := run(ctx)
+		if !result.Success {
+			return result, fmt.Errorf("%s", result.Message)
+		}
+		return result, nil
+	}), nil
+}
+
+// ListJobs returns the queued, running and finished jobs in queue order.
+func (a *App) ListJobs() []queue.Job {
+	return a.jobs.List()
+}
+
+// CancelJob takes a queued job out of the line, or stops a running one.
+func (a *App) CancelJob(id string) error {
+	return a.jobs.Cancel(id)
+}
+
+// ReorderJobs moves the queued jobs with the given IDs to the front of
+// the line, in that order.
+func (a *App) ReorderJobs(ids []string) error {
+	return a.jobs.Reorder(ids)
+}
+
+// ClearFinishedJobs removes the finished jobs from the list.
+func (a *App) ClearFinishedJobs() {
+	a.jobs.Clear()
+}
+
+// runGroupJob runs a queued group, found again as it may have been edited
+// while the job waited.
+func (a *App) runGroupJob(ctx context.Context, id string) CopyResult {
+	cfg := a.currentConfig()
+	group := cfg.FindGroup(id)
+	if group == nil {
+		return CopyResult{Message: fmt.Sprintf("group %q not found", id)}
+	}
+	summary, err := a.runGroup(ctx, history.KindGroup, cfg, *group)
+	if err != nil {
+		return CopyResult{Message: err.Error()}
+	}
+	return a.copyResult(summary.Total())
+}
+
+// runCopyJob copies source to destination with the current settings.
+func (a *App) runCopyJob(ctx context.Context, source, destination string) CopyResult {
+	cfg := *cloneConfig(a.currentConfig())
+	cfg.Source = source
+	cfg.Sources = nil
+	cfg.URLsFrom = ""
+	cfg.Destination = destination
+	if err := cfg.Validate(); err != nil {
+		return CopyResult{Message: err.Error()}
+	}
+
+	c := copier.New(&cfg)
+	files, err := c.GetFilesContext(a.scanning(ctx))
+	if err != nil {
+		return CopyResult{Message: a.catalog().T("app.scan_failed", err)}
+	}
+	a.notifyStart(history.KindCopy, "", c, len(files))
+	start := time.Now()
+	summary := a.runHooked(ctx, history.KindCopy, "", c, len(files), func() copier.CopySummary {
+		return a.copyLocked(ctx, c, files, cfg.DryRun, nil)
+	})
+	a.recordRun(history.KindCopy, "", c, start, summary, ctx.Err() != nil)
+	return a.copyResult(summary)
+}
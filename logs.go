@@ -0,0 +1,36 @@
+//go:build windows
+
+package main
+
+import (
+	"log/slog"
+
+	"copy-image/internal/copier"
+	"copy-image/internal/logging"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// logSize is how many log entries GetRecentLogs can return.
+const logSize = 1000
+
+// startLogs streams what copies log to the frontend's log panel: every
+// entry is sent with the log:entry event, and the last ones are kept for
+// GetRecentLogs. One line per file is logged, like the CLI's -v.
+func (a *App) startLogs() {
+	a.logs = logging.NewFeed(logSize, slog.LevelInfo, func(e logging.Entry) {
+		runtime.EventsEmit(a.ctx, "log:entry", e)
+	})
+}
+
+// GetRecentLogs returns the last n log entries, oldest first, so the log
+// panel can show what happened before it was opened. n <= 0 returns all
+// that are kept.
+func (a *App) GetRecentLogs(n int) []logging.Entry {
+	return a.logs.Recent(n)
+}
+
+// logTo makes c log to the log panel.
+func (a *App) logTo(c *copier.Copier) {
+	c.SetLogger(slog.New(a.logs))
+}
@@ -22,18 +22,24 @@ func main() {
 	// Create an instance of the app structure.
 	// This will be bound to the frontend, allowing JavaScript to call Go methods.
 	app := NewApp()
+	settings := launchSettings()
+	width, height := windowSize(settings)
 
 	// Configure and run the Wails application.
 	// These options control window appearance, behavior, and bindings.
 	err := wails.Run(&options.App{
 		Title:  "Copy Image Tool",
-		Width:  900,
-		Height: 700,
+		Width:  width,
+		Height: height,
 
 		// Prevent the window from being resized smaller than this.
 		// This ensures the UI remains usable on smaller displays.
-		MinWidth:  700,
-		MinHeight: 550,
+		MinWidth:  windowMinWidth,
+		MinHeight: windowMinHeight,
+
+		// Open minimized when the user chose to, or maximized as last
+		// closed; the position is restored at startup.
+		WindowStartState: windowStartState(settings),
 
 		// Asset server configuration - serves embedded frontend files.
 		AssetServer: &assetserver.Options{
@@ -44,8 +50,25 @@ func main() {
 		// This prevents white flash during app startup.
 		BackgroundColour: &options.RGBA{R: 15, G: 20, B: 25, A: 1},
 
+		// Files and folders dropped onto the window are handed to
+		// App.handleDrop instead of being opened by the webview.
+		DragAndDrop: &options.DragAndDrop{
+			EnableFileDrop:     true,
+			DisableWebViewDrop: true,
+		},
+
+		// Only one app runs at a time; launching it again focuses this one
+		// and opens the paths it was given.
+		SingleInstanceLock: &options.SingleInstanceLock{
+			UniqueId:               instanceID,
+			OnSecondInstanceLaunch: app.secondInstance,
+		},
+
 		// Lifecycle hooks
-		OnStartup: app.startup,
+		OnStartup:     app.startup,
+		OnDomReady:    app.domReady,
+		OnBeforeClose: app.beforeClose,
+		OnShutdown:    app.shutdown,
 
 		// Bind Go structs to make their methods callable from JavaScript.
 		// The App struct's exported methods become available as window.go.main.App.*
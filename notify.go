@@ -0,0 +1,25 @@
+//go:build windows
+
+package main
+
+import (
+	"copy-image/internal/copier"
+	"copy-image/internal/notify"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// notifyStart tells the configured webhooks and email that c is about to copy a
+// batch of files. Notifications are only logged when they fail.
+func (a *App) notifyStart(kind, groupID string, c *copier.Copier, files int) {
+	if err := notify.New(a.currentConfig()).Started(a.ctx, kind, groupID, c, files); err != nil {
+		runtime.LogWarningf(a.ctx, "notify: %v", err)
+	}
+}
+
+// notifyFinish tells the configured webhooks and email how a batch ended.
+func (a *App) notifyFinish(kind, groupID string, c *copier.Copier, summary copier.CopySummary, cancelled bool) {
+	if err := notify.New(a.currentConfig()).Finished(a.ctx, kind, groupID, c, summary, cancelled); err != nil {
+		runtime.LogWarningf(a.ctx, "notify: %v", err)
+	}
+}
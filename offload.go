@@ -0,0 +1,73 @@
+//go:build windows
+
+package main
+
+import (
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// OffloadResult is the outcome of StartOffload: the copy, the hash check of
+// every copied file, and whether the source was sealed.
+type OffloadResult struct {
+	Copy    CopyResult           `json:"copy"`
+	Verify  *copier.VerifyReport `json:"verify"`
+	Sealed  bool                 `json:"sealed"`
+	Message string               `json:"message"`
+}
+
+// StartOffload copies like StartCopy, then compares every copied file with
+// its source by SHA-256 and, only when all of them match, seals the source
+// with seal ("none", "readonly" or "marker"), so a camera card is not
+// formatted before its copies are known to be good. It emits
+// offload:verifying between the two steps and offload:complete at the end.
+func (a *App) StartOffload(seal string) OffloadResult {
+	result := a.offload(seal)
+	runtime.EventsEmit(a.ctx, "offload:complete", result)
+	return result
+}
+
+func (a *App) offload(seal string) OffloadResult {
+	if err := copier.CheckSeal(seal); err != nil {
+		return OffloadResult{Message: err.Error()}
+	}
+	cfg := a.currentConfig()
+	if cfg.DryRun {
+		return OffloadResult{Message: "an offload cannot be a dry run"}
+	}
+
+	result := OffloadResult{Copy: a.startCopy(cfg.Overwrite, history.KindOffload)}
+	if a.copier == nil || result.Copy.TotalFiles == 0 {
+		result.Message = result.Copy.Message
+		return result
+	}
+
+	// The copier was rebuilt by startCopy with the same config, so the
+	// scan returns the files that were just copied.
+	files, err := a.copier.GetFilesContext(a.scanning(a.ctx))
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	runtime.EventsEmit(a.ctx, "offload:verifying", map[string]any{"total": len(files)})
+	result.Verify, err = a.copier.VerifyFiles(a.ctx, files, true)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	a.lastVerify = result.Verify
+	if !result.Verify.Clean() || result.Copy.Failed > 0 {
+		result.Message = a.catalog().T("app.offload_not_sealed")
+		return result
+	}
+
+	if err := a.copier.Seal(seal, files); err != nil {
+		result.Message = err.Error()
+		return result
+	}
+	result.Sealed = seal != "" && seal != copier.SealNone
+	result.Message = a.catalog().T("app.offload_done", result.Verify.OK, result.Verify.Checked)
+	return result
+}
@@ -0,0 +1,260 @@
+// Package copier is the copy engine of copy-image for other Go programs:
+// it copies many files in parallel from a source folder (or bucket,
+// share or server) to a destination, with retries, filters and progress
+// reports, without running the CLI.
+//
+// It wraps the engine the CLI and the desktop app use, behind a small API
+// that does not change with their internals:
+//
+//	c, err := copier.New(copier.Options{Source: `E:\DCIM`, Destination: `D:\Photos`},
+//		copier.WithExtensions(".jpg", ".cr3"), copier.WithRecursive(true))
+//	if err != nil {
+//		return err
+//	}
+//	progress := make(chan copier.Progress, 16)
+//	go func() {
+//		for p := range progress {
+//			fmt.Printf("%d/%d %s\n", p.Current, p.Total, p.File)
+//		}
+//	}()
+//	summary, err := c.Copy(ctx, nil, progress)
+//	close(progress)
+package copier
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"time"
+
+	"copy-image/internal/config"
+	"copy-image/internal/copier"
+)
+
+// Options configure a Copier. Zero values take the defaults of the CLI.
+type Options struct {
+	// Source is the folder to copy from. Like Destination, it may also be
+	// an S3, SFTP, FTP or HTTP URL, or a Windows share.
+	Source string
+	// Sources are further folders or glob patterns copied with Source.
+	Sources []string
+	// SourceFS, if not nil, holds the files under Source instead of the
+	// file system, e.g. an fstest.MapFS or an embed.FS. Source may then
+	// be left empty.
+	SourceFS fs.FS
+	// Destination is the folder to copy to, created if needed.
+	Destination string
+
+	// Extensions limits the files copied, e.g. ".jpg"; empty copies all.
+	Extensions []string
+	// Recursive includes subfolders, keeping their structure.
+	Recursive bool
+	// Exclude leaves out files and folders matching these patterns.
+	Exclude []string
+
+	// Workers is how many files are copied at once; 10 by default.
+	Workers int
+	// Overwrite replaces files already at the destination instead of
+	// skipping them.
+	Overwrite bool
+	// MaxRetries is how often a failing file is tried again; 3 by default,
+	// and none when negative.
+	MaxRetries int
+	// DryRun reports what would be copied without copying.
+	DryRun bool
+	// Order is the order files are copied in: "name", "size-asc",
+	// "size-desc", "mtime" or "random"; empty keeps the order given.
+	Order string
+
+	// Logger receives a record for every file; nothing is logged when nil.
+	Logger *slog.Logger
+}
+
+// Option changes Options, for callers who prefer New(opts, WithX(...)).
+type Option func(*Options)
+
+// WithWorkers sets how many files are copied at once.
+func WithWorkers(n int) Option { return func(o *Options) { o.Workers = n } }
+
+// WithSourceFS reads the files under Source from fsys.
+func WithSourceFS(fsys fs.FS) Option { return func(o *Options) { o.SourceFS = fsys } }
+
+// WithExtensions limits the files copied to these extensions.
+func WithExtensions(exts ...string) Option {
+	return func(o *Options) { o.Extensions = append(o.Extensions, exts...) }
+}
+
+// WithRecursive includes subfolders.
+func WithRecursive(recursive bool) Option { return func(o *Options) { o.Recursive = recursive } }
+
+// WithExclude leaves out files and folders matching the patterns.
+func WithExclude(patterns ...string) Option {
+	return func(o *Options) { o.Exclude = append(o.Exclude, patterns...) }
+}
+
+// WithOverwrite replaces files already at the destination.
+func WithOverwrite(overwrite bool) Option { return func(o *Options) { o.Overwrite = overwrite } }
+
+// WithRetries sets how often a failing file is tried again.
+func WithRetries(n int) Option { return func(o *Options) { o.MaxRetries = n } }
+
+// WithOrder copies files in order, e.g. "size-desc" for largest first.
+func WithOrder(order string) Option { return func(o *Options) { o.Order = order } }
+
+// WithDryRun reports what would be copied without copying.
+func WithDryRun(dryRun bool) Option { return func(o *Options) { o.DryRun = dryRun } }
+
+// WithLogger logs every file to l.
+func WithLogger(l *slog.Logger) Option { return func(o *Options) { o.Logger = l } }
+
+// Status is what happened to a file.
+type Status string
+
+const (
+	Copied  Status = "success"
+	Skipped Status = "skipped"
+	Failed  Status = "failed"
+)
+
+// Progress is sent once per file as it is done.
+type Progress struct {
+	// Current counts the files done so far, of Total.
+	Current int
+	Total   int
+	// File is the name of the file.
+	File   string
+	Status Status
+}
+
+// Summary is how a Copy went.
+type Summary struct {
+	Total    int
+	Copied   int
+	Skipped  int
+	Failed   int
+	Duration time.Duration
+	// Bytes counts the bytes written to the destination.
+	Bytes int64
+	// Failures are the files that failed and why.
+	Failures []Failure
+}
+
+// Failure is a file that could not be copied.
+type Failure struct {
+	// Path is the file in the source, empty when the whole batch failed,
+	// e.g. because the destination could not be written.
+	Path string
+	// Err is why; errors.Is matches it against the errors below.
+	Err error
+	// Attempts is how often the copy was tried.
+	Attempts int
+}
+
+// Errors matched with errors.Is on Failure.Err.
+var (
+	// ErrSourceLocked: another program holds the source file open.
+	ErrSourceLocked = copier.ErrSourceLocked
+	// ErrInsufficientSpace: the destination is full.
+	ErrInsufficientSpace = copier.ErrInsufficientSpace
+	// ErrCancelled: ctx was cancelled while the file was copied.
+	ErrCancelled = copier.ErrCancelled
+)
+
+// Copier copies files from a source to a destination.
+type Copier interface {
+	// Files lists the files in the source that Copy copies by default.
+	Files() ([]string, error)
+	// Copy copies files, or all of Files when files is nil. Progress is
+	// sent to progress, if not nil, which is not closed; Copy waits for
+	// each send, so progress should be drained or buffered. Cancelling
+	// ctx stops the files being copied, starts no new ones and returns
+	// its error. Otherwise the error is only for a source or destination
+	// that cannot be used at all: failed files are in the summary.
+	Copy(ctx context.Context, files []string, progress chan<- Progress) (Summary, error)
+}
+
+// New returns a Copier with opts, changed by more. It fails when the
+// options are invalid, e.g. without a destination.
+func New(opts Options, more ...Option) (Copier, error) {
+	for _, o := range more {
+		o(&opts)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Source = opts.Source
+	if cfg.Source == "" && opts.SourceFS != nil {
+		cfg.Source = "."
+	}
+	cfg.Sources = opts.Sources
+	cfg.Destination = opts.Destination
+	cfg.Extensions = opts.Extensions
+	cfg.Recursive = opts.Recursive
+	cfg.Exclude = opts.Exclude
+	cfg.Overwrite = opts.Overwrite
+	cfg.DryRun = opts.DryRun
+	cfg.Order = opts.Order
+	if opts.Workers > 0 {
+		cfg.Workers = opts.Workers
+	}
+	switch {
+	case opts.MaxRetries > 0:
+		cfg.MaxRetries = opts.MaxRetries
+	case opts.MaxRetries < 0:
+		cfg.MaxRetries = 0
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	c := copier.New(cfg)
+	if opts.SourceFS != nil {
+		c.SetSourceFS(opts.SourceFS)
+	}
+	if opts.Logger != nil {
+		c.SetLogger(opts.Logger)
+	}
+	return engine{c}, nil
+}
+
+// engine implements Copier with the internal copier.
+type engine struct {
+	c *copier.Copier
+}
+
+func (e engine) Files() ([]string, error) {
+	return e.c.GetFiles()
+}
+
+func (e engine) Copy(ctx context.Context, files []string, progress chan<- Progress) (Summary, error) {
+	if err := e.c.Err(); err != nil {
+		return Summary{}, err
+	}
+	if files == nil {
+		var err error
+		if files, err = e.c.GetFilesContext(ctx); err != nil {
+			return Summary{}, err
+		}
+	}
+
+	var events chan<- copier.Event
+	if progress != nil {
+		var wait func()
+		events, wait = copier.Dispatch(func(ev copier.Event) {
+			f, ok := ev.(copier.FileFinished)
+			if !ok {
+				return
+			}
+			select {
+			case progress <- Progress{Current: f.Current, Total: f.Total, File: f.Result.FileName, Status: Status(f.Status)}:
+			case <-ctx.Done():
+			}
+		})
+		defer wait()
+	}
+	s := e.c.CopyFilesParallelWithEvents(ctx, files, events)
+
+	summary := Summary{Total: s.TotalFiles, Copied: s.Successful, Skipped: s.Skipped, Failed: s.Failed, Duration: s.Duration, Bytes: s.TotalBytes}
+	for _, f := range s.FailedFiles {
+		summary.Failures = append(summary.Failures, Failure{Path: f.Path, Err: f.Err, Attempts: f.Attempts})
+	}
+	return summary, ctx.Err()
+}
@@ -0,0 +1,81 @@
+package copier
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCopy(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.jpg", "b.JPG", "notes.txt", filepath.Join("day2", "c.jpg")} {
+		path := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dst, "a.jpg"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(Options{Source: src, Destination: dst}, WithExtensions(".jpg"), WithRecursive(true), WithWorkers(2))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	progress := make(chan Progress, 10)
+	summary, err := c.Copy(context.Background(), nil, progress)
+	close(progress)
+	if err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+	if summary.Total != 3 || summary.Copied != 2 || summary.Skipped != 1 || summary.Failed != 0 {
+		t.Errorf("Expected 2 copied and 1 skipped, got %+v", summary)
+	}
+	var sent int
+	for p := range progress {
+		sent++
+		if p.Total != 3 || p.File == "" {
+			t.Errorf("Unexpected progress %+v", p)
+		}
+	}
+	if sent != 3 {
+		t.Errorf("Expected progress for 3 files, got %d", sent)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "day2", "c.jpg")); err != nil {
+		t.Errorf("Expected the subfolder copied: %v", err)
+	}
+
+	// A file that disappeared is reported with its path.
+	missing := filepath.Join(src, "gone.jpg")
+	summary, err = c.Copy(context.Background(), []string{missing}, nil)
+	if err != nil || len(summary.Failures) != 1 || summary.Failures[0].Path != missing {
+		t.Errorf("Expected the missing file to fail, got %+v, %v", summary, err)
+	}
+}
+
+func TestCopyFromFS(t *testing.T) {
+	dst := t.TempDir()
+	fsys := fstest.MapFS{"a.jpg": {Data: []byte("a")}, "day2/b.jpg": {Data: []byte("b")}}
+	c, err := New(Options{Destination: dst, Recursive: true}, WithSourceFS(fsys))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	summary, err := c.Copy(context.Background(), nil, nil)
+	if err != nil || summary.Copied != 2 {
+		t.Fatalf("Expected 2 copies, got %+v, %v", summary, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "day2", "b.jpg")); err != nil || string(data) != "b" {
+		t.Errorf("Expected the copy of day2/b.jpg, got %q, %v", data, err)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(Options{Source: t.TempDir()}); err == nil {
+		t.Error("Expected an error without a destination")
+	}
+}
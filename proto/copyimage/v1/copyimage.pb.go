@@ -0,0 +1,1214 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: copyimage/v1/copyimage.proto
+
+// The gRPC control interface of `copyimage serve`, for services that start
+// and follow imports with typed clients. It serves the same runs as the
+// REST API.
+
+package copyimagev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RunStatus int32
+
+const (
+	RunStatus_RUN_STATUS_UNSPECIFIED RunStatus = 0
+	RunStatus_RUN_STATUS_RUNNING     RunStatus = 1
+	// Every file was copied or skipped.
+	RunStatus_RUN_STATUS_COMPLETED RunStatus = 2
+	// Files failed, or the group could not run.
+	RunStatus_RUN_STATUS_FAILED    RunStatus = 3
+	RunStatus_RUN_STATUS_CANCELLED RunStatus = 4
+)
+
+// Enum value maps for RunStatus.
+var (
+	RunStatus_name = map[int32]string{
+		0: "RUN_STATUS_UNSPECIFIED",
+		1: "RUN_STATUS_RUNNING",
+		2: "RUN_STATUS_COMPLETED",
+		3: "RUN_STATUS_FAILED",
+		4: "RUN_STATUS_CANCELLED",
+	}
+	RunStatus_value = map[string]int32{
+		"RUN_STATUS_UNSPECIFIED": 0,
+		"RUN_STATUS_RUNNING":     1,
+		"RUN_STATUS_COMPLETED":   2,
+		"RUN_STATUS_FAILED":      3,
+		"RUN_STATUS_CANCELLED":   4,
+	}
+)
+
+func (x RunStatus) Enum() *RunStatus {
+	p := new(RunStatus)
+	*p = x
+	return p
+}
+
+func (x RunStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RunStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_copyimage_v1_copyimage_proto_enumTypes[0].Descriptor()
+}
+
+func (RunStatus) Type() protoreflect.EnumType {
+	return &file_copyimage_v1_copyimage_proto_enumTypes[0]
+}
+
+func (x RunStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RunStatus.Descriptor instead.
+func (RunStatus) EnumDescriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{0}
+}
+
+type ListGroupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsRequest) Reset() {
+	*x = ListGroupsRequest{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsRequest) ProtoMessage() {}
+
+func (x *ListGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsRequest.ProtoReflect.Descriptor instead.
+func (*ListGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{0}
+}
+
+type ListGroupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*Group               `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsResponse) Reset() {
+	*x = ListGroupsResponse{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsResponse) ProtoMessage() {}
+
+func (x *ListGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListGroupsResponse) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type Group struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name    string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Source  string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Enabled bool                   `protobuf:"varint,4,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// Cron expression of scheduled groups.
+	Schedule     string         `protobuf:"bytes,5,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	Destinations []*Destination `protobuf:"bytes,6,rep,name=destinations,proto3" json:"destinations,omitempty"`
+	// Whether a run of the group is in progress.
+	Running       bool `protobuf:"varint,7,opt,name=running,proto3" json:"running,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Group) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Group) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Group) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Group) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Group) GetSchedule() string {
+	if x != nil {
+		return x.Schedule
+	}
+	return ""
+}
+
+func (x *Group) GetDestinations() []*Destination {
+	if x != nil {
+		return x.Destinations
+	}
+	return nil
+}
+
+func (x *Group) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+type Destination struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Enabled       bool                   `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Destination) Reset() {
+	*x = Destination{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Destination) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Destination) ProtoMessage() {}
+
+func (x *Destination) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Destination.ProtoReflect.Descriptor instead.
+func (*Destination) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Destination) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Destination) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Destination) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type StartCopyRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	GroupId string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	// Report what would be copied without copying.
+	DryRun        bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartCopyRequest) Reset() {
+	*x = StartCopyRequest{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartCopyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartCopyRequest) ProtoMessage() {}
+
+func (x *StartCopyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartCopyRequest.ProtoReflect.Descriptor instead.
+func (*StartCopyRequest) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StartCopyRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *StartCopyRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type CancelCopyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         int64                  `protobuf:"varint,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelCopyRequest) Reset() {
+	*x = CancelCopyRequest{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelCopyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelCopyRequest) ProtoMessage() {}
+
+func (x *CancelCopyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelCopyRequest.ProtoReflect.Descriptor instead.
+func (*CancelCopyRequest) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CancelCopyRequest) GetRunId() int64 {
+	if x != nil {
+		return x.RunId
+	}
+	return 0
+}
+
+type GetRunRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         int64                  `protobuf:"varint,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRunRequest) Reset() {
+	*x = GetRunRequest{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRunRequest) ProtoMessage() {}
+
+func (x *GetRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRunRequest.ProtoReflect.Descriptor instead.
+func (*GetRunRequest) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetRunRequest) GetRunId() int64 {
+	if x != nil {
+		return x.RunId
+	}
+	return 0
+}
+
+type StreamProgressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         int64                  `protobuf:"varint,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamProgressRequest) Reset() {
+	*x = StreamProgressRequest{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamProgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamProgressRequest) ProtoMessage() {}
+
+func (x *StreamProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamProgressRequest.ProtoReflect.Descriptor instead.
+func (*StreamProgressRequest) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StreamProgressRequest) GetRunId() int64 {
+	if x != nil {
+		return x.RunId
+	}
+	return 0
+}
+
+// Run is a group run started through the API.
+type Run struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	GroupId string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Status  RunStatus              `protobuf:"varint,3,opt,name=status,proto3,enum=copyimage.v1.RunStatus" json:"status,omitempty"`
+	DryRun  bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Started *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started,proto3" json:"started,omitempty"`
+	// Unset while the run is in progress.
+	Finished *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=finished,proto3" json:"finished,omitempty"`
+	// The latest file done; unset until the first one.
+	Progress *Progress `protobuf:"bytes,7,opt,name=progress,proto3" json:"progress,omitempty"`
+	// The results per destination, once the run has ended.
+	Destinations []*DestinationResult `protobuf:"bytes,8,rep,name=destinations,proto3" json:"destinations,omitempty"`
+	// Why the group could not run, e.g. its source is missing.
+	Error         string `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Run) Reset() {
+	*x = Run{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Run) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Run) ProtoMessage() {}
+
+func (x *Run) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Run.ProtoReflect.Descriptor instead.
+func (*Run) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Run) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Run) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *Run) GetStatus() RunStatus {
+	if x != nil {
+		return x.Status
+	}
+	return RunStatus_RUN_STATUS_UNSPECIFIED
+}
+
+func (x *Run) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *Run) GetStarted() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Started
+	}
+	return nil
+}
+
+func (x *Run) GetFinished() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Finished
+	}
+	return nil
+}
+
+func (x *Run) GetProgress() *Progress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+func (x *Run) GetDestinations() []*DestinationResult {
+	if x != nil {
+		return x.Destinations
+	}
+	return nil
+}
+
+func (x *Run) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type Progress struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Destination string                 `protobuf:"bytes,1,opt,name=destination,proto3" json:"destination,omitempty"`
+	Current     int32                  `protobuf:"varint,2,opt,name=current,proto3" json:"current,omitempty"`
+	Total       int32                  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	File        string                 `protobuf:"bytes,4,opt,name=file,proto3" json:"file,omitempty"`
+	// "success", "skipped" or "failed".
+	Status        string `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Progress) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *Progress) GetCurrent() int32 {
+	if x != nil {
+		return x.Current
+	}
+	return 0
+}
+
+func (x *Progress) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Progress) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *Progress) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type DestinationResult struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Path       string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	TotalFiles int32                  `protobuf:"varint,3,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`
+	Successful int32                  `protobuf:"varint,4,opt,name=successful,proto3" json:"successful,omitempty"`
+	Failed     int32                  `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`
+	Skipped    int32                  `protobuf:"varint,6,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	// "name: error" for each failed file.
+	FailedFiles   []string             `protobuf:"bytes,7,rep,name=failed_files,json=failedFiles,proto3" json:"failed_files,omitempty"`
+	Duration      *durationpb.Duration `protobuf:"bytes,8,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DestinationResult) Reset() {
+	*x = DestinationResult{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DestinationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DestinationResult) ProtoMessage() {}
+
+func (x *DestinationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DestinationResult.ProtoReflect.Descriptor instead.
+func (*DestinationResult) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DestinationResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DestinationResult) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *DestinationResult) GetTotalFiles() int32 {
+	if x != nil {
+		return x.TotalFiles
+	}
+	return 0
+}
+
+func (x *DestinationResult) GetSuccessful() int32 {
+	if x != nil {
+		return x.Successful
+	}
+	return 0
+}
+
+func (x *DestinationResult) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *DestinationResult) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *DestinationResult) GetFailedFiles() []string {
+	if x != nil {
+		return x.FailedFiles
+	}
+	return nil
+}
+
+func (x *DestinationResult) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type GetHistoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// How many batches to return; 0 returns the last 20.
+	Limit         int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryRequest) Reset() {
+	*x = GetHistoryRequest{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryRequest) ProtoMessage() {}
+
+func (x *GetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runs          []*HistoryRun          `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryResponse) Reset() {
+	*x = GetHistoryResponse{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryResponse) ProtoMessage() {}
+
+func (x *GetHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetHistoryResponse) GetRuns() []*HistoryRun {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+// HistoryRun is one batch recorded in the run history: a copy to one
+// destination, by any part of copy-image.
+type HistoryRun struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// What started the batch: copy, group, watch, schedule, card, offload
+	// or api.
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	GroupId       string                 `protobuf:"bytes,3,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Sources       []string               `protobuf:"bytes,4,rep,name=sources,proto3" json:"sources,omitempty"`
+	Destination   string                 `protobuf:"bytes,5,opt,name=destination,proto3" json:"destination,omitempty"`
+	Start         *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start,proto3" json:"start,omitempty"`
+	Duration      *durationpb.Duration   `protobuf:"bytes,7,opt,name=duration,proto3" json:"duration,omitempty"`
+	DryRun        bool                   `protobuf:"varint,8,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Cancelled     bool                   `protobuf:"varint,9,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
+	TotalFiles    int32                  `protobuf:"varint,10,opt,name=total_files,json=totalFiles,proto3" json:"total_files,omitempty"`
+	Successful    int32                  `protobuf:"varint,11,opt,name=successful,proto3" json:"successful,omitempty"`
+	Failed        int32                  `protobuf:"varint,12,opt,name=failed,proto3" json:"failed,omitempty"`
+	Skipped       int32                  `protobuf:"varint,13,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryRun) Reset() {
+	*x = HistoryRun{}
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryRun) ProtoMessage() {}
+
+func (x *HistoryRun) ProtoReflect() protoreflect.Message {
+	mi := &file_copyimage_v1_copyimage_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryRun.ProtoReflect.Descriptor instead.
+func (*HistoryRun) Descriptor() ([]byte, []int) {
+	return file_copyimage_v1_copyimage_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *HistoryRun) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *HistoryRun) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *HistoryRun) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *HistoryRun) GetSources() []string {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+func (x *HistoryRun) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *HistoryRun) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *HistoryRun) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *HistoryRun) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *HistoryRun) GetCancelled() bool {
+	if x != nil {
+		return x.Cancelled
+	}
+	return false
+}
+
+func (x *HistoryRun) GetTotalFiles() int32 {
+	if x != nil {
+		return x.TotalFiles
+	}
+	return 0
+}
+
+func (x *HistoryRun) GetSuccessful() int32 {
+	if x != nil {
+		return x.Successful
+	}
+	return 0
+}
+
+func (x *HistoryRun) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *HistoryRun) GetSkipped() int32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+var File_copyimage_v1_copyimage_proto protoreflect.FileDescriptor
+
+const file_copyimage_v1_copyimage_proto_rawDesc = "" +
+	"\n" +
+	"\x1ccopyimage/v1/copyimage.proto\x12\fcopyimage.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x13\n" +
+	"\x11ListGroupsRequest\"A\n" +
+	"\x12ListGroupsResponse\x12+\n" +
+	"\x06groups\x18\x01 \x03(\v2\x13.copyimage.v1.GroupR\x06groups\"\xd2\x01\n" +
+	"\x05Group\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x18\n" +
+	"\aenabled\x18\x04 \x01(\bR\aenabled\x12\x1a\n" +
+	"\bschedule\x18\x05 \x01(\tR\bschedule\x12=\n" +
+	"\fdestinations\x18\x06 \x03(\v2\x19.copyimage.v1.DestinationR\fdestinations\x12\x18\n" +
+	"\arunning\x18\a \x01(\bR\arunning\"K\n" +
+	"\vDestination\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x18\n" +
+	"\aenabled\x18\x03 \x01(\bR\aenabled\"F\n" +
+	"\x10StartCopyRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"*\n" +
+	"\x11CancelCopyRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\x03R\x05runId\"&\n" +
+	"\rGetRunRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\x03R\x05runId\".\n" +
+	"\x15StreamProgressRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\x03R\x05runId\"\xf7\x02\n" +
+	"\x03Run\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\bgroup_id\x18\x02 \x01(\tR\agroupId\x12/\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x17.copyimage.v1.RunStatusR\x06status\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\x124\n" +
+	"\astarted\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\astarted\x126\n" +
+	"\bfinished\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\bfinished\x122\n" +
+	"\bprogress\x18\a \x01(\v2\x16.copyimage.v1.ProgressR\bprogress\x12C\n" +
+	"\fdestinations\x18\b \x03(\v2\x1f.copyimage.v1.DestinationResultR\fdestinations\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\"\x88\x01\n" +
+	"\bProgress\x12 \n" +
+	"\vdestination\x18\x01 \x01(\tR\vdestination\x12\x18\n" +
+	"\acurrent\x18\x02 \x01(\x05R\acurrent\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\x12\x12\n" +
+	"\x04file\x18\x04 \x01(\tR\x04file\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\"\x84\x02\n" +
+	"\x11DestinationResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x1f\n" +
+	"\vtotal_files\x18\x03 \x01(\x05R\n" +
+	"totalFiles\x12\x1e\n" +
+	"\n" +
+	"successful\x18\x04 \x01(\x05R\n" +
+	"successful\x12\x16\n" +
+	"\x06failed\x18\x05 \x01(\x05R\x06failed\x12\x18\n" +
+	"\askipped\x18\x06 \x01(\x05R\askipped\x12!\n" +
+	"\ffailed_files\x18\a \x03(\tR\vfailedFiles\x125\n" +
+	"\bduration\x18\b \x01(\v2\x19.google.protobuf.DurationR\bduration\")\n" +
+	"\x11GetHistoryRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"B\n" +
+	"\x12GetHistoryResponse\x12,\n" +
+	"\x04runs\x18\x01 \x03(\v2\x18.copyimage.v1.HistoryRunR\x04runs\"\x9a\x03\n" +
+	"\n" +
+	"HistoryRun\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x19\n" +
+	"\bgroup_id\x18\x03 \x01(\tR\agroupId\x12\x18\n" +
+	"\asources\x18\x04 \x03(\tR\asources\x12 \n" +
+	"\vdestination\x18\x05 \x01(\tR\vdestination\x120\n" +
+	"\x05start\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x125\n" +
+	"\bduration\x18\a \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x17\n" +
+	"\adry_run\x18\b \x01(\bR\x06dryRun\x12\x1c\n" +
+	"\tcancelled\x18\t \x01(\bR\tcancelled\x12\x1f\n" +
+	"\vtotal_files\x18\n" +
+	" \x01(\x05R\n" +
+	"totalFiles\x12\x1e\n" +
+	"\n" +
+	"successful\x18\v \x01(\x05R\n" +
+	"successful\x12\x16\n" +
+	"\x06failed\x18\f \x01(\x05R\x06failed\x12\x18\n" +
+	"\askipped\x18\r \x01(\x05R\askipped*\x8a\x01\n" +
+	"\tRunStatus\x12\x1a\n" +
+	"\x16RUN_STATUS_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12RUN_STATUS_RUNNING\x10\x01\x12\x18\n" +
+	"\x14RUN_STATUS_COMPLETED\x10\x02\x12\x15\n" +
+	"\x11RUN_STATUS_FAILED\x10\x03\x12\x18\n" +
+	"\x14RUN_STATUS_CANCELLED\x10\x042\xb7\x03\n" +
+	"\vCopyService\x12O\n" +
+	"\n" +
+	"ListGroups\x12\x1f.copyimage.v1.ListGroupsRequest\x1a .copyimage.v1.ListGroupsResponse\x12>\n" +
+	"\tStartCopy\x12\x1e.copyimage.v1.StartCopyRequest\x1a\x11.copyimage.v1.Run\x12@\n" +
+	"\n" +
+	"CancelCopy\x12\x1f.copyimage.v1.CancelCopyRequest\x1a\x11.copyimage.v1.Run\x128\n" +
+	"\x06GetRun\x12\x1b.copyimage.v1.GetRunRequest\x1a\x11.copyimage.v1.Run\x12J\n" +
+	"\x0eStreamProgress\x12#.copyimage.v1.StreamProgressRequest\x1a\x11.copyimage.v1.Run0\x01\x12O\n" +
+	"\n" +
+	"GetHistory\x12\x1f.copyimage.v1.GetHistoryRequest\x1a .copyimage.v1.GetHistoryResponseB+Z)copy-image/proto/copyimage/v1;copyimagev1b\x06proto3"
+
+var (
+	file_copyimage_v1_copyimage_proto_rawDescOnce sync.Once
+	file_copyimage_v1_copyimage_proto_rawDescData []byte
+)
+
+func file_copyimage_v1_copyimage_proto_rawDescGZIP() []byte {
+	file_copyimage_v1_copyimage_proto_rawDescOnce.Do(func() {
+		file_copyimage_v1_copyimage_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_copyimage_v1_copyimage_proto_rawDesc), len(file_copyimage_v1_copyimage_proto_rawDesc)))
+	})
+	return file_copyimage_v1_copyimage_proto_rawDescData
+}
+
+var file_copyimage_v1_copyimage_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_copyimage_v1_copyimage_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_copyimage_v1_copyimage_proto_goTypes = []any{
+	(RunStatus)(0),                // 0: copyimage.v1.RunStatus
+	(*ListGroupsRequest)(nil),     // 1: copyimage.v1.ListGroupsRequest
+	(*ListGroupsResponse)(nil),    // 2: copyimage.v1.ListGroupsResponse
+	(*Group)(nil),                 // 3: copyimage.v1.Group
+	(*Destination)(nil),           // 4: copyimage.v1.Destination
+	(*StartCopyRequest)(nil),      // 5: copyimage.v1.StartCopyRequest
+	(*CancelCopyRequest)(nil),     // 6: copyimage.v1.CancelCopyRequest
+	(*GetRunRequest)(nil),         // 7: copyimage.v1.GetRunRequest
+	(*StreamProgressRequest)(nil), // 8: copyimage.v1.StreamProgressRequest
+	(*Run)(nil),                   // 9: copyimage.v1.Run
+	(*Progress)(nil),              // 10: copyimage.v1.Progress
+	(*DestinationResult)(nil),     // 11: copyimage.v1.DestinationResult
+	(*GetHistoryRequest)(nil),     // 12: copyimage.v1.GetHistoryRequest
+	(*GetHistoryResponse)(nil),    // 13: copyimage.v1.GetHistoryResponse
+	(*HistoryRun)(nil),            // 14: copyimage.v1.HistoryRun
+	(*timestamppb.Timestamp)(nil), // 15: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 16: google.protobuf.Duration
+}
+var file_copyimage_v1_copyimage_proto_depIdxs = []int32{
+	3,  // 0: copyimage.v1.ListGroupsResponse.groups:type_name -> copyimage.v1.Group
+	4,  // 1: copyimage.v1.Group.destinations:type_name -> copyimage.v1.Destination
+	0,  // 2: copyimage.v1.Run.status:type_name -> copyimage.v1.RunStatus
+	15, // 3: copyimage.v1.Run.started:type_name -> google.protobuf.Timestamp
+	15, // 4: copyimage.v1.Run.finished:type_name -> google.protobuf.Timestamp
+	10, // 5: copyimage.v1.Run.progress:type_name -> copyimage.v1.Progress
+	11, // 6: copyimage.v1.Run.destinations:type_name -> copyimage.v1.DestinationResult
+	16, // 7: copyimage.v1.DestinationResult.duration:type_name -> google.protobuf.Duration
+	14, // 8: copyimage.v1.GetHistoryResponse.runs:type_name -> copyimage.v1.HistoryRun
+	15, // 9: copyimage.v1.HistoryRun.start:type_name -> google.protobuf.Timestamp
+	16, // 10: copyimage.v1.HistoryRun.duration:type_name -> google.protobuf.Duration
+	1,  // 11: copyimage.v1.CopyService.ListGroups:input_type -> copyimage.v1.ListGroupsRequest
+	5,  // 12: copyimage.v1.CopyService.StartCopy:input_type -> copyimage.v1.StartCopyRequest
+	6,  // 13: copyimage.v1.CopyService.CancelCopy:input_type -> copyimage.v1.CancelCopyRequest
+	7,  // 14: copyimage.v1.CopyService.GetRun:input_type -> copyimage.v1.GetRunRequest
+	8,  // 15: copyimage.v1.CopyService.StreamProgress:input_type -> copyimage.v1.StreamProgressRequest
+	12, // 16: copyimage.v1.CopyService.GetHistory:input_type -> copyimage.v1.GetHistoryRequest
+	2,  // 17: copyimage.v1.CopyService.ListGroups:output_type -> copyimage.v1.ListGroupsResponse
+	9,  // 18: copyimage.v1.CopyService.StartCopy:output_type -> copyimage.v1.Run
+	9,  // 19: copyimage.v1.CopyService.CancelCopy:output_type -> copyimage.v1.Run
+	9,  // 20: copyimage.v1.CopyService.GetRun:output_type -> copyimage.v1.Run
+	9,  // 21: copyimage.v1.CopyService.StreamProgress:output_type -> copyimage.v1.Run
+	13, // 22: copyimage.v1.CopyService.GetHistory:output_type -> copyimage.v1.GetHistoryResponse
+	17, // [17:23] is the sub-list for method output_type
+	11, // [11:17] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_copyimage_v1_copyimage_proto_init() }
+func file_copyimage_v1_copyimage_proto_init() {
+	if File_copyimage_v1_copyimage_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_copyimage_v1_copyimage_proto_rawDesc), len(file_copyimage_v1_copyimage_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_copyimage_v1_copyimage_proto_goTypes,
+		DependencyIndexes: file_copyimage_v1_copyimage_proto_depIdxs,
+		EnumInfos:         file_copyimage_v1_copyimage_proto_enumTypes,
+		MessageInfos:      file_copyimage_v1_copyimage_proto_msgTypes,
+	}.Build()
+	File_copyimage_v1_copyimage_proto = out.File
+	file_copyimage_v1_copyimage_proto_goTypes = nil
+	file_copyimage_v1_copyimage_proto_depIdxs = nil
+}
@@ -0,0 +1,349 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: copyimage/v1/copyimage.proto
+
+// The gRPC control interface of `copyimage serve`, for services that start
+// and follow imports with typed clients. It serves the same runs as the
+// REST API.
+
+package copyimagev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CopyService_ListGroups_FullMethodName     = "/copyimage.v1.CopyService/ListGroups"
+	CopyService_StartCopy_FullMethodName      = "/copyimage.v1.CopyService/StartCopy"
+	CopyService_CancelCopy_FullMethodName     = "/copyimage.v1.CopyService/CancelCopy"
+	CopyService_GetRun_FullMethodName         = "/copyimage.v1.CopyService/GetRun"
+	CopyService_StreamProgress_FullMethodName = "/copyimage.v1.CopyService/StreamProgress"
+	CopyService_GetHistory_FullMethodName     = "/copyimage.v1.CopyService/GetHistory"
+)
+
+// CopyServiceClient is the client API for CopyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CopyService runs the copy groups of the server's config. Every call
+// needs the API token as "authorization: Bearer <token>" metadata.
+type CopyServiceClient interface {
+	// ListGroups returns the copy groups of the current config.
+	ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsResponse, error)
+	// StartCopy starts a group run in the background and returns it. It
+	// fails with NOT_FOUND for an unknown group and ALREADY_EXISTS while the
+	// group is running.
+	StartCopy(ctx context.Context, in *StartCopyRequest, opts ...grpc.CallOption) (*Run, error)
+	// CancelCopy stops a run, letting the files being copied finish, and
+	// returns it once it has ended.
+	CancelCopy(ctx context.Context, in *CancelCopyRequest, opts ...grpc.CallOption) (*Run, error)
+	// GetRun returns a run started since the server started.
+	GetRun(ctx context.Context, in *GetRunRequest, opts ...grpc.CallOption) (*Run, error)
+	// StreamProgress sends the run now and each time it changes, and ends
+	// once the run has. Changes are not queued: a slow client gets the
+	// latest state rather than every file.
+	StreamProgress(ctx context.Context, in *StreamProgressRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Run], error)
+	// GetHistory returns past batches from the run history, newest first,
+	// without their file lists; `copyimage history <id>` shows those.
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+}
+
+type copyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCopyServiceClient(cc grpc.ClientConnInterface) CopyServiceClient {
+	return &copyServiceClient{cc}
+}
+
+func (c *copyServiceClient) ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListGroupsResponse)
+	err := c.cc.Invoke(ctx, CopyService_ListGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *copyServiceClient) StartCopy(ctx context.Context, in *StartCopyRequest, opts ...grpc.CallOption) (*Run, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Run)
+	err := c.cc.Invoke(ctx, CopyService_StartCopy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *copyServiceClient) CancelCopy(ctx context.Context, in *CancelCopyRequest, opts ...grpc.CallOption) (*Run, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Run)
+	err := c.cc.Invoke(ctx, CopyService_CancelCopy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *copyServiceClient) GetRun(ctx context.Context, in *GetRunRequest, opts ...grpc.CallOption) (*Run, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Run)
+	err := c.cc.Invoke(ctx, CopyService_GetRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *copyServiceClient) StreamProgress(ctx context.Context, in *StreamProgressRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Run], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CopyService_ServiceDesc.Streams[0], CopyService_StreamProgress_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamProgressRequest, Run]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CopyService_StreamProgressClient = grpc.ServerStreamingClient[Run]
+
+func (c *copyServiceClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, CopyService_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CopyServiceServer is the server API for CopyService service.
+// All implementations must embed UnimplementedCopyServiceServer
+// for forward compatibility.
+//
+// CopyService runs the copy groups of the server's config. Every call
+// needs the API token as "authorization: Bearer <token>" metadata.
+type CopyServiceServer interface {
+	// ListGroups returns the copy groups of the current config.
+	ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsResponse, error)
+	// StartCopy starts a group run in the background and returns it. It
+	// fails with NOT_FOUND for an unknown group and ALREADY_EXISTS while the
+	// group is running.
+	StartCopy(context.Context, *StartCopyRequest) (*Run, error)
+	// CancelCopy stops a run, letting the files being copied finish, and
+	// returns it once it has ended.
+	CancelCopy(context.Context, *CancelCopyRequest) (*Run, error)
+	// GetRun returns a run started since the server started.
+	GetRun(context.Context, *GetRunRequest) (*Run, error)
+	// StreamProgress sends the run now and each time it changes, and ends
+	// once the run has. Changes are not queued: a slow client gets the
+	// latest state rather than every file.
+	StreamProgress(*StreamProgressRequest, grpc.ServerStreamingServer[Run]) error
+	// GetHistory returns past batches from the run history, newest first,
+	// without their file lists; `copyimage history <id>` shows those.
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	mustEmbedUnimplementedCopyServiceServer()
+}
+
+// UnimplementedCopyServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCopyServiceServer struct{}
+
+func (UnimplementedCopyServiceServer) ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroups not implemented")
+}
+func (UnimplementedCopyServiceServer) StartCopy(context.Context, *StartCopyRequest) (*Run, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartCopy not implemented")
+}
+func (UnimplementedCopyServiceServer) CancelCopy(context.Context, *CancelCopyRequest) (*Run, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelCopy not implemented")
+}
+func (UnimplementedCopyServiceServer) GetRun(context.Context, *GetRunRequest) (*Run, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRun not implemented")
+}
+func (UnimplementedCopyServiceServer) StreamProgress(*StreamProgressRequest, grpc.ServerStreamingServer[Run]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamProgress not implemented")
+}
+func (UnimplementedCopyServiceServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedCopyServiceServer) mustEmbedUnimplementedCopyServiceServer() {}
+func (UnimplementedCopyServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeCopyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CopyServiceServer will
+// result in compilation errors.
+type UnsafeCopyServiceServer interface {
+	mustEmbedUnimplementedCopyServiceServer()
+}
+
+func RegisterCopyServiceServer(s grpc.ServiceRegistrar, srv CopyServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCopyServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CopyService_ServiceDesc, srv)
+}
+
+func _CopyService_ListGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CopyServiceServer).ListGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CopyService_ListGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CopyServiceServer).ListGroups(ctx, req.(*ListGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CopyService_StartCopy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartCopyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CopyServiceServer).StartCopy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CopyService_StartCopy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CopyServiceServer).StartCopy(ctx, req.(*StartCopyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CopyService_CancelCopy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelCopyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CopyServiceServer).CancelCopy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CopyService_CancelCopy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CopyServiceServer).CancelCopy(ctx, req.(*CancelCopyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CopyService_GetRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CopyServiceServer).GetRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CopyService_GetRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CopyServiceServer).GetRun(ctx, req.(*GetRunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CopyService_StreamProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamProgressRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CopyServiceServer).StreamProgress(m, &grpc.GenericServerStream[StreamProgressRequest, Run]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CopyService_StreamProgressServer = grpc.ServerStreamingServer[Run]
+
+func _CopyService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CopyServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CopyService_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CopyServiceServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CopyService_ServiceDesc is the grpc.ServiceDesc for CopyService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CopyService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "copyimage.v1.CopyService",
+	HandlerType: (*CopyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListGroups",
+			Handler:    _CopyService_ListGroups_Handler,
+		},
+		{
+			MethodName: "StartCopy",
+			Handler:    _CopyService_StartCopy_Handler,
+		},
+		{
+			MethodName: "CancelCopy",
+			Handler:    _CopyService_CancelCopy_Handler,
+		},
+		{
+			MethodName: "GetRun",
+			Handler:    _CopyService_GetRun_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _CopyService_GetHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamProgress",
+			Handler:       _CopyService_StreamProgress_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "copyimage/v1/copyimage.proto",
+}
@@ -0,0 +1,87 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"copy-image/internal/config"
+	"copy-image/internal/history"
+	"copy-image/internal/schedule"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ScheduledRun is sent with the schedule:done event after a scheduled group
+// ran.
+type ScheduledRun struct {
+	GroupID string     `json:"groupId"`
+	Result  CopyResult `json:"result"`
+}
+
+// startScheduler runs scheduled groups in the background for as long as
+// the app is open.
+func (a *App) startScheduler() {
+	a.scheduler = schedule.New(a.runScheduled, a.dataPath(schedule.StateFile))
+	a.refreshSchedule()
+	go a.scheduler.Start(a.ctx)
+}
+
+// dataPath returns where the state file name (scheduled runs, history) is
+// kept: next to the config file, like the CLI, so both show the same runs.
+func (a *App) dataPath(name string) string {
+	if a.configPath == "" {
+		return config.DataPath(name)
+	}
+	return filepath.Join(filepath.Dir(a.configPath), name)
+}
+
+// refreshSchedule updates the scheduled jobs after the groups changed.
+// Invalid schedules are already reported by Validate, so the error is only
+// logged.
+func (a *App) refreshSchedule() {
+	if a.scheduler == nil {
+		return
+	}
+	if err := a.scheduler.SetJobs(schedule.Jobs(a.currentConfig())); err != nil {
+		runtime.LogWarningf(a.ctx, "schedule: %v", err)
+	}
+}
+
+// runScheduled runs one scheduled group with the current settings,
+// emitting schedule:start and schedule:done.
+func (a *App) runScheduled(ctx context.Context, id string) error {
+	cfg := a.currentConfig()
+	group := cfg.FindGroup(id)
+	if group == nil {
+		return fmt.Errorf("group %q not found", id)
+	}
+
+	runtime.EventsEmit(a.ctx, "schedule:start", id)
+	summary, err := a.runGroup(ctx, history.KindSchedule, cfg, *group)
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "schedule:done", ScheduledRun{
+			GroupID: id,
+			Result:  CopyResult{Message: err.Error()},
+		})
+		return err
+	}
+
+	total := summary.Total()
+	runtime.EventsEmit(a.ctx, "schedule:done", ScheduledRun{GroupID: id, Result: a.copyResult(total)})
+	if total.Failed > 0 {
+		return fmt.Errorf("%d file(s) failed", total.Failed)
+	}
+	return nil
+}
+
+// GetSchedule returns the scheduled groups with their next run time and
+// the outcome of their last run, soonest first.
+func (a *App) GetSchedule() []schedule.Status {
+	if a.scheduler == nil {
+		return nil
+	}
+	return a.scheduler.Status()
+}
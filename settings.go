@@ -0,0 +1,70 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"copy-image/internal/config"
+	"copy-image/internal/i18n"
+)
+
+// Settings are the preferences shown on the settings page, with their
+// defaults filled in. They are kept in the app section of config.yaml,
+// except Language, which the CLI uses as well.
+type Settings struct {
+	Theme string `json:"theme"`
+	// Language is "en" or "vi"; empty follows Windows.
+	Language         string `json:"language"`
+	StartMinimized   bool   `json:"startMinimized"`
+	CloseToTray      bool   `json:"closeToTray"`
+	CheckUpdates     bool   `json:"checkUpdates"`
+	UpdateChannel    string `json:"updateChannel"`
+	ConfirmOverwrite bool   `json:"confirmOverwrite"`
+}
+
+// GetAppSettings returns the app's preferences.
+func (a *App) GetAppSettings() Settings {
+	cfg := a.currentConfig()
+	s := cfg.App
+	return Settings{
+		Theme:            s.ThemeName(),
+		Language:         cfg.Language,
+		StartMinimized:   s.StartsMinimized(),
+		CloseToTray:      s.ClosesToTray(),
+		CheckUpdates:     s.ChecksUpdates(),
+		UpdateChannel:    s.Channel(),
+		ConfirmOverwrite: s.ConfirmsOverwrite(),
+	}
+}
+
+// SetAppSettings applies the preferences and saves them at once, unlike
+// other settings, so that they hold at the next launch even when the
+// frontend is closed before saving.
+func (a *App) SetAppSettings(s Settings) error {
+	if s.Language != "" && !slices.Contains(i18n.Supported(), s.Language) {
+		return fmt.Errorf("unsupported language %q", s.Language)
+	}
+	_, err := a.updateConfig(func(next *config.Config) error {
+		next.Language = s.Language
+		next.App = &config.AppSettings{
+			Theme:            s.Theme,
+			StartMinimized:   s.StartMinimized,
+			CloseToTray:      s.CloseToTray,
+			CheckUpdates:     &s.CheckUpdates,
+			UpdateChannel:    s.UpdateChannel,
+			ConfirmOverwrite: &s.ConfirmOverwrite,
+			// Not on the settings page: kept as SkipVersion left it.
+			SkipVersion: next.App.SkippedVersion(),
+		}
+		if err := next.Validate(); err != nil {
+			return fmt.Errorf("invalid settings: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return a.SaveConfig()
+}
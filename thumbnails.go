@@ -0,0 +1,77 @@
+//go:build windows
+
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+
+	"copy-image/internal/storage"
+	"copy-image/internal/thumbnail"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// defaultThumbnailSize is used when the frontend asks for no size.
+	defaultThumbnailSize = 256
+	// maxThumbnailSize keeps a preview grid from decoding full images.
+	maxThumbnailSize = 1024
+	// thumbnailWorkers decode images at once; decoding is CPU bound and
+	// the copy may be running too.
+	thumbnailWorkers = 4
+	// thumbnailAge is how long unused thumbnails are kept.
+	thumbnailAge = 30 * 24 * time.Hour
+)
+
+var thumbnailCache = sync.OnceValue(func() *thumbnail.Cache {
+	c := thumbnail.NewCache(thumbnail.DefaultDir())
+	go func() { _ = c.Prune(thumbnailAge) }()
+	return c
+})
+
+// GetThumbnails returns JPEG thumbnails of files returned by ScanFiles, at
+// most maxSize pixels wide and high, as data URLs keyed by path, for the
+// scan preview grid. Files without a thumbnail, such as videos and raw
+// files, are left out. Thumbnails are cached on disk, so showing the same
+// source again is fast.
+func (a *App) GetThumbnails(paths []string, maxSize int) map[string]string {
+	if maxSize <= 0 {
+		maxSize = defaultThumbnailSize
+	}
+	maxSize = min(maxSize, maxThumbnailSize)
+	thumbs := make(map[string]string, len(paths))
+	src, err := storage.ForSource(a.currentConfig())
+	if err != nil {
+		runtime.LogWarningf(a.ctx, "thumbnails: %v", err)
+		return thumbs
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		semaphore = make(chan struct{}, thumbnailWorkers)
+	)
+	for _, path := range paths {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			data, err := thumbnailCache().Get(src, path, maxSize)
+			if err != nil {
+				if !errors.Is(err, thumbnail.ErrUnsupported) {
+					runtime.LogDebugf(a.ctx, "thumbnails: %v", err)
+				}
+				return
+			}
+			mu.Lock()
+			thumbs[path] = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return thumbs
+}
@@ -0,0 +1,181 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	_ "embed"
+	goruntime "runtime"
+	"sync"
+
+	"fyne.io/systray"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+//go:embed assets/tray.ico
+var trayIcon []byte
+
+// tray is the system tray icon. Its menu shows the window, runs groups,
+// pauses watch mode and quits, so the app can keep working in the
+// background with the window closed (AppSettings.CloseToTray).
+type tray struct {
+	mu    sync.Mutex
+	show  *systray.MenuItem
+	run   *systray.MenuItem
+	pause *systray.MenuItem
+	quit  *systray.MenuItem
+	// groups are the items under run, rebuilt when the groups change.
+	groups []*systray.MenuItem
+	// paused is set while watch mode is stopped from the tray, so the tray
+	// can resume it.
+	paused bool
+}
+
+// startTray shows the tray icon. The tray runs its own message loop on a
+// thread of its own, next to the window's.
+func (a *App) startTray() {
+	a.tray = &tray{}
+	go func() {
+		goruntime.LockOSThread()
+		systray.Run(a.trayReady, nil)
+	}()
+}
+
+// stopTray removes the tray icon.
+func (a *App) stopTray() {
+	if a.tray != nil {
+		systray.Quit()
+	}
+}
+
+// trayReady builds the tray menu once the icon is shown.
+func (a *App) trayReady() {
+	systray.SetIcon(trayIcon)
+	systray.SetTooltip("Copy Image Tool")
+	systray.SetOnTapped(a.showWindow)
+
+	t := a.tray
+	t.mu.Lock()
+	t.show = systray.AddMenuItem("", "")
+	t.run = systray.AddMenuItem("", "")
+	t.pause = systray.AddMenuItem("", "")
+	systray.AddSeparator()
+	t.quit = systray.AddMenuItem("", "")
+	t.mu.Unlock()
+
+	onClick(t.show, a.showWindow)
+	onClick(t.pause, a.toggleWatchPause)
+	onClick(t.quit, a.quit)
+	a.refreshTray()
+}
+
+// refreshTray updates the tray menu after the groups, the language or
+// watch mode changed.
+func (a *App) refreshTray() {
+	t := a.tray
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.show == nil {
+		// Not shown yet: trayReady refreshes the menu.
+		return
+	}
+
+	t.show.SetTitle(a.catalog().T("tray.show"))
+	t.run.SetTitle(a.catalog().T("tray.run_group"))
+	t.quit.SetTitle(a.catalog().T("tray.quit"))
+
+	for _, item := range t.groups {
+		item.Remove()
+	}
+	t.groups = nil
+	for _, group := range a.currentConfig().Groups {
+		item := t.run.AddSubMenuItem(group.Name, "")
+		id := group.ID
+		onClick(item, func() { a.runGroupFromTray(id) })
+		t.groups = append(t.groups, item)
+	}
+	if len(t.groups) == 0 {
+		t.groups = append(t.groups, t.run.AddSubMenuItem(a.catalog().T("tray.no_groups"), ""))
+		t.groups[0].Disable()
+	}
+
+	watching := a.IsWatching()
+	if watching || !t.paused {
+		t.pause.SetTitle(a.catalog().T("tray.pause_watch"))
+	} else {
+		t.pause.SetTitle(a.catalog().T("tray.resume_watch"))
+	}
+	if watching || t.paused {
+		t.pause.Enable()
+	} else {
+		t.pause.Disable()
+	}
+}
+
+// onClick calls f whenever item is clicked, until it is removed.
+func onClick(item *systray.MenuItem, f func()) {
+	go func() {
+		for range item.ClickedCh {
+			f()
+		}
+	}()
+}
+
+// showWindow brings the window back, e.g. after it was closed to the tray.
+func (a *App) showWindow() {
+	runtime.WindowShow(a.ctx)
+	runtime.WindowUnminimise(a.ctx)
+}
+
+// runGroupFromTray queues the group like the frontend's run button does,
+// so it runs even while the window is hidden.
+func (a *App) runGroupFromTray(id string) {
+	if _, err := a.EnqueueJob(JobSpec{Kind: JobGroup, GroupID: id}); err != nil {
+		runtime.LogWarningf(a.ctx, "tray: %v", err)
+	}
+}
+
+// toggleWatchPause stops watch mode, or starts it again after it was
+// paused from the tray. watch:changed tells the frontend.
+func (a *App) toggleWatchPause() {
+	if a.IsWatching() {
+		a.StopWatch()
+		a.setWatchPaused(true)
+	} else if err := a.StartWatch(); err != nil {
+		runtime.EventsEmit(a.ctx, "watch:error", err.Error())
+	}
+	runtime.EventsEmit(a.ctx, "watch:changed", a.IsWatching())
+}
+
+// setWatchPaused records whether watch mode is paused from the tray; the
+// frontend starting or stopping it clears the pause.
+func (a *App) setWatchPaused(paused bool) {
+	if a.tray == nil {
+		return
+	}
+	a.tray.mu.Lock()
+	a.tray.paused = paused
+	a.tray.mu.Unlock()
+	a.refreshTray()
+}
+
+// quit ends the app, even when closing the window only hides it.
+func (a *App) quit() {
+	a.quitting.Store(true)
+	runtime.Quit(a.ctx)
+}
+
+// beforeClose hides the window to the tray instead of closing it when the
+// user chose so. Quit in the tray menu still closes it. The window state
+// is saved when it really closes, as the app is ending.
+func (a *App) beforeClose(ctx context.Context) bool {
+	if a.quitting.Load() || a.tray == nil || !a.currentConfig().App.ClosesToTray() {
+		a.saveWindow(ctx)
+		return false
+	}
+	runtime.WindowHide(ctx)
+	return true
+}
@@ -3,30 +3,17 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
-	"syscall"
+	"time"
 
-	"github.com/wailsapp/wails/v2/pkg/runtime"
-)
+	"copy-image/internal/config"
+	"copy-image/internal/updater"
+	"copy-image/internal/version"
 
-// CurrentVersion holds the application version.
-// This should be updated when releasing new versions.
-// For production builds, use ldflags to inject the version at build time:
-// go build -ldflags "-X main.CurrentVersion=v2.1.3"
-var CurrentVersion = "v2.1.5"
-
-// GitHubOwner and GitHubRepo identify the repository for update checks.
-// These constants define where to look for new releases on GitHub.
-const (
-	GitHubOwner = "hoangtran1411"
-	GitHubRepo  = "copy-image"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // UpdateInfo holds information about available updates.
@@ -37,58 +24,63 @@ type UpdateInfo struct {
 	LatestVer   string `json:"latestVersion"`
 	DownloadURL string `json:"downloadUrl"`
 	ReleaseURL  string `json:"releaseUrl"`
-}
-
-// GitHubRelease represents the relevant fields from GitHub's release API response.
-// We only parse the fields we need to minimize processing overhead.
-type GitHubRelease struct {
-	TagName string `json:"tag_name"`
-	HTMLURL string `json:"html_url"`
-	Assets  []struct {
-		Name               string `json:"name"`
-		BrowserDownloadURL string `json:"browser_download_url"`
-	} `json:"assets"`
+	// ReleaseNotes are what's new in the latest version, in GitHub
+	// markdown, shown before the user agrees to update.
+	ReleaseNotes string `json:"releaseNotes"`
+	// PublishedAt is when the latest version was released, in RFC 3339
+	// format; empty if unknown.
+	PublishedAt string `json:"publishedAt"`
+	// Channel is the update channel looked in, "stable" or "beta".
+	Channel string `json:"channel"`
+	// Skipped is set when the latest version is newer but the user chose
+	// to skip it; Available is false then.
+	Skipped bool `json:"skipped"`
 }
 
 // GetCurrentVersion returns the current app version.
 // The frontend displays this in the header to help users identify their version.
 func (a *App) GetCurrentVersion() string {
-	return CurrentVersion
+	return version.Version
+}
+
+// GetBuildInfo returns the version, commit and build date of the app, for
+// the about box and bug reports.
+func (a *App) GetBuildInfo() version.Info {
+	return version.Get()
 }
 
-// CheckForUpdate queries GitHub API to check if a newer version is available.
+// CheckForUpdate queries the update feed, GitHub's API by default, to check
+// if a newer version is available.
 // This runs asynchronously on app startup so it doesn't block the UI.
 // Returns update info including download URL if an update is available.
+// The update channel of the settings decides whether prereleases count,
+// and a version the user skipped is not offered.
 func (a *App) CheckForUpdate() UpdateInfo {
+	settings := a.currentConfig().App
 	info := UpdateInfo{
 		Available:  false,
-		CurrentVer: CurrentVersion,
+		CurrentVer: version.Version,
+		Channel:    settings.Channel(),
 	}
 
-	// Construct the GitHub API URL for the latest release.
-	// Using the releases/latest endpoint gives us the most recent non-prerelease version.
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", GitHubOwner, GitHubRepo)
-
-	resp, err := http.Get(url)
+	checker, err := a.updateChecker()
 	if err != nil {
-		// Network errors are silently ignored - the app should work offline.
+		runtime.LogWarningf(a.ctx, "update check: %v", err)
 		return info
 	}
-	defer resp.Body.Close()
-
-	// Non-200 responses indicate API issues or rate limiting.
-	// We fail gracefully by returning no update available.
-	if resp.StatusCode != http.StatusOK {
-		return info
-	}
-
-	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+	release, err := checker.Check(a.ctx)
+	if err != nil {
+		// Network and API errors, such as rate limiting, are silently
+		// ignored - the app should work offline.
 		return info
 	}
 
 	info.LatestVer = release.TagName
 	info.ReleaseURL = release.HTMLURL
+	info.ReleaseNotes = release.Body
+	if !release.PublishedAt.IsZero() {
+		info.PublishedAt = release.PublishedAt.Format(time.RFC3339)
+	}
 
 	// Find the Windows executable in the release assets.
 	// We specifically look for the "desktop-windows-amd64" version to avoid
@@ -113,169 +105,200 @@ func (a *App) CheckForUpdate() UpdateInfo {
 
 	// Compare versions using semantic versioning.
 	// Only mark as available if the remote version is strictly newer.
-	if info.LatestVer != "" && CompareVersions(info.LatestVer, CurrentVersion) {
-		info.Available = true
+	if info.LatestVer != "" && CompareVersions(info.LatestVer, version.Version) {
+		if info.LatestVer == settings.SkippedVersion() {
+			info.Skipped = true
+		} else {
+			info.Available = true
+		}
 	}
 
 	return info
 }
 
-// CompareVersions determines if v1 is newer than v2 using semantic versioning.
-// Returns true if v1 > v2, false otherwise.
-// This handles version strings like "v1.2.3" or "1.2.3".
-func CompareVersions(v1, v2 string) bool {
-	// Remove the 'v' prefix if present for consistent parsing.
-	v1 = strings.TrimPrefix(v1, "v")
-	v2 = strings.TrimPrefix(v2, "v")
+// updateClient returns the HTTP client for update checks and downloads,
+// going through the proxy and trusting the CA file of the config.
+func (a *App) updateClient() (*http.Client, error) {
+	cfg := a.currentConfig()
+	return updater.NewClient(cfg.HTTP.ProxyURL(), cfg.HTTP.CAPath())
+}
 
-	parts1 := parseVersion(v1)
-	parts2 := parseVersion(v2)
+// updateChecker returns the checker for the feed and channel of the
+// settings: the public GitHub repository unless an update feed is
+// configured. It keeps the feed's last answer in the data folder, so that
+// checks are conditional on its ETag and unchanged answers are not rate
+// limited.
+func (a *App) updateChecker() (*updater.Checker, error) {
+	client, err := a.updateClient()
+	if err != nil {
+		return nil, err
+	}
+	cfg := a.currentConfig()
+	return &updater.Checker{
+		Client:    client,
+		Feed:      updater.NewFeed(cfg.UpdateFeed),
+		Channel:   cfg.App.Channel(),
+		CachePath: a.dataPath(updater.CacheFile),
+	}, nil
+}
 
-	// Compare major, minor, patch in order of significance.
-	// Return as soon as we find a difference.
-	for i := 0; i < 3; i++ {
-		if parts1[i] > parts2[i] {
-			return true
+// updatePoll is how often watchUpdates wakes up to see whether a check is
+// due, so that changes to the settings apply without a restart.
+var updatePoll = time.Hour
+
+// watchUpdates looks for updates in the background while the app runs,
+// once a day or a week as the settings say, counting from the last check
+// made, even by an earlier run. The frontend checks at launch; a release
+// appearing later is announced with update:available.
+func (a *App) watchUpdates() {
+	ticker := time.NewTicker(updatePoll)
+	defer ticker.Stop()
+	var announced string
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		settings := a.currentConfig().App
+		if !settings.ChecksUpdates() {
+			continue
 		}
-		if parts1[i] < parts2[i] {
-			return false
+		checker, err := a.updateChecker()
+		if err != nil || time.Since(checker.LastChecked()) < settings.UpdateEvery() {
+			continue
+		}
+		info := a.CheckForUpdate()
+		if info.Available && info.LatestVer != announced {
+			announced = info.LatestVer
+			runtime.EventsEmit(a.ctx, "update:available", info)
 		}
 	}
-
-	// Versions are equal
-	return false
 }
 
-// parseVersion splits a version string into [major, minor, patch] integers.
-// Missing parts default to 0 (e.g., "1.2" becomes [1, 2, 0]).
-func parseVersion(v string) [3]int {
-	var result [3]int
-	parts := strings.Split(v, ".")
-
-	for i := 0; i < len(parts) && i < 3; i++ {
-		// Use Sscanf for safe integer parsing - invalid inputs become 0.
-		// We explicitly ignore the error because we want best-effort parsing (0 default)
-		_, _ = fmt.Sscanf(parts[i], "%d", &result[i])
+// SkipVersion stops tag from being offered, without turning update checks
+// off: the next release after it is offered as usual. An empty tag offers
+// a skipped version again.
+func (a *App) SkipVersion(tag string) error {
+	_, err := a.updateConfig(func(next *config.Config) error {
+		var app config.AppSettings
+		if next.App != nil {
+			app = *next.App
+		}
+		app.SkipVersion = tag
+		next.App = &app
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	return a.SaveConfig()
+}
+
+// CompareVersions determines if v1 is newer than v2 using semantic versioning.
+// Returns true if v1 > v2, false otherwise.
+// This handles version strings like "v1.2.3" or "1.2.3", and prereleases
+// like "v1.3.0-beta.1", which come before their release.
+func CompareVersions(v1, v2 string) bool {
+	return updater.Newer(v1, v2)
+}
 
-	return result
+// UpdateProgress is the payload of update:progress: the stage of the
+// update and, while downloading, how far it got.
+type UpdateProgress struct {
+	Stage          string  `json:"stage"` // "download", "verify" or "install"
+	Message        string  `json:"message"`
+	Downloaded     int64   `json:"downloaded"`
+	Total          int64   `json:"total"` // 0 when the size is unknown
+	Percent        float64 `json:"percent"`
+	BytesPerSecond float64 `json:"bytesPerSecond"`
 }
 
 // PerformUpdate downloads and installs a new version of the application.
 // This is a complex operation that:
-// 1. Downloads the new executable to a secure temp file
-// 2. Creates a batch script to replace the running executable
-// 3. Exits the current app and lets the batch script do the swap
+// 1. Downloads the new executable, resuming a download that broke off
+// 2. Verifies it against the checksums, and their signature, of the release
+// 3. Installs it in place of the running one, the way the platform allows
+// 4. Exits the current app and lets the new version start
 //
-// We use a batch script because Windows locks running executables,
-// so we can't directly overwrite the file while it's running.
+// The platform details, such as Windows locking running executables, are
+// left to the updater package.
 func (a *App) PerformUpdate(downloadURL string) (bool, error) {
 	if downloadURL == "" {
 		return false, fmt.Errorf("no download URL provided")
 	}
 
-	// Get the path to the currently running executable.
-	// This is the file we'll replace with the new version.
-	exePath, err := os.Executable()
+	u, err := updater.New()
 	if err != nil {
-		return false, fmt.Errorf("failed to get executable path: %w", err)
+		return false, err
 	}
-	exePath, _ = filepath.Abs(exePath)
-
-	// SECURITY: Use os.CreateTemp to avoid predictable temporary filenames (TOCTOU)
-	tempFile, err := os.CreateTemp("", "copyimage_update_*.exe")
+	client, err := a.updateClient()
 	if err != nil {
-		return false, fmt.Errorf("failed to create temp file: %w", err)
+		return false, err
 	}
-	tempPath := tempFile.Name()
-
-	// Notify the frontend that download is starting.
-	runtime.EventsEmit(a.ctx, "update:progress", "Downloading update...")
-
-	// Download the new version.
-	resp, err := http.Get(downloadURL)
+	// Kept in the user's cache folder under a name of its own, so the
+	// next attempt resumes it; the checksum rules out a tampered file.
+	partPath, err := updater.PartialPath(downloadURL)
 	if err != nil {
-		_ = tempFile.Close()
-		_ = os.Remove(tempPath)
-		return false, fmt.Errorf("failed to download update: %w", err)
+		return false, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		_ = tempFile.Close()
-		_ = os.Remove(tempPath)
-		return false, fmt.Errorf("download failed with status: %d", resp.StatusCode)
-	}
-
-	// Copy the downloaded content to the temp file.
-	_, err = io.Copy(tempFile, resp.Body)
-	// Explicitly close the file to ensure flush.
-	// We handle the error if closes fails, but prioritize the copy error if it exists.
-	closeErr := tempFile.Close()
+	runtime.EventsEmit(a.ctx, "update:progress", UpdateProgress{Stage: "download", Message: "Downloading update..."})
+	err = updater.Download(a.ctx, client, downloadURL, partPath, func(p updater.Progress) {
+		runtime.EventsEmit(a.ctx, "update:progress", UpdateProgress{
+			Stage:          "download",
+			Message:        "Downloading update...",
+			Downloaded:     p.Downloaded,
+			Total:          p.Total,
+			Percent:        p.Percent(),
+			BytesPerSecond: p.BytesPerSecond,
+		})
+	})
 	if err != nil {
-		_ = os.Remove(tempPath)
-		return false, fmt.Errorf("failed to save update: %w", err)
-	}
-	if closeErr != nil {
-		_ = os.Remove(tempPath)
-		return false, fmt.Errorf("failed to close temp file: %w", closeErr)
+		return false, err
 	}
 
-	runtime.EventsEmit(a.ctx, "update:progress", "Installing update...")
+	// Never install a download the release does not vouch for.
+	runtime.EventsEmit(a.ctx, "update:progress", UpdateProgress{Stage: "verify", Message: "Verifying update...", Percent: 100})
+	if err := updater.Verify(a.ctx, client, downloadURL, partPath); err != nil {
+		// Not to be resumed: it is the wrong file.
+		_ = os.Remove(partPath)
+		return false, fmt.Errorf("update refused: %w", err)
+	}
 
-	// Create a batch script for the update process
-	// SECURITY: Use CreateTemp for the batch script too
-	batchFile, err := os.CreateTemp("", "update_copyimage_*.bat")
+	runtime.EventsEmit(a.ctx, "update:progress", UpdateProgress{Stage: "install", Message: "Installing update...", Percent: 100})
+	err = u.Install(partPath)
+	// Install copied the download next to the executable.
+	_ = os.Remove(partPath)
 	if err != nil {
-		_ = os.Remove(tempPath)
-		return false, fmt.Errorf("failed to create batch script: %w", err)
+		return false, err
+	}
+	// The new version waits for this one to exit before it starts.
+	if err := u.Restart(); err != nil {
+		return false, err
 	}
-	batchPath := batchFile.Name()
 
-	// Optimized batch script for Windows:
-	// - timeout: waits for the app to close
-	// - del: removes old exe
-	// - move: installs new exe
-	// - start: launches the updated app
-	// - (goto) trick: safely deletes the script itself after execution
-	batchContent := fmt.Sprintf(`@echo off
-timeout /t 2 /nobreak >nul
-del /f /q "%s"
-move /y "%s" "%s"
-start "" "%s"
-(goto) 2>nul & del "%%~f0"
-`, exePath, tempPath, exePath, exePath)
+	// Exit the application to allow the new version to start.
+	runtime.Quit(a.ctx)
 
-	if _, err := batchFile.Write([]byte(batchContent)); err != nil {
-		_ = batchFile.Close()
-		_ = os.Remove(batchPath)
-		_ = os.Remove(tempPath)
-		return false, fmt.Errorf("failed to write batch script: %w", err)
-	}
+	// In case Quit doesn't effectively kill us instantly from this goroutine's perspective
+	return true, nil
+}
 
-	if err := batchFile.Close(); err != nil {
-		_ = os.Remove(batchPath)
-		_ = os.Remove(tempPath)
-		return false, fmt.Errorf("failed to close batch script: %w", err)
+// RollbackUpdate puts back the version the last update replaced and
+// restarts into it, for when a new release breaks a workflow mid-deadline.
+// The version rolled back from is kept, so rolling back again undoes it.
+func (a *App) RollbackUpdate() (bool, error) {
+	u, err := updater.New()
+	if err != nil {
+		return false, err
 	}
-
-	// Run the batch script as a detached process to ensure it continues
-	// running after the main application exits.
-	cmd := exec.Command("cmd", "/c", batchPath)
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		HideWindow:    true,
-		CreationFlags: 0x00000008, // DETACHED_PROCESS
+	if err := u.Rollback(); err != nil {
+		return false, err
 	}
-
-	if err := cmd.Start(); err != nil {
-		_ = os.Remove(batchPath)
-		_ = os.Remove(tempPath)
-		return false, fmt.Errorf("failed to start update script: %w", err)
+	if err := u.Restart(); err != nil {
+		return false, err
 	}
-
-	// Exit the application to allow the batch script to replace the executable.
 	runtime.Quit(a.ctx)
-
-	// In case Quit doesn't effectively kill us instantly from this goroutine's perspective
 	return true, nil
 }
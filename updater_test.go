@@ -96,55 +96,6 @@ func TestCompareVersions(t *testing.T) {
 	}
 }
 
-// TestParseVersion verifies that version strings are correctly parsed into components.
-func TestParseVersion(t *testing.T) {
-	tests := []struct {
-		name     string
-		version  string
-		expected [3]int
-	}{
-		{
-			name:     "full version",
-			version:  "1.2.3",
-			expected: [3]int{1, 2, 3},
-		},
-		{
-			name:     "two parts",
-			version:  "1.2",
-			expected: [3]int{1, 2, 0},
-		},
-		{
-			name:     "one part",
-			version:  "1",
-			expected: [3]int{1, 0, 0},
-		},
-		{
-			name:     "empty string",
-			version:  "",
-			expected: [3]int{0, 0, 0},
-		},
-		{
-			name:     "with extra parts",
-			version:  "1.2.3.4",
-			expected: [3]int{1, 2, 3},
-		},
-		{
-			name:     "invalid characters default to 0",
-			version:  "a.b.c",
-			expected: [3]int{0, 0, 0},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := parseVersion(tt.version)
-			if result != tt.expected {
-				t.Errorf("parseVersion(%q) = %v, want %v", tt.version, result, tt.expected)
-			}
-		})
-	}
-}
-
 // TestGetCurrentVersion ensures the version string is returned correctly.
 func TestGetCurrentVersion(t *testing.T) {
 	app := &App{}
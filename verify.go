@@ -0,0 +1,48 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+
+	"copy-image/internal/copier"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// VerifyDestination compares the destination with the source without
+// copying anything, using the same engine as `copyimage verify`: missing,
+// extra and mismatched files (by size, or by content when hash is set).
+func (a *App) VerifyDestination(hash bool) (*copier.VerifyReport, error) {
+	cfg := a.currentConfig()
+	if cfg.Source == "" || cfg.Destination == "" {
+		return nil, fmt.Errorf("source and destination must be configured")
+	}
+	report, err := copier.New(cfg).Verify(a.ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	a.lastVerify = report
+	return report, nil
+}
+
+// ExportVerifyReport asks where to save the last verify report and writes
+// it there, as CSV or JSON depending on the chosen name. It returns the
+// path, or "" if the dialog was cancelled.
+func (a *App) ExportVerifyReport() (string, error) {
+	if a.lastVerify == nil {
+		return "", fmt.Errorf("run a verify first")
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           a.catalog().T("app.export_verify"),
+		DefaultFilename: "verify-report.csv",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "CSV (*.csv)", Pattern: "*.csv"},
+			{DisplayName: "JSON (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	return path, a.lastVerify.Export(path)
+}
@@ -0,0 +1,137 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"copy-image/internal/copier"
+	"copy-image/internal/history"
+	"copy-image/internal/lock"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// WatchBatch is sent with the watch:batch event after watch mode copied a
+// batch of new files to one destination.
+type WatchBatch struct {
+	GroupID     string     `json:"groupId,omitempty"`
+	Destination string     `json:"destination"`
+	Result      CopyResult `json:"result"`
+}
+
+// StartWatch starts watch mode: new files appearing in the source of every
+// enabled group (or, without groups, in the configured source) are copied
+// as soon as they are complete. Each batch emits watch:batch; a source that
+// cannot be watched emits watch:error. Watch mode runs until StopWatch.
+func (a *App) StartWatch() error {
+	if a.watchCancel != nil {
+		return fmt.Errorf("watch mode is already running")
+	}
+	// Validate fills in defaults, so it checks a copy.
+	cfg := *cloneConfig(a.currentConfig())
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	a.watchCancel = cancel
+	opts := copier.DefaultWatchOptions()
+
+	// Batches are copied one at a time, like the CLI, so two groups never
+	// write to a shared destination at once.
+	var mu sync.Mutex
+	run := func(groupID string) copier.DestinationRunner {
+		return func(ctx context.Context, c *copier.Copier, files []string) copier.CopySummary {
+			mu.Lock()
+			defer mu.Unlock()
+			a.notifyStart(history.KindWatch, groupID, c, len(files))
+			start := time.Now()
+			summary := a.runHooked(ctx, history.KindWatch, groupID, c, len(files), func() copier.CopySummary {
+				return a.copyLocked(ctx, c, files, cfg.DryRun, nil)
+			})
+			a.recordRun(history.KindWatch, groupID, c, start, summary, ctx.Err() != nil)
+			runtime.EventsEmit(a.ctx, "watch:batch", WatchBatch{
+				GroupID:     groupID,
+				Destination: c.Destination(),
+				Result:      a.copyResult(summary),
+			})
+			return summary
+		}
+	}
+
+	groups := cfg.GetEnabledGroups()
+	if len(groups) == 0 {
+		c := copier.New(&cfg)
+		go a.watchDone(c.Watch(ctx, opts, func(files []string) {
+			_ = run("")(ctx, c, files)
+		}))
+		a.setWatchPaused(false)
+		return nil
+	}
+	for _, group := range groups {
+		go a.watchDone(copier.WatchGroup(ctx, &cfg, group, opts, run(group.ID), nil))
+	}
+	a.setWatchPaused(false)
+	return nil
+}
+
+// watchDone reports why a watch stopped. A nil error means StopWatch was
+// called.
+func (a *App) watchDone(err error) {
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "watch:error", err.Error())
+	}
+}
+
+// StopWatch stops watch mode. A batch being copied is cancelled.
+func (a *App) StopWatch() {
+	if a.watchCancel != nil {
+		a.watchCancel()
+		a.watchCancel = nil
+	}
+	a.setWatchPaused(false)
+}
+
+// IsWatching reports whether watch mode is running, so the frontend can
+// restore its toggle after a reload.
+func (a *App) IsWatching() bool {
+	return a.watchCancel != nil
+}
+
+// copyLocked copies files with c while holding the destination's session
+// lock, passing the copy's events to onEvent if it is not nil. A busy
+// destination fails the whole batch.
+func (a *App) copyLocked(ctx context.Context, c *copier.Copier, files []string, dryRun bool, onEvent func(copier.Event)) copier.CopySummary {
+	if err := c.Err(); err != nil {
+		return copier.CopySummary{
+			TotalFiles:  len(files),
+			Failed:      len(files),
+			FailedFiles: copier.AllFailed(files, errors.New(a.catalog().T("app.destination_unavailable", err))),
+		}
+	}
+	if !dryRun {
+		lease, err := lock.Acquire(ctx, c.Config().LockDir(), lock.Options{})
+		if err != nil {
+			return copier.CopySummary{
+				TotalFiles:  len(files),
+				Failed:      len(files),
+				FailedFiles: copier.AllFailed(files, errors.New(a.catalog().T("app.destination_busy", err))),
+			}
+		}
+		defer func() { _ = lease.Release() }()
+	}
+	a.logTo(c)
+	save := a.useManifest(c)
+	defer save()
+	if onEvent == nil {
+		return c.CopyFilesParallelWithEvents(ctx, files, nil)
+	}
+	events, wait := copier.Dispatch(onEvent)
+	defer wait()
+	return c.CopyFilesParallelWithEvents(ctx, files, events)
+}
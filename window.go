@@ -0,0 +1,131 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"github.com/wailsapp/wails/v2/pkg/options"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"copy-image/internal/config"
+)
+
+// Default and smallest size of the window.
+const (
+	windowWidth     = 900
+	windowHeight    = 700
+	windowMinWidth  = 700
+	windowMinHeight = 550
+)
+
+// launchSettings returns the app settings in the config file, read before
+// the app starts since Wails needs the window's size and state before
+// startup. It is nil without settings.
+func launchSettings() *config.AppSettings {
+	cfg, _ := config.LoadFromFile(config.Locate(""))
+	if cfg == nil {
+		return nil
+	}
+	return cfg.App
+}
+
+// windowSize returns the size the window opens with: the saved one, or the
+// default.
+func windowSize(s *config.AppSettings) (width, height int) {
+	if s == nil || s.Window == nil || s.Window.Width < windowMinWidth || s.Window.Height < windowMinHeight {
+		return windowWidth, windowHeight
+	}
+	return s.Window.Width, s.Window.Height
+}
+
+// windowStartState returns how the window opens.
+func windowStartState(s *config.AppSettings) options.WindowStartState {
+	switch {
+	case s.StartsMinimized():
+		return options.Minimised
+	case s != nil && s.Window != nil && s.Window.Maximized:
+		return options.Maximised
+	}
+	return options.Normal
+}
+
+// restoreWindow moves the window where it was last closed, unless that is
+// off the screen, e.g. on a monitor since disconnected.
+func (a *App) restoreWindow(ctx context.Context) {
+	w := a.currentConfig().App
+	if w == nil || w.Window == nil || w.Window.Maximized {
+		return
+	}
+	screens, err := runtime.ScreenGetAll(ctx)
+	if err != nil {
+		return
+	}
+	for _, s := range screens {
+		if s.IsCurrent && w.Window.X >= 0 && w.Window.Y >= 0 && w.Window.X+100 <= s.Width && w.Window.Y+100 <= s.Height {
+			runtime.WindowSetPosition(ctx, w.Window.X, w.Window.Y)
+			return
+		}
+	}
+}
+
+// saveWindow keeps the window's size, position and maximized state in the
+// app settings when it is closed. Only they are written to the config
+// file, not settings the frontend has not saved.
+func (a *App) saveWindow(ctx context.Context) {
+	if runtime.WindowIsMinimised(ctx) {
+		return
+	}
+	current := a.currentConfig().App
+	var state config.WindowState
+	if current != nil && current.Window != nil {
+		state = *current.Window
+	}
+	if runtime.WindowIsMaximised(ctx) {
+		// Keep the size to restore when unmaximized.
+		state.Maximized = true
+	} else {
+		state.Maximized = false
+		state.Width, state.Height = runtime.WindowGetSize(ctx)
+		state.X, state.Y = runtime.WindowGetPosition(ctx)
+	}
+	if current != nil && current.Window != nil && *current.Window == state {
+		return
+	}
+
+	_, _ = a.updateConfig(func(next *config.Config) error {
+		next.App = withWindow(next.App, state)
+		return nil
+	})
+
+	cfg, err := config.LoadFromFile(a.configPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) || a.configPath == "":
+		cfg = config.DefaultConfig()
+	case err != nil:
+		// Saving would drop what could not be read, such as unknown keys.
+		runtime.LogWarningf(ctx, "window state not saved: %v", err)
+		return
+	}
+	cfg.App = withWindow(cfg.App, state)
+	if a.configPath == "" {
+		a.configPath, err = cfg.SaveToDefault()
+	} else {
+		err = cfg.SaveToFile(a.configPath)
+	}
+	if err != nil {
+		runtime.LogWarningf(ctx, "window state not saved: %v", err)
+	}
+}
+
+// withWindow returns a copy of s with the window state.
+func withWindow(s *config.AppSettings, state config.WindowState) *config.AppSettings {
+	var next config.AppSettings
+	if s != nil {
+		next = *s
+	}
+	next.Window = &state
+	return &next
+}